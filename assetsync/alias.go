@@ -0,0 +1,40 @@
+package assetsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// setAlias stores the alias of the asset in payload as an asset of type
+// cfg.AliasAssetType whose identifier is the alias. The aliased asset is set
+// as its parent and the team of the asset as its owner, so searches by
+// alias resolve to the aliased asset in the Security Graph. If the asset
+// does not have alias or cfg.AliasAssetType is empty, nothing is done.
+func setAlias(icli inventory.Inventory, asset inventory.AssetResp, team inventory.TeamResp, payload vulcan.AssetPayload, cfg Config) error {
+	if cfg.AliasAssetType == "" || payload.Alias == "" {
+		return nil
+	}
+
+	aliasPayload := vulcan.AssetPayload{
+		Team:       payload.Team,
+		AssetType:  vulcan.AssetType(cfg.AliasAssetType),
+		Identifier: payload.Alias,
+	}
+	alias, _, err := upsertAsset(icli, aliasPayload, cfg)
+	if err != nil {
+		return fmt.Errorf("could not upsert alias: %w", err)
+	}
+
+	if _, err := icli.UpsertParent(alias.ID, asset.ID, time.Now(), inventory.Unexpired); err != nil {
+		return fmt.Errorf("could not upsert parent: %w", err)
+	}
+
+	if err := setOwner(icli, alias, team); err != nil {
+		return fmt.Errorf("could not set owner: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestRefreshAssetAlias(t *testing.T) {
+	tests := []struct {
+		name           string
+		aliasAssetType string
+		alias          string
+		wantAlias      bool
+	}{
+		{
+			name:           "alias",
+			aliasAssetType: "Alias",
+			alias:          "www",
+			wantAlias:      true,
+		},
+		{
+			name:           "no alias",
+			aliasAssetType: "Alias",
+			alias:          "",
+			wantAlias:      false,
+		},
+		{
+			name:           "disabled",
+			aliasAssetType: "",
+			alias:          "www",
+			wantAlias:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				InventoryPageSize: 100,
+				AliasAssetType:    tt.aliasAssetType,
+			}
+
+			inv := inventorytest.NewInMemory()
+
+			payload := vulcan.AssetPayload{
+				Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+				AssetType:  "Hostname",
+				Identifier: "example.com",
+				Alias:      tt.alias,
+			}
+			// Refresh the asset twice to check that the alias is
+			// not duplicated.
+			for i := 0; i < 2; i++ {
+				if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+					t.Fatalf("error refreshing asset: %v", err)
+				}
+			}
+
+			assets, err := inv.Assets("Hostname", "example.com", time.Time{}, inventory.Pagination{})
+			if err != nil || len(assets) != 1 {
+				t.Fatalf("unexpected assets: %v, %v", assets, err)
+			}
+
+			aliases, err := inv.Assets("Alias", "www", time.Time{}, inventory.Pagination{})
+			if err != nil {
+				t.Fatalf("error getting aliases: %v", err)
+			}
+			if !tt.wantAlias {
+				if len(aliases) != 0 {
+					t.Errorf("unexpected aliases: %v", aliases)
+				}
+				return
+			}
+			if len(aliases) != 1 {
+				t.Fatalf("unexpected aliases: %v", aliases)
+			}
+
+			parents, err := inv.Parents(aliases[0].ID, inventory.Pagination{})
+			if err != nil {
+				t.Fatalf("error getting parents: %v", err)
+			}
+			var gotParents []string
+			for _, p := range parents {
+				gotParents = append(gotParents, p.ParentID)
+			}
+			if diff := cmp.Diff([]string{assets[0].ID}, gotParents); diff != "" {
+				t.Errorf("parents mismatch (-want +got):\n%v", diff)
+			}
+
+			owners, err := inv.Owners(aliases[0].ID, inventory.Pagination{})
+			if err != nil || len(owners) != 1 {
+				t.Fatalf("unexpected owners: %v, %v", owners, err)
+			}
+			teams, err := inv.Teams("team-1", inventory.Pagination{})
+			if err != nil || len(teams) != 1 {
+				t.Fatalf("unexpected teams: %v, %v", teams, err)
+			}
+			if owners[0].TeamID != teams[0].ID {
+				t.Errorf("unexpected owner: got %v, want %v", owners[0].TeamID, teams[0].ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package assetsync synchronizes Vulcan assets with the Security Graph
+// Asset Inventory. [Engine] applies the asset events to the Asset
+// Inventory and it is the engine run by the graph-vulcan-assets consumer.
+// The package also provides its extension points. They allow deployments
+// embedding graph-vulcan-assets to add custom enrichments and relations to
+// the assets, for instance the result of CMDB lookups, to veto, modify or
+// mirror the writes made to the Asset Inventory, and to query the status
+// of the synchronization.
+package assetsync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// AnyAssetType is the asset type used to register enrichers that are
+// applied to every asset regardless of its type.
+const AnyAssetType vulcan.AssetType = "*"
+
+// Enricher enriches an asset after it has been created or updated in the
+// Asset Inventory. asset is the inventory asset corresponding to payload.
+// Enrichers must be idempotent because the same asset can be processed
+// several times.
+type Enricher func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error
+
+// registration is an enricher and the asset type it is registered for.
+type registration struct {
+	assetType vulcan.AssetType
+	fn        Enricher
+}
+
+// ErrVeto is returned, possibly wrapped, by the before hooks of [Hooks] to
+// veto an operation. A vetoed operation is not applied to the Asset
+// Inventory and the corresponding event is considered processed.
+var ErrVeto = errors.New("operation vetoed")
+
+// Hooks are called before and after the operations applied to the Asset
+// Inventory when an asset is synchronized. Nil hooks are ignored. Like
+// enrichers, hooks must be idempotent because the same asset can be
+// processed several times. Hooks are also called when the operations are
+// planned without writing to the Asset Inventory, for instance by the report
+// command, in which case icli does not write anything.
+type Hooks struct {
+	// BeforeUpsertAsset is called before creating or updating the asset
+	// of payload. It returns the payload to be applied, so it can modify
+	// it. It is called before checking whether the asset changed since it
+	// was last applied, so the returned payload is the one compared. If
+	// it returns an error, the asset is not written.
+	BeforeUpsertAsset func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error)
+
+	// AfterUpsertAsset is called after the asset, its relations and its
+	// enrichments have been written. If it returns an error, the event
+	// fails and it is processed again.
+	AfterUpsertAsset func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error
+
+	// BeforeExpireAsset is called before applying the tombstone of asset
+	// to the Asset Inventory. If it returns an error, no relation of the
+	// asset is expired.
+	BeforeExpireAsset func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error
+
+	// AfterExpireAsset is called after the asset has been expired. It is
+	// not called if the asset is still owned by other teams. If it
+	// returns an error, the tombstone fails and it is processed again.
+	AfterExpireAsset func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error
+}
+
+// Registry is a set of enrichers and hooks. The zero value is an empty
+// registry ready to use. It is safe for concurrent use.
+type Registry struct {
+	mu            sync.RWMutex
+	registrations []registration
+	hooks         []Hooks
+}
+
+// RegisterEnricher registers fn for the assets with the provided type. If
+// assetType is [AnyAssetType], fn is applied to every asset.
+func (r *Registry) RegisterEnricher(assetType vulcan.AssetType, fn Enricher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.registrations = append(r.registrations, registration{assetType, fn})
+}
+
+// Enrich applies the enrichers registered for the type of payload to asset.
+// Enrichers are applied in registration order. Enrich stops at the first
+// error.
+func (r *Registry) Enrich(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+	r.mu.RLock()
+	registrations := r.registrations
+	r.mu.RUnlock()
+
+	for i, reg := range registrations {
+		if reg.assetType != AnyAssetType && reg.assetType != payload.AssetType {
+			continue
+		}
+		if err := reg.fn(icli, asset, payload); err != nil {
+			return fmt.Errorf("enricher %v (%v): %w", i, reg.assetType, err)
+		}
+	}
+	return nil
+}
+
+// RegisterHooks registers h. The hooks of every registration are called in
+// registration order.
+func (r *Registry) RegisterHooks(h Hooks) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, h)
+}
+
+// registeredHooks returns the registered hooks.
+func (r *Registry) registeredHooks() []Hooks {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.hooks
+}
+
+// BeforeUpsertAsset calls the registered BeforeUpsertAsset hooks. Every hook
+// receives the payload returned by the previous one. It returns the payload
+// returned by the last hook and stops at the first error.
+func (r *Registry) BeforeUpsertAsset(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+	for i, h := range r.registeredHooks() {
+		if h.BeforeUpsertAsset == nil {
+			continue
+		}
+		var err error
+		if payload, err = h.BeforeUpsertAsset(icli, payload); err != nil {
+			return vulcan.AssetPayload{}, fmt.Errorf("before upsert hook %v: %w", i, err)
+		}
+	}
+	return payload, nil
+}
+
+// AfterUpsertAsset calls the registered AfterUpsertAsset hooks. It stops at
+// the first error.
+func (r *Registry) AfterUpsertAsset(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+	for i, h := range r.registeredHooks() {
+		if h.AfterUpsertAsset == nil {
+			continue
+		}
+		if err := h.AfterUpsertAsset(icli, asset, payload); err != nil {
+			return fmt.Errorf("after upsert hook %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// BeforeExpireAsset calls the registered BeforeExpireAsset hooks. It stops
+// at the first error.
+func (r *Registry) BeforeExpireAsset(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+	for i, h := range r.registeredHooks() {
+		if h.BeforeExpireAsset == nil {
+			continue
+		}
+		if err := h.BeforeExpireAsset(icli, asset, payload); err != nil {
+			return fmt.Errorf("before expire hook %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// AfterExpireAsset calls the registered AfterExpireAsset hooks. It stops at
+// the first error.
+func (r *Registry) AfterExpireAsset(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+	for i, h := range r.registeredHooks() {
+		if h.AfterExpireAsset == nil {
+			continue
+		}
+		if err := h.AfterExpireAsset(icli, asset, payload); err != nil {
+			return fmt.Errorf("after expire hook %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// DefaultRegistry is the registry used by [RegisterEnricher], [Enrich] and
+// [RegisterHooks].
+var DefaultRegistry = &Registry{}
+
+// RegisterEnricher registers fn in [DefaultRegistry]. It is meant to be
+// called from the init functions of the packages providing enrichers.
+func RegisterEnricher(assetType vulcan.AssetType, fn Enricher) {
+	DefaultRegistry.RegisterEnricher(assetType, fn)
+}
+
+// Enrich applies the enrichers of [DefaultRegistry].
+func Enrich(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+	return DefaultRegistry.Enrich(icli, asset, payload)
+}
+
+// RegisterHooks registers h in [DefaultRegistry]. It is meant to be called
+// from the init functions of the packages providing hooks.
+func RegisterHooks(h Hooks) {
+	DefaultRegistry.RegisterHooks(h)
+}
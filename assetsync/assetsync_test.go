@@ -0,0 +1,165 @@
+package assetsync
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestRegistryEnrich(t *testing.T) {
+	var got []string
+	enricher := func(name string) Enricher {
+		return func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, name+":"+asset.ID)
+			return nil
+		}
+	}
+
+	var r Registry
+	r.RegisterEnricher("Hostname", enricher("hostname"))
+	r.RegisterEnricher(AnyAssetType, enricher("any"))
+	r.RegisterEnricher("AWSAccount", enricher("aws"))
+
+	tests := []struct {
+		name      string
+		assetType vulcan.AssetType
+		want      []string
+	}{
+		{
+			name:      "type and any",
+			assetType: "Hostname",
+			want:      []string{"hostname:asset-1", "any:asset-1"},
+		},
+		{
+			name:      "any only",
+			assetType: "IP",
+			want:      []string{"any:asset-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			asset := inventory.AssetResp{ID: "asset-1"}
+			if err := r.Enrich(nil, asset, vulcan.AssetPayload{AssetType: tt.assetType}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("applied enrichers mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRegistryEnrichError(t *testing.T) {
+	errEnricher := errors.New("enricher error")
+
+	var called bool
+	var r Registry
+	r.RegisterEnricher(AnyAssetType, func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+		return errEnricher
+	})
+	r.RegisterEnricher(AnyAssetType, func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+		called = true
+		return nil
+	})
+
+	err := r.Enrich(nil, inventory.AssetResp{}, vulcan.AssetPayload{AssetType: "Hostname"})
+	if !errors.Is(err, errEnricher) {
+		t.Errorf("unexpected error: got: %v, want: %v", err, errEnricher)
+	}
+	if called {
+		t.Errorf("enricher called after error")
+	}
+}
+
+func TestRegistryHooks(t *testing.T) {
+	var got []string
+	var r Registry
+	r.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			got = append(got, "before upsert 0:"+payload.Identifier)
+			payload.Identifier = "www." + payload.Identifier
+			return payload, nil
+		},
+		AfterExpireAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, "after expire 0:"+asset.ID)
+			return nil
+		},
+	})
+	r.RegisterHooks(Hooks{})
+	r.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			got = append(got, "before upsert 2:"+payload.Identifier)
+			return payload, nil
+		},
+		AfterUpsertAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, "after upsert 2:"+asset.ID)
+			return nil
+		},
+		BeforeExpireAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, "before expire 2:"+asset.ID)
+			return nil
+		},
+	})
+
+	asset := inventory.AssetResp{ID: "asset-1"}
+	payload, err := r.BeforeUpsertAsset(nil, vulcan.AssetPayload{Identifier: "example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Identifier != "www.example.com" {
+		t.Errorf("unexpected identifier: %v", payload.Identifier)
+	}
+	if err := r.AfterUpsertAsset(nil, asset, payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.BeforeExpireAsset(nil, asset, payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AfterExpireAsset(nil, asset, payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"before upsert 0:example.com",
+		"before upsert 2:www.example.com",
+		"after upsert 2:asset-1",
+		"before expire 2:asset-1",
+		"after expire 0:asset-1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("called hooks mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestRegistryHooksVeto(t *testing.T) {
+	var called bool
+	var r Registry
+	r.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			return vulcan.AssetPayload{}, fmt.Errorf("unsupported identifier: %w", ErrVeto)
+		},
+	})
+	r.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			called = true
+			return payload, nil
+		},
+	})
+
+	_, err := r.BeforeUpsertAsset(nil, vulcan.AssetPayload{Identifier: "example.com"})
+	if !errors.Is(err, ErrVeto) {
+		t.Errorf("unexpected error: got: %v, want: %v", err, ErrVeto)
+	}
+	if called {
+		t.Errorf("hook called after veto")
+	}
+}
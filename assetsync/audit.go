@@ -0,0 +1,222 @@
+package assetsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// AssetState is the state of an asset in the Asset Inventory as seen by the
+// audit mode. Owners are indexed by team ID and parents by parent asset ID.
+type AssetState struct {
+	ID         string
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	Expiration time.Time
+	Owners     map[string]OwnerState
+	Parents    map[string]ParentState
+}
+
+// OwnerState is the state of an owns relation.
+type OwnerState struct {
+	StartTime time.Time
+	EndTime   *time.Time
+}
+
+// ParentState is the state of a parent-of relation.
+type ParentState struct {
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	Expiration time.Time
+}
+
+// Change is a change of a field of an asset.
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// auditEntry is the structured diff logged by the audit mode.
+type auditEntry struct {
+	AssetType  string   `json:"asset_type"`
+	Identifier string   `json:"identifier"`
+	AssetID    string   `json:"asset_id"`
+	Changes    []Change `json:"changes"`
+}
+
+// ReadAssetState returns the state of the asset corresponding to the
+// provided payload. The relations are listed with pages of pageSize
+// entries. It returns nil if the asset does not exist, it is duplicated or
+// its state cannot be retrieved.
+func ReadAssetState(icli inventory.Inventory, payload vulcan.AssetPayload, pageSize int) *AssetState {
+	assets, err := inventory.AllAssets(icli, string(payload.AssetType), payload.Identifier, time.Time{}, pageSize)
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: audit: could not get assets: %v", err)
+		return nil
+	}
+	if len(assets) != 1 {
+		return nil
+	}
+	asset := assets[0]
+
+	owners, err := inventory.AllOwners(icli, asset.ID, pageSize)
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: audit: could not get owners: %v", err)
+		return nil
+	}
+
+	parents, err := inventory.AllParents(icli, asset.ID, pageSize)
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: audit: could not get parents: %v", err)
+		return nil
+	}
+
+	state := &AssetState{
+		ID:         asset.ID,
+		FirstSeen:  asset.FirstSeen,
+		LastSeen:   asset.LastSeen,
+		Expiration: asset.Expiration,
+		Owners:     make(map[string]OwnerState),
+		Parents:    make(map[string]ParentState),
+	}
+	for _, o := range owners {
+		state.Owners[o.TeamID] = OwnerState{StartTime: o.StartTime, EndTime: o.EndTime}
+	}
+	for _, p := range parents {
+		state.Parents[p.ParentID] = ParentState{FirstSeen: p.FirstSeen, LastSeen: p.LastSeen, Expiration: p.Expiration}
+	}
+	return state
+}
+
+// DiffAssetStates returns the changes between two states of an asset. A nil
+// state means that the asset does not exist.
+func DiffAssetStates(before, after *AssetState) []Change {
+	if before == nil && after == nil {
+		return nil
+	}
+	if before == nil {
+		before = &AssetState{}
+	}
+	if after == nil {
+		after = &AssetState{}
+	}
+
+	var changes []Change
+	add := func(field string, b, a any) {
+		changes = append(changes, Change{Field: field, Before: b, After: a})
+	}
+
+	if before.ID != after.ID {
+		add("id", before.ID, after.ID)
+	}
+	if !before.FirstSeen.Equal(after.FirstSeen) {
+		add("first_seen", before.FirstSeen, after.FirstSeen)
+	}
+	if !before.LastSeen.Equal(after.LastSeen) {
+		add("last_seen", before.LastSeen, after.LastSeen)
+	}
+	if !before.Expiration.Equal(after.Expiration) {
+		add("expiration", before.Expiration, after.Expiration)
+	}
+
+	for _, id := range unionKeys(before.Owners, after.Owners) {
+		b, bok := before.Owners[id]
+		a, aok := after.Owners[id]
+		switch {
+		case !bok:
+			add(fmt.Sprintf("owners[%v]", id), nil, a)
+		case !aok:
+			add(fmt.Sprintf("owners[%v]", id), b, nil)
+		default:
+			if !b.StartTime.Equal(a.StartTime) {
+				add(fmt.Sprintf("owners[%v].start_time", id), b.StartTime, a.StartTime)
+			}
+			if !equalTimePtr(b.EndTime, a.EndTime) {
+				add(fmt.Sprintf("owners[%v].end_time", id), b.EndTime, a.EndTime)
+			}
+		}
+	}
+
+	for _, id := range unionKeys(before.Parents, after.Parents) {
+		b, bok := before.Parents[id]
+		a, aok := after.Parents[id]
+		switch {
+		case !bok:
+			add(fmt.Sprintf("parents[%v]", id), nil, a)
+		case !aok:
+			add(fmt.Sprintf("parents[%v]", id), b, nil)
+		default:
+			if !b.FirstSeen.Equal(a.FirstSeen) {
+				add(fmt.Sprintf("parents[%v].first_seen", id), b.FirstSeen, a.FirstSeen)
+			}
+			if !b.LastSeen.Equal(a.LastSeen) {
+				add(fmt.Sprintf("parents[%v].last_seen", id), b.LastSeen, a.LastSeen)
+			}
+			if !b.Expiration.Equal(a.Expiration) {
+				add(fmt.Sprintf("parents[%v].expiration", id), b.Expiration, a.Expiration)
+			}
+		}
+	}
+
+	return changes
+}
+
+// logAuditDiff logs the changes between two states of the asset
+// corresponding to the provided payload. Nothing is logged if there are no
+// changes.
+func logAuditDiff(payload vulcan.AssetPayload, before, after *AssetState) {
+	changes := DiffAssetStates(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	entry := auditEntry{
+		AssetType:  string(payload.AssetType),
+		Identifier: payload.Identifier,
+		Changes:    changes,
+	}
+	if after != nil {
+		entry.AssetID = after.ID
+	} else if before != nil {
+		entry.AssetID = before.ID
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: audit: could not marshal diff: %v", err)
+		return
+	}
+	log.Info.Printf("graph-vulcan-assets: audit: %s", b)
+}
+
+// unionKeys returns the sorted union of the keys of the provided maps.
+func unionKeys[V any](m1, m2 map[string]V) []string {
+	set := make(map[string]bool)
+	for k := range m1 {
+		set[k] = true
+	}
+	for k := range m2 {
+		set[k] = true
+	}
+
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equalTimePtr reports whether two optional times are equal.
+func equalTimePtr(t1, t2 *time.Time) bool {
+	if t1 == nil || t2 == nil {
+		return t1 == t2
+	}
+	return t1.Equal(*t2)
+}
@@ -0,0 +1,128 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestDiffAssetStates(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		before *AssetState
+		after  *AssetState
+		want   []Change
+	}{
+		{
+			name:   "no asset",
+			before: nil,
+			after:  nil,
+			want:   nil,
+		},
+		{
+			name: "no changes",
+			before: &AssetState{
+				ID:       "asset-1",
+				LastSeen: t0,
+				Owners:   map[string]OwnerState{"team-1": {StartTime: t0}},
+			},
+			after: &AssetState{
+				ID:       "asset-1",
+				LastSeen: t0,
+				Owners:   map[string]OwnerState{"team-1": {StartTime: t0}},
+			},
+			want: nil,
+		},
+		{
+			name:   "created",
+			before: nil,
+			after: &AssetState{
+				ID:        "asset-1",
+				FirstSeen: t0,
+				Owners:    map[string]OwnerState{"team-1": {StartTime: t0}},
+			},
+			want: []Change{
+				{Field: "id", Before: "", After: "asset-1"},
+				{Field: "first_seen", Before: time.Time{}, After: t0},
+				{Field: "owners[team-1]", Before: nil, After: OwnerState{StartTime: t0}},
+			},
+		},
+		{
+			name: "updated",
+			before: &AssetState{
+				ID:         "asset-1",
+				LastSeen:   t0,
+				Expiration: t1,
+				Owners:     map[string]OwnerState{"team-1": {StartTime: t0}},
+				Parents:    map[string]ParentState{"asset-2": {Expiration: t1}, "asset-3": {}},
+			},
+			after: &AssetState{
+				ID:         "asset-1",
+				LastSeen:   t1,
+				Expiration: t1,
+				Owners:     map[string]OwnerState{"team-1": {StartTime: t0, EndTime: &t1}},
+				Parents:    map[string]ParentState{"asset-2": {Expiration: t0}, "asset-4": {}},
+			},
+			want: []Change{
+				{Field: "last_seen", Before: t0, After: t1},
+				{Field: "owners[team-1].end_time", Before: (*time.Time)(nil), After: &t1},
+				{Field: "parents[asset-2].expiration", Before: t1, After: t0},
+				{Field: "parents[asset-3]", Before: ParentState{}, After: nil},
+				{Field: "parents[asset-4]", Before: nil, After: ParentState{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffAssetStates(tt.before, tt.after)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("changes mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestGetAssetState(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100}
+	inv := inventorytest.NewInMemory()
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+
+	if state := ReadAssetState(inv, payload, cfg.InventoryPageSize); state != nil {
+		t.Fatalf("unexpected state of missing asset: %#v", state)
+	}
+
+	if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+
+	state := ReadAssetState(inv, payload, cfg.InventoryPageSize)
+	if state == nil {
+		t.Fatal("missing asset state")
+	}
+
+	teams, err := inv.Teams("team-1", inventory.Pagination{})
+	if err != nil || len(teams) != 1 {
+		t.Fatalf("unexpected teams: %v, %v", teams, err)
+	}
+
+	if _, ok := state.Owners[teams[0].ID]; !ok || len(state.Owners) != 1 {
+		t.Errorf("unexpected owners: %v", state.Owners)
+	}
+	if len(state.Parents) != 0 {
+		t.Errorf("unexpected parents: %v", state.Parents)
+	}
+}
@@ -0,0 +1,303 @@
+package assetsync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// assetKey identifies an asset of the Asset Inventory.
+type assetKey struct {
+	typ        vulcan.AssetType
+	identifier string
+}
+
+// assetCache caches the assets that are set as parents of other assets,
+// like AWS accounts, so they are not looked up every time a child asset is
+// processed. Entries are kept for the life of the process and removed when
+// the cached asset expires or is expired by the consumer.
+//
+// It also caches negative lookups, that is, the assets and teams that were
+// not found in the Asset Inventory, for a short TTL. So, repeated
+// tombstones of absent assets, which are common after replaying the topic,
+// do not query the Asset Inventory every time.
+//
+// It is safe for concurrent use. The methods of a nil cache are no-ops.
+type assetCache struct {
+	mu     sync.Mutex
+	assets map[assetKey]inventory.AssetResp
+
+	// clock is used to check whether the cached assets are expired.
+	clock clock
+
+	// negativeTTL is the time the negative lookups are cached. If it is
+	// zero, negative lookups are not cached.
+	negativeTTL time.Duration
+
+	// missingAssets and missingTeams contain the time when the
+	// negative lookups of assets and teams expire.
+	missingAssets map[assetKey]time.Time
+	missingTeams  map[string]time.Time
+
+	// nextSweep is the time when the expired negative lookups are
+	// removed.
+	nextSweep time.Time
+
+	// index contains the assets and teams of the Asset Inventory. If
+	// it is nil, the existence of the entities is not checked against
+	// an index.
+	index *existenceIndex
+}
+
+// newAssetCache returns an empty [assetCache] that caches the negative
+// lookups for the provided TTL and uses clk to check the expiration of the
+// cached assets.
+func newAssetCache(negativeTTL time.Duration, clk clock) *assetCache {
+	return &assetCache{
+		assets:        make(map[assetKey]inventory.AssetResp),
+		clock:         clk,
+		negativeTTL:   negativeTTL,
+		missingAssets: make(map[assetKey]time.Time),
+		missingTeams:  make(map[string]time.Time),
+	}
+}
+
+// get returns the cached asset with the provided type and identifier if it
+// is not expired at the provided time.
+func (c *assetCache) get(typ vulcan.AssetType, identifier string, at time.Time) (inventory.AssetResp, bool) {
+	if c == nil {
+		return inventory.AssetResp{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	key := assetKey{typ, identifier}
+	asset, ok := c.assets[key]
+	if !ok {
+		assetCacheStats.lookup(false)
+		return inventory.AssetResp{}, false
+	}
+	if c.clock.expired(asset.Expiration, at) {
+		delete(c.assets, key)
+		assetCacheStats.lookup(false)
+		return inventory.AssetResp{}, false
+	}
+	assetCacheStats.lookup(true)
+	return asset, true
+}
+
+// now returns the current time according to the clock of the cache. If the
+// cache is nil, the local time is returned.
+func (c *assetCache) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.clock.now()
+}
+
+// set caches the provided asset.
+func (c *assetCache) set(asset inventory.AssetResp) {
+	if c == nil {
+		return
+	}
+
+	c.index.addAsset(vulcan.AssetType(asset.Type), asset.Identifier)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	key := assetKey{vulcan.AssetType(asset.Type), asset.Identifier}
+	c.assets[key] = asset
+	delete(c.missingAssets, key)
+}
+
+// setAssetMissing caches that the asset with the provided type and
+// identifier was not found at the provided time.
+func (c *assetCache) setAssetMissing(typ vulcan.AssetType, identifier string, at time.Time) {
+	if c == nil || c.negativeTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	c.maybeSweep(at)
+	c.missingAssets[assetKey{typ, identifier}] = at.Add(c.negativeTTL)
+}
+
+// setTeamMissing caches that the team with the provided ID was not found at
+// the provided time.
+func (c *assetCache) setTeamMissing(teamID string, at time.Time) {
+	if c == nil || c.negativeTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	c.maybeSweep(at)
+	c.missingTeams[teamID] = at.Add(c.negativeTTL)
+}
+
+// maybeSweep calls [assetCache.sweep] if it has not been called during the
+// last TTL, so the negative lookups of entities that are never looked up
+// again do not accumulate. It must be called with c.mu held.
+func (c *assetCache) maybeSweep(at time.Time) {
+	if at.Before(c.nextSweep) {
+		return
+	}
+	c.sweep(at)
+}
+
+// sweep removes the negative lookups that are expired at the provided time.
+// It must be called with c.mu held.
+func (c *assetCache) sweep(at time.Time) {
+	for k, exp := range c.missingAssets {
+		if !at.Before(exp) {
+			delete(c.missingAssets, k)
+		}
+	}
+	for k, exp := range c.missingTeams {
+		if !at.Before(exp) {
+			delete(c.missingTeams, k)
+		}
+	}
+	c.nextSweep = at.Add(c.negativeTTL)
+}
+
+// assetMissing reports whether the asset with the provided type and
+// identifier is cached as not found at the provided time.
+func (c *assetCache) assetMissing(typ vulcan.AssetType, identifier string, at time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	exp, ok := c.missingAssets[assetKey{typ, identifier}]
+	missing := ok && at.Before(exp)
+	if c.negativeTTL > 0 {
+		negativeCacheStats.lookup(missing)
+	}
+	return missing
+}
+
+// teamMissing reports whether the team with the provided ID is cached as
+// not found at the provided time.
+func (c *assetCache) teamMissing(teamID string, at time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	exp, ok := c.missingTeams[teamID]
+	missing := ok && at.Before(exp)
+	if c.negativeTTL > 0 {
+		negativeCacheStats.lookup(missing)
+	}
+	return missing
+}
+
+// clearMissing removes the negative lookups of the asset with the provided
+// type and identifier and of the team with the provided ID, and adds them
+// to the index.
+func (c *assetCache) clearMissing(typ vulcan.AssetType, identifier, teamID string) {
+	if c == nil {
+		return
+	}
+
+	c.index.addAsset(typ, identifier)
+	c.index.addTeam(teamID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	delete(c.missingAssets, assetKey{typ, identifier})
+	delete(c.missingTeams, teamID)
+}
+
+// assetIndexed reports whether the asset with the provided type and
+// identifier is in the index. If the cache or its index is nil, it returns
+// true.
+func (c *assetCache) assetIndexed(icli inventory.Inventory, typ vulcan.AssetType, identifier string) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+	return c.index.assetExists(icli, typ, identifier)
+}
+
+// teamIndexed reports whether the team with the provided ID is in the
+// index. If the cache or its index is nil, it returns true.
+func (c *assetCache) teamIndexed(icli inventory.Inventory, teamID string) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+	return c.index.teamExists(icli, teamID)
+}
+
+// delete removes the asset with the provided type and identifier from the
+// cache.
+func (c *assetCache) delete(typ vulcan.AssetType, identifier string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.assets), c.missing())
+
+	delete(c.assets, assetKey{typ, identifier})
+}
+
+// missing returns the number of cached negative lookups. It must be called
+// with c.mu held.
+func (c *assetCache) missing() int {
+	return len(c.missingAssets) + len(c.missingTeams)
+}
+
+// resized updates the statistics of the asset caches with the change of
+// the number of cached assets and negative lookups since there were assets
+// and missing, respectively. It must be called with c.mu held.
+func (c *assetCache) resized(assets, missing int) {
+	assetCacheStats.resize(len(c.assets) - assets)
+	negativeCacheStats.resize(c.missing() - missing)
+}
+
+// upsertCachedAsset is like [upsertAsset] but, if the asset is in cache, it
+// is updated using its cached ID, so it is not looked up in the Asset
+// Inventory. If the cached asset does not exist anymore, it falls back to
+// [upsertAsset]. It reports whether the asset has been created.
+func upsertCachedAsset(icli inventory.Inventory, cache *assetCache, payload vulcan.AssetPayload, cfg Config) (inventory.AssetResp, bool, error) {
+	if cached, ok := cache.get(payload.AssetType, payload.Identifier, cache.now()); ok {
+		asset, err := icli.UpdateAsset(cached.ID, string(payload.AssetType), payload.Identifier, time.Now(), inventory.Unexpired)
+		if err == nil {
+			cache.set(asset)
+			return asset, false, nil
+		}
+
+		cache.delete(payload.AssetType, payload.Identifier)
+		if !errors.Is(err, inventory.ErrNotFound) {
+			return inventory.AssetResp{}, false, fmt.Errorf("could not update asset: %w", err)
+		}
+	}
+
+	asset, created, err := upsertAsset(icli, payload, cfg)
+	if err != nil {
+		return inventory.AssetResp{}, false, err
+	}
+	cache.set(asset)
+	return asset, created, nil
+}
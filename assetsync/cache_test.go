@@ -0,0 +1,184 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// countingInventory is an [inventory.Inventory] that counts the calls to
+// Assets.
+type countingInventory struct {
+	inventory.Inventory
+	assetsCalls int
+}
+
+func (inv *countingInventory) Assets(typ, identifier string, validAt time.Time, pag inventory.Pagination) ([]inventory.AssetResp, error) {
+	inv.assetsCalls++
+	return inv.Inventory.Assets(typ, identifier, validAt, pag)
+}
+
+func TestAssetCache(t *testing.T) {
+	now := time.Now()
+
+	cache := newAssetCache(0, clock{})
+	cache.set(inventory.AssetResp{ID: "1", Type: "AWSAccount", Identifier: "a", Expiration: inventory.Unexpired})
+	cache.set(inventory.AssetResp{ID: "2", Type: "AWSAccount", Identifier: "b", Expiration: now.Add(-time.Hour)})
+
+	if asset, ok := cache.get("AWSAccount", "a", now); !ok || asset.ID != "1" {
+		t.Errorf("unexpected cached asset: %v, %v", asset, ok)
+	}
+	if asset, ok := cache.get("AWSAccount", "b", now); ok {
+		t.Errorf("expired asset returned: %v", asset)
+	}
+	if asset, ok := cache.get("Hostname", "a", now); ok {
+		t.Errorf("asset with different type returned: %v", asset)
+	}
+
+	cache.delete("AWSAccount", "a")
+	if asset, ok := cache.get("AWSAccount", "a", now); ok {
+		t.Errorf("deleted asset returned: %v", asset)
+	}
+
+	var nilCache *assetCache
+	nilCache.set(inventory.AssetResp{ID: "1", Type: "AWSAccount", Identifier: "a"})
+	if asset, ok := nilCache.get("AWSAccount", "a", now); ok {
+		t.Errorf("nil cache returned asset: %v", asset)
+	}
+}
+
+func TestUpsertCachedAsset(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100}
+	payload := vulcan.AssetPayload{AssetType: "AWSAccount", Identifier: "arn:aws:iam::111111111111:root"}
+
+	inv := &countingInventory{Inventory: inventorytest.NewInMemory()}
+	cache := newAssetCache(0, clock{})
+
+	first, created, err := upsertCachedAsset(inv, cache, payload, cfg)
+	if err != nil {
+		t.Fatalf("error upserting asset: %v", err)
+	}
+	if !created {
+		t.Errorf("unexpected created: want=true, got=%v", created)
+	}
+	if inv.assetsCalls != 1 {
+		t.Errorf("unexpected number of Assets calls: want=1, got=%v", inv.assetsCalls)
+	}
+
+	second, created, err := upsertCachedAsset(inv, cache, payload, cfg)
+	if err != nil {
+		t.Fatalf("error upserting asset: %v", err)
+	}
+	if created {
+		t.Errorf("unexpected created: want=false, got=%v", created)
+	}
+	if inv.assetsCalls != 1 {
+		t.Errorf("unexpected number of Assets calls: want=1, got=%v", inv.assetsCalls)
+	}
+	if first.ID != second.ID {
+		t.Errorf("asset ID mismatch: first=%v, second=%v", first.ID, second.ID)
+	}
+
+	// A stale entry falls back to the lookup.
+	cache.set(inventory.AssetResp{ID: "stale", Type: "AWSAccount", Identifier: payload.Identifier, Expiration: inventory.Unexpired})
+
+	third, created, err := upsertCachedAsset(inv, cache, payload, cfg)
+	if err != nil {
+		t.Fatalf("error upserting asset: %v", err)
+	}
+	if created {
+		t.Errorf("unexpected created: want=false, got=%v", created)
+	}
+	if inv.assetsCalls != 2 {
+		t.Errorf("unexpected number of Assets calls: want=2, got=%v", inv.assetsCalls)
+	}
+	if third.ID != first.ID {
+		t.Errorf("asset ID mismatch: want=%v, got=%v", first.ID, third.ID)
+	}
+	if cached, ok := cache.get("AWSAccount", payload.Identifier, time.Now()); !ok || cached.ID != first.ID {
+		t.Errorf("unexpected cached asset: %v, %v", cached, ok)
+	}
+}
+
+func TestAssetCacheMissing(t *testing.T) {
+	now := time.Now()
+
+	cache := newAssetCache(time.Minute, clock{})
+	cache.setAssetMissing("Hostname", "example.com", now)
+	cache.setTeamMissing("team-1", now)
+
+	if !cache.assetMissing("Hostname", "example.com", now.Add(30*time.Second)) {
+		t.Error("missing asset not cached")
+	}
+	if cache.assetMissing("Hostname", "example.com", now.Add(time.Minute)) {
+		t.Error("missing asset cached after TTL")
+	}
+	if cache.assetMissing("DomainName", "example.com", now) {
+		t.Error("asset with different type cached as missing")
+	}
+	if !cache.teamMissing("team-1", now) {
+		t.Error("missing team not cached")
+	}
+
+	cache.set(inventory.AssetResp{ID: "1", Type: "Hostname", Identifier: "example.com", Expiration: inventory.Unexpired})
+	if cache.assetMissing("Hostname", "example.com", now) {
+		t.Error("asset cached as missing after set")
+	}
+
+	cache.setAssetMissing("Hostname", "example.org", now)
+	cache.clearMissing("Hostname", "example.org", "team-1")
+	if cache.assetMissing("Hostname", "example.org", now) || cache.teamMissing("team-1", now) {
+		t.Error("negative lookups not cleared")
+	}
+
+	// Expired negative lookups are swept when new ones are added.
+	cache.setAssetMissing("Hostname", "old.example.com", now)
+	cache.setAssetMissing("Hostname", "new.example.com", now.Add(2*time.Minute))
+	if _, ok := cache.missingAssets[assetKey{"Hostname", "old.example.com"}]; ok {
+		t.Error("expired negative lookup not swept")
+	}
+
+	disabled := newAssetCache(0, clock{})
+	disabled.setAssetMissing("Hostname", "example.com", now)
+	if disabled.assetMissing("Hostname", "example.com", now) {
+		t.Error("negative lookup cached with zero TTL")
+	}
+
+	var nilCache *assetCache
+	nilCache.setAssetMissing("Hostname", "example.com", now)
+	if nilCache.assetMissing("Hostname", "example.com", now) {
+		t.Error("nil cache returned missing asset")
+	}
+}
+
+func TestExpireAssetNegativeCache(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100, MissingTeamPolicy: MissingTeamPolicyIgnore}
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+
+	inv := &countingInventory{Inventory: inventorytest.NewInMemory()}
+	cache := newAssetCache(time.Minute, clock{})
+
+	for i := 0; i < 3; i++ {
+		if _, err := expireAsset(inv, cache, payload, cfg); err != nil {
+			t.Fatalf("error expiring asset: %v", err)
+		}
+	}
+	if inv.assetsCalls != 1 {
+		t.Errorf("unexpected number of Assets calls: want=1, got=%v", inv.assetsCalls)
+	}
+
+	// Refreshing the asset clears the negative lookup.
+	if _, _, err := refreshAsset(inv, cache, payload, cfg); err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+	if cache.assetMissing(payload.AssetType, payload.Identifier, time.Now()) {
+		t.Error("asset cached as missing after refresh")
+	}
+}
@@ -0,0 +1,49 @@
+package assetsync
+
+import (
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// serverClock is implemented by the Asset Inventory clients that can
+// estimate the clock of the server, like [inventory.Client].
+type serverClock interface {
+	Now() time.Time
+}
+
+// clock is the source of the current time used to compare the expirations
+// set by the Asset Inventory. The local clock of the host can drift from
+// the clock of the Asset Inventory, so entities are considered expired only
+// when their expiration is older than a skew tolerance.
+//
+// The zero value uses the local clock without tolerance.
+type clock struct {
+	src  serverClock
+	skew time.Duration
+}
+
+// newClock returns the [clock] configured by cfg. If
+// cfg.InventoryServerTime is enabled and icli can estimate the clock of the
+// Asset Inventory server, it is used instead of the local clock.
+func newClock(icli inventory.Inventory, cfg Config) clock {
+	c := clock{skew: cfg.InventoryClockSkew}
+	if sc, ok := icli.(serverClock); ok && cfg.InventoryServerTime {
+		c.src = sc
+	}
+	return c
+}
+
+// now returns the current time.
+func (c clock) now() time.Time {
+	if c.src == nil {
+		return time.Now()
+	}
+	return c.src.Now()
+}
+
+// expired reports whether an entity with the provided expiration is
+// expired at the provided time, tolerating the clock skew.
+func (c clock) expired(expiration, at time.Time) bool {
+	return inventory.IsExpired(expiration, at.Add(-c.skew))
+}
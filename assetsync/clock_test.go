@@ -0,0 +1,98 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+)
+
+// fixedClockInventory is an [inventory.Inventory] whose server clock
+// returns a fixed time.
+type fixedClockInventory struct {
+	inventory.Inventory
+	now time.Time
+}
+
+func (inv fixedClockInventory) Now() time.Time {
+	return inv.now
+}
+
+func TestClockExpired(t *testing.T) {
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		skew       time.Duration
+		expiration time.Time
+		want       bool
+	}{
+		{
+			name:       "expired without skew",
+			skew:       0,
+			expiration: at,
+			want:       true,
+		},
+		{
+			name:       "within skew",
+			skew:       time.Minute,
+			expiration: at.Add(-30 * time.Second),
+			want:       false,
+		},
+		{
+			name:       "beyond skew",
+			skew:       time.Minute,
+			expiration: at.Add(-time.Minute),
+			want:       true,
+		},
+		{
+			name:       "unexpired",
+			skew:       time.Minute,
+			expiration: inventory.Unexpired,
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := clock{skew: tt.skew}
+			if got := c.expired(tt.expiration, at); got != tt.want {
+				t.Errorf("unexpected result: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewClock(t *testing.T) {
+	server := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	inv := fixedClockInventory{Inventory: inventorytest.NewInMemory(), now: server}
+
+	if got := newClock(inv, Config{InventoryServerTime: true}).now(); !got.Equal(server) {
+		t.Errorf("unexpected server time: want=%v got=%v", server, got)
+	}
+
+	if got := newClock(inv, Config{}).now(); time.Since(got) > time.Minute {
+		t.Errorf("unexpected local time: %v", got)
+	}
+
+	// The in-memory inventory cannot estimate the server clock.
+	c := newClock(inventorytest.NewInMemory(), Config{InventoryServerTime: true, InventoryClockSkew: time.Minute})
+	if c.src != nil || c.skew != time.Minute {
+		t.Errorf("unexpected clock: %+v", c)
+	}
+}
+
+func TestAssetCacheClockSkew(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	cache := newAssetCache(0, clock{skew: time.Minute})
+	cache.set(inventory.AssetResp{ID: "1", Type: "AWSAccount", Identifier: "a", Expiration: now.Add(-30 * time.Second)})
+
+	if _, ok := cache.get("AWSAccount", "a", now); !ok {
+		t.Error("asset expired within the skew tolerance should be cached")
+	}
+	if _, ok := cache.get("AWSAccount", "a", now.Add(time.Minute)); ok {
+		t.Error("asset expired beyond the skew tolerance should not be cached")
+	}
+}
@@ -0,0 +1,798 @@
+package assetsync
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Config is the configuration of an [Engine]. The fields that configure
+// the synchronization have the same meaning as the environment variables
+// of the consumer with the same name. The features whose fields are zero
+// are disabled.
+type Config struct {
+	// InventoryPageSize is the page size of the list requests sent to
+	// the Asset Inventory.
+	InventoryPageSize int
+
+	// InventoryParallelism is the maximum number of concurrent requests
+	// sent to the Asset Inventory to expire the relations of an asset.
+	InventoryParallelism int
+
+	// InventoryNegativeCacheTTL is the time the assets and teams not found
+	// in the Asset Inventory are cached. Zero disables the cache.
+	InventoryNegativeCacheTTL time.Duration
+
+	// InventoryServerTime enables the use of the clock of the Asset
+	// Inventory server to expire the entities.
+	InventoryServerTime bool
+
+	// InventoryClockSkew is the clock skew tolerated when checking
+	// whether an entity is expired.
+	InventoryClockSkew time.Duration
+
+	// TombstoneIndexTTL is the time the existence index used to skip the
+	// tombstones of unknown assets is valid. Zero disables the index.
+	TombstoneIndexTTL time.Duration
+
+	// AssetStateCacheSize is the maximum number of applied events kept in
+	// memory to skip the events of unchanged assets. Zero disables the
+	// cache.
+	AssetStateCacheSize int
+
+	// AssetStateTTL is the time an applied event is considered current.
+	AssetStateTTL time.Duration
+
+	// AWSAccountAnnotationKeys are the keys of the annotations that
+	// reference the AWS account of an asset, in order of preference.
+	AWSAccountAnnotationKeys []string
+
+	// AliasAssetType is the type of the assets created for the aliases of
+	// the assets. Empty disables the aliases.
+	AliasAssetType string
+
+	// AuditDiff enables logging the changes applied to every asset.
+	AuditDiff bool
+
+	// DeriveIPRanges enables setting the IP range of the IP assets as
+	// their parent.
+	DeriveIPRanges bool
+
+	// MissingTeamPolicy is the policy applied to the tombstones of the
+	// assets whose team does not exist: [MissingTeamPolicyIgnore] or
+	// [MissingTeamPolicyExpire].
+	MissingTeamPolicy string
+
+	// NormalizeAssetTypes are the asset types whose identifiers are
+	// normalized. See [NormalizedAssetTypes].
+	NormalizeAssetTypes []vulcan.AssetType
+
+	// OwnershipTransferWindow is the maximum time between the events of
+	// an asset that are considered an ownership transfer.
+	OwnershipTransferWindow time.Duration
+
+	// TeamMapping maps Vulcan teams to teams of the Security Graph.
+	TeamMapping TeamMapping
+
+	// VulcanIDs, if not nil, stores the Vulcan IDs of the assets and
+	// teams.
+	VulcanIDs VulcanIDStore
+
+	// Provenance, if not nil, stores the provenance of the relations.
+	Provenance ProvenanceStore
+
+	// Version is the version recorded in the provenance of the
+	// relations.
+	Version string
+
+	// States, if not nil, persists the states of the applied events, so
+	// the redelivered events of unchanged assets are skipped after a
+	// restart.
+	States StateStore
+
+	// Processed, if not nil, returns the offsets processed before a
+	// restart. Only the states of the redelivered events are checked.
+	Processed checkpoint.Offsets
+
+	// Registry contains the enrichers and hooks applied to the assets.
+	// If nil, [DefaultRegistry] is used.
+	Registry *Registry
+
+	// Observer, if not nil, is notified of the assets created, updated
+	// and expired.
+	Observer Observer
+}
+
+// registry returns the registry of the configuration.
+func (cfg Config) registry() *Registry {
+	if cfg.Registry == nil {
+		return DefaultRegistry
+	}
+	return cfg.Registry
+}
+
+// observer returns the observer of the configuration.
+func (cfg Config) observer() Observer {
+	if cfg.Observer == nil {
+		return nopObserver{}
+	}
+	return cfg.Observer
+}
+
+// Observer is notified of the changes applied to the Asset Inventory by an
+// [Engine]. It must be safe for concurrent use.
+type Observer interface {
+	// AssetCreated is called when the asset of payload is created.
+	AssetCreated(payload vulcan.AssetPayload)
+
+	// AssetUpdated is called when the asset of payload is updated.
+	AssetUpdated(payload vulcan.AssetPayload)
+
+	// AssetExpired is called when the asset of payload is expired.
+	AssetExpired(payload vulcan.AssetPayload)
+}
+
+// nopObserver is an [Observer] that does nothing.
+type nopObserver struct{}
+
+func (nopObserver) AssetCreated(vulcan.AssetPayload) {}
+func (nopObserver) AssetUpdated(vulcan.AssetPayload) {}
+func (nopObserver) AssetExpired(vulcan.AssetPayload) {}
+
+// VulcanIDStore stores the Vulcan IDs of the entities of the Security Graph.
+// It is implemented by [props.Store].
+type VulcanIDStore interface {
+	SetAsset(id string, props map[string]string) error
+	SetTeam(id string, props map[string]string) error
+}
+
+// Engine applies asset events to an Asset Inventory. The parent assets
+// derived from the events are cached for the life of the engine. If
+// [Config.AssetStateCacheSize] is not zero, the events of assets that have
+// not changed since they were last applied are skipped. The events of the
+// same asset are processed one at a time, even if the engine is called
+// concurrently. It is safe for concurrent use.
+type Engine struct {
+	icli     inventory.Inventory
+	cfg      Config
+	cache    *assetCache
+	states   *stateCache
+	pstates  *persistedStates
+	inflight *inflightAssets
+}
+
+// NewEngine returns an [Engine] that applies the events to icli with the
+// provided configuration.
+func NewEngine(icli inventory.Inventory, cfg Config) *Engine {
+	cache := newAssetCache(cfg.InventoryNegativeCacheTTL, newClock(icli, cfg))
+	cache.index = newExistenceIndex(cfg.TombstoneIndexTTL, cfg.InventoryPageSize)
+
+	var pstates *persistedStates
+	if cfg.States != nil {
+		pstates = newPersistedStates(cfg.States, cfg.Processed, cfg.AssetStateTTL, cfg.InventoryPageSize)
+	}
+
+	return &Engine{
+		icli:     icli,
+		cfg:      cfg,
+		cache:    cache,
+		states:   newStateCache(cfg.AssetStateCacheSize, cfg.AssetStateTTL),
+		pstates:  pstates,
+		inflight: newInflightAssets(),
+	}
+}
+
+// Handle applies the event of payload to the Asset Inventory. If isNil is
+// true, the event is a tombstone and the asset is expired. Otherwise, the
+// asset is created or updated. The BeforeUpsertAsset hooks are called
+// before checking whether the asset changed, so the payload they return
+// is the one compared with the last applied event. The events vetoed by a
+// hook are considered processed. Handle implements [vulcan.AssetHandler].
+func (e *Engine) Handle(payload vulcan.AssetPayload, isNil bool) error {
+	cfg := e.cfg
+	payload = NormalizePayload(payload, cfg.NormalizeAssetTypes)
+	inv := withProvenance(e.icli, cfg.Provenance, cfg.Version, payload.Position)
+
+	unlock := e.inflight.lock(payload.AssetType, payload.Identifier)
+	defer unlock()
+
+	if !isNil {
+		hooked, err := cfg.registry().BeforeUpsertAsset(inv, payload)
+		if errors.Is(err, ErrVeto) {
+			vetoed(payload, isNil, err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not run hooks: %w", err)
+		}
+		payload = hooked
+
+		if e.states.unchanged(payload, time.Now()) {
+			log.Debug.Printf("graph-vulcan-assets: skipping unchanged asset %v/%v", payload.AssetType, payload.Identifier)
+			unchangedAssetsTotal.Inc()
+			return nil
+		}
+
+		unchanged, err := e.pstates.unchanged(e.icli, payload, time.Now())
+		if err != nil {
+			return fmt.Errorf("could not check asset state: %w", err)
+		}
+		if unchanged {
+			log.Debug.Printf("graph-vulcan-assets: skipping unchanged asset %v/%v", payload.AssetType, payload.Identifier)
+			unchangedAssetsTotal.Inc()
+			return nil
+		}
+	}
+
+	if cfg.AuditDiff {
+		before := ReadAssetState(e.icli, payload, cfg.InventoryPageSize)
+		defer func() {
+			logAuditDiff(payload, before, ReadAssetState(e.icli, payload, cfg.InventoryPageSize))
+		}()
+	}
+
+	if isNil {
+		// The asset could be cached as the parent of
+		// other assets.
+		e.cache.delete(payload.AssetType, payload.Identifier)
+		e.states.delete(payload.AssetType, payload.Identifier)
+		asset, err := expireAsset(inv, e.cache, payload, cfg)
+		if errors.Is(err, ErrVeto) {
+			// The asset is kept, so its persisted state is
+			// still the one of the last applied event.
+			vetoed(payload, isNil, err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not expire asset: %w", err)
+		}
+		if asset.ID != "" {
+			if err := e.pstates.clear(asset); err != nil {
+				return fmt.Errorf("could not clear asset state: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// The state is removed before applying the event, so it is
+	// applied again if it fails.
+	e.states.delete(payload.AssetType, payload.Identifier)
+	applied := time.Now()
+	asset, team, err := refreshAsset(inv, e.cache, payload, cfg)
+	if err != nil {
+		return fmt.Errorf("could not refresh asset: %w", err)
+	}
+	if err := e.pstates.set(asset, team, payload, applied); err != nil {
+		return fmt.Errorf("could not persist asset state: %w", err)
+	}
+	e.states.set(payload, applied)
+
+	return nil
+}
+
+// Apply applies the event of payload to icli, which is usually a wrapper
+// of the Asset Inventory of the engine that records or plans the writes.
+// Unlike [Engine.Handle], the event is applied even if the asset did not
+// change, the states of the applied events are not updated and the vetoed
+// events return an error wrapping [ErrVeto]. The caches of the engine are
+// shared with [Engine.Handle].
+func (e *Engine) Apply(icli inventory.Inventory, payload vulcan.AssetPayload, isNil bool) error {
+	cfg := e.cfg
+	payload = NormalizePayload(payload, cfg.NormalizeAssetTypes)
+
+	if isNil {
+		e.cache.delete(payload.AssetType, payload.Identifier)
+		_, err := expireAsset(icli, e.cache, payload, cfg)
+		return err
+	}
+
+	payload, err := cfg.registry().BeforeUpsertAsset(icli, payload)
+	if err != nil {
+		return fmt.Errorf("could not run hooks: %w", err)
+	}
+	_, _, err = refreshAsset(icli, e.cache, payload, cfg)
+	return err
+}
+
+// vetoed logs and counts an event vetoed by a hook.
+func vetoed(payload vulcan.AssetPayload, isNil bool, err error) {
+	operation := "upsert"
+	if isNil {
+		operation = "expire"
+	}
+	vetoedEventsTotal.Inc(operation)
+	log.Info.Printf("graph-vulcan-assets: %v of asset %v/%v vetoed: %v", operation, payload.AssetType, payload.Identifier, err)
+}
+
+// refreshAsset is called when an asset is created or updated. It takes care of
+// refreshing its time attributes, as well as its parent-of and owns relations.
+// If cfg.VulcanIDs is not nil, the Vulcan IDs of the asset and its team are
+// stored. The AfterUpsertAsset hooks of cfg.Registry are called after
+// writing the asset, the BeforeUpsertAsset hooks must be called by the
+// caller. It returns the refreshed asset and the team that owns it.
+func refreshAsset(icli inventory.Inventory, cache *assetCache, payload vulcan.AssetPayload, cfg Config) (inventory.AssetResp, inventory.TeamResp, error) {
+	vids := cfg.VulcanIDs
+
+	if err := validateIdentifier(payload); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("invalid identifier: %w", err)
+	}
+
+	asset, _, err := upsertAsset(icli, payload, cfg)
+	if err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not upsert asset: %w", err)
+	}
+
+	team, err := upsertTeam(icli, payload, cfg)
+	if err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not upsert team: %w", err)
+	}
+
+	// The asset and the team exist now.
+	cache.clearMissing(payload.AssetType, payload.Identifier, team.Identifier)
+
+	// The owners are only needed to detect ownership transfers.
+	var owners []inventory.OwnsResp
+	if cfg.OwnershipTransferWindow > 0 {
+		if owners, err = inventory.AllOwners(icli, asset.ID, cfg.InventoryPageSize); err != nil {
+			return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not get owners: %w", err)
+		}
+	}
+
+	if err := setOwner(icli, asset, team); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not set owner: %w", err)
+	}
+
+	at := messageTime(payload, time.Now())
+	if from, ok := acquiredOwnership(owners, team.ID, at, cfg.OwnershipTransferWindow); ok {
+		reportOwnershipTransfer(payload, asset, from.TeamID, team.ID, at)
+	}
+
+	if vids != nil {
+		if err := setVulcanIDs(vids, asset, team, payload, cfg.TeamMapping); err != nil {
+			return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not set Vulcan IDs: %w", err)
+		}
+	}
+
+	if err := setAlias(icli, asset, team, payload, cfg); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not set alias: %w", err)
+	}
+
+	if err := builtinEnrichers(cfg, cache, vids).Enrich(icli, asset, payload); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not enrich asset: %w", err)
+	}
+
+	if err := cfg.registry().Enrich(icli, asset, payload); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not enrich asset: %w", err)
+	}
+
+	if cfg.DeriveIPRanges && payload.AssetType == ipAssetType {
+		if err := setIPRange(icli, asset, cfg); err != nil {
+			return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not set IP range: %w", err)
+		}
+	}
+
+	if err := cfg.registry().AfterUpsertAsset(icli, asset, payload); err != nil {
+		return inventory.AssetResp{}, inventory.TeamResp{}, fmt.Errorf("could not run hooks: %w", err)
+	}
+
+	return asset, team, nil
+}
+
+// upsertAsset creates an asset if it does not exist. If it exists, it updates
+// its time attributes. It returns the created or updated asset and reports
+// whether it has been created.
+func upsertAsset(icli inventory.Inventory, payload vulcan.AssetPayload, cfg Config) (inventory.AssetResp, bool, error) {
+	assets, err := inventory.AllAssets(icli, string(payload.AssetType), payload.Identifier, time.Time{}, cfg.InventoryPageSize)
+	if err != nil {
+		return inventory.AssetResp{}, false, fmt.Errorf("could not get assets: %w", err)
+	}
+
+	switch len(assets) {
+	case 1:
+		asset, err := icli.UpdateAsset(assets[0].ID, string(payload.AssetType), payload.Identifier, time.Now(), inventory.Unexpired)
+		if err != nil {
+			return inventory.AssetResp{}, false, fmt.Errorf("could not update asset: %w", err)
+		}
+		cfg.observer().AssetUpdated(payload)
+		return asset, false, nil
+	case 0:
+		asset, err := icli.CreateAsset(string(payload.AssetType), payload.Identifier, time.Now(), inventory.Unexpired)
+		if err != nil {
+			return inventory.AssetResp{}, false, fmt.Errorf("could not create asset: %w", err)
+		}
+		createdAssetsTotal.Inc()
+		cfg.observer().AssetCreated(payload)
+		return asset, true, nil
+	}
+
+	duplicatedAssetsTotal.Inc(string(payload.AssetType), payload.Team.ID)
+	return inventory.AssetResp{}, false, errors.New("duplicated asset")
+}
+
+// upsertTeam creates a team if it does not exist. If it exists, it updates its
+// name. It returns the created or updated team. If the team is mapped to
+// another team in cfg.TeamMapping, the mapped team is upserted instead. Its
+// name is only set when it is created, because it belongs to the Vulcan
+// team with that identifier.
+func upsertTeam(icli inventory.Inventory, payload vulcan.AssetPayload, cfg Config) (inventory.TeamResp, error) {
+	vteam := payload.Team
+
+	id, mapped := cfg.TeamMapping.graphID(vteam.ID)
+	teams, err := inventory.AllTeams(icli, id, cfg.InventoryPageSize)
+	if err != nil {
+		return inventory.TeamResp{}, fmt.Errorf("could not get teams: %w", err)
+	}
+
+	switch len(teams) {
+	case 1:
+		name := vteam.Name
+		if mapped {
+			name = teams[0].Name
+		}
+		team, err := icli.UpdateTeam(teams[0].ID, id, name)
+		if err != nil {
+			return inventory.TeamResp{}, fmt.Errorf("could not update team: %w", err)
+		}
+		return team, nil
+	case 0:
+		team, err := icli.CreateTeam(id, vteam.Name)
+		if err != nil {
+			return inventory.TeamResp{}, fmt.Errorf("could not create team: %w", err)
+		}
+		return team, nil
+	default:
+		duplicatedTeamsTotal.Inc(id)
+		return inventory.TeamResp{}, errors.New("duplicated team")
+	}
+}
+
+// setVulcanIDs stores the Vulcan IDs of an asset and its team as properties
+// of the asset and the team. The alias of the asset and the tag and the
+// description of the team, which are not part of the models of the Asset
+// Inventory API, are stored as properties too. The properties of the teams mapped
+// to other teams in tmap are not stored, so they do not overwrite the ones
+// of the Vulcan team of the mapped team.
+func setVulcanIDs(vids VulcanIDStore, asset inventory.AssetResp, team inventory.TeamResp, payload vulcan.AssetPayload, tmap TeamMapping) error {
+	assetProps := map[string]string{
+		props.VulcanAssetIDKey:    payload.ID,
+		props.VulcanAssetAliasKey: payload.Alias,
+	}
+	if err := vids.SetAsset(asset.ID, assetProps); err != nil {
+		return fmt.Errorf("could not set asset ID: %w", err)
+	}
+	if _, mapped := tmap.graphID(payload.Team.ID); mapped {
+		return nil
+	}
+	teamProps := map[string]string{
+		props.VulcanTeamIDKey:          payload.Team.ID,
+		props.VulcanTeamTagKey:         payload.Team.Tag,
+		props.VulcanTeamDescriptionKey: payload.Team.Description,
+	}
+	if err := vids.SetTeam(team.ID, teamProps); err != nil {
+		return fmt.Errorf("could not set team ID: %w", err)
+	}
+	return nil
+}
+
+// setOwner sets the owner of an assset. If the owns relation already exists,
+// the original [inventory.OwnsResp.StartTime] is used.
+func setOwner(icli inventory.Inventory, asset inventory.AssetResp, team inventory.TeamResp) error {
+	startTime := time.Now()
+	owner, err := icli.Owner(asset.ID, team.ID)
+	switch {
+	case err == nil:
+		startTime = owner.StartTime
+	case !errors.Is(err, inventory.ErrNotFound):
+		return fmt.Errorf("could not get owner: %w", err)
+	}
+
+	if _, err := icli.UpsertOwner(asset.ID, team.ID, startTime, time.Time{}); err != nil {
+		return fmt.Errorf("could not upsert owner: %w", err)
+	}
+
+	return nil
+}
+
+// builtinEnrichers returns the registry with the enrichers shipped with
+// graph-vulcan-assets. They are applied before the enrichers registered in
+// [Config.Registry]. The parent assets set by the enrichers are
+// cached in cache. If vids is not nil, the parent assets are marked as
+// derived.
+func builtinEnrichers(cfg Config, cache *assetCache, vids VulcanIDStore) *Registry {
+	r := &Registry{}
+	r.RegisterEnricher(AnyAssetType, awsAccountEnricher(cfg, cache, vids))
+	return r
+}
+
+// awsAccountEnricher returns an enricher that sets the AWS account referenced
+// by the annotations with keys cfg.AWSAccountAnnotationKeys as parent of the
+// asset. The keys are checked in order and only the annotations with the
+// first key present in the asset are used. If vids is not nil, the AWS
+// accounts are marked as derived.
+func awsAccountEnricher(cfg Config, cache *assetCache, vids VulcanIDStore) Enricher {
+	return func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+		for _, key := range cfg.AWSAccountAnnotationKeys {
+			var found bool
+			for _, a := range payload.Annotations {
+				if a.Key != key {
+					continue
+				}
+				found = true
+				if err := setAWSAccount(icli, cache, vids, asset, a.Value, cfg); err != nil {
+					return fmt.Errorf("could not set AWS account: %w", err)
+				}
+				awsAccountAnnotationsTotal.Inc(key)
+			}
+			if found {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// setAWSAccount sets the parent AWS account of an assset. It takes care of
+// normalizing the AWS account ID, so it always has the long format
+// "arn:aws:iam::000000000000:root". The AWS account asset is cached, so it
+// is not looked up for every child asset. If vids is not nil, the AWS
+// account is marked as derived when it is created by the consumer, so the
+// AWS accounts received from Vulcan or created before are never marked, and
+// the expire-derived command of graph-vulcan-assets can expire them when
+// they have no active children.
+func setAWSAccount(icli inventory.Inventory, cache *assetCache, vids VulcanIDStore, asset inventory.AssetResp, awsAccount string, cfg Config) error {
+	normAWSAccount, err := normalizeAWSAccountID(awsAccount)
+	if err != nil {
+		return fmt.Errorf("could not normalize AWS account ID: %w", err)
+	}
+
+	payload := vulcan.AssetPayload{
+		Identifier: normAWSAccount,
+		AssetType:  vulcan.AssetType("AWSAccount"),
+	}
+	assetAWSAccount, created, err := upsertCachedAsset(icli, cache, payload, cfg)
+	if err != nil {
+		return fmt.Errorf("could not upsert AWS account: %w", err)
+	}
+
+	if vids != nil && created {
+		if err := vids.SetAsset(assetAWSAccount.ID, map[string]string{props.DerivedKey: "true"}); err != nil {
+			return fmt.Errorf("could not mark AWS account as derived: %w", err)
+		}
+	}
+
+	if _, err := icli.UpsertParent(asset.ID, assetAWSAccount.ID, time.Now(), inventory.Unexpired); err != nil {
+		return fmt.Errorf("could not upsert parent: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	shortAWSAccountRe = regexp.MustCompile(`^[0-9]{12}$`)
+	longAWSAccountRe  = regexp.MustCompile(`^arn:aws:iam::[0-9]{12}:root$`)
+)
+
+// normalizeAWSAccountID normalizes the provided AWS account ID. The returned
+// ID will always follows the format "arn:aws:iam::000000000000:root".
+func normalizeAWSAccountID(id string) (string, error) {
+	if longAWSAccountRe.MatchString(id) {
+		return id, nil
+	}
+
+	if shortAWSAccountRe.MatchString(id) {
+		return fmt.Sprintf("arn:aws:iam::%v:root", id), nil
+	}
+
+	return "", fmt.Errorf("invalid AWS account id format: %v", id)
+}
+
+// Policies applied to the tombstones of the assets whose team does not exist
+// in the Asset Inventory. See [Config.MissingTeamPolicy].
+const (
+	MissingTeamPolicyIgnore = "ignore"
+	MissingTeamPolicyExpire = "expire"
+)
+
+// Outcomes of the processing of a tombstone.
+const (
+	tombstoneAssetNotFound       = "asset_not_found"
+	tombstoneAssetDeleted        = "asset_deleted"
+	tombstoneTeamNotFound        = "team_not_found_ignored"
+	tombstoneTeamNotFoundOwned   = "team_not_found_owned"
+	tombstoneTeamNotFoundExpired = "team_not_found_expired"
+	tombstoneOwned               = "owned"
+	tombstoneExpired             = "expired"
+)
+
+// expireAsset expires the provided asset, which means:
+//
+//   - The owns relation with the specific team is expired.
+//   - If all the owns relations are expired, the asset is expired.
+//   - If the asset is expired, all its parent-of relations are expired (both
+//     ingoing and outgoing).
+//
+// If the team does not exist, nothing is done unless cfg.MissingTeamPolicy
+// is "expire". In that case, the asset is expired if it is not owned by any
+// other team. The assets and teams that are not found are cached in cache as
+// negative lookups.
+//
+// The relations are expired with at most cfg.InventoryParallelism concurrent
+// requests. The asset is expired only after all its relations have been
+// expired, so a failed expiration can be retried safely. The expiration
+// time is taken from the clock of cache, except for the owns relation of an
+// asset transferred to another team, which is expired at the time of the
+// tombstone.
+//
+// It returns the asset found in the Asset Inventory, even if the expiration
+// fails, or the zero value if it was not found.
+func expireAsset(icli inventory.Inventory, cache *assetCache, payload vulcan.AssetPayload, cfg Config) (inventory.AssetResp, error) {
+	now := cache.now()
+
+	if cache.assetMissing(payload.AssetType, payload.Identifier, now) {
+		negativeCacheHitsTotal.Inc("asset")
+		tombstonesTotal.Inc(tombstoneAssetNotFound)
+		return inventory.AssetResp{}, nil
+	}
+
+	indexed, err := cache.assetIndexed(icli, payload.AssetType, payload.Identifier)
+	if err != nil {
+		return inventory.AssetResp{}, fmt.Errorf("could not check asset index: %w", err)
+	}
+	if !indexed {
+		existenceIndexHitsTotal.Inc("asset")
+		tombstonesTotal.Inc(tombstoneAssetNotFound)
+		return inventory.AssetResp{}, nil
+	}
+
+	assets, err := inventory.AllAssets(icli, string(payload.AssetType), payload.Identifier, time.Time{}, cfg.InventoryPageSize)
+	if err != nil {
+		return inventory.AssetResp{}, fmt.Errorf("could not get assets: %w", err)
+	}
+
+	if len(assets) == 0 {
+		// The asset does not exist, so nothing needs to be done.
+		cache.setAssetMissing(payload.AssetType, payload.Identifier, now)
+		tombstonesTotal.Inc(tombstoneAssetNotFound)
+		return inventory.AssetResp{}, nil
+	}
+	if len(assets) > 1 {
+		duplicatedAssetsTotal.Inc(string(payload.AssetType), payload.Team.ID)
+		return inventory.AssetResp{}, errors.New("duplicated asset")
+	}
+
+	if assets[0].Deleted {
+		// The asset has been soft deleted in the Asset Inventory, so
+		// its relations cannot be modified and there is nothing to
+		// expire.
+		tombstonesTotal.Inc(tombstoneAssetDeleted)
+		return assets[0], nil
+	}
+
+	if err := cfg.registry().BeforeExpireAsset(icli, assets[0], payload); err != nil {
+		return assets[0], fmt.Errorf("could not run hooks: %w", err)
+	}
+
+	teamIdentifier, _ := cfg.TeamMapping.graphID(payload.Team.ID)
+
+	teamIndexed, err := cache.teamIndexed(icli, teamIdentifier)
+	if err != nil {
+		return assets[0], fmt.Errorf("could not check team index: %w", err)
+	}
+
+	var teams []inventory.TeamResp
+	switch {
+	case cache.teamMissing(teamIdentifier, now):
+		negativeCacheHitsTotal.Inc("team")
+	case !teamIndexed:
+		existenceIndexHitsTotal.Inc("team")
+	default:
+		teams, err = inventory.AllTeams(icli, teamIdentifier, cfg.InventoryPageSize)
+		if err != nil {
+			return assets[0], fmt.Errorf("could not get teams: %w", err)
+		}
+		if len(teams) == 0 {
+			cache.setTeamMissing(teamIdentifier, now)
+		}
+	}
+
+	if len(teams) > 1 {
+		duplicatedTeamsTotal.Inc(teamIdentifier)
+		return assets[0], errors.New("duplicated team")
+	}
+
+	// If the team does not exist, there is no owns relation to expire.
+	// Depending on the policy, nothing is done or the asset is expired if
+	// it is not owned by any other team.
+	var teamID string
+	if len(teams) == 0 {
+		if cfg.MissingTeamPolicy != MissingTeamPolicyExpire {
+			tombstonesTotal.Inc(tombstoneTeamNotFound)
+			return assets[0], nil
+		}
+	} else {
+		teamID = teams[0].ID
+	}
+
+	// Check if there is any active owns relation end expire owner.
+	owners, err := inventory.AllOwners(icli, assets[0].ID, cfg.InventoryPageSize)
+	if err != nil {
+		return assets[0], fmt.Errorf("error getting owners: %w", err)
+	}
+
+	var (
+		active  bool
+		expired []inventory.OwnsResp
+	)
+	for _, o := range owners {
+		if teamID == "" || o.TeamID != teamID {
+			if o.EndTime == nil {
+				active = true
+			}
+			continue
+		}
+		o.AssetID = assets[0].ID
+		expired = append(expired, o)
+	}
+
+	// If the ownership has been transferred to another team, the owns
+	// relation is expired at the time of the tombstone, so the audit
+	// trail reflects when the transfer happened.
+	end := now
+	from, to, transferred := releasedOwnership(owners, teamID, messageTime(payload, now), cfg.OwnershipTransferWindow)
+	if transferred && !messageTime(payload, now).Before(from.StartTime) {
+		end = messageTime(payload, now)
+	}
+
+	if err := inventory.ExpireOwners(icli, expired, end, cfg.InventoryParallelism); err != nil {
+		return assets[0], fmt.Errorf("could not expire owner: %w", err)
+	}
+
+	if transferred {
+		reportOwnershipTransfer(payload, assets[0], from.TeamID, to.TeamID, end)
+	}
+
+	// If the asset is still owned by a team, we can return because it is
+	// not expired.
+	if active {
+		if teamID == "" {
+			tombstonesTotal.Inc(tombstoneTeamNotFoundOwned)
+		} else {
+			tombstonesTotal.Inc(tombstoneOwned)
+		}
+		return assets[0], nil
+	}
+
+	// Expire parents and children before the asset, so expiring the
+	// asset is the single commit point of the tombstone. If any relation
+	// cannot be expired, the asset is kept and the whole expiration is
+	// retried.
+	if err := inventory.ExpireRelations(icli, []string{assets[0].ID}, now, cfg.InventoryPageSize, cfg.InventoryParallelism); err != nil {
+		return assets[0], fmt.Errorf("error expiring parent-of relations: %w", err)
+	}
+
+	// Expire asset.
+	asset, err := icli.UpdateAsset(assets[0].ID, string(payload.AssetType), payload.Identifier, now, now)
+	if err != nil {
+		return assets[0], fmt.Errorf("could not expire asset: %w", err)
+	}
+	expiredAssetsTotal.Inc()
+	cfg.observer().AssetExpired(payload)
+	if teamID == "" {
+		tombstonesTotal.Inc(tombstoneTeamNotFoundExpired)
+	} else {
+		tombstonesTotal.Inc(tombstoneExpired)
+	}
+
+	if err := cfg.registry().AfterExpireAsset(icli, asset, payload); err != nil {
+		return assets[0], fmt.Errorf("could not run hooks: %w", err)
+	}
+
+	return assets[0], nil
+}
@@ -0,0 +1,827 @@
+package assetsync
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Main(m, containers.Inventory))
+}
+
+// memVulcanIDStore is an in-memory [VulcanIDStore] and [StateStore]. The
+// properties of assets and teams are stored by ID. Like
+// in the Asset Inventory, the properties with an empty value are removed.
+type memVulcanIDStore map[string]map[string]string
+
+func (s memVulcanIDStore) SetAsset(id string, props map[string]string) error {
+	if s[id] == nil {
+		s[id] = make(map[string]string)
+	}
+	for k, v := range props {
+		if v == "" {
+			delete(s[id], k)
+			continue
+		}
+		s[id][k] = v
+	}
+	return nil
+}
+
+func (s memVulcanIDStore) SetTeam(id string, props map[string]string) error {
+	return s.SetAsset(id, props)
+}
+
+func (s memVulcanIDStore) Asset(id string) (map[string]string, error) {
+	props := make(map[string]string)
+	for k, v := range s[id] {
+		props[k] = v
+	}
+	return props, nil
+}
+
+func TestRefreshAssetVulcanIDs(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	cfg := Config{
+		InventoryPageSize: 100,
+		VulcanIDs:         props.NewStore(inv),
+	}
+
+	payload := vulcan.AssetPayload{
+		ID: "vulcan-asset-1",
+		Team: vulcan.Team{
+			ID:          "vulcan-team-1",
+			Name:        "Team 1",
+			Description: "Description of team 1",
+			Tag:         "team-1-tag",
+		},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Alias:      "www",
+	}
+	if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+
+	assets, err := inv.Assets("Hostname", "example.com", time.Time{}, inventory.Pagination{})
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	teams, err := inv.Teams("vulcan-team-1", inventory.Pagination{})
+	if err != nil || len(teams) != 1 {
+		t.Fatalf("unexpected teams: %v, %v", teams, err)
+	}
+
+	assetProps, err := inv.AssetProperties(assets[0].ID)
+	if err != nil {
+		t.Fatalf("error getting asset properties: %v", err)
+	}
+	wantAssetProps := inventory.Properties{
+		props.VulcanAssetIDKey:    "vulcan-asset-1",
+		props.VulcanAssetAliasKey: "www",
+	}
+	if diff := cmp.Diff(wantAssetProps, assetProps); diff != "" {
+		t.Errorf("asset properties mismatch (-want +got):\n%v", diff)
+	}
+
+	teamProps, err := inv.TeamProperties(teams[0].ID)
+	if err != nil {
+		t.Fatalf("error getting team properties: %v", err)
+	}
+	wantTeamProps := inventory.Properties{
+		props.VulcanTeamIDKey:          "vulcan-team-1",
+		props.VulcanTeamTagKey:         "team-1-tag",
+		props.VulcanTeamDescriptionKey: "Description of team 1",
+	}
+	if diff := cmp.Diff(wantTeamProps, teamProps); diff != "" {
+		t.Errorf("team properties mismatch (-want +got):\n%v", diff)
+	}
+
+	// The tag and the description follow the changes of the team in
+	// Vulcan.
+	payload.Team.Tag = ""
+	payload.Team.Description = "New description of team 1"
+	if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+
+	teamProps, err = inv.TeamProperties(teams[0].ID)
+	if err != nil {
+		t.Fatalf("error getting team properties: %v", err)
+	}
+	wantTeamProps = inventory.Properties{
+		props.VulcanTeamIDKey:          "vulcan-team-1",
+		props.VulcanTeamDescriptionKey: "New description of team 1",
+	}
+	if diff := cmp.Diff(wantTeamProps, teamProps); diff != "" {
+		t.Errorf("team properties mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestExpireAssetMissingTeam(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		otherOwner  bool
+		wantExpired bool
+		wantOutcome string
+	}{
+		{
+			name:        "ignore",
+			policy:      MissingTeamPolicyIgnore,
+			otherOwner:  false,
+			wantExpired: false,
+			wantOutcome: tombstoneTeamNotFound,
+		},
+		{
+			name:        "expire owned",
+			policy:      MissingTeamPolicyExpire,
+			otherOwner:  true,
+			wantExpired: false,
+			wantOutcome: tombstoneTeamNotFoundOwned,
+		},
+		{
+			name:        "expire not owned",
+			policy:      MissingTeamPolicyExpire,
+			otherOwner:  false,
+			wantExpired: true,
+			wantOutcome: tombstoneTeamNotFoundExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				MissingTeamPolicy: tt.policy,
+				InventoryPageSize: 100,
+			}
+
+			inv := inventorytest.NewInMemory()
+
+			asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+			if err != nil {
+				t.Fatalf("could not create asset: %v", err)
+			}
+			if tt.otherOwner {
+				team, err := inv.CreateTeam("vulcan-team-other", "Other team")
+				if err != nil {
+					t.Fatalf("could not create team: %v", err)
+				}
+				if _, err := inv.UpsertOwner(asset.ID, team.ID, time.Now(), time.Time{}); err != nil {
+					t.Fatalf("could not create owner: %v", err)
+				}
+			}
+
+			before := tombstonesTotal.Value(tt.wantOutcome)
+
+			payload := vulcan.AssetPayload{
+				Team:       vulcan.Team{ID: "vulcan-team-missing"},
+				AssetType:  "Hostname",
+				Identifier: "example.com",
+			}
+			if _, err := expireAsset(inv, nil, payload, cfg); err != nil {
+				t.Fatalf("error expiring asset: %v", err)
+			}
+
+			assets, err := inv.Assets("Hostname", "example.com", time.Time{}, inventory.Pagination{})
+			if err != nil || len(assets) != 1 {
+				t.Fatalf("unexpected assets: %v, %v", assets, err)
+			}
+			if got := inventory.IsExpired(assets[0].Expiration, time.Now()); got != tt.wantExpired {
+				t.Errorf("unexpected expired: want=%v, got=%v", tt.wantExpired, got)
+			}
+
+			if got := tombstonesTotal.Value(tt.wantOutcome) - before; got != 1 {
+				t.Errorf("unexpected tombstones with outcome %v: want=1, got=%v", tt.wantOutcome, got)
+			}
+		})
+	}
+}
+
+// deletedInventory is an [inventory.Inventory] that marks all the assets
+// as soft deleted.
+type deletedInventory struct {
+	inventory.Inventory
+}
+
+func (inv deletedInventory) Assets(typ, identifier string, validAt time.Time, pag inventory.Pagination) ([]inventory.AssetResp, error) {
+	assets, err := inv.Inventory.Assets(typ, identifier, validAt, pag)
+	for i := range assets {
+		assets[i].Deleted = true
+	}
+	return assets, err
+}
+
+func TestExpireAssetDeleted(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100}
+	inv := inventorytest.NewInMemory()
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "vulcan-team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+	if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+
+	want, err := inventorytest.TakeSnapshot(inv)
+	if err != nil {
+		t.Fatalf("error taking snapshot: %v", err)
+	}
+
+	before := tombstonesTotal.Value(tombstoneAssetDeleted)
+
+	if _, err := expireAsset(deletedInventory{inv}, nil, payload, cfg); err != nil {
+		t.Fatalf("error expiring asset: %v", err)
+	}
+
+	got, err := inventorytest.TakeSnapshot(inv)
+	if err != nil {
+		t.Fatalf("error taking snapshot: %v", err)
+	}
+	if diff := inventorytest.Diff(want, got); diff != "" {
+		t.Errorf("the inventory was modified (-want +got):\n%v", diff)
+	}
+
+	if got := tombstonesTotal.Value(tombstoneAssetDeleted) - before; got != 1 {
+		t.Errorf("unexpected tombstones with outcome %v: want=1, got=%v", tombstoneAssetDeleted, got)
+	}
+}
+
+func TestAWSAccountEnricher(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations []vulcan.Annotation
+		want        []string
+		wantKey     string
+	}{
+		{
+			name: "first key",
+			annotations: []vulcan.Annotation{
+				{Key: "aws/account-id", Value: "222222222222"},
+				{Key: "discovery/aws/account", Value: "111111111111"},
+			},
+			want:    []string{"arn:aws:iam::111111111111:root"},
+			wantKey: "discovery/aws/account",
+		},
+		{
+			name: "second key",
+			annotations: []vulcan.Annotation{
+				{Key: "aws/account-id", Value: "222222222222"},
+			},
+			want:    []string{"arn:aws:iam::222222222222:root"},
+			wantKey: "aws/account-id",
+		},
+		{
+			name: "unknown key",
+			annotations: []vulcan.Annotation{
+				{Key: "gcp/project", Value: "project"},
+			},
+			want:    nil,
+			wantKey: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				AWSAccountAnnotationKeys: []string{"discovery/aws/account", "aws/account-id"},
+				InventoryPageSize:        100,
+			}
+
+			inv := inventorytest.NewInMemory()
+
+			asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+			if err != nil {
+				t.Fatalf("could not create asset: %v", err)
+			}
+
+			before := awsAccountAnnotationsTotal.Value(tt.wantKey)
+
+			payload := vulcan.AssetPayload{
+				AssetType:   "Hostname",
+				Identifier:  "example.com",
+				Annotations: tt.annotations,
+			}
+			if err := awsAccountEnricher(cfg, nil, nil)(inv, asset, payload); err != nil {
+				t.Fatalf("error enriching asset: %v", err)
+			}
+
+			parents, err := inv.Parents(asset.ID, inventory.Pagination{})
+			if err != nil {
+				t.Fatalf("could not get parents: %v", err)
+			}
+
+			var got []string
+			for _, p := range parents {
+				accounts, err := inventory.AllAssets(inv, "AWSAccount", "", time.Time{}, 100)
+				if err != nil {
+					t.Fatalf("could not get AWS accounts: %v", err)
+				}
+				for _, a := range accounts {
+					if a.ID == p.ParentID {
+						got = append(got, a.Identifier)
+					}
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("AWS accounts mismatch (-want +got):\n%v", diff)
+			}
+
+			if tt.wantKey == "" {
+				return
+			}
+			if got := awsAccountAnnotationsTotal.Value(tt.wantKey) - before; got != 1 {
+				t.Errorf("unexpected AWS account annotations for key %v: want=1, got=%v", tt.wantKey, got)
+			}
+		})
+	}
+}
+
+// benchmarkPayloads returns n asset payloads owned by a few teams. Half of
+// the assets have an AWS account annotation.
+func benchmarkPayloads(n int) []vulcan.AssetPayload {
+	payloads := make([]vulcan.AssetPayload, n)
+	for i := range payloads {
+		payloads[i] = vulcan.AssetPayload{
+			Team: vulcan.Team{
+				ID:   fmt.Sprintf("bench-team%v", i%5),
+				Name: fmt.Sprintf("bench-team%v name", i%5),
+			},
+			AssetType:  "Hostname",
+			Identifier: fmt.Sprintf("bench-asset%v.example.com", i),
+		}
+		if i%2 == 0 {
+			payloads[i].Annotations = []vulcan.Annotation{
+				{Key: "discovery/aws/account", Value: fmt.Sprintf("%012d", i%10)},
+			}
+		}
+	}
+	return payloads
+}
+
+// benchmarkRefreshAsset measures the throughput of refreshAsset against
+// icli. The first refresh of every asset is done before starting the timer,
+// so the benchmark measures the steady state, where assets already exist.
+func benchmarkRefreshAsset(b *testing.B, icli inventory.Inventory) {
+	cfg := Config{
+		AWSAccountAnnotationKeys: []string{"discovery/aws/account"},
+		InventoryPageSize:        100,
+	}
+	payloads := benchmarkPayloads(100)
+
+	for _, p := range payloads {
+		if _, _, err := refreshAsset(icli, nil, p, cfg); err != nil {
+			b.Fatalf("error refreshing asset: %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := refreshAsset(icli, nil, payloads[i%len(payloads)], cfg); err != nil {
+			b.Fatalf("error refreshing asset: %v", err)
+		}
+	}
+}
+
+// benchmarkUpsertAsset measures the throughput of upsertAsset against icli.
+func benchmarkUpsertAsset(b *testing.B, icli inventory.Inventory) {
+	cfg := Config{InventoryPageSize: 100}
+	payloads := benchmarkPayloads(100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := upsertAsset(icli, payloads[i%len(payloads)], cfg); err != nil {
+			b.Fatalf("error upserting asset: %v", err)
+		}
+	}
+}
+
+func resetInventory() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := testinfra.WaitGremlin(ctx); err != nil {
+		return err
+	}
+	if err := testinfra.WaitInventory(ctx); err != nil {
+		return err
+	}
+	return testinfra.ResetGraph()
+}
+
+func newBenchmarkInventoryClient(b *testing.B) inventory.Client {
+	if err := resetInventory(); err != nil {
+		b.Fatalf("error resetting inventory: %v", err)
+	}
+
+	icli, err := inventory.NewClient(testinfra.InventoryEndpoint(), true)
+	if err != nil {
+		b.Fatalf("could not create inventory client: %v", err)
+	}
+	return icli
+}
+
+func BenchmarkRefreshAssetInMemory(b *testing.B) {
+	benchmarkRefreshAsset(b, inventorytest.NewInMemory())
+}
+
+func BenchmarkRefreshAssetInventory(b *testing.B) {
+	benchmarkRefreshAsset(b, newBenchmarkInventoryClient(b))
+}
+
+func BenchmarkUpsertAssetInMemory(b *testing.B) {
+	benchmarkUpsertAsset(b, inventorytest.NewInMemory())
+}
+
+func BenchmarkUpsertAssetInventory(b *testing.B) {
+	benchmarkUpsertAsset(b, newBenchmarkInventoryClient(b))
+}
+
+func TestNormalizeAWSAccountID(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantID     string
+		wantNilErr bool
+	}{
+		{
+			name:       "long ID",
+			id:         "arn:aws:iam::123456789012:root",
+			wantID:     "arn:aws:iam::123456789012:root",
+			wantNilErr: true,
+		},
+		{
+			name:       "short ID",
+			id:         "123456789012",
+			wantID:     "arn:aws:iam::123456789012:root",
+			wantNilErr: true,
+		},
+		{
+			name:       "invalid long ID",
+			id:         "arn:aws:iam::12345abc9012:root",
+			wantID:     "",
+			wantNilErr: false,
+		},
+		{
+			name:       "invalid short ID",
+			id:         "12345abc9012",
+			wantID:     "",
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, err := normalizeAWSAccountID(tt.id)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if gotID != tt.wantID {
+				t.Errorf("unexpected ID: want=%v, got=%v", tt.wantID, gotID)
+			}
+		})
+	}
+}
+
+func FuzzNormalizeAWSAccountID(f *testing.F) {
+	for _, seed := range []string{
+		"123456789012",
+		"arn:aws:iam::123456789012:root",
+		"12345678901",
+		"arn:aws:iam::123456789012:user/name",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		got, err := normalizeAWSAccountID(id)
+		if err != nil {
+			return
+		}
+
+		if !longAWSAccountRe.MatchString(got) {
+			t.Errorf("invalid normalized AWS account ID: %q", got)
+		}
+
+		again, err := normalizeAWSAccountID(got)
+		if err != nil || again != got {
+			t.Errorf("normalization is not idempotent: %q -> %q (err=%v)", got, again, err)
+		}
+	})
+}
+
+func TestEngineHooks(t *testing.T) {
+	var got []string
+	registry := &Registry{}
+	registry.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			if payload.Identifier == "vetoed.example.com" {
+				return vulcan.AssetPayload{}, ErrVeto
+			}
+			return payload, nil
+		},
+		AfterUpsertAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, "upsert:"+asset.Identifier)
+			return nil
+		},
+		BeforeExpireAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			if asset.Identifier == "kept.example.com" {
+				return ErrVeto
+			}
+			return nil
+		},
+		AfterExpireAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			got = append(got, "expire:"+asset.Identifier)
+			return nil
+		},
+	})
+
+	cfg := Config{
+		InventoryPageSize: 100,
+		MissingTeamPolicy: MissingTeamPolicyIgnore,
+		Registry:          registry,
+	}
+	inv := inventorytest.NewInMemory()
+	h := NewEngine(inv, cfg).Handle
+
+	team := vulcan.Team{ID: "team-1", Name: "Team 1"}
+	events := []struct {
+		identifier string
+		isNil      bool
+	}{
+		{identifier: "example.com", isNil: false},
+		{identifier: "kept.example.com", isNil: false},
+		{identifier: "vetoed.example.com", isNil: false},
+		{identifier: "example.com", isNil: true},
+		{identifier: "kept.example.com", isNil: true},
+	}
+
+	beforeUpsert := vetoedEventsTotal.Value("upsert")
+	beforeExpire := vetoedEventsTotal.Value("expire")
+	for _, e := range events {
+		payload := vulcan.AssetPayload{Team: team, AssetType: "Hostname", Identifier: e.identifier}
+		if err := h(payload, e.isNil); err != nil {
+			t.Fatalf("error handling %v (isNil=%v): %v", e.identifier, e.isNil, err)
+		}
+	}
+
+	want := []string{"upsert:example.com", "upsert:kept.example.com", "expire:example.com"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("called hooks mismatch (-want +got):\n%v", diff)
+	}
+
+	if assets, err := inv.Assets("Hostname", "vetoed.example.com", time.Time{}, inventory.Pagination{}); err != nil || len(assets) != 0 {
+		t.Errorf("vetoed asset was created: %v, %v", assets, err)
+	}
+	assets, err := inv.Assets("Hostname", "kept.example.com", time.Time{}, inventory.Pagination{})
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	if !inventory.IsUnexpired(assets[0].Expiration) {
+		t.Errorf("vetoed tombstone expired the asset: %v", assets[0].Expiration)
+	}
+
+	if n := vetoedEventsTotal.Value("upsert") - beforeUpsert; n != 1 {
+		t.Errorf("unexpected number of vetoed upserts: %v", n)
+	}
+	if n := vetoedEventsTotal.Value("expire") - beforeExpire; n != 1 {
+		t.Errorf("unexpected number of vetoed expirations: %v", n)
+	}
+}
+
+func TestEngineHookedPayloadState(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterHooks(Hooks{
+		BeforeUpsertAsset: func(icli inventory.Inventory, payload vulcan.AssetPayload) (vulcan.AssetPayload, error) {
+			payload.Annotations = append(payload.Annotations, vulcan.Annotation{Key: "hooked", Value: "true"})
+			return payload, nil
+		},
+	})
+
+	inv := inventorytest.NewInMemory()
+	store := make(memVulcanIDStore)
+	cfg := Config{
+		InventoryPageSize:   100,
+		AssetStateCacheSize: 10,
+		AssetStateTTL:       time.Hour,
+		States:              store,
+		Registry:            registry,
+	}
+	e := NewEngine(inv, cfg)
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+	if err := e.Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	hooked := payload
+	hooked.Annotations = []vulcan.Annotation{{Key: "hooked", Value: "true"}}
+
+	if !e.states.unchanged(hooked, time.Now()) {
+		t.Error("the cached state is not the one of the hooked payload")
+	}
+
+	assets, err := inventory.AllAssets(inv, "Hostname", "example.com", time.Time{}, 100)
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	fp := payloadFingerprint(hooked)
+	if got := store[assets[0].ID][props.AssetStateFingerprintKey]; got != hex.EncodeToString(fp[:]) {
+		t.Errorf("the persisted state is not the one of the hooked payload: %v", got)
+	}
+
+	// The hooks are run before the state is checked, so the redelivered
+	// event is skipped.
+	before := unchangedAssetsTotal.Value()
+	if err := e.Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if n := unchangedAssetsTotal.Value() - before; n != 1 {
+		t.Errorf("unexpected number of unchanged assets: %v", n)
+	}
+}
+
+func TestEngineVetoedTombstoneState(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterHooks(Hooks{
+		BeforeExpireAsset: func(icli inventory.Inventory, asset inventory.AssetResp, payload vulcan.AssetPayload) error {
+			return ErrVeto
+		},
+	})
+
+	inv := &updateCountingInventory{Inventory: inventorytest.NewInMemory()}
+	store := make(memVulcanIDStore)
+	cfg := Config{
+		InventoryPageSize: 100,
+		AssetStateTTL:     time.Hour,
+		States:            store,
+		Registry:          registry,
+	}
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Position:   stream.Position{Topic: vulcan.AssetsEntityName, Partition: 0, Offset: 5},
+	}
+	if err := NewEngine(inv, cfg).Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	// A new engine simulates a restart of the consumer after
+	// processing offset 10.
+	cfg.Processed = checkpoint.Offsets{0: 10}
+	h := NewEngine(inv, cfg).Handle
+
+	tombstone := payload
+	tombstone.Position.Offset = 6
+	if err := h(tombstone, true); err != nil {
+		t.Fatalf("error handling tombstone: %v", err)
+	}
+
+	assets, err := inventory.AllAssets(inv, "Hostname", "example.com", time.Time{}, 100)
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	fp := payloadFingerprint(payload)
+	if got := store[assets[0].ID][props.AssetStateFingerprintKey]; got != hex.EncodeToString(fp[:]) {
+		t.Errorf("the vetoed tombstone cleared the persisted state: %q", got)
+	}
+
+	// The asset was kept, so the redelivered event is still
+	// skipped.
+	before := unchangedAssetsTotal.Value()
+	calls := inv.updateCalls
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.updateCalls != calls {
+		t.Errorf("unchanged asset was applied: want=%v UpdateAsset calls, got=%v", calls, inv.updateCalls)
+	}
+	if n := unchangedAssetsTotal.Value() - before; n != 1 {
+		t.Errorf("unexpected number of unchanged assets: %v", n)
+	}
+}
+
+func TestEngineApply(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	cfg := Config{
+		InventoryPageSize:   100,
+		AssetStateCacheSize: 10,
+		AssetStateTTL:       time.Hour,
+		Registry:            &Registry{},
+	}
+	e := NewEngine(inv, cfg)
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+	if err := e.Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	// Apply does not skip unchanged assets.
+	cinv := &updateCountingInventory{Inventory: inv}
+	if err := e.Apply(cinv, payload, false); err != nil {
+		t.Fatalf("error applying asset: %v", err)
+	}
+	if cinv.updateCalls != 1 {
+		t.Errorf("unexpected number of UpdateAsset calls: %v", cinv.updateCalls)
+	}
+
+	if err := e.Apply(inv, payload, true); err != nil {
+		t.Fatalf("error applying tombstone: %v", err)
+	}
+	assets, err := inventory.AllAssets(inv, "Hostname", "example.com", time.Time{}, 100)
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	if inventory.IsUnexpired(assets[0].Expiration) {
+		t.Error("the asset was not expired")
+	}
+}
+
+func TestSetAWSAccountCachedNotMarked(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100}
+
+	inv := inventorytest.NewInMemory()
+	vids := make(memVulcanIDStore)
+	cache := newAssetCache(0, clock{})
+
+	asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("could not create asset: %v", err)
+	}
+
+	if err := setAWSAccount(inv, cache, vids, asset, "123456789012", cfg); err != nil {
+		t.Fatalf("error setting AWS account: %v", err)
+	}
+	if len(vids) != 1 {
+		t.Fatalf("unexpected properties: %v", vids)
+	}
+
+	// The AWS account is in cache now, so it is not marked again.
+	for id := range vids {
+		delete(vids, id)
+	}
+	if err := setAWSAccount(inv, cache, vids, asset, "123456789012", cfg); err != nil {
+		t.Fatalf("error setting AWS account: %v", err)
+	}
+	if len(vids) != 0 {
+		t.Errorf("unexpected properties: %v", vids)
+	}
+}
+
+func TestSetAWSAccountExistingNotMarked(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100}
+
+	inv := inventorytest.NewInMemory()
+	vids := make(memVulcanIDStore)
+
+	// The AWS account exists before the consumer sees it, for
+	// instance, because Vulcan published it or it was created before
+	// a restart.
+	if _, err := inv.CreateAsset("AWSAccount", "arn:aws:iam::123456789012:root", time.Now(), inventory.Unexpired); err != nil {
+		t.Fatalf("could not create AWS account: %v", err)
+	}
+	asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("could not create asset: %v", err)
+	}
+
+	// A new cache simulates a restart.
+	cache := newAssetCache(0, clock{})
+	if err := setAWSAccount(inv, cache, vids, asset, "123456789012", cfg); err != nil {
+		t.Fatalf("error setting AWS account: %v", err)
+	}
+	if len(vids) != 0 {
+		t.Errorf("unexpected properties: %v", vids)
+	}
+}
@@ -0,0 +1,165 @@
+package assetsync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// existenceIndex contains the identifiers of the assets and teams of the
+// Asset Inventory. The assets of a type, or all the teams, are listed in
+// bulk the first time they are checked and listed again once the index is
+// older than its TTL. So, the tombstones of entities that never existed,
+// which are common when replaying the compacted assets topic, are skipped
+// with a few paginated requests instead of one request per tombstone.
+//
+// The entities created by the consumer are added to the index, so the
+// tombstones that follow their creation are not skipped. The entities
+// created by other writers are only visible after the index is reloaded.
+//
+// It is safe for concurrent use. The methods of a nil index report that
+// every entity exists.
+type existenceIndex struct {
+	ttl      time.Duration
+	pageSize int
+	now      func() time.Time
+
+	mu     sync.Mutex
+	assets map[vulcan.AssetType]*indexedKeys
+	teams  *indexedKeys
+}
+
+// indexedKeys is a set of identifiers listed from the Asset Inventory.
+type indexedKeys struct {
+	loaded time.Time
+	keys   map[string]struct{}
+}
+
+// newExistenceIndex returns an empty [existenceIndex] that is reloaded
+// after the provided TTL. The entities are listed using pages of size
+// pageSize. If ttl is zero, it returns nil.
+func newExistenceIndex(ttl time.Duration, pageSize int) *existenceIndex {
+	if ttl <= 0 {
+		return nil
+	}
+	return &existenceIndex{
+		ttl:      ttl,
+		pageSize: pageSize,
+		now:      time.Now,
+		assets:   make(map[vulcan.AssetType]*indexedKeys),
+	}
+}
+
+// assetExists reports whether the asset with the provided type and
+// identifier is in the index. The assets of the type are listed if they
+// are not indexed or the index is older than its TTL.
+func (idx *existenceIndex) assetExists(icli inventory.Inventory, typ vulcan.AssetType, identifier string) (bool, error) {
+	if idx == nil {
+		return true, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	keys := idx.assets[typ]
+	if idx.stale(keys) {
+		var err error
+		keys, err = idx.load(string(typ), func(f func(string)) error {
+			return inventory.WalkAssets(icli, string(typ), "", time.Time{}, idx.pageSize, func(a inventory.AssetResp) error {
+				f(a.Identifier)
+				return nil
+			})
+		})
+		if err != nil {
+			return false, fmt.Errorf("could not list assets: %w", err)
+		}
+		idx.assets[typ] = keys
+	}
+
+	_, ok := keys.keys[identifier]
+	return ok, nil
+}
+
+// teamExists reports whether the team with the provided identifier is in
+// the index. The teams are listed if they are not indexed or the index is
+// older than its TTL.
+func (idx *existenceIndex) teamExists(icli inventory.Inventory, identifier string) (bool, error) {
+	if idx == nil {
+		return true, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.stale(idx.teams) {
+		keys, err := idx.load("teams", func(f func(string)) error {
+			return inventory.WalkTeams(icli, "", idx.pageSize, func(t inventory.TeamResp) error {
+				f(t.Identifier)
+				return nil
+			})
+		})
+		if err != nil {
+			return false, fmt.Errorf("could not list teams: %w", err)
+		}
+		idx.teams = keys
+	}
+
+	_, ok := idx.teams.keys[identifier]
+	return ok, nil
+}
+
+// stale reports whether keys must be loaded. It must be called with idx.mu
+// held.
+func (idx *existenceIndex) stale(keys *indexedKeys) bool {
+	return keys == nil || idx.now().Sub(keys.loaded) >= idx.ttl
+}
+
+// load returns the keys listed by walk, which calls f with every
+// identifier. name is the name of the listed entities used in the logs. It
+// must be called with idx.mu held.
+func (idx *existenceIndex) load(name string, walk func(f func(string)) error) (*indexedKeys, error) {
+	start := idx.now()
+	keys := &indexedKeys{loaded: start, keys: make(map[string]struct{})}
+	err := walk(func(identifier string) {
+		keys.keys[identifier] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info.Printf("graph-vulcan-assets: indexed %v %v in %v", len(keys.keys), name, idx.now().Sub(start).Round(time.Millisecond))
+	return keys, nil
+}
+
+// addAsset adds the asset with the provided type and identifier to the
+// index if the assets of its type are indexed.
+func (idx *existenceIndex) addAsset(typ vulcan.AssetType, identifier string) {
+	if idx == nil {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if keys := idx.assets[typ]; keys != nil {
+		keys.keys[identifier] = struct{}{}
+	}
+}
+
+// addTeam adds the team with the provided identifier to the index if the
+// teams are indexed.
+func (idx *existenceIndex) addTeam(identifier string) {
+	if idx == nil {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.teams != nil {
+		idx.teams.keys[identifier] = struct{}{}
+	}
+}
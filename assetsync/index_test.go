@@ -0,0 +1,129 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestExistenceIndex(t *testing.T) {
+	inv := &countingInventory{Inventory: inventorytest.NewInMemory()}
+	if _, err := inv.CreateAsset("Hostname", "a.example.com", time.Now(), inventory.Unexpired); err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	if _, err := inv.CreateTeam("team-1", "team-1"); err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+
+	now := time.Now()
+	idx := newExistenceIndex(time.Minute, 100)
+	idx.now = func() time.Time { return now }
+
+	for _, tt := range []struct {
+		typ        vulcan.AssetType
+		identifier string
+		want       bool
+	}{
+		{"Hostname", "a.example.com", true},
+		{"Hostname", "b.example.com", false},
+		{"DomainName", "a.example.com", false},
+	} {
+		got, err := idx.assetExists(inv, tt.typ, tt.identifier)
+		if err != nil {
+			t.Fatalf("error checking asset %v/%v: %v", tt.typ, tt.identifier, err)
+		}
+		if got != tt.want {
+			t.Errorf("unexpected existence of asset %v/%v: want=%v got=%v", tt.typ, tt.identifier, tt.want, got)
+		}
+	}
+	if inv.assetsCalls != 2 {
+		t.Errorf("unexpected number of Assets calls: want=2, got=%v", inv.assetsCalls)
+	}
+
+	// The assets created by other writers are seen after the index is
+	// reloaded.
+	if _, err := inv.CreateAsset("Hostname", "b.example.com", time.Now(), inventory.Unexpired); err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	if ok, err := idx.assetExists(inv, "Hostname", "b.example.com"); err != nil || ok {
+		t.Errorf("asset seen before reload: ok=%v err=%v", ok, err)
+	}
+	now = now.Add(time.Minute)
+	if ok, err := idx.assetExists(inv, "Hostname", "b.example.com"); err != nil || !ok {
+		t.Errorf("asset not seen after reload: ok=%v err=%v", ok, err)
+	}
+
+	// The added assets are seen immediately.
+	idx.addAsset("Hostname", "c.example.com")
+	if ok, err := idx.assetExists(inv, "Hostname", "c.example.com"); err != nil || !ok {
+		t.Errorf("added asset not seen: ok=%v err=%v", ok, err)
+	}
+
+	if ok, err := idx.teamExists(inv, "team-1"); err != nil || !ok {
+		t.Errorf("team not seen: ok=%v err=%v", ok, err)
+	}
+	if ok, err := idx.teamExists(inv, "team-2"); err != nil || ok {
+		t.Errorf("missing team seen: ok=%v err=%v", ok, err)
+	}
+	idx.addTeam("team-2")
+	if ok, err := idx.teamExists(inv, "team-2"); err != nil || !ok {
+		t.Errorf("added team not seen: ok=%v err=%v", ok, err)
+	}
+
+	var nilIndex *existenceIndex
+	nilIndex.addAsset("Hostname", "d.example.com")
+	if ok, err := nilIndex.assetExists(inv, "Hostname", "d.example.com"); err != nil || !ok {
+		t.Errorf("nil index did not report asset as existing: ok=%v err=%v", ok, err)
+	}
+	if idx := newExistenceIndex(0, 100); idx != nil {
+		t.Errorf("index created with zero TTL: %+v", idx)
+	}
+}
+
+func TestExpireAssetExistenceIndex(t *testing.T) {
+	cfg := Config{InventoryPageSize: 100, MissingTeamPolicy: MissingTeamPolicyIgnore}
+	inv := &countingInventory{Inventory: inventorytest.NewInMemory()}
+	cache := newAssetCache(0, clock{})
+	cache.index = newExistenceIndex(time.Hour, cfg.InventoryPageSize)
+
+	// The tombstones of assets that never existed are skipped after
+	// listing the assets once.
+	for _, identifier := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		payload := vulcan.AssetPayload{
+			Team:       vulcan.Team{ID: "team-1"},
+			AssetType:  "Hostname",
+			Identifier: identifier,
+		}
+		if _, err := expireAsset(inv, cache, payload, cfg); err != nil {
+			t.Fatalf("error expiring asset: %v", err)
+		}
+	}
+	if inv.assetsCalls != 1 {
+		t.Errorf("unexpected number of Assets calls: want=1, got=%v", inv.assetsCalls)
+	}
+
+	// The assets created after the index is loaded are expired.
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1"},
+		AssetType:  "Hostname",
+		Identifier: "d.example.com",
+	}
+	asset, _, err := refreshAsset(inv, cache, payload, cfg)
+	if err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+	if _, err := expireAsset(inv, cache, payload, cfg); err != nil {
+		t.Fatalf("error expiring asset: %v", err)
+	}
+
+	owners, err := inventory.AllOwners(inv, asset.ID, cfg.InventoryPageSize)
+	if err != nil {
+		t.Fatalf("error getting owners: %v", err)
+	}
+	if len(owners) != 1 || owners[0].EndTime == nil {
+		t.Errorf("owns relation not expired: %+v", owners)
+	}
+}
@@ -0,0 +1,68 @@
+package assetsync
+
+import (
+	"sync"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// inflightAssets is a registry of the assets whose events are being
+// processed. It serializes the processing of the events of the same asset,
+// so two workers never race to create it in the Asset Inventory, which
+// would leave the asset duplicated. [Engine.Handle] is called
+// concurrently when batching is enabled, by the reconcile workers and by
+// the periodic resync. It is safe for concurrent use.
+type inflightAssets struct {
+	mu    sync.Mutex
+	locks map[assetKey]*inflightLock
+}
+
+// inflightLock is the lock of an asset of [inflightAssets]. refs is the
+// number of workers holding or waiting for the lock, so it is removed from
+// the registry when no worker needs it anymore.
+type inflightLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// newInflightAssets returns an empty [inflightAssets].
+func newInflightAssets() *inflightAssets {
+	return &inflightAssets{locks: make(map[assetKey]*inflightLock)}
+}
+
+// lock blocks until no other worker is processing an event of the asset
+// with the provided type and identifier. It returns a function that must be
+// called once the event is processed.
+func (in *inflightAssets) lock(typ vulcan.AssetType, identifier string) (unlock func()) {
+	key := assetKey{typ, identifier}
+
+	in.mu.Lock()
+	l, ok := in.locks[key]
+	if !ok {
+		l = &inflightLock{}
+		in.locks[key] = l
+	}
+	l.refs++
+	in.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		in.mu.Lock()
+		defer in.mu.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(in.locks, key)
+		}
+	}
+}
+
+// len returns the number of assets being processed.
+func (in *inflightAssets) len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return len(in.locks)
+}
@@ -0,0 +1,44 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInflightAssets(t *testing.T) {
+	in := newInflightAssets()
+
+	unlock := in.lock("Hostname", "example.com")
+
+	acquired := make(chan func())
+	go func() {
+		acquired <- in.lock("Hostname", "example.com")
+	}()
+
+	// Other assets are not blocked.
+	in.lock("Hostname", "other.example.com")()
+	in.lock("DomainName", "example.com")()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while the asset is in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if n := in.len(); n != 1 {
+		t.Errorf("unexpected number of assets in flight: %v", n)
+	}
+
+	unlock()
+
+	select {
+	case unlock = <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock not acquired after unlocking")
+	}
+	unlock()
+
+	if n := in.len(); n != 0 {
+		t.Errorf("unexpected number of assets in flight after unlocking: %v", n)
+	}
+}
@@ -0,0 +1,117 @@
+package assetsync
+
+import (
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/metrics"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Data quality metrics.
+var (
+	duplicatedAssetsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_duplicated_assets_total",
+		"Number of times an asset has been found duplicated in the Asset Inventory.",
+		"asset_type", "team",
+	)
+
+	duplicatedTeamsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_duplicated_teams_total",
+		"Number of times a team has been found duplicated in the Asset Inventory.",
+		"team",
+	)
+)
+
+// Processing metrics.
+var (
+	processedMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_processed_messages_total",
+		"Number of processed messages.",
+	)
+
+	assetEventsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_asset_events_total",
+		"Number of processed messages by asset type and team.",
+		"asset_type", "team",
+	)
+
+	assetErrorsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_asset_errors_total",
+		"Number of messages whose processing failed by asset type and team.",
+		"asset_type", "team",
+	)
+
+	processingErrorsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_processing_errors_total",
+		"Number of messages whose processing failed.",
+	)
+
+	createdAssetsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_created_assets_total",
+		"Number of assets created in the Asset Inventory.",
+	)
+
+	expiredAssetsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_expired_assets_total",
+		"Number of assets expired in the Asset Inventory.",
+	)
+
+	tombstonesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_tombstones_total",
+		"Number of processed tombstones by outcome.",
+		"outcome",
+	)
+
+	negativeCacheHitsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_negative_cache_hits_total",
+		"Number of lookups of assets and teams avoided because they were cached as not found.",
+		"entity",
+	)
+
+	existenceIndexHitsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_existence_index_hits_total",
+		"Number of lookups of assets and teams avoided because they were not in the existence index.",
+		"entity",
+	)
+
+	awsAccountAnnotationsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_aws_account_annotations_total",
+		"Number of AWS accounts set as parent of an asset from an annotation.",
+		"key",
+	)
+
+	unchangedAssetsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_unchanged_assets_total",
+		"Number of asset events skipped because the asset did not change since it was last applied.",
+	)
+
+	ownershipTransfersTotal = metrics.NewCounter(
+		"graph_vulcan_assets_ownership_transfers_total",
+		"Number of assets whose ownership has been transferred from one team to another.",
+		"asset_type",
+	)
+
+	vetoedEventsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_vetoed_events_total",
+		"Number of events skipped because a hook vetoed them by operation.",
+		"operation",
+	)
+)
+
+// CountingHandler returns a [vulcan.AssetHandler] that calls h and counts
+// the processed messages and the processing errors, in total and by asset
+// type and team. The counters and the last error are reported by
+// [Status].
+func CountingHandler(h vulcan.AssetHandler) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		processedMessagesTotal.Inc()
+		assetEventsTotal.Inc(string(payload.AssetType), payload.Team.ID)
+		err := h(payload, isNil)
+		if err != nil {
+			processingErrorsTotal.Inc()
+			assetErrorsTotal.Inc(string(payload.AssetType), payload.Team.ID)
+			lastMessageError.record(payload, err, time.Now())
+		}
+		return err
+	}
+}
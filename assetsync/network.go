@@ -0,0 +1,72 @@
+package assetsync
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Asset types of network assets.
+const (
+	ipAssetType      = vulcan.AssetType("IP")
+	ipRangeAssetType = vulcan.AssetType("IPRange")
+)
+
+// validateIdentifier checks that the identifier of the provided payload is
+// valid. Only the identifiers of IP and IPRange assets are validated.
+func validateIdentifier(payload vulcan.AssetPayload) error {
+	switch payload.AssetType {
+	case ipAssetType:
+		if net.ParseIP(payload.Identifier) == nil {
+			return fmt.Errorf("invalid IP address: %v", payload.Identifier)
+		}
+	case ipRangeAssetType:
+		if _, _, err := net.ParseCIDR(payload.Identifier); err != nil {
+			return fmt.Errorf("invalid CIDR: %v", payload.Identifier)
+		}
+	}
+	return nil
+}
+
+// setIPRange sets the smallest IPRange asset of the Asset Inventory that
+// contains the provided IP asset as its parent. If there is no such
+// IPRange asset, the parents of the IP asset are not modified.
+func setIPRange(icli inventory.Inventory, asset inventory.AssetResp, cfg Config) error {
+	ip := net.ParseIP(asset.Identifier)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address: %v", asset.Identifier)
+	}
+
+	// The IP ranges are walked, so they are not buffered in memory.
+	var (
+		parent inventory.AssetResp
+		maxLen = -1
+	)
+	err := inventory.WalkAssets(icli, string(ipRangeAssetType), "", time.Now(), cfg.InventoryPageSize, func(r inventory.AssetResp) error {
+		_, ipnet, err := net.ParseCIDR(r.Identifier)
+		if err != nil || !ipnet.Contains(ip) {
+			return nil
+		}
+		if ones, _ := ipnet.Mask.Size(); ones > maxLen {
+			parent = r
+			maxLen = ones
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not get IP ranges: %w", err)
+	}
+
+	if maxLen < 0 {
+		return nil
+	}
+
+	if _, err := icli.UpsertParent(asset.ID, parent.ID, time.Now(), inventory.Unexpired); err != nil {
+		return fmt.Errorf("could not upsert parent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        vulcan.AssetType
+		identifier string
+		wantNilErr bool
+	}{
+		{
+			name:       "valid ipv4",
+			typ:        "IP",
+			identifier: "192.0.2.1",
+			wantNilErr: true,
+		},
+		{
+			name:       "valid ipv6",
+			typ:        "IP",
+			identifier: "2001:db8::1",
+			wantNilErr: true,
+		},
+		{
+			name:       "invalid ip",
+			typ:        "IP",
+			identifier: "192.0.2.256",
+			wantNilErr: false,
+		},
+		{
+			name:       "valid cidr",
+			typ:        "IPRange",
+			identifier: "192.0.2.0/24",
+			wantNilErr: true,
+		},
+		{
+			name:       "invalid cidr",
+			typ:        "IPRange",
+			identifier: "192.0.2.0",
+			wantNilErr: false,
+		},
+		{
+			name:       "other type",
+			typ:        "Hostname",
+			identifier: "192.0.2.256",
+			wantNilErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := vulcan.AssetPayload{AssetType: tt.typ, Identifier: tt.identifier}
+			if err := validateIdentifier(payload); (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshAssetIPRange(t *testing.T) {
+	cfg := Config{
+		InventoryPageSize: 100,
+		DeriveIPRanges:    true,
+	}
+
+	inv := inventorytest.NewInMemory()
+
+	ranges := make(map[string]inventory.AssetResp)
+	for _, cidr := range []string{"192.0.2.0/24", "192.0.2.0/28", "198.51.100.0/24"} {
+		r, err := inv.CreateAsset("IPRange", cidr, time.Now(), inventory.Unexpired)
+		if err != nil {
+			t.Fatalf("error creating IP range: %v", err)
+		}
+		ranges[cidr] = r
+	}
+
+	team := vulcan.Team{ID: "team-1", Name: "Team 1"}
+	for _, ip := range []string{"192.0.2.1", "192.0.2.100", "203.0.113.1"} {
+		payload := vulcan.AssetPayload{Team: team, AssetType: "IP", Identifier: ip}
+		if _, _, err := refreshAsset(inv, nil, payload, cfg); err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+	}
+
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{ip: "192.0.2.1", want: []string{ranges["192.0.2.0/28"].ID}},
+		{ip: "192.0.2.100", want: []string{ranges["192.0.2.0/24"].ID}},
+		{ip: "203.0.113.1", want: nil},
+	}
+
+	for _, tt := range tests {
+		assets, err := inv.Assets("IP", tt.ip, time.Time{}, inventory.Pagination{})
+		if err != nil || len(assets) != 1 {
+			t.Fatalf("unexpected assets: %v, %v", assets, err)
+		}
+
+		parents, err := inv.Parents(assets[0].ID, inventory.Pagination{})
+		if err != nil {
+			t.Fatalf("error getting parents: %v", err)
+		}
+
+		var got []string
+		for _, p := range parents {
+			got = append(got, p.ParentID)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("parents of %v mismatch (-want +got):\n%v", tt.ip, diff)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package assetsync
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// normalizers contains the identifier normalization functions of every
+// supported asset type. They are applied before looking up the assets in the
+// Asset Inventory, so different spellings of the same identifier do not end
+// up as different vertices.
+var normalizers = map[vulcan.AssetType]func(identifier string) string{
+	"Hostname":    normalizeHostname,
+	"DomainName":  normalizeHostname,
+	"IP":          normalizeIP,
+	"IPRange":     normalizeIPRange,
+	"DockerImage": normalizeDockerImage,
+}
+
+// NormalizedAssetTypes returns the sorted asset types whose identifiers can
+// be normalized. See [Config.NormalizeAssetTypes].
+func NormalizedAssetTypes() []vulcan.AssetType {
+	var types []vulcan.AssetType
+	for t := range normalizers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// NormalizePayload returns a copy of the provided payload with its
+// identifier normalized, if its asset type is included in types.
+func NormalizePayload(payload vulcan.AssetPayload, types []vulcan.AssetType) vulcan.AssetPayload {
+	for _, t := range types {
+		if t != payload.AssetType {
+			continue
+		}
+		if normalize, ok := normalizers[t]; ok {
+			payload.Identifier = normalize(payload.Identifier)
+		}
+		break
+	}
+	return payload
+}
+
+// normalizeHostname lowercases a hostname and removes its trailing dots.
+func normalizeHostname(hostname string) string {
+	return strings.TrimRight(strings.ToLower(hostname), ".")
+}
+
+// normalizeIP returns the canonical representation of an IP address. For
+// instance, IPv6 addresses are compressed and lowercased. Invalid addresses
+// are returned unmodified.
+func normalizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	return parsed.String()
+}
+
+// normalizeIPRange returns the canonical representation of a CIDR. Only the
+// address is normalized, so the host bits are preserved. Invalid CIDRs are
+// returned unmodified.
+func normalizeIPRange(cidr string) string {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cidr
+	}
+	ones, _ := ipnet.Mask.Size()
+	return ip.String() + "/" + strconv.Itoa(ones)
+}
+
+// normalizeDockerImage normalizes a Docker image reference to the format
+// "name:tag@digest". The registry domain and the digest are lowercased and,
+// if the reference has neither tag nor digest, the tag "latest" is added.
+func normalizeDockerImage(ref string) string {
+	name, digest, hasDigest := strings.Cut(ref, "@")
+
+	var tag string
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	if domain, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		name = strings.ToLower(domain) + "/" + rest
+	}
+
+	if tag == "" && !hasDigest {
+		tag = "latest"
+	}
+
+	norm := name
+	if tag != "" {
+		norm += ":" + tag
+	}
+	if hasDigest {
+		norm += "@" + strings.ToLower(digest)
+	}
+	return norm
+}
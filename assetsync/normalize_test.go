@@ -0,0 +1,105 @@
+package assetsync
+
+import (
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestNormalizePayload(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   vulcan.AssetType
+		id    string
+		types []vulcan.AssetType
+		want  string
+	}{
+		{
+			name:  "hostname",
+			typ:   "Hostname",
+			id:    "WWW.Example.COM.",
+			types: []vulcan.AssetType{"Hostname"},
+			want:  "www.example.com",
+		},
+		{
+			name:  "domain name",
+			typ:   "DomainName",
+			id:    "Example.com..",
+			types: []vulcan.AssetType{"DomainName"},
+			want:  "example.com",
+		},
+		{
+			name:  "type not selected",
+			typ:   "Hostname",
+			id:    "WWW.Example.COM.",
+			types: []vulcan.AssetType{"IP"},
+			want:  "WWW.Example.COM.",
+		},
+		{
+			name:  "ipv6",
+			typ:   "IP",
+			id:    "2001:0DB8:0000:0000:0000:0000:0000:0001",
+			types: []vulcan.AssetType{"IP"},
+			want:  "2001:db8::1",
+		},
+		{
+			name:  "ipv4",
+			typ:   "IP",
+			id:    "192.0.2.1",
+			types: []vulcan.AssetType{"IP"},
+			want:  "192.0.2.1",
+		},
+		{
+			name:  "invalid ip",
+			typ:   "IP",
+			id:    "not-an-ip",
+			types: []vulcan.AssetType{"IP"},
+			want:  "not-an-ip",
+		},
+		{
+			name:  "ip range",
+			typ:   "IPRange",
+			id:    "2001:DB8:0:0::1/64",
+			types: []vulcan.AssetType{"IPRange"},
+			want:  "2001:db8::1/64",
+		},
+		{
+			name:  "docker image without tag",
+			typ:   "DockerImage",
+			id:    "Registry.Example.com:5000/team/app",
+			types: []vulcan.AssetType{"DockerImage"},
+			want:  "registry.example.com:5000/team/app:latest",
+		},
+		{
+			name:  "docker image with tag",
+			typ:   "DockerImage",
+			id:    "team/app:V1",
+			types: []vulcan.AssetType{"DockerImage"},
+			want:  "team/app:V1",
+		},
+		{
+			name:  "docker image with tag and digest",
+			typ:   "DockerImage",
+			id:    "registry.example.com/app:1.0@sha256:ABCDEF",
+			types: []vulcan.AssetType{"DockerImage"},
+			want:  "registry.example.com/app:1.0@sha256:abcdef",
+		},
+		{
+			name:  "docker image with digest",
+			typ:   "DockerImage",
+			id:    "app@sha256:abcdef",
+			types: []vulcan.AssetType{"DockerImage"},
+			want:  "app@sha256:abcdef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := vulcan.AssetPayload{AssetType: tt.typ, Identifier: tt.id}
+			got := NormalizePayload(payload, tt.types)
+			if got.Identifier != tt.want {
+				t.Errorf("unexpected identifier: got: %v, want: %v", got.Identifier, tt.want)
+			}
+		})
+	}
+}
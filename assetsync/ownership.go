@@ -0,0 +1,123 @@
+package assetsync
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// ownershipTransfer is the change event logged when the owner of an asset
+// changes from one team to another.
+type ownershipTransfer struct {
+	Event      string    `json:"event"`
+	AssetType  string    `json:"asset_type"`
+	Identifier string    `json:"identifier"`
+	AssetID    string    `json:"asset_id"`
+	FromTeamID string    `json:"from_team_id"`
+	ToTeamID   string    `json:"to_team_id"`
+	Time       time.Time `json:"time"`
+	Position   string    `json:"position"`
+}
+
+// ownershipTransferredEvent is the name of the change event logged when an
+// ownership transfer is detected.
+const ownershipTransferredEvent = "ownership_transferred"
+
+// messageTime returns the timestamp of the message of payload. If it is not
+// available or it is after now, it returns now.
+func messageTime(payload vulcan.AssetPayload, now time.Time) time.Time {
+	if payload.Timestamp.IsZero() || payload.Timestamp.After(now) {
+		return now
+	}
+	return payload.Timestamp
+}
+
+// withinWindow reports whether t1 and t2 are at most window apart.
+func withinWindow(t1, t2 time.Time, window time.Duration) bool {
+	d := t1.Sub(t2)
+	if d < 0 {
+		d = -d
+	}
+	return d <= window
+}
+
+// releasedOwnership detects the ownership transfers completed by the
+// tombstone of the asset for the team with ID teamID, received at the
+// provided time. owners are the owns relations of the asset. The transfer
+// is detected if the team is an active owner of the asset and another team
+// started owning it during the window around the time of the tombstone. It
+// returns the active relation of the team and the relation of the new
+// owner.
+func releasedOwnership(owners []inventory.OwnsResp, teamID string, at time.Time, window time.Duration) (from, to inventory.OwnsResp, ok bool) {
+	if teamID == "" || window <= 0 {
+		return inventory.OwnsResp{}, inventory.OwnsResp{}, false
+	}
+
+	var found, foundTo bool
+	for _, o := range owners {
+		if o.EndTime != nil {
+			continue
+		}
+		switch {
+		case o.TeamID == teamID:
+			from, found = o, true
+		case withinWindow(o.StartTime, at, window) && (!foundTo || o.StartTime.After(to.StartTime)):
+			to, foundTo = o, true
+		}
+	}
+	return from, to, found && foundTo
+}
+
+// acquiredOwnership detects the ownership transfers completed by the
+// team with ID teamID becoming an owner of the asset at the provided time.
+// owners are the owns relations of the asset before the team became an
+// owner. The transfer is detected if the team was not an active owner, no
+// other team is an active owner and the ownership of another team ended
+// during the window around the provided time. It returns the most recently
+// ended relation.
+func acquiredOwnership(owners []inventory.OwnsResp, teamID string, at time.Time, window time.Duration) (from inventory.OwnsResp, ok bool) {
+	if window <= 0 {
+		return inventory.OwnsResp{}, false
+	}
+
+	for _, o := range owners {
+		if o.EndTime == nil {
+			// The team already owns the asset or the asset is
+			// shared with another team.
+			return inventory.OwnsResp{}, false
+		}
+		if o.TeamID == teamID || !withinWindow(*o.EndTime, at, window) {
+			continue
+		}
+		if !ok || o.EndTime.After(*from.EndTime) {
+			from, ok = o, true
+		}
+	}
+	return from, ok
+}
+
+// reportOwnershipTransfer counts and logs the transfer of the ownership of
+// asset from the team with ID fromTeamID to the team with ID toTeamID. The
+// team IDs are the IDs of the teams in the Asset Inventory.
+func reportOwnershipTransfer(payload vulcan.AssetPayload, asset inventory.AssetResp, fromTeamID, toTeamID string, at time.Time) {
+	ownershipTransfersTotal.Inc(string(payload.AssetType))
+
+	b, err := json.Marshal(ownershipTransfer{
+		Event:      ownershipTransferredEvent,
+		AssetType:  string(payload.AssetType),
+		Identifier: payload.Identifier,
+		AssetID:    asset.ID,
+		FromTeamID: fromTeamID,
+		ToTeamID:   toTeamID,
+		Time:       at,
+		Position:   payload.Position.String(),
+	})
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: could not marshal ownership transfer: %v", err)
+		return
+	}
+	log.Info.Printf("graph-vulcan-assets: %v: %s", ownershipTransferredEvent, b)
+}
@@ -0,0 +1,201 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestReleasedOwnership(t *testing.T) {
+	now := time.Now()
+	ended := now.Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		owners []inventory.OwnsResp
+		want   bool
+		wantTo string
+	}{
+		{
+			name: "transfer",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-1", StartTime: now.Add(-24 * time.Hour)},
+				{TeamID: "team-2", StartTime: now.Add(-time.Minute)},
+			},
+			want:   true,
+			wantTo: "team-2",
+		},
+		{
+			name: "shared ownership",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-1", StartTime: now.Add(-24 * time.Hour)},
+				{TeamID: "team-2", StartTime: now.Add(-12 * time.Hour)},
+			},
+			want: false,
+		},
+		{
+			name: "old owner not active",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-1", StartTime: now.Add(-24 * time.Hour), EndTime: &ended},
+				{TeamID: "team-2", StartTime: now.Add(-time.Minute)},
+			},
+			want: false,
+		},
+		{
+			name: "no new owner",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-1", StartTime: now.Add(-24 * time.Hour)},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := releasedOwnership(tt.owners, "team-1", now, time.Hour)
+			if ok != tt.want {
+				t.Fatalf("unexpected detection: want=%v got=%v", tt.want, ok)
+			}
+			if ok && (from.TeamID != "team-1" || to.TeamID != tt.wantTo) {
+				t.Errorf("unexpected transfer: from=%v to=%v", from.TeamID, to.TeamID)
+			}
+		})
+	}
+}
+
+func TestAcquiredOwnership(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Minute)
+	old := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		owners   []inventory.OwnsResp
+		want     bool
+		wantFrom string
+	}{
+		{
+			name: "transfer",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-1", StartTime: old, EndTime: &old},
+				{TeamID: "team-2", StartTime: old, EndTime: &recent},
+			},
+			want:     true,
+			wantFrom: "team-2",
+		},
+		{
+			name: "already owned",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-2", StartTime: old, EndTime: &recent},
+				{TeamID: "team-3", StartTime: old},
+			},
+			want: false,
+		},
+		{
+			name: "ownership ended long ago",
+			owners: []inventory.OwnsResp{
+				{TeamID: "team-2", StartTime: old, EndTime: &old},
+			},
+			want: false,
+		},
+		{
+			name:   "new asset",
+			owners: nil,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, ok := acquiredOwnership(tt.owners, "team-3", now, time.Hour)
+			if ok != tt.want {
+				t.Fatalf("unexpected detection: want=%v got=%v", tt.want, ok)
+			}
+			if ok && from.TeamID != tt.wantFrom {
+				t.Errorf("unexpected old owner: want=%v got=%v", tt.wantFrom, from.TeamID)
+			}
+		})
+	}
+}
+
+func TestOwnershipTransfer(t *testing.T) {
+	cfg := Config{
+		InventoryPageSize:       100,
+		MissingTeamPolicy:       MissingTeamPolicyIgnore,
+		OwnershipTransferWindow: time.Hour,
+	}
+	newPayload := func(teamID string) vulcan.AssetPayload {
+		return vulcan.AssetPayload{
+			Team:       vulcan.Team{ID: teamID},
+			AssetType:  "Hostname",
+			Identifier: "example.com",
+		}
+	}
+
+	t.Run("tombstone after new owner", func(t *testing.T) {
+		inv := inventorytest.NewInMemory()
+		cache := newAssetCache(0, clock{})
+
+		asset, _, err := refreshAsset(inv, cache, newPayload("team-1"), cfg)
+		if err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+		if _, _, err := refreshAsset(inv, cache, newPayload("team-2"), cfg); err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+
+		before := ownershipTransfersTotal.Value("Hostname")
+
+		tombstone := newPayload("team-1")
+		tombstone.Timestamp = time.Now()
+		if _, err := expireAsset(inv, cache, tombstone, cfg); err != nil {
+			t.Fatalf("error expiring asset: %v", err)
+		}
+
+		if got := ownershipTransfersTotal.Value("Hostname") - before; got != 1 {
+			t.Errorf("unexpected number of transfers: want=1 got=%v", got)
+		}
+
+		teams, err := inventory.AllTeams(inv, "team-1", cfg.InventoryPageSize)
+		if err != nil || len(teams) != 1 {
+			t.Fatalf("could not get team: teams=%v err=%v", teams, err)
+		}
+		owner, err := inv.Owner(asset.ID, teams[0].ID)
+		if err != nil {
+			t.Fatalf("error getting owner: %v", err)
+		}
+		if owner.EndTime == nil || !owner.EndTime.Equal(tombstone.Timestamp) {
+			t.Errorf("unexpected end time: want=%v got=%v", tombstone.Timestamp, owner.EndTime)
+		}
+	})
+
+	t.Run("tombstone before new owner", func(t *testing.T) {
+		inv := inventorytest.NewInMemory()
+		cache := newAssetCache(0, clock{})
+
+		if _, _, err := refreshAsset(inv, cache, newPayload("team-1"), cfg); err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+		if _, err := expireAsset(inv, cache, newPayload("team-1"), cfg); err != nil {
+			t.Fatalf("error expiring asset: %v", err)
+		}
+
+		before := ownershipTransfersTotal.Value("Hostname")
+
+		if _, _, err := refreshAsset(inv, cache, newPayload("team-2"), cfg); err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+		// Refreshing the asset again does not report the transfer
+		// twice.
+		if _, _, err := refreshAsset(inv, cache, newPayload("team-2"), cfg); err != nil {
+			t.Fatalf("error refreshing asset: %v", err)
+		}
+
+		if got := ownershipTransfersTotal.Value("Hostname") - before; got != 1 {
+			t.Errorf("unexpected number of transfers: want=1 got=%v", got)
+		}
+	})
+}
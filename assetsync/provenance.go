@@ -0,0 +1,75 @@
+package assetsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// provenanceSource is the source recorded in the provenance of the
+// relations created by the consumer.
+const provenanceSource = "vulcan"
+
+// ProvenanceStore stores the provenance of the relations of the Security
+// Graph. It is implemented by [props.Store].
+type ProvenanceStore interface {
+	SetParent(childID, parentID string, props map[string]string) error
+	SetOwner(assetID, teamID string, props map[string]string) error
+}
+
+// provenanceInventory is an [inventory.Inventory] that records the
+// provenance of the relations it creates or updates.
+type provenanceInventory struct {
+	inventory.Inventory
+	store ProvenanceStore
+	props map[string]string
+}
+
+// withProvenance returns an [inventory.Inventory] that records in store the
+// provenance of the relations created or updated by the provided version
+// of the consumer while processing the message with position pos. If store
+// is nil, icli is returned.
+func withProvenance(icli inventory.Inventory, store ProvenanceStore, version string, pos stream.Position) inventory.Inventory {
+	if store == nil {
+		return icli
+	}
+
+	return provenanceInventory{
+		Inventory: icli,
+		store:     store,
+		props: map[string]string{
+			props.ProvenanceSourceKey:   provenanceSource,
+			props.ProvenanceVersionKey:  version,
+			props.ProvenancePositionKey: pos.String(),
+		},
+	}
+}
+
+// UpsertParent creates or updates a parent-of relation and records its
+// provenance.
+func (inv provenanceInventory) UpsertParent(childID, parentID string, timestamp, expiration time.Time) (inventory.ParentOfResp, error) {
+	rel, err := inv.Inventory.UpsertParent(childID, parentID, timestamp, expiration)
+	if err != nil {
+		return inventory.ParentOfResp{}, err
+	}
+	if err := inv.store.SetParent(childID, parentID, inv.props); err != nil {
+		return inventory.ParentOfResp{}, fmt.Errorf("could not set provenance: %w", err)
+	}
+	return rel, nil
+}
+
+// UpsertOwner creates or updates an owns relation and records its
+// provenance.
+func (inv provenanceInventory) UpsertOwner(assetID, teamID string, startTime, endTime time.Time) (inventory.OwnsResp, error) {
+	rel, err := inv.Inventory.UpsertOwner(assetID, teamID, startTime, endTime)
+	if err != nil {
+		return inventory.OwnsResp{}, err
+	}
+	if err := inv.store.SetOwner(assetID, teamID, inv.props); err != nil {
+		return inventory.OwnsResp{}, fmt.Errorf("could not set provenance: %w", err)
+	}
+	return rel, nil
+}
@@ -0,0 +1,16 @@
+package assetsync
+
+import (
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestWithProvenanceNilStore(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	if got := withProvenance(inv, nil, "v1.2.3", stream.Position{}); got != inventory.Inventory(inv) {
+		t.Errorf("unexpected inventory: %T", got)
+	}
+}
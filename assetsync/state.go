@@ -0,0 +1,282 @@
+package assetsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// stateCache remembers the state of the assets applied to the Asset
+// Inventory, so refreshing an asset that has not changed since it was last
+// applied does not send any request to the Asset Inventory. The state of an
+// asset is kept for a TTL, so the time attributes of unchanged assets are
+// still refreshed periodically and any drift in the Asset Inventory is
+// repaired.
+//
+// When the cache is full, the expired entries are removed and, if it is
+// still full, an arbitrary entry is evicted.
+//
+// It is safe for concurrent use. The methods of a nil cache are no-ops.
+type stateCache struct {
+	mu     sync.Mutex
+	size   int
+	ttl    time.Duration
+	states map[assetKey]appliedState
+}
+
+// appliedState is the state of an asset applied to the Asset Inventory.
+type appliedState struct {
+	fingerprint [sha256.Size]byte
+	applied     time.Time
+}
+
+// newStateCache returns a [stateCache] with the provided maximum number of
+// entries and TTL. If size is zero, it returns nil, so no state is cached.
+func newStateCache(size int, ttl time.Duration) *stateCache {
+	if size <= 0 {
+		return nil
+	}
+	return &stateCache{
+		size:   size,
+		ttl:    ttl,
+		states: make(map[assetKey]appliedState),
+	}
+}
+
+// unchanged reports whether payload has been applied to the Asset Inventory
+// during the TTL preceding the provided time.
+func (c *stateCache) unchanged(payload vulcan.AssetPayload, at time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st, ok := c.states[assetKey{payload.AssetType, payload.Identifier}]
+	unchanged := ok && st.fingerprint == payloadFingerprint(payload) && at.Sub(st.applied) < c.ttl
+	stateCacheStats.lookup(unchanged)
+	return unchanged
+}
+
+// set records that payload has been applied to the Asset Inventory at the
+// provided time.
+func (c *stateCache) set(payload vulcan.AssetPayload, at time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.states))
+
+	key := assetKey{payload.AssetType, payload.Identifier}
+	if _, ok := c.states[key]; !ok && len(c.states) >= c.size {
+		c.evict(at)
+	}
+	c.states[key] = appliedState{
+		fingerprint: payloadFingerprint(payload),
+		applied:     at,
+	}
+}
+
+// evict removes the entries that are expired at the provided time. If none
+// is expired, an arbitrary entry is removed. It must be called with c.mu
+// held.
+func (c *stateCache) evict(at time.Time) {
+	for k, st := range c.states {
+		if at.Sub(st.applied) >= c.ttl {
+			delete(c.states, k)
+		}
+	}
+	if len(c.states) < c.size {
+		return
+	}
+	for k := range c.states {
+		delete(c.states, k)
+		return
+	}
+}
+
+// delete removes the state of the asset with the provided type and
+// identifier, so the next event of the asset is applied.
+func (c *stateCache) delete(typ vulcan.AssetType, identifier string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.resized(len(c.states))
+
+	delete(c.states, assetKey{typ, identifier})
+}
+
+// resized updates the statistics of the state caches with the change of
+// the number of entries since there were n. It must be called with c.mu
+// held.
+func (c *stateCache) resized(n int) {
+	stateCacheStats.resize(len(c.states) - n)
+}
+
+// StateStore stores the properties of the assets of the Security Graph. It
+// is implemented by [props.Store].
+type StateStore interface {
+	Asset(id string) (map[string]string, error)
+	SetAsset(id string, props map[string]string) error
+}
+
+// persistedStates remembers the state of the assets applied to the Asset
+// Inventory as properties of the assets. Unlike [stateCache], the states
+// survive restarts, so the events redelivered after a crash do not write to
+// the Asset Inventory again. Checking an asset requires looking it up, with
+// its owners and its properties, in the Asset Inventory, so only the events
+// that were processed before the start of the consumer, according to the
+// stored checkpoint, are checked.
+//
+// The methods of a nil persistedStates are no-ops.
+type persistedStates struct {
+	store     StateStore
+	processed checkpoint.Offsets
+	ttl       time.Duration
+	pageSize  int
+}
+
+// newPersistedStates returns a [persistedStates] that stores the states in
+// store for the provided TTL. processed contains the offsets of the assets
+// topic processed before the start of the consumer. If store is nil, it
+// returns nil, so no state is persisted.
+func newPersistedStates(store StateStore, processed checkpoint.Offsets, ttl time.Duration, pageSize int) *persistedStates {
+	if store == nil {
+		return nil
+	}
+	return &persistedStates{
+		store:     store,
+		processed: processed,
+		ttl:       ttl,
+		pageSize:  pageSize,
+	}
+}
+
+// redelivered reports whether payload was consumed from a position of the
+// assets topic that had already been processed before the start of the
+// consumer.
+func (s *persistedStates) redelivered(payload vulcan.AssetPayload) bool {
+	pos := payload.Position
+	if pos.Topic != vulcan.AssetsEntityName {
+		return false
+	}
+	offset, ok := s.processed[pos.Partition]
+	return ok && pos.Offset <= offset
+}
+
+// unchanged reports whether payload is a redelivered event that has been
+// applied to the unexpired asset of icli during the TTL preceding the
+// provided time, and the asset is still owned by the team it was applied
+// for.
+func (s *persistedStates) unchanged(icli inventory.Inventory, payload vulcan.AssetPayload, at time.Time) (bool, error) {
+	if s == nil || !s.redelivered(payload) {
+		return false, nil
+	}
+
+	assets, err := inventory.AllAssets(icli, string(payload.AssetType), payload.Identifier, time.Time{}, s.pageSize)
+	if err != nil {
+		return false, fmt.Errorf("could not get assets: %w", err)
+	}
+	if len(assets) != 1 || assets[0].Deleted || inventory.IsExpired(assets[0].Expiration, at) {
+		return false, nil
+	}
+
+	p, err := s.store.Asset(assets[0].ID)
+	if err != nil {
+		return false, fmt.Errorf("could not get state: %w", err)
+	}
+
+	fp := payloadFingerprint(payload)
+	if p[props.AssetStateFingerprintKey] != hex.EncodeToString(fp[:]) {
+		return false, nil
+	}
+	applied, err := time.Parse(time.RFC3339Nano, p[props.AssetStateAppliedKey])
+	if err != nil || at.Sub(applied) >= s.ttl {
+		return false, nil
+	}
+
+	// The owns relation could have been expired by a tombstone of
+	// another consumer or by a different writer.
+	owners, err := inventory.AllOwners(icli, assets[0].ID, s.pageSize)
+	if err != nil {
+		return false, fmt.Errorf("could not get owners: %w", err)
+	}
+	for _, o := range owners {
+		if o.TeamID == p[props.AssetStateTeamKey] && o.EndTime == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// set records that payload has been applied to asset, owned by team, at the
+// provided time.
+func (s *persistedStates) set(asset inventory.AssetResp, team inventory.TeamResp, payload vulcan.AssetPayload, at time.Time) error {
+	if s == nil {
+		return nil
+	}
+
+	fp := payloadFingerprint(payload)
+	p := map[string]string{
+		props.AssetStateFingerprintKey: hex.EncodeToString(fp[:]),
+		props.AssetStateAppliedKey:     at.UTC().Format(time.RFC3339Nano),
+		props.AssetStateTeamKey:        team.ID,
+	}
+	if err := s.store.SetAsset(asset.ID, p); err != nil {
+		return fmt.Errorf("could not set state: %w", err)
+	}
+	return nil
+}
+
+// clear removes the state of asset, so its next event is applied even if it
+// is redelivered.
+func (s *persistedStates) clear(asset inventory.AssetResp) error {
+	if s == nil {
+		return nil
+	}
+
+	p := map[string]string{
+		props.AssetStateFingerprintKey: "",
+		props.AssetStateAppliedKey:     "",
+		props.AssetStateTeamKey:        "",
+	}
+	if err := s.store.SetAsset(asset.ID, p); err != nil {
+		return fmt.Errorf("could not clear state: %w", err)
+	}
+	return nil
+}
+
+// payloadFingerprint returns a hash of the fields of payload that are
+// relevant to the Asset Inventory and the Security Graph. The order of the
+// annotations is not relevant.
+func payloadFingerprint(payload vulcan.AssetPayload) [sha256.Size]byte {
+	annotations := make([]vulcan.Annotation, len(payload.Annotations))
+	copy(annotations, payload.Annotations)
+	sort.Slice(annotations, func(i, j int) bool {
+		if annotations[i].Key != annotations[j].Key {
+			return annotations[i].Key < annotations[j].Key
+		}
+		return annotations[i].Value < annotations[j].Value
+	})
+	payload.Annotations = annotations
+
+	// Marshaling an AssetPayload cannot fail and the Position and the
+	// Timestamp are not marshaled.
+	b, _ := json.Marshal(payload)
+	return sha256.Sum256(b)
+}
@@ -0,0 +1,366 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestStateCache(t *testing.T) {
+	now := time.Now()
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Annotations: []vulcan.Annotation{
+			{Key: "a", Value: "1"},
+			{Key: "b", Value: "2"},
+		},
+	}
+
+	cache := newStateCache(2, time.Hour)
+	if cache.unchanged(payload, now) {
+		t.Error("unknown asset reported as unchanged")
+	}
+
+	cache.set(payload, now)
+	if !cache.unchanged(payload, now.Add(time.Minute)) {
+		t.Error("applied asset reported as changed")
+	}
+	if cache.unchanged(payload, now.Add(time.Hour)) {
+		t.Error("expired state reported as unchanged")
+	}
+
+	reordered := payload
+	reordered.Annotations = []vulcan.Annotation{payload.Annotations[1], payload.Annotations[0]}
+	reordered.Position.Offset = 10
+	if !cache.unchanged(reordered, now) {
+		t.Error("asset with reordered annotations reported as changed")
+	}
+
+	changed := payload
+	changed.Annotations = []vulcan.Annotation{{Key: "a", Value: "3"}}
+	if cache.unchanged(changed, now) {
+		t.Error("asset with changed annotations reported as unchanged")
+	}
+
+	renamed := payload
+	renamed.Team.Name = "Team One"
+	if cache.unchanged(renamed, now) {
+		t.Error("asset with renamed team reported as unchanged")
+	}
+
+	cache.delete(payload.AssetType, payload.Identifier)
+	if cache.unchanged(payload, now) {
+		t.Error("deleted state reported as unchanged")
+	}
+
+	var nilCache *stateCache
+	nilCache.set(payload, now)
+	if nilCache.unchanged(payload, now) {
+		t.Error("nil cache reported asset as unchanged")
+	}
+}
+
+func TestStateCacheEviction(t *testing.T) {
+	now := time.Now()
+
+	cache := newStateCache(2, time.Hour)
+	cache.set(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "a.example.com"}, now.Add(-2*time.Hour))
+	cache.set(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "b.example.com"}, now.Add(-time.Minute))
+	cache.set(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "c.example.com"}, now)
+
+	if n := len(cache.states); n != 2 {
+		t.Errorf("unexpected number of entries: %v", n)
+	}
+	if _, ok := cache.states[assetKey{"Hostname", "a.example.com"}]; ok {
+		t.Error("expired entry was not evicted")
+	}
+
+	cache.set(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "d.example.com"}, now)
+	if n := len(cache.states); n != 2 {
+		t.Errorf("unexpected number of entries: %v", n)
+	}
+}
+
+func TestEngineUnchanged(t *testing.T) {
+	cfg := Config{
+		InventoryPageSize:   100,
+		MissingTeamPolicy:   MissingTeamPolicyIgnore,
+		AssetStateCacheSize: 10,
+		AssetStateTTL:       time.Hour,
+	}
+	inv := &countingInventory{Inventory: inventorytest.NewInMemory()}
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+
+	h := NewEngine(inv, cfg).Handle
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	calls := inv.assetsCalls
+
+	before := unchangedAssetsTotal.Value()
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.assetsCalls != calls {
+		t.Errorf("unchanged asset was applied: want=%v Assets calls, got=%v", calls, inv.assetsCalls)
+	}
+	if n := unchangedAssetsTotal.Value() - before; n != 1 {
+		t.Errorf("unexpected number of unchanged assets: %v", n)
+	}
+
+	// A tombstone removes the state, so the next event is applied.
+	if err := h(payload, true); err != nil {
+		t.Fatalf("error handling tombstone: %v", err)
+	}
+	calls = inv.assetsCalls
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.assetsCalls == calls {
+		t.Error("asset was not applied after a tombstone")
+	}
+}
+
+// updateCountingInventory is an [inventory.Inventory] that counts the calls
+// to UpdateAsset.
+type updateCountingInventory struct {
+	inventory.Inventory
+	updateCalls int
+}
+
+func (inv *updateCountingInventory) UpdateAsset(id, typ, identifier string, timestamp, expiration time.Time) (inventory.AssetResp, error) {
+	inv.updateCalls++
+	return inv.Inventory.UpdateAsset(id, typ, identifier, timestamp, expiration)
+}
+
+func TestEnginePersistedState(t *testing.T) {
+	cfg := Config{
+		InventoryPageSize: 100,
+		MissingTeamPolicy: MissingTeamPolicyIgnore,
+		AssetStateTTL:     time.Hour,
+	}
+	inv := &updateCountingInventory{Inventory: inventorytest.NewInMemory()}
+	store := make(memVulcanIDStore)
+	processed := checkpoint.Offsets{0: 10}
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Position:   stream.Position{Topic: vulcan.AssetsEntityName, Partition: 0, Offset: 5},
+	}
+
+	cfg.States = store
+	if err := NewEngine(inv, cfg).Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	// A new engine simulates a restart of the consumer after
+	// processing offset 10.
+	cfg.Processed = processed
+	h := NewEngine(inv, cfg).Handle
+
+	before := unchangedAssetsTotal.Value()
+	calls := inv.updateCalls
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.updateCalls != calls {
+		t.Errorf("unchanged asset was applied: want=%v UpdateAsset calls, got=%v", calls, inv.updateCalls)
+	}
+	if n := unchangedAssetsTotal.Value() - before; n != 1 {
+		t.Errorf("unexpected number of unchanged assets: %v", n)
+	}
+
+	// An event that was not processed before the restart is applied
+	// without checking the persisted state.
+	next := payload
+	next.Position.Offset = 11
+	calls = inv.updateCalls
+	if err := h(next, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.updateCalls == calls {
+		t.Error("new event was not applied")
+	}
+
+	// A changed asset is applied.
+	changed := payload
+	changed.Annotations = []vulcan.Annotation{{Key: "a", Value: "1"}}
+	calls = inv.updateCalls
+	if err := h(changed, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.updateCalls == calls {
+		t.Error("changed asset was not applied")
+	}
+
+	// An expired asset is applied, even if the event has not changed.
+	if err := h(changed, true); err != nil {
+		t.Fatalf("error handling tombstone: %v", err)
+	}
+	calls = inv.updateCalls
+	if err := NewEngine(inv, cfg).Handle(changed, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if inv.updateCalls == calls {
+		t.Error("expired asset was not applied")
+	}
+}
+
+func TestEnginePersistedStateTombstone(t *testing.T) {
+	cfg := Config{
+		InventoryPageSize: 100,
+		MissingTeamPolicy: MissingTeamPolicyIgnore,
+		AssetStateTTL:     time.Hour,
+	}
+	inv := &updateCountingInventory{Inventory: inventorytest.NewInMemory()}
+	cfg.States = make(memVulcanIDStore)
+	cfg.Processed = checkpoint.Offsets{0: 10}
+
+	newPayload := func(team string, offset int64) vulcan.AssetPayload {
+		return vulcan.AssetPayload{
+			Team:       vulcan.Team{ID: team, Name: team},
+			AssetType:  "Hostname",
+			Identifier: "example.com",
+			Position:   stream.Position{Topic: vulcan.AssetsEntityName, Partition: 0, Offset: offset},
+		}
+	}
+
+	h := NewEngine(inv, cfg).Handle
+	if err := h(newPayload("team-2", 1), false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if err := h(newPayload("team-1", 2), false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	// The asset is still owned by team-2, so the tombstone only expires
+	// the owns relation of team-1.
+	if err := h(newPayload("team-1", 3), true); err != nil {
+		t.Fatalf("error handling tombstone: %v", err)
+	}
+
+	// The redelivered event of team-1 must restore its owns relation.
+	if err := NewEngine(inv, cfg).Handle(newPayload("team-1", 2), false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	teams, err := inventory.AllTeams(inv, "team-1", 100)
+	if err != nil || len(teams) != 1 {
+		t.Fatalf("error getting team: %v, %v", teams, err)
+	}
+	assets, err := inventory.AllAssets(inv, "Hostname", "example.com", time.Time{}, 100)
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("error getting asset: %v, %v", assets, err)
+	}
+	owners, err := inventory.AllOwners(inv, assets[0].ID, 100)
+	if err != nil {
+		t.Fatalf("error getting owners: %v", err)
+	}
+	var owned bool
+	for _, o := range owners {
+		if o.TeamID == teams[0].ID && o.EndTime == nil {
+			owned = true
+		}
+	}
+	if !owned {
+		t.Error("owns relation of the redelivered event was not restored")
+	}
+}
+
+func TestPersistedStatesTTL(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	store := make(memVulcanIDStore)
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Position:   stream.Position{Topic: vulcan.AssetsEntityName, Partition: 0, Offset: 5},
+	}
+
+	now := time.Now()
+	asset, err := inv.CreateAsset("Hostname", "example.com", now, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	team, err := inv.CreateTeam("team-1", "Team 1")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	if _, err := inv.UpsertOwner(asset.ID, team.ID, now, time.Time{}); err != nil {
+		t.Fatalf("error creating owns relation: %v", err)
+	}
+
+	states := newPersistedStates(store, checkpoint.Offsets{0: 10}, time.Hour, 100)
+	if err := states.set(asset, team, payload, now); err != nil {
+		t.Fatalf("error setting state: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload vulcan.AssetPayload
+		at      time.Time
+		want    bool
+	}{
+		{name: "within TTL", payload: payload, at: now.Add(59 * time.Minute), want: true},
+		{name: "after TTL", payload: payload, at: now.Add(time.Hour), want: false},
+		{
+			name: "not redelivered",
+			payload: func() vulcan.AssetPayload {
+				p := payload
+				p.Position.Offset = 11
+				return p
+			}(),
+			at:   now,
+			want: false,
+		},
+		{
+			name: "unknown partition",
+			payload: func() vulcan.AssetPayload {
+				p := payload
+				p.Position.Partition = 1
+				return p
+			}(),
+			at:   now,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := states.unchanged(inv, tt.payload, tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected result: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+
+	if got, err := newPersistedStates(nil, nil, time.Hour, 100).unchanged(inv, payload, now); got || err != nil {
+		t.Errorf("unexpected result of nil states: %v, %v", got, err)
+	}
+
+	if err := states.clear(asset); err != nil {
+		t.Fatalf("error clearing state: %v", err)
+	}
+	if got, err := states.unchanged(inv, payload, now); got || err != nil {
+		t.Errorf("unexpected result of cleared state: %v, %v", got, err)
+	}
+}
@@ -0,0 +1,228 @@
+package assetsync
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// SyncStatus is a snapshot of the state of the synchronization run by the
+// consumer. It is the same state served by the status endpoint of the
+// admin API.
+type SyncStatus struct {
+	// Time is the time when the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// State is the state of the kafka consumer: "starting",
+	// "subscribed", "assigned", "consuming", "failed" or "closed". It is
+	// empty if the consumer is not running in the process.
+	State string `json:"state"`
+
+	// Ready reports whether the consumer is ready, with the same
+	// semantics as the readiness endpoint of the admin API.
+	Ready bool `json:"ready"`
+
+	// Error is the error that stopped the consumer, if any.
+	Error string `json:"error,omitempty"`
+
+	// Partitions contains the processing lag of the partitions assigned
+	// to the consumer in which a message has been processed, sorted by
+	// partition.
+	Partitions []PartitionLag `json:"partitions"`
+
+	// Counters contains the number of messages processed since the
+	// process started.
+	Counters Counters `json:"counters"`
+
+	// LastError is the last error processing a message. It is nil if no
+	// message has failed.
+	LastError *MessageError `json:"last_error,omitempty"`
+
+	// Caches contains the statistics of the caches of the engines by
+	// name: "assets", the parent assets; "negative", the assets and
+	// teams not found; and "states", the fingerprints of the applied
+	// events.
+	Caches map[string]CacheStats `json:"caches"`
+}
+
+// PartitionLag is the processing lag of a partition.
+type PartitionLag struct {
+	// Partition is the ID of the partition.
+	Partition int32 `json:"partition"`
+
+	// Lag is the time elapsed since the timestamp of the last message
+	// processed in the partition. It is encoded in JSON as nanoseconds.
+	Lag time.Duration `json:"lag"`
+}
+
+// Counters are the counters of the processed messages.
+type Counters struct {
+	// Processed is the number of processed messages.
+	Processed int64 `json:"processed"`
+
+	// Failed is the number of messages whose processing failed.
+	Failed int64 `json:"failed"`
+
+	// Unchanged is the number of messages skipped because the asset
+	// did not change.
+	Unchanged int64 `json:"unchanged"`
+
+	// Created is the number of assets created in the Asset Inventory.
+	Created int64 `json:"created"`
+
+	// Expired is the number of assets expired in the Asset Inventory.
+	Expired int64 `json:"expired"`
+}
+
+// MessageError is an error processing a message.
+type MessageError struct {
+	// Time is the time when the error happened.
+	Time time.Time `json:"time"`
+
+	// AssetType and Identifier identify the asset of the message.
+	AssetType  string `json:"asset_type"`
+	Identifier string `json:"identifier"`
+
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// CacheStats are the statistics of a cache.
+type CacheStats struct {
+	// Entries is the number of cached entries.
+	Entries int64 `json:"entries"`
+
+	// Hits and Misses are the number of lookups that found and did not
+	// find an entry, respectively.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// cacheStats contains the statistics of a kind of cache. They aggregate the
+// caches of every [Engine] of the process. It is safe for
+// concurrent use.
+type cacheStats struct {
+	entries atomic.Int64
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// Statistics of the caches of the engines reported by [Status].
+var (
+	assetCacheStats    = &cacheStats{}
+	negativeCacheStats = &cacheStats{}
+	stateCacheStats    = &cacheStats{}
+)
+
+// resize adds delta to the number of entries.
+func (s *cacheStats) resize(delta int) {
+	s.entries.Add(int64(delta))
+}
+
+// lookup records a lookup that found an entry if hit is true and a lookup
+// that did not find it otherwise.
+func (s *cacheStats) lookup(hit bool) {
+	if hit {
+		s.hits.Add(1)
+	} else {
+		s.misses.Add(1)
+	}
+}
+
+// snapshot returns the current statistics.
+func (s *cacheStats) snapshot() CacheStats {
+	return CacheStats{
+		Entries: s.entries.Load(),
+		Hits:    s.hits.Load(),
+		Misses:  s.misses.Load(),
+	}
+}
+
+// errorTracker records the last error processing a message. It is safe
+// for concurrent use.
+type errorTracker struct {
+	mu   sync.Mutex
+	last *MessageError
+}
+
+// lastMessageError is the last error returned by the handlers returned by
+// [CountingHandler].
+var lastMessageError = &errorTracker{}
+
+// record records that processing payload failed with err at the provided
+// time.
+func (t *errorTracker) record(payload vulcan.AssetPayload, err error, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.last = &MessageError{
+		Time:       at,
+		AssetType:  string(payload.AssetType),
+		Identifier: payload.Identifier,
+		Error:      err.Error(),
+	}
+}
+
+// get returns a copy of the last error. It returns nil if no error has
+// been recorded.
+func (t *errorTracker) get() *MessageError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.last == nil {
+		return nil
+	}
+	last := *t.last
+	return &last
+}
+
+var (
+	statusMu     sync.RWMutex
+	statusSource func() SyncStatus
+)
+
+// SetStatusSource sets the function that returns the status of the
+// consumer that runs the synchronization. The source provides the fields
+// [SyncStatus.State], [SyncStatus.Ready], [SyncStatus.Error] and
+// [SyncStatus.Partitions], the other fields are set by [Status]. It is
+// called by the consumer when it starts, so embedders running it do not
+// need to call it. If f is nil, the source is removed.
+func SetStatusSource(f func() SyncStatus) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+
+	statusSource = f
+}
+
+// Status returns a snapshot of the status of the synchronization. The
+// counters, the last error and the statistics of the caches aggregate the
+// engines and the handlers returned by [CountingHandler] in the process.
+// If no source has been set with [SetStatusSource], the fields of the
+// consumer are empty.
+func Status() SyncStatus {
+	statusMu.RLock()
+	f := statusSource
+	statusMu.RUnlock()
+
+	st := SyncStatus{Time: time.Now()}
+	if f != nil {
+		st = f()
+	}
+
+	st.Counters = Counters{
+		Processed: int64(processedMessagesTotal.Value()),
+		Failed:    int64(processingErrorsTotal.Value()),
+		Unchanged: int64(unchangedAssetsTotal.Value()),
+		Created:   int64(createdAssetsTotal.Value()),
+		Expired:   int64(expiredAssetsTotal.Value()),
+	}
+	st.LastError = lastMessageError.get()
+	st.Caches = map[string]CacheStats{
+		"assets":   assetCacheStats.snapshot(),
+		"negative": negativeCacheStats.snapshot(),
+		"states":   stateCacheStats.snapshot(),
+	}
+	return st
+}
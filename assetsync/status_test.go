@@ -0,0 +1,91 @@
+package assetsync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestStatus(t *testing.T) {
+	if st := Status(); st.State != "" || st.Time.IsZero() {
+		t.Errorf("unexpected status without source: %+v", st)
+	}
+
+	SetStatusSource(func() SyncStatus {
+		return SyncStatus{Time: time.Now(), State: "consuming", Ready: true}
+	})
+	defer SetStatusSource(nil)
+
+	if st := Status(); st.State != "consuming" || !st.Ready {
+		t.Errorf("unexpected status: %+v", st)
+	}
+}
+
+func TestStatusCountingHandler(t *testing.T) {
+	before := Status().Counters
+
+	h := CountingHandler(func(payload vulcan.AssetPayload, isNil bool) error {
+		if isNil {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+	payload := vulcan.AssetPayload{AssetType: "Hostname", Identifier: "example.com", Team: vulcan.Team{ID: "team-counting"}}
+	h(payload, false)
+	h(payload, true)
+
+	st := Status()
+	if got := st.Counters.Processed - before.Processed; got != 2 {
+		t.Errorf("unexpected processed messages: want=2, got=%v", got)
+	}
+	if got := st.Counters.Failed - before.Failed; got != 1 {
+		t.Errorf("unexpected failed messages: want=1, got=%v", got)
+	}
+	if st.LastError == nil || st.LastError.Identifier != payload.Identifier || st.LastError.Error != "handler error" {
+		t.Errorf("unexpected last error: %+v", st.LastError)
+	}
+	for _, name := range []string{"assets", "negative", "states"} {
+		if _, ok := st.Caches[name]; !ok {
+			t.Errorf("missing cache %v", name)
+		}
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	now := time.Now()
+
+	assets := assetCacheStats.snapshot()
+	negative := negativeCacheStats.snapshot()
+
+	cache := newAssetCache(time.Minute, clock{})
+	cache.set(inventory.AssetResp{ID: "1", Type: "AWSAccount", Identifier: "a", Expiration: inventory.Unexpired})
+	cache.get("AWSAccount", "a", now)
+	cache.get("AWSAccount", "b", now)
+	cache.setAssetMissing("Hostname", "example.com", now)
+	cache.setTeamMissing("team-1", now)
+	cache.assetMissing("Hostname", "example.com", now)
+	cache.clearMissing("Hostname", "example.com", "")
+
+	want := CacheStats{
+		Entries: assets.Entries + 1,
+		Hits:    assets.Hits + 1,
+		Misses:  assets.Misses + 1,
+	}
+	if diff := cmp.Diff(want, assetCacheStats.snapshot()); diff != "" {
+		t.Errorf("asset cache stats mismatch (-want +got):\n%v", diff)
+	}
+
+	want = CacheStats{
+		Entries: negative.Entries + 1,
+		Hits:    negative.Hits + 1,
+		Misses:  negative.Misses,
+	}
+	if diff := cmp.Diff(want, negativeCacheStats.snapshot()); diff != "" {
+		t.Errorf("negative cache stats mismatch (-want +got):\n%v", diff)
+	}
+}
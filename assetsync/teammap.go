@@ -0,0 +1,16 @@
+package assetsync
+
+// TeamMapping maps the IDs of Vulcan teams to the identifiers of the teams
+// in the Security Graph. It allows to assign the assets of a Vulcan team to
+// a different team of the Security Graph after mergers or renames.
+type TeamMapping map[string]string
+
+// graphID returns the identifier in the Security Graph of the Vulcan team
+// with the provided ID. If the team is not mapped, it returns the ID of the
+// team and false.
+func (m TeamMapping) graphID(id string) (string, bool) {
+	if to, ok := m[id]; ok {
+		return to, true
+	}
+	return id, false
+}
@@ -0,0 +1,78 @@
+package assetsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestTeamMappingOwner(t *testing.T) {
+	vids := make(memVulcanIDStore)
+	cfg := Config{
+		InventoryPageSize: 100,
+		TeamMapping:       TeamMapping{"vulcan-team-old": "vulcan-team-new"},
+		VulcanIDs:         vids,
+	}
+
+	inv := inventorytest.NewInMemory()
+
+	canonical, err := inv.CreateTeam("vulcan-team-new", "New team")
+	if err != nil {
+		t.Fatalf("could not create team: %v", err)
+	}
+
+	payload := vulcan.AssetPayload{
+		ID:         "vulcan-asset-1",
+		Team:       vulcan.Team{ID: "vulcan-team-old", Name: "Old team", Tag: "old-tag"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+	asset, _, err := refreshAsset(inv, nil, payload, cfg)
+	if err != nil {
+		t.Fatalf("error refreshing asset: %v", err)
+	}
+
+	if teams, err := inv.Teams("vulcan-team-old", inventory.Pagination{}); err != nil || len(teams) != 0 {
+		t.Errorf("unexpected teams of the Vulcan team: %v, %v", teams, err)
+	}
+	teams, err := inv.Teams("vulcan-team-new", inventory.Pagination{})
+	if err != nil || len(teams) != 1 {
+		t.Fatalf("unexpected teams of the mapped team: %v, %v", teams, err)
+	}
+	if teams[0].Name != "New team" {
+		t.Errorf("unexpected name of the mapped team: %v", teams[0].Name)
+	}
+
+	owners, err := inventory.AllOwners(inv, asset.ID, 100)
+	if err != nil || len(owners) != 1 || owners[0].TeamID != canonical.ID || owners[0].EndTime != nil {
+		t.Fatalf("unexpected owners: %v, %v", owners, err)
+	}
+
+	// Only the properties of the asset are stored.
+	wantVids := memVulcanIDStore{
+		asset.ID: {
+			props.VulcanAssetIDKey: "vulcan-asset-1",
+		},
+	}
+	if diff := cmp.Diff(wantVids, vids); diff != "" {
+		t.Errorf("Vulcan IDs mismatch (-want +got):\n%v", diff)
+	}
+
+	// Tombstones expire the owns relation of the mapped team.
+	if _, err := expireAsset(inv, nil, payload, cfg); err != nil {
+		t.Fatalf("error expiring asset: %v", err)
+	}
+	owners, err = inventory.AllOwners(inv, asset.ID, 100)
+	if err != nil || len(owners) != 1 || owners[0].EndTime == nil {
+		t.Fatalf("unexpected owners after expiring: %v, %v", owners, err)
+	}
+	if owners[0].EndTime.After(time.Now()) {
+		t.Errorf("owns relation not expired: %v", owners[0].EndTime)
+	}
+}
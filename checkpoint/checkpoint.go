@@ -0,0 +1,157 @@
+// Package checkpoint persists the offsets processed by the consumer as
+// properties of the Universe vertex of the Security Graph. Checkpoints allow
+// to detect gaps in processing caused by messages deleted by the kafka
+// retention policy before being processed.
+package checkpoint
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	gremlingo "github.com/apache/tinkerpop/gremlin-go/v3/driver"
+)
+
+// keyPrefix is the prefix of the properties of the Universe vertex that
+// contain the checkpoints. The full key has the format
+// "<keyPrefix><topic>/<partition>".
+const keyPrefix = "graph-vulcan-assets/checkpoint/"
+
+// universeLabel is the label of the Universe vertex.
+const universeLabel = "Universe"
+
+// Offsets contains the last processed offset of every partition of a topic.
+type Offsets map[int32]int64
+
+// Store stores checkpoints in the Universe vertex of a Security Graph.
+// Every partition is stored in a different property, so several consumers of
+// the same consumer group can store their checkpoints concurrently.
+type Store struct {
+	conn *gremlingo.DriverRemoteConnection
+	g    *gremlingo.GraphTraversalSource
+}
+
+// NewStore returns a [Store] connected to the gremlin-server with the
+// provided endpoint (for instance ws://gremlin-server:8182/gremlin).
+func NewStore(endpoint string) (Store, error) {
+	conn, err := gremlingo.NewDriverRemoteConnection(endpoint, func(settings *gremlingo.DriverRemoteConnectionSettings) {
+		settings.LogVerbosity = gremlingo.Off
+	})
+	if err != nil {
+		return Store{}, fmt.Errorf("could not connect to gremlin-server: %w", err)
+	}
+
+	store := Store{
+		conn: conn,
+		g:    gremlingo.Traversal_().WithRemote(conn),
+	}
+	return store, nil
+}
+
+// Load returns the checkpoint of the provided topic. It returns an empty
+// checkpoint if none has been stored yet.
+func (s Store) Load(topic string) (Offsets, error) {
+	results, err := s.g.V().HasLabel(universeLabel).Properties().ToList()
+	if err != nil {
+		return nil, fmt.Errorf("could not get universe properties: %w", err)
+	}
+
+	prefix := keyPrefix + topic + "/"
+	offsets := make(Offsets)
+	for _, r := range results {
+		vp, ok := r.GetInterface().(*gremlingo.VertexProperty)
+		if !ok || !strings.HasPrefix(vp.Label, prefix) {
+			continue
+		}
+
+		partition, err := strconv.ParseInt(strings.TrimPrefix(vp.Label, prefix), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint key %q: %w", vp.Label, err)
+		}
+
+		offset, err := toInt64(vp.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint %q: %w", vp.Label, err)
+		}
+
+		offsets[int32(partition)] = offset
+	}
+	return offsets, nil
+}
+
+// Save stores the provided offsets as the checkpoint of topic. The
+// checkpoints of the partitions not present in offsets are not modified.
+func (s Store) Save(topic string, offsets Offsets) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	partitions := make([]int32, 0, len(offsets))
+	for p := range offsets {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i] < partitions[j]
+	})
+
+	t := s.g.V().HasLabel(universeLabel)
+	for _, p := range partitions {
+		key := fmt.Sprintf("%v%v/%v", keyPrefix, topic, p)
+		t = t.Property(gremlingo.Cardinality.Single, key, offsets[p])
+	}
+
+	r, err := t.Count().Next()
+	if err != nil {
+		return fmt.Errorf("could not store checkpoint: %w", err)
+	}
+	n, err := r.GetInt64()
+	if err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%v vertex not found", universeLabel)
+	}
+
+	return nil
+}
+
+// Close closes the connection with gremlin-server.
+func (s Store) Close() {
+	s.conn.Close()
+}
+
+// Gaps returns the partitions whose next offset to be processed according to
+// the checkpoint is lower than the first offset available in kafka (low
+// watermark). In other words, the partitions where messages were deleted
+// before being processed. Partitions without checkpoint are ignored.
+func Gaps(checkpoint, low Offsets) []int32 {
+	var gaps []int32
+	for p, offset := range checkpoint {
+		l, ok := low[p]
+		if !ok {
+			continue
+		}
+		if offset+1 < l {
+			gaps = append(gaps, p)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i] < gaps[j]
+	})
+	return gaps
+}
+
+// toInt64 converts the numeric value of a property into an int64.
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int32:
+		return int64(n), nil
+	case int:
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+}
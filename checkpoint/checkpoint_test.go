@@ -0,0 +1,122 @@
+package checkpoint
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers"
+)
+
+// readyTimeout is the maximum time to wait for the testing infrastructure.
+const readyTimeout = time.Minute
+
+const topicPrefix = "checkpoint_checkpoint_test_topic_"
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Main(m, containers.Gremlin))
+}
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+func TestStoreSaveLoad(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	if err := testinfra.WaitGremlin(ctx); err != nil {
+		t.Fatalf("error waiting for gremlin-server: %v", err)
+	}
+
+	store, err := NewStore(testinfra.GremlinEndpoint())
+	if err != nil {
+		t.Fatalf("error creating store: %v", err)
+	}
+	defer store.Close()
+
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+	other := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	got, err := store.Load(topic)
+	if err != nil {
+		t.Fatalf("error loading empty checkpoint: %v", err)
+	}
+	if diff := cmp.Diff(Offsets{}, got); diff != "" {
+		t.Errorf("empty checkpoint mismatch (-want +got):\n%v", diff)
+	}
+
+	if err := store.Save(topic, Offsets{0: 10, 1: 20}); err != nil {
+		t.Fatalf("error saving checkpoint: %v", err)
+	}
+	if err := store.Save(topic, Offsets{1: 25, 2: 5}); err != nil {
+		t.Fatalf("error saving checkpoint: %v", err)
+	}
+	if err := store.Save(other, Offsets{0: 100}); err != nil {
+		t.Fatalf("error saving checkpoint: %v", err)
+	}
+
+	want := Offsets{0: 10, 1: 25, 2: 5}
+	got, err = store.Load(topic)
+	if err != nil {
+		t.Fatalf("error loading checkpoint: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("checkpoint mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestGaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkpoint Offsets
+		low        Offsets
+		want       []int32
+	}{
+		{
+			name:       "no gaps",
+			checkpoint: Offsets{0: 10, 1: 20},
+			low:        Offsets{0: 5, 1: 21},
+			want:       nil,
+		},
+		{
+			name:       "gaps",
+			checkpoint: Offsets{0: 10, 1: 20, 2: 30},
+			low:        Offsets{0: 12, 1: 21, 2: 100},
+			want:       []int32{0, 2},
+		},
+		{
+			name:       "empty checkpoint",
+			checkpoint: Offsets{},
+			low:        Offsets{0: 100},
+			want:       nil,
+		},
+		{
+			name:       "partition without checkpoint",
+			checkpoint: Offsets{0: 10},
+			low:        Offsets{0: 10, 1: 100},
+			want:       nil,
+		},
+		{
+			name:       "unknown partition",
+			checkpoint: Offsets{0: 10, 3: 10},
+			low:        Offsets{0: 10},
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Gaps(tt.checkpoint, tt.low)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("gaps mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
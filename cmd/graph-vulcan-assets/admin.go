@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/metrics"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// adminMux returns the handler of the admin HTTP server. If capture is not
+// nil, it also serves the requests sent to the Asset Inventory. If volume is
+// not nil, it also serves the assets with more events.
+func adminMux(maint *maintenance, gd groupDescriber, cs *consumerState, capture *inventory.Capture, volume *eventVolume) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/maintenance", maint)
+	mux.Handle("/kafka/group", groupHandler(gd))
+	mux.Handle("/ready", cs)
+	mux.Handle("/status", statusHandler())
+	if capture != nil {
+		mux.Handle("/debug/inventory", captureHandler(capture))
+	}
+	if volume != nil {
+		mux.Handle("/assets/top", volume)
+	}
+	return mux
+}
+
+// captureHandler returns the admin API endpoint that lists the requests
+// sent to the Asset Inventory recorded by capture, from the oldest to the
+// newest.
+func captureHandler(capture *inventory.Capture) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(capture.Exchanges())
+	})
+}
+
+// groupDescriber is implemented by the stream processors that can describe
+// their consumer group membership.
+type groupDescriber interface {
+	GroupStatus() (kafka.GroupStatus, error)
+}
+
+// groupHandler returns the admin API endpoint that describes the consumer
+// group membership of gd.
+func groupHandler(gd groupDescriber) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		status, err := gd.GroupStatus()
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not get consumer group status: %v", err)
+			http.Error(w, "could not get consumer group status", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	})
+}
+
+// serveAdmin starts an admin HTTP server listening on addr that serves h.
+// The server is shut down when the provided context is cancelled.
+func serveAdmin(ctx context.Context, addr string, h http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not listen on %v: %w", addr, err)
+	}
+
+	srv := &http.Server{Handler: h}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	go func() {
+		log.Info.Printf("graph-vulcan-assets: admin server listening on %v", ln.Addr())
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error.Printf("graph-vulcan-assets: admin server error: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// mockGroupDescriber is a [groupDescriber] that returns a fixed status.
+type mockGroupDescriber struct {
+	status kafka.GroupStatus
+	err    error
+}
+
+func (gd mockGroupDescriber) GroupStatus() (kafka.GroupStatus, error) {
+	return gd.status, gd.err
+}
+
+func TestGroupHandler(t *testing.T) {
+	status := kafka.GroupStatus{
+		GroupID:           "group-id",
+		RebalanceProtocol: "COOPERATIVE",
+		Rebalances:        2,
+		Partitions: []kafka.PartitionStatus{
+			{
+				Topic:         "assets",
+				Partition:     0,
+				Committed:     10,
+				Position:      12,
+				HighWatermark: 15,
+				Lag:           5,
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		gd         groupDescriber
+		wantStatus int
+		wantGroup  kafka.GroupStatus
+	}{
+		{
+			name:       "get status",
+			method:     http.MethodGet,
+			gd:         mockGroupDescriber{status: status},
+			wantStatus: http.StatusOK,
+			wantGroup:  status,
+		},
+		{
+			name:       "status error",
+			method:     http.MethodGet,
+			gd:         mockGroupDescriber{err: errors.New("error")},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "invalid method",
+			method:     http.MethodPost,
+			gd:         mockGroupDescriber{status: status},
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			adminMux(newMaintenance(""), tt.gd, newConsumerState(), nil, nil).ServeHTTP(rec, httptest.NewRequest(tt.method, "/kafka/group", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("unexpected status code: want=%v, got=%v", tt.wantStatus, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got kafka.GroupStatus
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantGroup, got); diff != "" {
+				t.Errorf("group status mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCaptureHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	capture := inventory.NewCapture(10, 1024)
+	icli, err := inventory.NewClient(srv.URL, false, inventory.WithCapture(capture))
+	if err != nil {
+		t.Fatalf("error creating inventory client: %v", err)
+	}
+	if _, err := icli.Teams("team-1", inventory.Pagination{}); err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	adminMux(newMaintenance(""), nil, newConsumerState(), capture, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inventory", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %v", rec.Code)
+	}
+
+	var got []inventory.Exchange
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("error decoding body: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("unexpected number of exchanges: %v", len(got))
+	}
+	if got[0].Method != http.MethodGet || got[0].Path != "/v1/teams?team_identifier=team-1" || got[0].ResponseBody != "[]" {
+		t.Errorf("unexpected exchange: %+v", got[0])
+	}
+
+	rec = httptest.NewRecorder()
+	adminMux(newMaintenance(""), nil, newConsumerState(), nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inventory", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code with capture disabled: %v", rec.Code)
+	}
+}
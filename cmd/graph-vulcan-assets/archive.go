@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/s3"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// archiveMaxPending is the maximum number of batches waiting to be uploaded
+// to S3. When it is exceeded, the oldest batch is dropped, so the memory
+// used by the archiver is bounded when S3 is not available.
+const archiveMaxPending = 10
+
+// archiveUploadTimeout is the maximum time spent uploading the pending
+// batches.
+const archiveUploadTimeout = time.Minute
+
+// Outcomes of the archived messages.
+const (
+	archiveOutcomeArchived = "archived"
+	archiveOutcomeDropped  = "dropped"
+	archiveOutcomeFailed   = "failed"
+)
+
+// archivedMessage is the representation of a message in the archive. It
+// extends the representation of the messages of the sample file with their
+// position and timestamp.
+type archivedMessage struct {
+	Topic     string                `json:"topic"`
+	Partition int32                 `json:"partition"`
+	Offset    int64                 `json:"offset"`
+	Timestamp *time.Time            `json:"timestamp,omitempty"`
+	Key       *string               `json:"key"`
+	Value     *string               `json:"value"`
+	Metadata  []sampledMetadataItem `json:"metadata"`
+}
+
+// newArchivedMessage returns the representation of msg in the archive.
+func newArchivedMessage(msg stream.Message) archivedMessage {
+	am := archivedMessage{
+		Topic:     msg.Position.Topic,
+		Partition: msg.Position.Partition,
+		Offset:    msg.Position.Offset,
+	}
+	if !msg.Timestamp.IsZero() {
+		ts := msg.Timestamp.UTC()
+		am.Timestamp = &ts
+	}
+	if msg.Key != nil {
+		key := string(msg.Key)
+		am.Key = &key
+	}
+	if msg.Value != nil {
+		value := string(msg.Value)
+		am.Value = &value
+	}
+	for _, e := range msg.Metadata {
+		am.Metadata = append(am.Metadata, sampledMetadataItem{Key: string(e.Key), Value: string(e.Value)})
+	}
+	return am
+}
+
+// archiveBatchKey identifies the batch of a message. Batches are
+// partitioned by date and topic.
+type archiveBatchKey struct {
+	date  string
+	topic string
+}
+
+// archiveBatch is a gzip-compressed batch of messages encoded using the
+// JSON Lines format.
+type archiveBatch struct {
+	key     archiveBatchKey
+	seq     int
+	created time.Time
+	n       int
+
+	buf bytes.Buffer
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+// newArchiveBatch returns an empty batch with the provided key. seq
+// distinguishes the batches created by the same instance at the same time.
+func newArchiveBatch(key archiveBatchKey, seq int, created time.Time) *archiveBatch {
+	b := &archiveBatch{key: key, seq: seq, created: created}
+	b.gz = gzip.NewWriter(&b.buf)
+	b.enc = json.NewEncoder(b.gz)
+	return b
+}
+
+// archiver appends the consumed messages to compressed batches that are
+// uploaded to S3 when they are full or old enough. The batches are kept in
+// memory, so the messages not uploaded yet are lost if the process crashes.
+// It is safe for concurrent use.
+type archiver struct {
+	cli      objectPutter
+	bucket   string
+	prefix   string
+	instance string
+	size     int
+	interval time.Duration
+	now      func() time.Time
+
+	// full is notified when a batch is full.
+	full chan struct{}
+
+	mu      sync.Mutex
+	batches map[archiveBatchKey]*archiveBatch
+	pending []*archiveBatch
+	seq     int
+}
+
+// newArchiver returns the [archiver] configured in cfg. If archiving is
+// disabled, it returns nil. The S3 credentials are read from the standard
+// AWS environment variables.
+func newArchiver(cfg config) (*archiver, error) {
+	if cfg.ArchiveS3URL == "" {
+		return nil, nil
+	}
+
+	bucket, prefix, err := s3.ParseURL(cfg.ArchiveS3URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 URL: %w", err)
+	}
+	cli, err := s3.NewClient(s3.ConfigFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("error creating S3 client: %w", err)
+	}
+	instance, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	return newArchiverWithClient(cli, bucket, prefix, instance, cfg.ArchiveBatchSize, cfg.ArchiveBatchInterval), nil
+}
+
+// newArchiverWithClient returns an [archiver] that uploads the batches to
+// the provided bucket and prefix using cli. Batches are uploaded when they
+// contain size messages or when they are older than interval.
+func newArchiverWithClient(cli objectPutter, bucket, prefix, instance string, size int, interval time.Duration) *archiver {
+	return &archiver{
+		cli:      cli,
+		bucket:   bucket,
+		prefix:   prefix,
+		instance: instance,
+		size:     size,
+		interval: interval,
+		now:      time.Now,
+		full:     make(chan struct{}, 1),
+		batches:  make(map[archiveBatchKey]*archiveBatch),
+	}
+}
+
+// write appends msg to the batch of its date and topic. The date is taken
+// from the timestamp of the message or, if it is not available, from the
+// current time.
+func (a *archiver) write(msg stream.Message) error {
+	now := a.now()
+	ts := msg.Timestamp
+	if ts.IsZero() {
+		ts = now
+	}
+	key := archiveBatchKey{date: ts.UTC().Format("2006-01-02"), topic: msg.Position.Topic}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, ok := a.batches[key]
+	if !ok {
+		a.seq++
+		b = newArchiveBatch(key, a.seq, now)
+		a.batches[key] = b
+	}
+	if err := b.enc.Encode(newArchivedMessage(msg)); err != nil {
+		return fmt.Errorf("could not encode message: %w", err)
+	}
+	b.n++
+
+	if b.n >= a.size {
+		a.seal(b)
+		select {
+		case a.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// seal moves b to the batches pending to be uploaded. If there are too
+// many pending batches, the oldest one is dropped. It must be called with
+// a.mu held.
+func (a *archiver) seal(b *archiveBatch) {
+	delete(a.batches, b.key)
+	if err := b.gz.Close(); err != nil {
+		log.Error.Printf("graph-vulcan-assets: could not compress archive batch: %v", err)
+		archivedMessagesTotal.Add(float64(b.n), archiveOutcomeDropped)
+		return
+	}
+
+	a.pending = append(a.pending, b)
+	if len(a.pending) > archiveMaxPending {
+		dropped := a.pending[0]
+		a.pending = a.pending[1:]
+		archivedMessagesTotal.Add(float64(dropped.n), archiveOutcomeDropped)
+		log.Error.Printf("graph-vulcan-assets: dropped archive batch of %v messages of topic %v", dropped.n, dropped.key.topic)
+	}
+}
+
+// sealOld seals the batches created before the provided time.
+func (a *archiver) sealOld(before time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, b := range a.batches {
+		if b.created.Before(before) {
+			a.seal(b)
+		}
+	}
+}
+
+// sealAll seals all the batches.
+func (a *archiver) sealAll() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, b := range a.batches {
+		a.seal(b)
+	}
+}
+
+// objectKey returns the key of the S3 object of b.
+func (a *archiver) objectKey(b *archiveBatch) string {
+	name := a.instance + "-" + b.created.UTC().Format("20060102T150405Z") + "-" + strconv.Itoa(b.seq) + ".jsonl.gz"
+	return path.Join(a.prefix, "date="+b.key.date, "topic="+b.key.topic, name)
+}
+
+// upload uploads the pending batches. The batches that cannot be uploaded
+// are kept to be retried.
+func (a *archiver) upload(ctx context.Context) {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+
+	var failed []*archiveBatch
+	for _, b := range pending {
+		key := a.objectKey(b)
+		if err := a.cli.PutObject(ctx, a.bucket, key, b.buf.Bytes(), "application/gzip"); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not upload archive batch %v: %v", key, err)
+			archivedMessagesTotal.Add(float64(b.n), archiveOutcomeFailed)
+			failed = append(failed, b)
+			continue
+		}
+		archivedMessagesTotal.Add(float64(b.n), archiveOutcomeArchived)
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pending = append(failed, a.pending...)
+	if n := len(a.pending) - archiveMaxPending; n > 0 {
+		for _, b := range a.pending[:n] {
+			archivedMessagesTotal.Add(float64(b.n), archiveOutcomeDropped)
+		}
+		a.pending = a.pending[n:]
+	}
+}
+
+// run uploads the batches when they are full or older than the batch
+// interval until the context is cancelled. Then, it uploads all the
+// remaining batches.
+func (a *archiver) run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.sealAll()
+			uctx, cancel := context.WithTimeout(context.Background(), archiveUploadTimeout)
+			a.upload(uctx)
+			cancel()
+			return
+		case <-ticker.C:
+			a.sealOld(a.now().Add(-a.interval))
+		case <-a.full:
+		}
+
+		uctx, cancel := context.WithTimeout(ctx, archiveUploadTimeout)
+		a.upload(uctx)
+		cancel()
+	}
+}
+
+// archivingProcessor is a [stream.Processor] that archives the received
+// messages before processing them. Archiving errors are logged and do not
+// interrupt the processing of the messages.
+type archivingProcessor struct {
+	proc stream.Processor
+	arch *archiver
+}
+
+// newArchivingProcessor returns a [stream.Processor] that processes the
+// messages of proc, archiving them with arch. If arch is nil, it returns
+// proc.
+func newArchivingProcessor(proc stream.Processor, arch *archiver) stream.Processor {
+	if arch == nil {
+		return proc
+	}
+	return archivingProcessor{proc: proc, arch: arch}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p archivingProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if err := p.arch.write(msg); err != nil {
+			archivedMessagesTotal.Inc(archiveOutcomeDropped)
+			log.Error.Printf("graph-vulcan-assets: could not archive message %v: %v", msg.Position, err)
+		}
+		return h(msg)
+	})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+// readArchive decodes the messages of an archive batch.
+func readArchive(t *testing.T, body string) []archivedMessage {
+	t.Helper()
+
+	gz, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("error decompressing batch: %v", err)
+	}
+
+	var msgs []archivedMessage
+	s := bufio.NewScanner(gz)
+	for s.Scan() {
+		var msg archivedMessage
+		if err := json.Unmarshal(s.Bytes(), &msg); err != nil {
+			t.Fatalf("error decoding message: %v", err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("error reading batch: %v", err)
+	}
+	return msgs
+}
+
+func archiveTestMessage(topic string, offset int64, ts time.Time) stream.Message {
+	return stream.Message{
+		Key:       []byte("team-1/asset-1"),
+		Value:     []byte(`{"id":"asset-1"}`),
+		Metadata:  []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("0.0.1")}},
+		Position:  stream.Position{Topic: topic, Partition: 1, Offset: offset},
+		Timestamp: ts,
+	}
+}
+
+func TestArchiver(t *testing.T) {
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	day1 := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+
+	objects := memObjectPutter{}
+	a := newArchiverWithClient(objects, "archive", "graph-vulcan-assets", "host-0", 2, time.Minute)
+	a.now = func() time.Time { return now }
+
+	msgs := []stream.Message{
+		archiveTestMessage("assets", 1, day1),
+		archiveTestMessage("assets", 2, now),
+		archiveTestMessage("assets", 3, now),
+		archiveTestMessage("findings", 1, now),
+	}
+	for _, msg := range msgs {
+		if err := a.write(msg); err != nil {
+			t.Fatalf("error archiving message: %v", err)
+		}
+	}
+
+	before := archivedMessagesTotal.Value(archiveOutcomeArchived)
+
+	// Only the full batch is uploaded.
+	a.upload(context.Background())
+	if len(objects) != 1 {
+		t.Fatalf("unexpected number of objects: want=1 got=%v", len(objects))
+	}
+
+	// The remaining batches are uploaded when they are too old.
+	a.sealOld(now.Add(time.Second))
+	a.upload(context.Background())
+
+	ts := func(t time.Time) *time.Time { return &t }
+	str := func(s string) *string { return &s }
+	archived := func(topic string, offset int64, t time.Time) archivedMessage {
+		return archivedMessage{
+			Topic:     topic,
+			Partition: 1,
+			Offset:    offset,
+			Timestamp: ts(t),
+			Key:       str("team-1/asset-1"),
+			Value:     str(`{"id":"asset-1"}`),
+			Metadata:  []sampledMetadataItem{{Key: "version", Value: "0.0.1"}},
+		}
+	}
+	want := map[string][]archivedMessage{
+		"archive/graph-vulcan-assets/date=2024-01-01/topic=assets/host-0-20240102T100000Z-1.jsonl.gz": {
+			archived("assets", 1, day1),
+		},
+		"archive/graph-vulcan-assets/date=2024-01-02/topic=assets/host-0-20240102T100000Z-2.jsonl.gz": {
+			archived("assets", 2, now),
+			archived("assets", 3, now),
+		},
+		"archive/graph-vulcan-assets/date=2024-01-02/topic=findings/host-0-20240102T100000Z-3.jsonl.gz": {
+			archived("findings", 1, now),
+		},
+	}
+	got := map[string][]archivedMessage{}
+	for k, v := range objects {
+		got[k] = readArchive(t, v)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("objects mismatch (-want +got):\n%v", diff)
+	}
+
+	if n := archivedMessagesTotal.Value(archiveOutcomeArchived) - before; n != float64(len(msgs)) {
+		t.Errorf("unexpected number of archived messages: want=%v got=%v", len(msgs), n)
+	}
+}
+
+// failingObjectPutter is an [objectPutter] that always fails.
+type failingObjectPutter struct{}
+
+func (failingObjectPutter) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error {
+	return errors.New("unavailable")
+}
+
+func TestArchiverUploadError(t *testing.T) {
+	a := newArchiverWithClient(failingObjectPutter{}, "archive", "", "host-0", 1, time.Minute)
+
+	beforeDropped := archivedMessagesTotal.Value(archiveOutcomeDropped)
+
+	n := archiveMaxPending + 5
+	for i := 0; i < n; i++ {
+		if err := a.write(archiveTestMessage("assets", int64(i), time.Time{})); err != nil {
+			t.Fatalf("error archiving message: %v", err)
+		}
+		a.upload(context.Background())
+	}
+
+	if len(a.pending) != archiveMaxPending {
+		t.Errorf("unexpected number of pending batches: want=%v got=%v", archiveMaxPending, len(a.pending))
+	}
+	if got := archivedMessagesTotal.Value(archiveOutcomeDropped) - beforeDropped; got != float64(n-archiveMaxPending) {
+		t.Errorf("unexpected number of dropped messages: want=%v got=%v", n-archiveMaxPending, got)
+	}
+
+	// The oldest batches are dropped.
+	if offset := readArchive(t, a.pending[0].buf.String())[0].Offset; offset != int64(n-archiveMaxPending) {
+		t.Errorf("unexpected offset of the oldest pending batch: want=%v got=%v", n-archiveMaxPending, offset)
+	}
+}
+
+func TestArchiverRun(t *testing.T) {
+	objects := memObjectPutter{}
+	a := newArchiverWithClient(objects, "archive", "", "host-0", 100, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.run(ctx)
+		close(done)
+	}()
+
+	if err := a.write(archiveTestMessage("assets", 1, time.Time{})); err != nil {
+		t.Fatalf("error archiving message: %v", err)
+	}
+
+	// The pending batches are uploaded when the context is cancelled.
+	cancel()
+	<-done
+
+	if len(objects) != 1 {
+		t.Errorf("unexpected number of objects: want=1 got=%v", len(objects))
+	}
+}
+
+func TestArchivingProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		archiveTestMessage("assets", 1, time.Time{}),
+		archiveTestMessage("assets", 2, time.Time{}),
+	}
+
+	a := newArchiverWithClient(memObjectPutter{}, "archive", "", "host-0", 100, time.Hour)
+	proc := newArchivingProcessor(streamtest.NewMockProcessor(msgs), a)
+
+	var processed int
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+		processed++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if processed != len(msgs) {
+		t.Errorf("unexpected number of processed messages: want=%v got=%v", len(msgs), processed)
+	}
+	var archived int
+	for _, b := range a.batches {
+		archived += b.n
+	}
+	if archived != len(msgs) {
+		t.Errorf("unexpected number of archived messages: want=%v got=%v", len(msgs), archived)
+	}
+}
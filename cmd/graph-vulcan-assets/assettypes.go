@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// Policies applied to the messages of asset types that are not in
+// ALLOWED_ASSET_TYPES.
+const (
+	unknownTypePolicyAllow = "allow"
+	unknownTypePolicySkip  = "skip"
+	unknownTypePolicyDLQ   = "dlq"
+)
+
+// typeMetadataKey is the metadata key that contains the type of the asset
+// in the messages of the assets topic.
+const typeMetadataKey = "type"
+
+// dlqReasonUnknownType is the reason of the records sent to the dead letter
+// queue because their asset type is not allowed.
+const dlqReasonUnknownType = "unknown_asset_type"
+
+// assetTypeProcessor is a [stream.Processor] that applies a policy to the
+// messages whose asset type is not allowed, so the owners of the graph
+// schema control when a new asset type starts appearing in the Asset
+// Inventory. Messages without asset type are processed, so they are
+// reported as malformed.
+type assetTypeProcessor struct {
+	proc    stream.Processor
+	allowed map[string]bool
+	policy  string
+	topic   string
+	dlq     dlqProducer
+}
+
+// newAssetTypeProcessor returns a [assetTypeProcessor] that processes the
+// messages of proc whose asset type is in allowed and applies policy to the
+// rest. If policy is "dlq", they are sent to the provided topic using dlq.
+// If dlq is nil, they are skipped instead, so replaying the topic does not
+// send them to the dead letter queue again. If allowed is empty or the
+// policy is "allow", all the messages are processed.
+func newAssetTypeProcessor(proc stream.Processor, allowed []string, policy, topic string, dlq dlqProducer) assetTypeProcessor {
+	return assetTypeProcessor{
+		proc:    proc,
+		allowed: stringSet(allowed),
+		policy:  policy,
+		topic:   topic,
+		dlq:     dlq,
+	}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p assetTypeProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	if len(p.allowed) == 0 || p.policy == unknownTypePolicyAllow {
+		return p.proc.Process(ctx, entity, h)
+	}
+
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		typ := metadataValue(msg, typeMetadataKey)
+		if typ == "" || p.allowed[typ] {
+			return h(msg)
+		}
+
+		unknownAssetTypesTotal.Inc(typ, p.policy)
+
+		if p.policy == unknownTypePolicyDLQ && p.dlq != nil {
+			log.Error.Printf("graph-vulcan-assets: sending message %v (key %q) with unknown asset type %q to %v", msg.Position, msg.Key, typ, p.topic)
+			rec := dlqRecord(msg, dlqReasonUnknownType, 0)
+			if err := p.dlq.ProduceSync(ctx, p.topic, rec); err != nil {
+				return fmt.Errorf("could not send message to the dead letter queue: %w", err)
+			}
+			return nil
+		}
+
+		log.Error.Printf("graph-vulcan-assets: skipping message %v (key %q) with unknown asset type %q", msg.Position, msg.Key, typ)
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestAssetTypeProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{
+			Key:      []byte("team-1/asset-1"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("type"), Value: []byte("Hostname")}},
+		},
+		{
+			Key:      []byte("team-1/asset-2"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("type"), Value: []byte("KubernetesCluster")}},
+		},
+		{
+			Key: []byte("team-1/asset-3"),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		policy      string
+		dlq         *fakeDLQProducer
+		wantKeys    []string
+		wantDLQKeys []string
+		wantSkipped float64
+	}{
+		{
+			name:     "allow",
+			allowed:  []string{"Hostname"},
+			policy:   unknownTypePolicyAllow,
+			wantKeys: []string{"team-1/asset-1", "team-1/asset-2", "team-1/asset-3"},
+		},
+		{
+			name:     "empty allowlist",
+			allowed:  nil,
+			policy:   unknownTypePolicySkip,
+			wantKeys: []string{"team-1/asset-1", "team-1/asset-2", "team-1/asset-3"},
+		},
+		{
+			name:        "skip",
+			allowed:     []string{"Hostname"},
+			policy:      unknownTypePolicySkip,
+			wantKeys:    []string{"team-1/asset-1", "team-1/asset-3"},
+			wantSkipped: 1,
+		},
+		{
+			name:        "dlq",
+			allowed:     []string{"Hostname"},
+			policy:      unknownTypePolicyDLQ,
+			dlq:         &fakeDLQProducer{},
+			wantKeys:    []string{"team-1/asset-1", "team-1/asset-3"},
+			wantDLQKeys: []string{"team-1/asset-2"},
+			wantSkipped: 1,
+		},
+		{
+			name:        "dlq without producer",
+			allowed:     []string{"Hostname"},
+			policy:      unknownTypePolicyDLQ,
+			wantKeys:    []string{"team-1/asset-1", "team-1/asset-3"},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dlq dlqProducer
+			if tt.dlq != nil {
+				dlq = tt.dlq
+			}
+
+			before := unknownAssetTypesTotal.Value("KubernetesCluster", tt.policy)
+
+			proc := newAssetTypeProcessor(streamtest.NewMockProcessor(msgs), tt.allowed, tt.policy, "dlq", dlq)
+
+			var keys []string
+			err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+				keys = append(keys, string(msg.Key))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantKeys, keys); diff != "" {
+				t.Errorf("processed messages mismatch (-want +got):\n%v", diff)
+			}
+
+			if tt.dlq != nil {
+				var dlqKeys []string
+				for _, msg := range tt.dlq.msgs {
+					dlqKeys = append(dlqKeys, string(msg.Key))
+					if reason := metadataValue(msg, dlqReasonKey); reason != dlqReasonUnknownType {
+						t.Errorf("unexpected reason: %v", reason)
+					}
+				}
+				if diff := cmp.Diff(tt.wantDLQKeys, dlqKeys); diff != "" {
+					t.Errorf("dead letter queue mismatch (-want +got):\n%v", diff)
+				}
+			}
+
+			if n := unknownAssetTypesTotal.Value("KubernetesCluster", tt.policy) - before; n != tt.wantSkipped {
+				t.Errorf("unexpected number of unknown asset types: got: %v, want: %v", n, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// maskedValue replaces the values of the secret variables in the startup
+// banner.
+const maskedValue = "*****"
+
+// startupBanner is the record logged when the consumer starts. It contains
+// everything needed to reconstruct the runtime behavior of the consumer
+// from its logs.
+type startupBanner struct {
+	// Version is the version of the consumer.
+	Version string `json:"version"`
+
+	// GoVersion is the version of Go used to build the consumer.
+	GoVersion string `json:"go_version"`
+
+	// LibrdkafkaVersion is the version of librdkafka.
+	LibrdkafkaVersion string `json:"librdkafka_version"`
+
+	// Config contains the effective value of every environment
+	// variable. The values of the secret variables are masked.
+	Config map[string]string `json:"config"`
+
+	// Defaults are the variables that were not set, so their default
+	// value is used.
+	Defaults []string `json:"defaults"`
+
+	// Features are the optional features enabled by the
+	// configuration and the command line flags.
+	Features []string `json:"features"`
+}
+
+// newStartupBanner returns the startup banner of the consumer run with the
+// provided configuration and options.
+func newStartupBanner(cfg config, opts runOptions) startupBanner {
+	values, defaults := effectiveConfig(cfg)
+	return startupBanner{
+		Version:           consumerVersion(),
+		GoVersion:         runtime.Version(),
+		LibrdkafkaVersion: kafka.LibraryVersion(),
+		Config:            values,
+		Defaults:          defaults,
+		Features:          enabledFeatures(cfg, opts),
+	}
+}
+
+// logStartupBanner logs the startup banner as a single JSON record.
+func logStartupBanner(cfg config, opts runOptions) {
+	b, err := json.Marshal(newStartupBanner(cfg, opts))
+	if err != nil {
+		log.Error.Printf("graph-vulcan-assets: could not encode startup banner: %v", err)
+		return
+	}
+	log.Info.Printf("graph-vulcan-assets: startup %s", b)
+}
+
+// effectiveConfig returns the value of every environment variable read
+// into cfg and the variables that use their default value, sorted
+// alphabetically. The values of the variables with the "secret" tag are
+// masked, as well as the passwords of the URLs.
+func effectiveConfig(cfg config) (map[string]string, []string) {
+	values := make(map[string]string)
+	defaults := []string{}
+
+	v := reflect.ValueOf(cfg)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts, _ := strings.Cut(f.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		if s, ok := lookupEnv(name); !ok || (s == "" && opts != "allowempty") {
+			if _, ok := f.Tag.Lookup("default"); ok {
+				defaults = append(defaults, name)
+			}
+		}
+
+		s := formatField(v.Field(i))
+		switch {
+		case s == "":
+		case f.Tag.Get("secret") == "true":
+			s = maskedValue
+		default:
+			s = redactURL(s)
+		}
+		values[name] = s
+	}
+
+	sort.Strings(defaults)
+	return values, defaults
+}
+
+// formatField returns the value of a field of [config] with the format of
+// the corresponding environment variable.
+func formatField(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "1"
+		}
+		return "0"
+	case reflect.Slice:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(elems, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// redactURL returns s with the password replaced by "xxxxx" if it is a URL
+// with user information. Otherwise, it returns s.
+func redactURL(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
+
+// enabledFeatures returns the optional features enabled by the provided
+// configuration and options.
+func enabledFeatures(cfg config, opts runOptions) []string {
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{"admin_api", cfg.AdminAddr != ""},
+		{"archive", cfg.ArchiveS3URL != ""},
+		{"asset_state", cfg.StoreAssetState},
+		{"audit_diff", cfg.AuditDiff},
+		{"batching", cfg.InventoryBatchSize > 1},
+		{"batch_priority", cfg.InventoryBatchPriority},
+		{"catch_up", opts.catchUp},
+		{"chaos", cfg.ChaosMode},
+		{"checkpoint", cfg.CheckpointGremlinEndpoint != ""},
+		{"daily_report", cfg.DailyReportWebhook != "" || cfg.DailyReportS3URL != ""},
+		{"derive_ip_ranges", cfg.DeriveIPRanges},
+		{"dlq", cfg.DLQTopic != ""},
+		{"from_beginning", opts.fromBeginning},
+		{"heartbeat", cfg.HeartbeatFile != ""},
+		{"maintenance", cfg.MaintenanceFile != ""},
+		{"ownership_transfers", cfg.OwnershipTransferWindow > 0},
+		{"payload_schema", cfg.PayloadSchemaPolicy != "" && cfg.PayloadSchemaPolicy != schemaPolicyOff},
+		{"provenance", cfg.StoreProvenance},
+		{"resync", cfg.ResyncSchedule != ""},
+		{"routing", cfg.RoutingFile != ""},
+		{"sampling", cfg.SamplePercent > 0},
+		{"team_mapping", cfg.TeamMappingFile != ""},
+		{"teams_as_assets", cfg.InventoryTeamsAsAssets},
+		{"tombstone_index", cfg.TombstoneIndexTTL > 0},
+		{"vulcan_ids", cfg.StoreVulcanIDs},
+		{"wal", cfg.WALFile != ""},
+	}
+
+	enabled := []string{}
+	for _, f := range features {
+		if f.enabled {
+			enabled = append(enabled, f.name)
+		}
+	}
+	return enabled
+}
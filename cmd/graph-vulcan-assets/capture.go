@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// runCapture implements the capture command. It reads messages from the
+// assets topic and writes them as streamtest fixtures.
+func runCapture(args []string) error {
+	fs := flag.NewFlagSet("capture", flag.ContinueOnError)
+	n := fs.Int("n", 100, "number of messages to capture")
+	output := fs.String("o", "", "output file (default: standard output)")
+	fromTimestamp := fs.String("from-timestamp", "", "capture messages produced after this RFC3339 timestamp")
+	fromOffset := fs.Int64("from-offset", 0, "capture messages starting at this offset in every partition")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *n <= 0 {
+		return errors.New("-n must be greater than zero")
+	}
+
+	window := kafka.Window{FromOffset: *fromOffset}
+	if *fromTimestamp != "" {
+		t, err := time.Parse(time.RFC3339, *fromTimestamp)
+		if err != nil {
+			return fmt.Errorf("invalid -from-timestamp: %w", err)
+		}
+		window.FromTimestamp = t
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	kcfg := kafkaConfig(cfg)
+	kcfg["group.id"] = throwawayGroupID(cfg, "capture")
+
+	proc, err := kafka.NewReplayProcessor(kcfg, window)
+	if err != nil {
+		return fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	msgs, err := capture(context.Background(), proc, *n, cfg.RedactAnnotations)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("could not create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := streamtest.WriteJSON(w, msgs); err != nil {
+		return fmt.Errorf("could not write messages: %w", err)
+	}
+
+	log.Info.Printf("graph-vulcan-assets: captured %v messages", len(msgs))
+
+	return nil
+}
+
+// capture reads up to n messages of the assets topic from proc. The values
+// of the annotations whose key matches any of the provided patterns are
+// redacted.
+func capture(ctx context.Context, proc stream.Processor, n int, patterns []string) ([]stream.Message, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var msgs []stream.Message
+	err := proc.Process(ctx, vulcan.AssetsEntityName, func(msg stream.Message) error {
+		if len(msgs) >= n {
+			return nil
+		}
+
+		value, err := redactMessageValue(msg.Value, patterns)
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: capturing message with key %q without redaction: %v", msg.Key, err)
+		} else {
+			msg.Value = value
+		}
+		msgs = append(msgs, msg)
+
+		if len(msgs) >= n {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error processing messages: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// redactMessageValue masks the value of the annotations of the asset payload
+// encoded in value whose key matches any of the provided patterns. Unknown
+// fields are preserved, so the fixture still reproduces unexpected payloads.
+// Nil values are returned unmodified.
+func redactMessageValue(value []byte, patterns []string) ([]byte, error) {
+	if value == nil || len(patterns) == 0 {
+		return value, nil
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(value, &payload); err != nil {
+		return nil, fmt.Errorf("could not unmarshal payload: %w", err)
+	}
+
+	raw, ok := payload["Annotations"]
+	if !ok {
+		return value, nil
+	}
+
+	var annotations []map[string]any
+	err := json.Unmarshal(raw, &annotations)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal annotations: %w", err)
+	}
+
+	redacted := false
+	for _, a := range annotations {
+		key, _ := a["Key"].(string)
+		if _, ok := a["Value"]; ok && redactedKey(key, patterns) {
+			a["Value"] = redactedValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return value, nil
+	}
+
+	if payload["Annotations"], err = json.Marshal(annotations); err != nil {
+		return nil, fmt.Errorf("could not marshal annotations: %w", err)
+	}
+
+	value, err = json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal payload: %w", err)
+	}
+	return value, nil
+}
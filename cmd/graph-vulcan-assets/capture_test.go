@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestCapture(t *testing.T) {
+	base := streamtest.NewAssetMessage()
+	msgs := []stream.Message{
+		base.WithAnnotations(map[string]string{"api_token": "s3cr3t", "owner": "alice"}).Message(),
+		base.Tombstone().Message(),
+		{Key: []byte("key"), Value: []byte("malformed")},
+	}
+
+	tests := []struct {
+		name     string
+		n        int
+		patterns []string
+		want     []stream.Message
+	}{
+		{
+			name:     "redacted",
+			n:        3,
+			patterns: []string{"*token*"},
+			want: []stream.Message{
+				base.WithAnnotations(map[string]string{"api_token": redactedValue, "owner": "alice"}).Message(),
+				base.Tombstone().Message(),
+				{Key: []byte("key"), Value: []byte("malformed")},
+			},
+		},
+		{
+			name:     "no patterns",
+			n:        3,
+			patterns: nil,
+			want:     msgs,
+		},
+		{
+			name:     "limit",
+			n:        2,
+			patterns: nil,
+			want:     msgs[:2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := streamtest.NewMockProcessor(msgs)
+
+			got, err := capture(context.Background(), mp, tt.n, tt.patterns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, equateJSONValues, cmpopts.IgnoreFields(stream.Message{}, "Position")); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+// equateJSONValues considers equal the message values that encode the same
+// JSON document, regardless of the order of the object keys.
+var equateJSONValues = cmp.FilterPath(func(p cmp.Path) bool {
+	sf, ok := p.Last().(cmp.StructField)
+	return ok && sf.Name() == "Value" && p.Index(-2).Type() == reflect.TypeOf(stream.Message{})
+}, cmp.Comparer(func(x, y []byte) bool {
+	var vx, vy any
+	if json.Unmarshal(x, &vx) != nil || json.Unmarshal(y, &vy) != nil {
+		return bytes.Equal(x, y)
+	}
+	return reflect.DeepEqual(vx, vy)
+}))
+
+func TestRedactMessageValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		patterns   []string
+		want       string
+		wantNilErr bool
+	}{
+		{
+			name:       "redacted annotation",
+			value:      `{"Id":"1","Annotations":[{"Key":"password","Value":"s3cr3t"},{"Key":"owner","Value":"alice"}],"Unknown":true}`,
+			patterns:   []string{"*password*"},
+			want:       `{"Annotations":[{"Key":"password","Value":"[REDACTED]"},{"Key":"owner","Value":"alice"}],"Id":"1","Unknown":true}`,
+			wantNilErr: true,
+		},
+		{
+			name:       "nothing to redact",
+			value:      `{"Id":"1","Annotations":[{"Key":"owner","Value":"alice"}]}`,
+			patterns:   []string{"*password*"},
+			want:       `{"Id":"1","Annotations":[{"Key":"owner","Value":"alice"}]}`,
+			wantNilErr: true,
+		},
+		{
+			name:       "no annotations",
+			value:      `{"Id":"1"}`,
+			patterns:   []string{"*password*"},
+			want:       `{"Id":"1"}`,
+			wantNilErr: true,
+		},
+		{
+			name:       "malformed payload",
+			value:      `malformed`,
+			patterns:   []string{"*password*"},
+			want:       "",
+			wantNilErr: false,
+		},
+		{
+			name:       "malformed annotations",
+			value:      `{"Annotations":"malformed"}`,
+			patterns:   []string{"*password*"},
+			want:       "",
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactMessageValue([]byte(tt.value), tt.patterns)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("unexpected value: want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
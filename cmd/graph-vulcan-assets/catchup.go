@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// catchUpInterval is the time between two consecutive checks of the lag
+// in catch-up mode.
+const catchUpInterval = 10 * time.Second
+
+// catchUpProcessor is the processor whose batching is relaxed in catch-up
+// mode. It is implemented by [kafka.AloProcessor].
+type catchUpProcessor interface {
+	GroupStatus() (kafka.GroupStatus, error)
+	SetBatching(b kafka.Batching)
+}
+
+// catchUp relaxes the batching of a processor until its lag falls below a
+// threshold or a maximum duration elapses. Then, it restores the normal
+// batching.
+type catchUp struct {
+	proc        catchUpProcessor
+	threshold   int64
+	maxDuration time.Duration
+	relaxed     kafka.Batching
+	normal      kafka.Batching
+	interval    time.Duration
+	now         func() time.Time
+}
+
+// newCatchUp returns the [catchUp] of proc configured in cfg.
+func newCatchUp(proc catchUpProcessor, cfg config) catchUp {
+	return catchUp{
+		proc:        proc,
+		threshold:   int64(cfg.CatchUpLagThreshold),
+		maxDuration: cfg.CatchUpMaxDuration,
+		relaxed: kafka.Batching{
+			Size:        cfg.CatchUpBatchSize,
+			Interval:    cfg.InventoryBatchInterval,
+			Parallelism: cfg.CatchUpParallelism,
+			Priority:    messagePriority(cfg),
+		},
+		normal:   inventoryBatching(cfg),
+		interval: catchUpInterval,
+		now:      time.Now,
+	}
+}
+
+// inventoryBatching returns the batching of the messages configured in
+// cfg. Batching is disabled if the batch size is lower than two.
+func inventoryBatching(cfg config) kafka.Batching {
+	if cfg.InventoryBatchSize <= 1 {
+		return kafka.Batching{}
+	}
+	return kafka.Batching{
+		Size:        cfg.InventoryBatchSize,
+		Interval:    cfg.InventoryBatchInterval,
+		Parallelism: cfg.InventoryParallelism,
+		Priority:    messagePriority(cfg),
+	}
+}
+
+// totalLag returns the sum of the lag of the partitions in status. It
+// returns false if no partition is assigned.
+func totalLag(status kafka.GroupStatus) (int64, bool) {
+	if len(status.Partitions) == 0 {
+		return 0, false
+	}
+	var lag int64
+	for _, p := range status.Partitions {
+		lag += p.Lag
+	}
+	return lag, true
+}
+
+// catchUpProgress returns the percentage of the initial lag that has been
+// processed.
+func catchUpProgress(initial, lag int64) float64 {
+	if initial <= 0 || lag <= 0 {
+		return 100
+	}
+	if lag >= initial {
+		return 0
+	}
+	return float64(initial-lag) / float64(initial) * 100
+}
+
+// run enables the relaxed batching and checks the lag periodically,
+// logging the progress, until it falls below the threshold, the maximum
+// duration elapses or the context is cancelled. Then, it restores the
+// normal batching.
+func (c catchUp) run(ctx context.Context) {
+	start := c.now()
+	log.Info.Printf("graph-vulcan-assets: catch-up started (threshold=%v maxDuration=%v batchSize=%v parallelism=%v)",
+		c.threshold, c.maxDuration, c.relaxed.Size, c.relaxed.Parallelism)
+	c.proc.SetBatching(c.relaxed)
+	defer c.proc.SetBatching(c.normal)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	initial := int64(-1)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		elapsed := c.now().Sub(start)
+
+		status, err := c.proc.GroupStatus()
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: catch-up: could not get lag: %v", err)
+		} else if lag, ok := totalLag(status); ok {
+			if initial < 0 {
+				initial = lag
+			}
+			if lag <= c.threshold {
+				log.Info.Printf("graph-vulcan-assets: catch-up finished in %v: lag is %v messages", elapsed.Round(time.Second), lag)
+				return
+			}
+			log.Info.Printf("graph-vulcan-assets: catch-up progress: %.1f%% (lag %v of %v messages)", catchUpProgress(initial, lag), lag, initial)
+		}
+
+		if elapsed >= c.maxDuration {
+			log.Error.Printf("graph-vulcan-assets: catch-up stopped after %v without reaching the lag threshold", elapsed.Round(time.Second))
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// fakeCatchUpProcessor is a [catchUpProcessor] that returns the provided
+// lags in order. The last one is repeated.
+type fakeCatchUpProcessor struct {
+	mu       sync.Mutex
+	lags     []int64
+	batching []kafka.Batching
+}
+
+func (p *fakeCatchUpProcessor) GroupStatus() (kafka.GroupStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lag := p.lags[0]
+	if len(p.lags) > 1 {
+		p.lags = p.lags[1:]
+	}
+	return kafka.GroupStatus{
+		Partitions: []kafka.PartitionStatus{
+			{Partition: 0, Lag: lag / 2},
+			{Partition: 1, Lag: lag - lag/2},
+		},
+	}, nil
+}
+
+func (p *fakeCatchUpProcessor) SetBatching(b kafka.Batching) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.batching = append(p.batching, b)
+}
+
+func TestCatchUpRun(t *testing.T) {
+	relaxed := kafka.Batching{Size: 500, Interval: time.Second, Parallelism: 16}
+	normal := kafka.Batching{}
+
+	tests := []struct {
+		name    string
+		lags    []int64
+		elapsed time.Duration
+	}{
+		{
+			name:    "threshold",
+			lags:    []int64{1000, 500, 100},
+			elapsed: time.Second,
+		},
+		{
+			name:    "max duration",
+			lags:    []int64{1000},
+			elapsed: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := &fakeCatchUpProcessor{lags: tt.lags}
+
+			now := time.Now()
+			c := catchUp{
+				proc:        proc,
+				threshold:   100,
+				maxDuration: 5 * time.Minute,
+				relaxed:     relaxed,
+				normal:      normal,
+				interval:    time.Millisecond,
+				now: func() time.Time {
+					now = now.Add(tt.elapsed)
+					return now
+				},
+			}
+
+			done := make(chan struct{})
+			go func() {
+				c.run(context.Background())
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("catch-up did not finish")
+			}
+
+			if diff := cmp.Diff([]kafka.Batching{relaxed, normal}, proc.batching); diff != "" {
+				t.Errorf("batching mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCatchUpProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial int64
+		lag     int64
+		want    float64
+	}{
+		{name: "started", initial: 1000, lag: 1000, want: 0},
+		{name: "half", initial: 1000, lag: 500, want: 50},
+		{name: "finished", initial: 1000, lag: 0, want: 100},
+		{name: "lag increased", initial: 1000, lag: 2000, want: 0},
+		{name: "no initial lag", initial: 0, lag: 0, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catchUpProgress(tt.initial, tt.lag); got != tt.want {
+				t.Errorf("unexpected progress: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInventoryBatching(t *testing.T) {
+	cfg := config{InventoryBatchSize: 1, InventoryBatchInterval: time.Second, InventoryParallelism: 4}
+	if diff := cmp.Diff(kafka.Batching{}, inventoryBatching(cfg)); diff != "" {
+		t.Errorf("batching mismatch (-want +got):\n%v", diff)
+	}
+
+	cfg.InventoryBatchSize = 10
+	want := kafka.Batching{Size: 10, Interval: time.Second, Parallelism: 4}
+	if diff := cmp.Diff(want, inventoryBatching(cfg)); diff != "" {
+		t.Errorf("batching mismatch (-want +got):\n%v", diff)
+	}
+}
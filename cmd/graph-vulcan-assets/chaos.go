@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// chaos injects faults in the consumer, so its resilience features can be
+// exercised in integration environments. It must never be enabled in
+// production.
+type chaos struct {
+	inventoryErrorPercent int
+	inventoryLatency      time.Duration
+	handlerPanicPercent   int
+
+	// rnd returns a pseudo-random number in [0, 100).
+	rnd func() int
+
+	// sleep pauses the current goroutine for the provided duration.
+	sleep func(time.Duration)
+}
+
+// newChaos returns the [chaos] configured in cfg. If the chaos mode is
+// disabled, it returns nil.
+func newChaos(cfg config) *chaos {
+	if !cfg.ChaosMode {
+		return nil
+	}
+	return &chaos{
+		inventoryErrorPercent: cfg.ChaosInventoryErrorPercent,
+		inventoryLatency:      cfg.ChaosInventoryLatency,
+		handlerPanicPercent:   cfg.ChaosHandlerPanicPercent,
+		rnd:                   newChaosRand(time.Now().UnixNano()),
+		sleep:                 time.Sleep,
+	}
+}
+
+// newChaosRand returns a function that returns pseudo-random numbers in
+// [0, 100) from a source initialized with seed. The global source of
+// math/rand is deterministic unless it is seeded, so every run of the
+// consumer would inject the same faults. The returned function is safe for
+// concurrent use.
+func newChaosRand(seed int64) func() int {
+	var mu sync.Mutex
+	r := rand.New(rand.NewSource(seed))
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return r.Intn(100)
+	}
+}
+
+// inject reports whether a fault with the provided probability, in
+// percent, must be injected.
+func (c *chaos) inject(percent int) bool {
+	return percent > 0 && c.rnd() < percent
+}
+
+// clientOptions returns the options that make an Asset Inventory client
+// send its requests through the chaos proxy. The methods of a nil chaos
+// return no options.
+func (c *chaos) clientOptions() []inventory.ClientOption {
+	if c == nil {
+		return nil
+	}
+	wrap := func(next http.RoundTripper) http.RoundTripper {
+		return chaosTransport{next: next, chaos: c}
+	}
+	return []inventory.ClientOption{inventory.WithTransportWrapper(wrap)}
+}
+
+// chaosTransport is an [http.RoundTripper] that delays the requests and
+// replies to some of them with an error instead of sending them to the
+// Asset Inventory.
+type chaosTransport struct {
+	next  http.RoundTripper
+	chaos *chaos
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.chaos.inventoryLatency > 0 {
+		t.chaos.sleep(t.chaos.inventoryLatency)
+	}
+
+	if !t.chaos.inject(t.chaos.inventoryErrorPercent) {
+		return t.next.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	chaosFaultsTotal.Inc("inventory_error")
+	log.Debug.Printf("graph-vulcan-assets: chaos: injecting error in %v %v", req.Method, req.URL.Path)
+	body := "chaos: injected error"
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable)),
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// handler returns a handler that calls h, except for some of the messages,
+// whose processing fails with an injected panic. The panic is recovered and
+// returned as an error, so it simulates a handler failure instead of killing
+// the process, which would take down the other messages of a batch being
+// processed concurrently. The methods of a nil chaos return h.
+func (c *chaos) handler(h vulcan.AssetHandler) vulcan.AssetHandler {
+	if c == nil {
+		return h
+	}
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		if c.inject(c.handlerPanicPercent) {
+			chaosFaultsTotal.Inc("handler_panic")
+			return injectPanic(payload)
+		}
+		return h(payload, isNil)
+	}
+}
+
+// injectPanic panics while processing payload and returns the recovered
+// panic as an error.
+func injectPanic(payload vulcan.AssetPayload) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered panic: %v", r)
+		}
+	}()
+	log.Debug.Printf("graph-vulcan-assets: chaos: injecting panic processing asset %v/%v", payload.AssetType, payload.Identifier)
+	panic(fmt.Sprintf("chaos: injected panic processing asset %v/%v", payload.AssetType, payload.Identifier))
+}
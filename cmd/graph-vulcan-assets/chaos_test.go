@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// sequence returns a function that returns the provided numbers in order.
+// The last one is repeated.
+func sequence(nums ...int) func() int {
+	return func() int {
+		n := nums[0]
+		if len(nums) > 1 {
+			nums = nums[1:]
+		}
+		return n
+	}
+}
+
+func TestNewChaosDisabled(t *testing.T) {
+	c := newChaos(config{ChaosInventoryErrorPercent: 100})
+	if c != nil {
+		t.Fatalf("unexpected chaos: %+v", c)
+	}
+	if opts := c.clientOptions(); opts != nil {
+		t.Errorf("unexpected client options: %v", opts)
+	}
+
+	called := false
+	h := c.handler(func(payload vulcan.AssetPayload, isNil bool) error {
+		called = true
+		return nil
+	})
+	if err := h(vulcan.AssetPayload{}, false); err != nil || !called {
+		t.Errorf("unexpected handler result: err=%v called=%v", err, called)
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := &chaos{
+		inventoryErrorPercent: 50,
+		inventoryLatency:      time.Second,
+		rnd:                   sequence(10, 90),
+		sleep:                 func(d time.Duration) { slept = append(slept, d) },
+	}
+
+	icli, err := inventory.NewClient(srv.URL, false, c.clientOptions()...)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	before := chaosFaultsTotal.Value("inventory_error")
+
+	// The first request is replied by the chaos proxy.
+	if err := icli.Ping(); !inventory.IsTransient(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := icli.Ping(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("unexpected number of requests: want=1 got=%v", requests)
+	}
+	if len(slept) != 2 || slept[0] != time.Second {
+		t.Errorf("unexpected latency: %v", slept)
+	}
+	if got := chaosFaultsTotal.Value("inventory_error") - before; got != 1 {
+		t.Errorf("unexpected number of faults: want=1 got=%v", got)
+	}
+}
+
+func TestChaosHandler(t *testing.T) {
+	c := &chaos{
+		handlerPanicPercent: 50,
+		rnd:                 sequence(10, 90),
+	}
+
+	var calls int
+	h := c.handler(func(payload vulcan.AssetPayload, isNil bool) error {
+		calls++
+		return nil
+	})
+
+	err := h(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "example.com"}, false)
+	if err == nil || !strings.Contains(err.Error(), "chaos: injected panic processing asset Hostname/example.com") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := h(vulcan.AssetPayload{AssetType: "Hostname", Identifier: "example.com"}, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("unexpected number of calls: want=1 got=%v", calls)
+	}
+}
+
+func TestNewChaosRand(t *testing.T) {
+	rnd := newChaosRand(1)
+	other := newChaosRand(2)
+
+	same := true
+	for i := 0; i < 100; i++ {
+		n := rnd()
+		if n < 0 || n >= 100 {
+			t.Fatalf("number out of range: %v", n)
+		}
+		if n != other() {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("different seeds returned the same sequence")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/log"
+)
+
+// checkpointStore stores the offsets processed in every partition of a
+// topic. It is implemented by [checkpoint.Store].
+type checkpointStore interface {
+	Load(topic string) (checkpoint.Offsets, error)
+	Save(topic string, offsets checkpoint.Offsets) error
+}
+
+// offsetSource provides the offsets processed by the consumer and the
+// offsets available in kafka. It is implemented by [kafka.AloProcessor].
+type offsetSource interface {
+	ProcessedOffsets() map[int32]int64
+	LowWatermarks(topic string) (map[int32]int64, error)
+}
+
+// checkpointGaps returns the partitions of topic with messages that were
+// deleted from kafka before being processed, according to the checkpoint
+// stored in store.
+func checkpointGaps(store checkpointStore, src offsetSource, topic string) ([]int32, error) {
+	saved, err := store.Load(topic)
+	if err != nil {
+		return nil, fmt.Errorf("could not load checkpoint: %w", err)
+	}
+
+	low, err := src.LowWatermarks(topic)
+	if err != nil {
+		return nil, fmt.Errorf("could not get low watermarks: %w", err)
+	}
+
+	return checkpoint.Gaps(saved, low), nil
+}
+
+// checkpointer periodically stores the offsets processed by the consumer.
+type checkpointer struct {
+	store checkpointStore
+	src   offsetSource
+	topic string
+
+	// pending contains the offsets that have not been stored yet.
+	pending checkpoint.Offsets
+}
+
+// newCheckpointer returns a checkpointer that stores the offsets of topic
+// provided by src into store.
+func newCheckpointer(store checkpointStore, src offsetSource, topic string) *checkpointer {
+	return &checkpointer{
+		store:   store,
+		src:     src,
+		topic:   topic,
+		pending: make(checkpoint.Offsets),
+	}
+}
+
+// save stores the offsets processed since the last successful call. If the
+// offsets cannot be stored, they are retried in the next call.
+func (cp *checkpointer) save() error {
+	for p, offset := range cp.src.ProcessedOffsets() {
+		cp.pending[p] = offset
+	}
+	if len(cp.pending) == 0 {
+		return nil
+	}
+
+	if err := cp.store.Save(cp.topic, cp.pending); err != nil {
+		return fmt.Errorf("could not save checkpoint: %w", err)
+	}
+	log.Debug.Printf("graph-vulcan-assets: checkpoint saved: %v", cp.pending)
+
+	cp.pending = make(checkpoint.Offsets)
+	return nil
+}
+
+// run stores the processed offsets every interval until ctx is done. Before
+// returning, it stores the offsets processed since the last tick.
+func (cp *checkpointer) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := cp.save(); err != nil {
+				log.Error.Printf("graph-vulcan-assets: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := cp.save(); err != nil {
+				log.Error.Printf("graph-vulcan-assets: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+)
+
+// memStore is an in-memory [checkpointStore].
+type memStore struct {
+	offsets map[string]checkpoint.Offsets
+	err     error
+}
+
+func (s *memStore) Load(topic string) (checkpoint.Offsets, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	offsets := make(checkpoint.Offsets)
+	for p, o := range s.offsets[topic] {
+		offsets[p] = o
+	}
+	return offsets, nil
+}
+
+func (s *memStore) Save(topic string, offsets checkpoint.Offsets) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.offsets == nil {
+		s.offsets = make(map[string]checkpoint.Offsets)
+	}
+	if s.offsets[topic] == nil {
+		s.offsets[topic] = make(checkpoint.Offsets)
+	}
+	for p, o := range offsets {
+		s.offsets[topic][p] = o
+	}
+	return nil
+}
+
+// fakeOffsetSource is an [offsetSource] that returns predefined offsets.
+type fakeOffsetSource struct {
+	processed []map[int32]int64
+	low       map[int32]int64
+}
+
+func (src *fakeOffsetSource) ProcessedOffsets() map[int32]int64 {
+	if len(src.processed) == 0 {
+		return nil
+	}
+	offsets := src.processed[0]
+	src.processed = src.processed[1:]
+	return offsets
+}
+
+func (src *fakeOffsetSource) LowWatermarks(topic string) (map[int32]int64, error) {
+	return src.low, nil
+}
+
+func TestCheckpointGaps(t *testing.T) {
+	store := &memStore{
+		offsets: map[string]checkpoint.Offsets{
+			"assets": {0: 10, 1: 20},
+		},
+	}
+	src := &fakeOffsetSource{low: map[int32]int64{0: 5, 1: 50}}
+
+	gaps, err := checkpointGaps(store, src, "assets")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int32{1}, gaps); diff != "" {
+		t.Errorf("gaps mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestCheckpointerSave(t *testing.T) {
+	store := &memStore{}
+	src := &fakeOffsetSource{
+		processed: []map[int32]int64{
+			{0: 10, 1: 20},
+			{1: 25},
+			{0: 15, 2: 5},
+		},
+	}
+	cp := newCheckpointer(store, src, "assets")
+
+	if err := cp.save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Offsets that cannot be stored are retried in the next call.
+	store.err = errors.New("store error")
+	if err := cp.save(); err == nil {
+		t.Fatalf("expected error saving checkpoint")
+	}
+	store.err = nil
+
+	if err := cp.save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Nothing to store.
+	if err := cp.save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]checkpoint.Offsets{
+		"assets": {0: 15, 1: 25, 2: 5},
+	}
+	if diff := cmp.Diff(want, store.offsets); diff != "" {
+		t.Errorf("checkpoint mismatch (-want +got):\n%v", diff)
+	}
+}
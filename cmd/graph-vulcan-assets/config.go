@@ -0,0 +1,681 @@
+package main
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/cron"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/s3"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// envPrefix is the optional prefix of the environment variables. It allows
+// to avoid collisions with the variables of other services sharing the same
+// environment. If both the prefixed and the unprefixed variables are set,
+// the prefixed one takes precedence.
+const envPrefix = "GVA_"
+
+// config contains the configuration of the command. Every field is read from
+// the environment variable specified by the "env" tag. The option
+// "allowempty" means that an empty value does not fall back to the default.
+// The "default" tag contains the value used if the variable is not set, the
+// "required" tag marks the variables that must be set and the "example" tag
+// contains an example value for the documentation of the required
+// variables. The "secret" tag marks the variables whose values must not be
+// logged.
+//
+// Fields are parsed according to their type. Boolean fields are true if the
+// value is "1". Slices are parsed as comma-separated lists. Types
+// implementing [encoding.TextUnmarshaler] are parsed with UnmarshalText.
+//
+// Variables are documented in [configDescriptions].
+type config struct {
+	KafkaBootstrapServers         string                   `env:"KAFKA_BOOTSTRAP_SERVERS" required:"true" example:"kafka.example.com:9092"`
+	InventoryEndpoint             string                   `env:"INVENTORY_ENDPOINT" required:"true" example:"https://inventory.example.com"`
+	AWSAccountAnnotationKeys      []string                 `env:"AWS_ACCOUNT_ANNOTATION_KEY" required:"true" example:"discovery/aws/account,aws/account-id"`
+	LogLevel                      string                   `env:"LOG_LEVEL" default:"info"`
+	LogFormat                     string                   `env:"LOG_FORMAT" default:"text"`
+	LogOutput                     string                   `env:"LOG_OUTPUT" default:"stderr"`
+	LogSyslogAddr                 string                   `env:"LOG_SYSLOG_ADDR"`
+	LogFile                       string                   `env:"LOG_FILE"`
+	LogFileMaxSize                int                      `env:"LOG_FILE_MAX_SIZE" default:"104857600"`
+	LogFileMaxAge                 time.Duration            `env:"LOG_FILE_MAX_AGE" default:"0s"`
+	LogFileMaxBackups             int                      `env:"LOG_FILE_MAX_BACKUPS" default:"5"`
+	AuditDiff                     bool                     `env:"AUDIT_DIFF" default:"0"`
+	AdminAddr                     string                   `env:"ADMIN_ADDR"`
+	RetryDuration                 time.Duration            `env:"RETRY_DURATION" default:"5s"`
+	HandlerRetryAttempts          int                      `env:"HANDLER_RETRY_ATTEMPTS" default:"3"`
+	HandlerRetryBackoff           time.Duration            `env:"HANDLER_RETRY_BACKOFF" default:"500ms"`
+	PreflightTimeout              time.Duration            `env:"PREFLIGHT_TIMEOUT" default:"1m"`
+	HeartbeatFile                 string                   `env:"HEARTBEAT_FILE"`
+	WALFile                       string                   `env:"WAL_FILE"`
+	MaintenanceFile               string                   `env:"MAINTENANCE_FILE"`
+	ResyncSchedule                string                   `env:"RESYNC_SCHEDULE"`
+	ReconcileParallelism          int                      `env:"RECONCILE_PARALLELISM" default:"1"`
+	CheckpointGremlinEndpoint     string                   `env:"CHECKPOINT_GREMLIN_ENDPOINT"`
+	CheckpointInterval            time.Duration            `env:"CHECKPOINT_INTERVAL" default:"1m"`
+	StoreVulcanIDs                bool                     `env:"STORE_VULCAN_IDS" default:"0"`
+	StoreProvenance               bool                     `env:"STORE_PROVENANCE" default:"0"`
+	StoreAssetState               bool                     `env:"STORE_ASSET_STATE" default:"0"`
+	MaxMessageSize                int                      `env:"MAX_MESSAGE_SIZE" default:"0"`
+	OversizedMessagePolicy        string                   `env:"OVERSIZED_MESSAGE_POLICY" default:"fail"`
+	DLQTopic                      string                   `env:"DLQ_TOPIC"`
+	AllowedAssetTypes             []string                 `env:"ALLOWED_ASSET_TYPES"`
+	UnknownAssetTypePolicy        string                   `env:"UNKNOWN_ASSET_TYPE_POLICY" default:"allow"`
+	PayloadSchemaPolicy           string                   `env:"PAYLOAD_SCHEMA_POLICY" default:"off"`
+	QuarantineKeys                []string                 `env:"QUARANTINE_KEYS"`
+	QuarantineIdentifiers         []string                 `env:"QUARANTINE_IDENTIFIERS"`
+	MissingTeamPolicy             string                   `env:"MISSING_TEAM_POLICY" default:"ignore"`
+	KafkaGroupID                  string                   `env:"KAFKA_GROUP_ID" default:"graph-vulcan-assets"`
+	KafkaAssignmentStrategy       kafka.AssignmentStrategy `env:"KAFKA_ASSIGNMENT_STRATEGY"`
+	KafkaSubscribeRetryAttempts   int                      `env:"KAFKA_SUBSCRIBE_RETRY_ATTEMPTS" default:"10"`
+	KafkaSubscribeRetryBackoff    time.Duration            `env:"KAFKA_SUBSCRIBE_RETRY_BACKOFF" default:"1s"`
+	KafkaUsername                 string                   `env:"KAFKA_USERNAME"`
+	KafkaPassword                 string                   `env:"KAFKA_PASSWORD" secret:"true"`
+	KafkaPreset                   string                   `env:"KAFKA_PRESET"`
+	KafkaConsumerProfile          string                   `env:"KAFKA_CONSUMER_PROFILE"`
+	KafkaAutoOffsetReset          string                   `env:"KAFKA_AUTO_OFFSET_RESET" default:"earliest"`
+	KafkaSessionTimeout           time.Duration            `env:"KAFKA_SESSION_TIMEOUT"`
+	KafkaHeartbeatInterval        time.Duration            `env:"KAFKA_HEARTBEAT_INTERVAL"`
+	KafkaMaxPollInterval          time.Duration            `env:"KAFKA_MAX_POLL_INTERVAL"`
+	RedactAnnotations             redactPatternList        `env:"REDACT_ANNOTATIONS,allowempty" default:"*password*,*secret*,*token*"`
+	NormalizeAssetTypes           assetTypeList            `env:"NORMALIZE_ASSET_TYPES"`
+	DeriveIPRanges                bool                     `env:"DERIVE_IP_RANGES" default:"0"`
+	AliasAssetType                string                   `env:"ALIAS_ASSET_TYPE"`
+	InventoryInsecureSkipVerify   bool                     `env:"INVENTORY_INSECURE_SKIP_VERIFY" default:"0"`
+	InventoryPageSize             int                      `env:"INVENTORY_PAGE_SIZE" default:"100"`
+	InventoryDeletedFilter        inventory.DeletedFilter  `env:"INVENTORY_DELETED_FILTER"`
+	InventoryNegativeCacheTTL     time.Duration            `env:"INVENTORY_NEGATIVE_CACHE_TTL" default:"30s"`
+	TombstoneIndexTTL             time.Duration            `env:"TOMBSTONE_INDEX_TTL" default:"0s"`
+	OwnershipTransferWindow       time.Duration            `env:"OWNERSHIP_TRANSFER_WINDOW" default:"0s"`
+	InventoryVersionPolicy        string                   `env:"INVENTORY_VERSION_POLICY" default:"fail"`
+	InventoryVersionCheckInterval time.Duration            `env:"INVENTORY_VERSION_CHECK_INTERVAL" default:"5m"`
+	InventoryParallelism          int                      `env:"INVENTORY_PARALLELISM" default:"4"`
+	InventoryBatchSize            int                      `env:"INVENTORY_BATCH_SIZE" default:"1"`
+	InventoryBatchInterval        time.Duration            `env:"INVENTORY_BATCH_INTERVAL" default:"1s"`
+	InventoryBatchPriority        bool                     `env:"INVENTORY_BATCH_PRIORITY" default:"0"`
+	InventoryPriorityAssetTypes   []string                 `env:"INVENTORY_BATCH_PRIORITY_ASSET_TYPES"`
+	InventoryHTTPMaxIdleConns     int                      `env:"INVENTORY_HTTP_MAX_IDLE_CONNS_PER_HOST" default:"10"`
+	InventoryHTTPIdleTimeout      time.Duration            `env:"INVENTORY_HTTP_IDLE_CONN_TIMEOUT" default:"90s"`
+	InventoryHTTP2                bool                     `env:"INVENTORY_HTTP_ENABLE_HTTP2" default:"0"`
+	InventoryTeamsAsAssets        bool                     `env:"INVENTORY_TEAMS_AS_ASSETS" default:"0"`
+	InventoryServerTime           bool                     `env:"INVENTORY_SERVER_TIME" default:"0"`
+	InventoryClockSkew            time.Duration            `env:"INVENTORY_CLOCK_SKEW" default:"0s"`
+	InventoryCaptureSize          int                      `env:"INVENTORY_CAPTURE_SIZE" default:"0"`
+	InventoryCaptureMaxBody       int                      `env:"INVENTORY_CAPTURE_MAX_BODY" default:"4096"`
+	InventoryTLSCertFile          string                   `env:"INVENTORY_TLS_CERT_FILE"`
+	InventoryTLSKeyFile           string                   `env:"INVENTORY_TLS_KEY_FILE"`
+	InventoryTLSCAFile            string                   `env:"INVENTORY_TLS_CA_FILE"`
+	InventoryTLSReloadInterval    time.Duration            `env:"INVENTORY_TLS_RELOAD_INTERVAL" default:"1m"`
+	AssetStateCacheSize           int                      `env:"ASSET_STATE_CACHE_SIZE" default:"0"`
+	AssetStateTTL                 time.Duration            `env:"ASSET_STATE_TTL" default:"1h"`
+	TopAssetsMaxAssets            int                      `env:"TOP_ASSETS_MAX_ASSETS" default:"10000"`
+	FreshnessThreshold            time.Duration            `env:"FRESHNESS_THRESHOLD" default:"0s"`
+	SamplePercent                 int                      `env:"SAMPLE_PERCENT" default:"0"`
+	SampleTopic                   string                   `env:"SAMPLE_TOPIC"`
+	SampleFile                    string                   `env:"SAMPLE_FILE"`
+	DailyReportSchedule           string                   `env:"DAILY_REPORT_SCHEDULE" default:"@daily"`
+	DailyReportFormat             string                   `env:"DAILY_REPORT_FORMAT" default:"json"`
+	DailyReportWebhook            string                   `env:"DAILY_REPORT_WEBHOOK" secret:"true"`
+	DailyReportS3URL              string                   `env:"DAILY_REPORT_S3_URL"`
+	ArchiveS3URL                  string                   `env:"ARCHIVE_S3_URL"`
+	ArchiveBatchSize              int                      `env:"ARCHIVE_BATCH_SIZE" default:"10000"`
+	ArchiveBatchInterval          time.Duration            `env:"ARCHIVE_BATCH_INTERVAL" default:"5m"`
+	RoutingFile                   string                   `env:"ROUTING_FILE"`
+	TeamMappingFile               string                   `env:"TEAM_MAPPING_FILE"`
+	CatchUpLagThreshold           int                      `env:"CATCHUP_LAG_THRESHOLD" default:"1000"`
+	CatchUpMaxDuration            time.Duration            `env:"CATCHUP_MAX_DURATION" default:"1h"`
+	CatchUpBatchSize              int                      `env:"CATCHUP_BATCH_SIZE" default:"500"`
+	CatchUpParallelism            int                      `env:"CATCHUP_PARALLELISM" default:"16"`
+	MetricsDisabledLabels         []string                 `env:"METRICS_DISABLED_LABELS"`
+	MetricsMaxLabelValues         int                      `env:"METRICS_MAX_LABEL_VALUES" default:"0"`
+	ChaosMode                     bool                     `env:"CHAOS_MODE" default:"0"`
+	ChaosInventoryErrorPercent    int                      `env:"CHAOS_INVENTORY_ERROR_PERCENT" default:"0"`
+	ChaosInventoryLatency         time.Duration            `env:"CHAOS_INVENTORY_LATENCY" default:"0s"`
+	ChaosHandlerPanicPercent      int                      `env:"CHAOS_HANDLER_PANIC_PERCENT" default:"0"`
+
+	// TeamMapping is read from TeamMappingFile by [readConfig].
+	TeamMapping assetsync.TeamMapping
+}
+
+// configDescriptions contains the description of the environment variables
+// read into [config]. It is used to generate the configuration reference.
+var configDescriptions = map[string]string{
+	"KAFKA_BOOTSTRAP_SERVERS":                "Kafka bootstrap servers",
+	"INVENTORY_ENDPOINT":                     "Endpoint of the Security Graph Asset Inventory",
+	"AWS_ACCOUNT_ANNOTATION_KEY":             "Comma-separated list of keys of the annotations that contain the asset's parent AWS account. The first key present in the asset is used",
+	"LOG_LEVEL":                              "Log level. Valid values: `info`, `debug`, `error`, `disabled`",
+	"LOG_FORMAT":                             "Log format. Valid values: `text`, `pretty`. The `pretty` format is meant for local development",
+	"LOG_OUTPUT":                             "Log output. Valid values: `stderr`, `syslog`, `file`",
+	"LOG_SYSLOG_ADDR":                        "Address of the syslog server with the format `<network>://<address>` (e.g. `udp://syslog.example.com:514`). If empty, the local syslog server is used",
+	"LOG_FILE":                               "Path of the log file. Required if `LOG_OUTPUT` is `file`",
+	"LOG_FILE_MAX_SIZE":                      "Maximum size in bytes of the log file before rotating it. If the value is `0` the file is not rotated by size",
+	"LOG_FILE_MAX_AGE":                       "Maximum age of the log file before rotating it. If the value is `0s` the file is not rotated by age",
+	"LOG_FILE_MAX_BACKUPS":                   "Maximum number of rotated log files kept. If the value is `0` all of them are kept",
+	"AUDIT_DIFF":                             "If the value is `1` then the changes made to every processed asset are logged. See [Audit Mode](#audit-mode)",
+	"ADMIN_ADDR":                             "Address of the admin HTTP server (e.g. `:9090`). If empty, the server is disabled",
+	"RETRY_DURATION":                         "Time between retries if the stream processor fails. If the value is `0` the command exits on error",
+	"HANDLER_RETRY_ATTEMPTS":                 "Maximum number of times a message is retried in place after a transient error (network error or 5xx/429 response from the Asset Inventory) before failing the stream processor. If the value is `0` messages are not retried",
+	"HANDLER_RETRY_BACKOFF":                  "Time to wait before the first in-place retry of a message. It is doubled after every retry",
+	"PREFLIGHT_TIMEOUT":                      "Maximum time spent retrying the startup checks of the kafka topic and the Asset Inventory. If the value is `0` failed checks are not retried",
+	"HEARTBEAT_FILE":                         "Path of a JSON file updated with the time and the position of the last processed message, the last processed offset of every partition and the number of processed messages. If empty, the file is not written",
+	"WAL_FILE":                               "Path of the write-ahead log of the events being processed. Pending events are replayed on startup. If empty, the log is disabled. See [Write-Ahead Log](#write-ahead-log)",
+	"MAINTENANCE_FILE":                       "Path of a file that enables the maintenance mode while it exists",
+	"RESYNC_SCHEDULE":                        "Cron expression (e.g. `0 3 * * 0` or `@weekly`) that schedules a periodic full resync. If empty, no resync is scheduled",
+	"RECONCILE_PARALLELISM":                  "Number of partitions of the assets topic processed concurrently by the reconcile command and the periodic resync",
+	"CHECKPOINT_GREMLIN_ENDPOINT":            "Endpoint of the gremlin-server of the Security Graph (e.g. `ws://gremlin.example.com:8182/gremlin`) used to store the processing checkpoint. If empty, checkpointing is disabled",
+	"CHECKPOINT_INTERVAL":                    "Time between checkpoint writes",
+	"STORE_VULCAN_IDS":                       "If `1`, the Vulcan IDs of assets and teams, as well as the tag and description of the teams and the mark of the derived assets, are stored as properties in the Asset Inventory. See [Vulcan IDs](#vulcan-ids)",
+	"STORE_PROVENANCE":                       "If `1`, the provenance of the relations created or updated by the consumer is stored as properties in the Asset Inventory. See [Relation Provenance](#relation-provenance)",
+	"STORE_ASSET_STATE":                      "If `1`, the fingerprint of the last event applied to every asset is persisted as a property in the Asset Inventory. It requires `CHECKPOINT_GREMLIN_ENDPOINT`. See [Change Detection](#change-detection)",
+	"MAX_MESSAGE_SIZE":                       "Maximum size in bytes of the value of the messages. Larger messages are handled according to `OVERSIZED_MESSAGE_POLICY`. If the value is `0` there is no limit",
+	"OVERSIZED_MESSAGE_POLICY":               "Policy applied to the messages larger than `MAX_MESSAGE_SIZE`. Valid values: `fail`, `skip`, `dlq`",
+	"DLQ_TOPIC":                              "Kafka topic used as dead letter queue. Required if `OVERSIZED_MESSAGE_POLICY`, `UNKNOWN_ASSET_TYPE_POLICY` or `PAYLOAD_SCHEMA_POLICY` is `dlq`",
+	"ALLOWED_ASSET_TYPES":                    "Comma-separated list of asset types written to the Asset Inventory. The messages of other asset types are handled according to `UNKNOWN_ASSET_TYPE_POLICY`. See [Asset Types](#asset-types)",
+	"UNKNOWN_ASSET_TYPE_POLICY":              "Policy applied to the messages of asset types not in `ALLOWED_ASSET_TYPES`. Valid values: `allow`, `skip`, `dlq`",
+	"PAYLOAD_SCHEMA_POLICY":                  "Policy applied to the asset messages whose payload does not conform to the JSON schema of the Vulcan async API. Valid values: `off`, `warn`, `reject`, `dlq`. See [Payload Schema](#payload-schema)",
+	"QUARANTINE_KEYS":                        "Comma-separated list of message keys whose messages are skipped. See [Quarantine](#quarantine)",
+	"QUARANTINE_IDENTIFIERS":                 "Comma-separated list of asset identifiers whose messages are skipped. See [Quarantine](#quarantine)",
+	"MISSING_TEAM_POLICY":                    "Policy applied to the tombstones of the assets whose team does not exist in the Asset Inventory. Valid values: `ignore`, `expire`",
+	"KAFKA_GROUP_ID":                         "Kafka consumer group ID",
+	"KAFKA_ASSIGNMENT_STRATEGY":              "Partition assignment strategy of the consumer group. Valid values: `range`, `roundrobin`, `cooperative-sticky`. If empty, the default of librdkafka is used",
+	"KAFKA_SUBSCRIBE_RETRY_ATTEMPTS":         "Maximum number of consecutive retries after an error subscribing to the topic or connecting to the Kafka brokers before failing the stream processor. If the value is `0` these errors are not retried",
+	"KAFKA_SUBSCRIBE_RETRY_BACKOFF":          "Time to wait before the first retry after an error subscribing to the topic or connecting to the Kafka brokers. It is doubled after every retry up to a maximum of 1m",
+	"KAFKA_USERNAME":                         "Kafka username",
+	"KAFKA_PASSWORD":                         "kafka password",
+	"KAFKA_PRESET":                           "Set of kafka client settings for a specific provider. Valid values: `confluent-cloud`",
+	"KAFKA_CONSUMER_PROFILE":                 "Set of consumer group settings for a specific workload. Valid values: `low-latency`, `catch-up`. See [Consumer Profiles](#consumer-profiles)",
+	"KAFKA_AUTO_OFFSET_RESET":                "Where a consumer group without committed offsets starts consuming. Valid values: `earliest`, `latest`, `error`",
+	"KAFKA_SESSION_TIMEOUT":                  "Time without heartbeats after which a consumer is removed from the consumer group. If empty, the value of the consumer profile or the default of librdkafka is used",
+	"KAFKA_HEARTBEAT_INTERVAL":               "Time between heartbeats sent to the consumer group coordinator. It must be lower than the session timeout. If empty, the value of the consumer profile or the default of librdkafka is used",
+	"KAFKA_MAX_POLL_INTERVAL":                "Maximum time between reads of messages before the consumer leaves the consumer group. It must not be lower than the session timeout. If empty, the value of the consumer profile or the default of librdkafka is used",
+	"REDACT_ANNOTATIONS":                     "Comma-separated list of annotation key patterns (e.g. `*/email`) whose values are masked in the logs. Patterns are case insensitive and follow the syntax of Go's `path.Match`",
+	"NORMALIZE_ASSET_TYPES":                  "Comma-separated list of asset types whose identifiers are normalized before looking them up in the Asset Inventory. Supported types: `Hostname`, `DomainName`, `IP`, `IPRange`, `DockerImage`. The value `*` selects all of them. See [Identifier Normalization](#identifier-normalization)",
+	"DERIVE_IP_RANGES":                       "If the value is `1` then the smallest `IPRange` asset containing an `IP` asset is set as its parent. See [Network Assets](#network-assets)",
+	"ALIAS_ASSET_TYPE":                       "Asset type of the assets created for the aliases of the Vulcan assets. If empty, aliases are not stored as assets. See [Aliases](#aliases)",
+	"INVENTORY_INSECURE_SKIP_VERIFY":         "If the value is `1` then skip TLS verification",
+	"INVENTORY_PAGE_SIZE":                    "Page size used when listing entities from the Asset Inventory. If the value is `0` pagination is disabled",
+	"INVENTORY_DELETED_FILTER":               "Filter applied when listing teams and assets from an Asset Inventory with soft deletes. Valid values: `exclude` (only entities that are not deleted), `only` (only deleted entities). If empty, the default of the Asset Inventory is used",
+	"INVENTORY_NEGATIVE_CACHE_TTL":           "Time the assets and teams not found in the Asset Inventory while processing tombstones are cached, so repeated tombstones do not look them up again. If the value is `0` negative lookups are not cached",
+	"OWNERSHIP_TRANSFER_WINDOW":              "Maximum time between the tombstone of an asset for its old team and the event of the asset for its new team for the change to be reported as an ownership transfer. If the value is `0` transfers are not detected. See [Ownership Transfers](#ownership-transfers)",
+	"TOMBSTONE_INDEX_TTL":                    "Time the identifiers of the assets and teams listed in bulk from the Asset Inventory are used to skip the tombstones of entities that do not exist. If the value is `0` the index is disabled. See [Tombstones](#tombstones)",
+	"INVENTORY_VERSION_POLICY":               "Policy applied when the API version of an Asset Inventory is not supported at startup. Valid values: `fail` (exit with error), `pause` (start with processing paused). See [Schema Guard](#schema-guard)",
+	"INVENTORY_VERSION_CHECK_INTERVAL":       "Time between checks of the API version of the Asset Inventories while running. If the value is `0` the version is only checked at startup",
+	"INVENTORY_PARALLELISM":                  "Maximum number of concurrent requests sent to the Asset Inventory when expiring the owns and parent-of relations of an asset and of messages of a batch processed concurrently",
+	"INVENTORY_BATCH_SIZE":                   "Maximum number of messages applied to the Asset Inventory in a batch. If the value is `1` messages are not batched",
+	"INVENTORY_BATCH_INTERVAL":               "Maximum time a message waits in a batch before the batch is applied to the Asset Inventory",
+	"INVENTORY_BATCH_PRIORITY":               "Applies the tombstones and the messages of the asset types in `INVENTORY_BATCH_PRIORITY_ASSET_TYPES` ahead of the routine refreshes of a batch. See [Write Batching](#write-batching)",
+	"INVENTORY_BATCH_PRIORITY_ASSET_TYPES":   "Comma-separated list of security-relevant asset types whose messages are applied ahead of the routine refreshes of a batch. It requires `INVENTORY_BATCH_PRIORITY`",
+	"INVENTORY_HTTP_MAX_IDLE_CONNS_PER_HOST": "Maximum number of idle connections to the Asset Inventory kept for reuse",
+	"INVENTORY_HTTP_IDLE_CONN_TIMEOUT":       "Time an idle connection to the Asset Inventory is kept before closing it. If the value is `0s` idle connections are never closed",
+	"INVENTORY_HTTP_ENABLE_HTTP2":            "If the value is `1` then try to use HTTP/2 when connecting to the Asset Inventory over TLS",
+	"INVENTORY_CAPTURE_SIZE":                 "Number of HTTP requests sent to the Asset Inventory, and their responses, kept in memory and exposed by the admin API. If the value is `0` requests are not captured. See [Admin API](#admin-api)",
+	"INVENTORY_CAPTURE_MAX_BODY":             "Maximum number of bytes of the bodies of the captured requests and responses",
+	"INVENTORY_SERVER_TIME":                  "If the value is `1` then the current time used to check and set expirations is derived from the `Date` header of the responses of the Asset Inventory instead of the local clock. See [Clock Skew](#clock-skew)",
+	"INVENTORY_CLOCK_SKEW":                   "Tolerance applied when checking whether an entity of the Asset Inventory is expired, so entities are not considered expired prematurely on hosts with drifting clocks",
+	"INVENTORY_TEAMS_AS_ASSETS":              "If the value is `1` then teams are stored as assets of type `Team`, for the Asset Inventory deployments that model teams as assets. See [Teams as Assets](#teams-as-assets)",
+	"INVENTORY_TLS_CERT_FILE":                "PEM encoded client certificate used to connect to the Asset Inventory. It requires `INVENTORY_TLS_KEY_FILE`",
+	"INVENTORY_TLS_KEY_FILE":                 "PEM encoded private key of the client certificate",
+	"INVENTORY_TLS_CA_FILE":                  "PEM encoded certificates of the CAs used to verify the Asset Inventory server certificate",
+	"INVENTORY_TLS_RELOAD_INTERVAL":          "Time between checks of the TLS files. When their contents change, new connections use the new certificates",
+	"ASSET_STATE_CACHE_SIZE":                 "Maximum number of assets whose last applied state is remembered, so the events of assets that did not change are skipped. If the value is `0` all the events are applied. See [Change Detection](#change-detection)",
+	"FRESHNESS_THRESHOLD":                    "Processing lag of a partition above which a warning is logged. If the value is `0s` no warning is logged. See [Processing Lag](#processing-lag)",
+	"SAMPLE_PERCENT":                         "Percentage of the asset keys whose messages are copied to the sample topic or file. If the value is `0` messages are not sampled. See [Sampling](#sampling)",
+	"SAMPLE_TOPIC":                           "Kafka topic the sampled messages are sent to",
+	"SAMPLE_FILE":                            "Path of the file the sampled messages are appended to using the JSON Lines format",
+	"DAILY_REPORT_SCHEDULE":                  "Cron expression that schedules the publication of the daily report. See [Daily Report](#daily-report)",
+	"DAILY_REPORT_FORMAT":                    "Format of the daily report. Valid values: `json`, `markdown`",
+	"DAILY_REPORT_WEBHOOK":                   "URL the daily report is sent to with a POST request. If neither `DAILY_REPORT_WEBHOOK` nor `DAILY_REPORT_S3_URL` are set, the daily report is disabled",
+	"DAILY_REPORT_S3_URL":                    "S3 location, with the format `s3://<bucket>/<prefix>`, the daily reports are uploaded to. The credentials are read from the standard AWS environment variables",
+	"ARCHIVE_S3_URL":                         "S3 location, with the format `s3://<bucket>/<prefix>`, the consumed messages are archived to. If empty, messages are not archived. See [Archiving](#archiving)",
+	"ARCHIVE_BATCH_SIZE":                     "Maximum number of messages of an archive batch",
+	"ARCHIVE_BATCH_INTERVAL":                 "Maximum time a message waits in an archive batch before being uploaded",
+	"TOP_ASSETS_MAX_ASSETS":                  "Maximum number of assets tracked every minute to rank the assets by event volume. If the value is `0` the assets are not ranked. See [Admin API](#admin-api)",
+	"ASSET_STATE_TTL":                        "Time during which an asset that did not change is not applied again. When it elapses, the next event of the asset is applied, so its time attributes are refreshed",
+	"ROUTING_FILE":                           "Path of a JSON file that routes the assets of specific teams to other Asset Inventory endpoints. If empty, all the assets are sent to `INVENTORY_ENDPOINT`. The properties enabled with the `STORE_*` settings are stored in the Asset Inventory of every asset. See [Routing](#routing)",
+	"CATCHUP_LAG_THRESHOLD":                  "Number of messages pending to be processed by the consumer group below which the catch-up mode finishes. See [Catch-Up](#catch-up)",
+	"CATCHUP_MAX_DURATION":                   "Maximum duration of the catch-up mode",
+	"CATCHUP_BATCH_SIZE":                     "Maximum number of messages of a batch in catch-up mode",
+	"CATCHUP_PARALLELISM":                    "Maximum number of messages of a batch processed concurrently in catch-up mode",
+	"METRICS_DISABLED_LABELS":                "Comma-separated list of labels removed from all the metrics, like the high-cardinality `team` label. See [Metrics](#metrics)",
+	"METRICS_MAX_LABEL_VALUES":               "Maximum number of distinct values of a label of a metric. Once reached, new values are exported as `__other__`. If the value is `0` the values are not limited",
+	"CHAOS_MODE":                             "Enables the fault injection mode for integration testing. It must never be enabled in production. See [Chaos Mode](#chaos-mode)",
+	"CHAOS_INVENTORY_ERROR_PERCENT":          "Percentage of the requests to the Asset Inventory replied with an injected `503` error in chaos mode",
+	"CHAOS_INVENTORY_LATENCY":                "Latency added to every request to the Asset Inventory in chaos mode",
+	"CHAOS_HANDLER_PANIC_PERCENT":            "Percentage of the messages whose processing panics in chaos mode. The panics are recovered and returned as handler errors",
+	"TEAM_MAPPING_FILE":                      "Path of a JSON file that maps Vulcan teams to the identifiers of other teams of the Security Graph. If empty, teams are not mapped. See [Team Mapping](#team-mapping)",
+}
+
+// readConfig reads the configuration from the environment.
+func readConfig() (config, error) {
+	var cfg config
+	if err := loadEnv(&cfg); err != nil {
+		return config{}, err
+	}
+	if err := cfg.validate(); err != nil {
+		return config{}, err
+	}
+	if cfg.TeamMappingFile != "" {
+		m, err := readTeamMapping(cfg.TeamMappingFile)
+		if err != nil {
+			return config{}, err
+		}
+		cfg.TeamMapping = m
+	}
+	return cfg, nil
+}
+
+// validate checks the constraints that cannot be expressed with the tags of
+// [config].
+func (cfg config) validate() error {
+	switch cfg.LogOutput {
+	case logOutputStderr, logOutputFile:
+	case logOutputSyslog:
+		if cfg.LogSyslogAddr != "" {
+			if _, _, ok := strings.Cut(cfg.LogSyslogAddr, "://"); !ok {
+				return fmt.Errorf("invalid syslog address %q", cfg.LogSyslogAddr)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid log output %q", cfg.LogOutput)
+	}
+	if cfg.LogOutput == logOutputFile && cfg.LogFile == "" {
+		return errors.New("missing log file")
+	}
+	if cfg.LogFileMaxSize < 0 {
+		return fmt.Errorf("invalid log file max size: %v", cfg.LogFileMaxSize)
+	}
+	if cfg.LogFileMaxAge < 0 {
+		return fmt.Errorf("invalid log file max age: %v", cfg.LogFileMaxAge)
+	}
+	if cfg.LogFileMaxBackups < 0 {
+		return fmt.Errorf("invalid log file max backups: %v", cfg.LogFileMaxBackups)
+	}
+
+	if cfg.HandlerRetryAttempts < 0 {
+		return fmt.Errorf("invalid handler retry attempts: %v", cfg.HandlerRetryAttempts)
+	}
+	if cfg.HandlerRetryBackoff < 0 {
+		return fmt.Errorf("invalid handler retry backoff: %v", cfg.HandlerRetryBackoff)
+	}
+
+	if cfg.KafkaSubscribeRetryAttempts < 0 {
+		return fmt.Errorf("invalid kafka subscribe retry attempts: %v", cfg.KafkaSubscribeRetryAttempts)
+	}
+	if cfg.KafkaSubscribeRetryBackoff < 0 {
+		return fmt.Errorf("invalid kafka subscribe retry backoff: %v", cfg.KafkaSubscribeRetryBackoff)
+	}
+
+	if cfg.ResyncSchedule != "" {
+		if _, err := cron.Parse(cfg.ResyncSchedule); err != nil {
+			return fmt.Errorf("invalid resync schedule: %w", err)
+		}
+	}
+
+	if cfg.AssetStateCacheSize < 0 {
+		return fmt.Errorf("invalid asset state cache size: %v", cfg.AssetStateCacheSize)
+	}
+	if cfg.AssetStateTTL <= 0 {
+		return fmt.Errorf("invalid asset state TTL: %v", cfg.AssetStateTTL)
+	}
+	if cfg.StoreAssetState && cfg.CheckpointGremlinEndpoint == "" {
+		return errors.New("persisted asset states require a checkpoint store")
+	}
+	if cfg.TopAssetsMaxAssets < 0 {
+		return fmt.Errorf("invalid top assets max assets: %v", cfg.TopAssetsMaxAssets)
+	}
+	if cfg.FreshnessThreshold < 0 {
+		return fmt.Errorf("invalid freshness threshold: %v", cfg.FreshnessThreshold)
+	}
+	if cfg.SamplePercent < 0 || cfg.SamplePercent > 100 {
+		return fmt.Errorf("invalid sample percent: %v", cfg.SamplePercent)
+	}
+	if cfg.SamplePercent > 0 && (cfg.SampleTopic == "") == (cfg.SampleFile == "") {
+		return errors.New("exactly one of sample topic and sample file must be set")
+	}
+
+	if _, err := cron.Parse(cfg.DailyReportSchedule); err != nil {
+		return fmt.Errorf("invalid daily report schedule: %w", err)
+	}
+	switch cfg.DailyReportFormat {
+	case dailyReportFormatJSON, dailyReportFormatMarkdown:
+	default:
+		return fmt.Errorf("invalid daily report format %q", cfg.DailyReportFormat)
+	}
+	if cfg.DailyReportS3URL != "" {
+		if _, _, err := s3.ParseURL(cfg.DailyReportS3URL); err != nil {
+			return fmt.Errorf("invalid daily report S3 URL: %w", err)
+		}
+	}
+
+	if cfg.ArchiveS3URL != "" {
+		if _, _, err := s3.ParseURL(cfg.ArchiveS3URL); err != nil {
+			return fmt.Errorf("invalid archive S3 URL: %w", err)
+		}
+	}
+	if cfg.CatchUpLagThreshold < 0 {
+		return fmt.Errorf("invalid catch-up lag threshold: %v", cfg.CatchUpLagThreshold)
+	}
+	if cfg.CatchUpMaxDuration <= 0 {
+		return fmt.Errorf("invalid catch-up max duration: %v", cfg.CatchUpMaxDuration)
+	}
+	if cfg.CatchUpBatchSize < 1 {
+		return fmt.Errorf("invalid catch-up batch size: %v", cfg.CatchUpBatchSize)
+	}
+	if cfg.CatchUpParallelism < 1 {
+		return fmt.Errorf("invalid catch-up parallelism: %v", cfg.CatchUpParallelism)
+	}
+	if cfg.MetricsMaxLabelValues < 0 {
+		return fmt.Errorf("invalid metrics max label values: %v", cfg.MetricsMaxLabelValues)
+	}
+	if cfg.ChaosInventoryErrorPercent < 0 || cfg.ChaosInventoryErrorPercent > 100 {
+		return fmt.Errorf("invalid chaos inventory error percent: %v", cfg.ChaosInventoryErrorPercent)
+	}
+	if cfg.ChaosInventoryLatency < 0 {
+		return fmt.Errorf("invalid chaos inventory latency: %v", cfg.ChaosInventoryLatency)
+	}
+	if cfg.ChaosHandlerPanicPercent < 0 || cfg.ChaosHandlerPanicPercent > 100 {
+		return fmt.Errorf("invalid chaos handler panic percent: %v", cfg.ChaosHandlerPanicPercent)
+	}
+	chaosSet := cfg.ChaosInventoryErrorPercent > 0 || cfg.ChaosInventoryLatency > 0 || cfg.ChaosHandlerPanicPercent > 0
+	if chaosSet && !cfg.ChaosMode {
+		return errors.New("chaos faults require CHAOS_MODE")
+	}
+
+	if cfg.ArchiveBatchSize < 1 {
+		return fmt.Errorf("invalid archive batch size: %v", cfg.ArchiveBatchSize)
+	}
+	if cfg.ArchiveBatchInterval <= 0 {
+		return fmt.Errorf("invalid archive batch interval: %v", cfg.ArchiveBatchInterval)
+	}
+
+	if cfg.ReconcileParallelism < 1 {
+		return fmt.Errorf("invalid reconcile parallelism: %v", cfg.ReconcileParallelism)
+	}
+
+	if cfg.CheckpointInterval <= 0 {
+		return fmt.Errorf("invalid checkpoint interval: %v", cfg.CheckpointInterval)
+	}
+
+	if cfg.MaxMessageSize < 0 {
+		return fmt.Errorf("invalid max message size: %v", cfg.MaxMessageSize)
+	}
+	switch cfg.OversizedMessagePolicy {
+	case oversizedPolicyFail, oversizedPolicySkip, oversizedPolicyDLQ:
+	default:
+		return fmt.Errorf("invalid oversized message policy %q", cfg.OversizedMessagePolicy)
+	}
+	if cfg.OversizedMessagePolicy == oversizedPolicyDLQ && cfg.DLQTopic == "" {
+		return errors.New("missing dead letter queue topic")
+	}
+
+	switch cfg.UnknownAssetTypePolicy {
+	case unknownTypePolicyAllow:
+	case unknownTypePolicySkip, unknownTypePolicyDLQ:
+		if len(cfg.AllowedAssetTypes) == 0 {
+			return fmt.Errorf("unknown asset type policy %q requires allowed asset types", cfg.UnknownAssetTypePolicy)
+		}
+	default:
+		return fmt.Errorf("invalid unknown asset type policy %q", cfg.UnknownAssetTypePolicy)
+	}
+	if cfg.UnknownAssetTypePolicy == unknownTypePolicyDLQ && cfg.DLQTopic == "" {
+		return errors.New("missing dead letter queue topic")
+	}
+
+	switch cfg.PayloadSchemaPolicy {
+	case schemaPolicyOff, schemaPolicyWarn, schemaPolicyReject, schemaPolicyDLQ:
+	default:
+		return fmt.Errorf("invalid payload schema policy %q", cfg.PayloadSchemaPolicy)
+	}
+	if cfg.PayloadSchemaPolicy == schemaPolicyDLQ && cfg.DLQTopic == "" {
+		return errors.New("missing dead letter queue topic")
+	}
+
+	switch cfg.MissingTeamPolicy {
+	case assetsync.MissingTeamPolicyIgnore, assetsync.MissingTeamPolicyExpire:
+	default:
+		return fmt.Errorf("invalid missing team policy %q", cfg.MissingTeamPolicy)
+	}
+
+	if cfg.InventoryVersionPolicy != inventoryVersionPolicyFail && cfg.InventoryVersionPolicy != inventoryVersionPolicyPause {
+		return fmt.Errorf("invalid inventory version policy %q", cfg.InventoryVersionPolicy)
+	}
+	if cfg.InventoryVersionCheckInterval < 0 {
+		return fmt.Errorf("invalid inventory version check interval: %v", cfg.InventoryVersionCheckInterval)
+	}
+	if cfg.InventoryNegativeCacheTTL < 0 {
+		return fmt.Errorf("invalid inventory negative cache TTL: %v", cfg.InventoryNegativeCacheTTL)
+	}
+	if cfg.OwnershipTransferWindow < 0 {
+		return fmt.Errorf("invalid ownership transfer window: %v", cfg.OwnershipTransferWindow)
+	}
+	if cfg.TombstoneIndexTTL < 0 {
+		return fmt.Errorf("invalid tombstone index TTL: %v", cfg.TombstoneIndexTTL)
+	}
+	if !cfg.InventoryDeletedFilter.Valid() {
+		return fmt.Errorf("invalid inventory deleted filter %q", cfg.InventoryDeletedFilter)
+	}
+	if cfg.KafkaAssignmentStrategy != "" && !cfg.KafkaAssignmentStrategy.Valid() {
+		return fmt.Errorf("invalid kafka assignment strategy %q", cfg.KafkaAssignmentStrategy)
+	}
+	if cfg.KafkaPreset != "" {
+		if _, ok := kafkaPresets[cfg.KafkaPreset]; !ok {
+			return fmt.Errorf("unknown kafka preset %q", cfg.KafkaPreset)
+		}
+		if cfg.KafkaUsername == "" || cfg.KafkaPassword == "" {
+			return fmt.Errorf("kafka preset %q requires kafka credentials", cfg.KafkaPreset)
+		}
+	}
+
+	if cfg.KafkaConsumerProfile != "" {
+		if _, ok := kafkaConsumerProfiles[cfg.KafkaConsumerProfile]; !ok {
+			return fmt.Errorf("unknown kafka consumer profile %q", cfg.KafkaConsumerProfile)
+		}
+	}
+	switch cfg.KafkaAutoOffsetReset {
+	case "earliest", "latest", "error":
+	default:
+		return fmt.Errorf("invalid kafka auto offset reset %q", cfg.KafkaAutoOffsetReset)
+	}
+	if cfg.KafkaSessionTimeout < 0 {
+		return fmt.Errorf("invalid kafka session timeout: %v", cfg.KafkaSessionTimeout)
+	}
+	if cfg.KafkaHeartbeatInterval < 0 {
+		return fmt.Errorf("invalid kafka heartbeat interval: %v", cfg.KafkaHeartbeatInterval)
+	}
+	if cfg.KafkaMaxPollInterval < 0 {
+		return fmt.Errorf("invalid kafka max poll interval: %v", cfg.KafkaMaxPollInterval)
+	}
+	if err := validateKafkaTimeouts(kafkaConfig(cfg)); err != nil {
+		return err
+	}
+
+	if cfg.InventoryPageSize < 0 {
+		return fmt.Errorf("invalid inventory page size: %v", cfg.InventoryPageSize)
+	}
+	if cfg.InventoryParallelism < 1 {
+		return fmt.Errorf("invalid inventory parallelism: %v", cfg.InventoryParallelism)
+	}
+	if cfg.InventoryBatchSize < 1 {
+		return fmt.Errorf("invalid inventory batch size: %v", cfg.InventoryBatchSize)
+	}
+	if cfg.InventoryBatchInterval <= 0 {
+		return fmt.Errorf("invalid inventory batch interval: %v", cfg.InventoryBatchInterval)
+	}
+	if len(cfg.InventoryPriorityAssetTypes) > 0 && !cfg.InventoryBatchPriority {
+		return errors.New("priority asset types require INVENTORY_BATCH_PRIORITY")
+	}
+	if cfg.InventoryHTTPMaxIdleConns < 0 {
+		return fmt.Errorf("invalid inventory max idle connections: %v", cfg.InventoryHTTPMaxIdleConns)
+	}
+	if cfg.InventoryHTTPIdleTimeout < 0 {
+		return fmt.Errorf("invalid inventory idle connection timeout: %v", cfg.InventoryHTTPIdleTimeout)
+	}
+	if cfg.InventoryClockSkew < 0 {
+		return fmt.Errorf("invalid inventory clock skew: %v", cfg.InventoryClockSkew)
+	}
+	if cfg.InventoryCaptureSize < 0 {
+		return fmt.Errorf("invalid inventory capture size: %v", cfg.InventoryCaptureSize)
+	}
+	if cfg.InventoryCaptureMaxBody < 0 {
+		return fmt.Errorf("invalid inventory capture max body: %v", cfg.InventoryCaptureMaxBody)
+	}
+	if (cfg.InventoryTLSCertFile == "") != (cfg.InventoryTLSKeyFile == "") {
+		return errors.New("inventory TLS certificate and key files must be provided together")
+	}
+	if cfg.InventoryTLSReloadInterval <= 0 {
+		return fmt.Errorf("invalid inventory TLS reload interval: %v", cfg.InventoryTLSReloadInterval)
+	}
+
+	return nil
+}
+
+// lookupEnv returns the value of the environment variable with the provided
+// name. The variable prefixed with [envPrefix] takes precedence.
+func lookupEnv(name string) (string, bool) {
+	if v, ok := os.LookupEnv(envPrefix + name); ok {
+		return v, true
+	}
+	return os.LookupEnv(name)
+}
+
+// loadEnv fills the fields of the struct pointed to by cfg with the
+// environment variables specified by their tags. See [config] for the
+// supported tags and types.
+func loadEnv(cfg any) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts, _ := strings.Cut(f.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		s, ok := lookupEnv(name)
+		if !ok || (s == "" && opts != "allowempty") {
+			if f.Tag.Get("required") == "true" {
+				return fmt.Errorf("missing %v", name)
+			}
+			if s, ok = f.Tag.Lookup("default"); !ok {
+				continue
+			}
+		}
+
+		if err := setField(v.Field(i), s); err != nil {
+			return fmt.Errorf("invalid %v: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// durationType is the [reflect.Type] of [time.Duration].
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField parses s according to the type of v and stores the result in v.
+func setField(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(s == "1")
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		list := parseList(s)
+		if list == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		slice := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, e := range list {
+			slice.Index(i).SetString(e)
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// writeConfigReference writes the Markdown reference of the environment
+// variables read into [config] to w. It is included in the README.
+func writeConfigReference(w io.Writer) error {
+	var required, optional strings.Builder
+
+	t := reflect.TypeOf(config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		desc, ok := configDescriptions[name]
+		if !ok {
+			return fmt.Errorf("missing description of %v", name)
+		}
+
+		if f.Tag.Get("required") == "true" {
+			required.WriteString(referenceRow(name, desc, f.Tag.Get("example")))
+		} else {
+			optional.WriteString(referenceRow(name, desc, f.Tag.Get("default")))
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `The following environment variables are **required**:
+
+| Variable | Description | Example |
+| --- | --- | --- |
+%v
+The following environment variables are **optional**:
+
+| Variable | Description | Default |
+| --- | --- | --- |
+%v
+All the variables can be prefixed with `+"`%v`"+`. If both the prefixed and the
+unprefixed variables are set, the prefixed one takes precedence.
+`, required.String(), optional.String(), envPrefix)
+	return err
+}
+
+// referenceRow returns the row of the configuration reference that
+// documents the variable with the provided name. value is the example or the
+// default value of the variable.
+func referenceRow(name, desc, value string) string {
+	if value == "" {
+		return fmt.Sprintf("| `%v` | %v | |\n", name, desc)
+	}
+	return fmt.Sprintf("| `%v` | %v | `%v` |\n", name, desc, value)
+}
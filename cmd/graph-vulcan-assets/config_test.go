@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the configuration reference in the README and the expected snapshot of test-env")
+
+// readmeFile is the README that contains the configuration reference.
+const readmeFile = "../../README.md"
+
+// Markers delimiting the configuration reference in the README.
+const (
+	referenceStart = "<!-- config-reference:start -->\n"
+	referenceEnd   = "<!-- config-reference:end -->\n"
+)
+
+func TestConfigReference(t *testing.T) {
+	readme, err := os.ReadFile(readmeFile)
+	if err != nil {
+		t.Fatalf("error reading README: %v", err)
+	}
+
+	start := bytes.Index(readme, []byte(referenceStart))
+	end := bytes.Index(readme, []byte(referenceEnd))
+	if start < 0 || end < start {
+		t.Fatalf("missing configuration reference markers")
+	}
+	start += len(referenceStart)
+
+	var buf bytes.Buffer
+	if err := writeConfigReference(&buf); err != nil {
+		t.Fatalf("error generating configuration reference: %v", err)
+	}
+
+	if *update {
+		var out []byte
+		out = append(out, readme[:start]...)
+		out = append(out, buf.Bytes()...)
+		out = append(out, readme[end:]...)
+		if err := os.WriteFile(readmeFile, out, 0o644); err != nil {
+			t.Fatalf("error writing README: %v", err)
+		}
+		return
+	}
+
+	if got := readme[start:end]; !bytes.Equal(got, buf.Bytes()) {
+		t.Errorf("outdated configuration reference, run \"go test -run TestConfigReference -update\"\ngot:\n%s\nwant:\n%s", got, buf.Bytes())
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	type testConfig struct {
+		Required string   `env:"TEST_REQUIRED" required:"true"`
+		Default  string   `env:"TEST_DEFAULT" default:"default"`
+		Empty    string   `env:"TEST_EMPTY,allowempty" default:"default"`
+		List     []string `env:"TEST_LIST"`
+		Ignored  string
+	}
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		want       testConfig
+		wantNilErr bool
+	}{
+		{
+			name: "defaults",
+			env: map[string]string{
+				"TEST_REQUIRED": "required",
+			},
+			want: testConfig{
+				Required: "required",
+				Default:  "default",
+				Empty:    "default",
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "empty values",
+			env: map[string]string{
+				"TEST_REQUIRED": "required",
+				"TEST_DEFAULT":  "",
+				"TEST_EMPTY":    "",
+			},
+			want: testConfig{
+				Required: "required",
+				Default:  "default",
+				Empty:    "",
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "prefix precedence",
+			env: map[string]string{
+				"TEST_REQUIRED":     "required",
+				"GVA_TEST_REQUIRED": "prefixed",
+				"GVA_TEST_LIST":     "a, b,,c",
+			},
+			want: testConfig{
+				Required: "prefixed",
+				Default:  "default",
+				Empty:    "default",
+				List:     []string{"a", "b", "c"},
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "missing required",
+			env: map[string]string{
+				"TEST_REQUIRED": "",
+			},
+			want:       testConfig{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var got testConfig
+			err := loadEnv(&got)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("config mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
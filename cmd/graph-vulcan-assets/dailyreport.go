@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/cron"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/s3"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Formats of the daily report.
+const (
+	dailyReportFormatJSON     = "json"
+	dailyReportFormatMarkdown = "markdown"
+)
+
+// publishTimeout is the maximum time to publish a daily report.
+const publishTimeout = time.Minute
+
+// Classes of the errors counted in the daily report.
+const (
+	errorClassMalformedPayload   = "malformed_payload"
+	errorClassUnsupportedVersion = "unsupported_version"
+	errorClassUnsupportedEntity  = "unsupported_entity"
+	errorClassInventoryTransient = "inventory_transient"
+	errorClassInventoryStatus    = "inventory_status"
+	errorClassOther              = "other"
+)
+
+// dailyActivity aggregates the activity of the consumer reported by the
+// daily report.
+var dailyActivity = newActivity(time.Now())
+
+var _ assetsync.Observer = (*activity)(nil)
+
+// activityCounts are the number of changes applied to the Asset Inventory.
+type activityCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Expired int `json:"expired"`
+	Failed  int `json:"failed"`
+}
+
+// activityEntry contains the counts of a team and asset type.
+type activityEntry struct {
+	TeamID    string `json:"team_id"`
+	AssetType string `json:"asset_type"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Expired   int    `json:"expired"`
+	Failed    int    `json:"failed"`
+}
+
+// activityKey identifies the entries of an [activity].
+type activityKey struct {
+	teamID string
+	typ    vulcan.AssetType
+}
+
+// activity aggregates the assets created, updated and expired by team and
+// asset type, and the processing errors by class, since the last daily
+// report. It is safe for concurrent use.
+type activity struct {
+	mu      sync.Mutex
+	since   time.Time
+	entries map[activityKey]*activityCounts
+	errors  map[string]int
+}
+
+// newActivity returns an empty [activity] that starts at the provided
+// time.
+func newActivity(since time.Time) *activity {
+	return &activity{
+		since:   since,
+		entries: make(map[activityKey]*activityCounts),
+		errors:  make(map[string]int),
+	}
+}
+
+// counts returns the counts of the team and asset type of payload. It must
+// be called with a.mu held.
+func (a *activity) counts(payload vulcan.AssetPayload) *activityCounts {
+	key := activityKey{payload.Team.ID, payload.AssetType}
+	c, ok := a.entries[key]
+	if !ok {
+		c = &activityCounts{}
+		a.entries[key] = c
+	}
+	return c
+}
+
+// AssetCreated records the creation of the asset of payload.
+func (a *activity) AssetCreated(payload vulcan.AssetPayload) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts(payload).Created++
+}
+
+// AssetUpdated records the update of the asset of payload.
+func (a *activity) AssetUpdated(payload vulcan.AssetPayload) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts(payload).Updated++
+}
+
+// AssetExpired records the expiration of the asset of payload.
+func (a *activity) AssetExpired(payload vulcan.AssetPayload) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts(payload).Expired++
+}
+
+// failed records a failure processing the event of payload.
+func (a *activity) failed(payload vulcan.AssetPayload, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts(payload).Failed++
+	a.errors[errorClass(err)]++
+}
+
+// invalid records a message that could not be parsed.
+func (a *activity) invalid(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.errors[errorClass(err)]++
+}
+
+// errorClass returns the class of err reported in the daily report.
+func errorClass(err error) string {
+	var merr vulcan.InvalidMessageError
+	if errors.As(err, &merr) {
+		switch merr.Reason {
+		case vulcan.ErrMalformedPayload:
+			return errorClassMalformedPayload
+		case vulcan.ErrUnsupportedVersion:
+			return errorClassUnsupportedVersion
+		case vulcan.ErrUnsupportedEntity:
+			return errorClassUnsupportedEntity
+		}
+	}
+
+	if inventory.IsTransient(err) {
+		return errorClassInventoryTransient
+	}
+
+	var serr inventory.InvalidStatusError
+	if errors.As(err, &serr) {
+		return errorClassInventoryStatus
+	}
+
+	return errorClassOther
+}
+
+// dailyReport is the summary of the activity of the consumer published
+// every day.
+type dailyReport struct {
+	Instance string          `json:"instance"`
+	Date     string          `json:"date"`
+	Since    time.Time       `json:"since"`
+	Until    time.Time       `json:"until"`
+	Totals   activityCounts  `json:"totals"`
+	Assets   []activityEntry `json:"assets"`
+	Errors   map[string]int  `json:"errors"`
+}
+
+// flush returns the report of the activity recorded until the provided time
+// and resets it.
+func (a *activity) flush(until time.Time) dailyReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rep := dailyReport{
+		Date:   a.since.UTC().Format("2006-01-02"),
+		Since:  a.since,
+		Until:  until,
+		Assets: make([]activityEntry, 0, len(a.entries)),
+		Errors: a.errors,
+	}
+	for k, c := range a.entries {
+		rep.Assets = append(rep.Assets, activityEntry{
+			TeamID:    k.teamID,
+			AssetType: string(k.typ),
+			Created:   c.Created,
+			Updated:   c.Updated,
+			Expired:   c.Expired,
+			Failed:    c.Failed,
+		})
+		rep.Totals.Created += c.Created
+		rep.Totals.Updated += c.Updated
+		rep.Totals.Expired += c.Expired
+		rep.Totals.Failed += c.Failed
+	}
+	sort.Slice(rep.Assets, func(i, j int) bool {
+		if rep.Assets[i].TeamID != rep.Assets[j].TeamID {
+			return rep.Assets[i].TeamID < rep.Assets[j].TeamID
+		}
+		return rep.Assets[i].AssetType < rep.Assets[j].AssetType
+	})
+
+	a.since = until
+	a.entries = make(map[activityKey]*activityCounts)
+	a.errors = make(map[string]int)
+
+	return rep
+}
+
+// encode writes rep to w using the provided format.
+func (rep dailyReport) encode(w io.Writer, format string) error {
+	switch format {
+	case dailyReportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case dailyReportFormatMarkdown:
+		return rep.markdown(w)
+	}
+	return fmt.Errorf("invalid format: %v", format)
+}
+
+// markdown writes rep to w using the Markdown format.
+func (rep dailyReport) markdown(w io.Writer) error {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# Security Graph sync summary %v\n\n", rep.Date)
+	fmt.Fprintf(&b, "Instance %v, from %v to %v.\n\n", rep.Instance, rep.Since.UTC().Format(time.RFC3339), rep.Until.UTC().Format(time.RFC3339))
+
+	fmt.Fprintln(&b, "| Created | Updated | Expired | Failed |")
+	fmt.Fprintln(&b, "| --- | --- | --- | --- |")
+	fmt.Fprintf(&b, "| %v | %v | %v | %v |\n", rep.Totals.Created, rep.Totals.Updated, rep.Totals.Expired, rep.Totals.Failed)
+
+	fmt.Fprintln(&b, "\n## Assets")
+	fmt.Fprintln(&b)
+	if len(rep.Assets) == 0 {
+		fmt.Fprintln(&b, "No activity.")
+	} else {
+		fmt.Fprintln(&b, "| Team | Asset type | Created | Updated | Expired | Failed |")
+		fmt.Fprintln(&b, "| --- | --- | --- | --- | --- | --- |")
+		for _, e := range rep.Assets {
+			fmt.Fprintf(&b, "| %v | %v | %v | %v | %v | %v |\n", e.TeamID, e.AssetType, e.Created, e.Updated, e.Expired, e.Failed)
+		}
+	}
+
+	fmt.Fprintln(&b, "\n## Errors")
+	fmt.Fprintln(&b)
+	if len(rep.Errors) == 0 {
+		fmt.Fprintln(&b, "No errors.")
+	} else {
+		classes := make([]string, 0, len(rep.Errors))
+		for class := range rep.Errors {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+
+		fmt.Fprintln(&b, "| Class | Errors |")
+		fmt.Fprintln(&b, "| --- | --- |")
+		for _, class := range classes {
+			fmt.Fprintf(&b, "| %v | %v |\n", class, rep.Errors[class])
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// objectPutter uploads objects to S3. It is implemented by [s3.Client].
+type objectPutter interface {
+	PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error
+}
+
+// dailyReporter publishes the daily reports to a webhook and to S3.
+type dailyReporter struct {
+	instance string
+	format   string
+	webhook  string
+
+	s3cli    objectPutter
+	s3bucket string
+	s3prefix string
+
+	httpcli *http.Client
+}
+
+// newDailyReporter returns the [dailyReporter] configured in cfg. If
+// neither DAILY_REPORT_WEBHOOK nor DAILY_REPORT_S3_URL are set, it returns
+// nil. The S3 credentials are read from the standard AWS environment
+// variables.
+func newDailyReporter(cfg config) (*dailyReporter, error) {
+	if cfg.DailyReportWebhook == "" && cfg.DailyReportS3URL == "" {
+		return nil, nil
+	}
+
+	instance, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	dr := &dailyReporter{
+		instance: instance,
+		format:   cfg.DailyReportFormat,
+		webhook:  cfg.DailyReportWebhook,
+		httpcli:  &http.Client{Timeout: publishTimeout},
+	}
+
+	if cfg.DailyReportS3URL != "" {
+		bucket, prefix, err := s3.ParseURL(cfg.DailyReportS3URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 URL: %w", err)
+		}
+		s3cli, err := s3.NewClient(s3.ConfigFromEnv())
+		if err != nil {
+			return nil, fmt.Errorf("error creating S3 client: %w", err)
+		}
+		dr.s3cli = s3cli
+		dr.s3bucket = bucket
+		dr.s3prefix = prefix
+	}
+
+	return dr, nil
+}
+
+// contentType returns the content type of the reports.
+func (dr *dailyReporter) contentType() string {
+	if dr.format == dailyReportFormatMarkdown {
+		return "text/markdown; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// objectKey returns the key of the S3 object of rep. Every instance
+// uploads its own report.
+func (dr *dailyReporter) objectKey(rep dailyReport) string {
+	ext := ".json"
+	if dr.format == dailyReportFormatMarkdown {
+		ext = ".md"
+	}
+	return path.Join(dr.s3prefix, rep.Date, rep.Instance+ext)
+}
+
+// publish publishes rep to the configured destinations.
+func (dr *dailyReporter) publish(ctx context.Context, rep dailyReport) error {
+	var b bytes.Buffer
+	if err := rep.encode(&b, dr.format); err != nil {
+		return fmt.Errorf("could not encode report: %w", err)
+	}
+
+	if dr.s3cli != nil {
+		if err := dr.s3cli.PutObject(ctx, dr.s3bucket, dr.objectKey(rep), b.Bytes(), dr.contentType()); err != nil {
+			return fmt.Errorf("could not upload report to S3: %w", err)
+		}
+	}
+
+	if dr.webhook != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, dr.webhook, bytes.NewReader(b.Bytes()))
+		if err != nil {
+			return fmt.Errorf("could not create HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", dr.contentType())
+		resp, err := dr.httpcli.Do(req)
+		if err != nil {
+			return fmt.Errorf("could not send report to webhook: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("could not send report to webhook: unexpected status code %v", resp.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// run publishes the activity recorded in act according to sched until the
+// context is cancelled.
+func (dr *dailyReporter) run(ctx context.Context, act *activity, sched cron.Schedule) {
+	for {
+		next := sched.Next(time.Now())
+		log.Info.Printf("graph-vulcan-assets: next daily report at %v", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		rep := act.flush(time.Now())
+		rep.Instance = dr.instance
+
+		pctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err := dr.publish(pctx, rep)
+		cancel()
+		if err != nil {
+			dailyReportsTotal.Inc("failed")
+			log.Error.Printf("graph-vulcan-assets: error publishing daily report %v: %v", rep.Date, err)
+			continue
+		}
+		dailyReportsTotal.Inc("published")
+		log.Info.Printf("graph-vulcan-assets: daily report %v published", rep.Date)
+	}
+}
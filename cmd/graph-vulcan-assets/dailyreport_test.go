@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func activityPayload(team string, typ vulcan.AssetType) vulcan.AssetPayload {
+	return vulcan.AssetPayload{
+		AssetType:  typ,
+		Identifier: "example.com",
+		Team:       vulcan.Team{ID: team},
+	}
+}
+
+func TestActivityFlush(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(24 * time.Hour)
+
+	act := newActivity(t0)
+	act.AssetCreated(activityPayload("team-2", "Hostname"))
+	act.AssetUpdated(activityPayload("team-1", "Hostname"))
+	act.AssetUpdated(activityPayload("team-1", "Hostname"))
+	act.AssetExpired(activityPayload("team-1", "IP"))
+	act.failed(activityPayload("team-1", "IP"), fmt.Errorf("could not refresh asset: %w", inventory.InvalidStatusError{Expected: []int{http.StatusOK}, Returned: http.StatusBadRequest}))
+	act.invalid(vulcan.InvalidMessageError{Reason: vulcan.ErrMalformedPayload})
+
+	want := dailyReport{
+		Date:   "2024-01-01",
+		Since:  t0,
+		Until:  t1,
+		Totals: activityCounts{Created: 1, Updated: 2, Expired: 1, Failed: 1},
+		Assets: []activityEntry{
+			{TeamID: "team-1", AssetType: "Hostname", Updated: 2},
+			{TeamID: "team-1", AssetType: "IP", Expired: 1, Failed: 1},
+			{TeamID: "team-2", AssetType: "Hostname", Created: 1},
+		},
+		Errors: map[string]int{
+			errorClassInventoryStatus:  1,
+			errorClassMalformedPayload: 1,
+		},
+	}
+	if diff := cmp.Diff(want, act.flush(t1)); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%v", diff)
+	}
+
+	want = dailyReport{
+		Date:   "2024-01-02",
+		Since:  t1,
+		Until:  t1.Add(24 * time.Hour),
+		Assets: []activityEntry{},
+		Errors: map[string]int{},
+	}
+	if diff := cmp.Diff(want, act.flush(t1.Add(24*time.Hour))); diff != "" {
+		t.Errorf("report mismatch after flush (-want +got):\n%v", diff)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "malformed payload",
+			err:  vulcan.InvalidMessageError{Reason: vulcan.ErrMalformedPayload},
+			want: errorClassMalformedPayload,
+		},
+		{
+			name: "unsupported version",
+			err:  vulcan.InvalidMessageError{Reason: vulcan.ErrUnsupportedVersion},
+			want: errorClassUnsupportedVersion,
+		},
+		{
+			name: "transient",
+			err:  fmt.Errorf("could not refresh asset: %w", inventory.InvalidStatusError{Expected: []int{http.StatusOK}, Returned: http.StatusServiceUnavailable}),
+			want: errorClassInventoryTransient,
+		},
+		{
+			name: "invalid status",
+			err:  fmt.Errorf("could not refresh asset: %w", inventory.InvalidStatusError{Expected: []int{http.StatusOK}, Returned: http.StatusBadRequest}),
+			want: errorClassInventoryStatus,
+		},
+		{
+			name: "other",
+			err:  errors.New("duplicated asset"),
+			want: errorClassOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorClass(tt.err); got != tt.want {
+				t.Errorf("unexpected class: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDailyReportMarkdown(t *testing.T) {
+	rep := dailyReport{
+		Instance: "graph-vulcan-assets-0",
+		Date:     "2024-01-01",
+		Since:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Totals:   activityCounts{Created: 1, Updated: 2},
+		Assets: []activityEntry{
+			{TeamID: "team-1", AssetType: "Hostname", Created: 1, Updated: 2},
+		},
+		Errors: map[string]int{},
+	}
+
+	var b bytes.Buffer
+	if err := rep.encode(&b, dailyReportFormatMarkdown); err != nil {
+		t.Fatalf("error encoding report: %v", err)
+	}
+
+	want := `# Security Graph sync summary 2024-01-01
+
+Instance graph-vulcan-assets-0, from 2024-01-01T00:00:00Z to 2024-01-02T00:00:00Z.
+
+| Created | Updated | Expired | Failed |
+| --- | --- | --- | --- |
+| 1 | 2 | 0 | 0 |
+
+## Assets
+
+| Team | Asset type | Created | Updated | Expired | Failed |
+| --- | --- | --- | --- | --- | --- |
+| team-1 | Hostname | 1 | 2 | 0 | 0 |
+
+## Errors
+
+No errors.
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%v", diff)
+	}
+}
+
+// memObjectPutter is an [objectPutter] that stores the objects in memory.
+type memObjectPutter map[string]string
+
+func (m memObjectPutter) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error {
+	m[bucket+"/"+key] = string(body)
+	return nil
+}
+
+func TestDailyReporterPublish(t *testing.T) {
+	var (
+		gotBody string
+		gotType string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	objects := memObjectPutter{}
+	dr := &dailyReporter{
+		format:   dailyReportFormatJSON,
+		webhook:  srv.URL,
+		s3cli:    objects,
+		s3bucket: "reports",
+		s3prefix: "graph-vulcan-assets",
+		httpcli:  srv.Client(),
+	}
+
+	rep := newActivity(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).flush(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	rep.Instance = "graph-vulcan-assets-0"
+	if err := dr.publish(context.Background(), rep); err != nil {
+		t.Fatalf("error publishing report: %v", err)
+	}
+
+	var want bytes.Buffer
+	if err := rep.encode(&want, dailyReportFormatJSON); err != nil {
+		t.Fatalf("error encoding report: %v", err)
+	}
+
+	if gotType != "application/json" {
+		t.Errorf("unexpected content type: %v", gotType)
+	}
+	if diff := cmp.Diff(want.String(), gotBody); diff != "" {
+		t.Errorf("webhook body mismatch (-want +got):\n%v", diff)
+	}
+	wantObjects := memObjectPutter{"reports/graph-vulcan-assets/2024-01-01/graph-vulcan-assets-0.json": want.String()}
+	if diff := cmp.Diff(wantObjects, objects); diff != "" {
+		t.Errorf("objects mismatch (-want +got):\n%v", diff)
+	}
+
+	dr.webhook = srv.URL + "/fail"
+	if err := dr.publish(context.Background(), rep); err == nil {
+		t.Error("expected error publishing report to a failing webhook")
+	}
+}
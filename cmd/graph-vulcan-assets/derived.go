@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// derivedAssetTypes are the types of the assets that the consumer creates
+// implicitly. See [assetsync.Config.AWSAccountAnnotationKeys].
+var derivedAssetTypes = []vulcan.AssetType{"AWSAccount"}
+
+// derivedStore reads the properties of the assets of the Security Graph. It
+// is implemented by [props.Store].
+type derivedStore interface {
+	Asset(id string) (map[string]string, error)
+}
+
+// runExpireDerived implements the expire-derived command. It expires the
+// derived assets without active children.
+func runExpireDerived(args []string) error {
+	fs := flag.NewFlagSet("expire-derived", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "do not expire the assets, just log them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	if !cfg.StoreVulcanIDs {
+		return errors.New("the Vulcan IDs are not stored, STORE_VULCAN_IDS is not enabled")
+	}
+
+	icli, err := newInventoryClient(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating asset inventory client: %w", err)
+	}
+
+	expired, err := expireDerived(icli, props.NewStore(icli), cfg, time.Now(), *dryRun)
+	if err != nil {
+		return err
+	}
+	log.Info.Printf("graph-vulcan-assets: %v derived assets without active children (dry-run=%v)", len(expired), *dryRun)
+	return nil
+}
+
+// expireDerived expires the derived assets that have no active children at
+// the provided time and returns them. An asset is derived if its property
+// [props.DerivedKey] is "true" and it has not been received from Vulcan,
+// which means that it has no Vulcan ID. The parent-of relations of the
+// assets are expired before the assets. If dryRun is true, the assets are
+// returned but not expired.
+func expireDerived(icli inventory.Inventory, store derivedStore, cfg config, now time.Time, dryRun bool) ([]inventory.AssetResp, error) {
+	var orphans []inventory.AssetResp
+	for _, typ := range derivedAssetTypes {
+		// The assets are collected before expiring them, so the
+		// pages are not modified during the walk.
+		var assets []inventory.AssetResp
+		err := inventory.WalkAssets(icli, string(typ), "", now, cfg.InventoryPageSize, func(a inventory.AssetResp) error {
+			assets = append(assets, a)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get %v assets: %w", typ, err)
+		}
+
+		for _, a := range assets {
+			orphan, err := derivedOrphan(icli, store, a, cfg, now)
+			if err != nil {
+				return nil, fmt.Errorf("could not check asset %v/%v: %w", a.Type, a.Identifier, err)
+			}
+			if orphan {
+				orphans = append(orphans, a)
+			}
+		}
+	}
+
+	for _, a := range orphans {
+		if dryRun {
+			log.Info.Printf("graph-vulcan-assets: dry-run: expire derived asset %v/%v", a.Type, a.Identifier)
+			continue
+		}
+
+		if err := inventory.ExpireRelations(icli, []string{a.ID}, now, cfg.InventoryPageSize, cfg.InventoryParallelism); err != nil {
+			return nil, fmt.Errorf("error expiring parent-of relations of %v/%v: %w", a.Type, a.Identifier, err)
+		}
+		if _, err := icli.UpdateAsset(a.ID, a.Type, a.Identifier, now, now); err != nil {
+			return nil, fmt.Errorf("could not expire asset %v/%v: %w", a.Type, a.Identifier, err)
+		}
+		log.Info.Printf("graph-vulcan-assets: expired derived asset %v/%v", a.Type, a.Identifier)
+	}
+
+	return orphans, nil
+}
+
+// derivedOrphan reports whether the provided asset is derived and has no
+// active children at the provided time.
+func derivedOrphan(icli inventory.Inventory, store derivedStore, asset inventory.AssetResp, cfg config, now time.Time) (bool, error) {
+	p, err := store.Asset(asset.ID)
+	if err != nil {
+		return false, fmt.Errorf("could not get properties: %w", err)
+	}
+	if p[props.DerivedKey] != "true" || p[props.VulcanAssetIDKey] != "" {
+		return false, nil
+	}
+
+	children, err := inventory.AllChildren(icli, asset.ID, cfg.InventoryPageSize)
+	if err != nil {
+		return false, fmt.Errorf("could not get children: %w", err)
+	}
+	for _, c := range children {
+		if !inventory.IsExpired(c.Expiration, now) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestExpireDerived(t *testing.T) {
+	tests := []struct {
+		name        string
+		expireChild bool
+		vulcanAsset bool
+		dryRun      bool
+		wantExpired bool
+	}{
+		{
+			name:        "orphan",
+			expireChild: true,
+			wantExpired: true,
+		},
+		{
+			name:        "active child",
+			expireChild: false,
+			wantExpired: false,
+		},
+		{
+			name:        "vulcan asset",
+			expireChild: true,
+			vulcanAsset: true,
+			wantExpired: false,
+		},
+		{
+			name:        "dry run",
+			expireChild: true,
+			dryRun:      true,
+			wantExpired: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config{
+				InventoryPageSize:        100,
+				AWSAccountAnnotationKeys: []string{"discovery/aws/account"},
+			}
+
+			inv := inventorytest.NewInMemory()
+			vids := make(memVulcanIDStore)
+			scfg := engineConfig(cfg)
+			scfg.VulcanIDs = vids
+			engine := assetsync.NewEngine(inv, scfg)
+
+			payload := vulcan.AssetPayload{
+				ID:          "vulcan-asset-1",
+				Team:        vulcan.Team{ID: "vulcan-team-1", Name: "Team 1"},
+				AssetType:   "Hostname",
+				Identifier:  "example.com",
+				Annotations: []vulcan.Annotation{{Key: "discovery/aws/account", Value: "123456789012"}},
+			}
+			if err := engine.Handle(payload, false); err != nil {
+				t.Fatalf("error handling asset: %v", err)
+			}
+
+			accounts, err := inventory.AllAssets(inv, "AWSAccount", "arn:aws:iam::123456789012:root", time.Time{}, 100)
+			if err != nil || len(accounts) != 1 {
+				t.Fatalf("unexpected AWS accounts: %v, %v", accounts, err)
+			}
+			account := accounts[0]
+
+			if diff := cmp.Diff(map[string]string{props.DerivedKey: "true"}, vids[account.ID]); diff != "" {
+				t.Errorf("AWS account properties mismatch (-want +got):\n%v", diff)
+			}
+
+			if tt.vulcanAsset {
+				if err := vids.SetAsset(account.ID, map[string]string{props.VulcanAssetIDKey: "vulcan-asset-2"}); err != nil {
+					t.Fatalf("could not set Vulcan ID: %v", err)
+				}
+			}
+
+			if tt.expireChild {
+				if err := engine.Handle(payload, true); err != nil {
+					t.Fatalf("error handling tombstone: %v", err)
+				}
+			}
+
+			now := time.Now()
+			expired, err := expireDerived(inv, vids, cfg, now, tt.dryRun)
+			if err != nil {
+				t.Fatalf("error expiring derived assets: %v", err)
+			}
+
+			wantReturned := tt.wantExpired || tt.dryRun
+			if got := len(expired) == 1 && expired[0].ID == account.ID; got != wantReturned {
+				t.Errorf("unexpected returned assets: %v", expired)
+			}
+
+			active, err := inventory.AllAssets(inv, "AWSAccount", "", now, 100)
+			if err != nil {
+				t.Fatalf("could not get AWS accounts: %v", err)
+			}
+			if gotExpired := len(active) == 0; gotExpired != tt.wantExpired {
+				t.Errorf("unexpected expiration: want=%v got=%v", tt.wantExpired, gotExpired)
+			}
+		})
+	}
+}
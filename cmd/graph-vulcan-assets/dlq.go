@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// Policies applied to the messages larger than MAX_MESSAGE_SIZE.
+const (
+	oversizedPolicyFail = "fail"
+	oversizedPolicySkip = "skip"
+	oversizedPolicyDLQ  = "dlq"
+)
+
+// Metadata keys added to the records sent to the dead letter queue.
+const (
+	dlqReasonKey    = "dlq-reason"
+	dlqTopicKey     = "dlq-original-topic"
+	dlqPartitionKey = "dlq-original-partition"
+	dlqOffsetKey    = "dlq-original-offset"
+	dlqSizeKey      = "dlq-original-size"
+)
+
+// dlqReasonOversized is the reason of the records sent to the dead letter
+// queue because their value exceeds MAX_MESSAGE_SIZE.
+const dlqReasonOversized = "oversized"
+
+// dlqProducer delivers records to the dead letter queue. It is implemented
+// by [kafka.Producer].
+type dlqProducer interface {
+	ProduceSync(ctx context.Context, topic string, msg stream.Message) error
+}
+
+// dlqRecord returns the record sent to the dead letter queue for msg. The
+// metadata of msg is preserved and extended with the reason and the original
+// position and size of the message. If maxSize is greater than zero, the
+// value is truncated to maxSize bytes.
+func dlqRecord(msg stream.Message, reason string, maxSize int) stream.Message {
+	value := msg.Value
+	if maxSize > 0 && len(value) > maxSize {
+		value = value[:maxSize]
+	}
+
+	md := append([]stream.MetadataEntry(nil), msg.Metadata...)
+	md = append(md,
+		stream.MetadataEntry{Key: []byte(dlqReasonKey), Value: []byte(reason)},
+		stream.MetadataEntry{Key: []byte(dlqTopicKey), Value: []byte(msg.Position.Topic)},
+		stream.MetadataEntry{Key: []byte(dlqPartitionKey), Value: []byte(strconv.FormatInt(int64(msg.Position.Partition), 10))},
+		stream.MetadataEntry{Key: []byte(dlqOffsetKey), Value: []byte(strconv.FormatInt(msg.Position.Offset, 10))},
+		stream.MetadataEntry{Key: []byte(dlqSizeKey), Value: []byte(strconv.Itoa(len(msg.Value)))},
+	)
+
+	return stream.Message{
+		Key:      msg.Key,
+		Value:    value,
+		Metadata: md,
+	}
+}
+
+// oversizedHandler returns the handler called for the messages larger than
+// cfg.MaxMessageSize according to cfg.OversizedMessagePolicy. dlq is only
+// used by the dlq policy.
+func oversizedHandler(ctx context.Context, cfg config, dlq dlqProducer) stream.MsgHandler {
+	switch cfg.OversizedMessagePolicy {
+	case oversizedPolicySkip:
+		return func(msg stream.Message) error {
+			oversizedMessagesTotal.Inc(oversizedPolicySkip)
+			log.Error.Printf("graph-vulcan-assets: skipping oversized message %v (key %q): %v bytes", msg.Position, msg.Key, len(msg.Value))
+			return nil
+		}
+	case oversizedPolicyDLQ:
+		return func(msg stream.Message) error {
+			oversizedMessagesTotal.Inc(oversizedPolicyDLQ)
+			log.Error.Printf("graph-vulcan-assets: sending oversized message %v (key %q) to %v: %v bytes", msg.Position, msg.Key, cfg.DLQTopic, len(msg.Value))
+			rec := dlqRecord(msg, dlqReasonOversized, cfg.MaxMessageSize)
+			if err := dlq.ProduceSync(ctx, cfg.DLQTopic, rec); err != nil {
+				return fmt.Errorf("could not send message to the dead letter queue: %w", err)
+			}
+			return nil
+		}
+	default:
+		return func(msg stream.Message) error {
+			oversizedMessagesTotal.Inc(oversizedPolicyFail)
+			return fmt.Errorf("%w: %v bytes, limit is %v bytes", stream.ErrMessageTooLarge, len(msg.Value), cfg.MaxMessageSize)
+		}
+	}
+}
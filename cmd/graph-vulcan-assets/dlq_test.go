@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+var errDLQ = errors.New("dlq error")
+
+// fakeDLQProducer is a [dlqProducer] that stores the produced records.
+type fakeDLQProducer struct {
+	topics []string
+	msgs   []stream.Message
+	err    error
+}
+
+func (p *fakeDLQProducer) ProduceSync(ctx context.Context, topic string, msg stream.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.topics = append(p.topics, topic)
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+var oversizedMsg = stream.Message{
+	Key:   []byte("team/asset"),
+	Value: []byte("0123456789"),
+	Metadata: []stream.MetadataEntry{
+		{Key: []byte("version"), Value: []byte("0.1.2")},
+	},
+	Position: stream.Position{Topic: "assets-v0", Partition: 2, Offset: 42},
+}
+
+func TestDLQRecord(t *testing.T) {
+	want := stream.Message{
+		Key:   []byte("team/asset"),
+		Value: []byte("01234"),
+		Metadata: []stream.MetadataEntry{
+			{Key: []byte("version"), Value: []byte("0.1.2")},
+			{Key: []byte("dlq-reason"), Value: []byte("oversized")},
+			{Key: []byte("dlq-original-topic"), Value: []byte("assets-v0")},
+			{Key: []byte("dlq-original-partition"), Value: []byte("2")},
+			{Key: []byte("dlq-original-offset"), Value: []byte("42")},
+			{Key: []byte("dlq-original-size"), Value: []byte("10")},
+		},
+	}
+
+	got := dlqRecord(oversizedMsg, dlqReasonOversized, 5)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("record mismatch (-want +got):\n%v", diff)
+	}
+
+	if len(oversizedMsg.Metadata) != 1 {
+		t.Errorf("original metadata modified: %v", oversizedMsg.Metadata)
+	}
+}
+
+func TestOversizedHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		dlqErr     error
+		wantErr    error
+		wantTopics []string
+	}{
+		{
+			name:       "fail",
+			policy:     oversizedPolicyFail,
+			wantErr:    stream.ErrMessageTooLarge,
+			wantTopics: nil,
+		},
+		{
+			name:       "skip",
+			policy:     oversizedPolicySkip,
+			wantErr:    nil,
+			wantTopics: nil,
+		},
+		{
+			name:       "dlq",
+			policy:     oversizedPolicyDLQ,
+			wantErr:    nil,
+			wantTopics: []string{"assets-v0-dlq"},
+		},
+		{
+			name:       "dlq error",
+			policy:     oversizedPolicyDLQ,
+			dlqErr:     errDLQ,
+			wantErr:    errDLQ,
+			wantTopics: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config{
+				MaxMessageSize:         5,
+				OversizedMessagePolicy: tt.policy,
+				DLQTopic:               "assets-v0-dlq",
+			}
+			dlq := &fakeDLQProducer{err: tt.dlqErr}
+
+			err := oversizedHandler(context.Background(), cfg, dlq)(oversizedMsg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tt.wantTopics, dlq.topics); diff != "" {
+				t.Errorf("topics mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
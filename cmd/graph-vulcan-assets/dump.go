@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// dumpPageSize is the page size used when listing entities from the Asset
+// Inventory.
+const dumpPageSize = 100
+
+// assetDump is the representation of an asset and its relations printed by
+// the dump command.
+type assetDump struct {
+	Asset    inventory.AssetResp      `json:"asset"`
+	Owners   []inventory.OwnsResp     `json:"owners"`
+	Parents  []inventory.ParentOfResp `json:"parents"`
+	Children []inventory.ParentOfResp `json:"children"`
+}
+
+// teamDump is the representation of a team and its relations printed by the
+// dump command.
+type teamDump struct {
+	Team   inventory.TeamResp `json:"team"`
+	Assets []ownedAssetDump   `json:"assets"`
+}
+
+// ownedAssetDump is an asset owned by a team along with the owns relation.
+type ownedAssetDump struct {
+	Asset inventory.AssetResp `json:"asset"`
+	Owns  inventory.OwnsResp  `json:"owns"`
+}
+
+// runDump implements the dump command. It prints the team or asset specified
+// in args with all its relations as JSON.
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	team := fs.String("team", "", "identifier of the team to dump")
+	asset := fs.String("asset", "", "asset to dump with the format <type>/<identifier>")
+	allAssets := fs.String("all-assets", "", "dump all the assets of this type, one per line, without relations (\"*\" for all types)")
+	modifiedSince := fs.String("modified-since", "", "with -all-assets, dump only the assets created or updated after this RFC3339 time")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n := 0
+	for _, v := range []string{*team, *asset, *allAssets} {
+		if v != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of -team, -asset or -all-assets must be specified")
+	}
+
+	var since time.Time
+	if *modifiedSince != "" {
+		if *allAssets == "" {
+			return errors.New("-modified-since requires -all-assets")
+		}
+		t, err := time.Parse(time.RFC3339, *modifiedSince)
+		if err != nil {
+			return fmt.Errorf("invalid modified since time: %w", err)
+		}
+		since = t
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	icli, err := newInventoryClient(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating asset inventory client: %w", err)
+	}
+
+	if *team != "" {
+		return dumpTeam(os.Stdout, icli, *team)
+	}
+
+	if *allAssets != "" {
+		typ := *allAssets
+		if typ == "*" {
+			typ = ""
+		}
+		return dumpAllAssets(os.Stdout, icli, typ, since)
+	}
+
+	typ, identifier, err := parseAssetRef(*asset)
+	if err != nil {
+		return fmt.Errorf("invalid asset: %w", err)
+	}
+	return dumpAsset(os.Stdout, icli, typ, identifier)
+}
+
+// parseAssetRef parses an asset reference with the format
+// "<type>/<identifier>". Only the first slash is considered a separator, so
+// identifiers can contain slashes.
+func parseAssetRef(ref string) (typ, identifier string, err error) {
+	typ, identifier, found := strings.Cut(ref, "/")
+	if !found || typ == "" || identifier == "" {
+		return "", "", fmt.Errorf("malformed asset reference %q", ref)
+	}
+	return typ, identifier, nil
+}
+
+// dumpTeam writes the team with the provided identifier, as well as the
+// assets it owns and their owns relations, to w. The Asset Inventory API
+// does not allow to list the assets of a team, so all the assets are
+// walked and the owners of every asset are checked.
+func dumpTeam(w io.Writer, icli inventory.Inventory, identifier string) error {
+	teams, err := inventory.AllTeams(icli, identifier, dumpPageSize)
+	if err != nil {
+		return fmt.Errorf("could not get teams: %w", err)
+	}
+
+	switch len(teams) {
+	case 0:
+		return inventory.ErrNotFound
+	case 1:
+	default:
+		return errors.New("duplicated team")
+	}
+
+	dump := teamDump{Team: teams[0], Assets: []ownedAssetDump{}}
+
+	err = inventory.WalkAssets(icli, "", "", time.Time{}, dumpPageSize, func(asset inventory.AssetResp) error {
+		owners, err := inventory.AllOwners(icli, asset.ID, dumpPageSize)
+		if err != nil {
+			return fmt.Errorf("could not get owners of %v/%v: %w", asset.Type, asset.Identifier, err)
+		}
+		for _, o := range owners {
+			if o.TeamID == dump.Team.ID {
+				dump.Assets = append(dump.Assets, ownedAssetDump{Asset: asset, Owns: o})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not get owned assets: %w", err)
+	}
+
+	return writeJSON(w, dump)
+}
+
+// dumpAsset writes the asset with the provided type and identifier, as well as
+// its owns and parent-of relations, to w.
+func dumpAsset(w io.Writer, icli inventory.Inventory, typ, identifier string) error {
+	assets, err := inventory.AllAssets(icli, typ, identifier, time.Time{}, dumpPageSize)
+	if err != nil {
+		return fmt.Errorf("could not get assets: %w", err)
+	}
+
+	switch len(assets) {
+	case 0:
+		return inventory.ErrNotFound
+	case 1:
+	default:
+		return errors.New("duplicated asset")
+	}
+
+	dump := assetDump{Asset: assets[0]}
+
+	dump.Owners, err = inventory.AllOwners(icli, dump.Asset.ID, dumpPageSize)
+	if err != nil {
+		return fmt.Errorf("could not get owners: %w", err)
+	}
+
+	dump.Parents, err = inventory.AllParents(icli, dump.Asset.ID, dumpPageSize)
+	if err != nil {
+		return fmt.Errorf("could not get parents: %w", err)
+	}
+
+	dump.Children, err = inventory.AllChildren(icli, dump.Asset.ID, dumpPageSize)
+	if err != nil {
+		return fmt.Errorf("could not get children: %w", err)
+	}
+
+	return writeJSON(w, dump)
+}
+
+// dumpAllAssets writes the assets with the provided type to w as JSON, one
+// per line. If typ is empty, all the assets are written. If since is not
+// zero, only the assets created or updated after since are written. The
+// assets are written as they are received from the Asset Inventory, so
+// exports of millions of assets do not need to fit in memory.
+func dumpAllAssets(w io.Writer, icli inventory.Inventory, typ string, since time.Time) error {
+	enc := json.NewEncoder(w)
+	write := func(asset inventory.AssetResp) error {
+		if err := enc.Encode(asset); err != nil {
+			return fmt.Errorf("could not encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	var err error
+	if since.IsZero() {
+		err = inventory.WalkAssets(icli, typ, "", time.Time{}, dumpPageSize, write)
+	} else {
+		// The updated-after filter of the Asset Inventory
+		// cannot be combined with the type filter.
+		err = inventory.WalkAssetsModifiedSince(icli, since, dumpPageSize, func(asset inventory.AssetResp) error {
+			if typ != "" && asset.Type != typ {
+				return nil
+			}
+			return write(asset)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("could not get assets: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("could not encode JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+)
+
+func TestParseAssetRef(t *testing.T) {
+	tests := []struct {
+		name           string
+		ref            string
+		wantType       string
+		wantIdentifier string
+		wantNilErr     bool
+	}{
+		{
+			name:           "valid reference",
+			ref:            "Hostname/www.example.com",
+			wantType:       "Hostname",
+			wantIdentifier: "www.example.com",
+			wantNilErr:     true,
+		},
+		{
+			name:           "identifier with slashes",
+			ref:            "WebAddress/https://www.example.com/",
+			wantType:       "WebAddress",
+			wantIdentifier: "https://www.example.com/",
+			wantNilErr:     true,
+		},
+		{
+			name:           "missing separator",
+			ref:            "Hostname",
+			wantType:       "",
+			wantIdentifier: "",
+			wantNilErr:     false,
+		},
+		{
+			name:           "empty type",
+			ref:            "/www.example.com",
+			wantType:       "",
+			wantIdentifier: "",
+			wantNilErr:     false,
+		},
+		{
+			name:           "empty identifier",
+			ref:            "Hostname/",
+			wantType:       "",
+			wantIdentifier: "",
+			wantNilErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, identifier, err := parseAssetRef(tt.ref)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if typ != tt.wantType || identifier != tt.wantIdentifier {
+				t.Errorf("unexpected asset: want=%v/%v, got=%v/%v", tt.wantType, tt.wantIdentifier, typ, identifier)
+			}
+		})
+	}
+}
+
+func TestDumpAllAssets(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	for _, a := range []struct{ typ, identifier string }{
+		{"Hostname", "a.example.com"},
+		{"IP", "192.0.2.1"},
+		{"Hostname", "b.example.com"},
+	} {
+		if _, err := inv.CreateAsset(a.typ, a.identifier, time.Now(), inventory.Unexpired); err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := dumpAllAssets(&buf, inv, "Hostname", time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var asset inventory.AssetResp
+		if err := dec.Decode(&asset); err != nil {
+			t.Fatalf("error decoding asset: %v", err)
+		}
+		got = append(got, asset.Identifier)
+	}
+
+	want := []string{"a.example.com", "b.example.com"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestDumpAllAssetsModifiedSince(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := t0
+
+	inv := inventorytest.NewInMemory()
+	inv.Now = func() time.Time { return now }
+
+	for _, a := range []struct {
+		typ, identifier string
+		created         time.Time
+	}{
+		{"Hostname", "a.example.com", t0},
+		{"Hostname", "b.example.com", t0.Add(2 * time.Hour)},
+		{"IP", "192.0.2.1", t0.Add(2 * time.Hour)},
+	} {
+		now = a.created
+		if _, err := inv.CreateAsset(a.typ, a.identifier, time.Time{}, inventory.Unexpired); err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := dumpAllAssets(&buf, inv, "Hostname", t0.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var asset inventory.AssetResp
+		if err := dec.Decode(&asset); err != nil {
+			t.Fatalf("error decoding asset: %v", err)
+		}
+		got = append(got, asset.Identifier)
+	}
+
+	want := []string{"b.example.com"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestDumpTeam(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	inv := inventorytest.NewInMemory()
+	team, err := inv.CreateTeam("team-1", "Team 1")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	other, err := inv.CreateTeam("team-2", "Team 2")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+
+	for _, o := range []struct {
+		identifier string
+		team       inventory.TeamResp
+		end        time.Time
+	}{
+		{"a.example.com", team, time.Time{}},
+		{"b.example.com", other, time.Time{}},
+		{"c.example.com", team, t1},
+	} {
+		asset, err := inv.CreateAsset("Hostname", o.identifier, t0, inventory.Unexpired)
+		if err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+		if _, err := inv.UpsertOwner(asset.ID, o.team.ID, t0, o.end); err != nil {
+			t.Fatalf("error creating owner: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := dumpTeam(&buf, inv, "team-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got teamDump
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("error decoding team: %v", err)
+	}
+
+	if got.Team.Identifier != "team-1" {
+		t.Errorf("unexpected team: %v", got.Team.Identifier)
+	}
+
+	type owned struct {
+		Identifier string
+		StartTime  time.Time
+		EndTime    *time.Time
+	}
+	var gotOwned []owned
+	for _, a := range got.Assets {
+		if a.Owns.AssetID != a.Asset.ID || a.Owns.TeamID != got.Team.ID {
+			t.Errorf("unexpected owns relation: %+v", a.Owns)
+		}
+		gotOwned = append(gotOwned, owned{a.Asset.Identifier, a.Owns.StartTime, a.Owns.EndTime})
+	}
+
+	wantOwned := []owned{
+		{"a.example.com", t0, nil},
+		{"c.example.com", t0, &t1},
+	}
+	if diff := cmp.Diff(wantOwned, gotOwned); diff != "" {
+		t.Errorf("owned assets mismatch (-want +got):\n%v", diff)
+	}
+}
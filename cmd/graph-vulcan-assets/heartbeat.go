@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// heartbeatInterval is the minimum time between two consecutive writes of
+// the heartbeat file.
+const heartbeatInterval = 1 * time.Second
+
+// heartbeatState is the content of the heartbeat file.
+type heartbeatState struct {
+	// LastProcessed is the time when the last message was processed.
+	LastProcessed time.Time `json:"last_processed"`
+
+	// LastPosition is the position of the last processed message with
+	// the format "topic/partition@offset".
+	LastPosition string `json:"last_position,omitempty"`
+
+	// Offsets contains the offset of the last processed message of
+	// every partition, keyed by "topic/partition".
+	Offsets map[string]int64 `json:"offsets,omitempty"`
+
+	// Processed is the number of messages processed since the command
+	// started.
+	Processed uint64 `json:"processed"`
+}
+
+// heartbeat keeps a heartbeat file updated with the processing state, so
+// external watchdogs can detect a stuck consumer.
+type heartbeat struct {
+	path string
+
+	mu        sync.Mutex
+	state     heartbeatState
+	lastWrite time.Time
+	pending   bool
+	timer     *time.Timer
+	closed    bool
+}
+
+// newHeartbeat returns a heartbeat that writes the processing state to the
+// file in path.
+func newHeartbeat(path string) *heartbeat {
+	return &heartbeat{path: path}
+}
+
+// beat records that the message with the provided position has been
+// processed at the provided time. The heartbeat file is written at most once
+// every [heartbeatInterval]. The beats received in between are written when
+// the interval elapses, so the file is not left behind when processing
+// stops.
+func (hb *heartbeat) beat(now time.Time, pos stream.Position) error {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	hb.state.LastProcessed = now
+	hb.state.Processed++
+	if pos.Topic != "" {
+		hb.state.LastPosition = pos.String()
+		if hb.state.Offsets == nil {
+			hb.state.Offsets = make(map[string]int64)
+		}
+		hb.state.Offsets[fmt.Sprintf("%v/%v", pos.Topic, pos.Partition)] = pos.Offset
+	}
+	hb.pending = true
+
+	if wait := heartbeatInterval - now.Sub(hb.lastWrite); wait > 0 {
+		if hb.timer == nil && !hb.closed {
+			hb.timer = time.AfterFunc(wait, func() {
+				if err := hb.flush(); err != nil {
+					log.Error.Printf("graph-vulcan-assets: could not write heartbeat: %v", err)
+				}
+			})
+		}
+		return nil
+	}
+
+	return hb.write(now)
+}
+
+// flush writes the beats that have not been written yet.
+func (hb *heartbeat) flush() error {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	hb.timer = nil
+	if !hb.pending {
+		return nil
+	}
+	return hb.write(time.Now())
+}
+
+// close stops the pending writes and writes the beats that have not been
+// written yet. It must be called when processing stops, so the file
+// reflects the last processed message.
+func (hb *heartbeat) close() error {
+	hb.mu.Lock()
+	hb.closed = true
+	if hb.timer != nil {
+		hb.timer.Stop()
+	}
+	hb.mu.Unlock()
+
+	return hb.flush()
+}
+
+// write writes the processing state to the heartbeat file. It must be called
+// with hb.mu held.
+func (hb *heartbeat) write(now time.Time) error {
+	if err := writeFileAtomic(hb.path, hb.state); err != nil {
+		return err
+	}
+	hb.lastWrite = now
+	hb.pending = false
+	return nil
+}
+
+// handler returns an [vulcan.AssetHandler] that calls h and, if it succeeds,
+// records a heartbeat.
+func (hb *heartbeat) handler(h vulcan.AssetHandler) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		if err := h(payload, isNil); err != nil {
+			return err
+		}
+
+		if err := hb.beat(time.Now(), payload.Position); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not write heartbeat: %v", err)
+		}
+
+		return nil
+	}
+}
+
+// writeFileAtomic writes v as JSON to the file in path. The file is written
+// to a temporary file in the same directory and then renamed, so readers
+// never observe a partially written file.
+func writeFileAtomic(path string, v any) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode JSON: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not close temporary file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("could not rename temporary file: %w", err)
+	}
+
+	return nil
+}
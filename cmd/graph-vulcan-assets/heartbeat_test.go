@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestHeartbeatBeat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heartbeat.json")
+	hb := newHeartbeat(path)
+
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pos := func(partition int32, offset int64) stream.Position {
+		return stream.Position{Topic: "assets", Partition: partition, Offset: offset}
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		pos  stream.Position
+		want heartbeatState
+	}{
+		{
+			name: "first beat",
+			now:  t0,
+			pos:  pos(0, 10),
+			want: heartbeatState{
+				LastProcessed: t0,
+				LastPosition:  "assets/0@10",
+				Offsets:       map[string]int64{"assets/0": 10},
+				Processed:     1,
+			},
+		},
+		{
+			name: "rate limited beat",
+			now:  t0.Add(heartbeatInterval / 2),
+			pos:  pos(1, 5),
+			want: heartbeatState{
+				LastProcessed: t0,
+				LastPosition:  "assets/0@10",
+				Offsets:       map[string]int64{"assets/0": 10},
+				Processed:     1,
+			},
+		},
+		{
+			name: "beat after interval",
+			now:  t0.Add(heartbeatInterval),
+			pos:  pos(0, 11),
+			want: heartbeatState{
+				LastProcessed: t0.Add(heartbeatInterval),
+				LastPosition:  "assets/0@11",
+				Offsets:       map[string]int64{"assets/0": 11, "assets/1": 5},
+				Processed:     3,
+			},
+		},
+		{
+			name: "beat without position",
+			now:  t0.Add(2 * heartbeatInterval),
+			want: heartbeatState{
+				LastProcessed: t0.Add(2 * heartbeatInterval),
+				LastPosition:  "assets/0@11",
+				Offsets:       map[string]int64{"assets/0": 11, "assets/1": 5},
+				Processed:     4,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := hb.beat(tt.now, tt.pos); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, readHeartbeat(t, path)); diff != "" {
+				t.Errorf("heartbeat mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	if err := hb.close(); err != nil {
+		t.Fatalf("could not close heartbeat: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("could not read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("unexpected number of files: want=1, got=%v", len(entries))
+	}
+}
+
+func TestHeartbeatClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heartbeat.json")
+	hb := newHeartbeat(path)
+
+	now := time.Now()
+	if err := hb.beat(now, stream.Position{Topic: "assets", Partition: 0, Offset: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hb.beat(now, stream.Position{Topic: "assets", Partition: 0, Offset: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hb.close(); err != nil {
+		t.Fatalf("could not close heartbeat: %v", err)
+	}
+
+	got := readHeartbeat(t, path)
+	if got.LastPosition != "assets/0@2" || got.Processed != 2 {
+		t.Errorf("last beat not written: %+v", got)
+	}
+}
+
+func TestHeartbeatPendingBeat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heartbeat.json")
+	hb := newHeartbeat(path)
+	defer hb.close()
+
+	now := time.Now()
+	if err := hb.beat(now, stream.Position{Topic: "assets", Partition: 0, Offset: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hb.beat(now, stream.Position{Topic: "assets", Partition: 0, Offset: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * heartbeatInterval)
+	for {
+		if got := readHeartbeat(t, path); got.Processed == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pending beat not written")
+		}
+		time.Sleep(heartbeatInterval / 10)
+	}
+}
+
+// readHeartbeat reads the heartbeat file in path.
+func readHeartbeat(t *testing.T, path string) heartbeatState {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read heartbeat file: %v", err)
+	}
+
+	var st heartbeatState
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatalf("could not parse heartbeat file: %v", err)
+	}
+	return st
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// lagInterval is the time between two consecutive updates of the processing
+// lag of the partitions.
+const lagInterval = 10 * time.Second
+
+// partitionLag tracks, for every assigned partition, the timestamp of the
+// last processed message. The processing lag of a partition is the time
+// elapsed since that timestamp. It is exported as a gauge and a warning is
+// logged when it exceeds the freshness threshold. It is safe for concurrent
+// use.
+type partitionLag struct {
+	threshold time.Duration
+
+	mu    sync.Mutex
+	last  map[int32]time.Time
+	stale map[int32]bool
+}
+
+// newPartitionLag returns a [partitionLag] that logs a warning when the lag
+// of a partition exceeds threshold. If threshold is zero, no warning is
+// logged.
+func newPartitionLag(threshold time.Duration) *partitionLag {
+	return &partitionLag{
+		threshold: threshold,
+		last:      make(map[int32]time.Time),
+		stale:     make(map[int32]bool),
+	}
+}
+
+// record records that a message of the provided partition with the
+// provided timestamp has been processed. Messages without timestamp and
+// messages older than the last processed one are ignored.
+func (l *partitionLag) record(partition int32, ts time.Time) {
+	if ts.IsZero() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ts.After(l.last[partition]) {
+		l.last[partition] = ts
+	}
+}
+
+// revoked stops tracking the provided partitions. It has the signature of
+// [kafka.Lifecycle.OnRevoke].
+func (l *partitionLag) revoked(partitions []int32, lost bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, p := range partitions {
+		delete(l.last, p)
+		delete(l.stale, p)
+		partitionLagSeconds.Delete(partitionLabel(p))
+	}
+}
+
+// update computes the lag of the tracked partitions at the provided time,
+// updates the corresponding gauges and logs a warning for every partition
+// whose lag exceeds the freshness threshold. The warning is logged once
+// until the partition catches up. It returns the lag of every partition.
+func (l *partitionLag) update(now time.Time) map[int32]time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lags := make(map[int32]time.Duration, len(l.last))
+	for p, ts := range l.last {
+		lag := now.Sub(ts)
+		if lag < 0 {
+			lag = 0
+		}
+		lags[p] = lag
+		partitionLagSeconds.Set(lag.Seconds(), partitionLabel(p))
+
+		if l.threshold == 0 {
+			continue
+		}
+		stale := lag > l.threshold
+		if stale && !l.stale[p] {
+			log.Error.Printf("graph-vulcan-assets: partition %v is stale: last processed message is %v old (threshold %v)", p, lag.Round(time.Second), l.threshold)
+		} else if !stale && l.stale[p] {
+			log.Info.Printf("graph-vulcan-assets: partition %v caught up: last processed message is %v old", p, lag.Round(time.Second))
+		}
+		l.stale[p] = stale
+	}
+	return lags
+}
+
+// lags returns the lag of the tracked partitions at the provided time
+// without updating the gauges.
+func (l *partitionLag) lags(now time.Time) map[int32]time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lags := make(map[int32]time.Duration, len(l.last))
+	for p, ts := range l.last {
+		lag := now.Sub(ts)
+		if lag < 0 {
+			lag = 0
+		}
+		lags[p] = lag
+	}
+	return lags
+}
+
+// watch updates the lag of the partitions every [lagInterval]. It blocks
+// the calling goroutine until the provided context is cancelled.
+func (l *partitionLag) watch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(lagInterval):
+		}
+		l.update(time.Now())
+	}
+}
+
+// partitionLabel returns the value of the partition label of the lag
+// gauge.
+func partitionLabel(partition int32) string {
+	return strconv.FormatInt(int64(partition), 10)
+}
+
+// lagProcessor is a [stream.Processor] that records the timestamp of the
+// messages processed without error in a [partitionLag].
+type lagProcessor struct {
+	proc stream.Processor
+	lag  *partitionLag
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p lagProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if err := h(msg); err != nil {
+			return err
+		}
+		p.lag.record(msg.Position.Partition, msg.Timestamp)
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestLagProcessor(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	msgs := []stream.Message{
+		{Key: []byte("team-1/asset-1"), Timestamp: t0},
+		{Key: []byte("team-1/asset-2"), Timestamp: t0.Add(2 * time.Minute)},
+		{Key: []byte("team-1/asset-3"), Timestamp: t0.Add(time.Minute)},
+		{Key: []byte("team-1/asset-4")},
+		{Key: []byte("team-1/asset-5"), Timestamp: t0.Add(5 * time.Minute)},
+	}
+
+	lag := newPartitionLag(0)
+	proc := lagProcessor{streamtest.NewMockProcessor(msgs), lag}
+
+	errHandler := errors.New("handler error")
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+		if string(msg.Key) == "team-1/asset-5" {
+			return errHandler
+		}
+		return nil
+	})
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := lag.update(t0.Add(10 * time.Minute))
+	want := map[int32]time.Duration{0: 8 * time.Minute}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("lag mismatch (-want +got):\n%v", diff)
+	}
+	if v := partitionLagSeconds.Value("0"); v != 480 {
+		t.Errorf("unexpected gauge value: got: %v, want: 480", v)
+	}
+}
+
+func TestPartitionLag(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lag := newPartitionLag(time.Minute)
+	lag.record(1, t0)
+	lag.record(2, t0.Add(30*time.Second))
+
+	tests := []struct {
+		name      string
+		events    func()
+		now       time.Time
+		wantLags  map[int32]time.Duration
+		wantStale map[int32]bool
+	}{
+		{
+			name:      "fresh",
+			events:    func() {},
+			now:       t0.Add(time.Minute),
+			wantLags:  map[int32]time.Duration{1: time.Minute, 2: 30 * time.Second},
+			wantStale: map[int32]bool{1: false, 2: false},
+		},
+		{
+			name:      "stale",
+			events:    func() {},
+			now:       t0.Add(2 * time.Minute),
+			wantLags:  map[int32]time.Duration{1: 2 * time.Minute, 2: 90 * time.Second},
+			wantStale: map[int32]bool{1: true, 2: true},
+		},
+		{
+			name: "caught up",
+			events: func() {
+				lag.record(1, t0.Add(2*time.Minute))
+			},
+			now:       t0.Add(2 * time.Minute),
+			wantLags:  map[int32]time.Duration{1: 0, 2: 90 * time.Second},
+			wantStale: map[int32]bool{1: false, 2: true},
+		},
+		{
+			name: "revoked",
+			events: func() {
+				lag.revoked([]int32{2}, false)
+			},
+			now:       t0.Add(2 * time.Minute),
+			wantLags:  map[int32]time.Duration{1: 0},
+			wantStale: map[int32]bool{1: false},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.events()
+
+		got := lag.update(tt.now)
+		if diff := cmp.Diff(tt.wantLags, got); diff != "" {
+			t.Errorf("%v: lag mismatch (-want +got):\n%v", tt.name, diff)
+		}
+		if diff := cmp.Diff(tt.wantStale, lag.stale); diff != "" {
+			t.Errorf("%v: stale partitions mismatch (-want +got):\n%v", tt.name, diff)
+		}
+	}
+
+	if v := partitionLagSeconds.Value("2"); v != 0 {
+		t.Errorf("unexpected gauge value of revoked partition: %v", v)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// States of the kafka consumer reported by [consumerState].
+const (
+	consumerStarting   = "starting"
+	consumerSubscribed = "subscribed"
+	consumerAssigned   = "assigned"
+	consumerConsuming  = "consuming"
+	consumerFailed     = "failed"
+	consumerClosed     = "closed"
+)
+
+// kafkaDownWindow is the period after a transport or authentication event
+// of the kafka client during which the kafka cluster is considered down.
+const kafkaDownWindow = time.Minute
+
+// consumerStatus is the status of the kafka consumer returned by the
+// readiness endpoint of the admin API.
+type consumerStatus struct {
+	State      string  `json:"state"`
+	Ready      bool    `json:"ready"`
+	Partitions []int32 `json:"partitions"`
+	Error      string  `json:"error,omitempty"`
+	KafkaError string  `json:"kafka_error,omitempty"`
+}
+
+// consumerState tracks the state of the kafka consumer using the lifecycle
+// callbacks of the processor, so readiness, metrics and logs do not depend
+// on inferring the state from the logs of the kafka client. The errors of
+// the kafka cluster are tracked apart from the errors of the processor, so
+// "kafka is down" can be distinguished from "the handler failed". It is
+// safe for concurrent use.
+type consumerState struct {
+	now func() time.Time
+
+	mu         sync.Mutex
+	state      string
+	partitions map[int32]bool
+	err        error
+	kafkaErr   error
+	kafkaErrAt time.Time
+}
+
+// newConsumerState returns a [consumerState] in the starting state.
+func newConsumerState() *consumerState {
+	return &consumerState{
+		now:        time.Now,
+		state:      consumerStarting,
+		partitions: make(map[int32]bool),
+	}
+}
+
+// lifecycle returns the callbacks that update the state of the consumer.
+func (cs *consumerState) lifecycle() kafka.Lifecycle {
+	return kafka.Lifecycle{
+		OnSubscribe:    cs.subscribed,
+		OnAssign:       cs.assigned,
+		OnRevoke:       cs.revoked,
+		OnFirstMessage: cs.firstMessage,
+		OnError:        cs.failed,
+		OnClose:        cs.closed,
+		OnEvent:        cs.kafkaEvent,
+	}
+}
+
+func (cs *consumerState) subscribed(topic string) {
+	consumerEventsTotal.Inc("subscribe")
+	log.Info.Printf("graph-vulcan-assets: subscribed to topic %v", topic)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.state = consumerSubscribed
+	cs.err = nil
+}
+
+func (cs *consumerState) assigned(partitions []int32) {
+	consumerEventsTotal.Inc("assign")
+	log.Info.Printf("graph-vulcan-assets: partitions assigned: %v", partitions)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for _, p := range partitions {
+		cs.partitions[p] = true
+	}
+	cs.recovered()
+	if cs.state == consumerSubscribed {
+		cs.state = consumerAssigned
+	}
+}
+
+func (cs *consumerState) revoked(partitions []int32, lost bool) {
+	if lost {
+		consumerEventsTotal.Inc("lose")
+		log.Error.Printf("graph-vulcan-assets: partitions lost: %v", partitions)
+	} else {
+		consumerEventsTotal.Inc("revoke")
+		log.Info.Printf("graph-vulcan-assets: partitions revoked: %v", partitions)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for _, p := range partitions {
+		delete(cs.partitions, p)
+	}
+}
+
+func (cs *consumerState) firstMessage(pos stream.Position) {
+	consumerEventsTotal.Inc("first_message")
+	log.Info.Printf("graph-vulcan-assets: first message received at %v", pos)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.state = consumerConsuming
+}
+
+func (cs *consumerState) failed(err error) {
+	consumerEventsTotal.Inc("error")
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.state = consumerFailed
+	cs.err = err
+}
+
+func (cs *consumerState) kafkaEvent(ev kafka.Event) {
+	kafkaEventsTotal.Inc(string(ev.Kind))
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	switch ev.Kind {
+	case kafka.EventTransport, kafka.EventAuth, kafka.EventFatal:
+		if cs.kafkaErr == nil {
+			log.Error.Printf("graph-vulcan-assets: kafka is down: %v", ev.Err)
+		}
+		cs.kafkaErr = ev.Err
+		cs.kafkaErrAt = cs.now()
+	case kafka.EventPartitionEOF:
+		// The end of a partition can only be reached if the brokers
+		// are reachable.
+		cs.recovered()
+	default:
+		log.Error.Printf("graph-vulcan-assets: kafka error: %v", ev.Err)
+	}
+}
+
+// recovered clears the error of the kafka cluster. It must be called with
+// cs.mu held.
+func (cs *consumerState) recovered() {
+	if cs.kafkaErr != nil {
+		log.Info.Printf("graph-vulcan-assets: kafka is up")
+	}
+	cs.kafkaErr = nil
+	cs.kafkaErrAt = time.Time{}
+}
+
+func (cs *consumerState) closed() {
+	consumerEventsTotal.Inc("close")
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.state = consumerClosed
+	cs.partitions = make(map[int32]bool)
+}
+
+// status returns the current status of the consumer. The consumer is ready
+// once it has joined the consumer group, even if no partition is assigned
+// to it, and until it fails or it is closed. It is not ready either while
+// the kafka cluster is considered down, that is, during [kafkaDownWindow]
+// after a transport or authentication event.
+func (cs *consumerState) status() consumerStatus {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.kafkaErr != nil && cs.now().Sub(cs.kafkaErrAt) >= kafkaDownWindow {
+		cs.recovered()
+	}
+
+	st := consumerStatus{
+		State:      cs.state,
+		Ready:      cs.state == consumerAssigned || cs.state == consumerConsuming,
+		Partitions: make([]int32, 0, len(cs.partitions)),
+	}
+	for p := range cs.partitions {
+		st.Partitions = append(st.Partitions, p)
+	}
+	sort.Slice(st.Partitions, func(i, j int) bool { return st.Partitions[i] < st.Partitions[j] })
+	if cs.err != nil {
+		st.Error = cs.err.Error()
+	}
+	if cs.kafkaErr != nil {
+		st.Ready = false
+		st.KafkaError = cs.kafkaErr.Error()
+	}
+	return st
+}
+
+// ServeHTTP implements the readiness endpoint of the admin API. It responds
+// with the status of the consumer and, if it is not ready, with the status
+// code 503.
+func (cs *consumerState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := cs.status()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !st.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(st)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+func TestConsumerState(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     func(cs *consumerState)
+		wantStatus consumerStatus
+	}{
+		{
+			name:   "starting",
+			events: func(cs *consumerState) {},
+			wantStatus: consumerStatus{
+				State:      consumerStarting,
+				Partitions: []int32{},
+			},
+		},
+		{
+			name: "subscribed",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+			},
+			wantStatus: consumerStatus{
+				State:      consumerSubscribed,
+				Partitions: []int32{},
+			},
+		},
+		{
+			name: "assigned without partitions",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned(nil)
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Ready:      true,
+				Partitions: []int32{},
+			},
+		},
+		{
+			name: "consuming",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{2, 0, 1})
+				cs.revoked([]int32{1}, false)
+				cs.firstMessage(stream.Position{Topic: "assets", Partition: 0, Offset: 10})
+			},
+			wantStatus: consumerStatus{
+				State:      consumerConsuming,
+				Ready:      true,
+				Partitions: []int32{0, 2},
+			},
+		},
+		{
+			name: "lost partitions",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0, 1})
+				cs.revoked([]int32{0, 1}, true)
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Ready:      true,
+				Partitions: []int32{},
+			},
+		},
+		{
+			name: "failed",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.failed(errors.New("error reading message"))
+			},
+			wantStatus: consumerStatus{
+				State:      consumerFailed,
+				Partitions: []int32{0},
+				Error:      "error reading message",
+			},
+		},
+		{
+			name: "kafka down",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventTransport, Err: errors.New("all brokers down")})
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Partitions: []int32{0},
+				KafkaError: "all brokers down",
+			},
+		},
+		{
+			name: "kafka auth error",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventAuth, Err: errors.New("authentication failed")})
+			},
+			wantStatus: consumerStatus{
+				State:      consumerSubscribed,
+				Partitions: []int32{},
+				KafkaError: "authentication failed",
+			},
+		},
+		{
+			name: "kafka up after partition eof",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventTransport, Err: errors.New("all brokers down")})
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventPartitionEOF, Position: stream.Position{Topic: "assets", Partition: 0, Offset: 10}})
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Ready:      true,
+				Partitions: []int32{0},
+			},
+		},
+		{
+			name: "kafka up after window",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventTransport, Err: errors.New("all brokers down")})
+				now := cs.now()
+				cs.now = func() time.Time { return now.Add(kafkaDownWindow) }
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Ready:      true,
+				Partitions: []int32{0},
+			},
+		},
+		{
+			name: "kafka generic error",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.kafkaEvent(kafka.Event{Kind: kafka.EventError, Err: errors.New("unknown error")})
+			},
+			wantStatus: consumerStatus{
+				State:      consumerAssigned,
+				Ready:      true,
+				Partitions: []int32{0},
+			},
+		},
+		{
+			name: "closed",
+			events: func(cs *consumerState) {
+				cs.subscribed("assets")
+				cs.assigned([]int32{0})
+				cs.closed()
+			},
+			wantStatus: consumerStatus{
+				State:      consumerClosed,
+				Partitions: []int32{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := newConsumerState()
+			now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			cs.now = func() time.Time { return now }
+			tt.events(cs)
+
+			if diff := cmp.Diff(tt.wantStatus, cs.status()); diff != "" {
+				t.Errorf("status mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestConsumerStateServeHTTP(t *testing.T) {
+	cs := newConsumerState()
+	mux := adminMux(newMaintenance(""), nil, cs, nil, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status code before subscribing: %v", rec.Code)
+	}
+
+	cs.subscribed("assets")
+	cs.assigned([]int32{0})
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code after assignment: %v", rec.Code)
+	}
+
+	var got consumerStatus
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("error decoding body: %v", err)
+	}
+	want := consumerStatus{State: consumerAssigned, Ready: true, Partitions: []int32{0}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("status mismatch (-want +got):\n%v", diff)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ready", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status code with invalid method: %v", rec.Code)
+	}
+}
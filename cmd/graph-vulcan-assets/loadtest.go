@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Metadata keys added to the messages produced by the loadtest command.
+const (
+	loadtestRunKey    = "loadtest-run"
+	loadtestSentAtKey = "loadtest-sent-at"
+)
+
+// runLoadtest implements the loadtest command. It publishes synthetic assets
+// to a test topic at a fixed rate, processes them against the configured
+// Asset Inventory and reports the end-to-end throughput and latency.
+func runLoadtest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	topic := fs.String("topic", vulcan.AssetsEntityName+"-loadtest", "topic used to publish the synthetic assets")
+	rate := fs.Int("rate", 100, "number of messages published per second")
+	duration := fs.Duration("duration", time.Minute, "time spent publishing messages")
+	drainTimeout := fs.Duration("drain-timeout", time.Minute, "maximum time to wait for the published messages to be processed")
+	teams := fs.Int("teams", 10, "number of distinct teams")
+	assets := fs.Int("assets", 1000, "number of distinct assets")
+	tombstoneRatio := fs.Float64("tombstone-ratio", 0.05, "probability of a message being a tombstone")
+	seed := fs.Int64("seed", 1, "seed of the synthetic asset generator")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *rate <= 0 {
+		return errors.New("-rate must be greater than zero")
+	}
+	nmsgs := int(float64(*rate) * duration.Seconds())
+	if nmsgs <= 0 {
+		return errors.New("-rate and -duration must produce at least one message")
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	icli, err := newInventoryClient(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating asset inventory client: %w", err)
+	}
+
+	kcfg := kafkaConfig(cfg)
+	kcfg["group.id"] = throwawayGroupID(cfg, "loadtest")
+
+	proc, err := kafka.NewAloProcessor(kcfg)
+	if err != nil {
+		return fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	prod, err := kafka.NewProducer(producerConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("error creating kafka producer: %w", err)
+	}
+	defer prod.Close()
+
+	msgs := streamtest.Generate(streamtest.GeneratorConfig{
+		Seed:            *seed,
+		Messages:        nmsgs,
+		Assets:          *assets,
+		Teams:           *teams,
+		MaxAnnotations:  3,
+		AWSAccountKey:   cfg.AWSAccountAnnotationKeys[0],
+		AWSAccountRatio: 0.2,
+		TombstoneRatio:  *tombstoneRatio,
+	})
+
+	lt := loadtest{
+		runID: strconv.FormatInt(time.Now().UnixNano(), 16),
+		topic: *topic,
+		stats: &loadStats{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumerErr := make(chan error, 1)
+	go func() {
+		consumerErr <- vulcan.NewClient(lt.processor(proc)).ProcessAssets(ctx, assetHandler(icli, engineConfig(cfg), cfg))
+	}()
+
+	log.Info.Printf("graph-vulcan-assets: loadtest: publishing %v messages to %q (run=%v)", len(msgs), lt.topic, lt.runID)
+
+	start := time.Now()
+	if err := lt.publish(ctx, prod, msgs, *rate); err != nil {
+		return fmt.Errorf("error publishing messages: %w", err)
+	}
+
+	dctx, dcancel := context.WithTimeout(ctx, *drainTimeout)
+	defer dcancel()
+
+	if err := prod.Flush(dctx); err != nil {
+		return fmt.Errorf("error flushing messages: %w", err)
+	}
+
+	select {
+	case <-lt.stats.wait(dctx, len(msgs)):
+	case err := <-consumerErr:
+		return fmt.Errorf("error processing messages: %w", err)
+	}
+	elapsed := time.Since(start)
+
+	cancel()
+	if err := <-consumerErr; err != nil {
+		return fmt.Errorf("error processing messages: %w", err)
+	}
+
+	return lt.stats.report(len(msgs), elapsed).write(os.Stdout)
+}
+
+// loadtest contains the state of a load test run.
+type loadtest struct {
+	runID string
+	topic string
+	stats *loadStats
+}
+
+// publish sends msgs to the topic of the load test at the provided rate
+// (messages per second). Every message is tagged with the run ID and the
+// time it is sent.
+func (lt loadtest) publish(ctx context.Context, prod kafka.Producer, msgs []stream.Message, rate int) error {
+	start := time.Now()
+	for i, msg := range msgs {
+		// Wait until the i-th message is due.
+		due := start.Add(time.Duration(i) * time.Second / time.Duration(rate))
+		if d := time.Until(due); d > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+			}
+		}
+
+		msg.Metadata = append(msg.Metadata[:len(msg.Metadata):len(msg.Metadata)],
+			stream.MetadataEntry{Key: []byte(loadtestRunKey), Value: []byte(lt.runID)},
+			stream.MetadataEntry{Key: []byte(loadtestSentAtKey), Value: []byte(time.Now().Format(time.RFC3339Nano))},
+		)
+		if err := prod.Produce(lt.topic, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processor returns a [stream.Processor] that processes the messages of the
+// load test run from the topic of the load test using proc. The messages of
+// other runs are ignored. Errors returned by the handler are recorded
+// instead of stopping the processing.
+func (lt loadtest) processor(proc stream.Processor) stream.Processor {
+	return loadtestProcessor{lt: lt, proc: proc}
+}
+
+type loadtestProcessor struct {
+	lt   loadtest
+	proc stream.Processor
+}
+
+func (ltp loadtestProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return ltp.proc.Process(ctx, ltp.lt.topic, func(msg stream.Message) error {
+		var (
+			runID  string
+			sentAt time.Time
+		)
+		for _, e := range msg.Metadata {
+			switch string(e.Key) {
+			case loadtestRunKey:
+				runID = string(e.Value)
+			case loadtestSentAtKey:
+				sentAt, _ = time.Parse(time.RFC3339Nano, string(e.Value))
+			}
+		}
+		if runID != ltp.lt.runID || sentAt.IsZero() {
+			return nil
+		}
+
+		err := h(msg)
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: loadtest: error processing message: %v", err)
+		}
+		ltp.lt.stats.record(time.Since(sentAt), err)
+		return nil
+	})
+}
+
+// loadStats contains the statistics of a load test run. It is safe for
+// concurrent use.
+type loadStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+}
+
+// record records the result of processing a message.
+func (ls *loadStats) record(latency time.Duration, err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.latencies = append(ls.latencies, latency)
+	if err != nil {
+		ls.errors++
+	}
+}
+
+// processed returns the number of processed messages.
+func (ls *loadStats) processed() int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	return len(ls.latencies)
+}
+
+// wait returns a channel that is closed when n messages have been processed
+// or ctx is done.
+func (ls *loadStats) wait(ctx context.Context, n int) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for ls.processed() < n {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+// report returns the report of the load test. published is the number of
+// published messages and elapsed the duration of the test.
+func (ls *loadStats) report(published int, elapsed time.Duration) loadReport {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	latencies := append([]time.Duration(nil), ls.latencies...)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	r := loadReport{
+		Published: published,
+		Processed: len(latencies),
+		Errors:    ls.errors,
+		Elapsed:   elapsed,
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Processed) / elapsed.Seconds()
+	}
+	if len(latencies) > 0 {
+		r.P50 = percentile(latencies, 50)
+		r.P95 = percentile(latencies, 95)
+		r.P99 = percentile(latencies, 99)
+		r.Max = latencies[len(latencies)-1]
+	}
+	return r
+}
+
+// percentile returns the p-th percentile of the provided sorted durations
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// loadReport is the result of a load test.
+type loadReport struct {
+	Published  int
+	Processed  int
+	Errors     int
+	Elapsed    time.Duration
+	Throughput float64
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// write writes a human-readable version of the report to w.
+func (r loadReport) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `published:  %v
+processed:  %v
+errors:     %v
+elapsed:    %v
+throughput: %.2f msg/s
+latency:    p50=%v p95=%v p99=%v max=%v
+`, r.Published, r.Processed, r.Errors, r.Elapsed.Round(time.Millisecond), r.Throughput,
+		r.P50.Round(time.Millisecond), r.P95.Round(time.Millisecond), r.P99.Round(time.Millisecond), r.Max.Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestLoadtestProcessor(t *testing.T) {
+	sentAt := []byte(time.Now().Format(time.RFC3339Nano))
+	tag := func(msg stream.Message, runID string) stream.Message {
+		msg.Metadata = append(msg.Metadata,
+			stream.MetadataEntry{Key: []byte(loadtestRunKey), Value: []byte(runID)},
+			stream.MetadataEntry{Key: []byte(loadtestSentAtKey), Value: sentAt},
+		)
+		return msg
+	}
+
+	msgs := []stream.Message{
+		tag(stream.Message{Key: []byte("key0")}, "run"),
+		tag(stream.Message{Key: []byte("key1")}, "other"),
+		{Key: []byte("key2")},
+		tag(stream.Message{Key: []byte("key3")}, "run"),
+	}
+
+	lt := loadtest{runID: "run", topic: "topic", stats: &loadStats{}}
+
+	var got []string
+	err := lt.processor(streamtest.NewMockProcessor(msgs)).Process(context.Background(), "entity", func(msg stream.Message) error {
+		got = append(got, string(msg.Key))
+		if string(msg.Key) == "key3" {
+			return errors.New("error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"key0", "key3"}, got); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+
+	r := lt.stats.report(2, time.Second)
+	if r.Processed != 2 || r.Errors != 1 {
+		t.Errorf("unexpected stats: processed=%v errors=%v", r.Processed, r.Errors)
+	}
+}
+
+func TestLoadStatsReport(t *testing.T) {
+	var ls loadStats
+	for i := 100; i > 0; i-- {
+		ls.record(time.Duration(i)*time.Millisecond, nil)
+	}
+
+	want := loadReport{
+		Published:  100,
+		Processed:  100,
+		Errors:     0,
+		Elapsed:    10 * time.Second,
+		Throughput: 10,
+		P50:        50 * time.Millisecond,
+		P95:        95 * time.Millisecond,
+		P99:        99 * time.Millisecond,
+		Max:        100 * time.Millisecond,
+	}
+
+	got := ls.report(100, 10*time.Second)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -5,432 +5,686 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"regexp"
+	"strings"
 	"time"
 
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/cron"
 	"github.com/adevinta/graph-vulcan-assets/inventory"
 	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/metrics"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/stream"
 	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
 	"github.com/adevinta/graph-vulcan-assets/vulcan"
+	"github.com/adevinta/graph-vulcan-assets/wal"
 )
 
 // TODO(rm): The current implementation requires a lot of requests against the
 // Asset Inventory API every time an asset is updated.
 
-const (
-	defaultLogLevel      = "info"
-	defaultRetryDuration = 5 * time.Second
-	defaultKafkaGroupID  = "graph-vulcan-assets"
-)
+// commands contains the subcommands supported by graph-vulcan-assets. If no
+// subcommand is specified, the consumer is run.
+var commands = map[string]func(args []string) error{
+	"capture":        runCapture,
+	"dlq":            runDLQ,
+	"dump":           runDump,
+	"expire-derived": runExpireDerived,
+	"loadtest":       runLoadtest,
+	"reconcile":      runReconcile,
+	"replay":         runReplay,
+	"test-env":       runTestEnv,
+}
 
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		name := os.Args[1]
+		cmd, ok := commands[name]
+		if !ok {
+			log.Fatalf("graph-vulcan-assets: unknown command %q", name)
+		}
+		if err := cmd(os.Args[2:]); err != nil {
+			log.Fatalf("graph-vulcan-assets: %v: %v", name, err)
+		}
+		return
+	}
+
+	opts, err := parseRunFlags(os.Args[1:])
+	if err != nil {
+		log.Fatalf("graph-vulcan-assets: %v", err)
+	}
+
 	cfg, err := readConfig()
 	if err != nil {
 		log.Fatalf("graph-vulcan-assets: error reading config: %v", err)
 	}
 
-	if err := run(context.Background(), cfg); err != nil {
+	if err := run(context.Background(), cfg, opts); err != nil {
 		log.Fatalf("graph-vulcan-assets: %v", err)
 	}
 }
 
-// run is invoked by main and does the actual work.
-func run(ctx context.Context, cfg config) error {
-	if err := log.SetLevel(cfg.LogLevel); err != nil {
-		return fmt.Errorf("error setting log level: %w", err)
-	}
+// runOptions are the command line options of the consumer.
+type runOptions struct {
+	// fromBeginning enables the backfill mode. Before consuming the
+	// stream, the whole assets topic is processed from the earliest
+	// offsets.
+	fromBeginning bool
+
+	// catchUp enables the catch-up mode. The messages are processed
+	// with relaxed batching until the lag of the consumer group falls
+	// below CATCHUP_LAG_THRESHOLD.
+	catchUp bool
+}
 
-	kcfg := map[string]any{
-		"bootstrap.servers": cfg.KafkaBootstrapServers,
-		"group.id":          cfg.KafkaGroupID,
-		"auto.offset.reset": "earliest",
-	}
+// parseRunFlags parses the command line arguments of the consumer.
+func parseRunFlags(args []string) (runOptions, error) {
+	var opts runOptions
 
-	if cfg.KafkaUsername != "" && cfg.KafkaPassword != "" {
-		kcfg["security.protocol"] = "sasl_ssl"
-		kcfg["sasl.mechanisms"] = "SCRAM-SHA-256"
-		kcfg["sasl.username"] = cfg.KafkaUsername
-		kcfg["sasl.password"] = cfg.KafkaPassword
+	fs := flag.NewFlagSet("graph-vulcan-assets", flag.ContinueOnError)
+	fs.BoolVar(&opts.fromBeginning, "from-beginning", false, "process the whole assets topic before consuming the stream")
+	fs.BoolVar(&opts.catchUp, "catch-up", false, "process the stream with relaxed batching until the lag falls below CATCHUP_LAG_THRESHOLD")
+	if err := fs.Parse(args); err != nil {
+		return runOptions{}, err
 	}
-
-	proc, err := kafka.NewAloProcessor(kcfg)
-	if err != nil {
-		return fmt.Errorf("error creating kafka processor: %w", err)
+	if fs.NArg() > 0 {
+		return runOptions{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
 	}
-	defer proc.Close()
 
-	vcli := vulcan.NewClient(proc)
+	return opts, nil
+}
 
-	icli, err := inventory.NewClient(cfg.InventoryEndpoint, cfg.InventoryInsecureSkipVerify)
-	if err != nil {
-		return fmt.Errorf("error creating asset inventory client: %w", err)
+// run is invoked by main and does the actual work.
+func run(ctx context.Context, cfg config, opts runOptions) error {
+	if err := setupLog(cfg); err != nil {
+		return err
 	}
 
-	for {
-		log.Info.Println("graph-vulcan-assets: processing assets")
-
-		select {
-		case <-ctx.Done():
-			log.Info.Println("graph-vulcan-assets: context is done")
-			return nil
-		default:
-		}
-
-		if err := vcli.ProcessAssets(ctx, assetHandler(icli, cfg)); err != nil {
-			err = fmt.Errorf("error processing assets: %w", err)
-			if cfg.RetryDuration == 0 {
-				return err
-			}
-			log.Error.Printf("graph-vulcan-assets: %v", err)
-		}
-
-		log.Info.Printf("graph-vulcan-assets: retrying in %v", cfg.RetryDuration)
-		time.Sleep(cfg.RetryDuration)
+	metrics.SetLimits(metrics.Limits{
+		DisabledLabels: cfg.MetricsDisabledLabels,
+		MaxLabelValues: cfg.MetricsMaxLabelValues,
+	})
+	if err := metrics.Err(); err != nil {
+		return fmt.Errorf("invalid metrics: %w", err)
 	}
-}
 
-// assetHandler processes asset events coming from a stream.
-func assetHandler(icli inventory.Client, cfg config) vulcan.AssetHandler {
-	return func(payload vulcan.AssetPayload, isNil bool) error {
-		log.Debug.Printf("graph-vulcan-assets: payload=%#v isNil=%v", payload, isNil)
+	logStartupBanner(cfg, opts)
 
-		if isNil {
-			if err := expireAsset(icli, payload); err != nil {
-				return fmt.Errorf("could not expire asset: %w", err)
-			}
-			return nil
-		}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		if err := refreshAsset(icli, payload, cfg); err != nil {
-			return fmt.Errorf("could not refresh asset: %w", err)
-		}
-
-		return nil
+	var kopts []kafka.AloOption
+	if cfg.KafkaAssignmentStrategy != "" {
+		kopts = append(kopts, kafka.WithAssignmentStrategy(cfg.KafkaAssignmentStrategy))
+	}
+	kopts = append(kopts, kafka.WithSubscribeBackoff(kafka.Backoff{
+		Attempts: cfg.KafkaSubscribeRetryAttempts,
+		Initial:  cfg.KafkaSubscribeRetryBackoff,
+		Max:      maxSubscribeRetryBackoff,
+		Notify: func(err error, attempt int, wait time.Duration) {
+			log.Error.Printf("graph-vulcan-assets: kafka subscription failed, retrying in %v (attempt %v/%v): %v",
+				wait, attempt, cfg.KafkaSubscribeRetryAttempts, err)
+		},
+	}))
+	if cfg.InventoryBatchSize > 1 {
+		kopts = append(kopts, kafka.WithBatching(inventoryBatching(cfg)))
 	}
-}
 
-// refreshAsset is called when an asset is created or updated. It takes care of
-// refreshing its time attributes, as well as its parent-of and owns relations.
-func refreshAsset(icli inventory.Client, payload vulcan.AssetPayload, cfg config) error {
-	asset, err := upsertAsset(icli, payload)
-	if err != nil {
-		return fmt.Errorf("could not upsert asset: %w", err)
+	cs := newConsumerState()
+	lag := newPartitionLag(cfg.FreshnessThreshold)
+	lifecycle := cs.lifecycle()
+	lifecycle.OnRevoke = func(partitions []int32, lost bool) {
+		cs.revoked(partitions, lost)
+		lag.revoked(partitions, lost)
 	}
+	kopts = append(kopts, kafka.WithLifecycle(lifecycle))
+
+	assetsync.SetStatusSource(func() assetsync.SyncStatus {
+		return syncStatus(cs, lag, time.Now())
+	})
+	defer assetsync.SetStatusSource(nil)
 
-	team, err := upsertTeam(icli, payload)
+	proc, err := kafka.NewAloProcessor(kafkaConfig(cfg), kopts...)
 	if err != nil {
-		return fmt.Errorf("could not upsert team: %w", err)
+		return fmt.Errorf("error creating kafka processor: %w", err)
 	}
+	defer proc.Close()
 
-	if err := setOwner(icli, asset, team); err != nil {
-		return fmt.Errorf("could not set owner: %w", err)
+	maint := newMaintenance(cfg.MaintenanceFile)
+	capture, iopts := newInventoryCapture(cfg)
+	chaos := newChaos(cfg)
+	if chaos != nil {
+		log.Info.Printf("graph-vulcan-assets: chaos mode enabled, injecting faults (inventory errors=%v%% inventory latency=%v handler panics=%v%%)",
+			cfg.ChaosInventoryErrorPercent, cfg.ChaosInventoryLatency, cfg.ChaosHandlerPanicPercent)
 	}
+	iopts = append(iopts, chaos.clientOptions()...)
+	volume := newEventVolume(cfg.TopAssetsMaxAssets)
 
-	for _, a := range payload.Annotations {
-		if a.Key != cfg.AWSAccountAnnotationKey {
-			continue
-		}
-		if err := setAWSAccount(icli, asset, a.Value); err != nil {
-			return fmt.Errorf("could not set AWS account: %w", err)
+	if cfg.AdminAddr != "" {
+		if err := serveAdmin(ctx, cfg.AdminAddr, adminMux(maint, proc, cs, capture, volume)); err != nil {
+			return fmt.Errorf("error starting admin server: %w", err)
 		}
 	}
 
-	return nil
-}
+	go maint.watch(ctx, proc)
+	go lag.watch(ctx)
 
-// upsertAsset creates an asset if it does not exist. If it exists, it updates
-// its time attributes. It returns the created or updated asset.
-func upsertAsset(icli inventory.Client, payload vulcan.AssetPayload) (inventory.AssetResp, error) {
-	assets, err := icli.Assets(string(payload.AssetType), payload.Identifier, time.Time{}, inventory.Pagination{})
+	reporter, err := newDailyReporter(cfg)
 	if err != nil {
-		return inventory.AssetResp{}, fmt.Errorf("could not get assets: %w", err)
+		return fmt.Errorf("error creating daily reporter: %w", err)
+	}
+	if reporter != nil {
+		// The schedule is validated with the configuration.
+		sched, _ := cron.Parse(cfg.DailyReportSchedule)
+		go reporter.run(ctx, dailyActivity, sched)
 	}
 
-	switch len(assets) {
-	case 1:
-		asset, err := icli.UpdateAsset(assets[0].ID, string(payload.AssetType), payload.Identifier, time.Now(), inventory.Unexpired)
-		if err != nil {
-			return inventory.AssetResp{}, fmt.Errorf("could not update asset: %w", err)
-		}
-		return asset, nil
-	case 0:
-		asset, err := icli.CreateAsset(string(payload.AssetType), payload.Identifier, time.Now(), inventory.Unexpired)
-		if err != nil {
-			return inventory.AssetResp{}, fmt.Errorf("could not create asset: %w", err)
+	var dlq kafka.Producer
+	if cfg.OversizedMessagePolicy == oversizedPolicyDLQ || cfg.UnknownAssetTypePolicy == unknownTypePolicyDLQ || cfg.PayloadSchemaPolicy == schemaPolicyDLQ {
+		if dlq, err = kafka.NewProducer(producerConfig(cfg)); err != nil {
+			return fmt.Errorf("error creating dead letter queue producer: %w", err)
 		}
-		return asset, nil
+		defer dlq.Close()
 	}
 
-	return inventory.AssetResp{}, errors.New("duplicated asset")
-}
-
-// upsertTeam creates a team if it does not exist. If it exists, it updates its
-// name. It returns the created or updated team.
-func upsertTeam(icli inventory.Client, payload vulcan.AssetPayload) (inventory.TeamResp, error) {
-	vteam := payload.Team
-
-	teams, err := icli.Teams(vteam.ID, inventory.Pagination{})
+	sink, err := newSampleSink(cfg)
 	if err != nil {
-		return inventory.TeamResp{}, fmt.Errorf("could not get teams: %w", err)
+		return fmt.Errorf("error creating sample sink: %w", err)
 	}
-
-	switch len(teams) {
-	case 1:
-		team, err := icli.UpdateTeam(teams[0].ID, vteam.ID, vteam.Name)
-		if err != nil {
-			return inventory.TeamResp{}, fmt.Errorf("could not update team: %w", err)
-		}
-		return team, nil
-	case 0:
-		team, err := icli.CreateTeam(vteam.ID, vteam.Name)
-		if err != nil {
-			return inventory.TeamResp{}, fmt.Errorf("could not create team: %w", err)
-		}
-		return team, nil
-	default:
-		return inventory.TeamResp{}, errors.New("duplicated team")
+	if sink != nil {
+		defer func() {
+			if err := sink.Close(); err != nil {
+				log.Error.Printf("graph-vulcan-assets: error closing sample sink: %v", err)
+			}
+		}()
 	}
-}
 
-// setOwner sets the owner of an assset. If the owns relation already exists,
-// the original [inventory.OwnsResp.StartTime] is used.
-func setOwner(icli inventory.Client, asset inventory.AssetResp, team inventory.TeamResp) error {
-	owners, err := icli.Owners(asset.ID, inventory.Pagination{})
+	arch, err := newArchiver(cfg)
 	if err != nil {
-		return fmt.Errorf("could not get owners: %w", err)
+		return fmt.Errorf("error creating archiver: %w", err)
+	}
+	if arch != nil {
+		// The archiver is stopped after the processing of the
+		// messages, so the last consumed messages are uploaded.
+		actx, acancel := context.WithCancel(context.Background())
+		adone := make(chan struct{})
+		go func() {
+			arch.run(actx)
+			close(adone)
+		}()
+		defer func() {
+			acancel()
+			<-adone
+		}()
+	}
+
+	sproc := newSamplingProcessor(newArchivingProcessor(proc, arch), cfg.SamplePercent, sink)
+	qproc := newQuarantineProcessor(versionProcessor{lagProcessor{sproc, lag}}, cfg.QuarantineKeys, cfg.QuarantineIdentifiers)
+	tproc := newAssetTypeProcessor(qproc, cfg.AllowedAssetTypes, cfg.UnknownAssetTypePolicy, cfg.DLQTopic, dlq)
+	lproc := stream.NewSizeLimitedProcessor(tproc, cfg.MaxMessageSize, oversizedHandler(ctx, cfg, dlq))
+	vcli := vulcan.NewClient(newSchemaProcessor(lproc, cfg.PayloadSchemaPolicy, cfg.DLQTopic, dlq))
+
+	icli, err := newInventoryClient(cfg, iopts...)
+	if err != nil {
+		return fmt.Errorf("error creating asset inventory client: %w", err)
 	}
 
-	startTime := time.Now()
-	for _, o := range owners {
-		if o.TeamID == team.ID {
-			startTime = o.StartTime
-			break
-		}
+	rt, err := readRouter(icli, cfg, iopts...)
+	if err != nil {
+		return fmt.Errorf("error reading routing table: %w", err)
 	}
 
-	if _, err := icli.UpsertOwner(asset.ID, team.ID, startTime, time.Time{}); err != nil {
-		return fmt.Errorf("could not upsert owner: %w", err)
+	checks := []preflightCheck{
+		{
+			name:  "kafka topic " + vulcan.AssetsEntityName,
+			check: func() error { return proc.CheckTopic(vulcan.AssetsEntityName) },
+		},
 	}
+	checkers := make(map[string]versionChecker)
+	for _, endpoint := range rt.endpoints() {
+		rcli := rt.clients[endpoint]
+		go watchTLS(ctx, rcli, cfg.InventoryTLSReloadInterval)
 
-	return nil
-}
-
-// setAWSAccount sets the parent AWS account of an assset. It takes care of
-// normalizing the AWS account ID, so it always has the long format
-// "arn:aws:iam::000000000000:root".
-func setAWSAccount(icli inventory.Client, asset inventory.AssetResp, awsAccount string) error {
-	normAWSAccount, err := normalizeAWSAccountID(awsAccount)
-	if err != nil {
-		return fmt.Errorf("could not normalize AWS account ID: %w", err)
-	}
-
-	payload := vulcan.AssetPayload{
-		Identifier: normAWSAccount,
-		AssetType:  vulcan.AssetType("AWSAccount"),
+		name := "asset inventory"
+		if endpoint != cfg.InventoryEndpoint {
+			name += " " + endpoint
+		}
+		checks = append(checks, preflightCheck{
+			name:  name,
+			check: rcli.Ping,
+		})
+		if cfg.InventoryVersionPolicy == inventoryVersionPolicyFail {
+			checks = append(checks, preflightCheck{
+				name:  name + " API version",
+				check: func() error { return checkInventoryVersion(rcli) },
+			})
+		}
+		checkers[endpoint] = rcli
 	}
-	assetAWSAccount, err := upsertAsset(icli, payload)
-	if err != nil {
-		return fmt.Errorf("could not upsert AWS account: %w", err)
+	if err := preflight(ctx, cfg.PreflightTimeout, checks); err != nil {
+		return err
 	}
 
-	if _, err := icli.UpsertParent(asset.ID, assetAWSAccount.ID, time.Now(), inventory.Unexpired); err != nil {
-		return fmt.Errorf("could not upsert parent: %w", err)
+	guard := newSchemaGuard(checkers, maint)
+	if cfg.InventoryVersionPolicy == inventoryVersionPolicyPause {
+		if endpoints := guard.check(); len(endpoints) > 0 {
+			log.Error.Printf("graph-vulcan-assets: starting paused, unsupported asset inventory API version: %v", endpoints)
+		}
+	}
+	if cfg.InventoryVersionCheckInterval > 0 {
+		go guard.watch(ctx, cfg.InventoryVersionCheckInterval)
 	}
 
-	return nil
-}
-
-var (
-	shortAWSAccountRe = regexp.MustCompile(`^[0-9]{12}$`)
-	longAWSAccountRe  = regexp.MustCompile(`^arn:aws:iam::[0-9]{12}:root$`)
-)
+	var (
+		resyncNow bool
+		processed checkpoint.Offsets
+	)
+	if cfg.CheckpointGremlinEndpoint != "" {
+		store, err := checkpoint.NewStore(cfg.CheckpointGremlinEndpoint)
+		if err != nil {
+			return fmt.Errorf("error creating checkpoint store: %w", err)
+		}
+		defer store.Close()
 
-// normalizeAWSAccountID normalizes the provided AWS account ID. The returned
-// ID will always follows the format "arn:aws:iam::000000000000:root".
-func normalizeAWSAccountID(id string) (string, error) {
-	if longAWSAccountRe.MatchString(id) {
-		return id, nil
-	}
+		// The offsets processed before the start bound the events
+		// checked against the persisted asset states.
+		if processed, err = store.Load(vulcan.AssetsEntityName); err != nil {
+			log.Error.Printf("graph-vulcan-assets: error loading checkpoint: %v", err)
+		}
 
-	if shortAWSAccountRe.MatchString(id) {
-		return fmt.Sprintf("arn:aws:iam::%v:root", id), nil
-	}
+		gaps, err := checkpointGaps(store, proc, vulcan.AssetsEntityName)
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: error checking gaps: %v", err)
+		} else if len(gaps) > 0 {
+			log.Info.Printf("graph-vulcan-assets: messages lost in partitions %v, reconciling", gaps)
+			resyncNow = true
+		}
 
-	return "", fmt.Errorf("invalid AWS account id format: %v", id)
-}
+		cpctx, cpcancel := context.WithCancel(ctx)
+		cpdone := make(chan struct{})
+		go func() {
+			newCheckpointer(store, proc, vulcan.AssetsEntityName).run(cpctx, cfg.CheckpointInterval)
+			close(cpdone)
+		}()
+		defer func() {
+			cpcancel()
+			<-cpdone
+		}()
+	}
+
+	h := countingHandler(retryHandler(ctx, chaos.handler(rt.handler(processed, cfg)), handlerRetryPolicy(cfg)))
+	if cfg.WALFile != "" {
+		w, pending, err := wal.Open(cfg.WALFile)
+		if err != nil {
+			return fmt.Errorf("error opening write-ahead log: %w", err)
+		}
+		defer w.Close()
 
-// expireAsset expires the provided asset, which means:
-//
-//   - The owns relation with the specific team is expired.
-//   - If all the owns relations are expired, the asset is expired.
-//   - If the asset is expired, all its parent-of relations are expired (both
-//     ingoing and outgoing).
-func expireAsset(icli inventory.Client, payload vulcan.AssetPayload) error {
-	assets, err := icli.Assets(string(payload.AssetType), payload.Identifier, time.Time{}, inventory.Pagination{})
-	if err != nil {
-		return fmt.Errorf("could not get assets: %w", err)
+		replayWAL(w, pending, h)
+		h = walHandler(w, h)
 	}
-
-	if len(assets) == 0 {
-		// The asset does not exist, so nothing needs to be done.
-		return nil
+	if cfg.HeartbeatFile != "" {
+		hb := newHeartbeat(cfg.HeartbeatFile)
+		defer func() {
+			if err := hb.close(); err != nil {
+				log.Error.Printf("graph-vulcan-assets: could not write heartbeat: %v", err)
+			}
+		}()
+		h = hb.handler(h)
 	}
-	if len(assets) > 1 {
-		return errors.New("duplicated asset")
+	if volume != nil {
+		h = volume.handler(h)
 	}
 
-	teams, err := icli.Teams(payload.Team.ID, inventory.Pagination{})
-	if err != nil {
-		return fmt.Errorf("could not get teams: %w", err)
+	var (
+		resyncSched cron.Schedule
+		nextResync  time.Time
+	)
+	if cfg.ResyncSchedule != "" {
+		if resyncSched, err = cron.Parse(cfg.ResyncSchedule); err != nil {
+			return fmt.Errorf("invalid resync schedule: %w", err)
+		}
+		nextResync = resyncSched.Next(time.Now())
 	}
 
-	if len(teams) == 0 {
-		// The team does not exist, so nothing needs to be done.
-		return nil
-	}
-	if len(teams) > 1 {
-		return errors.New("duplicated team")
+	if opts.fromBeginning {
+		// The backfill processes the whole topic with a throwaway
+		// consumer group, so, afterwards, the stream is consumed from
+		// the offsets of the configured consumer group. The events
+		// processed twice are idempotent.
+		log.Info.Println("graph-vulcan-assets: backfilling assets from the beginning of the topic")
+		if err := reconcile(ctx, cfg, h, ""); err != nil {
+			return fmt.Errorf("error backfilling assets: %w", err)
+		}
+		resyncNow = false
 	}
 
-	now := time.Now()
-
-	// Check if there is any active owns relation end expire owner.
-	owners, err := icli.Owners(assets[0].ID, inventory.Pagination{})
-	if err != nil {
-		return fmt.Errorf("error getting owners: %w", err)
+	if opts.catchUp {
+		go newCatchUp(proc, cfg).run(ctx)
 	}
 
-	var active bool
-	for _, o := range owners {
-		if o.TeamID != teams[0].ID {
-			if o.EndTime == nil {
-				active = true
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info.Println("graph-vulcan-assets: context is done")
+			return nil
+		default:
+		}
+
+		if resyncNow || (!nextResync.IsZero() && !time.Now().Before(nextResync)) {
+			if err := reconcile(ctx, cfg, h, ""); err != nil {
+				log.Error.Printf("graph-vulcan-assets: error reconciling assets: %v", err)
+			}
+			resyncNow = false
+			if !nextResync.IsZero() {
+				nextResync = resyncSched.Next(time.Now())
 			}
 			continue
 		}
 
-		if _, err := icli.UpsertOwner(assets[0].ID, teams[0].ID, o.StartTime, now); err != nil {
-			return fmt.Errorf("could not expire owner: %w", err)
+		log.Info.Println("graph-vulcan-assets: processing assets")
+
+		// Stream processing is interrupted when the next resync is
+		// due.
+		pctx, pcancel := context.WithCancel(ctx)
+		var resyncTimer *time.Timer
+		if !nextResync.IsZero() {
+			log.Info.Printf("graph-vulcan-assets: next resync at %v", nextResync)
+			resyncTimer = time.AfterFunc(time.Until(nextResync), pcancel)
 		}
-	}
 
-	// If the asset is still owned by a team, we can return because it is
-	// not expired.
-	if active {
-		return nil
-	}
+		err := vcli.ProcessAssets(pctx, h)
 
-	// Expire asset.
-	asset, err := icli.UpdateAsset(assets[0].ID, string(payload.AssetType), payload.Identifier, now, now)
-	if err != nil {
-		return fmt.Errorf("could not expire asset: %w", err)
-	}
+		pcancel()
+		if resyncTimer != nil {
+			resyncTimer.Stop()
+		}
 
-	// Expire parents.
-	parents, err := icli.Parents(asset.ID, inventory.Pagination{})
-	if err != nil {
-		return fmt.Errorf("could not get parents: %w", err)
-	}
+		if err != nil {
+			countInvalidMessage(err)
 
-	for _, p := range parents {
-		if p.Expiration.Before(now) || p.Expiration.Equal(now) {
+			var serr kafka.SubscriptionError
+			if errors.As(err, &serr) {
+				err = fmt.Errorf("error subscribing to assets: %w", err)
+			} else {
+				err = fmt.Errorf("error processing assets: %w", err)
+			}
+			if cfg.RetryDuration == 0 {
+				return err
+			}
+			log.Error.Printf("graph-vulcan-assets: %v", err)
+		} else if ctx.Err() == nil {
+			// Processing was interrupted to resync.
 			continue
 		}
 
-		if _, err := icli.UpsertParent(p.ChildID, p.ParentID, now, now); err != nil {
-			return fmt.Errorf("error expiring parent-of relations: %w", err)
-		}
+		log.Info.Printf("graph-vulcan-assets: retrying in %v", cfg.RetryDuration)
+		time.Sleep(cfg.RetryDuration)
 	}
+}
 
-	// Expire children.
-	children, err := icli.Children(asset.ID, inventory.Pagination{})
-	if err != nil {
-		return fmt.Errorf("could not get children: %w", err)
+// maxSubscribeRetryBackoff is the maximum time to wait between retries
+// after an error subscribing to the assets topic.
+const maxSubscribeRetryBackoff = time.Minute
+
+// Log outputs.
+const (
+	logOutputStderr = "stderr"
+	logOutputSyslog = "syslog"
+	logOutputFile   = "file"
+)
+
+// setupLog configures the log package according to the provided command
+// configuration.
+func setupLog(cfg config) error {
+	if err := log.SetLevel(cfg.LogLevel); err != nil {
+		return fmt.Errorf("error setting log level: %w", err)
+	}
+	if err := log.SetFormat(cfg.LogFormat); err != nil {
+		return fmt.Errorf("error setting log format: %w", err)
 	}
 
-	for _, c := range children {
-		if c.Expiration.Before(now) || c.Expiration.Equal(now) {
-			continue
+	switch cfg.LogOutput {
+	case logOutputSyslog:
+		network, addr, _ := strings.Cut(cfg.LogSyslogAddr, "://")
+		s, err := log.NewSyslog(network, addr, "graph-vulcan-assets")
+		if err != nil {
+			return fmt.Errorf("error setting log output: %w", err)
 		}
-
-		if _, err := icli.UpsertParent(c.ChildID, c.ParentID, now, now); err != nil {
-			return fmt.Errorf("error expiring parent-of relations: %w", err)
+		log.SetOutput(s)
+	case logOutputFile:
+		rf, err := log.NewRotatingFile(cfg.LogFile, int64(cfg.LogFileMaxSize), cfg.LogFileMaxAge, cfg.LogFileMaxBackups)
+		if err != nil {
+			return fmt.Errorf("error setting log output: %w", err)
 		}
+		log.SetOutput(rf)
 	}
 
 	return nil
 }
 
-// config contains the configuration of the command.
-type config struct {
-	LogLevel                    string
-	RetryDuration               time.Duration
-	KafkaBootstrapServers       string
-	KafkaGroupID                string
-	KafkaUsername               string
-	KafkaPassword               string
-	AWSAccountAnnotationKey     string
-	InventoryEndpoint           string
-	InventoryInsecureSkipVerify bool
+// kafkaPresets contains the kafka configuration properties applied by every
+// supported KAFKA_PRESET value.
+var kafkaPresets = map[string]map[string]any{
+	// confluent-cloud contains the client settings recommended by
+	// Confluent to connect to Confluent Cloud.
+	"confluent-cloud": {
+		"security.protocol":       "sasl_ssl",
+		"sasl.mechanisms":         "PLAIN",
+		"session.timeout.ms":      45000,
+		"socket.keepalive.enable": true,
+		"metadata.max.age.ms":     60000,
+		"broker.address.ttl":      30000,
+		"api.version.request":     true,
+		"api.version.fallback.ms": 0,
+		"broker.version.fallback": "0.10.0.0",
+	},
 }
 
-// readConfig reads the configuration from the environment.
-func readConfig() (config, error) {
-	// Required config.
-	kafkaBootstrapServers := os.Getenv("KAFKA_BOOTSTRAP_SERVERS")
-	if kafkaBootstrapServers == "" {
-		return config{}, errors.New("missing kafka bootstrap servers")
+// kafkaConsumerProfiles contains the consumer group properties applied by
+// every supported KAFKA_CONSUMER_PROFILE value.
+var kafkaConsumerProfiles = map[string]map[string]any{
+	// low-latency detects failed consumers quickly, so their
+	// partitions are reassigned and processed with little delay. It
+	// is meant for consumers that are usually up to date.
+	"low-latency": {
+		"session.timeout.ms":    10000,
+		"heartbeat.interval.ms": 3000,
+		"max.poll.interval.ms":  60000,
+		"fetch.wait.max.ms":     100,
+	},
+
+	// catch-up tolerates long pauses between reads, like the ones
+	// caused by slow Asset Inventories or large batches, without
+	// triggering rebalances. It is meant for consumers processing a
+	// large backlog.
+	"catch-up": {
+		"session.timeout.ms":    45000,
+		"heartbeat.interval.ms": 15000,
+		"max.poll.interval.ms":  900000,
+		"fetch.min.bytes":       1048576,
+		"fetch.wait.max.ms":     500,
+	},
+}
+
+// kafkaConsumerProperties contains the kafka configuration properties that
+// only apply to consumers.
+var kafkaConsumerProperties = []string{
+	"group.id",
+	"auto.offset.reset",
+	"session.timeout.ms",
+	"heartbeat.interval.ms",
+	"max.poll.interval.ms",
+	"fetch.min.bytes",
+	"fetch.wait.max.ms",
+}
+
+// kafkaConfig returns the kafka configuration properties corresponding to
+// the provided command configuration. The settings of the provider preset
+// take precedence over the credentials, the settings of the consumer profile
+// over the provider preset and the explicitly configured properties over
+// the consumer profile.
+func kafkaConfig(cfg config) map[string]any {
+	kcfg := map[string]any{
+		"bootstrap.servers": cfg.KafkaBootstrapServers,
+		"group.id":          cfg.KafkaGroupID,
+		"auto.offset.reset": cfg.KafkaAutoOffsetReset,
 	}
 
-	inventoryEndpoint := os.Getenv("INVENTORY_ENDPOINT")
-	if inventoryEndpoint == "" {
-		return config{}, errors.New("missing asset inventory endpoint")
+	if cfg.KafkaUsername != "" && cfg.KafkaPassword != "" {
+		kcfg["security.protocol"] = "sasl_ssl"
+		kcfg["sasl.mechanisms"] = "SCRAM-SHA-256"
+		kcfg["sasl.username"] = cfg.KafkaUsername
+		kcfg["sasl.password"] = cfg.KafkaPassword
 	}
 
-	awsAccountAnnotationKey := os.Getenv("AWS_ACCOUNT_ANNOTATION_KEY")
-	if awsAccountAnnotationKey == "" {
-		return config{}, errors.New("missing AWS account annotation key")
+	for k, v := range kafkaPresets[cfg.KafkaPreset] {
+		kcfg[k] = v
 	}
 
-	// Optional config.
-	logLevel := defaultLogLevel
-	if level := os.Getenv("LOG_LEVEL"); level != "" {
-		logLevel = level
+	for k, v := range kafkaConsumerProfiles[cfg.KafkaConsumerProfile] {
+		kcfg[k] = v
 	}
 
-	retryDuration := defaultRetryDuration
-	if rd := os.Getenv("RETRY_DURATION"); rd != "" {
-		var err error
+	if cfg.KafkaSessionTimeout > 0 {
+		kcfg["session.timeout.ms"] = int(cfg.KafkaSessionTimeout.Milliseconds())
+	}
+	if cfg.KafkaHeartbeatInterval > 0 {
+		kcfg["heartbeat.interval.ms"] = int(cfg.KafkaHeartbeatInterval.Milliseconds())
+	}
+	if cfg.KafkaMaxPollInterval > 0 {
+		kcfg["max.poll.interval.ms"] = int(cfg.KafkaMaxPollInterval.Milliseconds())
+	}
 
-		retryDuration, err = time.ParseDuration(rd)
-		if err != nil {
-			return config{}, fmt.Errorf("invalid retry duration: %w", err)
-		}
+	return kcfg
+}
+
+// validateKafkaTimeouts checks that the heartbeat interval is lower than the
+// session timeout and that the session timeout is not greater than the max
+// poll interval. Otherwise, the consumer would fail at startup. Properties
+// that are not set are not checked, because librdkafka defaults are
+// consistent.
+func validateKafkaTimeouts(kcfg map[string]any) error {
+	session, hasSession := kcfg["session.timeout.ms"].(int)
+	heartbeat, hasHeartbeat := kcfg["heartbeat.interval.ms"].(int)
+	maxPoll, hasMaxPoll := kcfg["max.poll.interval.ms"].(int)
+
+	if hasSession && hasHeartbeat && heartbeat >= session {
+		return fmt.Errorf("kafka heartbeat interval (%vms) must be lower than the session timeout (%vms)", heartbeat, session)
+	}
+	if hasSession && hasMaxPoll && maxPoll < session {
+		return fmt.Errorf("kafka max poll interval (%vms) must not be lower than the session timeout (%vms)", maxPoll, session)
 	}
+	return nil
+}
+
+// producerConfig returns the kafka producer configuration properties
+// corresponding to the provided command configuration.
+func producerConfig(cfg config) map[string]any {
+	kcfg := kafkaConfig(cfg)
+
+	// Remove consumer properties.
+	for _, k := range kafkaConsumerProperties {
+		delete(kcfg, k)
+	}
+
+	return kcfg
+}
+
+// newInventoryClient returns an Asset Inventory client configured according
+// to the provided command configuration. The provided options are applied
+// after the configuration.
+func newInventoryClient(cfg config, opts ...inventory.ClientOption) (inventory.Client, error) {
+	copts := []inventory.ClientOption{
+		inventory.WithMaxIdleConnsPerHost(cfg.InventoryHTTPMaxIdleConns),
+		inventory.WithIdleConnTimeout(cfg.InventoryHTTPIdleTimeout),
+		inventory.WithHTTP2(cfg.InventoryHTTP2),
+		inventory.WithDeletedFilter(cfg.InventoryDeletedFilter),
+		inventory.WithTeamsAsAssets(cfg.InventoryTeamsAsAssets),
+		inventory.WithServerTime(cfg.InventoryServerTime),
+		inventory.WithTLSFiles(inventory.TLSFiles{
+			CertFile: cfg.InventoryTLSCertFile,
+			KeyFile:  cfg.InventoryTLSKeyFile,
+			CAFile:   cfg.InventoryTLSCAFile,
+		}),
+	}
+	copts = append(copts, opts...)
+	return inventory.NewClient(cfg.InventoryEndpoint, cfg.InventoryInsecureSkipVerify, copts...)
+}
 
-	kafkaGroupID := defaultKafkaGroupID
-	if id := os.Getenv("KAFKA_GROUP_ID"); id != "" {
-		kafkaGroupID = id
+// newInventoryCapture returns the [inventory.Capture] that records the
+// requests sent to the Asset Inventory and the client options that enable
+// it. If capturing is disabled, it returns nil.
+func newInventoryCapture(cfg config) (*inventory.Capture, []inventory.ClientOption) {
+	if cfg.InventoryCaptureSize == 0 {
+		return nil, nil
 	}
+	capture := inventory.NewCapture(cfg.InventoryCaptureSize, cfg.InventoryCaptureMaxBody)
+	return capture, []inventory.ClientOption{inventory.WithCapture(capture)}
+}
 
-	kafkaUsername := os.Getenv("KAFKA_USERNAME")
-	kafkaPassword := os.Getenv("KAFKA_PASSWORD")
+// engineConfig returns the configuration of the [assetsync.Engine] that
+// synchronizes the assets according to cfg without storing properties of
+// the Security Graph.
+func engineConfig(cfg config) assetsync.Config {
+	return assetsync.Config{
+		InventoryPageSize:         cfg.InventoryPageSize,
+		InventoryParallelism:      cfg.InventoryParallelism,
+		InventoryNegativeCacheTTL: cfg.InventoryNegativeCacheTTL,
+		InventoryServerTime:       cfg.InventoryServerTime,
+		InventoryClockSkew:        cfg.InventoryClockSkew,
+		TombstoneIndexTTL:         cfg.TombstoneIndexTTL,
+		AssetStateCacheSize:       cfg.AssetStateCacheSize,
+		AssetStateTTL:             cfg.AssetStateTTL,
+		AWSAccountAnnotationKeys:  cfg.AWSAccountAnnotationKeys,
+		AliasAssetType:            cfg.AliasAssetType,
+		AuditDiff:                 cfg.AuditDiff,
+		DeriveIPRanges:            cfg.DeriveIPRanges,
+		MissingTeamPolicy:         cfg.MissingTeamPolicy,
+		NormalizeAssetTypes:       cfg.NormalizeAssetTypes,
+		OwnershipTransferWindow:   cfg.OwnershipTransferWindow,
+		TeamMapping:               cfg.TeamMapping,
+		Version:                   consumerVersion(),
+		Observer:                  dailyActivity,
+	}
+}
 
-	inventoryInsecureSkipVerify := os.Getenv("INVENTORY_INSECURE_SKIP_VERIFY") == "1"
+// syncConfig returns the configuration of the [assetsync.Engine] that
+// synchronizes the assets according to cfg. The properties of the Security
+// Graph enabled by cfg are stored in the Asset Inventory of icli. processed
+// contains the offsets of the assets topic processed before the start of
+// the consumer. See [assetsync.Config.Processed].
+func syncConfig(icli props.Inventory, processed checkpoint.Offsets, cfg config) assetsync.Config {
+	store := props.NewStore(icli)
 
-	cfg := config{
-		LogLevel:                    logLevel,
-		RetryDuration:               retryDuration,
-		KafkaBootstrapServers:       kafkaBootstrapServers,
-		KafkaGroupID:                kafkaGroupID,
-		KafkaUsername:               kafkaUsername,
-		KafkaPassword:               kafkaPassword,
-		AWSAccountAnnotationKey:     awsAccountAnnotationKey,
-		InventoryEndpoint:           inventoryEndpoint,
-		InventoryInsecureSkipVerify: inventoryInsecureSkipVerify,
+	scfg := engineConfig(cfg)
+	if cfg.StoreVulcanIDs {
+		scfg.VulcanIDs = store
 	}
+	if cfg.StoreProvenance {
+		scfg.Provenance = store
+	}
+	if cfg.StoreAssetState {
+		scfg.States = store
+		scfg.Processed = processed
+	}
+	return scfg
+}
 
-	return cfg, nil
+// assetHandler returns an asset handler that applies the assets to icli
+// with an [assetsync.Engine] configured with scfg. If the debug level is
+// enabled, the payloads are logged with the annotations matching
+// cfg.RedactAnnotations redacted.
+func assetHandler(icli inventory.Inventory, scfg assetsync.Config, cfg config) vulcan.AssetHandler {
+	engine := assetsync.NewEngine(icli, scfg)
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		if log.At("debug") {
+			log.Debug.Printf("graph-vulcan-assets: payload=%#v isNil=%v", redactPayload(payload, cfg.RedactAnnotations), isNil)
+		}
+		return engine.Handle(payload, isNil)
+	}
 }
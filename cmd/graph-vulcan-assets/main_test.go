@@ -2,541 +2,393 @@ package main
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
-	gremlingo "github.com/apache/tinkerpop/gremlin-go/v3/driver"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers"
 	"github.com/adevinta/graph-vulcan-assets/inventory"
-	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
 	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
 	"github.com/adevinta/graph-vulcan-assets/vulcan"
 )
 
 const (
-	bootstrapServers = "127.0.0.1:9092"
-	gremlinEndpoint  = "ws://127.0.0.1:8182/gremlin"
-	messagesFile     = "testdata/messages.json"
-	timeout          = 5 * time.Minute
-
-	// endMessageKey is the key of the last message of testdata. It
-	// contains an invalid payload to force run to return.
-	endMessageKey = "ENDTESTDATA"
+	messagesFile = "testdata/messages.json"
+	snapshotFile = "testdata/snapshot.json"
+	timeout      = 5 * time.Minute
 )
 
-func setupKafka() error {
-	cfg := &kafka.ConfigMap{
-		"bootstrap.servers": bootstrapServers,
-
-		// Set message timeout to 5s, so the kafka client returns an
-		// error if the broker is not up.
-		"message.timeout.ms": 5000,
-	}
-
-	prod, err := kafka.NewProducer(cfg)
-	if err != nil {
-		return fmt.Errorf("error creating producer: %w", err)
-	}
-	defer prod.Close()
-
-	admin, err := kafka.NewAdminClientFromProducer(prod)
-	if err != nil {
-		return fmt.Errorf("error creating admin client: %w", err)
-	}
+func TestMain(m *testing.M) {
+	os.Exit(containers.Main(m, containers.Inventory))
+}
 
-	adminOpts := []kafka.DeleteTopicsAdminOption{
-		kafka.SetAdminRequestTimeout(timeout),
-		kafka.SetAdminOperationTimeout(timeout),
-	}
+func resetInventory() error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	if _, err := admin.DeleteTopics(ctx, []string{vulcan.AssetsEntityName}, adminOpts...); err != nil {
-		return fmt.Errorf("error deleting topic: %w", err)
-	}
-
-	msgs := streamtest.MustParse(messagesFile)
-	for _, msg := range msgs {
-		if err := produceMessage(prod, vulcan.AssetsEntityName, msg); err != nil {
-			return fmt.Errorf("error producing message: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func produceMessage(prod *kafka.Producer, topic string, msg stream.Message) error {
-	events := make(chan kafka.Event)
-	defer close(events)
-
-	kmsg := &kafka.Message{
-		Key:            msg.Key,
-		Value:          msg.Value,
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-	}
-
-	for _, e := range msg.Metadata {
-		hdr := kafka.Header{
-			Key:   string(e.Key),
-			Value: e.Value,
-		}
-		kmsg.Headers = append(kmsg.Headers, hdr)
-	}
-
-	if err := prod.Produce(kmsg, events); err != nil {
-		return fmt.Errorf("failed to produce message: %w", err)
-	}
-
-	e := <-events
-	kmsg, ok := e.(*kafka.Message)
-	if !ok {
-		return errors.New("event type is not *kafka.Message")
-	}
-	if kmsg.TopicPartition.Error != nil {
-		return fmt.Errorf("could not deliver message: %w", kmsg.TopicPartition.Error)
+	if err := testinfra.WaitGremlin(ctx); err != nil {
+		return err
 	}
-
-	return nil
-}
-
-func resetInventory() error {
-	conn, err := gremlingo.NewDriverRemoteConnection(gremlinEndpoint, func(settings *gremlingo.DriverRemoteConnectionSettings) {
-		settings.LogVerbosity = gremlingo.Off
-	})
-	if err != nil {
-		return fmt.Errorf("could not connect to gremlin-server: %w", err)
+	if err := testinfra.WaitInventory(ctx); err != nil {
+		return err
 	}
-	defer conn.Close()
-
-	g := gremlingo.Traversal_().WithRemote(conn)
-
-	<-g.V().Not(gremlingo.T__.HasLabel("Universe")).Drop().Iterate()
-
-	return nil
+	return testinfra.ResetGraph()
 }
 
-type (
-	testdata struct {
-		Teams  []tdTeam
-		Assets []tdAsset
-	}
-
-	tdTeam struct {
-		Identifier string
-		Name       string
-	}
-
-	tdAsset struct {
-		ID      tdAssetID
-		Expired bool
-		Parents []tdParentOf
-		Owners  []tdOwns
-	}
-
-	tdAssetID struct {
-		Type       string
-		Identifier string
-	}
-
-	tdParentOf struct {
-		Parent  tdAssetID
-		Expired bool
-	}
-
-	tdOwns struct {
-		Team    string
-		Expired bool
-	}
-)
-
-var (
-	want = testdata{
-		Teams: []tdTeam{
-			{
-				Identifier: "team0",
-				Name:       "team0 name",
-			},
-			{
-				Identifier: "team1",
-				Name:       "team1 name",
-			},
-			{
-				Identifier: "team2",
-				Name:       "team2 name",
-			},
-			{
-				Identifier: "team3",
-				Name:       "team3 name",
-			},
+var want = inventorytest.Snapshot{
+	Teams: []inventorytest.Team{
+		{
+			Identifier: "team0",
+			Name:       "team0 name",
 		},
-		Assets: []tdAsset{
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset0.example.com",
-				},
-				Expired: false,
-				Parents: []tdParentOf{
-					{
-						Parent: tdAssetID{
-							Type:       "AWSAccount",
-							Identifier: "arn:aws:iam::000000000000:root",
-						},
-						Expired: false,
-					},
-				},
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: false,
-					},
-					{
-						Team:    "team1",
-						Expired: true,
+		{
+			Identifier: "team1",
+			Name:       "team1 name",
+		},
+		{
+			Identifier: "team2",
+			Name:       "team2 name",
+		},
+		{
+			Identifier: "team3",
+			Name:       "team3 name",
+		},
+	},
+	Assets: []inventorytest.Asset{
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset0.example.com",
+			},
+			Expired: false,
+			Parents: []inventorytest.ParentOf{
+				{
+					Parent: inventorytest.AssetID{
+						Type:       "AWSAccount",
+						Identifier: "arn:aws:iam::000000000000:root",
 					},
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset1.example.com",
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: false,
 				},
-				Expired: false,
-				Parents: []tdParentOf{
-					{
-						Parent: tdAssetID{
-							Type:       "AWSAccount",
-							Identifier: "arn:aws:iam::000000000000:root",
-						},
-						Expired: false,
-					},
+				{
+					Team:    "team1",
+					Expired: true,
 				},
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: false,
+			},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset1.example.com",
+			},
+			Expired: false,
+			Parents: []inventorytest.ParentOf{
+				{
+					Parent: inventorytest.AssetID{
+						Type:       "AWSAccount",
+						Identifier: "arn:aws:iam::000000000000:root",
 					},
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset2.example.com",
-				},
-				Expired: false,
-				Parents: []tdParentOf{
-					{
-						Parent: tdAssetID{
-							Type:       "AWSAccount",
-							Identifier: "arn:aws:iam::000000000000:root",
-						},
-						Expired: false,
-					},
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: false,
 				},
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: false,
+			},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset2.example.com",
+			},
+			Expired: false,
+			Parents: []inventorytest.ParentOf{
+				{
+					Parent: inventorytest.AssetID{
+						Type:       "AWSAccount",
+						Identifier: "arn:aws:iam::000000000000:root",
 					},
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset3.example.com",
-				},
-				Expired: false,
-				Parents: []tdParentOf{
-					{
-						Parent: tdAssetID{
-							Type:       "AWSAccount",
-							Identifier: "arn:aws:iam::111111111111:root",
-						},
-						Expired: true,
-					},
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: false,
 				},
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: false,
-					},
-					{
-						Team:    "team1",
-						Expired: false,
+			},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset3.example.com",
+			},
+			Expired: false,
+			Parents: []inventorytest.ParentOf{
+				{
+					Parent: inventorytest.AssetID{
+						Type:       "AWSAccount",
+						Identifier: "arn:aws:iam::111111111111:root",
 					},
+					Expired: true,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset4.example.com",
-				},
-				Expired: true,
-				Parents: []tdParentOf{
-					{
-						Parent: tdAssetID{
-							Type:       "AWSAccount",
-							Identifier: "arn:aws:iam::222222222222:root",
-						},
-						Expired: true,
-					},
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: false,
 				},
-				Owners: []tdOwns{
-					{
-						Team:    "team1",
-						Expired: true,
-					},
+				{
+					Team:    "team1",
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "AWSAccount",
-					Identifier: "arn:aws:iam::000000000000:root",
-				},
-				Expired: false,
-				Parents: nil,
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: false,
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset4.example.com",
+			},
+			Expired: true,
+			Parents: []inventorytest.ParentOf{
+				{
+					Parent: inventorytest.AssetID{
+						Type:       "AWSAccount",
+						Identifier: "arn:aws:iam::222222222222:root",
 					},
+					Expired: true,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "AWSAccount",
-					Identifier: "arn:aws:iam::111111111111:root",
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team1",
+					Expired: true,
 				},
-				Expired: true,
-				Parents: nil,
-				Owners: []tdOwns{
-					{
-						Team:    "team0",
-						Expired: true,
-					},
-					{
-						Team:    "team1",
-						Expired: true,
-					},
+			},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "AWSAccount",
+				Identifier: "arn:aws:iam::000000000000:root",
+			},
+			Expired: false,
+			Parents: nil,
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "AWSAccount",
-					Identifier: "arn:aws:iam::222222222222:root",
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "AWSAccount",
+				Identifier: "arn:aws:iam::111111111111:root",
+			},
+			Expired: true,
+			Parents: nil,
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team0",
+					Expired: true,
 				},
-				Expired: false,
-				Parents: nil,
-				Owners: []tdOwns{
-					{
-						Team:    "team1",
-						Expired: false,
-					},
+				{
+					Team:    "team1",
+					Expired: true,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset5.example.com",
-				},
-				Expired: false,
-				Parents: nil,
-				Owners: []tdOwns{
-					{
-						Team:    "team2",
-						Expired: false,
-					},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "AWSAccount",
+				Identifier: "arn:aws:iam::222222222222:root",
+			},
+			Expired: false,
+			Parents: nil,
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team1",
+					Expired: false,
 				},
 			},
-			{
-				ID: tdAssetID{
-					Type:       "Hostname",
-					Identifier: "asset6.example.com",
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset5.example.com",
+			},
+			Expired: false,
+			Parents: nil,
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team2",
+					Expired: false,
 				},
-				Expired: false,
-				Parents: nil,
-				Owners: []tdOwns{
-					{
-						Team:    "team3",
-						Expired: false,
-					},
+			},
+		},
+		{
+			ID: inventorytest.AssetID{
+				Type:       "Hostname",
+				Identifier: "asset6.example.com",
+			},
+			Expired: false,
+			Parents: nil,
+			Owners: []inventorytest.Owns{
+				{
+					Team:    "team3",
+					Expired: false,
 				},
 			},
 		},
+	},
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	scenario := e2eScenario{
+		Fixtures: messagesFile,
+		GroupID:  "cmd-graph-vulcan-assets-main-test",
+		LogLevel: "disabled",
+	}
+	got, err := scenario.run(ctx)
+	if err != nil {
+		t.Fatalf("error running scenario: %v", err)
 	}
 
-	diffOpts = []cmp.Option{
-		cmpopts.SortSlices(func(a, b tdTeam) bool {
-			return a.Identifier < b.Identifier
-		}),
-		cmpopts.SortSlices(func(a, b tdAsset) bool {
-			idA := a.ID.Type + "/" + a.ID.Identifier
-			idB := b.ID.Type + "/" + b.ID.Identifier
-			return idA < idB
-		}),
-		cmpopts.SortSlices(func(a, b tdParentOf) bool {
-			idA := a.Parent.Type + "/" + a.Parent.Identifier
-			idB := b.Parent.Type + "/" + b.Parent.Identifier
-			return idA < idB
-		}),
-		cmpopts.SortSlices(func(a, b tdOwns) bool {
-			return a.Team < b.Team
-		}),
+	if diff := inventorytest.Diff(want, got); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
 	}
-)
+}
 
-func TestRun(t *testing.T) {
-	if err := setupKafka(); err != nil {
-		t.Fatalf("error setting up kafka: %v", err)
+// TestSnapshotFile checks that the expected snapshot used by the test-env
+// command matches the one used by the integration tests. Run with -update
+// to regenerate it.
+func TestSnapshotFile(t *testing.T) {
+	if *update {
+		if err := writeSnapshot(snapshotFile, want); err != nil {
+			t.Fatalf("error writing snapshot: %v", err)
+		}
+	}
+
+	got, err := readSnapshot(snapshotFile)
+	if err != nil {
+		t.Fatalf("error reading snapshot: %v", err)
 	}
 
-	if err := resetInventory(); err != nil {
-		t.Fatalf("error resetting inventory: %v", err)
+	if diff := inventorytest.Diff(want, got); diff != "" {
+		t.Errorf("snapshot mismatch (-want +got):\n%v", diff)
 	}
+}
 
+func TestAssetHandler(t *testing.T) {
 	cfg := config{
-		LogLevel:                    "disabled",
-		RetryDuration:               0,
-		KafkaBootstrapServers:       "127.0.0.1:9092",
-		KafkaGroupID:                "cmd-graph-vulcan-assets-main-test",
-		KafkaUsername:               "",
-		KafkaPassword:               "",
-		AWSAccountAnnotationKey:     "discovery/aws/account",
-		InventoryEndpoint:           "http://127.0.0.1:8000",
-		InventoryInsecureSkipVerify: true,
+		AWSAccountAnnotationKeys: []string{"discovery/aws/account"},
+		InventoryPageSize:        2,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	inv := inventorytest.NewInMemory()
 
-	if err := run(ctx, cfg); err != nil {
+	vcli := vulcan.NewClient(streamtest.NewMockProcessor(streamtest.MustParse(messagesFile)))
+	if err := vcli.ProcessAssets(context.Background(), assetHandler(inv, engineConfig(cfg), cfg)); err != nil {
 		if !strings.Contains(err.Error(), endMessageKey) {
 			t.Fatalf("error processing messages: %v", err)
 		}
 	}
 
-	icli, err := inventory.NewClient(cfg.InventoryEndpoint, cfg.InventoryInsecureSkipVerify)
-	if err != nil {
-		t.Fatalf("could not create inventory client: %v", err)
-	}
-
-	got, err := getTestResults(icli)
+	got, err := inventorytest.TakeSnapshot(inv)
 	if err != nil {
 		t.Fatalf("error getting test results: %v", err)
 	}
 
-	if diff := cmp.Diff(want, got, diffOpts...); diff != "" {
+	if diff := inventorytest.Diff(want, got); diff != "" {
 		t.Errorf("messages mismatch (-want +got):\n%v", diff)
 	}
 }
 
-func getTestResults(icli inventory.Client) (testdata, error) {
-	var td testdata
+// memVulcanIDStore is an in-memory [assetsync.VulcanIDStore] and
+// [derivedStore]. The properties of assets and teams are stored by ID. Like
+// in the Asset Inventory, the properties with an empty value are removed.
+type memVulcanIDStore map[string]map[string]string
 
-	// Get teams.
-	teams, err := icli.Teams("", inventory.Pagination{})
-	if err != nil {
-		return testdata{}, fmt.Errorf("could not get teams: %w", err)
+func (s memVulcanIDStore) SetAsset(id string, props map[string]string) error {
+	if s[id] == nil {
+		s[id] = make(map[string]string)
 	}
-
-	for _, t := range teams {
-		tdt := tdTeam{
-			Identifier: t.Identifier,
-			Name:       t.Name,
+	for k, v := range props {
+		if v == "" {
+			delete(s[id], k)
+			continue
 		}
-		td.Teams = append(td.Teams, tdt)
+		s[id][k] = v
 	}
+	return nil
+}
 
-	// Get assets.
-	assets, err := icli.Assets("", "", time.Time{}, inventory.Pagination{})
-	if err != nil {
-		return testdata{}, fmt.Errorf("could not get assets: %w", err)
-	}
+func (s memVulcanIDStore) SetTeam(id string, props map[string]string) error {
+	return s.SetAsset(id, props)
+}
 
-	for _, a := range assets {
-		tda, err := getTestAsset(icli, assets, teams, a)
-		if err != nil {
-			return testdata{}, fmt.Errorf("could not get asset: %w", err)
-		}
-		td.Assets = append(td.Assets, tda)
+func (s memVulcanIDStore) Asset(id string) (map[string]string, error) {
+	props := make(map[string]string)
+	for k, v := range s[id] {
+		props[k] = v
 	}
-
-	return td, nil
+	return props, nil
 }
 
-func getTestAsset(icli inventory.Client, assets []inventory.AssetResp, teams []inventory.TeamResp, asset inventory.AssetResp) (tdAsset, error) {
-	tda := tdAsset{
-		ID: tdAssetID{
-			Type:       asset.Type,
-			Identifier: asset.Identifier,
-		},
-		Expired: !asset.Expiration.Equal(inventory.Unexpired),
-	}
+func TestSyncConfig(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	processed := checkpoint.Offsets{0: 10}
 
-	// Get parents.
-	parents, err := icli.Parents(asset.ID, inventory.Pagination{})
-	if err != nil {
-		return tdAsset{}, fmt.Errorf("could not get parents: %w", err)
+	scfg := syncConfig(inv, processed, config{})
+	if scfg.VulcanIDs != nil || scfg.Provenance != nil || scfg.States != nil || scfg.Processed != nil {
+		t.Fatalf("unexpected stores: %+v", scfg)
 	}
 
-	for _, p := range parents {
-		parent, err := findAsset(assets, p.ParentID)
-		if err != nil {
-			return tdAsset{}, fmt.Errorf("could not find parent: %w", err)
-		}
-		tdp := tdParentOf{
-			Parent: tdAssetID{
-				Type:       parent.Type,
-				Identifier: parent.Identifier,
-			},
-			Expired: !p.Expiration.Equal(inventory.Unexpired),
-		}
-		tda.Parents = append(tda.Parents, tdp)
+	cfg := config{
+		StoreVulcanIDs:  true,
+		StoreProvenance: true,
+		StoreAssetState: true,
+	}
+	scfg = syncConfig(inv, processed, cfg)
+	if scfg.VulcanIDs == nil || scfg.Provenance == nil || scfg.States == nil || scfg.Processed == nil {
+		t.Fatalf("missing stores: %+v", scfg)
 	}
 
-	// Get owners.
-	owners, err := icli.Owners(asset.ID, inventory.Pagination{})
+	asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
 	if err != nil {
-		return tdAsset{}, fmt.Errorf("could not get owners: %w", err)
+		t.Fatalf("error creating asset: %v", err)
 	}
-
-	for _, o := range owners {
-		owner, err := findTeam(teams, o.TeamID)
-		if err != nil {
-			return tdAsset{}, fmt.Errorf("could not find owner: %w", err)
-		}
-		tdo := tdOwns{
-			Team:    owner.Identifier,
-			Expired: o.EndTime != nil,
-		}
-		tda.Owners = append(tda.Owners, tdo)
+	if err := scfg.VulcanIDs.SetAsset(asset.ID, map[string]string{props.VulcanAssetIDKey: "vulcan-asset-1"}); err != nil {
+		t.Fatalf("error setting Vulcan IDs: %v", err)
 	}
 
-	return tda, nil
-}
-
-func findAsset(assets []inventory.AssetResp, id string) (inventory.AssetResp, error) {
-	for _, a := range assets {
-		if a.ID == id {
-			return a, nil
-		}
+	// The properties are stored in the Asset Inventory of the
+	// provided client.
+	got, err := inv.AssetProperties(asset.ID)
+	if err != nil {
+		t.Fatalf("error getting properties: %v", err)
 	}
-	return inventory.AssetResp{}, errors.New("not found")
-}
-
-func findTeam(teams []inventory.TeamResp, id string) (inventory.TeamResp, error) {
-	for _, t := range teams {
-		if t.ID == id {
-			return t, nil
-		}
+	want := inventory.Properties{props.VulcanAssetIDKey: "vulcan-asset-1"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("properties mismatch (-want +got):\n%v", diff)
 	}
-	return inventory.TeamResp{}, errors.New("not found")
 }
 
 func TestReadConfig(t *testing.T) {
@@ -554,41 +406,257 @@ func TestReadConfig(t *testing.T) {
 				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
 			},
 			wantConfig: config{
-				LogLevel:                    defaultLogLevel,
-				RetryDuration:               defaultRetryDuration,
-				KafkaBootstrapServers:       "127.0.0.1:9092",
-				KafkaGroupID:                defaultKafkaGroupID,
-				KafkaUsername:               "",
-				KafkaPassword:               "",
-				AWSAccountAnnotationKey:     "discovery/aws/account",
-				InventoryEndpoint:           "http://127.0.0.1:8000",
-				InventoryInsecureSkipVerify: false,
+				LogLevel:                      "info",
+				LogFormat:                     "text",
+				MissingTeamPolicy:             "ignore",
+				LogOutput:                     "stderr",
+				LogFileMaxSize:                104857600,
+				LogFileMaxBackups:             5,
+				RetryDuration:                 5 * time.Second,
+				HandlerRetryAttempts:          3,
+				HandlerRetryBackoff:           500 * time.Millisecond,
+				KafkaSubscribeRetryAttempts:   10,
+				KafkaSubscribeRetryBackoff:    time.Second,
+				PreflightTimeout:              1 * time.Minute,
+				CheckpointInterval:            1 * time.Minute,
+				OversizedMessagePolicy:        oversizedPolicyFail,
+				KafkaBootstrapServers:         "127.0.0.1:9092",
+				KafkaGroupID:                  "graph-vulcan-assets",
+				KafkaUsername:                 "",
+				KafkaPassword:                 "",
+				AWSAccountAnnotationKeys:      []string{"discovery/aws/account"},
+				RedactAnnotations:             []string{"*password*", "*secret*", "*token*"},
+				InventoryEndpoint:             "http://127.0.0.1:8000",
+				InventoryInsecureSkipVerify:   false,
+				InventoryPageSize:             100,
+				InventoryNegativeCacheTTL:     30 * time.Second,
+				InventoryVersionPolicy:        "fail",
+				InventoryVersionCheckInterval: 5 * time.Minute,
+				InventoryParallelism:          4,
+				KafkaAutoOffsetReset:          "earliest",
+				AssetStateTTL:                 time.Hour,
+				TopAssetsMaxAssets:            10000,
+				DailyReportSchedule:           "@daily",
+				DailyReportFormat:             "json",
+				ArchiveBatchSize:              10000,
+				ArchiveBatchInterval:          5 * time.Minute,
+				CatchUpLagThreshold:           1000,
+				CatchUpMaxDuration:            time.Hour,
+				CatchUpBatchSize:              500,
+				CatchUpParallelism:            16,
+				UnknownAssetTypePolicy:        "allow",
+				PayloadSchemaPolicy:           "off",
+				ReconcileParallelism:          1,
+				InventoryBatchSize:            1,
+				InventoryBatchInterval:        time.Second,
+				InventoryHTTPMaxIdleConns:     10,
+				InventoryHTTPIdleTimeout:      90 * time.Second,
+				InventoryCaptureMaxBody:       4096,
+				InventoryTLSReloadInterval:    1 * time.Minute,
 			},
 			wantNilErr: true,
 		},
 		{
 			name: "set optional config",
 			env: map[string]string{
-				"LOG_LEVEL":                      "debug",
-				"RETRY_DURATION":                 "30s",
-				"KAFKA_BOOTSTRAP_SERVERS":        "127.0.0.1:9092",
-				"KAFKA_GROUP_ID":                 "group-id",
-				"KAFKA_USERNAME":                 "username",
-				"KAFKA_PASSWORD":                 "password",
-				"AWS_ACCOUNT_ANNOTATION_KEY":     "discovery/aws/account",
-				"INVENTORY_ENDPOINT":             "http://127.0.0.1:8000",
-				"INVENTORY_INSECURE_SKIP_VERIFY": "1",
+				"LOG_LEVEL":                              "debug",
+				"LOG_FORMAT":                             "pretty",
+				"LOG_OUTPUT":                             "file",
+				"LOG_FILE":                               "/var/log/graph-vulcan-assets.log",
+				"LOG_FILE_MAX_SIZE":                      "1024",
+				"LOG_FILE_MAX_AGE":                       "24h",
+				"LOG_FILE_MAX_BACKUPS":                   "2",
+				"AUDIT_DIFF":                             "1",
+				"ADMIN_ADDR":                             ":9090",
+				"RETRY_DURATION":                         "30s",
+				"HANDLER_RETRY_ATTEMPTS":                 "5",
+				"HANDLER_RETRY_BACKOFF":                  "1s",
+				"PREFLIGHT_TIMEOUT":                      "10s",
+				"HEARTBEAT_FILE":                         "/tmp/heartbeat.json",
+				"MAINTENANCE_FILE":                       "/tmp/maintenance",
+				"RESYNC_SCHEDULE":                        "@weekly",
+				"CHECKPOINT_GREMLIN_ENDPOINT":            "ws://127.0.0.1:8182/gremlin",
+				"CHECKPOINT_INTERVAL":                    "30s",
+				"STORE_VULCAN_IDS":                       "1",
+				"STORE_PROVENANCE":                       "1",
+				"STORE_ASSET_STATE":                      "1",
+				"MAX_MESSAGE_SIZE":                       "1048576",
+				"OVERSIZED_MESSAGE_POLICY":               "dlq",
+				"MISSING_TEAM_POLICY":                    "expire",
+				"DLQ_TOPIC":                              "assets-v0-dlq",
+				"QUARANTINE_KEYS":                        "team-1/asset-1,team-1/asset-2",
+				"QUARANTINE_IDENTIFIERS":                 "www.example.com",
+				"KAFKA_BOOTSTRAP_SERVERS":                "127.0.0.1:9092",
+				"KAFKA_GROUP_ID":                         "group-id",
+				"KAFKA_ASSIGNMENT_STRATEGY":              "cooperative-sticky",
+				"INVENTORY_DELETED_FILTER":               "exclude",
+				"INVENTORY_NEGATIVE_CACHE_TTL":           "1m",
+				"INVENTORY_VERSION_POLICY":               "pause",
+				"INVENTORY_VERSION_CHECK_INTERVAL":       "1m",
+				"KAFKA_PRESET":                           "confluent-cloud",
+				"KAFKA_CONSUMER_PROFILE":                 "catch-up",
+				"KAFKA_AUTO_OFFSET_RESET":                "latest",
+				"KAFKA_SESSION_TIMEOUT":                  "30s",
+				"KAFKA_HEARTBEAT_INTERVAL":               "10s",
+				"KAFKA_USERNAME":                         "username",
+				"KAFKA_PASSWORD":                         "password",
+				"AWS_ACCOUNT_ANNOTATION_KEY":             "discovery/aws/account, aws/account-id",
+				"REDACT_ANNOTATIONS":                     "*/email",
+				"NORMALIZE_ASSET_TYPES":                  "Hostname,IP",
+				"DERIVE_IP_RANGES":                       "1",
+				"ALIAS_ASSET_TYPE":                       "Alias",
+				"INVENTORY_ENDPOINT":                     "http://127.0.0.1:8000",
+				"INVENTORY_INSECURE_SKIP_VERIFY":         "1",
+				"INVENTORY_PAGE_SIZE":                    "50",
+				"INVENTORY_PARALLELISM":                  "8",
+				"RECONCILE_PARALLELISM":                  "4",
+				"INVENTORY_BATCH_SIZE":                   "50",
+				"INVENTORY_BATCH_INTERVAL":               "200ms",
+				"INVENTORY_HTTP_MAX_IDLE_CONNS_PER_HOST": "32",
+				"INVENTORY_HTTP_IDLE_CONN_TIMEOUT":       "30s",
+				"INVENTORY_HTTP_ENABLE_HTTP2":            "1",
+				"INVENTORY_TEAMS_AS_ASSETS":              "1",
+				"INVENTORY_SERVER_TIME":                  "1",
+				"INVENTORY_CLOCK_SKEW":                   "1m",
+				"INVENTORY_CAPTURE_SIZE":                 "100",
+				"INVENTORY_CAPTURE_MAX_BODY":             "1024",
+				"INVENTORY_TLS_CERT_FILE":                "/etc/tls/tls.crt",
+				"INVENTORY_TLS_KEY_FILE":                 "/etc/tls/tls.key",
+				"INVENTORY_TLS_CA_FILE":                  "/etc/tls/ca.crt",
+				"INVENTORY_TLS_RELOAD_INTERVAL":          "10s",
+				"ROUTING_FILE":                           "/etc/graph-vulcan-assets/routing.json",
+				"ASSET_STATE_CACHE_SIZE":                 "10000",
+				"ASSET_STATE_TTL":                        "6h",
+				"TOP_ASSETS_MAX_ASSETS":                  "500",
+				"FRESHNESS_THRESHOLD":                    "10m",
+				"SAMPLE_PERCENT":                         "5",
+				"SAMPLE_FILE":                            "/tmp/sample.jsonl",
+				"DAILY_REPORT_SCHEDULE":                  "0 6 * * *",
+				"DAILY_REPORT_FORMAT":                    "markdown",
+				"DAILY_REPORT_WEBHOOK":                   "https://hooks.example.com/graph",
+				"DAILY_REPORT_S3_URL":                    "s3://reports/graph-vulcan-assets",
+				"ARCHIVE_S3_URL":                         "s3://archive/graph-vulcan-assets",
+				"ARCHIVE_BATCH_SIZE":                     "500",
+				"ARCHIVE_BATCH_INTERVAL":                 "1m",
+				"CATCHUP_LAG_THRESHOLD":                  "0",
+				"CATCHUP_MAX_DURATION":                   "30m",
+				"CATCHUP_BATCH_SIZE":                     "1000",
+				"CATCHUP_PARALLELISM":                    "32",
+				"METRICS_DISABLED_LABELS":                "team,asset_type",
+				"METRICS_MAX_LABEL_VALUES":               "100",
+				"CHAOS_MODE":                             "1",
+				"CHAOS_INVENTORY_ERROR_PERCENT":          "10",
+				"CHAOS_INVENTORY_LATENCY":                "50ms",
+				"CHAOS_HANDLER_PANIC_PERCENT":            "1",
+				"TOMBSTONE_INDEX_TTL":                    "10m",
+				"OWNERSHIP_TRANSFER_WINDOW":              "1h",
+				"PAYLOAD_SCHEMA_POLICY":                  "warn",
+				"INVENTORY_BATCH_PRIORITY":               "1",
+				"INVENTORY_BATCH_PRIORITY_ASSET_TYPES":   "AWSAccount,DockerImage",
+				"ALLOWED_ASSET_TYPES":                    "Hostname,IP",
+				"UNKNOWN_ASSET_TYPE_POLICY":              "skip",
+				"WAL_FILE":                               "/var/lib/graph-vulcan-assets/wal",
 			},
 			wantConfig: config{
-				LogLevel:                    "debug",
-				RetryDuration:               30 * time.Second,
-				KafkaBootstrapServers:       "127.0.0.1:9092",
-				KafkaGroupID:                "group-id",
-				KafkaUsername:               "username",
-				KafkaPassword:               "password",
-				AWSAccountAnnotationKey:     "discovery/aws/account",
-				InventoryEndpoint:           "http://127.0.0.1:8000",
-				InventoryInsecureSkipVerify: true,
+				LogLevel:                      "debug",
+				LogFormat:                     "pretty",
+				MissingTeamPolicy:             "expire",
+				LogOutput:                     "file",
+				LogFile:                       "/var/log/graph-vulcan-assets.log",
+				LogFileMaxSize:                1024,
+				LogFileMaxAge:                 24 * time.Hour,
+				LogFileMaxBackups:             2,
+				AuditDiff:                     true,
+				AdminAddr:                     ":9090",
+				RetryDuration:                 30 * time.Second,
+				HandlerRetryAttempts:          5,
+				HandlerRetryBackoff:           time.Second,
+				PreflightTimeout:              10 * time.Second,
+				HeartbeatFile:                 "/tmp/heartbeat.json",
+				MaintenanceFile:               "/tmp/maintenance",
+				ResyncSchedule:                "@weekly",
+				CheckpointGremlinEndpoint:     "ws://127.0.0.1:8182/gremlin",
+				CheckpointInterval:            30 * time.Second,
+				StoreVulcanIDs:                true,
+				StoreProvenance:               true,
+				StoreAssetState:               true,
+				MaxMessageSize:                1048576,
+				OversizedMessagePolicy:        "dlq",
+				DLQTopic:                      "assets-v0-dlq",
+				QuarantineKeys:                []string{"team-1/asset-1", "team-1/asset-2"},
+				QuarantineIdentifiers:         []string{"www.example.com"},
+				KafkaBootstrapServers:         "127.0.0.1:9092",
+				KafkaPreset:                   "confluent-cloud",
+				KafkaGroupID:                  "group-id",
+				KafkaAssignmentStrategy:       "cooperative-sticky",
+				InventoryDeletedFilter:        inventory.DeletedExclude,
+				InventoryNegativeCacheTTL:     time.Minute,
+				InventoryVersionPolicy:        "pause",
+				InventoryVersionCheckInterval: time.Minute,
+				KafkaSubscribeRetryAttempts:   10,
+				KafkaSubscribeRetryBackoff:    time.Second,
+				KafkaUsername:                 "username",
+				KafkaPassword:                 "password",
+				AWSAccountAnnotationKeys:      []string{"discovery/aws/account", "aws/account-id"},
+				RedactAnnotations:             []string{"*/email"},
+				NormalizeAssetTypes:           []vulcan.AssetType{"Hostname", "IP"},
+				DeriveIPRanges:                true,
+				AliasAssetType:                "Alias",
+				InventoryEndpoint:             "http://127.0.0.1:8000",
+				InventoryInsecureSkipVerify:   true,
+				InventoryPageSize:             50,
+				InventoryParallelism:          8,
+				KafkaConsumerProfile:          "catch-up",
+				KafkaAutoOffsetReset:          "latest",
+				AssetStateCacheSize:           10000,
+				AssetStateTTL:                 6 * time.Hour,
+				TopAssetsMaxAssets:            500,
+				FreshnessThreshold:            10 * time.Minute,
+				SamplePercent:                 5,
+				SampleFile:                    "/tmp/sample.jsonl",
+				DailyReportSchedule:           "0 6 * * *",
+				DailyReportFormat:             "markdown",
+				DailyReportWebhook:            "https://hooks.example.com/graph",
+				DailyReportS3URL:              "s3://reports/graph-vulcan-assets",
+				ArchiveS3URL:                  "s3://archive/graph-vulcan-assets",
+				ArchiveBatchSize:              500,
+				ArchiveBatchInterval:          time.Minute,
+				CatchUpLagThreshold:           0,
+				CatchUpMaxDuration:            30 * time.Minute,
+				CatchUpBatchSize:              1000,
+				CatchUpParallelism:            32,
+				MetricsDisabledLabels:         []string{"team", "asset_type"},
+				MetricsMaxLabelValues:         100,
+				ChaosMode:                     true,
+				ChaosInventoryErrorPercent:    10,
+				ChaosInventoryLatency:         50 * time.Millisecond,
+				ChaosHandlerPanicPercent:      1,
+				TombstoneIndexTTL:             10 * time.Minute,
+				OwnershipTransferWindow:       time.Hour,
+				PayloadSchemaPolicy:           "warn",
+				InventoryBatchPriority:        true,
+				InventoryPriorityAssetTypes:   []string{"AWSAccount", "DockerImage"},
+				AllowedAssetTypes:             []string{"Hostname", "IP"},
+				UnknownAssetTypePolicy:        "skip",
+				KafkaSessionTimeout:           30 * time.Second,
+				KafkaHeartbeatInterval:        10 * time.Second,
+				ReconcileParallelism:          4,
+				InventoryBatchSize:            50,
+				InventoryBatchInterval:        200 * time.Millisecond,
+				InventoryHTTPMaxIdleConns:     32,
+				InventoryHTTPIdleTimeout:      30 * time.Second,
+				InventoryHTTP2:                true,
+				InventoryTeamsAsAssets:        true,
+				InventoryServerTime:           true,
+				InventoryClockSkew:            time.Minute,
+				InventoryCaptureSize:          100,
+				InventoryCaptureMaxBody:       1024,
+				InventoryTLSCertFile:          "/etc/tls/tls.crt",
+				InventoryTLSKeyFile:           "/etc/tls/tls.key",
+				InventoryTLSCAFile:            "/etc/tls/ca.crt",
+				InventoryTLSReloadInterval:    10 * time.Second,
+				RoutingFile:                   "/etc/graph-vulcan-assets/routing.json",
+				WALFile:                       "/var/lib/graph-vulcan-assets/wal",
 			},
 			wantNilErr: true,
 		},
@@ -631,89 +699,1061 @@ func TestReadConfig(t *testing.T) {
 			wantNilErr: false,
 		},
 		{
-			name: "zero RETRY_DURATION",
+			name: "invalid HANDLER_RETRY_ATTEMPTS",
 			env: map[string]string{
 				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
 				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
 				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
-				"RETRY_DURATION":             "0",
+				"HANDLER_RETRY_ATTEMPTS":     "-1",
 			},
-			wantConfig: config{
-				LogLevel:                    defaultLogLevel,
-				RetryDuration:               0,
-				KafkaBootstrapServers:       "127.0.0.1:9092",
-				KafkaGroupID:                defaultKafkaGroupID,
-				KafkaUsername:               "",
-				KafkaPassword:               "",
-				AWSAccountAnnotationKey:     "discovery/aws/account",
-				InventoryEndpoint:           "http://127.0.0.1:8000",
-				InventoryInsecureSkipVerify: false,
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_VERSION_POLICY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_VERSION_POLICY":   "ignore",
 			},
-			wantNilErr: true,
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_DELETED_FILTER",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_DELETED_FILTER":   "include",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid KAFKA_SUBSCRIBE_RETRY_ATTEMPTS",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":        "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":             "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":     "discovery/aws/account",
+				"KAFKA_SUBSCRIBE_RETRY_ATTEMPTS": "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid HANDLER_RETRY_BACKOFF",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"HANDLER_RETRY_BACKOFF":      "1x",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid PREFLIGHT_TIMEOUT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"PREFLIGHT_TIMEOUT":          "1x",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_PAGE_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_PAGE_SIZE":        "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid ASSET_STATE_CACHE_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ASSET_STATE_CACHE_SIZE":     "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid ASSET_STATE_TTL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ASSET_STATE_TTL":            "0s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "STORE_ASSET_STATE without CHECKPOINT_GREMLIN_ENDPOINT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"STORE_ASSET_STATE":          "1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid UNKNOWN_ASSET_TYPE_POLICY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"UNKNOWN_ASSET_TYPE_POLICY":  "drop",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "UNKNOWN_ASSET_TYPE_POLICY without ALLOWED_ASSET_TYPES",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"UNKNOWN_ASSET_TYPE_POLICY":  "skip",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "UNKNOWN_ASSET_TYPE_POLICY dlq without DLQ_TOPIC",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ALLOWED_ASSET_TYPES":        "Hostname",
+				"UNKNOWN_ASSET_TYPE_POLICY":  "dlq",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid PAYLOAD_SCHEMA_POLICY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"PAYLOAD_SCHEMA_POLICY":      "strict",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "PAYLOAD_SCHEMA_POLICY dlq without DLQ_TOPIC",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"PAYLOAD_SCHEMA_POLICY":      "dlq",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_CAPTURE_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_CAPTURE_SIZE":     "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_CAPTURE_MAX_BODY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_CAPTURE_MAX_BODY": "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid TOP_ASSETS_MAX_ASSETS",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"TOP_ASSETS_MAX_ASSETS":      "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid SAMPLE_PERCENT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"SAMPLE_PERCENT":             "101",
+				"SAMPLE_FILE":                "/tmp/sample.jsonl",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "missing sample sink",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"SAMPLE_PERCENT":             "5",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "both sample sinks",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"SAMPLE_PERCENT":             "5",
+				"SAMPLE_TOPIC":               "assets-sample",
+				"SAMPLE_FILE":                "/tmp/sample.jsonl",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid DAILY_REPORT_SCHEDULE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"DAILY_REPORT_SCHEDULE":      "daily",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid DAILY_REPORT_FORMAT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"DAILY_REPORT_FORMAT":        "html",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid DAILY_REPORT_S3_URL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"DAILY_REPORT_S3_URL":        "https://reports.s3.amazonaws.com",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid ARCHIVE_S3_URL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ARCHIVE_S3_URL":             "https://archive.s3.amazonaws.com",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid ARCHIVE_BATCH_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ARCHIVE_BATCH_SIZE":         "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid ARCHIVE_BATCH_INTERVAL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"ARCHIVE_BATCH_INTERVAL":     "0s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "missing TEAM_MAPPING_FILE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"TEAM_MAPPING_FILE":          "/nonexistent/teams.json",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CATCHUP_LAG_THRESHOLD",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CATCHUP_LAG_THRESHOLD":      "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CATCHUP_MAX_DURATION",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CATCHUP_MAX_DURATION":       "0s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CATCHUP_BATCH_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CATCHUP_BATCH_SIZE":         "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CATCHUP_PARALLELISM",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CATCHUP_PARALLELISM":        "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid METRICS_MAX_LABEL_VALUES",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"METRICS_MAX_LABEL_VALUES":   "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CHAOS_INVENTORY_ERROR_PERCENT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":       "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":            "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":    "discovery/aws/account",
+				"CHAOS_MODE":                    "1",
+				"CHAOS_INVENTORY_ERROR_PERCENT": "101",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CHAOS_INVENTORY_LATENCY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CHAOS_MODE":                 "1",
+				"CHAOS_INVENTORY_LATENCY":    "-1s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CHAOS_HANDLER_PANIC_PERCENT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":     "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":          "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":  "discovery/aws/account",
+				"CHAOS_MODE":                  "1",
+				"CHAOS_HANDLER_PANIC_PERCENT": "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "chaos faults without CHAOS_MODE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":     "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":          "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":  "discovery/aws/account",
+				"CHAOS_HANDLER_PANIC_PERCENT": "10",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid TOMBSTONE_INDEX_TTL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"TOMBSTONE_INDEX_TTL":        "-1m",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "INVENTORY_BATCH_PRIORITY_ASSET_TYPES without INVENTORY_BATCH_PRIORITY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":              "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":                   "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":           "discovery/aws/account",
+				"INVENTORY_BATCH_PRIORITY_ASSET_TYPES": "AWSAccount",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid OWNERSHIP_TRANSFER_WINDOW",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"OWNERSHIP_TRANSFER_WINDOW":  "-1h",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid FRESHNESS_THRESHOLD",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"FRESHNESS_THRESHOLD":        "-1m",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_CLOCK_SKEW",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_CLOCK_SKEW":       "-1m",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid RECONCILE_PARALLELISM",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"RECONCILE_PARALLELISM":      "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_PARALLELISM",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_PARALLELISM":      "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid MISSING_TEAM_POLICY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"MISSING_TEAM_POLICY":        "delete",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_BATCH_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_BATCH_SIZE":       "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_BATCH_INTERVAL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_BATCH_INTERVAL":   "0s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_HTTP_MAX_IDLE_CONNS_PER_HOST",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":                "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":                     "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":             "discovery/aws/account",
+				"INVENTORY_HTTP_MAX_IDLE_CONNS_PER_HOST": "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_HTTP_IDLE_CONN_TIMEOUT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":          "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":               "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":       "discovery/aws/account",
+				"INVENTORY_HTTP_IDLE_CONN_TIMEOUT": "soon",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "missing INVENTORY_TLS_KEY_FILE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"INVENTORY_TLS_CERT_FILE":    "/etc/tls/tls.crt",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid INVENTORY_TLS_RELOAD_INTERVAL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":       "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":            "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":    "discovery/aws/account",
+				"INVENTORY_TLS_RELOAD_INTERVAL": "0s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid NORMALIZE_ASSET_TYPES",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"NORMALIZE_ASSET_TYPES":      "Unknown",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid REDACT_ANNOTATIONS",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"REDACT_ANNOTATIONS":         "[",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid RESYNC_SCHEDULE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"RESYNC_SCHEDULE":            "every week",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid CHECKPOINT_INTERVAL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CHECKPOINT_INTERVAL":        "1x",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "zero CHECKPOINT_INTERVAL",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"CHECKPOINT_INTERVAL":        "0",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "unknown KAFKA_CONSUMER_PROFILE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_CONSUMER_PROFILE":     "fast",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid KAFKA_AUTO_OFFSET_RESET",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_AUTO_OFFSET_RESET":    "beginning",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "negative KAFKA_SESSION_TIMEOUT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_SESSION_TIMEOUT":      "-1s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "KAFKA_HEARTBEAT_INTERVAL not lower than KAFKA_SESSION_TIMEOUT",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_SESSION_TIMEOUT":      "10s",
+				"KAFKA_HEARTBEAT_INTERVAL":   "10s",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "KAFKA_SESSION_TIMEOUT greater than the profile max poll interval",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_CONSUMER_PROFILE":     "low-latency",
+				"KAFKA_SESSION_TIMEOUT":      "2m",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "unknown KAFKA_PRESET",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_USERNAME":             "username",
+				"KAFKA_PASSWORD":             "password",
+				"KAFKA_PRESET":               "unknown",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "KAFKA_PRESET without credentials",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_PRESET":               "confluent-cloud",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid MAX_MESSAGE_SIZE",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"MAX_MESSAGE_SIZE":           "-1",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid OVERSIZED_MESSAGE_POLICY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"OVERSIZED_MESSAGE_POLICY":   "truncate",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "dlq policy without DLQ_TOPIC",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"OVERSIZED_MESSAGE_POLICY":   "dlq",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "invalid KAFKA_ASSIGNMENT_STRATEGY",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"KAFKA_ASSIGNMENT_STRATEGY":  "sticky",
+			},
+			wantConfig: config{},
+			wantNilErr: false,
+		},
+		{
+			name: "prefixed config",
+			env: map[string]string{
+				"GVA_KAFKA_BOOTSTRAP_SERVERS": "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":          "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY":  "discovery/aws/account",
+				"LOG_LEVEL":                   "debug",
+				"GVA_LOG_LEVEL":               "error",
+				"GVA_REDACT_ANNOTATIONS":      "",
+			},
+			wantConfig: config{
+				LogLevel:                      "error",
+				LogFormat:                     "text",
+				MissingTeamPolicy:             "ignore",
+				LogOutput:                     "stderr",
+				LogFileMaxSize:                104857600,
+				LogFileMaxBackups:             5,
+				RetryDuration:                 5 * time.Second,
+				HandlerRetryAttempts:          3,
+				HandlerRetryBackoff:           500 * time.Millisecond,
+				KafkaSubscribeRetryAttempts:   10,
+				KafkaSubscribeRetryBackoff:    time.Second,
+				PreflightTimeout:              1 * time.Minute,
+				CheckpointInterval:            1 * time.Minute,
+				OversizedMessagePolicy:        oversizedPolicyFail,
+				KafkaBootstrapServers:         "127.0.0.1:9092",
+				KafkaGroupID:                  "graph-vulcan-assets",
+				AWSAccountAnnotationKeys:      []string{"discovery/aws/account"},
+				InventoryEndpoint:             "http://127.0.0.1:8000",
+				InventoryPageSize:             100,
+				InventoryNegativeCacheTTL:     30 * time.Second,
+				InventoryVersionPolicy:        "fail",
+				InventoryVersionCheckInterval: 5 * time.Minute,
+				InventoryParallelism:          4,
+				KafkaAutoOffsetReset:          "earliest",
+				AssetStateTTL:                 time.Hour,
+				TopAssetsMaxAssets:            10000,
+				DailyReportSchedule:           "@daily",
+				DailyReportFormat:             "json",
+				ArchiveBatchSize:              10000,
+				ArchiveBatchInterval:          5 * time.Minute,
+				CatchUpLagThreshold:           1000,
+				CatchUpMaxDuration:            time.Hour,
+				CatchUpBatchSize:              500,
+				CatchUpParallelism:            16,
+				UnknownAssetTypePolicy:        "allow",
+				PayloadSchemaPolicy:           "off",
+				ReconcileParallelism:          1,
+				InventoryBatchSize:            1,
+				InventoryBatchInterval:        time.Second,
+				InventoryHTTPMaxIdleConns:     10,
+				InventoryHTTPIdleTimeout:      90 * time.Second,
+				InventoryCaptureMaxBody:       4096,
+				InventoryTLSReloadInterval:    1 * time.Minute,
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "zero RETRY_DURATION",
+			env: map[string]string{
+				"KAFKA_BOOTSTRAP_SERVERS":    "127.0.0.1:9092",
+				"INVENTORY_ENDPOINT":         "http://127.0.0.1:8000",
+				"AWS_ACCOUNT_ANNOTATION_KEY": "discovery/aws/account",
+				"RETRY_DURATION":             "0",
+			},
+			wantConfig: config{
+				LogLevel:                      "info",
+				LogFormat:                     "text",
+				MissingTeamPolicy:             "ignore",
+				LogOutput:                     "stderr",
+				LogFileMaxSize:                104857600,
+				LogFileMaxBackups:             5,
+				RetryDuration:                 0,
+				HandlerRetryAttempts:          3,
+				HandlerRetryBackoff:           500 * time.Millisecond,
+				KafkaSubscribeRetryAttempts:   10,
+				KafkaSubscribeRetryBackoff:    time.Second,
+				PreflightTimeout:              1 * time.Minute,
+				CheckpointInterval:            1 * time.Minute,
+				OversizedMessagePolicy:        oversizedPolicyFail,
+				KafkaBootstrapServers:         "127.0.0.1:9092",
+				KafkaGroupID:                  "graph-vulcan-assets",
+				KafkaUsername:                 "",
+				KafkaPassword:                 "",
+				AWSAccountAnnotationKeys:      []string{"discovery/aws/account"},
+				RedactAnnotations:             []string{"*password*", "*secret*", "*token*"},
+				InventoryEndpoint:             "http://127.0.0.1:8000",
+				InventoryInsecureSkipVerify:   false,
+				InventoryPageSize:             100,
+				InventoryNegativeCacheTTL:     30 * time.Second,
+				InventoryVersionPolicy:        "fail",
+				InventoryVersionCheckInterval: 5 * time.Minute,
+				InventoryParallelism:          4,
+				KafkaAutoOffsetReset:          "earliest",
+				AssetStateTTL:                 time.Hour,
+				TopAssetsMaxAssets:            10000,
+				DailyReportSchedule:           "@daily",
+				DailyReportFormat:             "json",
+				ArchiveBatchSize:              10000,
+				ArchiveBatchInterval:          5 * time.Minute,
+				CatchUpLagThreshold:           1000,
+				CatchUpMaxDuration:            time.Hour,
+				CatchUpBatchSize:              500,
+				CatchUpParallelism:            16,
+				UnknownAssetTypePolicy:        "allow",
+				PayloadSchemaPolicy:           "off",
+				ReconcileParallelism:          1,
+				InventoryBatchSize:            1,
+				InventoryBatchInterval:        time.Second,
+				InventoryHTTPMaxIdleConns:     10,
+				InventoryHTTPIdleTimeout:      90 * time.Second,
+				InventoryCaptureMaxBody:       4096,
+				InventoryTLSReloadInterval:    1 * time.Minute,
+			},
+			wantNilErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			config, err := readConfig()
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if diff := cmp.Diff(tt.wantConfig, config); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestKafkaConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config
+		want map[string]any
+	}{
+		{
+			name: "plaintext",
+			cfg: config{
+				KafkaBootstrapServers: "127.0.0.1:9092",
+				KafkaGroupID:          "group-id",
+				KafkaAutoOffsetReset:  "earliest",
+			},
+			want: map[string]any{
+				"bootstrap.servers": "127.0.0.1:9092",
+				"group.id":          "group-id",
+				"auto.offset.reset": "earliest",
+			},
+		},
+		{
+			name: "credentials",
+			cfg: config{
+				KafkaBootstrapServers: "127.0.0.1:9092",
+				KafkaGroupID:          "group-id",
+				KafkaAutoOffsetReset:  "earliest",
+				KafkaUsername:         "username",
+				KafkaPassword:         "password",
+			},
+			want: map[string]any{
+				"bootstrap.servers": "127.0.0.1:9092",
+				"group.id":          "group-id",
+				"auto.offset.reset": "earliest",
+				"security.protocol": "sasl_ssl",
+				"sasl.mechanisms":   "SCRAM-SHA-256",
+				"sasl.username":     "username",
+				"sasl.password":     "password",
+			},
+		},
+		{
+			name: "confluent-cloud preset",
+			cfg: config{
+				KafkaBootstrapServers: "pkc-xxxxx.eu-west-1.aws.confluent.cloud:9092",
+				KafkaGroupID:          "group-id",
+				KafkaAutoOffsetReset:  "earliest",
+				KafkaUsername:         "api-key",
+				KafkaPassword:         "api-secret",
+				KafkaPreset:           "confluent-cloud",
+			},
+			want: map[string]any{
+				"bootstrap.servers":       "pkc-xxxxx.eu-west-1.aws.confluent.cloud:9092",
+				"group.id":                "group-id",
+				"auto.offset.reset":       "earliest",
+				"security.protocol":       "sasl_ssl",
+				"sasl.mechanisms":         "PLAIN",
+				"sasl.username":           "api-key",
+				"sasl.password":           "api-secret",
+				"session.timeout.ms":      45000,
+				"socket.keepalive.enable": true,
+				"metadata.max.age.ms":     60000,
+				"broker.address.ttl":      30000,
+				"api.version.request":     true,
+				"api.version.fallback.ms": 0,
+				"broker.version.fallback": "0.10.0.0",
+			},
+		},
+		{
+			name: "consumer profile",
+			cfg: config{
+				KafkaBootstrapServers: "127.0.0.1:9092",
+				KafkaGroupID:          "group-id",
+				KafkaAutoOffsetReset:  "latest",
+				KafkaConsumerProfile:  "low-latency",
+			},
+			want: map[string]any{
+				"bootstrap.servers":     "127.0.0.1:9092",
+				"group.id":              "group-id",
+				"auto.offset.reset":     "latest",
+				"session.timeout.ms":    10000,
+				"heartbeat.interval.ms": 3000,
+				"max.poll.interval.ms":  60000,
+				"fetch.wait.max.ms":     100,
+			},
+		},
+		{
+			name: "consumer profile with explicit timeouts",
+			cfg: config{
+				KafkaBootstrapServers:  "127.0.0.1:9092",
+				KafkaGroupID:           "group-id",
+				KafkaAutoOffsetReset:   "earliest",
+				KafkaConsumerProfile:   "catch-up",
+				KafkaSessionTimeout:    30 * time.Second,
+				KafkaHeartbeatInterval: 5 * time.Second,
+				KafkaMaxPollInterval:   10 * time.Minute,
+			},
+			want: map[string]any{
+				"bootstrap.servers":     "127.0.0.1:9092",
+				"group.id":              "group-id",
+				"auto.offset.reset":     "earliest",
+				"session.timeout.ms":    30000,
+				"heartbeat.interval.ms": 5000,
+				"max.poll.interval.ms":  600000,
+				"fetch.min.bytes":       1048576,
+				"fetch.wait.max.ms":     500,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kafkaConfig(tt.cfg)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("kafka config mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseRunFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		want       runOptions
+		wantNilErr bool
+	}{
+		{
+			name:       "no flags",
+			args:       nil,
+			want:       runOptions{},
+			wantNilErr: true,
+		},
+		{
+			name:       "from beginning",
+			args:       []string{"-from-beginning"},
+			want:       runOptions{fromBeginning: true},
+			wantNilErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			for k, v := range tt.env {
-				t.Setenv(k, v)
-			}
-
-			config, err := readConfig()
-			if (err == nil) != tt.wantNilErr {
-				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
-			}
-
-			if diff := cmp.Diff(tt.wantConfig, config); diff != "" {
-				t.Errorf("messages mismatch (-want +got):\n%v", diff)
-			}
-		})
-	}
-}
-
-func TestNormalizeAWSAccountID(t *testing.T) {
-	tests := []struct {
-		name       string
-		id         string
-		wantID     string
-		wantNilErr bool
-	}{
 		{
-			name:       "long ID",
-			id:         "arn:aws:iam::123456789012:root",
-			wantID:     "arn:aws:iam::123456789012:root",
+			name:       "from beginning double dash",
+			args:       []string{"--from-beginning"},
+			want:       runOptions{fromBeginning: true},
 			wantNilErr: true,
 		},
 		{
-			name:       "short ID",
-			id:         "123456789012",
-			wantID:     "arn:aws:iam::123456789012:root",
+			name:       "catch up",
+			args:       []string{"-catch-up"},
+			want:       runOptions{catchUp: true},
 			wantNilErr: true,
 		},
 		{
-			name:       "invalid long ID",
-			id:         "arn:aws:iam::12345abc9012:root",
-			wantID:     "",
+			name:       "unknown flag",
+			args:       []string{"-unknown"},
+			want:       runOptions{},
 			wantNilErr: false,
 		},
 		{
-			name:       "invalid short ID",
-			id:         "12345abc9012",
-			wantID:     "",
+			name:       "unexpected argument",
+			args:       []string{"-from-beginning", "extra"},
+			want:       runOptions{},
 			wantNilErr: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotID, err := normalizeAWSAccountID(tt.id)
+			got, err := parseRunFlags(tt.args)
 
 			if (err == nil) != tt.wantNilErr {
 				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
 			}
 
-			if gotID != tt.wantID {
-				t.Errorf("unexpected ID: want=%v, got=%v", tt.wantID, gotID)
+			if got != tt.want {
+				t.Errorf("unexpected options: want=%+v, got=%+v", tt.want, got)
 			}
 		})
 	}
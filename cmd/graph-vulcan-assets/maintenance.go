@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+)
+
+// maintenanceInterval is the time between two consecutive checks of the
+// maintenance mode.
+const maintenanceInterval = 1 * time.Second
+
+// pauser is implemented by the stream processors that can pause the
+// consumption of messages.
+type pauser interface {
+	SetPaused(paused bool)
+}
+
+// maintenance controls the maintenance mode. When the maintenance mode is
+// enabled, message consumption and, consequently, inventory writes are
+// paused without exiting the process. The maintenance mode is enabled if it
+// has been enabled using the admin API, the maintenance file exists or the
+// API version of an Asset Inventory is not supported.
+type maintenance struct {
+	file         string
+	enabled      atomic.Bool
+	incompatible atomic.Bool
+}
+
+// newMaintenance returns a maintenance mode controller. If file is not empty,
+// the maintenance mode is also enabled while the file exists.
+func newMaintenance(file string) *maintenance {
+	return &maintenance{file: file}
+}
+
+// active reports whether the maintenance mode is enabled.
+func (m *maintenance) active() bool {
+	if m.enabled.Load() || m.incompatible.Load() {
+		return true
+	}
+	if m.file == "" {
+		return false
+	}
+	_, err := os.Stat(m.file)
+	return err == nil
+}
+
+// setIncompatibleVersion enables or disables the maintenance mode because
+// the API version of an Asset Inventory is not supported. It cannot be
+// overridden using the admin API.
+func (m *maintenance) setIncompatibleVersion(incompatible bool) {
+	m.incompatible.Store(incompatible)
+}
+
+// watch pauses p when the maintenance mode is enabled and resumes it when it
+// is disabled. It blocks the calling goroutine until the provided context is
+// cancelled.
+func (m *maintenance) watch(ctx context.Context, p pauser) {
+	var paused bool
+	for {
+		if active := m.active(); active != paused {
+			p.SetPaused(active)
+			paused = active
+
+			if paused {
+				log.Info.Println("graph-vulcan-assets: maintenance mode enabled, processing paused")
+			} else {
+				log.Info.Println("graph-vulcan-assets: maintenance mode disabled, processing resumed")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(maintenanceInterval):
+		}
+	}
+}
+
+// maintenanceState is the representation of the maintenance mode used by
+// the admin API.
+type maintenanceState struct {
+	Enabled             bool `json:"enabled"`
+	IncompatibleVersion bool `json:"incompatible_version,omitempty"`
+}
+
+// ServeHTTP implements the admin API endpoint of the maintenance mode. GET
+// requests return the current state. PUT requests, with a
+// [maintenanceState] body, enable or disable the maintenance mode.
+func (m *maintenance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var state maintenanceState
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+		m.enabled.Store(state.Enabled)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maintenanceState{
+		Enabled:             m.active(),
+		IncompatibleVersion: m.incompatible.Load(),
+	})
+}
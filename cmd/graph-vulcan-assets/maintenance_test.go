@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaintenanceActive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance")
+	m := newMaintenance(file)
+
+	if m.active() {
+		t.Errorf("maintenance mode should be disabled")
+	}
+
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("could not create maintenance file: %v", err)
+	}
+	if !m.active() {
+		t.Errorf("maintenance mode should be enabled by file")
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("could not remove maintenance file: %v", err)
+	}
+	m.enabled.Store(true)
+	if !m.active() {
+		t.Errorf("maintenance mode should be enabled by flag")
+	}
+}
+
+func TestMaintenanceServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantState  maintenanceState
+	}{
+		{
+			name:       "get state",
+			method:     http.MethodGet,
+			body:       "",
+			wantStatus: http.StatusOK,
+			wantState:  maintenanceState{Enabled: false},
+		},
+		{
+			name:       "enable",
+			method:     http.MethodPut,
+			body:       `{"enabled": true}`,
+			wantStatus: http.StatusOK,
+			wantState:  maintenanceState{Enabled: true},
+		},
+		{
+			name:       "invalid body",
+			method:     http.MethodPut,
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid method",
+			method:     http.MethodPost,
+			body:       "",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMaintenance("")
+
+			req := httptest.NewRequest(tt.method, "/maintenance", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("unexpected status code: want=%v, got=%v", tt.wantStatus, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got maintenanceState
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			if got != tt.wantState {
+				t.Errorf("unexpected state: want=%+v, got=%+v", tt.wantState, got)
+			}
+		})
+	}
+}
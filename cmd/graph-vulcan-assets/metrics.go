@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"expvar"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/metrics"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Data quality metrics.
+var (
+	malformedPayloadsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_malformed_payloads_total",
+		"Number of messages with malformed payload or metadata.",
+		"asset_type", "team",
+	)
+
+	unsupportedVersionsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_unsupported_versions_total",
+		"Number of messages with an unsupported version.",
+		"asset_type", "team",
+	)
+
+	messageVersionsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_message_versions_total",
+		"Number of messages by major and minor version, including the unsupported ones.",
+		"version", "supported",
+	)
+)
+
+// Processing metrics.
+var (
+	handlerRetriesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_handler_retries_total",
+		"Number of times a message has been retried after a transient error.",
+		"asset_type",
+	)
+
+	oversizedMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_oversized_messages_total",
+		"Number of messages larger than the maximum message size.",
+		"policy",
+	)
+
+	prioritizedMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_prioritized_messages_total",
+		"Number of messages applied ahead of the routine refreshes of their batch.",
+		"reason",
+	)
+
+	unknownAssetTypesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_unknown_asset_types_total",
+		"Number of messages whose asset type is not allowed.",
+		"asset_type", "policy",
+	)
+
+	schemaViolationsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_schema_violations_total",
+		"Number of asset messages whose payload does not conform to the schema.",
+		"asset_type", "policy",
+	)
+
+	quarantinedMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_quarantined_messages_total",
+		"Number of messages skipped because they are quarantined.",
+	)
+
+	consumerEventsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_consumer_events_total",
+		"Number of lifecycle events of the kafka consumer.",
+		"event",
+	)
+
+	kafkaEventsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_kafka_events_total",
+		"Number of events reported by the kafka client by kind.",
+		"kind",
+	)
+
+	sampledMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_sampled_messages_total",
+		"Number of messages copied to the sample topic or file by outcome.",
+		"outcome",
+	)
+
+	archivedMessagesTotal = metrics.NewCounter(
+		"graph_vulcan_assets_archived_messages_total",
+		"Number of messages archived to S3 by outcome.",
+		"outcome",
+	)
+
+	dailyReportsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_daily_reports_total",
+		"Number of daily reports by outcome.",
+		"outcome",
+	)
+
+	chaosFaultsTotal = metrics.NewCounter(
+		"graph_vulcan_assets_chaos_faults_total",
+		"Number of faults injected in chaos mode by kind.",
+		"kind",
+	)
+
+	partitionLagSeconds = metrics.NewGauge(
+		"graph_vulcan_assets_partition_lag_seconds",
+		"Time elapsed since the timestamp of the last processed message by partition.",
+		"partition",
+	)
+)
+
+// countInvalidMessage increments the counter corresponding to err if it is a
+// [vulcan.InvalidMessageError].
+func countInvalidMessage(err error) {
+	var merr vulcan.InvalidMessageError
+	if !errors.As(err, &merr) {
+		return
+	}
+	dailyActivity.invalid(err)
+
+	switch merr.Reason {
+	case vulcan.ErrMalformedPayload:
+		malformedPayloadsTotal.Inc(string(merr.AssetType), merr.TeamID)
+	case vulcan.ErrUnsupportedVersion:
+		unsupportedVersionsTotal.Inc(string(merr.AssetType), merr.TeamID)
+	}
+}
+
+// expvarName is the name of the expvar variable that contains the core
+// processing counters.
+const expvarName = "graph_vulcan_assets"
+
+func init() {
+	expvar.Publish(expvarName, expvar.Func(coreCounters))
+}
+
+// coreCounters returns the current value of the core processing counters. It
+// allows to scrape them using expvar in environments without Prometheus.
+func coreCounters() any {
+	c := assetsync.Status().Counters
+	return map[string]float64{
+		"processed": float64(c.Processed),
+		"errors":    float64(c.Failed),
+		"created":   float64(c.Created),
+		"expired":   float64(c.Expired),
+	}
+}
+
+// countingHandler returns a [vulcan.AssetHandler] that calls h, counts the
+// processed messages and the processing errors with
+// [assetsync.CountingHandler] and records the failures in the daily
+// report.
+func countingHandler(h vulcan.AssetHandler) vulcan.AssetHandler {
+	return assetsync.CountingHandler(func(payload vulcan.AssetPayload, isNil bool) error {
+		err := h(payload, isNil)
+		if err != nil {
+			dailyActivity.failed(payload, err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestCountInvalidMessage(t *testing.T) {
+	malformed := malformedPayloadsTotal.Value("Hostname", "team-count-invalid")
+	unsupported := unsupportedVersionsTotal.Value("Hostname", "team-count-invalid")
+
+	errs := []error{
+		vulcan.InvalidMessageError{
+			Reason:    vulcan.ErrMalformedPayload,
+			AssetType: "Hostname",
+			TeamID:    "team-count-invalid",
+		},
+		fmt.Errorf("wrapped: %w", vulcan.InvalidMessageError{
+			Reason:    vulcan.ErrUnsupportedVersion,
+			AssetType: "Hostname",
+			TeamID:    "team-count-invalid",
+		}),
+		errors.New("other error"),
+	}
+	for _, err := range errs {
+		countInvalidMessage(err)
+	}
+
+	if got := malformedPayloadsTotal.Value("Hostname", "team-count-invalid") - malformed; got != 1 {
+		t.Errorf("unexpected malformed payloads: want=1, got=%v", got)
+	}
+	if got := unsupportedVersionsTotal.Value("Hostname", "team-count-invalid") - unsupported; got != 1 {
+		t.Errorf("unexpected unsupported versions: want=1, got=%v", got)
+	}
+}
+
+func TestCountingHandler(t *testing.T) {
+	before := assetsync.Status().Counters
+
+	h := countingHandler(func(payload vulcan.AssetPayload, isNil bool) error {
+		if isNil {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+	payload := vulcan.AssetPayload{AssetType: "Hostname", Team: vulcan.Team{ID: "team-counting"}}
+	h(payload, false)
+	h(payload, true)
+
+	counters := assetsync.Status().Counters
+	if got := counters.Processed - before.Processed; got != 2 {
+		t.Errorf("unexpected processed messages: want=2, got=%v", got)
+	}
+	if got := counters.Failed - before.Failed; got != 1 {
+		t.Errorf("unexpected processing errors: want=1, got=%v", got)
+	}
+}
+
+func TestAdminMuxExpvar(t *testing.T) {
+	countingHandler(func(payload vulcan.AssetPayload, isNil bool) error { return nil })(vulcan.AssetPayload{}, false)
+
+	rec := httptest.NewRecorder()
+	adminMux(newMaintenance(""), nil, newConsumerState(), nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %v", rec.Code)
+	}
+
+	var vars struct {
+		Counters map[string]float64 `json:"graph_vulcan_assets"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("could not decode vars: %v", err)
+	}
+
+	for _, name := range []string{"processed", "errors", "created", "expired"} {
+		if _, ok := vars.Counters[name]; !ok {
+			t.Errorf("missing counter %q", name)
+		}
+	}
+	if vars.Counters["processed"] < 1 {
+		t.Errorf("unexpected processed counter: %v", vars.Counters["processed"])
+	}
+}
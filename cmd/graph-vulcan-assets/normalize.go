@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// parseNormalizeTypes parses a comma-separated list of asset types whose
+// identifiers must be normalized. The value "*" selects all the supported
+// asset types.
+func parseNormalizeTypes(s string) ([]vulcan.AssetType, error) {
+	supported := assetsync.NormalizedAssetTypes()
+	if strings.TrimSpace(s) == "*" {
+		return supported, nil
+	}
+
+	var types []vulcan.AssetType
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !containsAssetType(supported, vulcan.AssetType(t)) {
+			return nil, fmt.Errorf("unsupported asset type %q", t)
+		}
+		types = append(types, vulcan.AssetType(t))
+	}
+	return types, nil
+}
+
+// assetTypeList is a list of asset types that can be read from a
+// comma-separated list with [parseNormalizeTypes].
+type assetTypeList []vulcan.AssetType
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (l *assetTypeList) UnmarshalText(text []byte) error {
+	types, err := parseNormalizeTypes(string(text))
+	if err != nil {
+		return err
+	}
+	*l = types
+	return nil
+}
+
+// containsAssetType reports whether types contains t.
+func containsAssetType(types []vulcan.AssetType, t vulcan.AssetType) bool {
+	for _, u := range types {
+		if u == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestParseNormalizeTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       []vulcan.AssetType
+		wantNilErr bool
+	}{
+		{
+			name:       "empty",
+			s:          "",
+			want:       nil,
+			wantNilErr: true,
+		},
+		{
+			name:       "multiple types",
+			s:          "Hostname, IP ,",
+			want:       []vulcan.AssetType{"Hostname", "IP"},
+			wantNilErr: true,
+		},
+		{
+			name:       "all types",
+			s:          "*",
+			want:       []vulcan.AssetType{"DockerImage", "DomainName", "Hostname", "IP", "IPRange"},
+			wantNilErr: true,
+		},
+		{
+			name:       "unsupported type",
+			s:          "Hostname,AWSAccount",
+			want:       nil,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNormalizeTypes(tt.s)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("types mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
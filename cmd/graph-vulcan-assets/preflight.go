@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+)
+
+const (
+	preflightInitialBackoff = 1 * time.Second
+	preflightMaxBackoff     = 30 * time.Second
+)
+
+// preflightCheck is a check run before starting to process messages.
+type preflightCheck struct {
+	name  string
+	check func() error
+}
+
+// permanentError is returned by a preflight check that failed because of a
+// condition that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// preflight runs the provided checks in order. A failed check is retried
+// with exponential backoff until it succeeds, timeout expires or the context
+// is cancelled. If timeout is zero, failed checks are not retried.
+func preflight(ctx context.Context, timeout time.Duration, checks []preflightCheck) error {
+	for _, c := range checks {
+		if err := retryBackoff(ctx, timeout, c); err != nil {
+			return fmt.Errorf("preflight check %q failed: %w", c.name, err)
+		}
+		log.Info.Printf("graph-vulcan-assets: preflight check %q passed", c.name)
+	}
+	return nil
+}
+
+// retryBackoff runs the provided check until it succeeds, timeout expires or
+// the context is cancelled.
+func retryBackoff(ctx context.Context, timeout time.Duration, c preflightCheck) error {
+	deadline := time.Now().Add(timeout)
+	backoff := preflightInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := c.check()
+		if err == nil {
+			return nil
+		}
+
+		var perr permanentError
+		if errors.As(err, &perr) {
+			return perr.err
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+
+		log.Error.Printf("graph-vulcan-assets: preflight check %q failed (attempt %v), retrying in %v: %v", c.name, attempt, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > preflightMaxBackoff {
+			backoff = preflightMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		failures   int
+		permanent  bool
+		wantCalls  int
+		wantNilErr bool
+	}{
+		{
+			name:       "passed check",
+			timeout:    0,
+			failures:   0,
+			permanent:  false,
+			wantCalls:  1,
+			wantNilErr: true,
+		},
+		{
+			name:       "failed check without retries",
+			timeout:    0,
+			failures:   1,
+			permanent:  false,
+			wantCalls:  1,
+			wantNilErr: false,
+		},
+		{
+			name:       "failed check with retries",
+			timeout:    5 * time.Second,
+			failures:   1,
+			permanent:  false,
+			wantCalls:  2,
+			wantNilErr: true,
+		},
+		{
+			name:       "permanent failure",
+			timeout:    5 * time.Second,
+			failures:   1,
+			permanent:  true,
+			wantCalls:  1,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			check := preflightCheck{
+				name: "check",
+				check: func() error {
+					calls++
+					if calls <= tt.failures {
+						if tt.permanent {
+							return permanentError{errors.New("error")}
+						}
+						return errors.New("error")
+					}
+					return nil
+				},
+			}
+
+			err := preflight(context.Background(), tt.timeout, []preflightCheck{check})
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("unexpected number of calls: want=%v, got=%v", tt.wantCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// Priorities of the messages of a batch.
+const (
+	priorityRoutine = 0
+	priorityHigh    = 1
+)
+
+// messagePriority returns the function that assigns a priority to the
+// messages of a batch according to cfg. Tombstones, which expire assets,
+// and the messages of the asset types in
+// INVENTORY_BATCH_PRIORITY_ASSET_TYPES have high priority. The rest of the
+// messages are routine refreshes. If prioritization is disabled, it returns
+// nil.
+func messagePriority(cfg config) func(stream.Message) int {
+	if !cfg.InventoryBatchPriority {
+		return nil
+	}
+
+	types := stringSet(cfg.InventoryPriorityAssetTypes)
+	return func(msg stream.Message) int {
+		if msg.Value == nil {
+			prioritizedMessagesTotal.Inc("tombstone")
+			return priorityHigh
+		}
+		if types[metadataValue(msg, typeMetadataKey)] {
+			prioritizedMessagesTotal.Inc("asset_type")
+			return priorityHigh
+		}
+		return priorityRoutine
+	}
+}
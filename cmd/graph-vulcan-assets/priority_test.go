@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestMessagePriority(t *testing.T) {
+	if priority := messagePriority(config{InventoryPriorityAssetTypes: []string{"AWSAccount"}}); priority != nil {
+		t.Fatal("priority enabled without INVENTORY_BATCH_PRIORITY")
+	}
+
+	priority := messagePriority(config{
+		InventoryBatchPriority:      true,
+		InventoryPriorityAssetTypes: []string{"AWSAccount"},
+	})
+
+	newMessage := func(typ string, value []byte) stream.Message {
+		return stream.Message{
+			Key:      []byte("team-1/asset-1"),
+			Value:    value,
+			Metadata: []stream.MetadataEntry{{Key: []byte(typeMetadataKey), Value: []byte(typ)}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		msg  stream.Message
+		want int
+	}{
+		{
+			name: "tombstone",
+			msg:  newMessage("Hostname", nil),
+			want: priorityHigh,
+		},
+		{
+			name: "priority asset type",
+			msg:  newMessage("AWSAccount", []byte(`{}`)),
+			want: priorityHigh,
+		},
+		{
+			name: "routine refresh",
+			msg:  newMessage("Hostname", []byte(`{}`)),
+			want: priorityRoutine,
+		},
+		{
+			name: "missing asset type",
+			msg:  stream.Message{Key: []byte("team-1/asset-1"), Value: []byte(`{}`)},
+			want: priorityRoutine,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priority(tt.msg); got != tt.want {
+				t.Errorf("unexpected priority: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
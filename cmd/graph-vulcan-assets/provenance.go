@@ -0,0 +1,29 @@
+package main
+
+import "runtime/debug"
+
+// version is the version of the consumer. It can be set at build time with
+// -ldflags "-X main.version=<version>". If it is empty, the version is
+// taken from the build information embedded in the binary.
+var version string
+
+// consumerVersion returns the version of the consumer.
+func consumerVersion() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return "unknown"
+}
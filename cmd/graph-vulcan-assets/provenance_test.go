@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestAssetHandlerProvenance(t *testing.T) {
+	defer func(v string) { version = v }(version)
+	version = "v1.2.3"
+
+	cfg := config{
+		AWSAccountAnnotationKeys: []string{"discovery/aws/account"},
+		InventoryPageSize:        100,
+		StoreProvenance:          true,
+	}
+
+	inv := inventorytest.NewInMemory()
+
+	payload := vulcan.AssetPayload{
+		ID:         "vulcan-asset-1",
+		Team:       vulcan.Team{ID: "vulcan-team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Annotations: []vulcan.Annotation{
+			{Key: "discovery/aws/account", Value: "111111111111"},
+		},
+		Position: stream.Position{Topic: "assets", Partition: 1, Offset: 10},
+	}
+	if err := assetHandler(inv, syncConfig(inv, nil, cfg), cfg)(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	assets, err := inv.Assets("Hostname", "example.com", time.Time{}, inventory.Pagination{})
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	owners, err := inv.Owners(assets[0].ID, inventory.Pagination{})
+	if err != nil || len(owners) != 1 {
+		t.Fatalf("unexpected owners: %v, %v", owners, err)
+	}
+	parents, err := inv.Parents(assets[0].ID, inventory.Pagination{})
+	if err != nil || len(parents) != 1 {
+		t.Fatalf("unexpected parents: %v, %v", parents, err)
+	}
+
+	want := inventory.Properties{
+		props.ProvenanceSourceKey:   "vulcan",
+		props.ProvenanceVersionKey:  "v1.2.3",
+		props.ProvenancePositionKey: "assets/1@10",
+	}
+	got, err := inv.OwnerProperties(assets[0].ID, owners[0].TeamID)
+	if err != nil {
+		t.Fatalf("error getting owner properties: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("owner provenance mismatch (-want +got):\n%v", diff)
+	}
+	got, err = inv.ParentProperties(assets[0].ID, parents[0].ParentID)
+	if err != nil {
+		t.Fatalf("error getting parent properties: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parent provenance mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// identifierMetadataKey is the metadata key that contains the identifier of
+// the asset in the messages of the assets topic.
+const identifierMetadataKey = "identifier"
+
+// quarantineProcessor is a [stream.Processor] that skips the messages whose
+// key or asset identifier is quarantined. Quarantined messages are
+// considered processed, so their offsets are committed. It is meant to be
+// used as an emergency valve when a specific message blocks the pipeline.
+type quarantineProcessor struct {
+	proc        stream.Processor
+	keys        map[string]bool
+	identifiers map[string]bool
+}
+
+// newQuarantineProcessor returns a [quarantineProcessor] that processes the
+// messages of proc, skipping the ones with the provided keys or asset
+// identifiers.
+func newQuarantineProcessor(proc stream.Processor, keys, identifiers []string) quarantineProcessor {
+	return quarantineProcessor{
+		proc:        proc,
+		keys:        stringSet(keys),
+		identifiers: stringSet(identifiers),
+	}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p quarantineProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	if len(p.keys) == 0 && len(p.identifiers) == 0 {
+		return p.proc.Process(ctx, entity, h)
+	}
+
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if !p.quarantined(msg) {
+			return h(msg)
+		}
+
+		log.Info.Printf("graph-vulcan-assets: skipping quarantined message %v (key %q)", msg.Position, msg.Key)
+		quarantinedMessagesTotal.Inc()
+		return nil
+	})
+}
+
+// quarantined reports whether the key or the asset identifier of msg is
+// quarantined.
+func (p quarantineProcessor) quarantined(msg stream.Message) bool {
+	if p.keys[string(msg.Key)] {
+		return true
+	}
+	for _, e := range msg.Metadata {
+		if string(e.Key) == identifierMetadataKey && p.identifiers[string(e.Value)] {
+			return true
+		}
+	}
+	return false
+}
+
+// stringSet returns a set with the provided strings.
+func stringSet(ss []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, s := range ss {
+		set[s] = true
+	}
+	return set
+}
+
+// parseList parses a comma-separated list of strings. Empty elements are
+// ignored.
+func parseList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
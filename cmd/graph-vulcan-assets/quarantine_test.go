@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestQuarantineProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{
+			Key:      []byte("team-1/asset-1"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("identifier"), Value: []byte("a.example.com")}},
+		},
+		{
+			Key:      []byte("team-1/asset-2"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("identifier"), Value: []byte("b.example.com")}},
+		},
+		{
+			Key:      []byte("team-1/asset-3"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("identifier"), Value: []byte("c.example.com")}},
+		},
+	}
+
+	proc := newQuarantineProcessor(streamtest.NewMockProcessor(msgs), []string{"team-1/asset-1"}, []string{"c.example.com"})
+
+	before := quarantinedMessagesTotal.Value()
+
+	var got []string
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+		got = append(got, string(msg.Key))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"team-1/asset-2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("processed messages mismatch (-want +got):\n%v", diff)
+	}
+
+	if n := quarantinedMessagesTotal.Value() - before; n != 2 {
+		t.Errorf("unexpected number of quarantined messages: got: %v, want: 2", n)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	got := parseList(" team-1/asset-1, ,team-1/asset-2,")
+	want := []string{"team-1/asset-1", "team-1/asset-2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("list mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// runReconcile implements the reconcile command. It runs a full resync of
+// the Asset Inventory once.
+func runReconcile(args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "do not apply the events to the Asset Inventory, just log them or record them in the report")
+	reportFile := fs.String("report", "", "write a JSON report to this file (\"-\" for the standard output)")
+	progressFile := fs.String("progress", "", "save the progress to this file and resume from it if it exists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	var rep *report
+	if *reportFile != "" {
+		rep = newReport("reconcile", *dryRun)
+	}
+
+	h, err := commandHandler(cfg, *dryRun, rep)
+	if err != nil {
+		return err
+	}
+
+	// On interruption, the progress is saved, so the reconciliation can
+	// be resumed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = reconcile(ctx, cfg, h, *progressFile)
+	if err == nil && ctx.Err() != nil {
+		err = errors.New("reconcile interrupted")
+	}
+	return writeReport(rep, *reportFile, err)
+}
+
+// reconcileProgressInterval is the time between saves of the progress of a
+// reconciliation.
+const reconcileProgressInterval = 10 * time.Second
+
+// reconcile resyncs the Asset Inventory with the assets topic. The assets
+// topic is compacted, so it contains at least the last event of every asset.
+// The whole topic is processed with h using a throwaway consumer group, so
+// the offsets of the consumer are not modified.
+//
+// The partitions of the topic are processed by up to
+// cfg.ReconcileParallelism workers. If progressFile is not empty, the
+// progress is saved to it periodically and when processing stops, so an
+// interrupted reconciliation resumes where it left off. The file is removed
+// once the reconciliation finishes. If the context is cancelled, reconcile
+// returns nil without finishing the reconciliation.
+func reconcile(ctx context.Context, cfg config, h vulcan.AssetHandler, progressFile string) error {
+	kcfg := kafkaConfig(cfg)
+	kcfg["group.id"] = throwawayGroupID(cfg, "reconcile")
+
+	partitions, err := topicPartitions(kcfg, vulcan.AssetsEntityName)
+	if err != nil {
+		return fmt.Errorf("error getting partitions: %w", err)
+	}
+
+	prog, err := loadReconcileProgress(progressFile, vulcan.AssetsEntityName)
+	if err != nil {
+		return fmt.Errorf("error loading reconcile progress: %w", err)
+	}
+
+	pending := prog.pending(partitions)
+	if len(pending) < len(partitions) {
+		log.Info.Printf("graph-vulcan-assets: resuming reconcile from %v, %v/%v partitions pending", progressFile, len(pending), len(partitions))
+	}
+
+	log.Info.Println("graph-vulcan-assets: reconciling assets")
+
+	start := time.Now()
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	saved := make(chan struct{})
+	go func() {
+		defer close(saved)
+		if progressFile != "" {
+			saveReconcileProgress(wctx, prog, progressFile)
+		}
+	}()
+
+	work := make(chan int32)
+	errs := make(chan error, len(pending))
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.ReconcileParallelism && i < len(pending); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partition := range work {
+				if err := reconcilePartition(wctx, kcfg, cfg, h, prog, partition); err != nil {
+					errs <- fmt.Errorf("error processing partition %v: %w", partition, err)
+					// Stop the other workers, so the
+					// progress is saved as soon as
+					// possible.
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+loop:
+	for _, partition := range pending {
+		select {
+		case work <- partition:
+		case <-wctx.Done():
+			break loop
+		}
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+
+	cancel()
+	<-saved
+
+	var perr error
+	for err := range errs {
+		if perr == nil {
+			perr = err
+		}
+	}
+
+	done := perr == nil && len(prog.pending(partitions)) == 0
+	if progressFile != "" {
+		if done {
+			if err := os.Remove(progressFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				log.Error.Printf("graph-vulcan-assets: could not remove reconcile progress: %v", err)
+			}
+		} else if err := prog.save(progressFile); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not save reconcile progress: %v", err)
+		}
+	}
+
+	if perr != nil {
+		return fmt.Errorf("error processing assets: %w", perr)
+	}
+
+	if !done {
+		log.Info.Printf("graph-vulcan-assets: reconcile interrupted after %v", time.Since(start))
+		return nil
+	}
+
+	log.Info.Printf("graph-vulcan-assets: reconcile finished in %v", time.Since(start))
+
+	return nil
+}
+
+// reconcilePartition processes the provided partition of the assets topic
+// with h, starting at the offset recorded in prog, and records its progress.
+func reconcilePartition(ctx context.Context, kcfg map[string]any, cfg config, h vulcan.AssetHandler, prog *reconcileProgress, partition int32) error {
+	window := kafka.Window{
+		FromOffset: 0,
+		Partitions: []int32{partition},
+	}
+	if offset, ok := prog.offset(partition); ok {
+		window.Offsets = map[int32]int64{partition: offset}
+	}
+
+	proc, err := kafka.NewReplayProcessor(kcfg, window)
+	if err != nil {
+		return fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	pproc := progressProcessor{proc: proc, prog: prog}
+	qproc := newQuarantineProcessor(versionProcessor{pproc}, cfg.QuarantineKeys, cfg.QuarantineIdentifiers)
+	tproc := newAssetTypeProcessor(qproc, cfg.AllowedAssetTypes, cfg.UnknownAssetTypePolicy, cfg.DLQTopic, nil)
+	sproc := newSchemaProcessor(tproc, cfg.PayloadSchemaPolicy, cfg.DLQTopic, nil)
+	if err := vulcan.NewClient(sproc).ProcessAssets(ctx, h); err != nil {
+		return err
+	}
+
+	// The processor returns nil if the context is cancelled before
+	// processing all the messages.
+	if ctx.Err() == nil {
+		prog.setDone(partition)
+	}
+	return nil
+}
+
+// topicPartitions returns the partitions of the provided topic.
+func topicPartitions(kcfg map[string]any, topic string) ([]int32, error) {
+	proc, err := kafka.NewReplayProcessor(kcfg, kafka.Window{})
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	return proc.Partitions(topic)
+}
+
+// saveReconcileProgress saves prog to the provided file every
+// [reconcileProgressInterval]. It blocks the calling goroutine until the
+// provided context is cancelled.
+func saveReconcileProgress(ctx context.Context, prog *reconcileProgress, name string) {
+	t := time.NewTicker(reconcileProgressInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := prog.save(name); err != nil {
+				log.Error.Printf("graph-vulcan-assets: could not save reconcile progress: %v", err)
+			}
+		}
+	}
+}
+
+// reconcileProgress is the progress of a reconciliation. It is safe for
+// concurrent use.
+type reconcileProgress struct {
+	mu sync.Mutex
+
+	// Topic is the reconciled topic.
+	Topic string `json:"topic"`
+
+	// Offsets contains the offset of the next message to be processed in
+	// the partitions that have been started.
+	Offsets map[int32]int64 `json:"offsets"`
+
+	// Done contains the partitions that have been completely processed.
+	Done map[int32]bool `json:"done"`
+}
+
+// newReconcileProgress returns the progress of a reconciliation of the
+// provided topic that has not started yet.
+func newReconcileProgress(topic string) *reconcileProgress {
+	return &reconcileProgress{
+		Topic:   topic,
+		Offsets: make(map[int32]int64),
+		Done:    make(map[int32]bool),
+	}
+}
+
+// loadReconcileProgress reads the progress of a reconciliation of the
+// provided topic from the provided file. If name is empty or the file does
+// not exist, the reconciliation has not started yet.
+func loadReconcileProgress(name, topic string) (*reconcileProgress, error) {
+	if name == "" {
+		return newReconcileProgress(topic), nil
+	}
+
+	b, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newReconcileProgress(topic), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+
+	prog := newReconcileProgress(topic)
+	if err := json.Unmarshal(b, prog); err != nil {
+		return nil, fmt.Errorf("could not decode file: %w", err)
+	}
+	if prog.Topic != topic {
+		return nil, fmt.Errorf("progress of topic %q, expected %q", prog.Topic, topic)
+	}
+	if prog.Offsets == nil {
+		prog.Offsets = make(map[int32]int64)
+	}
+	if prog.Done == nil {
+		prog.Done = make(map[int32]bool)
+	}
+	return prog, nil
+}
+
+// save writes the progress to the provided file. The file is replaced
+// atomically, so it is not corrupted if the process is killed while writing
+// it.
+func (prog *reconcileProgress) save(name string) error {
+	prog.mu.Lock()
+	b, err := json.MarshalIndent(prog, "", "  ")
+	prog.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("could not encode progress: %w", err)
+	}
+
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("could not write file: %w", err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return fmt.Errorf("could not rename file: %w", err)
+	}
+	return nil
+}
+
+// advance records that the message at the provided position has been
+// processed.
+func (prog *reconcileProgress) advance(pos stream.Position) {
+	prog.mu.Lock()
+	defer prog.mu.Unlock()
+
+	prog.Offsets[pos.Partition] = pos.Offset + 1
+}
+
+// offset returns the offset of the next message to be processed in the
+// provided partition, if it has been started.
+func (prog *reconcileProgress) offset(partition int32) (int64, bool) {
+	prog.mu.Lock()
+	defer prog.mu.Unlock()
+
+	offset, ok := prog.Offsets[partition]
+	return offset, ok
+}
+
+// setDone records that the provided partition has been completely
+// processed.
+func (prog *reconcileProgress) setDone(partition int32) {
+	prog.mu.Lock()
+	defer prog.mu.Unlock()
+
+	prog.Done[partition] = true
+}
+
+// pending returns the provided partitions that have not been completely
+// processed.
+func (prog *reconcileProgress) pending(partitions []int32) []int32 {
+	prog.mu.Lock()
+	defer prog.mu.Unlock()
+
+	var pending []int32
+	for _, p := range partitions {
+		if !prog.Done[p] {
+			pending = append(pending, p)
+		}
+	}
+	return pending
+}
+
+// progressProcessor is a [stream.Processor] that records in a
+// [reconcileProgress] the messages that have been processed.
+type progressProcessor struct {
+	proc stream.Processor
+	prog *reconcileProgress
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p progressProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if err := h(msg); err != nil {
+			return err
+		}
+		p.prog.advance(msg.Position)
+		return nil
+	})
+}
+
+// throwawayGroupID returns a unique kafka consumer group ID derived from the
+// configured one.
+func throwawayGroupID(cfg config, purpose string) string {
+	return cfg.KafkaGroupID + "-" + purpose + "-" + strconv.FormatInt(time.Now().UnixNano(), 16)
+}
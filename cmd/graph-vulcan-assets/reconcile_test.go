@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestReconcileProgressResume(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "progress.json")
+
+	prog, err := loadReconcileProgress(name, "assets")
+	if err != nil {
+		t.Fatalf("error loading missing progress: %v", err)
+	}
+	if diff := cmp.Diff([]int32{0, 1, 2}, prog.pending([]int32{0, 1, 2})); diff != "" {
+		t.Errorf("pending partitions mismatch (-want +got):\n%v", diff)
+	}
+
+	prog.advance(stream.Position{Topic: "assets", Partition: 0, Offset: 9})
+	prog.setDone(0)
+	prog.advance(stream.Position{Topic: "assets", Partition: 1, Offset: 4})
+	if err := prog.save(name); err != nil {
+		t.Fatalf("error saving progress: %v", err)
+	}
+
+	got, err := loadReconcileProgress(name, "assets")
+	if err != nil {
+		t.Fatalf("error loading progress: %v", err)
+	}
+	if diff := cmp.Diff(map[int32]int64{0: 10, 1: 5}, got.Offsets); diff != "" {
+		t.Errorf("offsets mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff(map[int32]bool{0: true}, got.Done); diff != "" {
+		t.Errorf("done partitions mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]int32{1, 2}, got.pending([]int32{0, 1, 2})); diff != "" {
+		t.Errorf("pending partitions mismatch (-want +got):\n%v", diff)
+	}
+
+	if offset, ok := got.offset(1); !ok || offset != 5 {
+		t.Errorf("unexpected offset of partition 1: %v (ok=%v)", offset, ok)
+	}
+	if _, ok := got.offset(2); ok {
+		t.Error("partition 2 should not have an offset")
+	}
+}
+
+func TestLoadReconcileProgressError(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantNilErr bool
+	}{
+		{
+			name:       "valid",
+			data:       `{"topic": "assets", "offsets": {"0": 10}}`,
+			wantNilErr: true,
+		},
+		{
+			name:       "other topic",
+			data:       `{"topic": "teams", "offsets": {"0": 10}}`,
+			wantNilErr: false,
+		},
+		{
+			name:       "malformed",
+			data:       `{"topic": "assets"`,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "progress.json")
+			if err := os.WriteFile(name, []byte(tt.data), 0o644); err != nil {
+				t.Fatalf("error writing file: %v", err)
+			}
+
+			_, err := loadReconcileProgress(name, "assets")
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProgressProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{Key: []byte("team-1/asset-1")},
+		{Key: []byte("team-1/asset-2")},
+		{Key: []byte("team-1/asset-3")},
+	}
+
+	prog := newReconcileProgress("assets")
+	proc := progressProcessor{proc: streamtest.NewMockProcessor(msgs), prog: prog}
+
+	errHandler := errors.New("handler error")
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+		if string(msg.Key) == "team-1/asset-3" {
+			return errHandler
+		}
+		return nil
+	})
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if offset, ok := prog.offset(0); !ok || offset != 2 {
+		t.Errorf("unexpected offset: %v (ok=%v)", offset, ok)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// redactedValue replaces the values of redacted annotations.
+const redactedValue = "[REDACTED]"
+
+// redactPayload returns a copy of the provided payload where the value of
+// the annotations whose key matches any of the provided patterns is masked.
+// Patterns follow the syntax of [path.Match] and are matched case
+// insensitively. The returned payload is meant to be logged.
+func redactPayload(payload vulcan.AssetPayload, patterns []string) vulcan.AssetPayload {
+	if len(payload.Annotations) == 0 || len(patterns) == 0 {
+		return payload
+	}
+
+	annotations := make([]vulcan.Annotation, len(payload.Annotations))
+	for i, a := range payload.Annotations {
+		if redactedKey(a.Key, patterns) {
+			a.Value = redactedValue
+		}
+		annotations[i] = a
+	}
+	payload.Annotations = annotations
+
+	return payload
+}
+
+// redactedKey returns true if key matches any of the provided patterns.
+func redactedKey(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRedactPatterns parses a comma-separated list of annotation key
+// patterns.
+func parseRedactPatterns(s string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// redactPatternList is a list of annotation key patterns that can be read
+// from a comma-separated list with [parseRedactPatterns].
+type redactPatternList []string
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (l *redactPatternList) UnmarshalText(text []byte) error {
+	patterns, err := parseRedactPatterns(string(text))
+	if err != nil {
+		return err
+	}
+	*l = patterns
+	return nil
+}
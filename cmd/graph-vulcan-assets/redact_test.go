@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestRedactPayload(t *testing.T) {
+	payload := vulcan.AssetPayload{
+		Identifier: "www.example.com",
+		Annotations: []vulcan.Annotation{
+			{Key: "discovery/aws/account", Value: "000000000000"},
+			{Key: "owner/email", Value: "user@example.com"},
+			{Key: "API_TOKEN", Value: "s3cr3t"},
+		},
+	}
+
+	want := vulcan.AssetPayload{
+		Identifier: "www.example.com",
+		Annotations: []vulcan.Annotation{
+			{Key: "discovery/aws/account", Value: "000000000000"},
+			{Key: "owner/email", Value: redactedValue},
+			{Key: "API_TOKEN", Value: redactedValue},
+		},
+	}
+
+	got := redactPayload(payload, []string{"*/email", "*token*"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payload mismatch (-want +got):\n%v", diff)
+	}
+
+	if payload.Annotations[1].Value != "user@example.com" {
+		t.Errorf("original payload has been modified")
+	}
+}
+
+func TestParseRedactPatterns(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       []string
+		wantNilErr bool
+	}{
+		{
+			name:       "empty",
+			s:          "",
+			want:       nil,
+			wantNilErr: true,
+		},
+		{
+			name:       "multiple patterns",
+			s:          "*password*, */email ,",
+			want:       []string{"*password*", "*/email"},
+			wantNilErr: true,
+		},
+		{
+			name:       "malformed pattern",
+			s:          "[",
+			want:       nil,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRedactPatterns(tt.s)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("patterns mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// dlqCommands contains the subcommands of the dlq command.
+var dlqCommands = map[string]func(args []string) error{
+	"redrive": runDLQRedrive,
+}
+
+// runDLQ implements the dlq command. It dispatches the subcommand specified
+// in the first argument.
+func runDLQ(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing dlq command")
+	}
+	cmd, ok := dlqCommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown dlq command %q", args[0])
+	}
+	return cmd(args[1:])
+}
+
+// errTruncatedRecord is returned by [redriveRecord] when the value of the
+// record was truncated when it was sent to the dead letter queue.
+var errTruncatedRecord = errors.New("truncated record")
+
+// runDLQRedrive implements the dlq redrive command. It reads the records of
+// the dead letter queue and republishes them to their original topic or
+// processes them directly.
+func runDLQRedrive(args []string) error {
+	opts, err := parseRedriveFlags(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	if cfg.DLQTopic == "" {
+		return errors.New("DLQ_TOPIC is not set")
+	}
+
+	// Use a throwaway consumer group, so the dead letter queue can be
+	// redriven again if needed.
+	kcfg := kafkaConfig(cfg)
+	kcfg["group.id"] = throwawayGroupID(cfg, "redrive")
+
+	proc, err := kafka.NewReplayProcessor(kcfg, opts.window)
+	if err != nil {
+		return fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	rproc := redriveProcessor{proc: proc, topic: cfg.DLQTopic, opts: opts}
+
+	log.Info.Printf("graph-vulcan-assets: redriving %v (window=%+v process=%v dryRun=%v)", cfg.DLQTopic, opts.window, opts.process, opts.dryRun)
+
+	if opts.process {
+		return processRedrive(cfg, rproc, opts)
+	}
+
+	var prod dlqProducer
+	if !opts.dryRun {
+		kprod, err := kafka.NewProducer(producerConfig(cfg))
+		if err != nil {
+			return fmt.Errorf("error creating kafka producer: %w", err)
+		}
+		defer kprod.Close()
+		prod = kprod
+	}
+
+	n, err := republish(context.Background(), rproc, prod, opts.topic)
+	if err != nil {
+		return fmt.Errorf("error republishing records: %w", err)
+	}
+
+	log.Info.Printf("graph-vulcan-assets: redrive finished: %v records republished", n)
+
+	return nil
+}
+
+// processRedrive applies the asset events of the records provided by rproc
+// to the Asset Inventory.
+func processRedrive(cfg config, rproc redriveProcessor, opts redriveOptions) error {
+	tproc := newAssetTypeProcessor(versionProcessor{rproc}, cfg.AllowedAssetTypes, cfg.UnknownAssetTypePolicy, cfg.DLQTopic, nil)
+	vcli := vulcan.NewClient(newSchemaProcessor(tproc, cfg.PayloadSchemaPolicy, cfg.DLQTopic, nil))
+
+	var rep *report
+	if opts.report != "" {
+		rep = newReport("redrive", opts.dryRun)
+	}
+
+	h, err := commandHandler(cfg, opts.dryRun, rep)
+	if err != nil {
+		return err
+	}
+
+	if err := vcli.ProcessAssets(context.Background(), h); err != nil {
+		return writeReport(rep, opts.report, fmt.Errorf("error processing assets: %w", err))
+	}
+
+	log.Info.Println("graph-vulcan-assets: redrive finished")
+
+	return writeReport(rep, opts.report, nil)
+}
+
+// republish produces the records provided by rproc to their original topic
+// or, if topic is not empty, to topic. If prod is nil, the records are just
+// logged. It returns the number of republished records.
+func republish(ctx context.Context, rproc stream.Processor, prod dlqProducer, topic string) (int, error) {
+	var n int
+	err := rproc.Process(ctx, "", func(msg stream.Message) error {
+		dst := topic
+		if dst == "" {
+			dst = msg.Position.Topic
+		}
+
+		if prod == nil {
+			log.Info.Printf("graph-vulcan-assets: dry-run: republish record %v (key %q) to %v", msg.Position, msg.Key, dst)
+			n++
+			return nil
+		}
+
+		if err := prod.ProduceSync(ctx, dst, msg); err != nil {
+			return fmt.Errorf("could not republish record %v: %w", msg.Position, err)
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// redriveProcessor is a [stream.Processor] that processes the records of
+// the dead letter queue as the messages they were created from. The
+// records that do not match the options of the command are skipped.
+type redriveProcessor struct {
+	proc  stream.Processor
+	topic string
+	opts  redriveOptions
+}
+
+// Process processes the records of the dead letter queue by calling h. The
+// entity is ignored.
+func (p redriveProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, p.topic, func(rec stream.Message) error {
+		msg, ok, err := redriveRecord(rec, p.opts.reason, p.opts.patches)
+		if err != nil {
+			log.Error.Printf("graph-vulcan-assets: skipping record %v (key %q): %v", rec.Position, rec.Key, err)
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+		return h(msg)
+	})
+}
+
+// redriveRecord returns the message a dead letter queue record was created
+// from. The dlq metadata entries are removed and the position is set to the
+// original position of the message. The patches are applied to the value
+// of the message. If reason is not empty and the record was sent to the
+// dead letter queue for another reason, it returns false.
+func redriveRecord(rec stream.Message, reason string, patches []fieldPatch) (stream.Message, bool, error) {
+	if reason != "" && metadataValue(rec, dlqReasonKey) != reason {
+		return stream.Message{}, false, nil
+	}
+
+	if size := metadataValue(rec, dlqSizeKey); size != "" && size != strconv.Itoa(len(rec.Value)) {
+		return stream.Message{}, false, fmt.Errorf("%w: %v of %v bytes", errTruncatedRecord, len(rec.Value), size)
+	}
+
+	msg := stream.Message{
+		Key:   rec.Key,
+		Value: rec.Value,
+		Position: stream.Position{
+			Topic: metadataValue(rec, dlqTopicKey),
+		},
+		Timestamp: rec.Timestamp,
+	}
+	if msg.Position.Topic == "" {
+		msg.Position.Topic = vulcan.AssetsEntityName
+	}
+	if partition, err := strconv.ParseInt(metadataValue(rec, dlqPartitionKey), 10, 32); err == nil {
+		msg.Position.Partition = int32(partition)
+	}
+	if offset, err := strconv.ParseInt(metadataValue(rec, dlqOffsetKey), 10, 64); err == nil {
+		msg.Position.Offset = offset
+	}
+
+	for _, e := range rec.Metadata {
+		if strings.HasPrefix(string(e.Key), "dlq-") {
+			continue
+		}
+		msg.Metadata = append(msg.Metadata, e)
+	}
+
+	if len(patches) > 0 && msg.Value != nil {
+		value, err := patchValue(msg.Value, patches)
+		if err != nil {
+			return stream.Message{}, false, fmt.Errorf("could not patch value: %w", err)
+		}
+		msg.Value = value
+	}
+
+	return msg, true, nil
+}
+
+// fieldPatch sets a field of a JSON payload.
+type fieldPatch struct {
+	// path is the path of the field. Every element is the name of a
+	// field of the parent object.
+	path []string
+
+	// value is the JSON value of the field.
+	value json.RawMessage
+}
+
+// parseFieldPatch parses a patch with the format <field>=<value>. The
+// nested fields are separated by dots. If value is not a valid JSON value,
+// it is set as a string.
+func parseFieldPatch(s string) (fieldPatch, error) {
+	field, value, ok := strings.Cut(s, "=")
+	if !ok || field == "" {
+		return fieldPatch{}, fmt.Errorf("invalid patch %q", s)
+	}
+
+	path := strings.Split(field, ".")
+	for _, name := range path {
+		if name == "" {
+			return fieldPatch{}, fmt.Errorf("invalid field %q", field)
+		}
+	}
+
+	raw := json.RawMessage(value)
+	if !json.Valid(raw) {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return fieldPatch{}, fmt.Errorf("invalid value %q: %w", value, err)
+		}
+	}
+
+	return fieldPatch{path: path, value: raw}, nil
+}
+
+// apply sets the field of the patch in obj. The missing parent objects are
+// created.
+func (p fieldPatch) apply(obj map[string]json.RawMessage) error {
+	name := p.path[0]
+	if len(p.path) == 1 {
+		obj[name] = p.value
+		return nil
+	}
+
+	child := make(map[string]json.RawMessage)
+	if raw, ok := obj[name]; ok && !bytes.Equal(raw, []byte("null")) {
+		if err := json.Unmarshal(raw, &child); err != nil {
+			return fmt.Errorf("field %v is not an object", name)
+		}
+	}
+	if err := (fieldPatch{path: p.path[1:], value: p.value}).apply(child); err != nil {
+		return fmt.Errorf("%v.%w", name, err)
+	}
+
+	raw, err := json.Marshal(child)
+	if err != nil {
+		return fmt.Errorf("could not marshal field %v: %w", name, err)
+	}
+	obj[name] = raw
+	return nil
+}
+
+// patchValue applies the provided patches to the JSON object encoded in
+// value. The fields not modified by the patches are preserved.
+func patchValue(value []byte, patches []fieldPatch) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(value, &obj); err != nil {
+		return nil, fmt.Errorf("could not unmarshal payload: %w", err)
+	}
+	if obj == nil {
+		return nil, errors.New("payload is not an object")
+	}
+
+	for _, p := range patches {
+		if err := p.apply(obj); err != nil {
+			return nil, fmt.Errorf("could not set field %v: %w", strings.Join(p.path, "."), err)
+		}
+	}
+
+	value, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal payload: %w", err)
+	}
+	return value, nil
+}
+
+// redriveOptions are the command line options of the dlq redrive command.
+type redriveOptions struct {
+	// window is the window of the dead letter queue to redrive.
+	window kafka.Window
+
+	// reason is the reason of the records to redrive. If empty, all the
+	// records are redriven.
+	reason string
+
+	// patches are applied to the payload of the records.
+	patches []fieldPatch
+
+	// topic is the topic the records are republished to. If empty,
+	// they are republished to their original topic.
+	topic string
+
+	// process makes the command apply the records to the Asset
+	// Inventory instead of republishing them.
+	process bool
+
+	// dryRun disables the writes to kafka and the Asset Inventory.
+	dryRun bool
+
+	// report is the name of the file where the JSON report is
+	// written. If empty, no report is written. It is only used with
+	// process.
+	report string
+}
+
+// parseRedriveFlags parses the arguments of the dlq redrive command.
+func parseRedriveFlags(args []string) (redriveOptions, error) {
+	var opts redriveOptions
+
+	fs := flag.NewFlagSet("dlq redrive", flag.ContinueOnError)
+	fromTimestamp := fs.String("from-timestamp", "", "redrive records produced after this RFC3339 timestamp")
+	fromOffset := fs.Int64("from-offset", -1, "redrive records starting at this offset in every partition (default: the beginning of the partitions)")
+	until := fs.String("until", "", "stop redriving at this RFC3339 timestamp (default: current end of the topic)")
+	fs.StringVar(&opts.reason, "reason", "", "only redrive the records with this dlq-reason (for instance, \"oversized\" or \"unknown_asset_type\")")
+	fs.Func("set", "set the `field=value` of the payloads before redriving them (nested fields are separated by dots, can be repeated)", func(s string) error {
+		p, err := parseFieldPatch(s)
+		if err != nil {
+			return err
+		}
+		opts.patches = append(opts.patches, p)
+		return nil
+	})
+	fs.StringVar(&opts.topic, "topic", "", "republish the records to this topic (default: the original topic of every record)")
+	fs.BoolVar(&opts.process, "process", false, "apply the records to the Asset Inventory instead of republishing them")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "do not republish or apply the records, just log them or record them in the report")
+	fs.StringVar(&opts.report, "report", "", "write a JSON report to this file (\"-\" for the standard output), only with -process")
+	if err := fs.Parse(args); err != nil {
+		return redriveOptions{}, err
+	}
+
+	if *fromTimestamp != "" && *fromOffset >= 0 {
+		return redriveOptions{}, errors.New("only one of -from-timestamp or -from-offset can be specified")
+	}
+	if opts.process && opts.topic != "" {
+		return redriveOptions{}, errors.New("-topic cannot be used with -process")
+	}
+	if !opts.process && opts.report != "" {
+		return redriveOptions{}, errors.New("-report can only be used with -process")
+	}
+
+	var err error
+	opts.window.FromOffset = *fromOffset
+	if *fromTimestamp != "" {
+		opts.window.FromTimestamp, err = time.Parse(time.RFC3339, *fromTimestamp)
+		if err != nil {
+			return redriveOptions{}, fmt.Errorf("invalid -from-timestamp: %w", err)
+		}
+	} else if *fromOffset < 0 {
+		opts.window.FromOffset = 0
+	}
+
+	if *until != "" {
+		opts.window.Until, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return redriveOptions{}, fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+
+	return opts, nil
+}
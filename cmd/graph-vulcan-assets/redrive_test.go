@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestRedriveRecord(t *testing.T) {
+	msg := stream.Message{
+		Key:   []byte("team/asset"),
+		Value: []byte(`{"AssetType":"Hostname","Identifier":"example.com","Team":{"ID":"team-1"}}`),
+		Metadata: []stream.MetadataEntry{
+			{Key: []byte("version"), Value: []byte("0.1.2")},
+		},
+		Position: stream.Position{Topic: "assets-v0", Partition: 2, Offset: 42},
+	}
+
+	tests := []struct {
+		name       string
+		rec        stream.Message
+		reason     string
+		patches    []fieldPatch
+		wantMsg    stream.Message
+		wantOK     bool
+		wantNilErr bool
+	}{
+		{
+			name: "unknown asset type",
+			rec:  dlqRecord(msg, dlqReasonUnknownType, 0),
+			wantMsg: stream.Message{
+				Key:      msg.Key,
+				Value:    msg.Value,
+				Metadata: msg.Metadata,
+				Position: msg.Position,
+			},
+			wantOK:     true,
+			wantNilErr: true,
+		},
+		{
+			name:   "other reason",
+			rec:    dlqRecord(msg, dlqReasonUnknownType, 0),
+			reason: dlqReasonOversized,
+			wantOK: false,
+			// The record is skipped without error.
+			wantNilErr: true,
+		},
+		{
+			name: "patched",
+			rec:  dlqRecord(msg, dlqReasonUnknownType, 0),
+			patches: []fieldPatch{
+				{path: []string{"AssetType"}, value: json.RawMessage(`"DomainName"`)},
+				{path: []string{"Team", "Name"}, value: json.RawMessage(`"Team 1"`)},
+			},
+			wantMsg: stream.Message{
+				Key:      msg.Key,
+				Value:    []byte(`{"AssetType":"DomainName","Identifier":"example.com","Team":{"ID":"team-1","Name":"Team 1"}}`),
+				Metadata: msg.Metadata,
+				Position: msg.Position,
+			},
+			wantOK:     true,
+			wantNilErr: true,
+		},
+		{
+			name:       "truncated",
+			rec:        dlqRecord(msg, dlqReasonOversized, 10),
+			wantOK:     false,
+			wantNilErr: false,
+		},
+		{
+			name: "patch tombstone",
+			rec:  dlqRecord(stream.Message{Key: msg.Key, Position: msg.Position}, dlqReasonUnknownType, 0),
+			patches: []fieldPatch{
+				{path: []string{"AssetType"}, value: json.RawMessage(`"DomainName"`)},
+			},
+			wantMsg: stream.Message{
+				Key:      msg.Key,
+				Position: msg.Position,
+			},
+			wantOK:     true,
+			wantNilErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := redriveRecord(tt.rec, tt.reason, tt.patches)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("unexpected ok: want=%v got=%v", tt.wantOK, ok)
+			}
+			if diff := cmp.Diff(tt.wantMsg, got); diff != "" {
+				t.Errorf("message mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseFieldPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       fieldPatch
+		wantNilErr bool
+	}{
+		{
+			name:       "string",
+			s:          "Team.ID=team-1",
+			want:       fieldPatch{path: []string{"Team", "ID"}, value: json.RawMessage(`"team-1"`)},
+			wantNilErr: true,
+		},
+		{
+			name:       "JSON value",
+			s:          `Annotations=[]`,
+			want:       fieldPatch{path: []string{"Annotations"}, value: json.RawMessage(`[]`)},
+			wantNilErr: true,
+		},
+		{
+			name:       "missing value",
+			s:          "AssetType",
+			want:       fieldPatch{},
+			wantNilErr: false,
+		},
+		{
+			name:       "empty field",
+			s:          "Team..ID=team-1",
+			want:       fieldPatch{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFieldPatch(tt.s)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(fieldPatch{})); diff != "" {
+				t.Errorf("patch mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPatchValueError(t *testing.T) {
+	patches := []fieldPatch{{path: []string{"Team", "ID"}, value: json.RawMessage(`"team-1"`)}}
+
+	for _, value := range []string{`not json`, `null`, `{"Team":"team-1"}`} {
+		if _, err := patchValue([]byte(value), patches); err == nil {
+			t.Errorf("expected error patching %q", value)
+		}
+	}
+}
+
+func TestRepublish(t *testing.T) {
+	msg := stream.Message{
+		Key:      []byte("team/asset"),
+		Value:    []byte(`{}`),
+		Position: stream.Position{Topic: "assets-v0", Partition: 1, Offset: 7},
+	}
+	recs := []stream.Message{
+		dlqRecord(msg, dlqReasonUnknownType, 0),
+		dlqRecord(msg, dlqReasonOversized, 1),
+	}
+
+	tests := []struct {
+		name       string
+		topic      string
+		prodErr    error
+		wantN      int
+		wantTopics []string
+		wantNilErr bool
+	}{
+		{
+			name:       "original topic",
+			wantN:      1,
+			wantTopics: []string{"assets-v0"},
+			wantNilErr: true,
+		},
+		{
+			name:       "topic",
+			topic:      "assets-v0-redrive",
+			wantN:      1,
+			wantTopics: []string{"assets-v0-redrive"},
+			wantNilErr: true,
+		},
+		{
+			name:       "producer error",
+			prodErr:    errDLQ,
+			wantN:      0,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prod := &fakeDLQProducer{err: tt.prodErr}
+			rproc := redriveProcessor{proc: streamtest.NewMockProcessor(recs), topic: "assets-v0-dlq"}
+
+			n, err := republish(context.Background(), rproc, prod, tt.topic)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if tt.prodErr != nil && !errors.Is(err, tt.prodErr) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.prodErr, err)
+			}
+			if n != tt.wantN {
+				t.Errorf("unexpected number of records: want=%v got=%v", tt.wantN, n)
+			}
+			if diff := cmp.Diff(tt.wantTopics, prod.topics); diff != "" {
+				t.Errorf("topics mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseRedriveFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantOpts   redriveOptions
+		wantNilErr bool
+	}{
+		{
+			name:       "defaults",
+			args:       nil,
+			wantOpts:   redriveOptions{window: kafka.Window{FromOffset: 0}},
+			wantNilErr: true,
+		},
+		{
+			name: "process",
+			args: []string{"-from-timestamp", "2022-01-01T00:00:00Z", "-reason", "unknown_asset_type", "-set", "AssetType=Hostname", "-process", "-dry-run", "-report", "-"},
+			wantOpts: redriveOptions{
+				window: kafka.Window{
+					FromTimestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					FromOffset:    -1,
+				},
+				reason:  "unknown_asset_type",
+				patches: []fieldPatch{{path: []string{"AssetType"}, value: json.RawMessage(`"Hostname"`)}},
+				process: true,
+				dryRun:  true,
+				report:  "-",
+			},
+			wantNilErr: true,
+		},
+		{
+			name:       "offset and timestamp",
+			args:       []string{"-from-offset", "10", "-from-timestamp", "2022-01-01T00:00:00Z"},
+			wantOpts:   redriveOptions{},
+			wantNilErr: false,
+		},
+		{
+			name:       "topic and process",
+			args:       []string{"-topic", "assets-v0", "-process"},
+			wantOpts:   redriveOptions{},
+			wantNilErr: false,
+		},
+		{
+			name:       "report without process",
+			args:       []string{"-report", "-"},
+			wantOpts:   redriveOptions{},
+			wantNilErr: false,
+		},
+		{
+			name:       "invalid patch",
+			args:       []string{"-set", "AssetType"},
+			wantOpts:   redriveOptions{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseRedriveFlags(tt.args)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if diff := cmp.Diff(tt.wantOpts, opts, cmp.AllowUnexported(redriveOptions{}, fieldPatch{})); diff != "" {
+				t.Errorf("options mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
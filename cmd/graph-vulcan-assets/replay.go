@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// runReplay implements the replay command. It processes a bounded window of
+// the assets topic using a throwaway consumer group.
+func runReplay(args []string) error {
+	opts, err := parseReplayFlags(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := setupLog(cfg); err != nil {
+		return err
+	}
+
+	// Use a throwaway consumer group, so the offsets of the consumer
+	// group used by the consumer are not modified.
+	kcfg := kafkaConfig(cfg)
+	kcfg["group.id"] = throwawayGroupID(cfg, "replay")
+
+	proc, err := kafka.NewReplayProcessor(kcfg, opts.window)
+	if err != nil {
+		return fmt.Errorf("error creating kafka processor: %w", err)
+	}
+	defer proc.Close()
+
+	tproc := newAssetTypeProcessor(versionProcessor{proc}, cfg.AllowedAssetTypes, cfg.UnknownAssetTypePolicy, cfg.DLQTopic, nil)
+	vcli := vulcan.NewClient(newSchemaProcessor(tproc, cfg.PayloadSchemaPolicy, cfg.DLQTopic, nil))
+
+	var rep *report
+	if opts.report != "" {
+		rep = newReport("replay", opts.dryRun)
+	}
+
+	h, err := commandHandler(cfg, opts.dryRun, rep)
+	if err != nil {
+		return err
+	}
+
+	log.Info.Printf("graph-vulcan-assets: replaying assets (window=%+v dryRun=%v)", opts.window, opts.dryRun)
+
+	if err := vcli.ProcessAssets(context.Background(), h); err != nil {
+		return writeReport(rep, opts.report, fmt.Errorf("error processing assets: %w", err))
+	}
+
+	log.Info.Println("graph-vulcan-assets: replay finished")
+
+	return writeReport(rep, opts.report, nil)
+}
+
+// replayOptions are the command line options of the replay command.
+type replayOptions struct {
+	// window is the window of the assets topic to replay.
+	window kafka.Window
+
+	// dryRun disables the writes to the Asset Inventory.
+	dryRun bool
+
+	// report is the name of the file where the JSON report is
+	// written. If empty, no report is written.
+	report string
+}
+
+// parseReplayFlags parses the arguments of the replay command.
+func parseReplayFlags(args []string) (replayOptions, error) {
+	var opts replayOptions
+
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fromTimestamp := fs.String("from-timestamp", "", "replay messages produced after this RFC3339 timestamp")
+	fromOffset := fs.Int64("from-offset", -1, "replay messages starting at this offset in every partition")
+	until := fs.String("until", "", "stop replaying at this RFC3339 timestamp (default: current end of the topic)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "do not apply the events to the Asset Inventory, just log them or record them in the report")
+	fs.StringVar(&opts.report, "report", "", "write a JSON report to this file (\"-\" for the standard output)")
+	if err := fs.Parse(args); err != nil {
+		return replayOptions{}, err
+	}
+
+	if (*fromTimestamp == "") == (*fromOffset < 0) {
+		return replayOptions{}, errors.New("exactly one of -from-timestamp or -from-offset must be specified")
+	}
+
+	var err error
+	opts.window.FromOffset = *fromOffset
+	if *fromTimestamp != "" {
+		opts.window.FromTimestamp, err = time.Parse(time.RFC3339, *fromTimestamp)
+		if err != nil {
+			return replayOptions{}, fmt.Errorf("invalid -from-timestamp: %w", err)
+		}
+	}
+
+	if *until != "" {
+		opts.window.Until, err = time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return replayOptions{}, fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+
+	return opts, nil
+}
+
+// commandHandler returns the asset handler of the reconcile and replay
+// commands. If rep is not nil, the planned mutations, in dry-run mode, or
+// the observed changes of every asset are recorded in rep. If dryRun is
+// true and rep is nil, the events are just logged.
+func commandHandler(cfg config, dryRun bool, rep *report) (vulcan.AssetHandler, error) {
+	if dryRun && rep == nil {
+		return dryRunAssetHandler(), nil
+	}
+
+	icli, err := newInventoryClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating asset inventory client: %w", err)
+	}
+	rt, err := readRouter(icli, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routing table: %w", err)
+	}
+
+	if dryRun {
+		return rt.planHandler(rep, cfg), nil
+	}
+
+	h := retryHandler(context.Background(), rt.handlerWith(func(_ string, icli inventory.Inventory) vulcan.AssetHandler {
+		return assetHandler(icli, engineConfig(cfg), cfg)
+	}), handlerRetryPolicy(cfg))
+	if rep != nil {
+		h = rt.observeHandler(h, rep, cfg)
+	}
+	return h, nil
+}
+
+// dryRunAssetHandler logs the asset events instead of applying them to the
+// Asset Inventory.
+func dryRunAssetHandler() vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		action := "refresh"
+		if isNil {
+			action = "expire"
+		}
+		log.Info.Printf("graph-vulcan-assets: dry-run: %v asset %v/%v (team=%v)", action, payload.AssetType, payload.Identifier, payload.Team.ID)
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+func TestParseReplayFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantOpts   replayOptions
+		wantNilErr bool
+	}{
+		{
+			name: "from offset",
+			args: []string{"-from-offset", "10"},
+			wantOpts: replayOptions{
+				window: kafka.Window{
+					FromOffset: 10,
+				},
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "from timestamp until timestamp",
+			args: []string{"-from-timestamp", "2022-01-01T00:00:00Z", "-until", "2022-01-02T00:00:00Z", "-dry-run", "-report", "report.json"},
+			wantOpts: replayOptions{
+				window: kafka.Window{
+					FromTimestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					FromOffset:    -1,
+					Until:         time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+				},
+				dryRun: true,
+				report: "report.json",
+			},
+			wantNilErr: true,
+		},
+		{
+			name:       "missing start",
+			args:       []string{"-until", "2022-01-02T00:00:00Z"},
+			wantOpts:   replayOptions{},
+			wantNilErr: false,
+		},
+		{
+			name:       "offset and timestamp",
+			args:       []string{"-from-offset", "10", "-from-timestamp", "2022-01-01T00:00:00Z"},
+			wantOpts:   replayOptions{},
+			wantNilErr: false,
+		},
+		{
+			name:       "invalid timestamp",
+			args:       []string{"-from-timestamp", "yesterday"},
+			wantOpts:   replayOptions{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseReplayFlags(tt.args)
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if diff := cmp.Diff(tt.wantOpts, opts, cmp.AllowUnexported(replayOptions{})); diff != "" {
+				t.Errorf("options mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Report actions.
+const (
+	reportActionRefresh = "refresh"
+	reportActionExpire  = "expire"
+)
+
+// plannedIDPrefix is the prefix of the IDs returned by [planInventory] for
+// the entities that would be created.
+const plannedIDPrefix = "planned:"
+
+// report is the machine-readable report of a reconcile or replay. In
+// dry-run mode, it contains the mutations that would be applied to the
+// Asset Inventory. Otherwise, it contains the observed changes of every
+// processed asset. It is safe for concurrent use.
+type report struct {
+	Command    string        `json:"command"`
+	DryRun     bool          `json:"dry_run"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Summary    reportSummary `json:"summary"`
+	Assets     []reportEntry `json:"assets"`
+
+	mu sync.Mutex
+}
+
+// reportSummary contains the counts of a [report].
+type reportSummary struct {
+	Assets    int            `json:"assets"`
+	Refreshed int            `json:"refreshed"`
+	Expired   int            `json:"expired"`
+	Changed   int            `json:"changed"`
+	Failed    int            `json:"failed"`
+	Mutations map[string]int `json:"mutations"`
+}
+
+// reportEntry is the result of processing an asset event against an Asset
+// Inventory.
+type reportEntry struct {
+	Endpoint   string             `json:"endpoint"`
+	AssetType  string             `json:"asset_type"`
+	Identifier string             `json:"identifier"`
+	TeamID     string             `json:"team_id"`
+	Action     string             `json:"action"`
+	Mutations  []reportMutation   `json:"mutations,omitempty"`
+	Changes    []assetsync.Change `json:"changes,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// reportMutation is a mutation of the Asset Inventory planned in dry-run
+// mode.
+type reportMutation struct {
+	Op   string            `json:"op"`
+	Args map[string]string `json:"args"`
+}
+
+// newReport returns an empty report of the provided command.
+func newReport(command string, dryRun bool) *report {
+	return &report{
+		Command:   command,
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		Summary:   reportSummary{Mutations: make(map[string]int)},
+		Assets:    []reportEntry{},
+	}
+}
+
+// newReportEntry returns the entry corresponding to the provided asset
+// event.
+func newReportEntry(endpoint string, payload vulcan.AssetPayload, isNil bool, err error) reportEntry {
+	entry := reportEntry{
+		Endpoint:   endpoint,
+		AssetType:  string(payload.AssetType),
+		Identifier: payload.Identifier,
+		TeamID:     payload.Team.ID,
+		Action:     reportActionRefresh,
+	}
+	if isNil {
+		entry.Action = reportActionExpire
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// add adds an entry to the report and updates the summary.
+func (rep *report) add(entry reportEntry) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	rep.Assets = append(rep.Assets, entry)
+
+	rep.Summary.Assets++
+	switch entry.Action {
+	case reportActionRefresh:
+		rep.Summary.Refreshed++
+	case reportActionExpire:
+		rep.Summary.Expired++
+	}
+	if len(entry.Mutations) > 0 || len(entry.Changes) > 0 {
+		rep.Summary.Changed++
+	}
+	if entry.Error != "" {
+		rep.Summary.Failed++
+	}
+	for _, m := range entry.Mutations {
+		rep.Summary.Mutations[m.Op]++
+	}
+}
+
+// write writes the JSON encoding of the report to the file with the
+// provided name. If name is "-", the report is written to the standard
+// output.
+func (rep *report) write(name string) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	rep.FinishedAt = time.Now()
+
+	var w io.Writer = os.Stdout
+	if name != "-" {
+		f, err := os.Create(name)
+		if err != nil {
+			return fmt.Errorf("could not create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		return fmt.Errorf("could not encode report: %w", err)
+	}
+	return nil
+}
+
+// writeReport writes rep to the file with the provided name, if rep is not
+// nil. The report is written even if processing the assets failed with
+// perr, so partial results are available. perr takes precedence over the
+// errors writing the report.
+func writeReport(rep *report, name string, perr error) error {
+	if rep == nil {
+		return perr
+	}
+
+	if err := rep.write(name); err != nil {
+		if perr != nil {
+			log.Error.Printf("graph-vulcan-assets: error writing report: %v", err)
+			return perr
+		}
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return perr
+}
+
+// planInventory is an [inventory.Inventory] that records the mutations
+// instead of applying them. Read operations are forwarded to the underlying
+// Asset Inventory, except for the entities that would be created.
+type planInventory struct {
+	inventory.Inventory
+	mutations []reportMutation
+}
+
+// record records a planned mutation.
+func (inv *planInventory) record(op string, args map[string]string) {
+	inv.mutations = append(inv.mutations, reportMutation{Op: op, Args: args})
+}
+
+// CreateTeam records the creation of a team.
+func (inv *planInventory) CreateTeam(identifier, name string) (inventory.TeamResp, error) {
+	inv.record("create_team", map[string]string{"identifier": identifier, "name": name})
+	return inventory.TeamResp{
+		ID:         plannedIDPrefix + "team:" + identifier,
+		Identifier: identifier,
+		Name:       name,
+	}, nil
+}
+
+// UpdateTeam records the update of a team.
+func (inv *planInventory) UpdateTeam(id, identifier, name string) (inventory.TeamResp, error) {
+	inv.record("update_team", map[string]string{"id": id, "identifier": identifier, "name": name})
+	return inventory.TeamResp{ID: id, Identifier: identifier, Name: name}, nil
+}
+
+// CreateAsset records the creation of an asset.
+func (inv *planInventory) CreateAsset(typ, identifier string, timestamp, expiration time.Time) (inventory.AssetResp, error) {
+	inv.record("create_asset", map[string]string{
+		"type":       typ,
+		"identifier": identifier,
+		"expiration": formatReportTime(expiration),
+	})
+	return inventory.AssetResp{
+		ID:         plannedIDPrefix + "asset:" + typ + "/" + identifier,
+		Type:       typ,
+		Identifier: identifier,
+		FirstSeen:  timestamp,
+		LastSeen:   timestamp,
+		Expiration: expiration,
+	}, nil
+}
+
+// UpdateAsset records the update of an asset.
+func (inv *planInventory) UpdateAsset(id, typ, identifier string, timestamp, expiration time.Time) (inventory.AssetResp, error) {
+	inv.record("update_asset", map[string]string{
+		"id":         id,
+		"type":       typ,
+		"identifier": identifier,
+		"expiration": formatReportTime(expiration),
+	})
+	return inventory.AssetResp{
+		ID:         id,
+		Type:       typ,
+		Identifier: identifier,
+		FirstSeen:  timestamp,
+		LastSeen:   timestamp,
+		Expiration: expiration,
+	}, nil
+}
+
+// UpsertParent records the creation or update of a parent-of relation.
+func (inv *planInventory) UpsertParent(childID, parentID string, timestamp, expiration time.Time) (inventory.ParentOfResp, error) {
+	inv.record("upsert_parent", map[string]string{
+		"child_id":   childID,
+		"parent_id":  parentID,
+		"expiration": formatReportTime(expiration),
+	})
+	return inventory.ParentOfResp{
+		ID:         plannedIDPrefix + "parent-of:" + childID + "/" + parentID,
+		ParentID:   parentID,
+		ChildID:    childID,
+		FirstSeen:  timestamp,
+		LastSeen:   timestamp,
+		Expiration: expiration,
+	}, nil
+}
+
+// UpsertOwner records the creation or update of an owns relation.
+func (inv *planInventory) UpsertOwner(assetID, teamID string, startTime, endTime time.Time) (inventory.OwnsResp, error) {
+	args := map[string]string{"asset_id": assetID, "team_id": teamID}
+	rel := inventory.OwnsResp{
+		ID:        plannedIDPrefix + "owns:" + assetID + "/" + teamID,
+		TeamID:    teamID,
+		AssetID:   assetID,
+		StartTime: startTime,
+	}
+	if !endTime.IsZero() {
+		args["end_time"] = formatReportTime(endTime)
+		rel.EndTime = &endTime
+	}
+	inv.record("upsert_owner", args)
+	return rel, nil
+}
+
+// Parents returns the parents of an asset. Assets that would be created
+// have no parents.
+func (inv *planInventory) Parents(assetID string, pag inventory.Pagination) ([]inventory.ParentOfResp, error) {
+	if strings.HasPrefix(assetID, plannedIDPrefix) {
+		return nil, nil
+	}
+	return inv.Inventory.Parents(assetID, pag)
+}
+
+// Parent returns a parent-of relation of an asset. Assets that would be
+// created have no parents.
+func (inv *planInventory) Parent(childID, parentID string) (inventory.ParentOfResp, error) {
+	if strings.HasPrefix(childID, plannedIDPrefix) || strings.HasPrefix(parentID, plannedIDPrefix) {
+		return inventory.ParentOfResp{}, inventory.ErrNotFound
+	}
+	return inv.Inventory.Parent(childID, parentID)
+}
+
+// Children returns the children of an asset. Assets that would be created
+// have no children.
+func (inv *planInventory) Children(assetID string, pag inventory.Pagination) ([]inventory.ParentOfResp, error) {
+	if strings.HasPrefix(assetID, plannedIDPrefix) {
+		return nil, nil
+	}
+	return inv.Inventory.Children(assetID, pag)
+}
+
+// Owners returns the owners of an asset. Assets that would be created have
+// no owners.
+func (inv *planInventory) Owners(assetID string, pag inventory.Pagination) ([]inventory.OwnsResp, error) {
+	if strings.HasPrefix(assetID, plannedIDPrefix) {
+		return nil, nil
+	}
+	return inv.Inventory.Owners(assetID, pag)
+}
+
+// Owner returns an owns relation of an asset. Assets and teams that would
+// be created have no owns relations.
+func (inv *planInventory) Owner(assetID, teamID string) (inventory.OwnsResp, error) {
+	if strings.HasPrefix(assetID, plannedIDPrefix) || strings.HasPrefix(teamID, plannedIDPrefix) {
+		return inventory.OwnsResp{}, inventory.ErrNotFound
+	}
+	return inv.Inventory.Owner(assetID, teamID)
+}
+
+// formatReportTime formats t for a report. The zero time is formatted as an
+// empty string.
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// planAssetHandler returns an asset handler that records in rep the
+// mutations that processing every asset would apply to the Asset Inventory
+// with the provided endpoint. Nothing is written to the Asset Inventory.
+// Processing errors are recorded in the report instead of being returned.
+func planAssetHandler(endpoint string, icli inventory.Inventory, rep *report, cfg config) vulcan.AssetHandler {
+	engine := assetsync.NewEngine(icli, engineConfig(cfg))
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		inv := &planInventory{Inventory: icli}
+		err := engine.Apply(inv, payload, isNil)
+
+		payload = assetsync.NormalizePayload(payload, cfg.NormalizeAssetTypes)
+		entry := newReportEntry(endpoint, payload, isNil, err)
+		entry.Mutations = inv.mutations
+		rep.add(entry)
+		return nil
+	}
+}
+
+// planHandler returns an asset handler that records in rep the mutations
+// that processing every asset would apply to the Asset Inventory selected by
+// the router. See [planAssetHandler].
+func (r router) planHandler(rep *report, cfg config) vulcan.AssetHandler {
+	return r.handlerWith(func(endpoint string, icli inventory.Inventory) vulcan.AssetHandler {
+		return planAssetHandler(endpoint, icli, rep, cfg)
+	})
+}
+
+// observeHandler returns an asset handler that processes every asset with h
+// and records in rep the observed changes of the asset in the Asset
+// Inventory selected by the router.
+func (r router) observeHandler(h vulcan.AssetHandler, rep *report, cfg config) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		npayload := assetsync.NormalizePayload(payload, cfg.NormalizeAssetTypes)
+		endpoints := r.route(payload.Team, isNil)
+
+		before := make([]*assetsync.AssetState, len(endpoints))
+		for i, endpoint := range endpoints {
+			before[i] = assetsync.ReadAssetState(r.clients[endpoint], npayload, cfg.InventoryPageSize)
+		}
+
+		err := h(payload, isNil)
+
+		for i, endpoint := range endpoints {
+			entry := newReportEntry(endpoint, npayload, isNil, err)
+			entry.Changes = assetsync.DiffAssetStates(before[i], assetsync.ReadAssetState(r.clients[endpoint], npayload, cfg.InventoryPageSize))
+			rep.add(entry)
+		}
+		return err
+	}
+}
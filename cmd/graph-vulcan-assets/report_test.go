@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestPlanAssetHandler(t *testing.T) {
+	cfg := config{InventoryPageSize: 100, MissingTeamPolicy: assetsync.MissingTeamPolicyIgnore}
+	inv := inventorytest.NewInMemory()
+	rep := newReport("reconcile", true)
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+
+	h := planAssetHandler("http://inventory", inv, rep, cfg)
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	snap, err := inventorytest.TakeSnapshot(inv)
+	if err != nil {
+		t.Fatalf("error taking snapshot: %v", err)
+	}
+	if len(snap.Assets) != 0 || len(snap.Teams) != 0 {
+		t.Errorf("the inventory was modified: %+v", snap)
+	}
+
+	if len(rep.Assets) != 1 {
+		t.Fatalf("unexpected number of entries: %v", len(rep.Assets))
+	}
+
+	var ops []string
+	for _, m := range rep.Assets[0].Mutations {
+		ops = append(ops, m.Op)
+	}
+	wantOps := []string{"create_asset", "create_team", "upsert_owner"}
+	if diff := cmp.Diff(wantOps, ops); diff != "" {
+		t.Errorf("mutations mismatch (-want +got):\n%v", diff)
+	}
+
+	wantSummary := reportSummary{
+		Assets:    1,
+		Refreshed: 1,
+		Changed:   1,
+		Mutations: map[string]int{"create_asset": 1, "create_team": 1, "upsert_owner": 1},
+	}
+	if diff := cmp.Diff(wantSummary, rep.Summary); diff != "" {
+		t.Errorf("summary mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestPlanAssetHandlerExisting(t *testing.T) {
+	cfg := config{InventoryPageSize: 100, MissingTeamPolicy: assetsync.MissingTeamPolicyIgnore}
+	inv := inventorytest.NewInMemory()
+	rep := newReport("replay", true)
+
+	payload := vulcan.AssetPayload{
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+	}
+	if err := assetsync.NewEngine(inv, engineConfig(cfg)).Handle(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	assets, err := inv.Assets("Hostname", "example.com", inventory.Unexpired, inventory.Pagination{})
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+
+	h := planAssetHandler("http://inventory", inv, rep, cfg)
+	if err := h(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+	if err := h(payload, true); err != nil {
+		t.Fatalf("error handling tombstone: %v", err)
+	}
+
+	if len(rep.Assets) != 2 {
+		t.Fatalf("unexpected number of entries: %v", len(rep.Assets))
+	}
+
+	wantOps := [][]string{
+		{"update_asset", "update_team", "upsert_owner"},
+		{"upsert_owner", "update_asset"},
+	}
+	for i, entry := range rep.Assets {
+		var ops []string
+		for _, m := range entry.Mutations {
+			ops = append(ops, m.Op)
+		}
+		if diff := cmp.Diff(wantOps[i], ops); diff != "" {
+			t.Errorf("mutations of entry %v mismatch (-want +got):\n%v", i, diff)
+		}
+	}
+	if rep.Assets[1].Action != reportActionExpire {
+		t.Errorf("unexpected action: %v", rep.Assets[1].Action)
+	}
+
+	got, err := inv.Assets("Hostname", "example.com", inventory.Unexpired, inventory.Pagination{})
+	if err != nil {
+		t.Fatalf("error getting assets: %v", err)
+	}
+	if diff := cmp.Diff(assets, got); diff != "" {
+		t.Errorf("the inventory was modified (-want +got):\n%v", diff)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	rep := newReport("reconcile", false)
+	rep.add(reportEntry{
+		Endpoint:   "http://inventory",
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		TeamID:     "team-1",
+		Action:     reportActionRefresh,
+		Changes:    []assetsync.Change{{Field: "id", Before: "", After: "asset-1"}},
+	})
+	rep.add(reportEntry{
+		Endpoint:   "http://inventory",
+		AssetType:  "Hostname",
+		Identifier: "example.org",
+		TeamID:     "team-1",
+		Action:     reportActionExpire,
+		Error:      "could not expire asset",
+	})
+
+	name := filepath.Join(t.TempDir(), "report.json")
+	if err := writeReport(rep, name, nil); err != nil {
+		t.Fatalf("error writing report: %v", err)
+	}
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("error reading report: %v", err)
+	}
+
+	var got struct {
+		Command string        `json:"command"`
+		DryRun  bool          `json:"dry_run"`
+		Summary reportSummary `json:"summary"`
+		Assets  []reportEntry `json:"assets"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("error decoding report: %v", err)
+	}
+
+	wantSummary := reportSummary{
+		Assets:    2,
+		Refreshed: 1,
+		Expired:   1,
+		Changed:   1,
+		Failed:    1,
+		Mutations: map[string]int{},
+	}
+	if got.Command != "reconcile" || got.DryRun {
+		t.Errorf("unexpected command: %v (dry-run=%v)", got.Command, got.DryRun)
+	}
+	if diff := cmp.Diff(wantSummary, got.Summary); diff != "" {
+		t.Errorf("summary mismatch (-want +got):\n%v", diff)
+	}
+	if len(got.Assets) != 2 {
+		t.Errorf("unexpected number of entries: %v", len(got.Assets))
+	}
+}
+
+func TestWriteReportError(t *testing.T) {
+	errProcess := errors.New("process error")
+
+	if err := writeReport(nil, "", errProcess); !errors.Is(err, errProcess) {
+		t.Errorf("unexpected error without report: %v", err)
+	}
+
+	rep := newReport("replay", false)
+	name := filepath.Join(t.TempDir(), "missing", "report.json")
+	if err := writeReport(rep, name, errProcess); !errors.Is(err, errProcess) {
+		t.Errorf("unexpected error with processing error: %v", err)
+	}
+	if err := writeReport(rep, name, nil); err == nil {
+		t.Error("expected error writing report")
+	}
+}
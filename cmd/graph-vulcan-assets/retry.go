@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// retryPolicy controls how many times a message is retried in place before
+// its error is returned to the stream processor.
+type retryPolicy struct {
+	// Attempts is the maximum number of retries. If zero, messages are
+	// not retried.
+	Attempts int
+
+	// Backoff is the time to wait before the first retry. It is doubled
+	// after every retry.
+	Backoff time.Duration
+
+	// Retriable reports whether an error can be retried.
+	Retriable func(err error) bool
+}
+
+// retryHandler returns a handler that calls h and retries it according to
+// the provided policy while it returns retriable errors. Once the retry
+// budget is exhausted or ctx is done, the last error is returned.
+func retryHandler(ctx context.Context, h vulcan.AssetHandler, policy retryPolicy) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		backoff := policy.Backoff
+		for i := 0; ; i++ {
+			err := h(payload, isNil)
+			if err == nil || i >= policy.Attempts || !policy.Retriable(err) {
+				return err
+			}
+
+			handlerRetriesTotal.Inc(string(payload.AssetType))
+			log.Error.Printf("graph-vulcan-assets: retrying message in %v (attempt %v/%v): %v", backoff, i+1, policy.Attempts, err)
+
+			t := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// handlerRetryPolicy returns the retry policy corresponding to the provided
+// command configuration.
+func handlerRetryPolicy(cfg config) retryPolicy {
+	return retryPolicy{
+		Attempts:  cfg.HandlerRetryAttempts,
+		Backoff:   cfg.HandlerRetryBackoff,
+		Retriable: inventory.IsTransient,
+	}
+}
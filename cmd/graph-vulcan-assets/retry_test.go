@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+var (
+	errRetriable    = errors.New("retriable error")
+	errNonRetriable = errors.New("non-retriable error")
+)
+
+func TestRetryHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		attempts   int
+		wantCalls  int
+		wantNilErr bool
+	}{
+		{
+			name:       "success",
+			errs:       nil,
+			attempts:   3,
+			wantCalls:  1,
+			wantNilErr: true,
+		},
+		{
+			name:       "success after retries",
+			errs:       []error{errRetriable, errRetriable},
+			attempts:   3,
+			wantCalls:  3,
+			wantNilErr: true,
+		},
+		{
+			name:       "budget exhausted",
+			errs:       []error{errRetriable, errRetriable, errRetriable, errRetriable},
+			attempts:   3,
+			wantCalls:  4,
+			wantNilErr: false,
+		},
+		{
+			name:       "non-retriable error",
+			errs:       []error{errRetriable, errNonRetriable},
+			attempts:   3,
+			wantCalls:  2,
+			wantNilErr: false,
+		},
+		{
+			name:       "retries disabled",
+			errs:       []error{errRetriable},
+			attempts:   0,
+			wantCalls:  1,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			h := func(payload vulcan.AssetPayload, isNil bool) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			}
+
+			policy := retryPolicy{
+				Attempts:  tt.attempts,
+				Backoff:   time.Millisecond,
+				Retriable: func(err error) bool { return errors.Is(err, errRetriable) },
+			}
+
+			err := retryHandler(context.Background(), h, policy)(vulcan.AssetPayload{}, false)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("unexpected number of calls: want=%v, got=%v", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryHandlerContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	h := func(payload vulcan.AssetPayload, isNil bool) error {
+		calls++
+		return errRetriable
+	}
+
+	policy := retryPolicy{
+		Attempts:  3,
+		Backoff:   time.Hour,
+		Retriable: func(err error) bool { return true },
+	}
+
+	if err := retryHandler(ctx, h, policy)(vulcan.AssetPayload{}, false); !errors.Is(err, errRetriable) {
+		t.Errorf("unexpected error: want=%v, got=%v", errRetriable, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("unexpected number of calls: want=1, got=%v", calls)
+	}
+}
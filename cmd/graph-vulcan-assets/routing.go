@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/adevinta/graph-vulcan-assets/checkpoint"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// routingTable maps teams to Asset Inventory endpoints.
+type routingTable struct {
+	Routes []routingRule `json:"routes"`
+}
+
+// routingRule routes the assets of the teams whose ID is in Teams or whose
+// tag is in Tags to the Asset Inventory with the provided endpoint.
+type routingRule struct {
+	Teams    []string `json:"teams"`
+	Tags     []string `json:"tags"`
+	Endpoint string   `json:"endpoint"`
+}
+
+// readRoutingTable reads the routing table stored in the JSON file with the
+// provided name.
+func readRoutingTable(name string) (routingTable, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return routingTable{}, fmt.Errorf("could not open routing file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	var table routingTable
+	if err := dec.Decode(&table); err != nil {
+		return routingTable{}, fmt.Errorf("could not decode routing file: %w", err)
+	}
+
+	for i, r := range table.Routes {
+		if r.Endpoint == "" {
+			return routingTable{}, fmt.Errorf("route %v: missing endpoint", i)
+		}
+		if len(r.Teams) == 0 && len(r.Tags) == 0 {
+			return routingTable{}, fmt.Errorf("route %v: missing teams and tags", i)
+		}
+	}
+
+	return table, nil
+}
+
+// route is a [routingRule] ready to be matched against teams.
+type route struct {
+	teams    map[string]bool
+	tags     map[string]bool
+	endpoint string
+}
+
+// endpointClient is the client of an Asset Inventory endpoint of a
+// [router].
+type endpointClient interface {
+	inventory.Inventory
+	props.Inventory
+	tlsReloader
+	versionChecker
+}
+
+var _ endpointClient = inventory.Client{}
+
+// router selects the Asset Inventory where the assets of a team are stored.
+type router struct {
+	routes   []route
+	def      string
+	clients  map[string]endpointClient
+	fallback []string
+}
+
+// newRouter returns a router for the provided routing table. The assets of
+// the teams not matched by any rule are routed to def, whose endpoint is
+// cfg.InventoryEndpoint. The clients of the other endpoints are created with
+// the rest of the inventory settings of cfg and opts.
+func newRouter(table routingTable, def inventory.Client, cfg config, opts ...inventory.ClientOption) (router, error) {
+	r := router{
+		def:     cfg.InventoryEndpoint,
+		clients: map[string]endpointClient{cfg.InventoryEndpoint: def},
+	}
+
+	fallback := map[string]bool{cfg.InventoryEndpoint: true}
+	for _, rule := range table.Routes {
+		r.routes = append(r.routes, route{
+			teams:    stringSet(rule.Teams),
+			tags:     stringSet(rule.Tags),
+			endpoint: rule.Endpoint,
+		})
+
+		if len(rule.Tags) > 0 {
+			fallback[rule.Endpoint] = true
+		}
+
+		if _, ok := r.clients[rule.Endpoint]; ok {
+			continue
+		}
+		rcfg := cfg
+		rcfg.InventoryEndpoint = rule.Endpoint
+		icli, err := newInventoryClient(rcfg, opts...)
+		if err != nil {
+			return router{}, fmt.Errorf("could not create client for %v: %w", rule.Endpoint, err)
+		}
+		r.clients[rule.Endpoint] = icli
+	}
+
+	for endpoint := range fallback {
+		r.fallback = append(r.fallback, endpoint)
+	}
+	sort.Strings(r.fallback)
+
+	return r, nil
+}
+
+// endpoints returns the endpoints of the router sorted alphabetically.
+func (r router) endpoints() []string {
+	var endpoints []string
+	for endpoint := range r.clients {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// route returns the endpoints where the assets of the provided team are
+// stored. The rules are evaluated in order and the first one matching the ID
+// or the tag of the team is used. Tombstones only contain the ID of the
+// team, so, if isNil is true and no rule matches the team ID, the endpoints
+// of the default route and of all the rules with tags are returned.
+func (r router) route(team vulcan.Team, isNil bool) []string {
+	for _, rt := range r.routes {
+		if rt.teams[team.ID] || (team.Tag != "" && rt.tags[team.Tag]) {
+			return []string{rt.endpoint}
+		}
+	}
+	if isNil {
+		return r.fallback
+	}
+	return []string{r.def}
+}
+
+// handler returns an asset handler that processes every asset against the
+// Asset Inventory selected by the router. The properties enabled by cfg are
+// stored in the same Asset Inventory as the assets they refer to. See
+// [syncConfig] for the meaning of processed.
+func (r router) handler(processed checkpoint.Offsets, cfg config) vulcan.AssetHandler {
+	return r.handlerWith(func(endpoint string, icli inventory.Inventory) vulcan.AssetHandler {
+		return assetHandler(icli, syncConfig(r.clients[endpoint], processed, cfg), cfg)
+	})
+}
+
+// handlerWith returns an asset handler that processes every asset with the
+// handler returned by newHandler for the Asset Inventory selected by the
+// router.
+func (r router) handlerWith(newHandler func(endpoint string, icli inventory.Inventory) vulcan.AssetHandler) vulcan.AssetHandler {
+	if len(r.routes) == 0 {
+		return newHandler(r.def, r.clients[r.def])
+	}
+
+	handlers := make(map[string]vulcan.AssetHandler)
+	for endpoint, icli := range r.clients {
+		handlers[endpoint] = newHandler(endpoint, icli)
+	}
+
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		for _, endpoint := range r.route(payload.Team, isNil) {
+			if err := handlers[endpoint](payload, isNil); err != nil {
+				return fmt.Errorf("%v: %w", endpoint, err)
+			}
+		}
+		return nil
+	}
+}
+
+// readRouter returns the router corresponding to the provided command
+// configuration. If cfg.RoutingFile is empty, the router sends every asset to
+// icli.
+func readRouter(icli inventory.Client, cfg config, opts ...inventory.ClientOption) (router, error) {
+	var table routingTable
+	if cfg.RoutingFile != "" {
+		var err error
+		if table, err = readRoutingTable(cfg.RoutingFile); err != nil {
+			return router{}, err
+		}
+	}
+	return newRouter(table, icli, cfg, opts...)
+}
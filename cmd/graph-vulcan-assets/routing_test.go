@@ -0,0 +1,226 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/props"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestReadRoutingTable(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		want       routingTable
+		wantNilErr bool
+	}{
+		{
+			name: "valid",
+			data: `{"routes": [{"teams": ["team-1"], "tags": ["tag-1"], "endpoint": "http://inventory-1"}]}`,
+			want: routingTable{
+				Routes: []routingRule{
+					{
+						Teams:    []string{"team-1"},
+						Tags:     []string{"tag-1"},
+						Endpoint: "http://inventory-1",
+					},
+				},
+			},
+			wantNilErr: true,
+		},
+		{
+			name:       "missing endpoint",
+			data:       `{"routes": [{"teams": ["team-1"]}]}`,
+			want:       routingTable{},
+			wantNilErr: false,
+		},
+		{
+			name:       "missing teams and tags",
+			data:       `{"routes": [{"endpoint": "http://inventory-1"}]}`,
+			want:       routingTable{},
+			wantNilErr: false,
+		},
+		{
+			name:       "unknown field",
+			data:       `{"routes": [{"team": ["team-1"], "endpoint": "http://inventory-1"}]}`,
+			want:       routingTable{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "routing.json")
+			if err := os.WriteFile(name, []byte(tt.data), 0o600); err != nil {
+				t.Fatalf("error writing routing file: %v", err)
+			}
+
+			got, err := readRoutingTable(name)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("routing table mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRouterRoute(t *testing.T) {
+	cfg := config{InventoryEndpoint: "http://inventory-default"}
+	icli, err := newInventoryClient(cfg)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	table := routingTable{
+		Routes: []routingRule{
+			{Teams: []string{"team-1"}, Endpoint: "http://inventory-1"},
+			{Tags: []string{"tag-2"}, Endpoint: "http://inventory-2"},
+			{Teams: []string{"team-3"}, Tags: []string{"tag-2"}, Endpoint: "http://inventory-3"},
+		},
+	}
+	r, err := newRouter(table, icli, cfg)
+	if err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		team  vulcan.Team
+		isNil bool
+		want  []string
+	}{
+		{
+			name: "team ID",
+			team: vulcan.Team{ID: "team-1", Tag: "tag-2"},
+			want: []string{"http://inventory-1"},
+		},
+		{
+			name: "tag",
+			team: vulcan.Team{ID: "team-2", Tag: "tag-2"},
+			want: []string{"http://inventory-2"},
+		},
+		{
+			name: "first match",
+			team: vulcan.Team{ID: "team-3", Tag: "tag-2"},
+			want: []string{"http://inventory-2"},
+		},
+		{
+			name: "default",
+			team: vulcan.Team{ID: "team-4", Tag: "tag-4"},
+			want: []string{"http://inventory-default"},
+		},
+		{
+			name:  "tombstone team ID",
+			team:  vulcan.Team{ID: "team-1"},
+			isNil: true,
+			want:  []string{"http://inventory-1"},
+		},
+		{
+			name:  "tombstone fallback",
+			team:  vulcan.Team{ID: "team-2"},
+			isNil: true,
+			want:  []string{"http://inventory-2", "http://inventory-3", "http://inventory-default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.route(tt.team, tt.isNil)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("endpoints mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	want := []string{"http://inventory-1", "http://inventory-2", "http://inventory-3", "http://inventory-default"}
+	if diff := cmp.Diff(want, r.endpoints()); diff != "" {
+		t.Errorf("endpoints mismatch (-want +got):\n%v", diff)
+	}
+}
+
+// memEndpointClient is an [endpointClient] backed by an in-memory Asset
+// Inventory.
+type memEndpointClient struct {
+	*inventorytest.InMemory
+}
+
+func (memEndpointClient) ReloadTLS() (bool, error) { return false, nil }
+
+func (memEndpointClient) CheckAPIVersion() error { return nil }
+
+func TestRouterHandlerProperties(t *testing.T) {
+	def := memEndpointClient{inventorytest.NewInMemory()}
+	routed := memEndpointClient{inventorytest.NewInMemory()}
+
+	r := router{
+		routes: []route{
+			{teams: stringSet([]string{"team-1"}), endpoint: "http://inventory-1"},
+		},
+		def: "http://inventory-default",
+		clients: map[string]endpointClient{
+			"http://inventory-default": def,
+			"http://inventory-1":       routed,
+		},
+	}
+
+	cfg := config{
+		InventoryPageSize: 100,
+		AssetStateTTL:     time.Hour,
+		StoreVulcanIDs:    true,
+		StoreProvenance:   true,
+		StoreAssetState:   true,
+	}
+
+	payload := vulcan.AssetPayload{
+		ID:         "asset-1",
+		Team:       vulcan.Team{ID: "team-1", Name: "Team 1"},
+		AssetType:  "Hostname",
+		Identifier: "example.com",
+		Position:   stream.Position{Topic: vulcan.AssetsEntityName, Partition: 0, Offset: 1},
+	}
+	if err := r.handler(nil, cfg)(payload, false); err != nil {
+		t.Fatalf("error handling asset: %v", err)
+	}
+
+	if assets, err := inventory.AllAssets(def, "Hostname", "example.com", time.Time{}, 100); err != nil || len(assets) != 0 {
+		t.Errorf("asset stored in the default inventory: %v, %v", assets, err)
+	}
+
+	assets, err := inventory.AllAssets(routed, "Hostname", "example.com", time.Time{}, 100)
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("unexpected assets: %v, %v", assets, err)
+	}
+	aprops, err := routed.AssetProperties(assets[0].ID)
+	if err != nil {
+		t.Fatalf("error getting asset properties: %v", err)
+	}
+	if aprops[props.VulcanAssetIDKey] != "asset-1" {
+		t.Errorf("Vulcan ID not stored in the routed inventory: %v", aprops)
+	}
+	if aprops[props.AssetStateFingerprintKey] == "" {
+		t.Errorf("asset state not stored in the routed inventory: %v", aprops)
+	}
+
+	owners, err := inventory.AllOwners(routed, assets[0].ID, 100)
+	if err != nil || len(owners) != 1 {
+		t.Fatalf("unexpected owners: %v, %v", owners, err)
+	}
+	oprops, err := routed.OwnerProperties(assets[0].ID, owners[0].TeamID)
+	if err != nil {
+		t.Fatalf("error getting owner properties: %v", err)
+	}
+	if oprops[props.ProvenancePositionKey] == "" {
+		t.Errorf("provenance not stored in the routed inventory: %v", oprops)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/kafka"
+)
+
+// sampleFlushTimeout is the maximum time spent delivering the sampled
+// messages pending when the sampling topic is closed.
+const sampleFlushTimeout = 10 * time.Second
+
+// sampleSink receives the sampled messages.
+type sampleSink interface {
+	write(msg stream.Message) error
+	Close() error
+}
+
+// newSampleSink returns the sink of the sampled messages configured in cfg.
+// If sampling is disabled, it returns nil.
+func newSampleSink(cfg config) (sampleSink, error) {
+	if cfg.SamplePercent == 0 {
+		return nil, nil
+	}
+
+	if cfg.SampleFile != "" {
+		f, err := os.OpenFile(cfg.SampleFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open sample file: %w", err)
+		}
+		return newFileSink(f), nil
+	}
+
+	prod, err := kafka.NewProducer(producerConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("could not create sample producer: %w", err)
+	}
+	return topicSink{prod: prod, topic: cfg.SampleTopic}, nil
+}
+
+// sampleProducer enqueues messages to be delivered to a kafka topic. It is
+// implemented by [kafka.Producer].
+type sampleProducer interface {
+	Produce(topic string, msg stream.Message) error
+	Flush(ctx context.Context) error
+	Close()
+}
+
+// topicSink is a [sampleSink] that sends the sampled messages to a kafka
+// topic. Messages are delivered asynchronously, so sampling does not slow
+// down the processing of the messages.
+type topicSink struct {
+	prod  sampleProducer
+	topic string
+}
+
+func (s topicSink) write(msg stream.Message) error {
+	return s.prod.Produce(s.topic, stream.Message{
+		Key:      msg.Key,
+		Value:    msg.Value,
+		Metadata: msg.Metadata,
+	})
+}
+
+// Close delivers the pending messages and closes the producer.
+func (s topicSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), sampleFlushTimeout)
+	defer cancel()
+
+	err := s.prod.Flush(ctx)
+	s.prod.Close()
+	if err != nil {
+		return fmt.Errorf("could not deliver sampled messages: %w", err)
+	}
+	return nil
+}
+
+// sampledMessage is the representation of a message in the sample file. It
+// has the same fields as the messages of the fixtures written by the capture
+// command.
+type sampledMessage struct {
+	Key      *string               `json:"key"`
+	Value    *string               `json:"value"`
+	Metadata []sampledMetadataItem `json:"metadata"`
+}
+
+// sampledMetadataItem is the representation of a metadata entry in the
+// sample file.
+type sampledMetadataItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// fileSink is a [sampleSink] that appends the sampled messages to a file
+// using the JSON Lines format. It is safe for concurrent use.
+type fileSink struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// newFileSink returns a [fileSink] that writes to f.
+func newFileSink(f *os.File) *fileSink {
+	return &fileSink{f: f, enc: json.NewEncoder(f)}
+}
+
+func (s *fileSink) write(msg stream.Message) error {
+	var sm sampledMessage
+	if msg.Key != nil {
+		key := string(msg.Key)
+		sm.Key = &key
+	}
+	if msg.Value != nil {
+		value := string(msg.Value)
+		sm.Value = &value
+	}
+	for _, e := range msg.Metadata {
+		sm.Metadata = append(sm.Metadata, sampledMetadataItem{Key: string(e.Key), Value: string(e.Value)})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enc.Encode(sm)
+}
+
+// Close closes the file.
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// samplingProcessor is a [stream.Processor] that copies a percentage of the
+// received messages to a [sampleSink] before processing them. Messages are
+// sampled by key, so all the events of a sampled asset are copied. Sampling
+// errors are logged and do not interrupt the processing of the messages.
+type samplingProcessor struct {
+	proc    stream.Processor
+	percent int
+	sink    sampleSink
+}
+
+// newSamplingProcessor returns a [stream.Processor] that processes the
+// messages of proc, copying the provided percentage of them to sink. If
+// sink is nil, it returns proc.
+func newSamplingProcessor(proc stream.Processor, percent int, sink sampleSink) stream.Processor {
+	if sink == nil {
+		return proc
+	}
+	return samplingProcessor{proc: proc, percent: percent, sink: sink}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p samplingProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if sampled(msg.Key, p.percent) {
+			if err := p.sink.write(msg); err != nil {
+				sampledMessagesTotal.Inc(sampleOutcomeFailed)
+				log.Error.Printf("graph-vulcan-assets: could not sample message %v (key %q): %v", msg.Position, msg.Key, err)
+			} else {
+				sampledMessagesTotal.Inc(sampleOutcomeSampled)
+			}
+		}
+		return h(msg)
+	})
+}
+
+// Outcomes of the sampled messages.
+const (
+	sampleOutcomeSampled = "sampled"
+	sampleOutcomeFailed  = "failed"
+)
+
+// sampled reports whether the messages with the provided key are sampled
+// when sampling the provided percentage of the keys.
+func sampled(key []byte, percent int) bool {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32()%100) < percent
+}
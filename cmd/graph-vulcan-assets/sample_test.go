@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestSampled(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent int
+		want    int
+	}{
+		{name: "none", percent: 0, want: 0},
+		{name: "all", percent: 100, want: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			for i := 0; i < 1000; i++ {
+				if sampled([]byte(fmt.Sprintf("team-1/asset-%v", i)), tt.percent) {
+					got++
+				}
+			}
+			if got != tt.want {
+				t.Errorf("unexpected number of sampled keys: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+
+	var n int
+	for i := 0; i < 10000; i++ {
+		if sampled([]byte(fmt.Sprintf("team-1/asset-%v", i)), 10) {
+			n++
+		}
+	}
+	if n < 800 || n > 1200 {
+		t.Errorf("unexpected number of sampled keys with 10%%: %v", n)
+	}
+}
+
+// memSampleSink is an in-memory [sampleSink].
+type memSampleSink struct {
+	msgs []stream.Message
+	err  error
+}
+
+func (s *memSampleSink) write(msg stream.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func (s *memSampleSink) Close() error {
+	return nil
+}
+
+func TestSamplingProcessor(t *testing.T) {
+	var msgs []stream.Message
+	for i := 0; i < 100; i++ {
+		msgs = append(msgs, stream.Message{Key: []byte(fmt.Sprintf("team-1/asset-%v", i%10))})
+	}
+
+	tests := []struct {
+		name    string
+		sinkErr error
+	}{
+		{
+			name:    "sampled",
+			sinkErr: nil,
+		},
+		{
+			name:    "sink error",
+			sinkErr: errors.New("sink error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memSampleSink{err: tt.sinkErr}
+			proc := newSamplingProcessor(streamtest.NewMockProcessor(msgs), 30, sink)
+
+			beforeSampled := sampledMessagesTotal.Value(sampleOutcomeSampled)
+			beforeFailed := sampledMessagesTotal.Value(sampleOutcomeFailed)
+
+			var processed int
+			err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+				processed++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if processed != len(msgs) {
+				t.Errorf("unexpected number of processed messages: got: %v, want: %v", processed, len(msgs))
+			}
+
+			// All the messages of a sampled key are sampled.
+			var want int
+			for _, msg := range msgs {
+				if sampled(msg.Key, 30) {
+					want++
+				}
+			}
+			if want == 0 || want%10 != 0 {
+				t.Fatalf("unexpected number of messages of sampled keys: %v", want)
+			}
+
+			wantSampled, wantFailed := float64(want), float64(0)
+			if tt.sinkErr != nil {
+				wantSampled, wantFailed = 0, float64(want)
+			}
+			if n := sampledMessagesTotal.Value(sampleOutcomeSampled) - beforeSampled; n != wantSampled {
+				t.Errorf("unexpected number of sampled messages: got: %v, want: %v", n, wantSampled)
+			}
+			if n := sampledMessagesTotal.Value(sampleOutcomeFailed) - beforeFailed; n != wantFailed {
+				t.Errorf("unexpected number of failed messages: got: %v, want: %v", n, wantFailed)
+			}
+			if n := len(sink.msgs); n != int(wantSampled) {
+				t.Errorf("unexpected number of messages in the sink: got: %v, want: %v", n, wantSampled)
+			}
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.jsonl")
+
+	cfg := config{SamplePercent: 100, SampleFile: path}
+	sink, err := newSampleSink(cfg)
+	if err != nil {
+		t.Fatalf("error creating sink: %v", err)
+	}
+
+	msgs := []stream.Message{
+		{
+			Key:      []byte("team-1/asset-1"),
+			Value:    []byte(`{"id":"asset-1"}`),
+			Metadata: []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("0.0.1")}},
+		},
+		{
+			Key: []byte("team-1/asset-2"),
+		},
+	}
+	for _, msg := range msgs {
+		if err := sink.write(msg); err != nil {
+			t.Fatalf("error writing message: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("error closing sink: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("error opening sample file: %v", err)
+	}
+	defer f.Close()
+
+	var got []sampledMessage
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var sm sampledMessage
+		if err := json.Unmarshal(sc.Bytes(), &sm); err != nil {
+			t.Fatalf("error decoding line %q: %v", sc.Text(), err)
+		}
+		got = append(got, sm)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("error reading sample file: %v", err)
+	}
+
+	key1, value1, key2 := "team-1/asset-1", `{"id":"asset-1"}`, "team-1/asset-2"
+	want := []sampledMessage{
+		{
+			Key:      &key1,
+			Value:    &value1,
+			Metadata: []sampledMetadataItem{{Key: "version", Value: "0.0.1"}},
+		},
+		{
+			Key: &key2,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sampled messages mismatch (-want +got):\n%v", diff)
+	}
+}
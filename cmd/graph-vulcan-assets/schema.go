@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// Policies applied to the asset messages whose payload does not conform to
+// the JSON schema of the Vulcan async API.
+const (
+	schemaPolicyOff    = "off"
+	schemaPolicyWarn   = "warn"
+	schemaPolicyReject = "reject"
+	schemaPolicyDLQ    = "dlq"
+)
+
+// dlqReasonSchemaViolation is the reason of the records sent to the dead
+// letter queue because their payload does not conform to the schema.
+const dlqReasonSchemaViolation = "schema_violation"
+
+// schemaProcessor is a [stream.Processor] that validates the payload of
+// the asset messages against the JSON schema of the Vulcan async API and
+// applies a policy to the non-conforming ones, so producer regressions are
+// caught before the messages reach the handler. Tombstones and the
+// messages of other entities are not validated.
+type schemaProcessor struct {
+	proc   stream.Processor
+	policy string
+	topic  string
+	dlq    dlqProducer
+}
+
+// newSchemaProcessor returns a [schemaProcessor] that applies policy to
+// the non-conforming messages of proc. If policy is "dlq", they are sent to
+// the provided topic using dlq. If dlq is nil, they are skipped instead, so
+// replaying the topic does not send them to the dead letter queue again.
+func newSchemaProcessor(proc stream.Processor, policy, topic string, dlq dlqProducer) schemaProcessor {
+	return schemaProcessor{
+		proc:   proc,
+		policy: policy,
+		topic:  topic,
+		dlq:    dlq,
+	}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p schemaProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	if p.policy == "" || p.policy == schemaPolicyOff {
+		return p.proc.Process(ctx, entity, h)
+	}
+
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		if msg.Value == nil {
+			return h(msg)
+		}
+		switch metadataValue(msg, vulcan.EntityKey) {
+		case "", vulcan.AssetEntity:
+		default:
+			return h(msg)
+		}
+
+		err := vulcan.ValidateAssetPayload(msg.Value)
+		if err == nil {
+			return h(msg)
+		}
+
+		typ := metadataValue(msg, typeMetadataKey)
+		schemaViolationsTotal.Inc(typ, p.policy)
+
+		switch {
+		case p.policy == schemaPolicyWarn:
+			log.Error.Printf("graph-vulcan-assets: processing non-conforming message %v (key %q): %v", msg.Position, msg.Key, err)
+			return h(msg)
+		case p.policy == schemaPolicyReject:
+			teamID, _, _ := strings.Cut(string(msg.Key), "/")
+			return vulcan.InvalidMessageError{
+				Reason:    vulcan.ErrMalformedPayload,
+				AssetType: vulcan.AssetType(typ),
+				TeamID:    teamID,
+				Position:  msg.Position,
+				Err:       err,
+			}
+		case p.policy == schemaPolicyDLQ && p.dlq != nil:
+			log.Error.Printf("graph-vulcan-assets: sending non-conforming message %v (key %q) to %v: %v", msg.Position, msg.Key, p.topic, err)
+			rec := dlqRecord(msg, dlqReasonSchemaViolation, 0)
+			if err := p.dlq.ProduceSync(ctx, p.topic, rec); err != nil {
+				return fmt.Errorf("could not send message to the dead letter queue: %w", err)
+			}
+			return nil
+		}
+
+		log.Error.Printf("graph-vulcan-assets: skipping non-conforming message %v (key %q): %v", msg.Position, msg.Key, err)
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestSchemaProcessor(t *testing.T) {
+	typeMetadata := []stream.MetadataEntry{{Key: []byte(typeMetadataKey), Value: []byte("Hostname")}}
+	msgs := []stream.Message{
+		{
+			Key:      []byte("team-1/asset-1"),
+			Value:    []byte(`{"Id":"asset-1","Team":{"Id":"team-1"},"AssetType":"Hostname","Identifier":"www.example.com"}`),
+			Metadata: typeMetadata,
+		},
+		{
+			Key:      []byte("team-1/asset-2"),
+			Value:    []byte(`{"Id":"asset-2","Team":{"Id":"team-1"},"AssetType":"Hostname"}`),
+			Metadata: typeMetadata,
+		},
+		{
+			Key:      []byte("team-1/asset-3"),
+			Metadata: typeMetadata,
+		},
+		{
+			Key:   []byte("team-1/finding-1"),
+			Value: []byte(`{"ID":"finding-1"}`),
+			Metadata: []stream.MetadataEntry{
+				{Key: []byte(vulcan.EntityKey), Value: []byte(vulcan.FindingEntity)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		policy         string
+		dlq            *fakeDLQProducer
+		wantKeys       []string
+		wantDLQKeys    []string
+		wantViolations float64
+		wantErr        error
+	}{
+		{
+			name:     "off",
+			policy:   schemaPolicyOff,
+			wantKeys: []string{"team-1/asset-1", "team-1/asset-2", "team-1/asset-3", "team-1/finding-1"},
+		},
+		{
+			name:           "warn",
+			policy:         schemaPolicyWarn,
+			wantKeys:       []string{"team-1/asset-1", "team-1/asset-2", "team-1/asset-3", "team-1/finding-1"},
+			wantViolations: 1,
+		},
+		{
+			name:           "reject",
+			policy:         schemaPolicyReject,
+			wantKeys:       []string{"team-1/asset-1"},
+			wantViolations: 1,
+			wantErr:        vulcan.ErrMalformedPayload,
+		},
+		{
+			name:           "dlq",
+			policy:         schemaPolicyDLQ,
+			dlq:            &fakeDLQProducer{},
+			wantKeys:       []string{"team-1/asset-1", "team-1/asset-3", "team-1/finding-1"},
+			wantDLQKeys:    []string{"team-1/asset-2"},
+			wantViolations: 1,
+		},
+		{
+			name:           "dlq without producer",
+			policy:         schemaPolicyDLQ,
+			wantKeys:       []string{"team-1/asset-1", "team-1/asset-3", "team-1/finding-1"},
+			wantViolations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dlq dlqProducer
+			if tt.dlq != nil {
+				dlq = tt.dlq
+			}
+
+			before := schemaViolationsTotal.Value("Hostname", tt.policy)
+
+			proc := newSchemaProcessor(streamtest.NewMockProcessor(msgs), tt.policy, "dlq", dlq)
+
+			var keys []string
+			err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+				keys = append(keys, string(msg.Key))
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: want: %v, got: %v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tt.wantKeys, keys); diff != "" {
+				t.Errorf("processed messages mismatch (-want +got):\n%v", diff)
+			}
+
+			if tt.dlq != nil {
+				var dlqKeys []string
+				for _, msg := range tt.dlq.msgs {
+					dlqKeys = append(dlqKeys, string(msg.Key))
+					if reason := metadataValue(msg, dlqReasonKey); reason != dlqReasonSchemaViolation {
+						t.Errorf("unexpected reason: %v", reason)
+					}
+				}
+				if diff := cmp.Diff(tt.wantDLQKeys, dlqKeys); diff != "" {
+					t.Errorf("dead letter queue mismatch (-want +got):\n%v", diff)
+				}
+			}
+
+			if n := schemaViolationsTotal.Value("Hostname", tt.policy) - before; n != tt.wantViolations {
+				t.Errorf("unexpected number of schema violations: got: %v, want: %v", n, tt.wantViolations)
+			}
+		})
+	}
+}
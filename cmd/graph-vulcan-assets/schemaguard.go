@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/log"
+)
+
+// Policies applied when the API version of an Asset Inventory is not
+// supported at startup.
+const (
+	inventoryVersionPolicyFail  = "fail"
+	inventoryVersionPolicyPause = "pause"
+)
+
+// versionChecker is implemented by the Asset Inventory clients that can
+// check the version of the API.
+type versionChecker interface {
+	CheckAPIVersion() error
+}
+
+// schemaGuard prevents writing to Asset Inventories whose API version is
+// not supported by the consumer, which could corrupt the data during
+// staggered rollouts. While any of the Asset Inventories is incompatible,
+// the maintenance mode is enabled, so processing is paused.
+type schemaGuard struct {
+	checkers map[string]versionChecker
+	maint    *maintenance
+
+	// incompatible contains the endpoints whose API version is not
+	// supported.
+	incompatible map[string]bool
+}
+
+// newSchemaGuard returns a [schemaGuard] that checks the Asset Inventories
+// with the provided endpoints and clients.
+func newSchemaGuard(checkers map[string]versionChecker, maint *maintenance) *schemaGuard {
+	return &schemaGuard{
+		checkers:     checkers,
+		maint:        maint,
+		incompatible: make(map[string]bool),
+	}
+}
+
+// check checks the API version of every Asset Inventory and updates the
+// maintenance mode accordingly. If the version of an Asset Inventory cannot
+// be checked because of a transient error, its previous state is kept. It
+// returns the endpoints whose API version is not supported.
+func (g *schemaGuard) check() []string {
+	for endpoint, c := range g.checkers {
+		err := checkInventoryVersion(c)
+
+		var perr permanentError
+		switch {
+		case err == nil:
+			if g.incompatible[endpoint] {
+				log.Info.Printf("graph-vulcan-assets: asset inventory %v API version is supported again", endpoint)
+			}
+			delete(g.incompatible, endpoint)
+		case errors.As(err, &perr):
+			if !g.incompatible[endpoint] {
+				log.Error.Printf("graph-vulcan-assets: asset inventory %v: %v, inventory writes paused", endpoint, err)
+			}
+			g.incompatible[endpoint] = true
+		default:
+			log.Error.Printf("graph-vulcan-assets: could not check the asset inventory %v API version: %v", endpoint, err)
+		}
+	}
+
+	var endpoints []string
+	for endpoint := range g.incompatible {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	g.maint.setIncompatibleVersion(len(endpoints) > 0)
+	return endpoints
+}
+
+// watch calls [schemaGuard.check] every interval. It blocks the calling
+// goroutine until the provided context is cancelled.
+func (g *schemaGuard) watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			g.check()
+		}
+	}
+}
+
+// checkInventoryVersion checks that the version of the Asset Inventory API is
+// supported. If the version cannot be determined, a warning is logged and
+// the check passes. An incompatible version is a permanent error.
+func checkInventoryVersion(c versionChecker) error {
+	err := c.CheckAPIVersion()
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, inventory.ErrUnknownAPIVersion) {
+		log.Error.Printf("graph-vulcan-assets: could not determine the asset inventory API version: %v", err)
+		return nil
+	}
+
+	var verr inventory.IncompatibleVersionError
+	if errors.As(err, &verr) {
+		return permanentError{err}
+	}
+
+	return err
+}
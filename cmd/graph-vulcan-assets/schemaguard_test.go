@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// fakeVersionChecker is a [versionChecker] that returns err.
+type fakeVersionChecker struct {
+	err error
+}
+
+func (c *fakeVersionChecker) CheckAPIVersion() error {
+	return c.err
+}
+
+func TestSchemaGuardCheck(t *testing.T) {
+	inv1 := &fakeVersionChecker{}
+	inv2 := &fakeVersionChecker{}
+
+	maint := newMaintenance("")
+	guard := newSchemaGuard(map[string]versionChecker{
+		"http://inventory-1": inv1,
+		"http://inventory-2": inv2,
+	}, maint)
+
+	if got := guard.check(); len(got) != 0 {
+		t.Errorf("unexpected incompatible endpoints: %v", got)
+	}
+	if maint.active() {
+		t.Error("maintenance mode enabled with compatible versions")
+	}
+
+	inv2.err = inventory.IncompatibleVersionError{Version: "1.0.0"}
+	if diff := cmp.Diff([]string{"http://inventory-2"}, guard.check()); diff != "" {
+		t.Errorf("incompatible endpoints mismatch (-want +got):\n%v", diff)
+	}
+	if !maint.active() {
+		t.Error("maintenance mode disabled with incompatible version")
+	}
+
+	// Transient errors keep the previous state.
+	inv2.err = errors.New("connection refused")
+	if diff := cmp.Diff([]string{"http://inventory-2"}, guard.check()); diff != "" {
+		t.Errorf("incompatible endpoints mismatch (-want +got):\n%v", diff)
+	}
+
+	// The admin API cannot disable the guard.
+	maint.enabled.Store(false)
+	if !maint.active() {
+		t.Error("maintenance mode disabled with incompatible version")
+	}
+
+	// Unknown versions are accepted.
+	inv2.err = inventory.ErrUnknownAPIVersion
+	if got := guard.check(); len(got) != 0 {
+		t.Errorf("unexpected incompatible endpoints: %v", got)
+	}
+	if maint.active() {
+		t.Error("maintenance mode enabled after the version is supported again")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+)
+
+// syncStatus returns the status of the consumer at the provided time. It is
+// the source of [assetsync.Status], which adds the counters, the last error
+// and the statistics of the caches of the engine.
+func syncStatus(cs *consumerState, lag *partitionLag, now time.Time) assetsync.SyncStatus {
+	st := cs.status()
+
+	partitions := []assetsync.PartitionLag{}
+	for p, d := range lag.lags(now) {
+		partitions = append(partitions, assetsync.PartitionLag{Partition: p, Lag: d})
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Partition < partitions[j].Partition })
+
+	return assetsync.SyncStatus{
+		Time:       now,
+		State:      st.State,
+		Ready:      st.Ready,
+		Error:      st.Error,
+		Partitions: partitions,
+	}
+}
+
+// statusHandler returns the admin API endpoint that responds with the
+// status returned by [assetsync.Status].
+func statusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(assetsync.Status())
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+)
+
+func TestSyncStatus(t *testing.T) {
+	now := time.Now()
+
+	cs := newConsumerState()
+	cs.subscribed("assets")
+	cs.assigned([]int32{1, 0})
+
+	lag := newPartitionLag(0)
+	lag.record(1, now.Add(-time.Minute))
+	lag.record(0, now.Add(-time.Second))
+
+	st := syncStatus(cs, lag, now)
+
+	if st.State != consumerAssigned || !st.Ready {
+		t.Errorf("unexpected state: state=%v ready=%v", st.State, st.Ready)
+	}
+	wantPartitions := []assetsync.PartitionLag{
+		{Partition: 0, Lag: time.Second},
+		{Partition: 1, Lag: time.Minute},
+	}
+	if diff := cmp.Diff(wantPartitions, st.Partitions); diff != "" {
+		t.Errorf("partitions mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	cs := newConsumerState()
+	lag := newPartitionLag(0)
+	assetsync.SetStatusSource(func() assetsync.SyncStatus {
+		return syncStatus(cs, lag, time.Now())
+	})
+	defer assetsync.SetStatusSource(nil)
+
+	rec := httptest.NewRecorder()
+	statusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("unexpected status code: %v", rec.Code)
+	}
+	// The counters of the engine are added to the status of the
+	// consumer.
+	if body := rec.Body.String(); !strings.Contains(body, `"state":"starting"`) || !strings.Contains(body, `"counters":{"processed":`) {
+		t.Errorf("unexpected body: %v", body)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+)
+
+// teamMappingFile is the format of the JSON file of a [assetsync.TeamMapping].
+type teamMappingFile struct {
+	Teams map[string]string `json:"teams"`
+}
+
+// readTeamMapping reads the team mapping stored in the JSON file with the
+// provided name.
+func readTeamMapping(name string) (assetsync.TeamMapping, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not open team mapping file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	var file teamMappingFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("could not decode team mapping file: %w", err)
+	}
+
+	for from, to := range file.Teams {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("invalid mapping %q to %q", from, to)
+		}
+		// Mappings are not transitive, so chains are rejected to
+		// avoid surprises.
+		if next, ok := file.Teams[to]; ok {
+			return nil, fmt.Errorf("team %v is mapped to %v, which is mapped to %v", from, to, next)
+		}
+	}
+
+	return assetsync.TeamMapping(file.Teams), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/assetsync"
+)
+
+func TestReadTeamMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		want       assetsync.TeamMapping
+		wantNilErr bool
+	}{
+		{
+			name:       "valid",
+			data:       `{"teams": {"team-old": "team-new", "team-other": "team-new"}}`,
+			want:       assetsync.TeamMapping{"team-old": "team-new", "team-other": "team-new"},
+			wantNilErr: true,
+		},
+		{
+			name:       "empty identifier",
+			data:       `{"teams": {"team-old": ""}}`,
+			want:       nil,
+			wantNilErr: false,
+		},
+		{
+			name:       "chain",
+			data:       `{"teams": {"team-1": "team-2", "team-2": "team-3"}}`,
+			want:       nil,
+			wantNilErr: false,
+		},
+		{
+			name:       "unknown field",
+			data:       `{"team": {"team-old": "team-new"}}`,
+			want:       nil,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "teams.json")
+			if err := os.WriteFile(name, []byte(tt.data), 0o600); err != nil {
+				t.Fatalf("error writing team mapping file: %v", err)
+			}
+
+			got, err := readTeamMapping(name)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("team mapping mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// endMessageKey is the key of the last message of the end-to-end fixtures.
+// It contains an invalid payload to force run to return.
+const endMessageKey = "ENDTESTDATA"
+
+// e2eScenario is the end-to-end scenario run by the test-env command and
+// the integration tests. It loads the fixtures into the testing kafka
+// cluster, processes them against the testing Asset Inventory and takes a
+// snapshot of the result.
+type e2eScenario struct {
+	// Fixtures is the path of the JSON file with the messages, in the
+	// format read by [streamtest.Parse]. The last message must have the
+	// key [endMessageKey].
+	Fixtures string
+
+	// GroupID is the kafka consumer group ID.
+	GroupID string
+
+	// LogLevel is the log level of the consumer.
+	LogLevel string
+}
+
+// config returns the configuration of the consumer used by the scenario.
+func (s e2eScenario) config() config {
+	return config{
+		LogLevel:                    s.LogLevel,
+		LogFormat:                   "text",
+		RetryDuration:               0,
+		PreflightTimeout:            0,
+		KafkaBootstrapServers:       testinfra.KafkaBootstrapServers(),
+		KafkaGroupID:                s.GroupID,
+		KafkaUsername:               "",
+		KafkaPassword:               "",
+		AWSAccountAnnotationKeys:    []string{"discovery/aws/account"},
+		InventoryEndpoint:           testinfra.InventoryEndpoint(),
+		InventoryInsecureSkipVerify: true,
+		InventoryPageSize:           2,
+	}
+}
+
+// run runs the scenario and returns the snapshot of the Asset Inventory
+// after processing the fixtures. The testing infrastructure must be
+// running.
+func (s e2eScenario) run(ctx context.Context) (inventorytest.Snapshot, error) {
+	msgs, err := streamtest.Parse(s.Fixtures)
+	if err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not parse fixtures: %w", err)
+	}
+
+	if err := testinfra.Wait(ctx); err != nil {
+		return inventorytest.Snapshot{}, err
+	}
+	if err := testinfra.ResetTopic(ctx, vulcan.AssetsEntityName); err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not reset kafka: %w", err)
+	}
+	if err := testinfra.LoadFixtures(ctx, vulcan.AssetsEntityName, msgs); err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not load fixtures: %w", err)
+	}
+	if err := testinfra.ResetGraph(); err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not reset inventory: %w", err)
+	}
+
+	cfg := s.config()
+	if err := run(ctx, cfg, runOptions{}); err != nil && !strings.Contains(err.Error(), endMessageKey) {
+		return inventorytest.Snapshot{}, fmt.Errorf("error processing messages: %w", err)
+	}
+
+	icli, err := inventory.NewClient(cfg.InventoryEndpoint, cfg.InventoryInsecureSkipVerify)
+	if err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not create inventory client: %w", err)
+	}
+
+	snap, err := inventorytest.TakeSnapshot(icli)
+	if err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not take snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// runTestEnv implements the test-env command. It provisions the local
+// testing infrastructure, runs the end-to-end scenario and compares the
+// resulting state of the Asset Inventory with the expected snapshot.
+func runTestEnv(args []string) error {
+	fs := flag.NewFlagSet("test-env", flag.ContinueOnError)
+	composeFile := fs.String("compose-file", "_script/docker-compose.yml", "docker-compose file of the testing infrastructure")
+	provision := fs.Bool("provision", true, "start the testing infrastructure with docker-compose (disable it if the infrastructure is provided by other means)")
+	teardown := fs.Bool("teardown", false, "stop and remove the testing infrastructure when finished")
+	fixtures := fs.String("fixtures", "cmd/graph-vulcan-assets/testdata/messages.json", "JSON file with the messages of the scenario")
+	want := fs.String("want", "cmd/graph-vulcan-assets/testdata/snapshot.json", "JSON file with the expected snapshot of the Asset Inventory")
+	update := fs.Bool("update", false, "write the resulting snapshot to the -want file instead of comparing them")
+	timeout := fs.Duration("timeout", 5*time.Minute, "maximum duration of the run")
+	logLevel := fs.String("log-level", "error", "log level of the consumer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *provision {
+		if err := testinfra.Provision(ctx, *composeFile); err != nil {
+			return err
+		}
+	}
+	if *teardown {
+		defer func() {
+			// The teardown must run even if the run timed out.
+			if err := testinfra.Teardown(context.Background(), *composeFile); err != nil {
+				log.Error.Printf("graph-vulcan-assets: test-env: %v", err)
+			}
+		}()
+	}
+
+	scenario := e2eScenario{
+		Fixtures: *fixtures,
+		GroupID:  "graph-vulcan-assets-test-env-" + strconv.FormatInt(time.Now().UnixNano(), 16),
+		LogLevel: *logLevel,
+	}
+	got, err := scenario.run(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *update {
+		return writeSnapshot(*want, got)
+	}
+
+	wantSnap, err := readSnapshot(*want)
+	if err != nil {
+		return err
+	}
+	if diff := inventorytest.Diff(wantSnap, got); diff != "" {
+		return fmt.Errorf("snapshot mismatch (-want +got):\n%v", diff)
+	}
+
+	fmt.Fprintf(os.Stdout, "ok: %v teams, %v assets\n", len(got.Teams), len(got.Assets))
+	return nil
+}
+
+// readSnapshot reads the JSON encoded snapshot in the provided file.
+func readSnapshot(filename string) (inventorytest.Snapshot, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not read snapshot: %w", err)
+	}
+
+	var snap inventorytest.Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return inventorytest.Snapshot{}, fmt.Errorf("could not decode snapshot: %w", err)
+	}
+	if len(snap.Teams) == 0 && len(snap.Assets) == 0 {
+		return inventorytest.Snapshot{}, errors.New("empty snapshot")
+	}
+	return snap, nil
+}
+
+// writeSnapshot writes snap to the provided file as indented JSON.
+func writeSnapshot(filename string, snap inventorytest.Snapshot) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("could not create snapshot file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeJSON(f, snap); err != nil {
+		return err
+	}
+	return f.Close()
+}
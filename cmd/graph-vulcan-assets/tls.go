@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+)
+
+// tlsReloader is implemented by the clients that can reload their TLS
+// material.
+type tlsReloader interface {
+	ReloadTLS() (bool, error)
+}
+
+// watchTLS reloads the TLS material of r every interval until ctx is done.
+// Reload errors are logged and the previous TLS material is kept.
+func watchTLS(ctx context.Context, r tlsReloader, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.ReloadTLS()
+			if err != nil {
+				log.Error.Printf("graph-vulcan-assets: error reloading TLS files: %v", err)
+				continue
+			}
+			if reloaded {
+				log.Info.Println("graph-vulcan-assets: TLS files reloaded")
+			}
+		}
+	}
+}
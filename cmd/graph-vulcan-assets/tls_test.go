@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeReloader is a [tlsReloader] that counts the reloads and fails the
+// first one.
+type fakeReloader struct {
+	calls atomic.Int64
+}
+
+func (r *fakeReloader) ReloadTLS() (bool, error) {
+	if r.calls.Add(1) == 1 {
+		return false, errors.New("reload error")
+	}
+	return true, nil
+}
+
+func TestWatchTLS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &fakeReloader{}
+	done := make(chan struct{})
+	go func() {
+		watchTLS(ctx, r, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.calls.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("TLS files not reloaded after an error")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
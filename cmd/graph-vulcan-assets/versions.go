@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+// versionMetadataKey is the metadata key that contains the version of the
+// Vulcan async API in the messages of the assets topic.
+const versionMetadataKey = "version"
+
+// seenVersions contains the version labels of the messages processed since
+// the process started.
+var seenVersions sync.Map
+
+// versionProcessor is a [stream.Processor] that records the versions of the
+// Vulcan async API of the processed messages, including the unsupported
+// ones. So, it is possible to know when producers start publishing a new
+// version before it breaks the consumer. The first message with every
+// version is logged.
+type versionProcessor struct {
+	proc stream.Processor
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p versionProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	return p.proc.Process(ctx, entity, func(msg stream.Message) error {
+		observeVersion(msg)
+		return h(msg)
+	})
+}
+
+// observeVersion records the version of msg.
+func observeVersion(msg stream.Message) {
+	version := metadataValue(msg, versionMetadataKey)
+	label := versionLabel(version)
+	supported := strconv.FormatBool(vulcan.SupportedVersion(version))
+
+	messageVersionsTotal.Inc(label, supported)
+
+	if _, seen := seenVersions.LoadOrStore(label, true); !seen {
+		log.Info.Printf("graph-vulcan-assets: first message with version %v (supported=%v) at %v", label, supported, msg.Position)
+	}
+}
+
+// versionLabel returns the major and minor components of the provided
+// semantic version, so the cardinality of the version metrics is bounded.
+// It returns "none" if the version is empty and "invalid" if it cannot be
+// parsed.
+func versionLabel(v string) string {
+	if v == "" {
+		return "none"
+	}
+
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) < 2 {
+		return "invalid"
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 0 {
+		return "invalid"
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return "invalid"
+	}
+
+	return strconv.Itoa(major) + "." + strconv.Itoa(minor)
+}
+
+// metadataValue returns the value of the metadata entry of msg with the
+// provided key. If there is no such entry, it returns an empty string.
+func metadataValue(msg stream.Message, key string) string {
+	for _, e := range msg.Metadata {
+		if string(e.Key) == key {
+			return string(e.Value)
+		}
+	}
+	return ""
+}
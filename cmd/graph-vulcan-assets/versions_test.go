@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestVersionLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		v    string
+		want string
+	}{
+		{
+			name: "semantic version",
+			v:    "0.2.3",
+			want: "0.2",
+		},
+		{
+			name: "leading v",
+			v:    "v1.0.0",
+			want: "1.0",
+		},
+		{
+			name: "leading zeros",
+			v:    "00.02.3",
+			want: "0.2",
+		},
+		{
+			name: "major and minor",
+			v:    "0.3",
+			want: "0.3",
+		},
+		{
+			name: "empty",
+			v:    "",
+			want: "none",
+		},
+		{
+			name: "only major",
+			v:    "1",
+			want: "invalid",
+		},
+		{
+			name: "not a number",
+			v:    "one.two.three",
+			want: "invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionLabel(tt.v); got != tt.want {
+				t.Errorf("unexpected label: got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{
+			Key:      []byte("team-1/asset-1"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("0.0.1")}},
+		},
+		{
+			Key:      []byte("team-1/asset-2"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("0.0.2")}},
+		},
+		{
+			Key:      []byte("team-1/asset-3"),
+			Metadata: []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("7.1.0")}},
+		},
+		{
+			Key: []byte("team-1/asset-4"),
+		},
+	}
+
+	before := map[[2]string]float64{
+		{"0.0", "true"}:   messageVersionsTotal.Value("0.0", "true"),
+		{"7.1", "false"}:  messageVersionsTotal.Value("7.1", "false"),
+		{"none", "false"}: messageVersionsTotal.Value("none", "false"),
+	}
+
+	proc := versionProcessor{streamtest.NewMockProcessor(msgs)}
+
+	var n int
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len(msgs) {
+		t.Errorf("unexpected number of processed messages: got: %v, want: %v", n, len(msgs))
+	}
+
+	want := map[[2]string]float64{
+		{"0.0", "true"}:   2,
+		{"7.1", "false"}:  1,
+		{"none", "false"}: 1,
+	}
+	for labels, v := range want {
+		if got := messageVersionsTotal.Value(labels[0], labels[1]) - before[labels]; got != v {
+			t.Errorf("unexpected count for %v: got: %v, want: %v", labels, got, v)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+const (
+	// volumeWindow is the period considered to rank the assets by event
+	// volume.
+	volumeWindow = time.Hour
+
+	// volumeResolution is the duration of every bucket of the window.
+	volumeResolution = time.Minute
+
+	// defaultTopAssets is the number of assets returned by the top
+	// assets endpoint if it is not specified in the request.
+	defaultTopAssets = 10
+)
+
+// assetVolume is the number of events of an asset.
+type assetVolume struct {
+	AssetType  string `json:"asset_type"`
+	Identifier string `json:"identifier"`
+	Team       string `json:"team"`
+	Events     int    `json:"events"`
+}
+
+// volumeKey identifies the assets counted by an [eventVolume].
+type volumeKey struct {
+	typ        vulcan.AssetType
+	identifier string
+}
+
+// volumeBucket contains the number of events of every asset received
+// during a [volumeResolution] interval.
+type volumeBucket struct {
+	start  time.Time
+	events map[volumeKey]*assetVolume
+}
+
+// eventVolume counts the events received for every asset during the last
+// [volumeWindow], so the assets that dominate the load of the Asset
+// Inventory can be identified. The number of assets tracked in every
+// bucket is limited, so the events of new assets are not counted once the
+// limit is reached. It is safe for concurrent use.
+type eventVolume struct {
+	max int
+
+	mu      sync.Mutex
+	buckets []volumeBucket
+}
+
+// newEventVolume returns an [eventVolume] that tracks at most max assets in
+// every bucket. If max is zero, it returns nil.
+func newEventVolume(max int) *eventVolume {
+	if max <= 0 {
+		return nil
+	}
+	return &eventVolume{
+		max:     max,
+		buckets: make([]volumeBucket, volumeWindow/volumeResolution),
+	}
+}
+
+// record counts an event of the asset of payload received at the provided
+// time.
+func (v *eventVolume) record(payload vulcan.AssetPayload, at time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	start := at.Truncate(volumeResolution)
+	b := &v.buckets[(start.UnixNano()/int64(volumeResolution))%int64(len(v.buckets))]
+	if b.start.After(start) {
+		// The bucket already tracks a more recent interval, so the
+		// event is out of the window.
+		return
+	}
+	if b.start.Before(start) {
+		b.start = start
+		b.events = make(map[volumeKey]*assetVolume)
+	}
+
+	key := volumeKey{payload.AssetType, payload.Identifier}
+	av, ok := b.events[key]
+	if !ok {
+		if len(b.events) >= v.max {
+			return
+		}
+		av = &assetVolume{
+			AssetType:  string(payload.AssetType),
+			Identifier: payload.Identifier,
+		}
+		b.events[key] = av
+	}
+	av.Events++
+	if payload.Team.ID != "" {
+		av.Team = payload.Team.ID
+	}
+}
+
+// top returns the n assets with more events during the [volumeWindow]
+// preceding the provided time, sorted by number of events in descending
+// order.
+func (v *eventVolume) top(n int, at time.Time) []assetVolume {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	since := at.Add(-volumeWindow)
+	totals := make(map[volumeKey]*assetVolume)
+	for _, b := range v.buckets {
+		if !b.start.After(since) || b.start.After(at) {
+			continue
+		}
+		for k, av := range b.events {
+			t, ok := totals[k]
+			if !ok {
+				t = &assetVolume{AssetType: av.AssetType, Identifier: av.Identifier}
+				totals[k] = t
+			}
+			t.Events += av.Events
+			if av.Team != "" {
+				t.Team = av.Team
+			}
+		}
+	}
+
+	volumes := make([]assetVolume, 0, len(totals))
+	for _, t := range totals {
+		volumes = append(volumes, *t)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Events != volumes[j].Events {
+			return volumes[i].Events > volumes[j].Events
+		}
+		if volumes[i].AssetType != volumes[j].AssetType {
+			return volumes[i].AssetType < volumes[j].AssetType
+		}
+		return volumes[i].Identifier < volumes[j].Identifier
+	})
+
+	if len(volumes) > n {
+		volumes = volumes[:n]
+	}
+	return volumes
+}
+
+// handler returns a [vulcan.AssetHandler] that records the events of the
+// assets and calls h.
+func (v *eventVolume) handler(h vulcan.AssetHandler) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		v.record(payload, time.Now())
+		return h(payload, isNil)
+	}
+}
+
+// ServeHTTP implements the top assets endpoint of the admin API. The
+// number of assets is set with the "n" query parameter.
+func (v *eventVolume) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := defaultTopAssets
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n < 1 {
+			http.Error(w, "invalid number of assets", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v.top(n, time.Now()))
+}
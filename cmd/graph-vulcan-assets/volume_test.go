@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func volumePayload(typ, identifier, team string) vulcan.AssetPayload {
+	return vulcan.AssetPayload{
+		AssetType:  vulcan.AssetType(typ),
+		Identifier: identifier,
+		Team:       vulcan.Team{ID: team},
+	}
+}
+
+func TestEventVolumeTop(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+
+	v := newEventVolume(10)
+	for i := 0; i < 3; i++ {
+		v.record(volumePayload("Hostname", "noisy.example.com", "team-1"), now.Add(-time.Duration(i)*time.Minute))
+	}
+	v.record(volumePayload("Hostname", "quiet.example.com", "team-2"), now)
+	v.record(volumePayload("DockerImage", "busybox:latest", "team-2"), now.Add(-10*time.Minute))
+	v.record(volumePayload("DockerImage", "busybox:latest", ""), now.Add(-20*time.Minute))
+
+	// Outside of the window.
+	for i := 0; i < 5; i++ {
+		v.record(volumePayload("Hostname", "old.example.com", "team-3"), now.Add(-2*time.Hour))
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []assetVolume
+	}{
+		{
+			name: "all",
+			n:    10,
+			want: []assetVolume{
+				{AssetType: "Hostname", Identifier: "noisy.example.com", Team: "team-1", Events: 3},
+				{AssetType: "DockerImage", Identifier: "busybox:latest", Team: "team-2", Events: 2},
+				{AssetType: "Hostname", Identifier: "quiet.example.com", Team: "team-2", Events: 1},
+			},
+		},
+		{
+			name: "top 1",
+			n:    1,
+			want: []assetVolume{
+				{AssetType: "Hostname", Identifier: "noisy.example.com", Team: "team-1", Events: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := v.top(tt.n, now)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("top assets mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestEventVolumeMaxAssets(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+
+	v := newEventVolume(2)
+	for i := 0; i < 3; i++ {
+		v.record(volumePayload("Hostname", fmt.Sprintf("host%v.example.com", i), "team-1"), now)
+	}
+	v.record(volumePayload("Hostname", "host0.example.com", "team-1"), now)
+
+	// A new bucket tracks new assets.
+	v.record(volumePayload("Hostname", "host2.example.com", "team-1"), now.Add(time.Minute))
+
+	want := []assetVolume{
+		{AssetType: "Hostname", Identifier: "host0.example.com", Team: "team-1", Events: 2},
+		{AssetType: "Hostname", Identifier: "host1.example.com", Team: "team-1", Events: 1},
+		{AssetType: "Hostname", Identifier: "host2.example.com", Team: "team-1", Events: 1},
+	}
+	if diff := cmp.Diff(want, v.top(10, now.Add(time.Minute))); diff != "" {
+		t.Errorf("top assets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestNewEventVolumeDisabled(t *testing.T) {
+	if v := newEventVolume(0); v != nil {
+		t.Errorf("expected nil event volume: %v", v)
+	}
+}
+
+func TestEventVolumeServeHTTP(t *testing.T) {
+	v := newEventVolume(10)
+	h := v.handler(func(payload vulcan.AssetPayload, isNil bool) error { return nil })
+	h(volumePayload("Hostname", "host0.example.com", "team-1"), false)
+	h(volumePayload("Hostname", "host0.example.com", "team-1"), true)
+	h(volumePayload("Hostname", "host1.example.com", "team-1"), false)
+
+	mux := adminMux(newMaintenance(""), nil, newConsumerState(), nil, v)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		want       []assetVolume
+	}{
+		{
+			name:       "default",
+			method:     http.MethodGet,
+			target:     "/assets/top",
+			wantStatus: http.StatusOK,
+			want: []assetVolume{
+				{AssetType: "Hostname", Identifier: "host0.example.com", Team: "team-1", Events: 2},
+				{AssetType: "Hostname", Identifier: "host1.example.com", Team: "team-1", Events: 1},
+			},
+		},
+		{
+			name:       "n",
+			method:     http.MethodGet,
+			target:     "/assets/top?n=1",
+			wantStatus: http.StatusOK,
+			want: []assetVolume{
+				{AssetType: "Hostname", Identifier: "host0.example.com", Team: "team-1", Events: 2},
+			},
+		},
+		{
+			name:       "invalid n",
+			method:     http.MethodGet,
+			target:     "/assets/top?n=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid method",
+			method:     http.MethodPost,
+			target:     "/assets/top",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("unexpected status code: want=%v, got=%v", tt.wantStatus, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got []assetVolume
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("error decoding body: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("top assets mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
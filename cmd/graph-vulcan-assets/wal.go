@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/adevinta/graph-vulcan-assets/log"
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+	"github.com/adevinta/graph-vulcan-assets/wal"
+)
+
+// walOperation is an asset event recorded in the write-ahead log.
+type walOperation struct {
+	Payload vulcan.AssetPayload `json:"payload"`
+	IsNil   bool                `json:"is_nil"`
+}
+
+// assetWAL is the write-ahead log of the asset events being processed.
+type assetWAL interface {
+	Begin(v any) (uint64, error)
+	Done(seq uint64) error
+}
+
+// walHandler returns a [vulcan.AssetHandler] that records every asset event
+// in w before calling h and marks it as done after h returns, whether it
+// fails or not. Failed events are redelivered by the stream, so only the
+// events interrupted by a crash are left pending.
+func walHandler(w assetWAL, h vulcan.AssetHandler) vulcan.AssetHandler {
+	return func(payload vulcan.AssetPayload, isNil bool) error {
+		seq, err := w.Begin(walOperation{Payload: payload, IsNil: isNil})
+		if err != nil {
+			return fmt.Errorf("could not record event in write-ahead log: %w", err)
+		}
+
+		herr := h(payload, isNil)
+
+		if err := w.Done(seq); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not mark event as done in write-ahead log: %v", err)
+		}
+
+		return herr
+	}
+}
+
+// replayWAL processes the pending entries of the write-ahead log with h and
+// marks them as done. Entries that cannot be processed are logged and
+// discarded, because their messages are redelivered by the stream unless
+// their offsets were already committed.
+func replayWAL(w assetWAL, entries []wal.Entry, h vulcan.AssetHandler) {
+	if len(entries) > 0 {
+		log.Info.Printf("graph-vulcan-assets: replaying %v pending events from the write-ahead log", len(entries))
+	}
+
+	for _, e := range entries {
+		var op walOperation
+		if err := json.Unmarshal(e.Data, &op); err != nil {
+			log.Error.Printf("graph-vulcan-assets: invalid write-ahead log entry %v: %v", e.Seq, err)
+		} else if err := h(op.Payload, op.IsNil); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not replay write-ahead log entry %v: %v", e.Seq, err)
+		}
+
+		if err := w.Done(e.Seq); err != nil {
+			log.Error.Printf("graph-vulcan-assets: could not mark event as done in write-ahead log: %v", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+	"github.com/adevinta/graph-vulcan-assets/wal"
+)
+
+func TestWALHandler(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "wal")
+	w, _, err := wal.Open(name)
+	if err != nil {
+		t.Fatalf("error opening write-ahead log: %v", err)
+	}
+
+	errHandler := errors.New("handler error")
+	h := walHandler(w, func(payload vulcan.AssetPayload, isNil bool) error {
+		if w.Pending() != 1 {
+			t.Errorf("event not recorded before processing")
+		}
+		if payload.ID == "asset-2" {
+			return errHandler
+		}
+		return nil
+	})
+
+	if err := h(vulcan.AssetPayload{ID: "asset-1"}, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := h(vulcan.AssetPayload{ID: "asset-2"}, true); !errors.Is(err, errHandler) {
+		t.Errorf("unexpected error: got: %v, want: %v", err, errHandler)
+	}
+	if n := w.Pending(); n != 0 {
+		t.Errorf("unexpected number of pending events: %v", n)
+	}
+
+	// Simulate a crash while processing an event.
+	if _, err := w.Begin(walOperation{Payload: vulcan.AssetPayload{ID: "asset-3"}, IsNil: true}); err != nil {
+		t.Fatalf("error recording event: %v", err)
+	}
+	w.Close()
+
+	w, pending, err := wal.Open(name)
+	if err != nil {
+		t.Fatalf("error opening write-ahead log: %v", err)
+	}
+	defer w.Close()
+
+	var got []walOperation
+	replayWAL(w, pending, func(payload vulcan.AssetPayload, isNil bool) error {
+		got = append(got, walOperation{Payload: payload, IsNil: isNil})
+		return nil
+	})
+
+	want := []walOperation{{Payload: vulcan.AssetPayload{ID: "asset-3"}, IsNil: true}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("replayed events mismatch (-want +got):\n%v", diff)
+	}
+	if n := w.Pending(); n != 0 {
+		t.Errorf("unexpected number of pending events: %v", n)
+	}
+}
+
+func TestReplayWALInvalidEntry(t *testing.T) {
+	w, _, err := wal.Open(filepath.Join(t.TempDir(), "wal"))
+	if err != nil {
+		t.Fatalf("error opening write-ahead log: %v", err)
+	}
+	defer w.Close()
+
+	seq, err := w.Begin("invalid")
+	if err != nil {
+		t.Fatalf("error recording event: %v", err)
+	}
+
+	entries := []wal.Entry{{Seq: seq, Data: json.RawMessage(`"invalid"`)}}
+	replayWAL(w, entries, func(payload vulcan.AssetPayload, isNil bool) error {
+		t.Errorf("invalid entry replayed")
+		return nil
+	})
+	if n := w.Pending(); n != 0 {
+		t.Errorf("unexpected number of pending events: %v", n)
+	}
+}
@@ -0,0 +1,93 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		expr       string
+		wantNilErr bool
+	}{
+		{name: "every minute", expr: "* * * * *", wantNilErr: true},
+		{name: "lists ranges and steps", expr: "0,30 8-18/2 1-15 */3 1-5", wantNilErr: true},
+		{name: "descriptor", expr: "@weekly", wantNilErr: true},
+		{name: "missing field", expr: "* * * *", wantNilErr: false},
+		{name: "out of range", expr: "60 * * * *", wantNilErr: false},
+		{name: "inverted range", expr: "* 10-5 * * *", wantNilErr: false},
+		{name: "invalid step", expr: "*/0 * * * *", wantNilErr: false},
+		{name: "invalid value", expr: "a * * * *", wantNilErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2022-01-05 is a Wednesday.
+	from := time.Date(2022, 1, 5, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+			want: time.Date(2022, 1, 5, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly",
+			expr: "@hourly",
+			want: time.Date(2022, 1, 5, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly",
+			expr: "@weekly",
+			want: time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "every 15 minutes",
+			expr: "*/15 * * * *",
+			want: time.Date(2022, 1, 5, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 3 1 * 5",
+			want: time.Date(2022, 1, 7, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "next year",
+			expr: "0 0 1 1 *",
+			want: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "impossible date",
+			expr: "0 0 30 2 *",
+			want: time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := sched.Next(from)
+			if !got.Equal(tt.want) {
+				t.Errorf("unexpected time: want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Package containers provisions the testing infrastructure with
+// testcontainers, so the integration tests do not depend on services
+// started by other means and every package gets its own, isolated set of
+// services.
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	tcwait "github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+)
+
+// Images of the services of the testing infrastructure. They must be kept
+// in sync with _script/docker-compose.yml.
+const (
+	KafkaImage     = "confluentinc/cp-kafka:7.2.2"
+	ZookeeperImage = "confluentinc/cp-zookeeper:7.2.2"
+	GremlinImage   = "tinkerpop/gremlin-server:3.5.4"
+	InventoryImage = "adevinta/graph-asset-inventory-api:v0.6.0"
+)
+
+// startupTimeout is the maximum time to wait for a container to be ready.
+const startupTimeout = 3 * time.Minute
+
+// kafkaStarter is the path of the script that starts the kafka broker
+// inside its container. See [startKafka].
+const kafkaStarter = "/tmp/start-kafka.sh"
+
+// endpointVars are the environment variables that override the endpoints
+// of the testing infrastructure.
+var endpointVars = []string{
+	"TEST_KAFKA_BOOTSTRAP_SERVERS",
+	"TEST_INVENTORY_ENDPOINT",
+	"TEST_GREMLIN_ENDPOINT",
+}
+
+// Service is a service of the testing infrastructure.
+type Service int
+
+// Services of the testing infrastructure.
+const (
+	// Kafka is a kafka cluster with a single broker.
+	Kafka Service = iota
+
+	// Gremlin is a gremlin-server with an empty TinkerGraph.
+	Gremlin
+
+	// Inventory is an Asset Inventory backed by the Gremlin service,
+	// which is provisioned with it.
+	Inventory
+)
+
+// Main provisions the provided services with testcontainers, points the
+// endpoints of the testing infrastructure to them, runs the tests and
+// removes the containers. It returns the exit code of the tests and is
+// meant to be called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(containers.Main(m, containers.Kafka))
+//	}
+//
+// Every test binary gets its own containers, so the packages can be tested
+// in parallel. Nothing is provisioned if any of the TEST_* environment
+// variables is set, in which case the tests run against the configured
+// endpoints. If the services cannot be provisioned (e.g. docker is not
+// available), the tests that wait for them fail immediately. See
+// [testinfra.Unavailable].
+func Main(m *testing.M, services ...Service) int {
+	if external() {
+		return m.Run()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	infra, err := provision(ctx, services)
+	cancel()
+	if err != nil {
+		err = fmt.Errorf("could not provision testing infrastructure: %w", err)
+		fmt.Fprintf(os.Stderr, "containers: %v\n", err)
+		testinfra.Unavailable(err)
+	}
+
+	code := m.Run()
+
+	if err := infra.terminate(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "containers: could not remove testing infrastructure: %v\n", err)
+	}
+	return code
+}
+
+// external reports whether the testing infrastructure is provided
+// externally by means of the TEST_* environment variables.
+func external() bool {
+	for _, key := range endpointVars {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// provisioned is testing infrastructure provisioned with testcontainers.
+type provisioned struct {
+	network    testcontainers.Network
+	containers []testcontainers.Container
+}
+
+// provision starts the provided services in a dedicated docker network and
+// sets the TEST_* environment variables to their endpoints. The returned
+// infrastructure must be terminated even if an error is returned.
+func provision(ctx context.Context, services []Service) (infra *provisioned, err error) {
+	// Creating the docker provider panics in some environments
+	// without docker.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("docker is not available: %v", r)
+		}
+	}()
+
+	infra = &provisioned{}
+
+	name := fmt.Sprintf("%v-%v-%v", testinfra.ComposeProject, os.Getpid(), time.Now().UnixNano())
+	infra.network, err = testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{
+			Name:           name,
+			CheckDuplicate: true,
+		},
+	})
+	if err != nil {
+		return infra, fmt.Errorf("could not create network: %w", err)
+	}
+
+	var kafka, gremlin, inventory bool
+	for _, s := range services {
+		switch s {
+		case Kafka:
+			kafka = true
+		case Gremlin:
+			gremlin = true
+		case Inventory:
+			gremlin, inventory = true, true
+		default:
+			return infra, fmt.Errorf("unknown service %v", s)
+		}
+	}
+
+	if kafka {
+		addr, err := infra.startKafka(ctx, name)
+		if err != nil {
+			return infra, fmt.Errorf("could not start kafka: %w", err)
+		}
+		if err := os.Setenv("TEST_KAFKA_BOOTSTRAP_SERVERS", addr); err != nil {
+			return infra, err
+		}
+	}
+
+	if gremlin {
+		endpoint, err := infra.startGremlin(ctx, name)
+		if err != nil {
+			return infra, fmt.Errorf("could not start gremlin-server: %w", err)
+		}
+		if err := os.Setenv("TEST_GREMLIN_ENDPOINT", endpoint); err != nil {
+			return infra, err
+		}
+	}
+
+	if inventory {
+		endpoint, err := infra.startInventory(ctx, name)
+		if err != nil {
+			return infra, fmt.Errorf("could not start inventory: %w", err)
+		}
+		if err := os.Setenv("TEST_INVENTORY_ENDPOINT", endpoint); err != nil {
+			return infra, err
+		}
+	}
+
+	return infra, nil
+}
+
+// startKafka starts zookeeper and a kafka broker in the provided network
+// and returns the bootstrap servers of the cluster. The broker must
+// advertise the port mapped in the host, which is not known until the
+// container is started. So, the container waits for a script that sets the
+// advertised listeners and starts the broker.
+func (infra *provisioned) startKafka(ctx context.Context, network string) (string, error) {
+	if _, err := infra.start(ctx, network, "zookeeper", testcontainers.ContainerRequest{
+		Image:        ZookeeperImage,
+		ExposedPorts: []string{"2181/tcp"},
+		Env: map[string]string{
+			"ZOOKEEPER_CLIENT_PORT": "2181",
+			"ZOOKEEPER_TICK_TIME":   "2000",
+		},
+		WaitingFor: tcwait.ForListeningPort("2181/tcp").WithStartupTimeout(startupTimeout),
+	}); err != nil {
+		return "", err
+	}
+
+	c, err := infra.start(ctx, network, "kafka", testcontainers.ContainerRequest{
+		Image:        KafkaImage,
+		ExposedPorts: []string{"9092/tcp"},
+		Env: map[string]string{
+			"KAFKA_BROKER_ID":                        "1",
+			"KAFKA_ZOOKEEPER_CONNECT":                "zookeeper:2181",
+			"KAFKA_LISTENERS":                        "PLAINTEXT://0.0.0.0:29092,PLAINTEXT_HOST://0.0.0.0:9092",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":   "PLAINTEXT:PLAINTEXT,PLAINTEXT_HOST:PLAINTEXT",
+			"KAFKA_INTER_BROKER_LISTENER_NAME":       "PLAINTEXT",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR": "1",
+		},
+		Entrypoint: []string{"sh"},
+		Cmd:        []string{"-c", fmt.Sprintf("while [ ! -f %[1]v ]; do sleep 0.1; done; exec %[1]v", kafkaStarter)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := hostAddr(ctx, c, "9092/tcp")
+	if err != nil {
+		return "", err
+	}
+
+	script := fmt.Sprintf("#!/bin/bash\nexport KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://kafka:29092,PLAINTEXT_HOST://%v\nexec /etc/confluent/docker/run\n", addr)
+	if err := c.CopyToContainer(ctx, []byte(script), kafkaStarter, 0o755); err != nil {
+		return "", fmt.Errorf("could not copy starter script: %w", err)
+	}
+
+	ready := tcwait.ForLog("started (kafka.server.KafkaServer)").WithStartupTimeout(startupTimeout)
+	if err := ready.WaitUntilReady(ctx, c); err != nil {
+		return "", fmt.Errorf("broker is not ready: %w", err)
+	}
+	return addr, nil
+}
+
+// startGremlin starts a gremlin-server, configured with the files in
+// _script/gremlin-server/conf, in the provided network and returns its
+// endpoint.
+func (infra *provisioned) startGremlin(ctx context.Context, network string) (string, error) {
+	conf, err := gremlinConf()
+	if err != nil {
+		return "", err
+	}
+
+	c, err := infra.start(ctx, network, "gremlin-server", testcontainers.ContainerRequest{
+		Image:        GremlinImage,
+		ExposedPorts: []string{"8182/tcp"},
+		Files:        conf,
+		Entrypoint:   []string{"/opt/gremlin-server/bin/gremlin-server.sh"},
+		Cmd:          []string{"conf/gremlin-server.yaml"},
+		WaitingFor:   tcwait.ForListeningPort("8182/tcp").WithStartupTimeout(startupTimeout),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := hostAddr(ctx, c, "8182/tcp")
+	if err != nil {
+		return "", err
+	}
+	return "ws://" + addr + "/gremlin", nil
+}
+
+// startInventory starts an Asset Inventory in the provided network and
+// returns its endpoint. It must be called after [provisioned.startGremlin].
+func (infra *provisioned) startInventory(ctx context.Context, network string) (string, error) {
+	c, err := infra.start(ctx, network, "graph-asset-inventory-api", testcontainers.ContainerRequest{
+		Image:        InventoryImage,
+		ExposedPorts: []string{"8000/tcp"},
+		Env: map[string]string{
+			"FLASK_ENV":         "development",
+			"PORT":              "8000",
+			"GREMLIN_ENDPOINT":  "ws://gremlin-server:8182/gremlin",
+			"GREMLIN_AUTH_MODE": "none",
+		},
+		WaitingFor: tcwait.ForListeningPort("8000/tcp").WithStartupTimeout(startupTimeout),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := hostAddr(ctx, c, "8000/tcp")
+	if err != nil {
+		return "", err
+	}
+	return "http://" + addr, nil
+}
+
+// start starts a container in the provided network that is reachable from
+// the other containers of the network with the provided alias.
+func (infra *provisioned) start(ctx context.Context, network, alias string, req testcontainers.ContainerRequest) (testcontainers.Container, error) {
+	req.Networks = []string{network}
+	req.NetworkAliases = map[string][]string{network: {alias}}
+
+	c, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if c != nil {
+		infra.containers = append(infra.containers, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not start %v: %w", req.Image, err)
+	}
+	return c, nil
+}
+
+// terminate removes the containers and the network. It can be called on a
+// nil receiver.
+func (infra *provisioned) terminate(ctx context.Context) error {
+	if infra == nil {
+		return nil
+	}
+
+	var errs []error
+	for i := len(infra.containers) - 1; i >= 0; i-- {
+		if err := infra.containers[i].Terminate(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if infra.network != nil {
+		if err := infra.network.Remove(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// hostAddr returns the address of the host where the provided port of the
+// container is mapped.
+func hostAddr(ctx context.Context, c testcontainers.Container, port string) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not get host: %w", err)
+	}
+	mapped, err := c.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		return "", fmt.Errorf("could not get mapped port: %w", err)
+	}
+	return fmt.Sprintf("%v:%v", host, mapped.Port()), nil
+}
+
+// gremlinConf returns the configuration files of gremlin-server in
+// _script/gremlin-server/conf, which are located relative to this source
+// file.
+func gremlinConf() ([]testcontainers.ContainerFile, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, errors.New("could not get source file")
+	}
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "_script", "gremlin-server", "conf")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read gremlin-server configuration: %w", err)
+	}
+
+	var files []testcontainers.ContainerFile
+	for _, e := range entries {
+		files = append(files, testcontainers.ContainerFile{
+			HostFilePath:      filepath.Join(dir, e.Name()),
+			ContainerFilePath: "/opt/gremlin-server/conf/" + e.Name(),
+			FileMode:          0o644,
+		})
+	}
+	return files, nil
+}
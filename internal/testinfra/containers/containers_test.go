@@ -0,0 +1,41 @@
+package containers
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestExternal(t *testing.T) {
+	for _, key := range endpointVars {
+		t.Setenv(key, "")
+	}
+	if external() {
+		t.Errorf("unexpected external infrastructure")
+	}
+
+	t.Setenv("TEST_GREMLIN_ENDPOINT", "ws://gremlin-server:8182/gremlin")
+	if !external() {
+		t.Errorf("expected external infrastructure")
+	}
+}
+
+func TestGremlinConf(t *testing.T) {
+	files, err := gremlinConf()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var found bool
+	for _, f := range files {
+		if _, err := os.Stat(f.HostFilePath); err != nil {
+			t.Errorf("unexpected host file: %v", err)
+		}
+		if path.Base(f.ContainerFilePath) == "gremlin-server.yaml" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("gremlin-server.yaml not found in %v", files)
+	}
+}
@@ -0,0 +1,163 @@
+package testinfra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	gremlingo "github.com/apache/tinkerpop/gremlin-go/v3/driver"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// ComposeProject is the name of the docker-compose project of the testing
+// infrastructure. It is the same project used by _script/setup, so both
+// share the same containers.
+const ComposeProject = "graph-vulcan-assets"
+
+// adminTimeout is the timeout of the kafka admin operations.
+const adminTimeout = 30 * time.Second
+
+// Provision starts the testing infrastructure defined in the provided
+// docker-compose file and waits until it is healthy. The output of
+// docker-compose is written to stderr.
+func Provision(ctx context.Context, composeFile string) error {
+	if err := compose(ctx, composeFile, "run", "setup"); err != nil {
+		return fmt.Errorf("could not provision testing infrastructure: %w", err)
+	}
+	return nil
+}
+
+// Teardown stops and removes the containers of the testing infrastructure
+// defined in the provided docker-compose file.
+func Teardown(ctx context.Context, composeFile string) error {
+	if err := compose(ctx, composeFile, "rm", "-s", "-f"); err != nil {
+		return fmt.Errorf("could not tear down testing infrastructure: %w", err)
+	}
+	return nil
+}
+
+// compose runs docker-compose with the provided arguments in the project
+// of the testing infrastructure.
+func compose(ctx context.Context, composeFile string, args ...string) error {
+	args = append([]string{"-p", ComposeProject, "-f", composeFile}, args...)
+	cmd := exec.CommandContext(ctx, "docker-compose", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Wait waits until kafka, the Asset Inventory and gremlin-server are ready
+// or ctx is done.
+func Wait(ctx context.Context) error {
+	for _, wait := range []func(context.Context) error{WaitKafka, WaitGremlin, WaitInventory} {
+		if err := wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetTopic deletes the provided topic of the testing kafka cluster, so it
+// does not contain the messages of previous runs. The topic is created
+// again when the first message is produced to it.
+func ResetTopic(ctx context.Context, topic string) error {
+	admin, err := kafka.NewAdminClient(&kafka.ConfigMap{
+		"bootstrap.servers": KafkaBootstrapServers(),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating admin client: %w", err)
+	}
+	defer admin.Close()
+
+	opts := []kafka.DeleteTopicsAdminOption{
+		kafka.SetAdminRequestTimeout(adminTimeout),
+		kafka.SetAdminOperationTimeout(adminTimeout),
+	}
+	if _, err := admin.DeleteTopics(ctx, []string{topic}, opts...); err != nil {
+		return fmt.Errorf("error deleting topic: %w", err)
+	}
+	return nil
+}
+
+// LoadFixtures produces msgs to the provided topic of the testing kafka
+// cluster and waits until all of them are delivered. Metadata entries are
+// sent as kafka headers.
+func LoadFixtures(ctx context.Context, topic string, msgs []stream.Message) error {
+	prod, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": KafkaBootstrapServers(),
+
+		// Set message timeout to 5s, so the kafka client returns an
+		// error if the broker is not up.
+		"message.timeout.ms": 5000,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating producer: %w", err)
+	}
+	defer prod.Close()
+
+	// The messages are delivered one by one, so they keep their order
+	// even if the topic has several partitions.
+	for _, msg := range msgs {
+		if err := produce(ctx, prod, topic, msg); err != nil {
+			return fmt.Errorf("error producing message: %w", err)
+		}
+	}
+	return nil
+}
+
+// produce delivers msg to topic using prod and waits for its delivery
+// report or until ctx is done.
+func produce(ctx context.Context, prod *kafka.Producer, topic string, msg stream.Message) error {
+	kmsg := &kafka.Message{
+		Key:            msg.Key,
+		Value:          msg.Value,
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+	}
+	for _, e := range msg.Metadata {
+		kmsg.Headers = append(kmsg.Headers, kafka.Header{Key: string(e.Key), Value: e.Value})
+	}
+
+	// The channel is buffered, so the delivery report does not block
+	// the producer if ctx is done before it is received.
+	delivery := make(chan kafka.Event, 1)
+	if err := prod.Produce(kmsg, delivery); err != nil {
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("could not wait for delivery report: %w", ctx.Err())
+	case e := <-delivery:
+		kmsg, ok := e.(*kafka.Message)
+		if !ok {
+			return fmt.Errorf("unexpected delivery event %T", e)
+		}
+		if kmsg.TopicPartition.Error != nil {
+			return fmt.Errorf("could not deliver message: %w", kmsg.TopicPartition.Error)
+		}
+	}
+	return nil
+}
+
+// ResetGraph removes all the vertices of the testing Security Graph but the
+// Universe vertex created by the Asset Inventory.
+func ResetGraph() error {
+	conn, err := gremlingo.NewDriverRemoteConnection(GremlinEndpoint(), func(settings *gremlingo.DriverRemoteConnectionSettings) {
+		settings.LogVerbosity = gremlingo.Off
+	})
+	if err != nil {
+		return fmt.Errorf("could not connect to gremlin-server: %w", err)
+	}
+	defer conn.Close()
+
+	g := gremlingo.Traversal_().WithRemote(conn)
+
+	if err := <-g.V().Not(gremlingo.T__.HasLabel("Universe")).Drop().Iterate(); err != nil {
+		return fmt.Errorf("could not drop vertices: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package testinfra provides the endpoints of the testing infrastructure used
+// by the integration tests, helpers to wait until it is ready and helpers to
+// provision it, reset it and load fixtures, which are shared by the
+// integration tests and the test-env command.
+//
+// The integration tests provision their own infrastructure with package
+// [github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers],
+// which points the endpoints to the started containers. Otherwise, the
+// endpoints point to the services started by _script/setup. They can be
+// overridden with the following environment variables, so the integration
+// tests can run against infrastructure provisioned by other means (e.g. a
+// CI service or a different docker-compose project):
+//
+//   - TEST_KAFKA_BOOTSTRAP_SERVERS
+//   - TEST_INVENTORY_ENDPOINT
+//   - TEST_GREMLIN_ENDPOINT
+package testinfra
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default endpoints of the testing infrastructure.
+const (
+	DefaultKafkaBootstrapServers = "127.0.0.1:9092"
+	DefaultInventoryEndpoint     = "http://127.0.0.1:8000"
+	DefaultGremlinEndpoint       = "ws://127.0.0.1:8182/gremlin"
+)
+
+// pollInterval is the time between readiness checks.
+const pollInterval = 500 * time.Millisecond
+
+// errUnavailable is the reason why the testing infrastructure is not
+// available. See [Unavailable].
+var errUnavailable error
+
+// Unavailable records that the testing infrastructure is not available
+// because of err, e.g. because it could not be provisioned. From then on,
+// the wait functions return err immediately instead of waiting for
+// infrastructure that will never be ready.
+func Unavailable(err error) {
+	errUnavailable = err
+}
+
+// KafkaBootstrapServers returns the bootstrap servers of the testing kafka
+// cluster.
+func KafkaBootstrapServers() string {
+	return getenv("TEST_KAFKA_BOOTSTRAP_SERVERS", DefaultKafkaBootstrapServers)
+}
+
+// InventoryEndpoint returns the endpoint of the testing Asset Inventory.
+func InventoryEndpoint() string {
+	return getenv("TEST_INVENTORY_ENDPOINT", DefaultInventoryEndpoint)
+}
+
+// GremlinEndpoint returns the endpoint of the testing gremlin-server.
+func GremlinEndpoint() string {
+	return getenv("TEST_GREMLIN_ENDPOINT", DefaultGremlinEndpoint)
+}
+
+// WaitKafka waits until the first bootstrap server of the testing kafka
+// cluster accepts connections or ctx is done.
+func WaitKafka(ctx context.Context) error {
+	addr, _, _ := strings.Cut(KafkaBootstrapServers(), ",")
+	return wait(ctx, "kafka", func() error {
+		return dial(ctx, addr)
+	})
+}
+
+// WaitInventory waits until the testing Asset Inventory responds
+// successfully to a request to its root path or ctx is done.
+func WaitInventory(ctx context.Context) error {
+	return wait(ctx, "inventory", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, InventoryEndpoint(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// WaitGremlin waits until the testing gremlin-server accepts connections or
+// ctx is done.
+func WaitGremlin(ctx context.Context) error {
+	endpoint := GremlinEndpoint()
+	endpoint = strings.TrimPrefix(endpoint, "ws://")
+	endpoint = strings.TrimPrefix(endpoint, "wss://")
+	addr, _, _ := strings.Cut(endpoint, "/")
+	return wait(ctx, "gremlin-server", func() error {
+		return dial(ctx, addr)
+	})
+}
+
+// wait calls check until it succeeds or ctx is done. It fails immediately
+// if the testing infrastructure is unavailable. See [Unavailable].
+func wait(ctx context.Context, name string, check func() error) error {
+	if errUnavailable != nil {
+		return fmt.Errorf("%v is not available: %w", name, errUnavailable)
+	}
+
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v is not ready: %w", name, err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// dial opens and closes a TCP connection with addr.
+func dial(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,95 @@
+package testinfra
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEndpoints(t *testing.T) {
+	t.Setenv("TEST_KAFKA_BOOTSTRAP_SERVERS", "")
+	if got := KafkaBootstrapServers(); got != DefaultKafkaBootstrapServers {
+		t.Errorf("unexpected default: want=%v, got=%v", DefaultKafkaBootstrapServers, got)
+	}
+
+	t.Setenv("TEST_KAFKA_BOOTSTRAP_SERVERS", "kafka:9092")
+	if got := KafkaBootstrapServers(); got != "kafka:9092" {
+		t.Errorf("unexpected value: want=%v, got=%v", "kafka:9092", got)
+	}
+}
+
+func TestWaitKafka(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	defer ln.Close()
+
+	t.Setenv("TEST_KAFKA_BOOTSTRAP_SERVERS", ln.Addr().String()+",127.0.0.1:1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := WaitKafka(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitInventory(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	t.Setenv("TEST_INVENTORY_ENDPOINT", ts.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := WaitInventory(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("unexpected number of calls: want=2, got=%v", calls)
+	}
+}
+
+func TestWaitGremlinTimeout(t *testing.T) {
+	t.Setenv("TEST_GREMLIN_ENDPOINT", "ws://127.0.0.1:1/gremlin")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := WaitGremlin(ctx); err == nil {
+		t.Errorf("expected error waiting for unreachable endpoint")
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	defer Unavailable(nil)
+
+	errDocker := errors.New("docker is not available")
+	Unavailable(errDocker)
+
+	t.Setenv("TEST_GREMLIN_ENDPOINT", "ws://127.0.0.1:1/gremlin")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	if err := WaitGremlin(ctx); !errors.Is(err, errDocker) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > pollInterval {
+		t.Errorf("waited for unavailable infrastructure: %v", elapsed)
+	}
+}
@@ -0,0 +1,128 @@
+package inventory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ExpireAssets sets the expiration of the provided assets to at. The assets
+// are updated concurrently with at most parallelism requests in flight. If
+// parallelism is lower than one, the assets are updated sequentially. It
+// returns the first error found, if any, after all the requests have
+// finished.
+func ExpireAssets(inv Inventory, assets []AssetResp, at time.Time, parallelism int) error {
+	return forEach(assets, parallelism, func(a AssetResp) error {
+		if _, err := inv.UpdateAsset(a.ID, a.Type, a.Identifier, at, at); err != nil {
+			return fmt.Errorf("could not expire asset %v: %w", a.ID, err)
+		}
+		return nil
+	})
+}
+
+// ExpireOwners sets to at the end time of the provided owns relations. The
+// relations are updated concurrently with at most parallelism requests in
+// flight. If parallelism is lower than one, the relations are updated
+// sequentially. It returns the first error found, if any, after all the
+// requests have finished.
+func ExpireOwners(inv Inventory, owners []OwnsResp, at time.Time, parallelism int) error {
+	return forEach(owners, parallelism, func(o OwnsResp) error {
+		if _, err := inv.UpsertOwner(o.AssetID, o.TeamID, o.StartTime, at); err != nil {
+			return fmt.Errorf("could not expire owns relation %v: %w", o.ID, err)
+		}
+		return nil
+	})
+}
+
+// ExpireRelations sets to at the expiration of the unexpired parent-of
+// relations of the provided assets, either as parent or as child. Relations
+// are retrieved using pages of size pageSize and updated concurrently with
+// at most parallelism requests in flight. If parallelism is lower than one,
+// the relations are updated sequentially. It returns the first error found,
+// if any, after all the requests have finished.
+func ExpireRelations(inv Inventory, assetIDs []string, at time.Time, pageSize, parallelism int) error {
+	var (
+		mu   sync.Mutex
+		rels []ParentOfResp
+		seen = make(map[string]bool)
+	)
+
+	add := func(ps []ParentOfResp) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, p := range ps {
+			if seen[p.ID] || IsExpired(p.Expiration, at) {
+				continue
+			}
+			seen[p.ID] = true
+			rels = append(rels, p)
+		}
+	}
+
+	err := forEach(assetIDs, parallelism, func(id string) error {
+		parents, err := AllParents(inv, id, pageSize)
+		if err != nil {
+			return fmt.Errorf("could not get parents of %v: %w", id, err)
+		}
+		add(parents)
+
+		children, err := AllChildren(inv, id, pageSize)
+		if err != nil {
+			return fmt.Errorf("could not get children of %v: %w", id, err)
+		}
+		add(children)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return forEach(rels, parallelism, func(p ParentOfResp) error {
+		if _, err := inv.UpsertParent(p.ChildID, p.ParentID, at, at); err != nil {
+			return fmt.Errorf("could not expire parent-of relation %v: %w", p.ID, err)
+		}
+		return nil
+	})
+}
+
+// forEach calls f for every item with at most parallelism concurrent calls.
+// If parallelism is lower than one, f is called sequentially. It returns the
+// first error returned by f, if any, after all the calls have finished.
+func forEach[T any](items []T, parallelism int, f func(T) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	sem := make(chan struct{}, parallelism)
+	for _, item := range items {
+		item := item
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := f(item); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
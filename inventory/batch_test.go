@@ -0,0 +1,239 @@
+package inventory_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+)
+
+var (
+	t0 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 = time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestExpireAssets(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+
+	var assets []inventory.AssetResp
+	for i := 0; i < 10; i++ {
+		a, err := inv.CreateAsset("Hostname", fmt.Sprintf("host%v.example.com", i), t0, inventory.Unexpired)
+		if err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+		assets = append(assets, a)
+	}
+
+	if err := inventory.ExpireAssets(inv, assets[:5], t1, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := inventory.AllAssets(inv, "Hostname", "", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("error getting assets: %v", err)
+	}
+
+	for i, a := range got {
+		want := inventory.Unexpired
+		if i < 5 {
+			want = t1
+		}
+		if !a.Expiration.Equal(want) {
+			t.Errorf("unexpected expiration of %v: want=%v got=%v", a.Identifier, want, a.Expiration)
+		}
+	}
+}
+
+func TestExpireAssetsError(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+
+	assets := []inventory.AssetResp{{ID: "unknown", Type: "Hostname", Identifier: "example.com"}}
+	if err := inventory.ExpireAssets(inv, assets, t1, 3); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v got=%v", inventory.ErrNotFound, err)
+	}
+}
+
+func TestExpireOwners(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+
+	asset, err := inv.CreateAsset("AWSAccount", "arn:aws:iam::123456789012:root", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+
+	var owners []inventory.OwnsResp
+	for i := 0; i < 10; i++ {
+		team, err := inv.CreateTeam(fmt.Sprintf("team-%v", i), fmt.Sprintf("Team %v", i))
+		if err != nil {
+			t.Fatalf("error creating team: %v", err)
+		}
+		o, err := inv.UpsertOwner(asset.ID, team.ID, t0, time.Time{})
+		if err != nil {
+			t.Fatalf("error creating owner: %v", err)
+		}
+		owners = append(owners, o)
+	}
+
+	if err := inventory.ExpireOwners(inv, owners[:5], t1, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := inventory.AllOwners(inv, asset.ID, 0)
+	if err != nil {
+		t.Fatalf("error getting owners: %v", err)
+	}
+
+	for i, o := range got {
+		if i < 5 {
+			if o.EndTime == nil || !o.EndTime.Equal(t1) {
+				t.Errorf("unexpected end time of %v: want=%v got=%v", o.ID, t1, o.EndTime)
+			}
+			continue
+		}
+		if o.EndTime != nil {
+			t.Errorf("unexpected end time of %v: %v", o.ID, o.EndTime)
+		}
+		if !o.StartTime.Equal(t0) {
+			t.Errorf("unexpected start time of %v: want=%v got=%v", o.ID, t0, o.StartTime)
+		}
+	}
+}
+
+func TestExpireOwnersError(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+
+	owners := []inventory.OwnsResp{{ID: "unknown", AssetID: "unknown", TeamID: "unknown"}}
+	if err := inventory.ExpireOwners(inv, owners, t1, 3); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v got=%v", inventory.ErrNotFound, err)
+	}
+}
+
+func TestExpireRelations(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+
+	newAsset := func(identifier string) inventory.AssetResp {
+		a, err := inv.CreateAsset("Hostname", identifier, t0, inventory.Unexpired)
+		if err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+		return a
+	}
+
+	root := newAsset("root")
+	parent := newAsset("parent")
+	child1 := newAsset("child1")
+	child2 := newAsset("child2")
+	other := newAsset("other")
+
+	rels := []struct {
+		child, parent inventory.AssetResp
+		expiration    time.Time
+	}{
+		{child: parent, parent: root, expiration: inventory.Unexpired},
+		{child: child1, parent: parent, expiration: inventory.Unexpired},
+		{child: child2, parent: parent, expiration: t0},
+		{child: other, parent: root, expiration: inventory.Unexpired},
+	}
+	for _, r := range rels {
+		if _, err := inv.UpsertParent(r.child.ID, r.parent.ID, t0, r.expiration); err != nil {
+			t.Fatalf("error creating relation: %v", err)
+		}
+	}
+
+	if err := inventory.ExpireRelations(inv, []string{parent.ID, child1.ID}, t1, 1, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := map[string]string{
+		root.ID:   "root",
+		parent.ID: "parent",
+		child1.ID: "child1",
+		child2.ID: "child2",
+		other.ID:  "other",
+	}
+
+	got := make(map[string]time.Time)
+	for _, a := range []inventory.AssetResp{root, parent} {
+		children, err := inventory.AllChildren(inv, a.ID, 0)
+		if err != nil {
+			t.Fatalf("error getting children: %v", err)
+		}
+		for _, c := range children {
+			got[names[c.ParentID]+"->"+names[c.ChildID]] = c.Expiration
+		}
+	}
+
+	want := map[string]time.Time{
+		"root->parent":   t1,
+		"parent->child1": t1,
+		"parent->child2": t0,
+		"root->other":    inventory.Unexpired,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("expirations mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestExpireRelationsParallelism(t *testing.T) {
+	inv := &countingInventory{InMemory: inventorytest.NewInMemory()}
+
+	parent, err := inv.CreateAsset("Hostname", "parent", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		child, err := inv.CreateAsset("Hostname", fmt.Sprintf("child%v", i), t0, inventory.Unexpired)
+		if err != nil {
+			t.Fatalf("error creating asset: %v", err)
+		}
+		if _, err := inv.UpsertParent(child.ID, parent.ID, t0, inventory.Unexpired); err != nil {
+			t.Fatalf("error creating relation: %v", err)
+		}
+	}
+
+	inv.upserts.Store(0)
+
+	const parallelism = 4
+	if err := inventory.ExpireRelations(inv, []string{parent.ID}, t1, 0, parallelism); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := inv.upserts.Load(); n != 20 {
+		t.Errorf("unexpected number of updates: want=20 got=%v", n)
+	}
+	if n := inv.maxInFlight.Load(); n > parallelism {
+		t.Errorf("too many concurrent requests: max=%v got=%v", parallelism, n)
+	}
+}
+
+// countingInventory wraps an [inventorytest.InMemory] and keeps track of the
+// calls to UpsertParent.
+type countingInventory struct {
+	*inventorytest.InMemory
+
+	upserts     atomic.Int64
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+}
+
+func (inv *countingInventory) UpsertParent(childID, parentID string, timestamp, expiration time.Time) (inventory.ParentOfResp, error) {
+	inv.upserts.Add(1)
+
+	n := inv.inFlight.Add(1)
+	defer inv.inFlight.Add(-1)
+	for {
+		max := inv.maxInFlight.Load()
+		if n <= max || inv.maxInFlight.CompareAndSwap(max, n) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return inv.InMemory.UpsertParent(childID, parentID, timestamp, expiration)
+}
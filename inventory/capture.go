@@ -0,0 +1,172 @@
+package inventory
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Exchange is an HTTP request sent to the Asset Inventory and its response,
+// as recorded by a [Capture]. Headers are not recorded, so credentials are
+// never captured.
+type Exchange struct {
+	Time              time.Time     `json:"time"`
+	Duration          time.Duration `json:"duration"`
+	Method            string        `json:"method"`
+	Host              string        `json:"host"`
+	Path              string        `json:"path"`
+	Status            int           `json:"status,omitempty"`
+	RequestBody       string        `json:"request_body,omitempty"`
+	ResponseBody      string        `json:"response_body,omitempty"`
+	RequestTruncated  bool          `json:"request_truncated,omitempty"`
+	ResponseTruncated bool          `json:"response_truncated,omitempty"`
+	Error             string        `json:"error,omitempty"`
+}
+
+// Capture is a ring buffer that keeps the last HTTP exchanges between a
+// [Client] and the Asset Inventory. Bodies are truncated to a maximum size.
+// It is safe for concurrent use, so it can be shared by several clients.
+type Capture struct {
+	maxBody int
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	next      int
+	full      bool
+}
+
+// NewCapture returns a [Capture] that keeps the last size exchanges and
+// truncates their bodies to maxBody bytes.
+func NewCapture(size, maxBody int) *Capture {
+	return &Capture{
+		maxBody:   maxBody,
+		exchanges: make([]Exchange, size),
+	}
+}
+
+// record adds ex to the capture. If the capture is full, the oldest
+// exchange is discarded.
+func (c *Capture) record(ex Exchange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.exchanges) == 0 {
+		return
+	}
+
+	c.exchanges[c.next] = ex
+	c.next = (c.next + 1) % len(c.exchanges)
+	if c.next == 0 {
+		c.full = true
+	}
+}
+
+// Exchanges returns the captured exchanges from the oldest to the newest.
+func (c *Capture) Exchanges() []Exchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.full {
+		return append([]Exchange(nil), c.exchanges[:c.next]...)
+	}
+	exchanges := make([]Exchange, 0, len(c.exchanges))
+	exchanges = append(exchanges, c.exchanges[c.next:]...)
+	return append(exchanges, c.exchanges[:c.next]...)
+}
+
+// WithCapture makes the client record its HTTP exchanges with the Asset
+// Inventory in c. By default, exchanges are not recorded.
+func WithCapture(c *Capture) ClientOption {
+	return func(cli *Client) {
+		cli.capture = c
+	}
+}
+
+// captureTransport is an [http.RoundTripper] that records the exchanges
+// sent through the underlying transport.
+type captureTransport struct {
+	next    http.RoundTripper
+	capture *Capture
+}
+
+// RoundTrip implements [http.RoundTripper]. The exchange is recorded when
+// the response body is closed, so the recorded response body contains the
+// bytes read by the client.
+func (t captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := Exchange{
+		Time:   time.Now(),
+		Method: req.Method,
+		Host:   req.URL.Host,
+		Path:   req.URL.RequestURI(),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		ex.RequestBody, ex.RequestTruncated = truncate(body, t.capture.maxBody)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		ex.Duration = time.Since(ex.Time)
+		ex.Error = err.Error()
+		t.capture.record(ex)
+		return nil, err
+	}
+
+	ex.Status = resp.StatusCode
+	resp.Body = &captureBody{
+		ReadCloser: resp.Body,
+		capture:    t.capture,
+		ex:         ex,
+	}
+	return resp, nil
+}
+
+// captureBody is a response body that records the exchange of the
+// response when it is closed.
+type captureBody struct {
+	io.ReadCloser
+	capture *Capture
+	ex      Exchange
+	buf     bytes.Buffer
+	once    sync.Once
+}
+
+// Read implements [io.Reader].
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := b.capture.maxBody + 1 - b.buf.Len(); room > 0 {
+		if n < room {
+			room = n
+		}
+		b.buf.Write(p[:room])
+	}
+	return n, err
+}
+
+// Close implements [io.Closer].
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.ex.Duration = time.Since(b.ex.Time)
+		b.ex.ResponseBody, b.ex.ResponseTruncated = truncate(b.buf.Bytes(), b.capture.maxBody)
+		b.capture.record(b.ex)
+	})
+	return err
+}
+
+// truncate returns the first max bytes of body as a string and whether it
+// has been truncated.
+func truncate(body []byte, max int) (string, bool) {
+	if len(body) <= max {
+		return string(body), false
+	}
+	return string(body[:max]), true
+}
@@ -0,0 +1,200 @@
+package inventory
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCapture(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `[{"id": "team-1", "identifier": "t1", "name": "Team 1"}]`)
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer srv.Close()
+
+	capture := NewCapture(2, 16)
+	cli, err := NewClient(srv.URL, false, WithCapture(capture))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if err := cli.Ping(); err != nil {
+		t.Fatalf("error pinging: %v", err)
+	}
+	if _, err := cli.Teams("t1", Pagination{}); err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if _, err := cli.CreateTeam("t1", "Team 1"); err != ErrAlreadyExists {
+		t.Fatalf("unexpected error creating team: %v", err)
+	}
+
+	want := []Exchange{
+		{
+			Method:            http.MethodGet,
+			Path:              "/v1/teams?team_identifier=t1",
+			Status:            http.StatusOK,
+			ResponseBody:      `[{"id": "team-1"`,
+			ResponseTruncated: true,
+		},
+		{
+			Method:           http.MethodPost,
+			Path:             "/v1/teams",
+			Status:           http.StatusConflict,
+			RequestBody:      `{"identifier":"t`,
+			RequestTruncated: true,
+		},
+	}
+	got := capture.Exchanges()
+	opts := cmpopts.IgnoreFields(Exchange{}, "Time", "Duration", "Host")
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("exchanges mismatch (-want +got):\n%v", diff)
+	}
+	for _, ex := range got {
+		if ex.Host != srv.Listener.Addr().String() {
+			t.Errorf("unexpected host: %v", ex.Host)
+		}
+	}
+}
+
+func TestCaptureTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	capture := NewCapture(10, 16)
+	cli, err := NewClient(srv.URL, false, WithCapture(capture))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if _, err := cli.Teams("", Pagination{}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	got := capture.Exchanges()
+	if len(got) != 1 {
+		t.Fatalf("unexpected number of exchanges: %v", len(got))
+	}
+	if got[0].Error == "" || got[0].Status != 0 {
+		t.Errorf("unexpected exchange: %+v", got[0])
+	}
+}
+
+func TestCaptureExchanges(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		n    int
+		want []string
+	}{
+		{
+			name: "empty",
+			size: 3,
+			n:    0,
+			want: nil,
+		},
+		{
+			name: "not full",
+			size: 3,
+			n:    2,
+			want: []string{"/0", "/1"},
+		},
+		{
+			name: "full",
+			size: 3,
+			n:    3,
+			want: []string{"/0", "/1", "/2"},
+		},
+		{
+			name: "wrapped",
+			size: 3,
+			n:    5,
+			want: []string{"/2", "/3", "/4"},
+		},
+		{
+			name: "zero size",
+			size: 0,
+			n:    2,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCapture(tt.size, 0)
+			for i := 0; i < tt.n; i++ {
+				c.record(Exchange{Path: fmt.Sprintf("/%v", i)})
+			}
+
+			var got []string
+			for _, ex := range c.Exchanges() {
+				got = append(got, ex.Path)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("exchanges mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestTransportWrapper(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	wrap := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}
+			return next.RoundTrip(req)
+		})
+	}
+
+	capture := NewCapture(2, 16)
+	cli, err := NewClient(srv.URL, false, WithTransportWrapper(wrap), WithCapture(capture))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if _, err := cli.Teams("t1", Pagination{}); err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if _, err := cli.CreateTeam("t1", "Team 1"); !IsTransient(err) {
+		t.Fatalf("unexpected error creating team: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("unexpected number of requests: want=1 got=%v", requests)
+	}
+
+	var statuses []int
+	for _, ex := range capture.Exchanges() {
+		statuses = append(statuses, ex.Status)
+	}
+	if diff := cmp.Diff([]int{http.StatusOK, http.StatusServiceUnavailable}, statuses); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%v", diff)
+	}
+}
+
+// roundTripperFunc is an [http.RoundTripper] implemented by a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,37 @@
+package inventory
+
+import "time"
+
+// unexpiredYear is the first year considered to be a sentinel for unexpired
+// entities. The current version of the Asset Inventory uses [Unexpired], but
+// other versions can use different sentinels, like the last second of the
+// year 9999.
+const unexpiredYear = 9999
+
+// IsUnexpired reports whether t is a sentinel expiration assigned to
+// unexpired entities. Besides [Unexpired], any time in the year 9999 or
+// later is considered a sentinel, as well as the zero time, which is the
+// value of missing expirations.
+func IsUnexpired(t time.Time) bool {
+	return t.IsZero() || t.UTC().Year() >= unexpiredYear
+}
+
+// IsExpired reports whether an entity with expiration t is expired at the
+// provided time. An entity is expired at its expiration time. Entities whose
+// expiration is a sentinel (see [IsUnexpired]) never expire.
+func IsExpired(t time.Time, at time.Time) bool {
+	if IsUnexpired(t) {
+		return false
+	}
+	return !t.After(at)
+}
+
+// Expiration returns the expiration that must be sent to the Asset
+// Inventory for an entity that expires at the provided time. If at is the
+// zero time, the entity does not expire and [Unexpired] is returned.
+func Expiration(at time.Time) time.Time {
+	if at.IsZero() {
+		return Unexpired
+	}
+	return at
+}
@@ -0,0 +1,71 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsExpired(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expiration time.Time
+		want       bool
+	}{
+		{
+			name:       "unexpired",
+			expiration: Unexpired,
+			want:       false,
+		},
+		{
+			name:       "alternative sentinel",
+			expiration: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC),
+			want:       false,
+		},
+		{
+			name:       "alternative sentinel in other location",
+			expiration: time.Date(9999, 12, 31, 23, 59, 59, 0, time.FixedZone("CET", 3600)),
+			want:       false,
+		},
+		{
+			name:       "missing expiration",
+			expiration: time.Time{},
+			want:       false,
+		},
+		{
+			name:       "past",
+			expiration: at.Add(-time.Second),
+			want:       true,
+		},
+		{
+			name:       "now",
+			expiration: at,
+			want:       true,
+		},
+		{
+			name:       "future",
+			expiration: at.Add(time.Second),
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExpired(tt.expiration, at); got != tt.want {
+				t.Errorf("unexpected result: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	if got := Expiration(time.Time{}); !got.Equal(Unexpired) {
+		t.Errorf("unexpected expiration: want=%v got=%v", Unexpired, got)
+	}
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := Expiration(at); !got.Equal(at) {
+		t.Errorf("unexpected expiration: want=%v got=%v", at, got)
+	}
+}
@@ -0,0 +1,5 @@
+package api
+
+// The client is generated from openapi.yaml, which contains the subset of the
+// Graph Asset Inventory REST API used by the inventory package.
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen@v1.12.4 -config config.yaml openapi.yaml
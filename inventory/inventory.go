@@ -6,15 +6,20 @@ package inventory
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"path"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory/internal/api"
 )
 
 var (
@@ -26,6 +31,10 @@ var (
 	// already exists.
 	ErrAlreadyExists = errors.New("already exists")
 
+	// ErrUnknownAPIVersion is returned when the version of the Graph Asset
+	// Inventory REST API cannot be determined.
+	ErrUnknownAPIVersion = errors.New("unknown API version")
+
 	// Unexpired is the [time.Time] expiration assigned to unexpired
 	// entities.
 	Unexpired = *strtime("9999-12-12T23:59:59Z")
@@ -42,6 +51,36 @@ func (w InvalidStatusError) Error() string {
 	return fmt.Sprintf("invalid status response code %v, expected %v", w.Returned, w.Expected)
 }
 
+// IsTransient reports whether err is likely to be caused by a temporary
+// condition, so the operation that returned it can be retried. That is the
+// case of network errors and server-side or rate limiting responses.
+func IsTransient(err error) bool {
+	var serr InvalidStatusError
+	if errors.As(err, &serr) {
+		return serr.Returned >= http.StatusInternalServerError || serr.Returned == http.StatusTooManyRequests
+	}
+
+	var uerr *url.Error
+	return errors.As(err, &uerr)
+}
+
+// Range of versions of the Graph Asset Inventory REST API supported by the
+// client. MinAPIVersion is inclusive and MaxAPIVersion is exclusive.
+const (
+	MinAPIVersion = "0.1.0"
+	MaxAPIVersion = "1.0.0"
+)
+
+// IncompatibleVersionError is returned when the version of the Graph Asset
+// Inventory REST API is not supported by the client.
+type IncompatibleVersionError struct {
+	Version string
+}
+
+func (e IncompatibleVersionError) Error() string {
+	return fmt.Sprintf("incompatible API version %v, supported versions are >=%v and <%v", e.Version, MinAPIVersion, MaxAPIVersion)
+}
+
 // TeamReq represents the "TeamReq" model as defined by the Graph Asset
 // Inventory REST API.
 type TeamReq struct {
@@ -55,6 +94,7 @@ type TeamResp struct {
 	ID         string `json:"id"`
 	Identifier string `json:"identifier"`
 	Name       string `json:"name"`
+	Deleted    bool   `json:"deleted,omitempty"`
 }
 
 // AssetReq represents the "AssetReq" model as defined by the Graph Asset
@@ -75,6 +115,7 @@ type AssetResp struct {
 	FirstSeen  time.Time `json:"first_seen"`
 	LastSeen   time.Time `json:"last_seen"`
 	Expiration time.Time `json:"expiration"`
+	Deleted    bool      `json:"deleted,omitempty"`
 }
 
 // ParentOfReq represents the "ParentOfReq" model as defined by the Graph Asset
@@ -112,6 +153,10 @@ type OwnsResp struct {
 	EndTime   *time.Time `json:"end_time,omitempty"`
 }
 
+// Properties represents the "Properties" model as defined by the Graph Asset
+// Inventory REST API. It contains the properties of an entity.
+type Properties map[string]string
+
 // Pagination contains the pagination parameters. If the Size field is zero,
 // pagination is disabled.
 type Pagination struct {
@@ -119,16 +164,190 @@ type Pagination struct {
 	Size int
 }
 
+// PageInfo contains the pagination metadata returned by the Graph Asset
+// Inventory REST API along with a page of entities. Its fields are -1 if the
+// server does not provide the corresponding information.
+type PageInfo struct {
+	// TotalItems is the total number of entities matching the query. It
+	// is read from the X-Total-Count header.
+	TotalItems int
+
+	// TotalPages is the total number of pages. It is read from the
+	// "last" link of the Link header or, if not present, computed from
+	// TotalItems and the page size.
+	TotalPages int
+}
+
+// Inventory represents the operations supported by the Graph Asset Inventory.
+// It is implemented by [Client].
+type Inventory interface {
+	Ping() error
+	Teams(identifier string, pag Pagination) ([]TeamResp, error)
+	CreateTeam(identifier, name string) (TeamResp, error)
+	UpdateTeam(id, identifier, name string) (TeamResp, error)
+	Assets(typ, identifier string, validAt time.Time, pag Pagination) ([]AssetResp, error)
+	CreateAsset(typ, identifier string, timestamp, expiration time.Time) (AssetResp, error)
+	UpdateAsset(id, typ, identifier string, timestamp, expiration time.Time) (AssetResp, error)
+	Parents(assetID string, pag Pagination) ([]ParentOfResp, error)
+	Parent(childID, parentID string) (ParentOfResp, error)
+	UpsertParent(childID, parentID string, timestamp, expiration time.Time) (ParentOfResp, error)
+	Children(assetID string, pag Pagination) ([]ParentOfResp, error)
+	Owners(assetID string, pag Pagination) ([]OwnsResp, error)
+	Owner(assetID, teamID string) (OwnsResp, error)
+	UpsertOwner(assetID, teamID string, startTime, endTime time.Time) (OwnsResp, error)
+}
+
+// A Serializer encodes the payloads sent to the Asset Inventory and decodes
+// its responses.
+type Serializer interface {
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// JSONSerializer is the default [Serializer]. It relies on the
+// [encoding/json] package.
+type JSONSerializer struct{}
+
+// Encode writes the JSON encoding of v to w.
+func (JSONSerializer) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads the JSON-encoded value from r and stores it in the value
+// pointed to by v.
+func (JSONSerializer) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// DeletedFilter filters the teams and assets returned by the Asset
+// Inventory by their soft deletion status. Soft deleted entities are marked
+// as deleted instead of being expired.
+type DeletedFilter string
+
+// Supported deleted filters.
+const (
+	// DeletedDefault does not filter by deletion status, so the default
+	// of the Asset Inventory applies. Versions of the Asset Inventory
+	// without soft deletes only support this filter.
+	DeletedDefault DeletedFilter = ""
+
+	// DeletedExclude returns only the entities that are not deleted.
+	DeletedExclude DeletedFilter = "exclude"
+
+	// DeletedOnly returns only the deleted entities.
+	DeletedOnly DeletedFilter = "only"
+)
+
+// Valid reports whether f is a supported deleted filter.
+func (f DeletedFilter) Valid() bool {
+	switch f {
+	case DeletedDefault, DeletedExclude, DeletedOnly:
+		return true
+	}
+	return false
+}
+
+// param returns the value of the "deleted" query parameter corresponding
+// to the filter. It returns nil if the parameter must not be sent.
+func (f DeletedFilter) param() *bool {
+	var deleted bool
+	switch f {
+	case DeletedExclude:
+		deleted = false
+	case DeletedOnly:
+		deleted = true
+	default:
+		return nil
+	}
+	return &deleted
+}
+
+// params returns the "page" and "size" query parameters corresponding to
+// pag. They are nil if pagination is disabled.
+func (pag Pagination) params() (page, size *int) {
+	if pag.Size == 0 {
+		return nil, nil
+	}
+	return &pag.Page, &pag.Size
+}
+
 // Client represents a client of the Graph Asset Inventory REST API.
 type Client struct {
-	endpoint *url.URL
-	httpcli  http.Client
+	endpoint   *url.URL
+	httpcli    http.Client
+	api        *api.Client
+	transport  *http.Transport
+	serializer Serializer
+	deleted    DeletedFilter
+
+	teamsAsAssets bool
+	capture       *Capture
+	clockOffset   *atomic.Int64
+	wrap          func(http.RoundTripper) http.RoundTripper
+
+	tlsFiles     TLSFiles
+	tlsTransport *tlsTransport
+}
+
+// A ClientOption configures a [Client].
+type ClientOption func(cli *Client)
+
+// WithSerializer sets the [Serializer] used by the client to encode requests
+// and decode responses. By default, [JSONSerializer] is used.
+func WithSerializer(s Serializer) ClientOption {
+	return func(cli *Client) {
+		cli.serializer = s
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle connections to
+// the Asset Inventory kept by the client. If n is zero,
+// [http.DefaultMaxIdleConnsPerHost] is used.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(cli *Client) {
+		cli.transport.MaxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets the maximum amount of time an idle connection to
+// the Asset Inventory is kept before closing it. If d is zero, idle
+// connections are never closed.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(cli *Client) {
+		cli.transport.IdleConnTimeout = d
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2. It is disabled by default.
+func WithHTTP2(enabled bool) ClientOption {
+	return func(cli *Client) {
+		cli.transport.ForceAttemptHTTP2 = enabled
+	}
+}
+
+// WithDeletedFilter sets the filter applied by the client when listing
+// teams and assets. By default, [DeletedDefault] is used.
+func WithDeletedFilter(f DeletedFilter) ClientOption {
+	return func(cli *Client) {
+		cli.deleted = f
+	}
+}
+
+// WithTransportWrapper makes the client send its requests through the
+// [http.RoundTripper] returned by wrap, which receives the underlying
+// transport. It allows to intercept the requests sent to the Asset
+// Inventory, for instance to inject faults in integration tests. The
+// responses returned by the wrapper are recorded by [WithCapture].
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(cli *Client) {
+		cli.wrap = wrap
+	}
 }
 
 // NewClient returns a [Client] pointing to the given endpoint (for instance
 // https://security-graph-asset-inventory/), and optionally skipping the
 // verification of the endpoint server certificate.
-func NewClient(endpoint string, insecureSkipVerify bool) (Client, error) {
+func NewClient(endpoint string, insecureSkipVerify bool, opts ...ClientOption) (Client, error) {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
 	}
@@ -140,141 +359,163 @@ func NewClient(endpoint string, insecureSkipVerify bool) (Client, error) {
 	}
 
 	cli := Client{
-		endpoint: endpointURL,
-		httpcli:  httpcli,
-	}
-	return cli, nil
-}
-
-func (cli Client) urlTeams(identifier string, pag Pagination) string {
-	u := cli.endpoint.JoinPath("/v1/teams")
-
-	q := u.Query()
-	if identifier != "" {
-		q.Set("team_identifier", identifier)
+		endpoint:   endpointURL,
+		httpcli:    httpcli,
+		transport:  tr,
+		serializer: JSONSerializer{},
 	}
-	if pag.Size != 0 {
-		q.Set("page", strconv.Itoa(pag.Page))
-		q.Set("size", strconv.Itoa(pag.Size))
+	for _, opt := range opts {
+		opt(&cli)
 	}
-	u.RawQuery = q.Encode()
-
-	return u.String()
-}
-
-func (cli Client) urlTeamsID(id string) string {
-	p := "/v1/teams"
-	p = path.Join(p, id)
-	u := cli.endpoint.JoinPath(p)
 
-	return u.String()
-}
-
-func (cli Client) urlAssets(typ, identifier string, validAt time.Time, pag Pagination) string {
-	u := cli.endpoint.JoinPath("/v1/assets")
+	if cli.tlsFiles != (TLSFiles{}) {
+		tlstr, err := newTLSTransport(tr, cli.tlsFiles)
+		if err != nil {
+			return Client{}, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		cli.tlsTransport = tlstr
+		cli.httpcli.Transport = tlstr
+	}
 
-	q := u.Query()
-	if typ != "" {
-		q.Set("asset_type", typ)
+	if cli.clockOffset != nil {
+		cli.httpcli.Transport = serverTimeTransport{
+			next:   cli.httpcli.Transport,
+			offset: cli.clockOffset,
+		}
 	}
-	if identifier != "" {
-		q.Set("asset_identifier", identifier)
+	if cli.wrap != nil {
+		cli.httpcli.Transport = cli.wrap(cli.httpcli.Transport)
 	}
-	if !validAt.IsZero() {
-		q.Set("valid_at", validAt.Format(time.RFC3339))
+	if cli.capture != nil {
+		cli.httpcli.Transport = captureTransport{
+			next:    cli.httpcli.Transport,
+			capture: cli.capture,
+		}
 	}
-	if pag.Size != 0 {
-		q.Set("page", strconv.Itoa(pag.Page))
-		q.Set("size", strconv.Itoa(pag.Size))
+
+	// The client generated from the OpenAPI document of the Graph Asset
+	// Inventory REST API sends the requests through the same transports.
+	httpcli = cli.httpcli
+	gen, err := api.NewClient(endpointURL.String(), api.WithHTTPClient(&httpcli))
+	if err != nil {
+		return Client{}, fmt.Errorf("could not create API client: %w", err)
 	}
-	u.RawQuery = q.Encode()
+	cli.api = gen
 
-	return u.String()
+	return cli, nil
 }
 
-func (cli Client) urlAssetsID(id string) string {
-	p := "/v1/assets"
-	p = path.Join(p, id)
-	u := cli.endpoint.JoinPath(p)
-
-	return u.String()
+// Ping checks that the Graph Asset Inventory REST API is reachable and
+// answers requests.
+func (cli Client) Ping() error {
+	if _, err := cli.Teams("", Pagination{Page: 0, Size: 1}); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (cli Client) urlParents(id string, pag Pagination) string {
-	p := "/v1/assets"
-	p = path.Join(p, id)
-	p = path.Join(p, "parents")
-	u := cli.endpoint.JoinPath(p)
+// APIVersion returns the version of the Graph Asset Inventory REST API as
+// advertised by its OpenAPI specification. It returns [ErrUnknownAPIVersion]
+// if the server does not expose the specification or it does not contain a
+// version.
+func (cli Client) APIVersion() (string, error) {
+	u := cli.endpoint.JoinPath("/v1/openapi.json")
+	resp, err := cli.httpcli.Get(u.String())
+	if err != nil {
+		return "", fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
 
-	q := u.Query()
-	if pag.Size != 0 {
-		q.Set("page", strconv.Itoa(pag.Page))
-		q.Set("size", strconv.Itoa(pag.Size))
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return "", ErrUnknownAPIVersion
+		}
+		err := InvalidStatusError{
+			Expected: []int{http.StatusOK},
+			Returned: resp.StatusCode,
+		}
+		return "", err
 	}
-	u.RawQuery = q.Encode()
 
-	return u.String()
-}
+	var spec struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := cli.serializer.Decode(resp.Body, &spec); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
 
-func (cli Client) urlParentsID(childID, parentID string) string {
-	p := "/v1/assets"
-	p = path.Join(p, childID)
-	p = path.Join(p, "parents")
-	p = path.Join(p, parentID)
-	u := cli.endpoint.JoinPath(p)
+	if spec.Info.Version == "" {
+		return "", ErrUnknownAPIVersion
+	}
 
-	return u.String()
+	return spec.Info.Version, nil
 }
 
-func (cli Client) urlChildren(id string, pag Pagination) string {
-	p := "/v1/assets"
-	p = path.Join(p, id)
-	p = path.Join(p, "children")
-	u := cli.endpoint.JoinPath(p)
-
-	q := u.Query()
-	if pag.Size != 0 {
-		q.Set("page", strconv.Itoa(pag.Page))
-		q.Set("size", strconv.Itoa(pag.Size))
+// CheckAPIVersion checks that the version of the Graph Asset Inventory REST
+// API is supported by the client. It returns an [IncompatibleVersionError]
+// if the version is not supported and [ErrUnknownAPIVersion] if it cannot be
+// determined, so callers can decide whether to continue.
+func (cli Client) CheckAPIVersion() error {
+	v, err := cli.APIVersion()
+	if err != nil {
+		return err
 	}
-	u.RawQuery = q.Encode()
 
-	return u.String()
+	ok, err := compatibleVersion(v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnknownAPIVersion, err)
+	}
+	if !ok {
+		return IncompatibleVersionError{Version: v}
+	}
+	return nil
 }
 
-func (cli Client) urlOwners(assetID string, pag Pagination) string {
-	p := "/v1/assets"
-	p = path.Join(p, assetID)
-	p = path.Join(p, "owners")
-	u := cli.endpoint.JoinPath(p)
+// Teams returns a list of teams filtered by identifier. If identifier is
+// empty, no filter is applied. The pag parameter controls pagination.
+func (cli Client) Teams(identifier string, pag Pagination) ([]TeamResp, error) {
+	teams, _, err := cli.TeamsPage(identifier, pag)
+	return teams, err
+}
 
-	q := u.Query()
-	if pag.Size != 0 {
-		q.Set("page", strconv.Itoa(pag.Page))
-		q.Set("size", strconv.Itoa(pag.Size))
+// TeamsPage is like [Client.Teams] but it also returns the pagination
+// metadata of the response.
+func (cli Client) TeamsPage(identifier string, pag Pagination) ([]TeamResp, PageInfo, error) {
+	var teams []TeamResp
+	_, info, err := cli.WalkTeamsPage(identifier, pag, func(team TeamResp) error {
+		teams = append(teams, team)
+		return nil
+	})
+	if err != nil {
+		return nil, PageInfo{}, err
 	}
-	u.RawQuery = q.Encode()
-
-	return u.String()
+	return teams, info, nil
 }
 
-func (cli Client) urlOwnersID(assetID, teamID string) string {
-	p := "/v1/assets"
-	p = path.Join(p, assetID)
-	p = path.Join(p, "owners")
-	p = path.Join(p, teamID)
-	u := cli.endpoint.JoinPath(p)
-
-	return u.String()
-}
+// WalkTeamsPage is like [Client.TeamsPage] but, instead of returning the
+// teams, it calls f with every team as soon as it is decoded from the
+// response, so the page is not buffered. If f returns an error, the walk
+// stops and the error is returned. It returns the number of teams in the
+// page.
+func (cli Client) WalkTeamsPage(identifier string, pag Pagination, f func(TeamResp) error) (int, PageInfo, error) {
+	if cli.teamsAsAssets {
+		return cli.walkTeamAssetsPage(identifier, pag, f)
+	}
 
-// Teams returns a list of teams filtered by identifier. If identifier is
-// empty, no filter is applied. The pag parameter controls pagination.
-func (cli Client) Teams(identifier string, pag Pagination) ([]TeamResp, error) {
-	u := cli.urlTeams(identifier, pag)
-	resp, err := cli.httpcli.Get(u)
+	page, size := pag.params()
+	params := api.ListTeamsParams{
+		Page:    page,
+		Size:    size,
+		Deleted: cli.deleted.param(),
+	}
+	if identifier != "" {
+		params.TeamIdentifier = &identifier
+	}
+	resp, err := cli.api.ListTeams(context.Background(), &params)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request error: %w", err)
+		return 0, PageInfo{}, fmt.Errorf("HTTP request error: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -283,31 +524,34 @@ func (cli Client) Teams(identifier string, pag Pagination) ([]TeamResp, error) {
 			Expected: []int{http.StatusOK},
 			Returned: resp.StatusCode,
 		}
-		return nil, err
+		return 0, PageInfo{}, err
 	}
 
-	var teams []TeamResp
-	if err := json.NewDecoder(resp.Body).Decode(&teams); err != nil {
-		return nil, fmt.Errorf("invalid response: %w", err)
+	n, err := decodeEach(cli.serializer, resp.Body, f)
+	if err != nil {
+		return n, PageInfo{}, err
 	}
 
-	return teams, nil
+	return n, pageInfo(resp.Header, pag), nil
 }
 
 // CreateTeam creates a team with the given identifier and name. It returns the
 // the created team.
 func (cli Client) CreateTeam(identifier, name string) (TeamResp, error) {
+	if cli.teamsAsAssets {
+		return cli.createTeamAsset(identifier)
+	}
+
 	var data bytes.Buffer
 	payload := TeamReq{
 		Identifier: identifier,
 		Name:       name,
 	}
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return TeamResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlTeams("", Pagination{})
-	resp, err := cli.httpcli.Post(u, "application/json", &data)
+	resp, err := cli.api.CreateTeamWithBody(context.Background(), "application/json", &data)
 	if err != nil {
 		return TeamResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -325,7 +569,7 @@ func (cli Client) CreateTeam(identifier, name string) (TeamResp, error) {
 	}
 
 	var team TeamResp
-	if err := json.NewDecoder(resp.Body).Decode(&team); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &team); err != nil {
 		return TeamResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -335,23 +579,21 @@ func (cli Client) CreateTeam(identifier, name string) (TeamResp, error) {
 // UpdateTeam updates a team with a given ID. The identifier must match the
 // asset ID.
 func (cli Client) UpdateTeam(id, identifier, name string) (TeamResp, error) {
+	if cli.teamsAsAssets {
+		return cli.updateTeamAsset(id, identifier)
+	}
+
 	payload := TeamReq{
 		Identifier: identifier,
 		Name:       name,
 	}
 
 	var data bytes.Buffer
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return TeamResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlTeamsID(id)
-	req, err := http.NewRequest(http.MethodPut, u, &data)
-	if err != nil {
-		return TeamResp{}, fmt.Errorf("could not create HTTP request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := cli.httpcli.Do(req)
+	resp, err := cli.api.UpdateTeamWithBody(context.Background(), id, "application/json", &data)
 	if err != nil {
 		return TeamResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -369,7 +611,7 @@ func (cli Client) UpdateTeam(id, identifier, name string) (TeamResp, error) {
 	}
 
 	var team TeamResp
-	if err := json.NewDecoder(resp.Body).Decode(&team); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &team); err != nil {
 		return TeamResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -380,10 +622,49 @@ func (cli Client) UpdateTeam(id, identifier, name string) (TeamResp, error) {
 // identifier are empty and validAt is zero, no filter is applied. The pag
 // parameter controls pagination.
 func (cli Client) Assets(typ, identifier string, validAt time.Time, pag Pagination) ([]AssetResp, error) {
-	u := cli.urlAssets(typ, identifier, validAt, pag)
-	resp, err := cli.httpcli.Get(u)
+	assets, _, err := cli.AssetsPage(typ, identifier, validAt, pag)
+	return assets, err
+}
+
+// AssetsPage is like [Client.Assets] but it also returns the pagination
+// metadata of the response.
+func (cli Client) AssetsPage(typ, identifier string, validAt time.Time, pag Pagination) ([]AssetResp, PageInfo, error) {
+	var assets []AssetResp
+	_, info, err := cli.WalkAssetsPage(typ, identifier, validAt, pag, func(asset AssetResp) error {
+		assets = append(assets, asset)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request error: %w", err)
+		return nil, PageInfo{}, err
+	}
+	return assets, info, nil
+}
+
+// WalkAssetsPage is like [Client.AssetsPage] but, instead of returning the
+// assets, it calls f with every asset as soon as it is decoded from the
+// response, so the page is not buffered. If f returns an error, the walk
+// stops and the error is returned. It returns the number of assets in the
+// page.
+func (cli Client) WalkAssetsPage(typ, identifier string, validAt time.Time, pag Pagination, f func(AssetResp) error) (int, PageInfo, error) {
+	page, size := pag.params()
+	params := api.ListAssetsParams{
+		Page:    page,
+		Size:    size,
+		Deleted: cli.deleted.param(),
+	}
+	if typ != "" {
+		params.AssetType = &typ
+	}
+	if identifier != "" {
+		params.AssetIdentifier = &identifier
+	}
+	if !validAt.IsZero() {
+		validAt = validAt.Truncate(time.Second)
+		params.ValidAt = &validAt
+	}
+	resp, err := cli.api.ListAssets(context.Background(), &params)
+	if err != nil {
+		return 0, PageInfo{}, fmt.Errorf("HTTP request error: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -392,17 +673,68 @@ func (cli Client) Assets(typ, identifier string, validAt time.Time, pag Paginati
 			Expected: []int{http.StatusOK},
 			Returned: resp.StatusCode,
 		}
-		return nil, err
+		return 0, PageInfo{}, err
 	}
 
-	var assets []AssetResp
-	if err := json.NewDecoder(resp.Body).Decode(&assets); err != nil {
-		return nil, fmt.Errorf("invalid response: %w", err)
+	n, err := decodeEach(cli.serializer, resp.Body, f)
+	if err != nil {
+		return n, PageInfo{}, err
 	}
 
+	return n, pageInfo(resp.Header, pag), nil
+}
+
+// AssetsModifiedSince returns the assets created or updated in the Asset
+// Inventory after t, using the updated-after filter of the API. It allows to
+// process only the recently changed vertices instead of scanning the whole
+// graph. The pag parameter controls pagination.
+func (cli Client) AssetsModifiedSince(t time.Time, pag Pagination) ([]AssetResp, error) {
+	var assets []AssetResp
+	_, _, err := cli.WalkAssetsModifiedSincePage(t, pag, func(asset AssetResp) error {
+		assets = append(assets, asset)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return assets, nil
 }
 
+// WalkAssetsModifiedSincePage is like [Client.AssetsModifiedSince] but,
+// instead of returning the assets, it calls f with every asset as soon as it
+// is decoded from the response, so the page is not buffered. If f returns an
+// error, the walk stops and the error is returned. It returns the number of
+// assets and the pagination metadata of the page.
+func (cli Client) WalkAssetsModifiedSincePage(t time.Time, pag Pagination, f func(AssetResp) error) (int, PageInfo, error) {
+	page, size := pag.params()
+	params := api.ListAssetsParams{
+		Page:         page,
+		Size:         size,
+		Deleted:      cli.deleted.param(),
+		UpdatedAfter: &t,
+	}
+	resp, err := cli.api.ListAssets(context.Background(), &params)
+	if err != nil {
+		return 0, PageInfo{}, fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := InvalidStatusError{
+			Expected: []int{http.StatusOK},
+			Returned: resp.StatusCode,
+		}
+		return 0, PageInfo{}, err
+	}
+
+	n, err := decodeEach(cli.serializer, resp.Body, f)
+	if err != nil {
+		return n, PageInfo{}, err
+	}
+
+	return n, pageInfo(resp.Header, pag), nil
+}
+
 // CreateAsset creates an asset with the given type, identifier and expiration.
 // It returns the the created asset.
 func (cli Client) CreateAsset(typ, identifier string, timestamp, expiration time.Time) (AssetResp, error) {
@@ -415,12 +747,11 @@ func (cli Client) CreateAsset(typ, identifier string, timestamp, expiration time
 	if !timestamp.IsZero() {
 		payload.Timestamp = &timestamp
 	}
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return AssetResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlAssets("", "", time.Time{}, Pagination{})
-	resp, err := cli.httpcli.Post(u, "application/json", &data)
+	resp, err := cli.api.CreateAssetWithBody(context.Background(), "application/json", &data)
 	if err != nil {
 		return AssetResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -438,7 +769,7 @@ func (cli Client) CreateAsset(typ, identifier string, timestamp, expiration time
 	}
 
 	var asset AssetResp
-	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &asset); err != nil {
 		return AssetResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -459,17 +790,11 @@ func (cli Client) UpdateAsset(id, typ, identifier string, timestamp, expiration
 	}
 
 	var data bytes.Buffer
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return AssetResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlAssetsID(id)
-	req, err := http.NewRequest(http.MethodPut, u, &data)
-	if err != nil {
-		return AssetResp{}, fmt.Errorf("could not create HTTP request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := cli.httpcli.Do(req)
+	resp, err := cli.api.UpdateAssetWithBody(context.Background(), id, "application/json", &data)
 	if err != nil {
 		return AssetResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -487,7 +812,7 @@ func (cli Client) UpdateAsset(id, typ, identifier string, timestamp, expiration
 	}
 
 	var asset AssetResp
-	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &asset); err != nil {
 		return AssetResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -497,8 +822,8 @@ func (cli Client) UpdateAsset(id, typ, identifier string, timestamp, expiration
 // Parents returns the "parent of" relations of the asset with the given ID.
 // The pag parameter controls pagination.
 func (cli Client) Parents(assetID string, pag Pagination) ([]ParentOfResp, error) {
-	u := cli.urlParents(assetID, pag)
-	resp, err := cli.httpcli.Get(u)
+	page, size := pag.params()
+	resp, err := cli.api.ListParents(context.Background(), assetID, &api.ListParentsParams{Page: page, Size: size})
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -516,13 +841,41 @@ func (cli Client) Parents(assetID string, pag Pagination) ([]ParentOfResp, error
 	}
 
 	var parents []ParentOfResp
-	if err := json.NewDecoder(resp.Body).Decode(&parents); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &parents); err != nil {
 		return nil, fmt.Errorf("invalid response: %w", err)
 	}
 
 	return parents, nil
 }
 
+// Parent returns the "parent of" relation between the provided assets. It
+// returns [ErrNotFound] if the relation does not exist.
+func (cli Client) Parent(childID, parentID string) (ParentOfResp, error) {
+	resp, err := cli.api.GetParent(context.Background(), childID, parentID)
+	if err != nil {
+		return ParentOfResp{}, fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return ParentOfResp{}, ErrNotFound
+		}
+		err := InvalidStatusError{
+			Expected: []int{http.StatusOK},
+			Returned: resp.StatusCode,
+		}
+		return ParentOfResp{}, err
+	}
+
+	var parent ParentOfResp
+	if err := cli.serializer.Decode(resp.Body, &parent); err != nil {
+		return ParentOfResp{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return parent, nil
+}
+
 // UpsertParent creates or updates the "parent of" relation between the
 // provided assets. If timestamp is zero, it is ignored.
 func (cli Client) UpsertParent(childID, parentID string, timestamp, expiration time.Time) (ParentOfResp, error) {
@@ -534,17 +887,11 @@ func (cli Client) UpsertParent(childID, parentID string, timestamp, expiration t
 	}
 
 	var data bytes.Buffer
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return ParentOfResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlParentsID(childID, parentID)
-	req, err := http.NewRequest(http.MethodPut, u, &data)
-	if err != nil {
-		return ParentOfResp{}, fmt.Errorf("could not create HTTP request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := cli.httpcli.Do(req)
+	resp, err := cli.api.UpsertParentWithBody(context.Background(), childID, parentID, "application/json", &data)
 	if err != nil {
 		return ParentOfResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -562,7 +909,7 @@ func (cli Client) UpsertParent(childID, parentID string, timestamp, expiration t
 	}
 
 	var parents ParentOfResp
-	if err := json.NewDecoder(resp.Body).Decode(&parents); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &parents); err != nil {
 		return ParentOfResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -572,8 +919,8 @@ func (cli Client) UpsertParent(childID, parentID string, timestamp, expiration t
 // Children returns the outgoing "parent of" relations of the asset with the
 // given ID. The pag parameter controls pagination.
 func (cli Client) Children(assetID string, pag Pagination) ([]ParentOfResp, error) {
-	u := cli.urlChildren(assetID, pag)
-	resp, err := cli.httpcli.Get(u)
+	page, size := pag.params()
+	resp, err := cli.api.ListChildren(context.Background(), assetID, &api.ListChildrenParams{Page: page, Size: size})
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -591,7 +938,7 @@ func (cli Client) Children(assetID string, pag Pagination) ([]ParentOfResp, erro
 	}
 
 	var children []ParentOfResp
-	if err := json.NewDecoder(resp.Body).Decode(&children); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &children); err != nil {
 		return nil, fmt.Errorf("invalid response: %w", err)
 	}
 
@@ -601,8 +948,8 @@ func (cli Client) Children(assetID string, pag Pagination) ([]ParentOfResp, erro
 // Owners returns the "owns" relations of the asset with the provided ID. The
 // pag parameter controls pagination.
 func (cli Client) Owners(assetID string, pag Pagination) ([]OwnsResp, error) {
-	u := cli.urlOwners(assetID, pag)
-	resp, err := cli.httpcli.Get(u)
+	page, size := pag.params()
+	resp, err := cli.api.ListOwners(context.Background(), assetID, &api.ListOwnersParams{Page: page, Size: size})
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -620,13 +967,41 @@ func (cli Client) Owners(assetID string, pag Pagination) ([]OwnsResp, error) {
 	}
 
 	var owners []OwnsResp
-	if err := json.NewDecoder(resp.Body).Decode(&owners); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &owners); err != nil {
 		return nil, fmt.Errorf("invalid response: %w", err)
 	}
 
 	return owners, nil
 }
 
+// Owner returns the "owns" relation between the provided asset and team. It
+// returns [ErrNotFound] if the relation does not exist.
+func (cli Client) Owner(assetID, teamID string) (OwnsResp, error) {
+	resp, err := cli.api.GetOwner(context.Background(), assetID, teamID)
+	if err != nil {
+		return OwnsResp{}, fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return OwnsResp{}, ErrNotFound
+		}
+		err := InvalidStatusError{
+			Expected: []int{http.StatusOK},
+			Returned: resp.StatusCode,
+		}
+		return OwnsResp{}, err
+	}
+
+	var owner OwnsResp
+	if err := cli.serializer.Decode(resp.Body, &owner); err != nil {
+		return OwnsResp{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return owner, nil
+}
+
 // UpsertOwner creates or updates the "owns" relation between the provided team
 // and asset. If endTime is zero, it is ignored.
 func (cli Client) UpsertOwner(assetID, teamID string, startTime, endTime time.Time) (OwnsResp, error) {
@@ -638,17 +1013,11 @@ func (cli Client) UpsertOwner(assetID, teamID string, startTime, endTime time.Ti
 	}
 
 	var data bytes.Buffer
-	if err := json.NewEncoder(&data).Encode(payload); err != nil {
+	if err := cli.serializer.Encode(&data, payload); err != nil {
 		return OwnsResp{}, fmt.Errorf("invalid payload: %w", err)
 	}
 
-	u := cli.urlOwnersID(assetID, teamID)
-	req, err := http.NewRequest(http.MethodPut, u, &data)
-	if err != nil {
-		return OwnsResp{}, fmt.Errorf("could not create HTTP request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := cli.httpcli.Do(req)
+	resp, err := cli.api.UpsertOwnerWithBody(context.Background(), assetID, teamID, "application/json", &data)
 	if err != nil {
 		return OwnsResp{}, fmt.Errorf("HTTP request error: %w", err)
 	}
@@ -666,16 +1035,333 @@ func (cli Client) UpsertOwner(assetID, teamID string, startTime, endTime time.Ti
 	}
 
 	var owner OwnsResp
-	if err := json.NewDecoder(resp.Body).Decode(&owner); err != nil {
+	if err := cli.serializer.Decode(resp.Body, &owner); err != nil {
 		return OwnsResp{}, fmt.Errorf("invalid response: %w", err)
 	}
 
 	return owner, nil
 }
 
+// AssetProperties returns the properties of the asset with the provided ID.
+// It returns [ErrNotFound] if the asset does not exist.
+func (cli Client) AssetProperties(assetID string) (Properties, error) {
+	return cli.properties(func(ctx context.Context) (*http.Response, error) {
+		return cli.api.GetAssetProperties(ctx, assetID)
+	})
+}
+
+// SetAssetProperties sets the provided properties of the asset with the
+// provided ID. The properties not present in props are not modified and the
+// properties with an empty value are removed. It returns the resulting
+// properties of the asset.
+func (cli Client) SetAssetProperties(assetID string, props Properties) (Properties, error) {
+	return cli.setProperties(props, func(ctx context.Context, body io.Reader) (*http.Response, error) {
+		return cli.api.SetAssetPropertiesWithBody(ctx, assetID, "application/json", body)
+	})
+}
+
+// TeamProperties returns the properties of the team with the provided ID.
+// It returns [ErrNotFound] if the team does not exist.
+func (cli Client) TeamProperties(teamID string) (Properties, error) {
+	if cli.teamsAsAssets {
+		return cli.AssetProperties(teamID)
+	}
+
+	return cli.properties(func(ctx context.Context) (*http.Response, error) {
+		return cli.api.GetTeamProperties(ctx, teamID)
+	})
+}
+
+// SetTeamProperties is like [Client.SetAssetProperties] but it sets the
+// properties of the team with the provided ID.
+func (cli Client) SetTeamProperties(teamID string, props Properties) (Properties, error) {
+	if cli.teamsAsAssets {
+		return cli.SetAssetProperties(teamID, props)
+	}
+
+	return cli.setProperties(props, func(ctx context.Context, body io.Reader) (*http.Response, error) {
+		return cli.api.SetTeamPropertiesWithBody(ctx, teamID, "application/json", body)
+	})
+}
+
+// ParentProperties returns the properties of the "parent of" relation
+// between the provided assets. It returns [ErrNotFound] if the relation does
+// not exist.
+func (cli Client) ParentProperties(childID, parentID string) (Properties, error) {
+	return cli.properties(func(ctx context.Context) (*http.Response, error) {
+		return cli.api.GetParentProperties(ctx, childID, parentID)
+	})
+}
+
+// SetParentProperties is like [Client.SetAssetProperties] but it sets the
+// properties of the "parent of" relation between the provided assets.
+func (cli Client) SetParentProperties(childID, parentID string, props Properties) (Properties, error) {
+	return cli.setProperties(props, func(ctx context.Context, body io.Reader) (*http.Response, error) {
+		return cli.api.SetParentPropertiesWithBody(ctx, childID, parentID, "application/json", body)
+	})
+}
+
+// OwnerProperties returns the properties of the "owns" relation between the
+// provided asset and team. It returns [ErrNotFound] if the relation does not
+// exist.
+func (cli Client) OwnerProperties(assetID, teamID string) (Properties, error) {
+	return cli.properties(func(ctx context.Context) (*http.Response, error) {
+		return cli.api.GetOwnerProperties(ctx, assetID, teamID)
+	})
+}
+
+// SetOwnerProperties is like [Client.SetAssetProperties] but it sets the
+// properties of the "owns" relation between the provided asset and team.
+func (cli Client) SetOwnerProperties(assetID, teamID string, props Properties) (Properties, error) {
+	return cli.setProperties(props, func(ctx context.Context, body io.Reader) (*http.Response, error) {
+		return cli.api.SetOwnerPropertiesWithBody(ctx, assetID, teamID, "application/json", body)
+	})
+}
+
+// properties calls get to send the request and returns the properties
+// contained in the response.
+func (cli Client) properties(get func(ctx context.Context) (*http.Response, error)) (Properties, error) {
+	resp, err := get(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return cli.decodeProperties(resp)
+}
+
+// setProperties encodes props and calls set to send the request with the
+// encoded properties as body. It returns the properties contained in the
+// response.
+func (cli Client) setProperties(props Properties, set func(ctx context.Context, body io.Reader) (*http.Response, error)) (Properties, error) {
+	var data bytes.Buffer
+	if err := cli.serializer.Encode(&data, props); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	resp, err := set(context.Background(), &data)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return cli.decodeProperties(resp)
+}
+
+// decodeProperties returns the properties contained in resp.
+func (cli Client) decodeProperties(resp *http.Response) (Properties, error) {
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		err := InvalidStatusError{
+			Expected: []int{http.StatusOK},
+			Returned: resp.StatusCode,
+		}
+		return nil, err
+	}
+
+	var props Properties
+	if err := cli.serializer.Decode(resp.Body, &props); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return props, nil
+}
+
+// AllTeams returns all the teams of inv filtered by identifier. The teams are
+// retrieved using pages of the provided size. If pageSize is zero, pagination
+// is disabled.
+func AllTeams(inv Inventory, identifier string, pageSize int) ([]TeamResp, error) {
+	return paginate(pageSize, func(pag Pagination) ([]TeamResp, error) {
+		return inv.Teams(identifier, pag)
+	})
+}
+
+// AllAssets returns all the assets of inv filtered by type, identifier and
+// validAt. The assets are retrieved using pages of the provided size. If
+// pageSize is zero, pagination is disabled.
+func AllAssets(inv Inventory, typ, identifier string, validAt time.Time, pageSize int) ([]AssetResp, error) {
+	return paginate(pageSize, func(pag Pagination) ([]AssetResp, error) {
+		return inv.Assets(typ, identifier, validAt, pag)
+	})
+}
+
+// AllParents returns all the "parent of" relations of the asset with the
+// given ID. The relations are retrieved using pages of the provided size. If
+// pageSize is zero, pagination is disabled.
+func AllParents(inv Inventory, assetID string, pageSize int) ([]ParentOfResp, error) {
+	return paginate(pageSize, func(pag Pagination) ([]ParentOfResp, error) {
+		return inv.Parents(assetID, pag)
+	})
+}
+
+// AllChildren returns all the outgoing "parent of" relations of the asset
+// with the given ID. The relations are retrieved using pages of the provided
+// size. If pageSize is zero, pagination is disabled.
+func AllChildren(inv Inventory, assetID string, pageSize int) ([]ParentOfResp, error) {
+	return paginate(pageSize, func(pag Pagination) ([]ParentOfResp, error) {
+		return inv.Children(assetID, pag)
+	})
+}
+
+// AllOwners returns all the "owns" relations of the asset with the provided
+// ID. The relations are retrieved using pages of the provided size. If
+// pageSize is zero, pagination is disabled.
+func AllOwners(inv Inventory, assetID string, pageSize int) ([]OwnsResp, error) {
+	return paginate(pageSize, func(pag Pagination) ([]OwnsResp, error) {
+		return inv.Owners(assetID, pag)
+	})
+}
+
+// paginate calls get with consecutive pages of the provided size until a
+// page with less than size items is returned. It returns the concatenation of
+// all the pages. If size is zero, get is called once with pagination
+// disabled.
+func paginate[T any](size int, get func(pag Pagination) ([]T, error)) ([]T, error) {
+	if size == 0 {
+		return get(Pagination{})
+	}
+
+	var items []T
+	for page := 0; ; page++ {
+		pitems, err := get(Pagination{Page: page, Size: size})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, pitems...)
+
+		if len(pitems) < size {
+			return items, nil
+		}
+	}
+}
+
 // strtime takes a time string with layout RFC3339 and returns the parsed
 // [time.Time]. It panics on error and is meant to be used on variable
 // initialization.
+// pageInfo returns the pagination metadata contained in the provided response
+// headers. pag are the pagination parameters of the request.
+func pageInfo(hdr http.Header, pag Pagination) PageInfo {
+	info := PageInfo{TotalItems: -1, TotalPages: -1}
+
+	if tc := hdr.Get("X-Total-Count"); tc != "" {
+		if n, err := strconv.Atoi(tc); err == nil && n >= 0 {
+			info.TotalItems = n
+		}
+	}
+
+	if last, ok := lastPage(hdr.Values("Link")); ok {
+		info.TotalPages = last + 1
+	} else if info.TotalItems >= 0 {
+		if pag.Size == 0 {
+			info.TotalPages = 1
+		} else {
+			info.TotalPages = (info.TotalItems + pag.Size - 1) / pag.Size
+		}
+	}
+
+	return info
+}
+
+// lastPage returns the page parameter of the link with relation type "last"
+// in the provided Link header values, as defined by RFC 8288.
+func lastPage(links []string) (int, bool) {
+	for _, v := range links {
+		for _, link := range strings.Split(v, ",") {
+			target, params, found := strings.Cut(link, ";")
+			if !found {
+				continue
+			}
+
+			var isLast bool
+			for _, p := range strings.Split(params, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if !strings.EqualFold(k, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(v, `"`)) {
+					if rel == "last" {
+						isLast = true
+					}
+				}
+			}
+			if !isLast {
+				continue
+			}
+
+			target = strings.TrimSpace(target)
+			target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			u, err := url.Parse(target)
+			if err != nil {
+				return 0, false
+			}
+			page, err := strconv.Atoi(u.Query().Get("page"))
+			if err != nil || page < 0 {
+				return 0, false
+			}
+			return page, true
+		}
+	}
+	return 0, false
+}
+
+// compatibleVersion reports whether the API version v is in the range of
+// supported versions.
+func compatibleVersion(v string) (bool, error) {
+	ver, err := parseVersion(v)
+	if err != nil {
+		return false, err
+	}
+	min, err := parseVersion(MinAPIVersion)
+	if err != nil {
+		return false, err
+	}
+	max, err := parseVersion(MaxAPIVersion)
+	if err != nil {
+		return false, err
+	}
+	return compareVersions(ver, min) >= 0 && compareVersions(ver, max) < 0, nil
+}
+
+// parseVersion parses a semantic version with the format
+// "[v]major.minor.patch". Pre-release and build suffixes are ignored.
+func parseVersion(v string) ([3]int, error) {
+	s := strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return [3]int{}, fmt.Errorf("invalid version %q", v)
+	}
+
+	var ver [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return [3]int{}, fmt.Errorf("invalid version %q", v)
+		}
+		ver[i] = n
+	}
+	return ver, nil
+}
+
+// compareVersions returns -1, 0 or 1 if a is lower than, equal to or greater
+// than b respectively.
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
 func strtime(s string) *time.Time {
 	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
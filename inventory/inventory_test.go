@@ -1,7 +1,15 @@
 package inventory
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
 	"strconv"
 	"testing"
 	"time"
@@ -9,15 +17,30 @@ import (
 	gremlingo "github.com/apache/tinkerpop/gremlin-go/v3/driver"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
-)
 
-const (
-	inventoryEndpoint = "http://127.0.0.1:8000"
-	gremlinEndpoint   = "ws://127.0.0.1:8182/gremlin"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers"
 )
 
+// readyTimeout is the maximum time to wait for the testing infrastructure.
+const readyTimeout = time.Minute
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Main(m, containers.Inventory))
+}
+
 func resetGraph() error {
-	conn, err := gremlingo.NewDriverRemoteConnection(gremlinEndpoint, func(settings *gremlingo.DriverRemoteConnectionSettings) {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	if err := testinfra.WaitGremlin(ctx); err != nil {
+		return err
+	}
+	if err := testinfra.WaitInventory(ctx); err != nil {
+		return err
+	}
+
+	conn, err := gremlingo.NewDriverRemoteConnection(testinfra.GremlinEndpoint(), func(settings *gremlingo.DriverRemoteConnectionSettings) {
 		settings.LogVerbosity = gremlingo.Off
 	})
 	if err != nil {
@@ -32,6 +55,26 @@ func resetGraph() error {
 	return nil
 }
 
+func TestClientPing(t *testing.T) {
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if err := cli.Ping(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cli, err = NewClient("http://127.0.0.1:1", true)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if err := cli.Ping(); err == nil {
+		t.Errorf("expected error pinging unreachable endpoint")
+	}
+}
+
 var (
 	teamsTestdata = []TeamReq{
 		{
@@ -107,7 +150,7 @@ func TestClientTeamsGetCreate(t *testing.T) {
 				t.Fatalf("error setting up graph: %v", err)
 			}
 
-			cli, err := NewClient(inventoryEndpoint, true)
+			cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 			if err != nil {
 				t.Fatalf("error creating client: %v", err)
 			}
@@ -135,7 +178,7 @@ func TestClientTeamsPagination(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -160,12 +203,38 @@ func TestClientTeamsPagination(t *testing.T) {
 	}
 }
 
+func TestClientAllTeams(t *testing.T) {
+	if err := resetGraph(); err != nil {
+		t.Fatalf("error setting up graph: %v", err)
+	}
+
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	for _, td := range teamsTestdata {
+		if _, err := cli.CreateTeam(td.Identifier, td.Name); err != nil {
+			t.Fatalf("error creating team: %v", err)
+		}
+	}
+
+	got, err := AllTeams(cli, "", 2)
+	if err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+
+	if diff := cmp.Diff(teamsWant, got, teamsDiffOpts...); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%v", diff)
+	}
+}
+
 func TestClientTeamsUpdate(t *testing.T) {
 	if err := resetGraph(); err != nil {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -320,7 +389,7 @@ func TestClientAssetsGetCreate(t *testing.T) {
 				t.Fatalf("error setting up graph: %v", err)
 			}
 
-			cli, err := NewClient(inventoryEndpoint, true)
+			cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 			if err != nil {
 				t.Fatalf("error creating client: %v", err)
 			}
@@ -348,7 +417,7 @@ func TestClientAssetsPagination(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -378,7 +447,7 @@ func TestClientAssetsUpdate(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -480,7 +549,7 @@ func TestClientParentsGetCreate(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -528,7 +597,7 @@ func TestClientParentsPagination(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -580,7 +649,7 @@ func TestClientParentsUpdate(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -651,7 +720,7 @@ func TestClientChildren(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -699,7 +768,7 @@ func TestClientChildrenPagination(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -799,7 +868,7 @@ func TestClientOwnersGetCreate(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -842,7 +911,7 @@ func TestClientOwnersPagination(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -889,7 +958,7 @@ func TestClientOwnersUpdate(t *testing.T) {
 		t.Fatalf("error setting up graph: %v", err)
 	}
 
-	cli, err := NewClient(inventoryEndpoint, true)
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
 	if err != nil {
 		t.Fatalf("error creating client: %v", err)
 	}
@@ -948,3 +1017,862 @@ func TestClientOwnersUpdate(t *testing.T) {
 		t.Errorf("owners mismatch (-want +got):\n%v", diff)
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []int
+		size      int
+		want      []int
+		wantPages []Pagination
+	}{
+		{
+			name:      "pagination disabled",
+			items:     []int{0, 1, 2, 3, 4},
+			size:      0,
+			want:      []int{0, 1, 2, 3, 4},
+			wantPages: []Pagination{{}},
+		},
+		{
+			name:  "partial last page",
+			items: []int{0, 1, 2, 3, 4},
+			size:  2,
+			want:  []int{0, 1, 2, 3, 4},
+			wantPages: []Pagination{
+				{Page: 0, Size: 2},
+				{Page: 1, Size: 2},
+				{Page: 2, Size: 2},
+			},
+		},
+		{
+			name:  "full last page",
+			items: []int{0, 1, 2, 3},
+			size:  2,
+			want:  []int{0, 1, 2, 3},
+			wantPages: []Pagination{
+				{Page: 0, Size: 2},
+				{Page: 1, Size: 2},
+				{Page: 2, Size: 2},
+			},
+		},
+		{
+			name:  "no items",
+			items: nil,
+			size:  2,
+			want:  nil,
+			wantPages: []Pagination{
+				{Page: 0, Size: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []Pagination
+			got, err := paginate(tt.size, func(pag Pagination) ([]int, error) {
+				pages = append(pages, pag)
+				if pag.Size == 0 {
+					return tt.items, nil
+				}
+				start := pag.Page * pag.Size
+				if start >= len(tt.items) {
+					return nil, nil
+				}
+				end := start + pag.Size
+				if end > len(tt.items) {
+					end = len(tt.items)
+				}
+				return tt.items[start:end], nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantPages, pages); diff != "" {
+				t.Errorf("pages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error
+		wantNilErr bool
+	}{
+		{
+			name:       "compatible version",
+			status:     http.StatusOK,
+			body:       `{"info": {"version": "0.3.1"}}`,
+			wantNilErr: true,
+		},
+		{
+			name:       "incompatible version",
+			status:     http.StatusOK,
+			body:       `{"info": {"version": "1.0.0"}}`,
+			wantErr:    IncompatibleVersionError{Version: "1.0.0"},
+			wantNilErr: false,
+		},
+		{
+			name:       "no specification",
+			status:     http.StatusNotFound,
+			wantErr:    ErrUnknownAPIVersion,
+			wantNilErr: false,
+		},
+		{
+			name:       "no version",
+			status:     http.StatusOK,
+			body:       `{"info": {}}`,
+			wantErr:    ErrUnknownAPIVersion,
+			wantNilErr: false,
+		},
+		{
+			name:       "invalid version",
+			status:     http.StatusOK,
+			body:       `{"info": {"version": "latest"}}`,
+			wantErr:    ErrUnknownAPIVersion,
+			wantNilErr: false,
+		},
+		{
+			name:       "server error",
+			status:     http.StatusInternalServerError,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/openapi.json" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			cli, err := NewClient(srv.URL, false)
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			err = cli.CheckAPIVersion()
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClientRelation(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		get        func(cli Client) (any, error)
+		want       any
+		wantErr    error
+		wantNilErr bool
+	}{
+		{
+			name:   "owner",
+			status: http.StatusOK,
+			body:   `{"id": "owns", "team_id": "team", "asset_id": "asset", "start_time": "2022-01-01T12:00:00Z"}`,
+			get: func(cli Client) (any, error) {
+				return cli.Owner("asset", "team")
+			},
+			want: OwnsResp{
+				ID:        "owns",
+				TeamID:    "team",
+				AssetID:   "asset",
+				StartTime: *strtime("2022-01-01T12:00:00Z"),
+			},
+			wantNilErr: true,
+		},
+		{
+			name:   "owner not found",
+			status: http.StatusNotFound,
+			get: func(cli Client) (any, error) {
+				return cli.Owner("asset", "team")
+			},
+			want:       OwnsResp{},
+			wantErr:    ErrNotFound,
+			wantNilErr: false,
+		},
+		{
+			name:   "parent",
+			status: http.StatusOK,
+			body:   `{"id": "parentof", "parent_id": "parent", "child_id": "asset", "first_seen": "2022-01-01T12:00:00Z", "last_seen": "2022-01-01T12:00:00Z", "expiration": "2022-02-01T12:00:00Z"}`,
+			get: func(cli Client) (any, error) {
+				return cli.Parent("asset", "parent")
+			},
+			want: ParentOfResp{
+				ID:         "parentof",
+				ParentID:   "parent",
+				ChildID:    "asset",
+				FirstSeen:  *strtime("2022-01-01T12:00:00Z"),
+				LastSeen:   *strtime("2022-01-01T12:00:00Z"),
+				Expiration: *strtime("2022-02-01T12:00:00Z"),
+			},
+			wantNilErr: true,
+		},
+		{
+			name:   "parent server error",
+			status: http.StatusInternalServerError,
+			get: func(cli Client) (any, error) {
+				return cli.Parent("asset", "parent")
+			},
+			want:       ParentOfResp{},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				switch r.URL.Path {
+				case "/v1/assets/asset/owners/team", "/v1/assets/asset/parents/parent":
+				default:
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			cli, err := NewClient(srv.URL, false)
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			got, err := tt.get(cli)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("relation mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		want       [3]int
+		wantNilErr bool
+	}{
+		{version: "1.2.3", want: [3]int{1, 2, 3}, wantNilErr: true},
+		{version: "v0.10.0", want: [3]int{0, 10, 0}, wantNilErr: true},
+		{version: "1.2.3-rc.1+build", want: [3]int{1, 2, 3}, wantNilErr: true},
+		{version: "1.2", wantNilErr: false},
+		{version: "1.x.3", wantNilErr: false},
+		{version: "", wantNilErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := parseVersion(tt.version)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected version: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPageInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		hdr  http.Header
+		pag  Pagination
+		want PageInfo
+	}{
+		{
+			name: "no metadata",
+			hdr:  http.Header{},
+			pag:  Pagination{Page: 0, Size: 10},
+			want: PageInfo{TotalItems: -1, TotalPages: -1},
+		},
+		{
+			name: "total count",
+			hdr:  http.Header{"X-Total-Count": {"25"}},
+			pag:  Pagination{Page: 0, Size: 10},
+			want: PageInfo{TotalItems: 25, TotalPages: 3},
+		},
+		{
+			name: "total count without pagination",
+			hdr:  http.Header{"X-Total-Count": {"25"}},
+			pag:  Pagination{},
+			want: PageInfo{TotalItems: 25, TotalPages: 1},
+		},
+		{
+			name: "link header",
+			hdr: http.Header{"Link": {
+				`<https://inventory.example.com/v1/assets?page=1&size=10>; rel="next", <https://inventory.example.com/v1/assets?page=4&size=10>; rel="last"`,
+			}},
+			pag:  Pagination{Page: 0, Size: 10},
+			want: PageInfo{TotalItems: -1, TotalPages: 5},
+		},
+		{
+			name: "link header with several relations",
+			hdr: http.Header{"Link": {
+				`<https://inventory.example.com/v1/assets?page=4&size=10>; rel="next last"`,
+			}},
+			pag:  Pagination{Page: 3, Size: 10},
+			want: PageInfo{TotalItems: -1, TotalPages: 5},
+		},
+		{
+			name: "link header and total count",
+			hdr: http.Header{
+				"Link":          {`<https://inventory.example.com/v1/assets?page=2&size=10>; rel="last"`},
+				"X-Total-Count": {"30"},
+			},
+			pag:  Pagination{Page: 0, Size: 10},
+			want: PageInfo{TotalItems: 30, TotalPages: 3},
+		},
+		{
+			name: "invalid metadata",
+			hdr: http.Header{
+				"Link":          {`<https://inventory.example.com/v1/assets?page=x>; rel="last"`},
+				"X-Total-Count": {"-1"},
+			},
+			pag:  Pagination{Page: 0, Size: 10},
+			want: PageInfo{TotalItems: -1, TotalPages: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageInfo(tt.hdr, tt.pag)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("page info mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestAssetsPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Count", "3")
+		fmt.Fprint(w, `[{"id": "asset-1", "type": "Hostname", "identifier": "example.com"}]`)
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, false)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	assets, info, err := cli.AssetsPage("Hostname", "", time.Time{}, Pagination{Page: 0, Size: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantAssets := []AssetResp{{ID: "asset-1", Type: "Hostname", Identifier: "example.com"}}
+	if diff := cmp.Diff(wantAssets, assets); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+
+	wantInfo := PageInfo{TotalItems: 3, TotalPages: 3}
+	if diff := cmp.Diff(wantInfo, info); diff != "" {
+		t.Errorf("page info mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestClientProperties(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ClientOption
+		path       string
+		get        func(cli Client) (Properties, error)
+		set        func(cli Client, props Properties) (Properties, error)
+		wantErr    error
+		wantNilErr bool
+	}{
+		{
+			name: "asset",
+			path: "/v1/assets/asset/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.AssetProperties("asset")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetAssetProperties("asset", props)
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "team",
+			path: "/v1/teams/team/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.TeamProperties("team")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetTeamProperties("team", props)
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "team as asset",
+			opts: []ClientOption{WithTeamsAsAssets(true)},
+			path: "/v1/assets/team/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.TeamProperties("team")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetTeamProperties("team", props)
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "parent",
+			path: "/v1/assets/asset/parents/parent/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.ParentProperties("asset", "parent")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetParentProperties("asset", "parent", props)
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "owner",
+			path: "/v1/assets/asset/owners/team/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.OwnerProperties("asset", "team")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetOwnerProperties("asset", "team", props)
+			},
+			wantNilErr: true,
+		},
+		{
+			name: "not found",
+			path: "/v1/assets/asset/properties",
+			get: func(cli Client) (Properties, error) {
+				return cli.AssetProperties("unknown")
+			},
+			set: func(cli Client, props Properties) (Properties, error) {
+				return cli.SetAssetProperties("unknown", props)
+			},
+			wantErr:    ErrNotFound,
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := Properties{"key1": "value1", "key2": "value2"}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					http.NotFound(w, r)
+					return
+				}
+				switch r.Method {
+				case http.MethodGet:
+				case http.MethodPatch:
+					var props Properties
+					if err := json.NewDecoder(r.Body).Decode(&props); err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+					for k, v := range props {
+						if v == "" {
+							delete(stored, k)
+							continue
+						}
+						stored[k] = v
+					}
+				default:
+					http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				if err := json.NewEncoder(w).Encode(stored); err != nil {
+					t.Errorf("error encoding properties: %v", err)
+				}
+			}))
+			defer srv.Close()
+
+			cli, err := NewClient(srv.URL, false, tt.opts...)
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			var want Properties
+			if tt.wantNilErr {
+				want = Properties{"key1": "value1", "key2": "value2"}
+			}
+			got, err := tt.get(cli)
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error getting properties: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error getting properties: want=%v got=%v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("properties mismatch (-want +got):\n%v", diff)
+			}
+
+			if tt.wantNilErr {
+				want = Properties{"key2": "value2", "key3": "value3"}
+			}
+			got, err = tt.set(cli, Properties{"key1": "", "key3": "value3"})
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error setting properties: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error setting properties: want=%v got=%v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("properties mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestClientEndpointPath(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL+"/inventory", false)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if _, err := cli.Teams("", Pagination{}); err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if _, err := cli.Owners("asset", Pagination{}); err != nil {
+		t.Fatalf("error getting owners: %v", err)
+	}
+
+	want := []string{"/inventory/v1/teams", "/inventory/v1/assets/asset/owners"}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Errorf("paths mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestClientDeletedFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      DeletedFilter
+		wantDeleted []string
+	}{
+		{
+			name:        "default",
+			filter:      DeletedDefault,
+			wantDeleted: nil,
+		},
+		{
+			name:        "exclude",
+			filter:      DeletedExclude,
+			wantDeleted: []string{"false"},
+		},
+		{
+			name:        "only",
+			filter:      DeletedOnly,
+			wantDeleted: []string{"true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []url.Values
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.Query())
+				switch r.URL.Path {
+				case "/v1/teams":
+					fmt.Fprint(w, `[{"id": "team-1", "identifier": "team", "name": "Team", "deleted": true}]`)
+				case "/v1/assets":
+					fmt.Fprint(w, `[{"id": "asset-1", "type": "Hostname", "identifier": "example.com", "deleted": true}]`)
+				}
+			}))
+			defer srv.Close()
+
+			cli, err := NewClient(srv.URL, false, WithDeletedFilter(tt.filter))
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			teams, err := cli.Teams("team", Pagination{})
+			if err != nil {
+				t.Fatalf("error getting teams: %v", err)
+			}
+			wantTeams := []TeamResp{{ID: "team-1", Identifier: "team", Name: "Team", Deleted: true}}
+			if diff := cmp.Diff(wantTeams, teams); diff != "" {
+				t.Errorf("teams mismatch (-want +got):\n%v", diff)
+			}
+
+			assets, err := cli.Assets("Hostname", "example.com", time.Time{}, Pagination{})
+			if err != nil {
+				t.Fatalf("error getting assets: %v", err)
+			}
+			wantAssets := []AssetResp{{ID: "asset-1", Type: "Hostname", Identifier: "example.com", Deleted: true}}
+			if diff := cmp.Diff(wantAssets, assets); diff != "" {
+				t.Errorf("assets mismatch (-want +got):\n%v", diff)
+			}
+
+			for _, q := range queries {
+				if diff := cmp.Diff(tt.wantDeleted, q["deleted"]); diff != "" {
+					t.Errorf("deleted parameter mismatch (-want +got):\n%v", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletedFilterValid(t *testing.T) {
+	for _, f := range []DeletedFilter{DeletedDefault, DeletedExclude, DeletedOnly} {
+		if !f.Valid() {
+			t.Errorf("filter %q is not valid", f)
+		}
+	}
+	if DeletedFilter("include").Valid() {
+		t.Error("filter \"include\" is valid")
+	}
+}
+
+func TestNewClientTransportOptions(t *testing.T) {
+	cli, err := NewClient("http://127.0.0.1:8000", false,
+		WithMaxIdleConnsPerHost(32),
+		WithIdleConnTimeout(30*time.Second),
+		WithHTTP2(true),
+	)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if got := cli.transport.MaxIdleConnsPerHost; got != 32 {
+		t.Errorf("unexpected max idle conns per host: got: %v, want: 32", got)
+	}
+	if got := cli.transport.IdleConnTimeout; got != 30*time.Second {
+		t.Errorf("unexpected idle conn timeout: got: %v, want: 30s", got)
+	}
+	if !cli.transport.ForceAttemptHTTP2 {
+		t.Error("HTTP/2 is not enabled")
+	}
+}
+
+func TestClientHTTP2(t *testing.T) {
+	tests := []struct {
+		name      string
+		http2     bool
+		wantProto int
+	}{
+		{
+			name:      "enabled",
+			http2:     true,
+			wantProto: 2,
+		},
+		{
+			name:      "disabled",
+			http2:     false,
+			wantProto: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotProto int
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotProto = r.ProtoMajor
+				fmt.Fprint(w, "[]")
+			}))
+			srv.EnableHTTP2 = true
+			srv.StartTLS()
+			defer srv.Close()
+
+			cli, err := NewClient(srv.URL, true, WithHTTP2(tt.http2))
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			if err := cli.Ping(); err != nil {
+				t.Fatalf("ping error: %v", err)
+			}
+
+			if gotProto != tt.wantProto {
+				t.Errorf("unexpected protocol version: got: %v, want: %v", gotProto, tt.wantProto)
+			}
+		})
+	}
+}
+
+// countingSerializer is a [Serializer] that counts the values it encodes
+// and decodes.
+type countingSerializer struct {
+	JSONSerializer
+	encoded, decoded *int
+}
+
+func (s countingSerializer) Encode(w io.Writer, v any) error {
+	*s.encoded++
+	return s.JSONSerializer.Encode(w, v)
+}
+
+func (s countingSerializer) Decode(r io.Reader, v any) error {
+	*s.decoded++
+	return s.JSONSerializer.Decode(r, v)
+}
+
+func TestClientWithSerializer(t *testing.T) {
+	var gotBody TeamReq
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "team-1", "identifier": "t1", "name": "Team 1"}`)
+	}))
+	defer srv.Close()
+
+	var encoded, decoded int
+	s := countingSerializer{encoded: &encoded, decoded: &decoded}
+
+	cli, err := NewClient(srv.URL, false, WithSerializer(s))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	team, err := cli.CreateTeam("t1", "Team 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBody := TeamReq{Identifier: "t1", Name: "Team 1"}
+	if diff := cmp.Diff(wantBody, gotBody); diff != "" {
+		t.Errorf("request body mismatch (-want +got):\n%v", diff)
+	}
+
+	wantTeam := TeamResp{ID: "team-1", Identifier: "t1", Name: "Team 1"}
+	if diff := cmp.Diff(wantTeam, team); diff != "" {
+		t.Errorf("team mismatch (-want +got):\n%v", diff)
+	}
+
+	if encoded != 1 || decoded != 1 {
+		t.Errorf("unexpected serializer calls: encoded=%v decoded=%v", encoded, decoded)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "server error",
+			err:  fmt.Errorf("wrapped: %w", InvalidStatusError{Expected: []int{200}, Returned: 503}),
+			want: true,
+		},
+		{
+			name: "too many requests",
+			err:  InvalidStatusError{Expected: []int{200}, Returned: 429},
+			want: true,
+		},
+		{
+			name: "client error",
+			err:  InvalidStatusError{Expected: []int{200}, Returned: 400},
+			want: false,
+		},
+		{
+			name: "network error",
+			err:  fmt.Errorf("HTTP request error: %w", &url.Error{Op: "Get", URL: "http://127.0.0.1:1", Err: errors.New("connection refused")}),
+			want: true,
+		},
+		{
+			name: "not found",
+			err:  ErrNotFound,
+			want: false,
+		},
+		{
+			name: "other error",
+			err:  errors.New("error"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("unexpected result: want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func BenchmarkClientUpdateAsset(b *testing.B) {
+	if err := resetGraph(); err != nil {
+		b.Fatalf("error setting up graph: %v", err)
+	}
+
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
+	if err != nil {
+		b.Fatalf("error creating client: %v", err)
+	}
+
+	asset, err := cli.CreateAsset("Type", "Identifier", time.Now(), Unexpired)
+	if err != nil {
+		b.Fatalf("error creating asset: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cli.UpdateAsset(asset.ID, asset.Type, asset.Identifier, time.Now(), Unexpired); err != nil {
+			b.Fatalf("error updating asset: %v", err)
+		}
+	}
+}
+
+func BenchmarkAllAssets(b *testing.B) {
+	if err := resetGraph(); err != nil {
+		b.Fatalf("error setting up graph: %v", err)
+	}
+
+	cli, err := NewClient(testinfra.InventoryEndpoint(), true)
+	if err != nil {
+		b.Fatalf("error creating client: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := cli.CreateAsset("Type", "Identifier"+strconv.Itoa(i), time.Now(), Unexpired); err != nil {
+			b.Fatalf("error creating asset: %v", err)
+		}
+	}
+
+	for _, size := range []int{0, 10, 100} {
+		b.Run("page size "+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := AllAssets(cli, "", "", time.Time{}, size); err != nil {
+					b.Fatalf("error getting assets: %v", err)
+				}
+			}
+		})
+	}
+}
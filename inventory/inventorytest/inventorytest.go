@@ -0,0 +1,416 @@
+// Package inventorytest provides utilities for testing code that interacts
+// with the Graph Asset Inventory.
+package inventorytest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// InMemory is an in-memory implementation of [inventory.Inventory]. It models
+// teams, assets and their owns and parent-of relations, including their time
+// attributes, their properties and pagination. Entities are returned in
+// creation order. It is safe for concurrent use.
+type InMemory struct {
+	// Now returns the current time. It is used when a timestamp is not
+	// provided. If nil, [time.Now] is used.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	lastID  int
+	teams   []inventory.TeamResp
+	assets  []inventory.AssetResp
+	parents []inventory.ParentOfResp
+	owners  []inventory.OwnsResp
+
+	// modified contains the last time every asset was created or
+	// updated by ID.
+	modified map[string]time.Time
+
+	// props contains the properties of the entities by ID.
+	props map[string]inventory.Properties
+}
+
+// NewInMemory returns an empty [InMemory] inventory.
+func NewInMemory() *InMemory {
+	return &InMemory{}
+}
+
+// Ping always succeeds.
+func (inv *InMemory) Ping() error {
+	return nil
+}
+
+// Teams returns the teams filtered by identifier. If identifier is empty, no
+// filter is applied.
+func (inv *InMemory) Teams(identifier string, pag inventory.Pagination) ([]inventory.TeamResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	var teams []inventory.TeamResp
+	for _, t := range inv.teams {
+		if identifier != "" && t.Identifier != identifier {
+			continue
+		}
+		teams = append(teams, t)
+	}
+	return page(teams, pag), nil
+}
+
+// CreateTeam creates a team. It returns [inventory.ErrAlreadyExists] if a
+// team with the same identifier already exists.
+func (inv *InMemory) CreateTeam(identifier, name string) (inventory.TeamResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for _, t := range inv.teams {
+		if t.Identifier == identifier {
+			return inventory.TeamResp{}, inventory.ErrAlreadyExists
+		}
+	}
+
+	team := inventory.TeamResp{
+		ID:         inv.newID("team"),
+		Identifier: identifier,
+		Name:       name,
+	}
+	inv.teams = append(inv.teams, team)
+	return team, nil
+}
+
+// UpdateTeam updates the team with the provided ID. It returns
+// [inventory.ErrNotFound] if the team does not exist.
+func (inv *InMemory) UpdateTeam(id, identifier, name string) (inventory.TeamResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for i, t := range inv.teams {
+		if t.ID != id {
+			continue
+		}
+		inv.teams[i].Identifier = identifier
+		inv.teams[i].Name = name
+		return inv.teams[i], nil
+	}
+	return inventory.TeamResp{}, inventory.ErrNotFound
+}
+
+// Assets returns the assets filtered by type and identifier. If validAt is
+// not zero, only the assets first seen before validAt and not expired at
+// validAt are returned.
+func (inv *InMemory) Assets(typ, identifier string, validAt time.Time, pag inventory.Pagination) ([]inventory.AssetResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	var assets []inventory.AssetResp
+	for _, a := range inv.assets {
+		if typ != "" && a.Type != typ {
+			continue
+		}
+		if identifier != "" && a.Identifier != identifier {
+			continue
+		}
+		if !validAt.IsZero() && (validAt.Before(a.FirstSeen) || inventory.IsExpired(a.Expiration, validAt)) {
+			continue
+		}
+		assets = append(assets, a)
+	}
+	return page(assets, pag), nil
+}
+
+// AssetsModifiedSince returns the assets created or updated after t. The
+// modification time is the time of the call to [InMemory.CreateAsset] or
+// [InMemory.UpdateAsset] as returned by Now, not the timestamp of the
+// asset.
+func (inv *InMemory) AssetsModifiedSince(t time.Time, pag inventory.Pagination) ([]inventory.AssetResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	var assets []inventory.AssetResp
+	for _, a := range inv.assets {
+		if inv.modified[a.ID].After(t) {
+			assets = append(assets, a)
+		}
+	}
+	return page(assets, pag), nil
+}
+
+// CreateAsset creates an asset. It returns [inventory.ErrAlreadyExists] if an
+// asset with the same type and identifier already exists.
+func (inv *InMemory) CreateAsset(typ, identifier string, timestamp, expiration time.Time) (inventory.AssetResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for _, a := range inv.assets {
+		if a.Type == typ && a.Identifier == identifier {
+			return inventory.AssetResp{}, inventory.ErrAlreadyExists
+		}
+	}
+
+	timestamp = inv.timestamp(timestamp)
+	asset := inventory.AssetResp{
+		ID:         inv.newID("asset"),
+		Type:       typ,
+		Identifier: identifier,
+		FirstSeen:  timestamp,
+		LastSeen:   timestamp,
+		Expiration: expiration,
+	}
+	inv.assets = append(inv.assets, asset)
+	inv.touch(asset.ID)
+	return asset, nil
+}
+
+// UpdateAsset updates the time attributes of the asset with the provided ID.
+// Zero times are ignored. It returns [inventory.ErrNotFound] if the asset does
+// not exist.
+func (inv *InMemory) UpdateAsset(id, typ, identifier string, timestamp, expiration time.Time) (inventory.AssetResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for i, a := range inv.assets {
+		if a.ID != id {
+			continue
+		}
+		if a.Type != typ || a.Identifier != identifier {
+			return inventory.AssetResp{}, fmt.Errorf("asset %v does not match %v/%v", id, typ, identifier)
+		}
+		if !timestamp.IsZero() {
+			inv.assets[i].FirstSeen, inv.assets[i].LastSeen = seen(a.FirstSeen, a.LastSeen, timestamp)
+		}
+		if !expiration.IsZero() {
+			inv.assets[i].Expiration = expiration
+		}
+		inv.touch(id)
+		return inv.assets[i], nil
+	}
+	return inventory.AssetResp{}, inventory.ErrNotFound
+}
+
+// Parents returns the "parent of" relations whose child is the asset with the
+// provided ID. It returns [inventory.ErrNotFound] if the asset does not
+// exist.
+func (inv *InMemory) Parents(assetID string, pag inventory.Pagination) ([]inventory.ParentOfResp, error) {
+	return inv.parentOf(assetID, pag, func(p inventory.ParentOfResp) bool {
+		return p.ChildID == assetID
+	})
+}
+
+// Children returns the "parent of" relations whose parent is the asset with
+// the provided ID. It returns [inventory.ErrNotFound] if the asset does not
+// exist.
+func (inv *InMemory) Children(assetID string, pag inventory.Pagination) ([]inventory.ParentOfResp, error) {
+	return inv.parentOf(assetID, pag, func(p inventory.ParentOfResp) bool {
+		return p.ParentID == assetID
+	})
+}
+
+func (inv *InMemory) parentOf(assetID string, pag inventory.Pagination, match func(inventory.ParentOfResp) bool) ([]inventory.ParentOfResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(assetID) {
+		return nil, inventory.ErrNotFound
+	}
+
+	var rels []inventory.ParentOfResp
+	for _, p := range inv.parents {
+		if match(p) {
+			rels = append(rels, p)
+		}
+	}
+	return page(rels, pag), nil
+}
+
+// Parent returns the "parent of" relation between the provided assets. It
+// returns [inventory.ErrNotFound] if the relation does not exist.
+func (inv *InMemory) Parent(childID, parentID string) (inventory.ParentOfResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for _, p := range inv.parents {
+		if p.ChildID == childID && p.ParentID == parentID {
+			return p, nil
+		}
+	}
+	return inventory.ParentOfResp{}, inventory.ErrNotFound
+}
+
+// UpsertParent creates or updates the "parent of" relation between the
+// provided assets. It returns [inventory.ErrNotFound] if any of the assets
+// does not exist.
+func (inv *InMemory) UpsertParent(childID, parentID string, timestamp, expiration time.Time) (inventory.ParentOfResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(childID) || !inv.assetExists(parentID) {
+		return inventory.ParentOfResp{}, inventory.ErrNotFound
+	}
+
+	timestamp = inv.timestamp(timestamp)
+	for i, p := range inv.parents {
+		if p.ChildID != childID || p.ParentID != parentID {
+			continue
+		}
+		inv.parents[i].FirstSeen, inv.parents[i].LastSeen = seen(p.FirstSeen, p.LastSeen, timestamp)
+		inv.parents[i].Expiration = expiration
+		return inv.parents[i], nil
+	}
+
+	rel := inventory.ParentOfResp{
+		ID:         inv.newID("parentof"),
+		ParentID:   parentID,
+		ChildID:    childID,
+		FirstSeen:  timestamp,
+		LastSeen:   timestamp,
+		Expiration: expiration,
+	}
+	inv.parents = append(inv.parents, rel)
+	return rel, nil
+}
+
+// Owners returns the "owns" relations of the asset with the provided ID. It
+// returns [inventory.ErrNotFound] if the asset does not exist.
+func (inv *InMemory) Owners(assetID string, pag inventory.Pagination) ([]inventory.OwnsResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(assetID) {
+		return nil, inventory.ErrNotFound
+	}
+
+	var owners []inventory.OwnsResp
+	for _, o := range inv.owners {
+		if o.AssetID == assetID {
+			owners = append(owners, o)
+		}
+	}
+	return page(owners, pag), nil
+}
+
+// Owner returns the "owns" relation between the provided asset and team.
+// It returns [inventory.ErrNotFound] if the relation does not exist.
+func (inv *InMemory) Owner(assetID, teamID string) (inventory.OwnsResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for _, o := range inv.owners {
+		if o.AssetID == assetID && o.TeamID == teamID {
+			return o, nil
+		}
+	}
+	return inventory.OwnsResp{}, inventory.ErrNotFound
+}
+
+// UpsertOwner creates or updates the "owns" relation between the provided
+// asset and team. If endTime is zero, the relation has no end time. It
+// returns [inventory.ErrNotFound] if the asset or the team do not exist.
+func (inv *InMemory) UpsertOwner(assetID, teamID string, startTime, endTime time.Time) (inventory.OwnsResp, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(assetID) || !inv.teamExists(teamID) {
+		return inventory.OwnsResp{}, inventory.ErrNotFound
+	}
+
+	var end *time.Time
+	if !endTime.IsZero() {
+		end = &endTime
+	}
+
+	for i, o := range inv.owners {
+		if o.AssetID != assetID || o.TeamID != teamID {
+			continue
+		}
+		inv.owners[i].StartTime = startTime
+		inv.owners[i].EndTime = end
+		return inv.owners[i], nil
+	}
+
+	owner := inventory.OwnsResp{
+		ID:        inv.newID("owns"),
+		TeamID:    teamID,
+		AssetID:   assetID,
+		StartTime: startTime,
+		EndTime:   end,
+	}
+	inv.owners = append(inv.owners, owner)
+	return owner, nil
+}
+
+// newID returns a unique ID with the provided prefix.
+func (inv *InMemory) newID(prefix string) string {
+	inv.lastID++
+	return fmt.Sprintf("%v-%v", prefix, inv.lastID)
+}
+
+// timestamp returns t or the current time if t is zero.
+func (inv *InMemory) timestamp(t time.Time) time.Time {
+	if !t.IsZero() {
+		return t
+	}
+	if inv.Now != nil {
+		return inv.Now()
+	}
+	return time.Now()
+}
+
+// touch sets the modification time of the asset with the provided ID to
+// the current time.
+func (inv *InMemory) touch(id string) {
+	if inv.modified == nil {
+		inv.modified = make(map[string]time.Time)
+	}
+	inv.modified[id] = inv.timestamp(time.Time{})
+}
+
+func (inv *InMemory) assetExists(id string) bool {
+	for _, a := range inv.assets {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (inv *InMemory) teamExists(id string) bool {
+	for _, t := range inv.teams {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// seen returns the first seen and last seen times resulting from seeing an
+// entity at t.
+func seen(firstSeen, lastSeen, t time.Time) (time.Time, time.Time) {
+	if t.Before(firstSeen) {
+		firstSeen = t
+	}
+	if t.After(lastSeen) {
+		lastSeen = t
+	}
+	return firstSeen, lastSeen
+}
+
+// page returns the page of items specified by pag. If the size of pag is
+// zero, all the items are returned.
+func page[T any](items []T, pag inventory.Pagination) []T {
+	if pag.Size == 0 {
+		return items
+	}
+
+	start := pag.Page * pag.Size
+	if start >= len(items) {
+		return nil
+	}
+	end := start + pag.Size
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
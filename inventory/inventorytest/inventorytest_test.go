@@ -0,0 +1,255 @@
+package inventorytest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+var (
+	t0 = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	t1 = t0.Add(time.Hour)
+	t2 = t1.Add(time.Hour)
+)
+
+func TestInMemoryTeams(t *testing.T) {
+	inv := NewInMemory()
+
+	for _, id := range []string{"team0", "team1", "team2"} {
+		if _, err := inv.CreateTeam(id, "name "+id); err != nil {
+			t.Fatalf("error creating team: %v", err)
+		}
+	}
+
+	if _, err := inv.CreateTeam("team0", "name"); !errors.Is(err, inventory.ErrAlreadyExists) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrAlreadyExists, err)
+	}
+
+	if _, err := inv.UpdateTeam("team-1", "team0", "new name"); err != nil {
+		t.Fatalf("error updating team: %v", err)
+	}
+
+	if _, err := inv.UpdateTeam("nonexistent", "team0", "name"); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrNotFound, err)
+	}
+
+	want := []inventory.TeamResp{
+		{ID: "team-1", Identifier: "team0", Name: "new name"},
+		{ID: "team-2", Identifier: "team1", Name: "name team1"},
+		{ID: "team-3", Identifier: "team2", Name: "name team2"},
+	}
+
+	got, err := inventory.AllTeams(inv, "", 2)
+	if err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%v", diff)
+	}
+
+	got, err = inv.Teams("team1", inventory.Pagination{})
+	if err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if diff := cmp.Diff(want[1:2], got); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%v", diff)
+	}
+
+	got, err = inv.Teams("", inventory.Pagination{Page: 3, Size: 1})
+	if err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("unexpected teams beyond the last page: %v", got)
+	}
+}
+
+func TestInMemoryAssets(t *testing.T) {
+	inv := NewInMemory()
+
+	asset, err := inv.CreateAsset("Hostname", "example.com", t1, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+
+	if _, err := inv.CreateAsset("Hostname", "example.com", t1, inventory.Unexpired); !errors.Is(err, inventory.ErrAlreadyExists) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrAlreadyExists, err)
+	}
+
+	// Seeing the asset before its first seen time updates the first seen
+	// time and keeps the last seen time.
+	if _, err := inv.UpdateAsset(asset.ID, asset.Type, asset.Identifier, t0, t2); err != nil {
+		t.Fatalf("error updating asset: %v", err)
+	}
+
+	want := []inventory.AssetResp{
+		{
+			ID:         asset.ID,
+			Type:       "Hostname",
+			Identifier: "example.com",
+			FirstSeen:  t0,
+			LastSeen:   t1,
+			Expiration: t2,
+		},
+	}
+
+	got, err := inv.Assets("Hostname", "example.com", t1, inventory.Pagination{})
+	if err != nil {
+		t.Fatalf("error getting assets: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+
+	got, err = inv.Assets("", "", t2.Add(time.Second), inventory.Pagination{})
+	if err != nil {
+		t.Fatalf("error getting assets: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("unexpected expired assets: %v", got)
+	}
+}
+
+func TestInMemoryAssetsModifiedSince(t *testing.T) {
+	now := t0
+	inv := NewInMemory()
+	inv.Now = func() time.Time { return now }
+
+	a0, err := inv.CreateAsset("Hostname", "a0.example.com", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	if _, err := inv.CreateAsset("Hostname", "a1.example.com", t0, inventory.Unexpired); err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+
+	now = t2
+	if _, err := inv.UpdateAsset(a0.ID, a0.Type, a0.Identifier, t0, time.Time{}); err != nil {
+		t.Fatalf("error updating asset: %v", err)
+	}
+	a2, err := inv.CreateAsset("Hostname", "a2.example.com", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+
+	got, err := inv.AssetsModifiedSince(t1, inventory.Pagination{})
+	if err != nil {
+		t.Fatalf("error getting assets: %v", err)
+	}
+
+	var ids []string
+	for _, a := range got {
+		ids = append(ids, a.ID)
+	}
+	if diff := cmp.Diff([]string{a0.ID, a2.ID}, ids); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestInMemoryRelations(t *testing.T) {
+	inv := NewInMemory()
+
+	child, err := inv.CreateAsset("Hostname", "example.com", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	parent, err := inv.CreateAsset("AWSAccount", "arn:aws:iam::123456789012:root", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	team, err := inv.CreateTeam("team", "name")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+
+	if _, err := inv.UpsertParent(child.ID, "nonexistent", t0, inventory.Unexpired); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrNotFound, err)
+	}
+	if _, err := inv.UpsertParent(child.ID, parent.ID, t0, inventory.Unexpired); err != nil {
+		t.Fatalf("error upserting parent: %v", err)
+	}
+	if _, err := inv.UpsertParent(child.ID, parent.ID, t1, t1); err != nil {
+		t.Fatalf("error upserting parent: %v", err)
+	}
+
+	wantParents := []inventory.ParentOfResp{
+		{
+			ID:         "parentof-4",
+			ParentID:   parent.ID,
+			ChildID:    child.ID,
+			FirstSeen:  t0,
+			LastSeen:   t1,
+			Expiration: t1,
+		},
+	}
+
+	gotParents, err := inventory.AllParents(inv, child.ID, 1)
+	if err != nil {
+		t.Fatalf("error getting parents: %v", err)
+	}
+	if diff := cmp.Diff(wantParents, gotParents); diff != "" {
+		t.Errorf("parents mismatch (-want +got):\n%v", diff)
+	}
+
+	gotChildren, err := inventory.AllChildren(inv, parent.ID, 0)
+	if err != nil {
+		t.Fatalf("error getting children: %v", err)
+	}
+	if diff := cmp.Diff(wantParents, gotChildren); diff != "" {
+		t.Errorf("children mismatch (-want +got):\n%v", diff)
+	}
+
+	gotParent, err := inv.Parent(child.ID, parent.ID)
+	if err != nil {
+		t.Fatalf("error getting parent: %v", err)
+	}
+	if diff := cmp.Diff(wantParents[0], gotParent); diff != "" {
+		t.Errorf("parent mismatch (-want +got):\n%v", diff)
+	}
+	if _, err := inv.Parent(parent.ID, child.ID); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrNotFound, err)
+	}
+
+	if _, err := inv.Owners("nonexistent", inventory.Pagination{}); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrNotFound, err)
+	}
+	if _, err := inv.UpsertOwner(child.ID, team.ID, t0, t1); err != nil {
+		t.Fatalf("error upserting owner: %v", err)
+	}
+	if _, err := inv.UpsertOwner(child.ID, team.ID, t0, time.Time{}); err != nil {
+		t.Fatalf("error upserting owner: %v", err)
+	}
+
+	wantOwners := []inventory.OwnsResp{
+		{
+			ID:        "owns-5",
+			TeamID:    team.ID,
+			AssetID:   child.ID,
+			StartTime: t0,
+			EndTime:   nil,
+		},
+	}
+
+	gotOwners, err := inventory.AllOwners(inv, child.ID, 1)
+	if err != nil {
+		t.Fatalf("error getting owners: %v", err)
+	}
+	if diff := cmp.Diff(wantOwners, gotOwners); diff != "" {
+		t.Errorf("owners mismatch (-want +got):\n%v", diff)
+	}
+
+	gotOwner, err := inv.Owner(child.ID, team.ID)
+	if err != nil {
+		t.Fatalf("error getting owner: %v", err)
+	}
+	if diff := cmp.Diff(wantOwners[0], gotOwner); diff != "" {
+		t.Errorf("owner mismatch (-want +got):\n%v", diff)
+	}
+	if _, err := inv.Owner(parent.ID, team.ID); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected error: want=%v, got=%v", inventory.ErrNotFound, err)
+	}
+}
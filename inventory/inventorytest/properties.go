@@ -0,0 +1,161 @@
+package inventorytest
+
+import (
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// AssetProperties returns the properties of the asset with the provided
+// ID. It returns [inventory.ErrNotFound] if the asset does not exist.
+func (inv *InMemory) AssetProperties(assetID string) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(assetID) {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.properties(assetID), nil
+}
+
+// SetAssetProperties sets the provided properties of the asset with the
+// provided ID. The properties with an empty value are removed. It returns
+// [inventory.ErrNotFound] if the asset does not exist.
+func (inv *InMemory) SetAssetProperties(assetID string, props inventory.Properties) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.assetExists(assetID) {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.setProperties(assetID, props), nil
+}
+
+// TeamProperties returns the properties of the team with the provided ID.
+// It returns [inventory.ErrNotFound] if the team does not exist.
+func (inv *InMemory) TeamProperties(teamID string) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.teamExists(teamID) {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.properties(teamID), nil
+}
+
+// SetTeamProperties sets the provided properties of the team with the
+// provided ID. The properties with an empty value are removed. It returns
+// [inventory.ErrNotFound] if the team does not exist.
+func (inv *InMemory) SetTeamProperties(teamID string, props inventory.Properties) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if !inv.teamExists(teamID) {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.setProperties(teamID, props), nil
+}
+
+// ParentProperties returns the properties of the "parent of" relation
+// between the provided assets. It returns [inventory.ErrNotFound] if the
+// relation does not exist.
+func (inv *InMemory) ParentProperties(childID, parentID string) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	id, ok := inv.parentID(childID, parentID)
+	if !ok {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.properties(id), nil
+}
+
+// SetParentProperties sets the provided properties of the "parent of"
+// relation between the provided assets. The properties with an empty value
+// are removed. It returns [inventory.ErrNotFound] if the relation does not
+// exist.
+func (inv *InMemory) SetParentProperties(childID, parentID string, props inventory.Properties) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	id, ok := inv.parentID(childID, parentID)
+	if !ok {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.setProperties(id, props), nil
+}
+
+// OwnerProperties returns the properties of the "owns" relation between the
+// provided asset and team. It returns [inventory.ErrNotFound] if the
+// relation does not exist.
+func (inv *InMemory) OwnerProperties(assetID, teamID string) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	id, ok := inv.ownerID(assetID, teamID)
+	if !ok {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.properties(id), nil
+}
+
+// SetOwnerProperties sets the provided properties of the "owns" relation
+// between the provided asset and team. The properties with an empty value
+// are removed. It returns [inventory.ErrNotFound] if the relation does not
+// exist.
+func (inv *InMemory) SetOwnerProperties(assetID, teamID string, props inventory.Properties) (inventory.Properties, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	id, ok := inv.ownerID(assetID, teamID)
+	if !ok {
+		return nil, inventory.ErrNotFound
+	}
+	return inv.setProperties(id, props), nil
+}
+
+// properties returns a copy of the properties of the entity with the
+// provided ID. It must be called with inv.mu held.
+func (inv *InMemory) properties(id string) inventory.Properties {
+	props := make(inventory.Properties)
+	for k, v := range inv.props[id] {
+		props[k] = v
+	}
+	return props
+}
+
+// setProperties sets the provided properties of the entity with the
+// provided ID and returns the resulting properties. It must be called with
+// inv.mu held.
+func (inv *InMemory) setProperties(id string, props inventory.Properties) inventory.Properties {
+	if inv.props == nil {
+		inv.props = make(map[string]inventory.Properties)
+	}
+	if inv.props[id] == nil {
+		inv.props[id] = make(inventory.Properties)
+	}
+	for k, v := range props {
+		if v == "" {
+			delete(inv.props[id], k)
+			continue
+		}
+		inv.props[id][k] = v
+	}
+	return inv.properties(id)
+}
+
+func (inv *InMemory) parentID(childID, parentID string) (string, bool) {
+	for _, p := range inv.parents {
+		if p.ChildID == childID && p.ParentID == parentID {
+			return p.ID, true
+		}
+	}
+	return "", false
+}
+
+func (inv *InMemory) ownerID(assetID, teamID string) (string, bool) {
+	for _, o := range inv.owners {
+		if o.AssetID == assetID && o.TeamID == teamID {
+			return o.ID, true
+		}
+	}
+	return "", false
+}
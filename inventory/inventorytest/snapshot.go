@@ -0,0 +1,186 @@
+package inventorytest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// Snapshot is a simplified representation of the state of an inventory that
+// is meant to be compared against an expected state. Internal IDs and time
+// attributes are replaced by natural identifiers and expiration flags, so
+// snapshots of different inventories can be compared.
+type Snapshot struct {
+	Teams  []Team
+	Assets []Asset
+}
+
+// Team represents a team in a [Snapshot].
+type Team struct {
+	Identifier string
+	Name       string
+}
+
+// Asset represents an asset and its relations in a [Snapshot]. An asset is
+// expired if its expiration is not a sentinel (see [inventory.IsUnexpired]).
+type Asset struct {
+	ID      AssetID
+	Expired bool
+	Parents []ParentOf
+	Owners  []Owns
+}
+
+// AssetID identifies an asset in a [Snapshot].
+type AssetID struct {
+	Type       string
+	Identifier string
+}
+
+// ParentOf represents a "parent of" relation in a [Snapshot]. A relation is
+// expired if its expiration is not a sentinel (see [inventory.IsUnexpired]).
+type ParentOf struct {
+	Parent  AssetID
+	Expired bool
+}
+
+// Owns represents an "owns" relation in a [Snapshot]. Team is the identifier
+// of the owner team. A relation is expired if it has an end time.
+type Owns struct {
+	Team    string
+	Expired bool
+}
+
+// TakeSnapshot returns a [Snapshot] of inv.
+func TakeSnapshot(inv inventory.Inventory) (Snapshot, error) {
+	var snap Snapshot
+
+	teams, err := inventory.AllTeams(inv, "", 0)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("could not get teams: %w", err)
+	}
+
+	for _, t := range teams {
+		st := Team{
+			Identifier: t.Identifier,
+			Name:       t.Name,
+		}
+		snap.Teams = append(snap.Teams, st)
+	}
+
+	assets, err := inventory.AllAssets(inv, "", "", time.Time{}, 0)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("could not get assets: %w", err)
+	}
+
+	for _, a := range assets {
+		sa, err := snapshotAsset(inv, assets, teams, a)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("could not get asset: %w", err)
+		}
+		snap.Assets = append(snap.Assets, sa)
+	}
+
+	return snap, nil
+}
+
+// snapshotAsset returns the representation of asset in a [Snapshot]. assets
+// and teams are used to resolve the natural identifiers of the relations.
+func snapshotAsset(inv inventory.Inventory, assets []inventory.AssetResp, teams []inventory.TeamResp, asset inventory.AssetResp) (Asset, error) {
+	sa := Asset{
+		ID: AssetID{
+			Type:       asset.Type,
+			Identifier: asset.Identifier,
+		},
+		Expired: !inventory.IsUnexpired(asset.Expiration),
+	}
+
+	parents, err := inventory.AllParents(inv, asset.ID, 0)
+	if err != nil {
+		return Asset{}, fmt.Errorf("could not get parents: %w", err)
+	}
+
+	for _, p := range parents {
+		parent, err := findAsset(assets, p.ParentID)
+		if err != nil {
+			return Asset{}, fmt.Errorf("could not find parent: %w", err)
+		}
+		sp := ParentOf{
+			Parent: AssetID{
+				Type:       parent.Type,
+				Identifier: parent.Identifier,
+			},
+			Expired: !inventory.IsUnexpired(p.Expiration),
+		}
+		sa.Parents = append(sa.Parents, sp)
+	}
+
+	owners, err := inventory.AllOwners(inv, asset.ID, 0)
+	if err != nil {
+		return Asset{}, fmt.Errorf("could not get owners: %w", err)
+	}
+
+	for _, o := range owners {
+		owner, err := findTeam(teams, o.TeamID)
+		if err != nil {
+			return Asset{}, fmt.Errorf("could not find owner: %w", err)
+		}
+		so := Owns{
+			Team:    owner.Identifier,
+			Expired: o.EndTime != nil,
+		}
+		sa.Owners = append(sa.Owners, so)
+	}
+
+	return sa, nil
+}
+
+func findAsset(assets []inventory.AssetResp, id string) (inventory.AssetResp, error) {
+	for _, a := range assets {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return inventory.AssetResp{}, errors.New("not found")
+}
+
+func findTeam(teams []inventory.TeamResp, id string) (inventory.TeamResp, error) {
+	for _, t := range teams {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return inventory.TeamResp{}, errors.New("not found")
+}
+
+// SnapshotOptions are the [cmp.Option] values used by [Diff]. They make the
+// comparison independent of the order of teams, assets and relations.
+var SnapshotOptions = []cmp.Option{
+	cmpopts.SortSlices(func(a, b Team) bool {
+		return a.Identifier < b.Identifier
+	}),
+	cmpopts.SortSlices(func(a, b Asset) bool {
+		return a.ID.String() < b.ID.String()
+	}),
+	cmpopts.SortSlices(func(a, b ParentOf) bool {
+		return a.Parent.String() < b.Parent.String()
+	}),
+	cmpopts.SortSlices(func(a, b Owns) bool {
+		return a.Team < b.Team
+	}),
+}
+
+// Diff returns a human-readable report of the differences between two
+// snapshots. It returns an empty string if they are equal.
+func Diff(want, got Snapshot) string {
+	return cmp.Diff(want, got, SnapshotOptions...)
+}
+
+// String returns the asset ID with the format "<type>/<identifier>".
+func (id AssetID) String() string {
+	return id.Type + "/" + id.Identifier
+}
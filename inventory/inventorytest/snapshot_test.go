@@ -0,0 +1,75 @@
+package inventorytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	inv := NewInMemory()
+
+	team0, err := inv.CreateTeam("team0", "team0 name")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	team1, err := inv.CreateTeam("team1", "team1 name")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	host, err := inv.CreateAsset("Hostname", "example.com", t0, inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	account, err := inv.CreateAsset("AWSAccount", "arn:aws:iam::123456789012:root", t0, t1)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	if _, err := inv.UpsertParent(host.ID, account.ID, t0, t1); err != nil {
+		t.Fatalf("error upserting parent: %v", err)
+	}
+	if _, err := inv.UpsertOwner(host.ID, team0.ID, t0, t1); err != nil {
+		t.Fatalf("error upserting owner: %v", err)
+	}
+	if _, err := inv.UpsertOwner(host.ID, team1.ID, t1, time.Time{}); err != nil {
+		t.Fatalf("error upserting owner: %v", err)
+	}
+
+	// The expected snapshot is intentionally unordered.
+	want := Snapshot{
+		Teams: []Team{
+			{Identifier: "team1", Name: "team1 name"},
+			{Identifier: "team0", Name: "team0 name"},
+		},
+		Assets: []Asset{
+			{
+				ID:      AssetID{Type: "AWSAccount", Identifier: "arn:aws:iam::123456789012:root"},
+				Expired: true,
+			},
+			{
+				ID:      AssetID{Type: "Hostname", Identifier: "example.com"},
+				Expired: false,
+				Parents: []ParentOf{
+					{
+						Parent:  AssetID{Type: "AWSAccount", Identifier: "arn:aws:iam::123456789012:root"},
+						Expired: true,
+					},
+				},
+				Owners: []Owns{
+					{Team: "team1", Expired: false},
+					{Team: "team0", Expired: true},
+				},
+			},
+		},
+	}
+
+	got, err := TakeSnapshot(inv)
+	if err != nil {
+		t.Fatalf("error taking snapshot: %v", err)
+	}
+
+	if diff := Diff(want, got); diff != "" {
+		t.Errorf("snapshot mismatch (-want +got):\n%v", diff)
+	}
+}
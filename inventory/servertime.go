@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// WithServerTime makes the client estimate the clock of the Asset Inventory
+// server from the Date header of its responses, so [Client.Now] is not
+// affected by the drift of the local clock. The Date header has a
+// resolution of one second, so the estimation can be up to one second
+// behind. It is disabled by default.
+func WithServerTime(enabled bool) ClientOption {
+	return func(cli *Client) {
+		if enabled {
+			cli.clockOffset = new(atomic.Int64)
+		}
+	}
+}
+
+// Now returns the current time. If the client estimates the clock of the
+// Asset Inventory server (see [WithServerTime]), the time of the server is
+// returned. Otherwise, or if no response has been received yet, the local
+// time is returned.
+func (cli Client) Now() time.Time {
+	now := time.Now()
+	if cli.clockOffset == nil {
+		return now
+	}
+	return now.Add(time.Duration(cli.clockOffset.Load()))
+}
+
+// serverTimeTransport is an [http.RoundTripper] that records the offset
+// between the clock of the server, reported in the Date header of the
+// responses, and the local clock.
+type serverTimeTransport struct {
+	next   http.RoundTripper
+	offset *atomic.Int64
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t serverTimeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		t.offset.Store(int64(date.Sub(time.Now().Truncate(time.Second))))
+	}
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package inventory
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientNowServerTime(t *testing.T) {
+	skew := 2 * time.Hour
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		serverTime bool
+		wantOffset time.Duration
+	}{
+		{
+			name:       "server time",
+			serverTime: true,
+			wantOffset: skew,
+		},
+		{
+			name:       "local time",
+			serverTime: false,
+			wantOffset: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, err := NewClient(srv.URL, false, WithServerTime(tt.serverTime))
+			if err != nil {
+				t.Fatalf("error creating client: %v", err)
+			}
+
+			if offset := time.Until(cli.Now()); offset < -2*time.Second || offset > 2*time.Second {
+				t.Errorf("unexpected offset before the first response: %v", offset)
+			}
+
+			if err := cli.Ping(); err != nil {
+				t.Fatalf("error pinging: %v", err)
+			}
+
+			offset := time.Until(cli.Now())
+			if d := offset - tt.wantOffset; d < -2*time.Second || d > 2*time.Second {
+				t.Errorf("unexpected offset: want=%v got=%v", tt.wantOffset, offset)
+			}
+		})
+	}
+}
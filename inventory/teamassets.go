@@ -0,0 +1,60 @@
+package inventory
+
+import "time"
+
+// TeamAssetType is the type of the assets that represent teams when the
+// Asset Inventory models teams as assets.
+const TeamAssetType = "Team"
+
+// WithTeamsAsAssets makes the client model teams as assets of type
+// [TeamAssetType], whose identifier is the identifier of the team. It allows
+// to use the same client with the deployments of the Asset Inventory that
+// have been migrated to the new model and with the ones that have not. It is
+// disabled by default.
+//
+// The team operations of the client are mapped onto the assets endpoints.
+// Assets do not have a name, so the name of the teams is not stored and
+// the returned teams have an empty name. The owns relations use the ID of
+// the team assets.
+func WithTeamsAsAssets(enabled bool) ClientOption {
+	return func(cli *Client) {
+		cli.teamsAsAssets = enabled
+	}
+}
+
+// walkTeamAssetsPage implements [Client.WalkTeamsPage] when teams are
+// modeled as assets.
+func (cli Client) walkTeamAssetsPage(identifier string, pag Pagination, f func(TeamResp) error) (int, PageInfo, error) {
+	return cli.WalkAssetsPage(TeamAssetType, identifier, time.Time{}, pag, func(asset AssetResp) error {
+		return f(teamFromAsset(asset))
+	})
+}
+
+// createTeamAsset implements [Client.CreateTeam] when teams are modeled as
+// assets.
+func (cli Client) createTeamAsset(identifier string) (TeamResp, error) {
+	asset, err := cli.CreateAsset(TeamAssetType, identifier, time.Now(), Unexpired)
+	if err != nil {
+		return TeamResp{}, err
+	}
+	return teamFromAsset(asset), nil
+}
+
+// updateTeamAsset implements [Client.UpdateTeam] when teams are modeled as
+// assets.
+func (cli Client) updateTeamAsset(id, identifier string) (TeamResp, error) {
+	asset, err := cli.UpdateAsset(id, TeamAssetType, identifier, time.Now(), Unexpired)
+	if err != nil {
+		return TeamResp{}, err
+	}
+	return teamFromAsset(asset), nil
+}
+
+// teamFromAsset returns the team represented by the provided asset.
+func teamFromAsset(asset AssetResp) TeamResp {
+	return TeamResp{
+		ID:         asset.ID,
+		Identifier: asset.Identifier,
+		Deleted:    asset.Deleted,
+	}
+}
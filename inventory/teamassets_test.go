@@ -0,0 +1,111 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTeamsAsAssets(t *testing.T) {
+	var (
+		requests []string
+		bodies   []AssetReq
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+
+		if r.Method != http.MethodGet {
+			var body AssetReq
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			bodies = append(bodies, body)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `[{"id": "asset-1", "type": "Team", "identifier": "t1", "deleted": true}]`)
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": "asset-2", "type": "Team", "identifier": "t2"}`)
+		case http.MethodPut:
+			fmt.Fprint(w, `{"id": "asset-1", "type": "Team", "identifier": "t1"}`)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, false, WithTeamsAsAssets(true))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	teams, err := cli.Teams("t1", Pagination{})
+	if err != nil {
+		t.Fatalf("error getting teams: %v", err)
+	}
+	wantTeams := []TeamResp{{ID: "asset-1", Identifier: "t1", Deleted: true}}
+	if diff := cmp.Diff(wantTeams, teams); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%v", diff)
+	}
+
+	team, err := cli.CreateTeam("t2", "Team 2")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	if diff := cmp.Diff(TeamResp{ID: "asset-2", Identifier: "t2"}, team); diff != "" {
+		t.Errorf("created team mismatch (-want +got):\n%v", diff)
+	}
+
+	team, err = cli.UpdateTeam("asset-1", "t1", "Team 1")
+	if err != nil {
+		t.Fatalf("error updating team: %v", err)
+	}
+	if diff := cmp.Diff(TeamResp{ID: "asset-1", Identifier: "t1"}, team); diff != "" {
+		t.Errorf("updated team mismatch (-want +got):\n%v", diff)
+	}
+
+	wantRequests := []string{
+		"GET /v1/assets?asset_identifier=t1&asset_type=Team",
+		"POST /v1/assets?",
+		"PUT /v1/assets/asset-1?",
+	}
+	if diff := cmp.Diff(wantRequests, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%v", diff)
+	}
+
+	for _, b := range bodies {
+		if b.Type != TeamAssetType || !b.Expiration.Equal(Unexpired) {
+			t.Errorf("unexpected request body: %+v", b)
+		}
+	}
+}
+
+func TestTeamsAsAssetsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+		case http.MethodPut:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, false, WithTeamsAsAssets(true))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if _, err := cli.CreateTeam("t1", "Team 1"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("unexpected create error: want=%v got=%v", ErrAlreadyExists, err)
+	}
+	if _, err := cli.UpdateTeam("asset-1", "t1", "Team 1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected update error: want=%v got=%v", ErrNotFound, err)
+	}
+}
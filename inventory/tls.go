@@ -0,0 +1,175 @@
+package inventory
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// TLSFiles are the files containing the TLS material used by the client to
+// connect to the Asset Inventory. CertFile and KeyFile contain the PEM
+// encoded client certificate and private key. CAFile contains the PEM encoded
+// certificates of the CAs used to verify the server certificate. Empty
+// fields are ignored.
+type TLSFiles struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// WithTLSFiles configures the client to load its TLS material from the
+// provided files. The files can be reloaded later calling
+// [Client.ReloadTLS].
+func WithTLSFiles(files TLSFiles) ClientOption {
+	return func(cli *Client) {
+		cli.tlsFiles = files
+	}
+}
+
+// ReloadTLS reloads the TLS files of the client if their contents have
+// changed since the last load. When this happens, the transport of the
+// client is rebuilt, so new connections use the new TLS material. It
+// reports whether the files were reloaded. If the client has not been
+// configured with [WithTLSFiles], ReloadTLS does nothing.
+func (cli Client) ReloadTLS() (bool, error) {
+	if cli.tlsTransport == nil {
+		return false, nil
+	}
+	return cli.tlsTransport.reload()
+}
+
+// tlsTransport is an [http.RoundTripper] that rebuilds the underlying
+// [http.Transport] when the TLS files change.
+type tlsTransport struct {
+	files TLSFiles
+
+	mu     sync.Mutex
+	digest []byte
+	tr     atomic.Pointer[http.Transport]
+}
+
+// newTLSTransport returns a [tlsTransport] based on tr. The TLS
+// configuration of tr is updated with the contents of the provided files.
+func newTLSTransport(tr *http.Transport, files TLSFiles) (*tlsTransport, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("both the certificate and key files must be provided")
+	}
+
+	t := &tlsTransport{files: files}
+	data, digest, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := buildTLSConfig(tr.TLSClientConfig, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.TLSClientConfig = tlsConfig
+	t.tr.Store(tr)
+	t.digest = digest
+	return t, nil
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *tlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.tr.Load().RoundTrip(req)
+}
+
+// reload rebuilds the underlying transport if the contents of the TLS files
+// have changed.
+func (t *tlsTransport) reload() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, digest, err := t.read()
+	if err != nil {
+		return false, err
+	}
+
+	if bytes.Equal(digest, t.digest) {
+		return false, nil
+	}
+
+	old := t.tr.Load()
+	tlsConfig, err := buildTLSConfig(old.TLSClientConfig, data)
+	if err != nil {
+		return false, err
+	}
+
+	tr := old.Clone()
+	tr.TLSClientConfig = tlsConfig
+	t.tr.Store(tr)
+	t.digest = digest
+	old.CloseIdleConnections()
+	return true, nil
+}
+
+// tlsData is the contents of the TLS files.
+type tlsData struct {
+	cert, key, ca []byte
+}
+
+// read reads the TLS files. It returns their contents and a digest that
+// identifies them.
+func (t *tlsTransport) read() (tlsData, []byte, error) {
+	var data tlsData
+
+	h := sha256.New()
+	for _, f := range []struct {
+		name string
+		dst  *[]byte
+	}{
+		{t.files.CertFile, &data.cert},
+		{t.files.KeyFile, &data.key},
+		{t.files.CAFile, &data.ca},
+	} {
+		if f.name == "" {
+			continue
+		}
+		b, err := os.ReadFile(f.name)
+		if err != nil {
+			return tlsData{}, nil, fmt.Errorf("could not read TLS file: %w", err)
+		}
+		*f.dst = b
+		h.Write(b)
+	}
+	return data, h.Sum(nil), nil
+}
+
+// buildTLSConfig returns a copy of base with the client certificate and the
+// root CAs contained in data.
+func buildTLSConfig(base *tls.Config, data tlsData) (*tls.Config, error) {
+	var cfg *tls.Config
+	if base != nil {
+		cfg = base.Clone()
+	} else {
+		cfg = &tls.Config{}
+	}
+
+	if data.cert != nil {
+		cert, err := tls.X509KeyPair(data.cert, data.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if data.ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data.ca) {
+			return nil, errors.New("invalid CA certificates")
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,195 @@
+package inventory
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// genCert generates a self-signed certificate with the provided common name.
+// It returns the PEM encoded certificate and private key.
+func genCert(t *testing.T, cn string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("error marshaling key: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+// writeFile writes data to the file name in dir and returns its path.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	return path
+}
+
+// newTLSServer returns a TLS server with a self-signed certificate that
+// requires a client certificate and responds with an empty list of teams.
+// The common name of every client certificate is sent to cns. It also
+// returns the PEM encoded server certificate.
+func newTLSServer(t *testing.T, cns chan<- string) (*httptest.Server, []byte) {
+	t.Helper()
+
+	certPEM, keyPEM := genCert(t, "server")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("error loading server certificate: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cns <- r.TLS.PeerCertificates[0].Subject.CommonName
+		fmt.Fprint(w, "[]")
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}
+	srv.StartTLS()
+	return srv, certPEM
+}
+
+func TestClientReloadTLS(t *testing.T) {
+	cns := make(chan string, 1)
+	srv, caPEM := newTLSServer(t, cns)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certPEM, keyPEM := genCert(t, "client-1")
+	files := TLSFiles{
+		CertFile: writeFile(t, dir, "tls.crt", certPEM),
+		KeyFile:  writeFile(t, dir, "tls.key", keyPEM),
+		CAFile:   writeFile(t, dir, "ca.crt", caPEM),
+	}
+
+	cli, err := NewClient(srv.URL, false, WithTLSFiles(files))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if err := cli.Ping(); err != nil {
+		t.Fatalf("ping error: %v", err)
+	}
+	if cn := <-cns; cn != "client-1" {
+		t.Errorf("unexpected client certificate: got: %v, want: client-1", cn)
+	}
+
+	reloaded, err := cli.ReloadTLS()
+	if err != nil {
+		t.Fatalf("error reloading TLS files: %v", err)
+	}
+	if reloaded {
+		t.Errorf("TLS files reloaded without changes")
+	}
+
+	// Rotate the client certificate and the server CA.
+	srv2, caPEM := newTLSServer(t, cns)
+	defer srv2.Close()
+
+	certPEM, keyPEM = genCert(t, "client-2")
+	writeFile(t, dir, "tls.crt", certPEM)
+	writeFile(t, dir, "tls.key", keyPEM)
+	writeFile(t, dir, "ca.crt", caPEM)
+
+	// cli2 sends its requests through the transport of cli, so it uses
+	// the same TLS files.
+	cli2, err := NewClient(srv2.URL, false, WithTransportWrapper(func(http.RoundTripper) http.RoundTripper {
+		return cli.httpcli.Transport
+	}))
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	if err := cli2.Ping(); err == nil {
+		t.Fatalf("ping succeeded before reloading the TLS files")
+	}
+
+	reloaded, err = cli.ReloadTLS()
+	if err != nil {
+		t.Fatalf("error reloading TLS files: %v", err)
+	}
+	if !reloaded {
+		t.Errorf("TLS files not reloaded")
+	}
+
+	if err := cli2.Ping(); err != nil {
+		t.Fatalf("ping error: %v", err)
+	}
+	if cn := <-cns; cn != "client-2" {
+		t.Errorf("unexpected client certificate: got: %v, want: client-2", cn)
+	}
+}
+
+func TestNewClientTLSFilesError(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, _ := genCert(t, "client")
+
+	tests := []struct {
+		name  string
+		files TLSFiles
+	}{
+		{
+			name:  "missing key",
+			files: TLSFiles{CertFile: writeFile(t, dir, "tls.crt", certPEM)},
+		},
+		{
+			name:  "file not found",
+			files: TLSFiles{CAFile: filepath.Join(dir, "notfound.crt")},
+		},
+		{
+			name:  "invalid CA",
+			files: TLSFiles{CAFile: writeFile(t, dir, "ca.crt", []byte("invalid"))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClient("https://127.0.0.1:8000", false, WithTLSFiles(tt.files)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
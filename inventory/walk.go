@@ -0,0 +1,187 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// teamWalker is implemented by the inventories that can stream the teams
+// of a page, like [Client].
+type teamWalker interface {
+	WalkTeamsPage(identifier string, pag Pagination, f func(TeamResp) error) (int, PageInfo, error)
+}
+
+// assetWalker is implemented by the inventories that can stream the assets
+// of a page, like [Client].
+type assetWalker interface {
+	WalkAssetsPage(typ, identifier string, validAt time.Time, pag Pagination, f func(AssetResp) error) (int, PageInfo, error)
+}
+
+// modifiedAssetWalker is implemented by the inventories that can stream the
+// assets modified after a given time, like [Client].
+type modifiedAssetWalker interface {
+	WalkAssetsModifiedSincePage(t time.Time, pag Pagination, f func(AssetResp) error) (int, PageInfo, error)
+}
+
+// modifiedAssetLister is implemented by the inventories that can list the
+// assets modified after a given time, like the in-memory inventory of
+// the inventorytest package.
+type modifiedAssetLister interface {
+	AssetsModifiedSince(t time.Time, pag Pagination) ([]AssetResp, error)
+}
+
+// ErrModifiedSinceUnsupported is returned by [WalkAssetsModifiedSince] when
+// the inventory cannot filter the assets by modification time.
+var ErrModifiedSinceUnsupported = errors.New("modified since filter not supported")
+
+// WalkTeams is like [AllTeams] but, instead of returning the teams, it
+// calls f with every team. If inv is a [Client], the teams are decoded as
+// they are received, so neither the result set nor the pages are buffered.
+// This keeps memory usage constant when listing large result sets. If f
+// returns an error, the walk stops and the error is returned.
+func WalkTeams(inv Inventory, identifier string, pageSize int, f func(TeamResp) error) error {
+	if w, ok := inv.(teamWalker); ok {
+		return walk(pageSize, f, func(pag Pagination, f func(TeamResp) error) (int, error) {
+			n, _, err := w.WalkTeamsPage(identifier, pag, f)
+			return n, err
+		})
+	}
+
+	return walk(pageSize, f, func(pag Pagination, f func(TeamResp) error) (int, error) {
+		teams, err := inv.Teams(identifier, pag)
+		if err != nil {
+			return 0, err
+		}
+		return each(teams, f)
+	})
+}
+
+// WalkAssets is like [AllAssets] but, instead of returning the assets, it
+// calls f with every asset. If inv is a [Client], the assets are decoded as
+// they are received, so neither the result set nor the pages are buffered.
+// This keeps memory usage constant when listing large result sets. If f
+// returns an error, the walk stops and the error is returned.
+func WalkAssets(inv Inventory, typ, identifier string, validAt time.Time, pageSize int, f func(AssetResp) error) error {
+	if w, ok := inv.(assetWalker); ok {
+		return walk(pageSize, f, func(pag Pagination, f func(AssetResp) error) (int, error) {
+			n, _, err := w.WalkAssetsPage(typ, identifier, validAt, pag, f)
+			return n, err
+		})
+	}
+
+	return walk(pageSize, f, func(pag Pagination, f func(AssetResp) error) (int, error) {
+		assets, err := inv.Assets(typ, identifier, validAt, pag)
+		if err != nil {
+			return 0, err
+		}
+		return each(assets, f)
+	})
+}
+
+// WalkAssetsModifiedSince calls f with every asset of inv created or updated
+// after t. The assets are retrieved using pages of the provided size. If
+// pageSize is zero, pagination is disabled. If inv is a [Client], the assets
+// are decoded as they are received, so neither the result set nor the pages
+// are buffered. If inv cannot filter the assets by modification time, it
+// returns [ErrModifiedSinceUnsupported], so callers can fall back to
+// [WalkAssets]. If f returns an error, the walk stops and the error is
+// returned.
+func WalkAssetsModifiedSince(inv Inventory, t time.Time, pageSize int, f func(AssetResp) error) error {
+	if w, ok := inv.(modifiedAssetWalker); ok {
+		return walk(pageSize, f, func(pag Pagination, f func(AssetResp) error) (int, error) {
+			n, _, err := w.WalkAssetsModifiedSincePage(t, pag, f)
+			return n, err
+		})
+	}
+
+	l, ok := inv.(modifiedAssetLister)
+	if !ok {
+		return ErrModifiedSinceUnsupported
+	}
+	return walk(pageSize, f, func(pag Pagination, f func(AssetResp) error) (int, error) {
+		assets, err := l.AssetsModifiedSince(t, pag)
+		if err != nil {
+			return 0, err
+		}
+		return each(assets, f)
+	})
+}
+
+// walk is like [paginate] but, instead of concatenating the pages, every
+// page is walked with f. get must call f with every item of the requested
+// page and return the number of items in the page.
+func walk[T any](size int, f func(T) error, get func(pag Pagination, f func(T) error) (int, error)) error {
+	if size == 0 {
+		_, err := get(Pagination{}, f)
+		return err
+	}
+
+	for page := 0; ; page++ {
+		n, err := get(Pagination{Page: page, Size: size}, f)
+		if err != nil {
+			return err
+		}
+		if n < size {
+			return nil
+		}
+	}
+}
+
+// each calls f with every item. It returns the number of items.
+func each[T any](items []T, f func(T) error) (int, error) {
+	for _, item := range items {
+		if err := f(item); err != nil {
+			return 0, err
+		}
+	}
+	return len(items), nil
+}
+
+// decodeEach decodes the JSON array read from r and calls f with every
+// element as soon as it is decoded. It returns the number of decoded
+// elements. A JSON null is considered an empty array. If s is not a
+// [JSONSerializer], the whole array is decoded with s before calling f.
+// Errors returned by f are returned unchanged.
+func decodeEach[T any](s Serializer, r io.Reader, f func(T) error) (int, error) {
+	if _, ok := s.(JSONSerializer); !ok {
+		var items []T
+		if err := s.Decode(r, &items); err != nil {
+			return 0, fmt.Errorf("invalid response: %w", err)
+		}
+		return each(items, f)
+	}
+
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, fmt.Errorf("invalid response: %w", err)
+	}
+	if tok == nil {
+		return 0, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, errors.New("invalid response: not a JSON array")
+	}
+
+	n := 0
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return n, fmt.Errorf("invalid response: %w", err)
+		}
+		n++
+
+		if err := f(item); err != nil {
+			return n, err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return n, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,242 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWalkAssets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch page {
+		case 0:
+			fmt.Fprint(w, `[{"id": "asset-1", "type": "Hostname", "identifier": "a.example.com"}, {"id": "asset-2", "type": "Hostname", "identifier": "b.example.com"}]`)
+		case 1:
+			fmt.Fprint(w, `[{"id": "asset-3", "type": "Hostname", "identifier": "c.example.com"}]`)
+		default:
+			t.Errorf("unexpected page: %v", page)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, false)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	var got []string
+	err = WalkAssets(cli, "Hostname", "", time.Time{}, 2, func(asset AssetResp) error {
+		got = append(got, asset.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"asset-1", "asset-2", "asset-3"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+
+	errStop := errors.New("stop")
+	got = nil
+	err = WalkAssets(cli, "Hostname", "", time.Time{}, 2, func(asset AssetResp) error {
+		got = append(got, asset.ID)
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"asset-1"}, got); diff != "" {
+		t.Errorf("assets mismatch after stopping (-want +got):\n%v", diff)
+	}
+}
+
+func TestWalkAssetsModifiedSince(t *testing.T) {
+	since := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("updated_after"); got != "2023-01-01T12:00:00Z" {
+			t.Errorf("unexpected updated_after: %v", got)
+		}
+		if r.URL.Query().Has("asset_type") {
+			t.Errorf("unexpected asset_type: %v", r.URL.Query().Get("asset_type"))
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch page {
+		case 0:
+			fmt.Fprint(w, `[{"id": "asset-1", "type": "Hostname", "identifier": "a.example.com"}, {"id": "asset-2", "type": "IP", "identifier": "192.0.2.1"}]`)
+		case 1:
+			fmt.Fprint(w, `[]`)
+		default:
+			t.Errorf("unexpected page: %v", page)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL, false)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+
+	var got []string
+	err = WalkAssetsModifiedSince(cli, since, 2, func(asset AssetResp) error {
+		got = append(got, asset.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"asset-1", "asset-2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assets mismatch (-want +got):\n%v", diff)
+	}
+
+	assets, err := cli.AssetsModifiedSince(since, Pagination{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(assets) != 2 {
+		t.Errorf("unexpected number of assets: %v", len(assets))
+	}
+}
+
+func TestWalkAssetsModifiedSinceUnsupported(t *testing.T) {
+	err := WalkAssetsModifiedSince(&pagedInventory{}, time.Now(), 2, func(asset AssetResp) error {
+		return nil
+	})
+	if !errors.Is(err, ErrModifiedSinceUnsupported) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// pagedInventory is an [Inventory] that returns the teams in pages and does
+// not support streaming.
+type pagedInventory struct {
+	Inventory
+	teams []TeamResp
+	calls int
+}
+
+func (inv *pagedInventory) Teams(identifier string, pag Pagination) ([]TeamResp, error) {
+	inv.calls++
+	if pag.Size == 0 {
+		return inv.teams, nil
+	}
+	start := pag.Page * pag.Size
+	if start >= len(inv.teams) {
+		return nil, nil
+	}
+	end := start + pag.Size
+	if end > len(inv.teams) {
+		end = len(inv.teams)
+	}
+	return inv.teams[start:end], nil
+}
+
+func TestWalkTeamsFallback(t *testing.T) {
+	inv := &pagedInventory{
+		teams: []TeamResp{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}},
+	}
+
+	var got []string
+	err := WalkTeams(inv, "", 2, func(team TeamResp) error {
+		got = append(got, team.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"1", "2", "3", "4"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%v", diff)
+	}
+	if inv.calls != 3 {
+		t.Errorf("unexpected number of pages requested: %v", inv.calls)
+	}
+}
+
+func TestDecodeEach(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer Serializer
+		data       string
+		want       []string
+		wantNilErr bool
+	}{
+		{
+			name:       "array",
+			serializer: JSONSerializer{},
+			data:       `[{"id": "1"}, {"id": "2"}]`,
+			want:       []string{"1", "2"},
+			wantNilErr: true,
+		},
+		{
+			name:       "empty array",
+			serializer: JSONSerializer{},
+			data:       `[]`,
+			want:       nil,
+			wantNilErr: true,
+		},
+		{
+			name:       "null",
+			serializer: JSONSerializer{},
+			data:       `null`,
+			want:       nil,
+			wantNilErr: true,
+		},
+		{
+			name:       "object",
+			serializer: JSONSerializer{},
+			data:       `{"id": "1"}`,
+			want:       nil,
+			wantNilErr: false,
+		},
+		{
+			name:       "truncated array",
+			serializer: JSONSerializer{},
+			data:       `[{"id": "1"}, {"id": `,
+			want:       []string{"1"},
+			wantNilErr: false,
+		},
+		{
+			name:       "custom serializer",
+			serializer: countingSerializer{encoded: new(int), decoded: new(int)},
+			data:       `[{"id": "1"}, {"id": "2"}]`,
+			want:       []string{"1", "2"},
+			wantNilErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			n, err := decodeEach(tt.serializer, strings.NewReader(tt.data), func(team TeamResp) error {
+				got = append(got, team.ID)
+				return nil
+			})
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("decoded items mismatch (-want +got):\n%v", diff)
+			}
+			if n != len(got) {
+				t.Errorf("unexpected number of items: got: %v, want: %v", n, len(got))
+			}
+		})
+	}
+}
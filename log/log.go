@@ -51,9 +51,23 @@ var (
 	Error = &logger{ErrorLevel}
 )
 
+// Log formats.
+const (
+	// TextFormat is the default log format. Every line starts with the
+	// date and time in UTC.
+	TextFormat = "text"
+
+	// PrettyFormat is a human-friendly log format meant for local
+	// development. Every line starts with a short timestamp and the
+	// colored log level.
+	PrettyFormat = "pretty"
+)
+
 type globalState struct {
 	currentLevel  Level
 	defaultLogger Logger
+	output        io.Writer
+	format        string
 }
 
 var (
@@ -61,6 +75,8 @@ var (
 	state = globalState{
 		currentLevel:  InfoLevel,
 		defaultLogger: newDefaultLogger(os.Stderr),
+		output:        os.Stderr,
+		format:        TextFormat,
 	}
 )
 
@@ -74,6 +90,35 @@ func newDefaultLogger(w io.Writer) Logger {
 	return log.New(w, "", log.Ldate|log.Ltime|log.LUTC|log.Lmicroseconds)
 }
 
+// newLogger returns the default logger for the provided output and format.
+func newLogger(w io.Writer, format string) Logger {
+	if w == nil {
+		return nil
+	}
+	if lw, ok := w.(levelWriter); ok {
+		return newLevelOutputLogger(lw)
+	}
+	if format == PrettyFormat {
+		return newPrettyLogger(w)
+	}
+	return newDefaultLogger(w)
+}
+
+// levelLogger is implemented by the default loggers that format the
+// messages of every level differently.
+type levelLogger interface {
+	atLevel(level Level) Logger
+}
+
+// loggerAt returns the default logger used to write messages with the
+// provided level. It returns nil if the default loggers are disabled.
+func (g globalState) loggerAt(level Level) Logger {
+	if ll, ok := g.defaultLogger.(levelLogger); ok {
+		return ll.atLevel(level)
+	}
+	return g.defaultLogger
+}
+
 // logBridge augments the Logger type with the io.Writer interface enabling
 // NewStdLogger to connect Go's standard library logger to the logger provided
 // by this package.
@@ -107,16 +152,31 @@ func NewStdLogger(l Logger) *log.Logger {
 }
 
 // SetOutput sets the default loggers to write to w. If w is nil, the default
-// loggers are disabled.
+// loggers are disabled. If w is a [Syslog] output, the level of every message
+// is sent as its severity and the log format is ignored.
 func SetOutput(w io.Writer) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if w == nil {
-		state.defaultLogger = nil
-	} else {
-		state.defaultLogger = newDefaultLogger(w)
+	state.output = w
+	state.defaultLogger = newLogger(w, state.format)
+}
+
+// SetFormat sets the format of the default loggers. Valid formats are
+// [TextFormat] and [PrettyFormat].
+func SetFormat(format string) error {
+	switch format {
+	case TextFormat, PrettyFormat:
+	default:
+		return fmt.Errorf("invalid log format %q", format)
 	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	state.format = format
+	state.defaultLogger = newLogger(state.output, format)
+	return nil
 }
 
 type logger struct {
@@ -132,8 +192,8 @@ func (l *logger) Printf(format string, v ...any) {
 	if l.level < g.currentLevel {
 		return // Don't log at lower levels.
 	}
-	if g.defaultLogger != nil {
-		g.defaultLogger.Printf(format, v...)
+	if dl := g.loggerAt(l.level); dl != nil {
+		dl.Printf(format, v...)
 	}
 }
 
@@ -144,8 +204,8 @@ func (l *logger) Print(v ...any) {
 	if l.level < g.currentLevel {
 		return // Don't log at lower levels.
 	}
-	if g.defaultLogger != nil {
-		g.defaultLogger.Print(v...)
+	if dl := g.loggerAt(l.level); dl != nil {
+		dl.Print(v...)
 	}
 }
 
@@ -156,8 +216,8 @@ func (l *logger) Println(v ...any) {
 	if l.level < g.currentLevel {
 		return // Don't log at lower levels.
 	}
-	if g.defaultLogger != nil {
-		g.defaultLogger.Println(v...)
+	if dl := g.loggerAt(l.level); dl != nil {
+		dl.Println(v...)
 	}
 }
 
@@ -166,8 +226,8 @@ func (l *logger) Println(v ...any) {
 func (l *logger) Fatal(v ...any) {
 	g := globals()
 
-	if g.defaultLogger != nil {
-		g.defaultLogger.Fatal(v...)
+	if dl := g.loggerAt(l.level); dl != nil {
+		dl.Fatal(v...)
 	} else {
 		log.Fatal(v...)
 	}
@@ -178,8 +238,8 @@ func (l *logger) Fatal(v ...any) {
 func (l *logger) Fatalf(format string, v ...any) {
 	g := globals()
 
-	if g.defaultLogger != nil {
-		g.defaultLogger.Fatalf(format, v...)
+	if dl := g.loggerAt(l.level); dl != nil {
+		dl.Fatalf(format, v...)
 	} else {
 		log.Fatalf(format, v...)
 	}
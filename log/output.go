@@ -0,0 +1,204 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// levelWriter is implemented by the outputs that record the level of every
+// message, like syslog. The default loggers write the messages of every
+// level to the writer returned by writerAt, without timestamps, regardless
+// of the log format.
+type levelWriter interface {
+	writerAt(level Level) io.Writer
+}
+
+// levelOutputLogger is the default logger used with a [levelWriter].
+type levelOutputLogger struct {
+	loggers [DisabledLevel]*log.Logger
+}
+
+var _ Logger = (*levelOutputLogger)(nil)
+
+// newLevelOutputLogger returns a [levelOutputLogger] that writes to lw.
+func newLevelOutputLogger(lw levelWriter) *levelOutputLogger {
+	var l levelOutputLogger
+	for level := DebugLevel; level < DisabledLevel; level++ {
+		l.loggers[level] = log.New(lw.writerAt(level), "", 0)
+	}
+	return &l
+}
+
+func (l *levelOutputLogger) atLevel(level Level) Logger {
+	if level >= DisabledLevel {
+		level = ErrorLevel
+	}
+	return l.loggers[level]
+}
+
+// Printf writes a formatted message with info level.
+func (l *levelOutputLogger) Printf(format string, v ...any) {
+	l.loggers[InfoLevel].Printf(format, v...)
+}
+
+// Print writes a message with info level.
+func (l *levelOutputLogger) Print(v ...any) {
+	l.loggers[InfoLevel].Print(v...)
+}
+
+// Println writes a line with info level.
+func (l *levelOutputLogger) Println(v ...any) {
+	l.loggers[InfoLevel].Println(v...)
+}
+
+// Fatal writes a message with error level and aborts.
+func (l *levelOutputLogger) Fatal(v ...any) {
+	l.loggers[ErrorLevel].Fatal(v...)
+}
+
+// Fatalf writes a formatted message with error level and aborts.
+func (l *levelOutputLogger) Fatalf(format string, v ...any) {
+	l.loggers[ErrorLevel].Fatalf(format, v...)
+}
+
+// writerFunc is an [io.Writer] that writes using a function.
+type writerFunc func(b []byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
+
+// backupTimeFormat is the format of the timestamp appended to the name of
+// the rotated log files.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotatingFile is an [io.WriteCloser] that writes to a file and rotates it
+// when it exceeds a maximum size or age. Rotated files are renamed appending
+// the rotation time in UTC to their name. It is safe for concurrent use.
+type RotatingFile struct {
+	name       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewRotatingFile returns a [RotatingFile] that writes to the file with the
+// provided name. If the file exists, new messages are appended. The file is
+// rotated before writing a message that would make it larger than maxSize
+// bytes and when it is older than maxAge. A zero maxSize or maxAge disables
+// the corresponding rotation. At most maxBackups rotated files are kept. If
+// maxBackups is zero, all of them are kept.
+func NewRotatingFile(name string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		name:       name,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		now:        time.Now,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Write writes b to the file, rotating it if needed.
+func (rf *RotatingFile) Write(b []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	tooLarge := rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(b)) > rf.maxSize
+	tooOld := rf.maxAge > 0 && rf.now().Sub(rf.openedAt) >= rf.maxAge
+	if tooLarge || tooOld {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(b)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Close()
+}
+
+// open opens the log file.
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not stat log file: %w", err)
+	}
+
+	rf.f = f
+	rf.size = fi.Size()
+	rf.openedAt = rf.now()
+	return nil
+}
+
+// rotate renames the current log file, opens a new one and removes the
+// oldest rotated files.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return fmt.Errorf("could not close log file: %w", err)
+	}
+
+	backup := rf.name + "." + rf.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(rf.name, backup); err != nil {
+		return fmt.Errorf("could not rename log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	return rf.removeBackups()
+}
+
+// removeBackups removes the oldest rotated files, so at most rf.maxBackups
+// are kept.
+func (rf *RotatingFile) removeBackups() error {
+	if rf.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(rf.name + ".*")
+	if err != nil {
+		return fmt.Errorf("could not list rotated log files: %w", err)
+	}
+	if len(backups) <= rf.maxBackups {
+		return nil
+	}
+
+	// The timestamp format sorts chronologically.
+	sort.Strings(backups)
+	for _, b := range backups[:len(backups)-rf.maxBackups] {
+		if err := os.Remove(b); err != nil {
+			return fmt.Errorf("could not remove rotated log file: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// readBackups returns the contents of the rotated files of the log file
+// with the provided name, from oldest to newest.
+func readBackups(t *testing.T, name string) []string {
+	t.Helper()
+
+	backups, err := filepath.Glob(name + ".*")
+	if err != nil {
+		t.Fatalf("error listing backups: %v", err)
+	}
+	sort.Strings(backups)
+
+	var contents []string
+	for _, b := range backups {
+		data, err := os.ReadFile(b)
+		if err != nil {
+			t.Fatalf("error reading backup: %v", err)
+		}
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "graph-vulcan-assets.log")
+
+	rf, err := NewRotatingFile(name, 10, 0, 2)
+	if err != nil {
+		t.Fatalf("error creating rotating file: %v", err)
+	}
+	defer rf.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, msg := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n"} {
+		if _, err := rf.Write([]byte(msg)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("error reading log file: %v", err)
+	}
+	if string(data) != "line-4\n" {
+		t.Errorf("unexpected log file contents: %q", data)
+	}
+
+	want := []string{"line-2\n", "line-3\n"}
+	if diff := cmp.Diff(want, readBackups(t, name)); diff != "" {
+		t.Errorf("backups mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestRotatingFileAge(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "graph-vulcan-assets.log")
+	if err := os.WriteFile(name, []byte("previous\n"), 0o644); err != nil {
+		t.Fatalf("error writing log file: %v", err)
+	}
+
+	rf, err := NewRotatingFile(name, 0, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("error creating rotating file: %v", err)
+	}
+	defer rf.Close()
+
+	now := rf.openedAt
+	rf.now = func() time.Time { return now }
+
+	rf.Write([]byte("line-1\n"))
+	now = now.Add(time.Hour)
+	rf.Write([]byte("line-2\n"))
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("error reading log file: %v", err)
+	}
+	if string(data) != "line-2\n" {
+		t.Errorf("unexpected log file contents: %q", data)
+	}
+
+	want := []string{"previous\nline-1\n"}
+	if diff := cmp.Diff(want, readBackups(t, name)); diff != "" {
+		t.Errorf("backups mismatch (-want +got):\n%v", diff)
+	}
+}
+
+// mockLevelWriter is a [levelWriter] that records the messages of every
+// level.
+type mockLevelWriter struct {
+	bufs [DisabledLevel]bytes.Buffer
+}
+
+func (w *mockLevelWriter) Write(b []byte) (int, error) {
+	return w.bufs[InfoLevel].Write(b)
+}
+
+func (w *mockLevelWriter) writerAt(level Level) io.Writer {
+	return &w.bufs[level]
+}
+
+func TestSetOutputLevelWriter(t *testing.T) {
+	var w mockLevelWriter
+	SetOutput(&w)
+	defer SetOutput(os.Stderr)
+
+	mustSetLevel("debug")
+	defer mustSetLevel("info")
+
+	Debug.Print("debug message")
+	Info.Print("info message")
+	Error.Print("error message")
+
+	want := [DisabledLevel]string{"debug message\n", "info message\n", "error message\n"}
+	var got [DisabledLevel]string
+	for i := range w.bufs {
+		got[i] = w.bufs[i].String()
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ANSI escape codes used by the pretty format.
+const (
+	colorReset = "\x1b[0m"
+	colorGray  = "\x1b[90m"
+	colorCyan  = "\x1b[36m"
+	colorRed   = "\x1b[31m"
+)
+
+// prettyTimeFormat is the format of the timestamps of the pretty format.
+const prettyTimeFormat = "15:04:05.000"
+
+// prettyLogger is the default logger used with [PrettyFormat]. It writes
+// every message prefixed with a short local timestamp and the level of the
+// message. Levels are aligned and, unless the environment variable NO_COLOR
+// is set, colored.
+type prettyLogger struct {
+	loggers [DisabledLevel]*log.Logger
+}
+
+var _ Logger = (*prettyLogger)(nil)
+
+// newPrettyLogger returns a [prettyLogger] that writes to w.
+func newPrettyLogger(w io.Writer) *prettyLogger {
+	_, noColor := os.LookupEnv("NO_COLOR")
+
+	// The loggers of every level share the same mutex, so lines written
+	// concurrently are not interleaved.
+	mu := &sync.Mutex{}
+
+	var pl prettyLogger
+	for level := DebugLevel; level < DisabledLevel; level++ {
+		pw := &prettyWriter{
+			w:     w,
+			mu:    mu,
+			label: prettyLabel(level, !noColor),
+			now:   time.Now,
+		}
+		pl.loggers[level] = log.New(pw, "", 0)
+	}
+	return &pl
+}
+
+// prettyLabel returns the aligned label of the provided level. If color is
+// true, the label is colored using ANSI escape codes.
+func prettyLabel(level Level, color bool) string {
+	var label, code string
+	switch level {
+	case DebugLevel:
+		label, code = "DEBUG", colorGray
+	case InfoLevel:
+		label, code = "INFO ", colorCyan
+	case ErrorLevel:
+		label, code = "ERROR", colorRed
+	}
+
+	if !color {
+		return label
+	}
+	return code + label + colorReset
+}
+
+func (pl *prettyLogger) atLevel(level Level) Logger {
+	if level >= DisabledLevel {
+		level = ErrorLevel
+	}
+	return pl.loggers[level]
+}
+
+// Printf writes a formatted message with info level.
+func (pl *prettyLogger) Printf(format string, v ...any) {
+	pl.loggers[InfoLevel].Printf(format, v...)
+}
+
+// Print writes a message with info level.
+func (pl *prettyLogger) Print(v ...any) {
+	pl.loggers[InfoLevel].Print(v...)
+}
+
+// Println writes a line with info level.
+func (pl *prettyLogger) Println(v ...any) {
+	pl.loggers[InfoLevel].Println(v...)
+}
+
+// Fatal writes a message with error level and aborts.
+func (pl *prettyLogger) Fatal(v ...any) {
+	pl.loggers[ErrorLevel].Fatal(v...)
+}
+
+// Fatalf writes a formatted message with error level and aborts.
+func (pl *prettyLogger) Fatalf(format string, v ...any) {
+	pl.loggers[ErrorLevel].Fatalf(format, v...)
+}
+
+// prettyWriter prefixes every message written by a [log.Logger] with the
+// timestamp and the level label.
+type prettyWriter struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	label string
+	now   func() time.Time
+}
+
+func (pw *prettyWriter) Write(b []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	line := make([]byte, 0, len(prettyTimeFormat)+len(pw.label)+len(b)+2)
+	line = pw.now().AppendFormat(line, prettyTimeFormat)
+	line = append(line, ' ')
+	line = append(line, pw.label...)
+	line = append(line, ' ')
+	line = append(line, b...)
+
+	if _, err := pw.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
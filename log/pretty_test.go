@@ -0,0 +1,70 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestPrettyFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		noColor bool
+		want    string
+	}{
+		{
+			name:    "no color",
+			noColor: true,
+			want: `^\d\d:\d\d:\d\d\.\d{3} DEBUG debug message
+\d\d:\d\d:\d\d\.\d{3} INFO  info message
+\d\d:\d\d:\d\d\.\d{3} ERROR error message
+$`,
+		},
+		{
+			name:    "color",
+			noColor: false,
+			want: `^\d\d:\d\d:\d\d\.\d{3} \x1b\[90mDEBUG\x1b\[0m debug message
+\d\d:\d\d:\d\d\.\d{3} \x1b\[36mINFO \x1b\[0m info message
+\d\d:\d\d:\d\d\.\d{3} \x1b\[31mERROR\x1b\[0m error message
+$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noColor {
+				t.Setenv("NO_COLOR", "1")
+			} else {
+				t.Setenv("NO_COLOR", "")
+				os.Unsetenv("NO_COLOR")
+			}
+
+			var buf bytes.Buffer
+			SetOutput(&buf)
+			defer SetOutput(os.Stderr)
+
+			if err := SetFormat(PrettyFormat); err != nil {
+				t.Fatalf("error setting format: %v", err)
+			}
+			defer SetFormat(TextFormat)
+
+			mustSetLevel("debug")
+			defer mustSetLevel("info")
+
+			Debug.Println("debug message")
+			Info.Printf("%v message", "info")
+			Error.Print("error message")
+
+			if !regexp.MustCompile(tt.want).Match(buf.Bytes()) {
+				t.Errorf("unexpected output:\n%s", buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestSetFormatInvalid(t *testing.T) {
+	if err := SetFormat("json"); err == nil {
+		t.Errorf("expected error")
+	}
+}
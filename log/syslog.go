@@ -0,0 +1,55 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+// Syslog is an output that sends the messages to a syslog server. The
+// severity of the messages corresponds to their level.
+type Syslog struct {
+	w *syslog.Writer
+}
+
+// NewSyslog returns a [Syslog] output connected to the syslog server with
+// the provided address. The messages are tagged with tag. If network is
+// empty, it connects to the local syslog server.
+func NewSyslog(network, raddr, tag string) (*Syslog, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to syslog: %w", err)
+	}
+	return &Syslog{w: w}, nil
+}
+
+// Write sends b to syslog with informational severity.
+func (s *Syslog) Write(b []byte) (int, error) {
+	return s.w.Write(b)
+}
+
+// Close closes the connection to syslog.
+func (s *Syslog) Close() error {
+	return s.w.Close()
+}
+
+func (s *Syslog) writerAt(level Level) io.Writer {
+	var write func(m string) error
+	switch level {
+	case DebugLevel:
+		write = s.w.Debug
+	case InfoLevel:
+		write = s.w.Info
+	default:
+		write = s.w.Err
+	}
+
+	return writerFunc(func(b []byte) (int, error) {
+		if err := write(string(b)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	})
+}
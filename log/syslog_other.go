@@ -0,0 +1,32 @@
+//go:build windows || plan9
+
+package log
+
+import (
+	"errors"
+	"io"
+)
+
+// Syslog is an output that sends the messages to a syslog server. It is not
+// supported on this platform.
+type Syslog struct{}
+
+// NewSyslog returns an error because syslog is not supported on this
+// platform.
+func NewSyslog(network, raddr, tag string) (*Syslog, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
+
+// Write implements [io.Writer].
+func (s *Syslog) Write(b []byte) (int, error) {
+	return 0, errors.New("syslog is not supported on this platform")
+}
+
+// Close implements [io.Closer].
+func (s *Syslog) Close() error {
+	return nil
+}
+
+func (s *Syslog) writerAt(level Level) io.Writer {
+	return s
+}
@@ -0,0 +1,54 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	defer conn.Close()
+
+	s, err := NewSyslog("udp", conn.LocalAddr().String(), "graph-vulcan-assets")
+	if err != nil {
+		t.Fatalf("error creating syslog output: %v", err)
+	}
+	defer s.Close()
+
+	SetOutput(s)
+	defer SetOutput(os.Stderr)
+
+	mustSetLevel("debug")
+	defer mustSetLevel("info")
+
+	// Priorities are facility*8 + severity, with facility daemon (3).
+	tests := []struct {
+		log  func(v ...any)
+		want string
+	}{
+		{Debug.Print, "<31>"},
+		{Info.Print, "<30>"},
+		{Error.Print, "<27>"},
+	}
+
+	buf := make([]byte, 1024)
+	for _, tt := range tests {
+		tt.log("message")
+
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("error reading syslog message: %v", err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, tt.want) || !strings.Contains(msg, "graph-vulcan-assets") || !strings.HasSuffix(msg, "message\n") {
+			t.Errorf("unexpected syslog message: %q", msg)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+// Package metrics exports application metrics using the [Prometheus text
+// exposition format].
+//
+// [Prometheus text exposition format]: https://prometheus.io/docs/instrumenting/exposition_formats/
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Counter is a cumulative metric whose value can only increase. A counter
+// can have labels, in which case a different value is tracked for every
+// combination of label values.
+type Counter struct {
+	vec
+}
+
+// A Gauge is a metric whose value can go up and down. Like a [Counter], a
+// gauge can have labels.
+type Gauge struct {
+	vec
+}
+
+// vec contains the values of a metric for every combination of label
+// values.
+type vec struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*series
+
+	// seen contains, for every label, the number of series with
+	// each label value. It is used to cap the number of distinct
+	// values of the labels.
+	seen []map[string]int
+}
+
+// series is the value of a metric for a given combination of label values.
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// metric is implemented by all the metric types.
+type metric interface {
+	metricName() string
+	write(w io.Writer)
+}
+
+// OtherLabelValue is the label value that replaces the values of a label
+// once it reaches the maximum number of distinct values. See [Limits].
+const OtherLabelValue = "__other__"
+
+// Limits restricts the cardinality of the metrics, so large installations
+// do not export an unbounded number of series.
+type Limits struct {
+	// DisabledLabels are the names of the labels that are removed
+	// from all the metrics. The series that only differ in the value
+	// of a disabled label are aggregated.
+	DisabledLabels []string
+
+	// MaxLabelValues is the maximum number of distinct values of a
+	// label of a metric. Once a label reaches it, new values are
+	// replaced by [OtherLabelValue]. Zero means no limit.
+	MaxLabelValues int
+}
+
+// limits contains the limits set with [SetLimits].
+var limits = struct {
+	sync.RWMutex
+	disabled map[string]bool
+	max      int
+}{}
+
+// SetLimits sets the cardinality limits of all the metrics. It must be
+// called before updating the metrics, because the existing series are not
+// modified.
+func SetLimits(l Limits) {
+	limits.Lock()
+	defer limits.Unlock()
+
+	limits.disabled = make(map[string]bool)
+	for _, name := range l.DisabledLabels {
+		limits.disabled[name] = true
+	}
+	limits.max = l.MaxLabelValues
+}
+
+// labelDisabled reports whether the label with the provided name has been
+// disabled with [SetLimits].
+func labelDisabled(name string) bool {
+	limits.RLock()
+	defer limits.RUnlock()
+
+	return limits.disabled[name]
+}
+
+// registry contains all the metrics created with [NewCounter] and
+// [NewGauge].
+var registry = struct {
+	sync.Mutex
+	metrics map[string]metric
+}{metrics: make(map[string]metric)}
+
+// errs contains the errors found registering and updating the metrics. See
+// [Err].
+var errs = struct {
+	sync.Mutex
+	msgs []string
+	seen map[string]bool
+}{seen: make(map[string]bool)}
+
+// errorsTotal counts the invalid registrations and updates of metrics, so
+// they are visible in the exported metrics. It is created in init, because
+// creating a metric can record an error.
+var errorsTotal *Counter
+
+func init() {
+	errorsTotal = NewCounter("metrics_errors_total", "Number of invalid registrations and updates of metrics.")
+}
+
+// recordErr records an invalid registration or update of a metric. Every
+// distinct error is kept once, so the errors of a metric updated in a loop
+// do not grow without bound.
+func recordErr(err error) {
+	errs.Lock()
+	if !errs.seen[err.Error()] {
+		errs.seen[err.Error()] = true
+		errs.msgs = append(errs.msgs, err.Error())
+	}
+	errs.Unlock()
+
+	if errorsTotal != nil {
+		errorsTotal.Inc()
+	}
+}
+
+// Err returns an error describing the invalid registrations and updates of
+// metrics found so far, like metrics with duplicated names or updates with
+// the wrong number of label values. It returns nil if there are none.
+func Err() error {
+	errs.Lock()
+	defer errs.Unlock()
+
+	if len(errs.msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs.msgs, "; "))
+}
+
+// register registers m. If a metric with the same name has already been
+// registered, m is not registered and the error is reported by [Err].
+func register(m metric) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	name := m.metricName()
+	if _, ok := registry.metrics[name]; ok {
+		recordErr(fmt.Errorf("duplicated metric %q", name))
+		return
+	}
+	registry.metrics[name] = m
+}
+
+// newVec returns a vec with the provided name, help text, type and label
+// names.
+func newVec(name, help, typ string, labels []string) vec {
+	return vec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		values: make(map[string]*series),
+		seen:   make([]map[string]int, len(labels)),
+	}
+}
+
+// NewCounter creates and registers a counter with the provided name, help
+// text and label names. If a metric with the same name has already been
+// registered, the counter is not exported and the error is reported by
+// [Err].
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{newVec(name, help, "counter", labels)}
+	register(c)
+	return c
+}
+
+// Inc increments by one the counter corresponding to the provided label
+// values. If the number of label values does not match the number of labels
+// of the counter, the update is discarded and the error is reported by
+// [Err].
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter corresponding to the provided label values. If
+// v is negative or the number of label values does not match the number of
+// labels of the counter, the update is discarded and the error is reported
+// by [Err].
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		recordErr(fmt.Errorf("metric %q: counter cannot decrease", c.name))
+		return
+	}
+	c.update(labelValues, func(s *series) { s.value += v })
+}
+
+// NewGauge creates and registers a gauge with the provided name, help text
+// and label names. If a metric with the same name has already been
+// registered, the gauge is not exported and the error is reported by [Err].
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{newVec(name, help, "gauge", labels)}
+	register(g)
+	return g
+}
+
+// Set sets the gauge corresponding to the provided label values to v. If
+// the number of label values does not match the number of labels of the
+// gauge, the update is discarded and the error is reported by [Err].
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Delete removes the gauge corresponding to the provided label values, so
+// it is not exported anymore.
+func (g *Gauge) Delete(labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := strings.Join(g.limit(labelValues), "\xff")
+	s, ok := g.values[key]
+	if !ok {
+		return
+	}
+	delete(g.values, key)
+	for i, v := range s.labelValues {
+		if g.seen[i][v]--; g.seen[i][v] <= 0 {
+			delete(g.seen[i], v)
+		}
+	}
+}
+
+// update calls f with the series corresponding to the provided label values,
+// creating it if it does not exist. If the number of label values does not
+// match the number of labels of the metric, f is not called and the error is
+// reported by [Err].
+func (m *vec) update(labelValues []string, f func(s *series)) {
+	if len(labelValues) != len(m.labels) {
+		recordErr(fmt.Errorf("metric %q: got %v label values, expected %v", m.name, len(labelValues), len(m.labels)))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labelValues = m.limit(labelValues)
+	key := strings.Join(labelValues, "\xff")
+
+	s, ok := m.values[key]
+	if !ok {
+		s = &series{labelValues: labelValues}
+		m.values[key] = s
+		for i, v := range labelValues {
+			if m.seen[i] == nil {
+				m.seen[i] = make(map[string]int)
+			}
+			m.seen[i][v]++
+		}
+	}
+	f(s)
+}
+
+// limit returns a copy of the provided label values with the limits set
+// with [SetLimits] applied. The values of the disabled labels are empty
+// and the values of the labels that reached the maximum number of distinct
+// values, and are not already in use, are replaced by [OtherLabelValue].
+// The caller must hold m.mu.
+func (m *vec) limit(labelValues []string) []string {
+	limits.RLock()
+	defer limits.RUnlock()
+
+	limited := make([]string, len(labelValues))
+	for i, v := range labelValues {
+		if i < len(m.labels) && limits.disabled[m.labels[i]] {
+			continue
+		}
+		if limits.max > 0 && i < len(m.seen) {
+			if _, ok := m.seen[i][v]; !ok && len(m.seen[i]) >= limits.max {
+				v = OtherLabelValue
+			}
+		}
+		limited[i] = v
+	}
+	return limited
+}
+
+// Value returns the value of the metric corresponding to the provided label
+// values.
+func (m *vec) Value(labelValues ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.Join(m.limit(labelValues), "\xff")
+
+	s, ok := m.values[key]
+	if !ok {
+		return 0
+	}
+	return s.value
+}
+
+func (m *vec) metricName() string {
+	return m.name
+}
+
+// write writes the metric to w using the Prometheus text format.
+func (m *vec) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %v %v\n", m.name, escapeHelp(m.help))
+	fmt.Fprintf(w, "# TYPE %v %v\n", m.name, m.typ)
+
+	keys := make([]string, 0, len(m.values))
+	for k := range m.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := m.values[k]
+		fmt.Fprintf(w, "%v%v %v\n", m.name, formatLabels(m.labels, s.labelValues), strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
+
+// WriteText writes all the registered metrics to w using the Prometheus text
+// format.
+func WriteText(w io.Writer) error {
+	registry.Lock()
+	metrics := make([]metric, 0, len(registry.metrics))
+	for _, m := range registry.metrics {
+		metrics = append(metrics, m)
+	}
+	registry.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].metricName() < metrics[j].metricName()
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an [http.Handler] that serves all the registered metrics
+// using the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+// formatLabels returns the label set formed by the provided names and
+// values. The labels disabled with [SetLimits] are omitted.
+func formatLabels(names, values []string) string {
+	var sb strings.Builder
+	for i, name := range names {
+		if labelDisabled(name) {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteByte('{')
+		} else {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(values[i]))
+		sb.WriteByte('"')
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("metrics_test_counter_total", "Test counter.", "label")
+
+	c.Inc("a")
+	c.Inc("a")
+	c.Add(3, "b")
+
+	if got := c.Value("a"); got != 2 {
+		t.Errorf("unexpected value: want=2, got=%v", got)
+	}
+	if got := c.Value("b"); got != 3 {
+		t.Errorf("unexpected value: want=3, got=%v", got)
+	}
+	if got := c.Value("c"); got != 0 {
+		t.Errorf("unexpected value: want=0, got=%v", got)
+	}
+}
+
+func TestCounterInvalidLabels(t *testing.T) {
+	c := NewCounter("metrics_test_invalid_labels_total", "Test counter.", "label")
+
+	before := errorsTotal.Value()
+
+	c.Inc("a", "b")
+	c.Add(-1, "a")
+
+	if got := c.Value("a"); got != 0 {
+		t.Errorf("unexpected value: want=0, got=%v", got)
+	}
+	if n := errorsTotal.Value() - before; n != 2 {
+		t.Errorf("unexpected number of errors: want=2, got=%v", n)
+	}
+
+	err := Err()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		`metric "metrics_test_invalid_labels_total": got 2 label values, expected 1`,
+		`metric "metrics_test_invalid_labels_total": counter cannot decrease`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing error %q in %q", want, err)
+		}
+	}
+}
+
+func TestNewCounterDuplicated(t *testing.T) {
+	NewCounter("metrics_test_duplicated_total", "Test counter.")
+	g := NewGauge("metrics_test_duplicated_total", "Test gauge.")
+
+	g.Set(1)
+
+	err := Err()
+	if err == nil || !strings.Contains(err.Error(), `duplicated metric "metrics_test_duplicated_total"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "# TYPE metrics_test_duplicated_total gauge") {
+		t.Errorf("duplicated metric exported:\n%v", buf.String())
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGauge("metrics_test_gauge", "Test gauge.", "label")
+
+	g.Set(2, "a")
+	g.Set(1, "a")
+	g.Set(3, "b")
+	g.Delete("b")
+
+	if got := g.Value("a"); got != 1 {
+		t.Errorf("unexpected value: want=1, got=%v", got)
+	}
+	if got := g.Value("b"); got != 0 {
+		t.Errorf("unexpected value: want=0, got=%v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP metrics_test_gauge Test gauge.
+# TYPE metrics_test_gauge gauge
+metrics_test_gauge{label="a"} 1
+`
+	got := buf.String()
+	start := strings.Index(got, "# HELP metrics_test_gauge ")
+	if start < 0 {
+		t.Fatalf("metric not found:\n%v", got)
+	}
+	got = got[start : start+len(want)]
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	c := NewCounter("metrics_test_write_total", "Test\ncounter.", "l0", "l1")
+	c.Inc("b", `"quoted"`)
+	c.Inc("a", "value")
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP metrics_test_write_total Test\ncounter.
+# TYPE metrics_test_write_total counter
+metrics_test_write_total{l0="a",l1="value"} 1
+metrics_test_write_total{l0="b",l1="\"quoted\""} 1
+`
+	got := buf.String()
+	start := strings.Index(got, "# HELP metrics_test_write_total")
+	if start < 0 {
+		t.Fatalf("metric not found:\n%v", got)
+	}
+	got = got[start : start+len(want)]
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	SetLimits(Limits{DisabledLabels: []string{"team"}, MaxLabelValues: 2})
+	defer SetLimits(Limits{})
+
+	c := NewCounter("metrics_test_limits_total", "Test counter.", "asset_type", "team")
+	c.Inc("Hostname", "team-1")
+	c.Inc("Hostname", "team-2")
+	c.Inc("IP", "team-1")
+	c.Inc("DomainName", "team-1")
+	c.Inc("WebAddress", "team-3")
+	c.Inc("IP", "team-2")
+
+	g := NewGauge("metrics_test_limits_gauge", "Test gauge.", "partition")
+	g.Set(1, "0")
+	g.Set(2, "1")
+	g.Delete("1")
+	g.Set(3, "2")
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP metrics_test_limits_gauge Test gauge.
+# TYPE metrics_test_limits_gauge gauge
+metrics_test_limits_gauge{partition="0"} 1
+metrics_test_limits_gauge{partition="2"} 3
+# HELP metrics_test_limits_total Test counter.
+# TYPE metrics_test_limits_total counter
+metrics_test_limits_total{asset_type="Hostname"} 2
+metrics_test_limits_total{asset_type="IP"} 2
+metrics_test_limits_total{asset_type="__other__"} 2
+`
+	got := buf.String()
+	start := strings.Index(got, "# HELP metrics_test_limits_gauge")
+	if start < 0 {
+		t.Fatalf("metric not found:\n%v", got)
+	}
+	got = got[start : start+len(want)]
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%v", diff)
+	}
+
+	if got := c.Value("Hostname", "team-3"); got != 2 {
+		t.Errorf("unexpected value: want=2, got=%v", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package props stores properties of the entities of the Security Graph that
+// are not part of the models of the Asset Inventory API. For instance, the IDs
+// of the Vulcan entities that correspond to every asset and team. The
+// properties are stored using the properties API of the Asset Inventory.
+package props
+
+import (
+	"fmt"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+)
+
+// Keys of the properties that contain the Vulcan IDs.
+const (
+	// VulcanAssetIDKey is the key of the property that contains the
+	// Vulcan ID of an asset.
+	VulcanAssetIDKey = "vulcan_asset_id"
+
+	// VulcanAssetAliasKey is the key of the property that contains the
+	// alias of the asset in Vulcan.
+	VulcanAssetAliasKey = "vulcan_asset_alias"
+
+	// VulcanTeamIDKey is the key of the property that contains the
+	// Vulcan ID of a team.
+	VulcanTeamIDKey = "vulcan_team_id"
+)
+
+// Keys of the properties that contain the state of an asset applied to the
+// Asset Inventory.
+const (
+	// AssetStateFingerprintKey is the key of the property that contains
+	// the fingerprint of the last event applied to an asset.
+	AssetStateFingerprintKey = "asset_state_fingerprint"
+
+	// AssetStateAppliedKey is the key of the property that contains the
+	// time, with the RFC 3339 format, when the last event of an asset was
+	// applied.
+	AssetStateAppliedKey = "asset_state_applied"
+
+	// AssetStateTeamKey is the key of the property that contains the
+	// Asset Inventory ID of the team that owned an asset when its last
+	// event was applied.
+	AssetStateTeamKey = "asset_state_team"
+)
+
+// Keys of the properties that contain the Vulcan attributes of a team that
+// are not supported by the Asset Inventory API.
+const (
+	// VulcanTeamTagKey is the key of the property that contains the
+	// Vulcan tag of a team.
+	VulcanTeamTagKey = "vulcan_team_tag"
+
+	// VulcanTeamDescriptionKey is the key of the property that contains
+	// the Vulcan description of a team.
+	VulcanTeamDescriptionKey = "vulcan_team_description"
+)
+
+// Keys of the properties that contain the origin of an asset.
+const (
+	// DerivedKey is the key of the property that is set to "true" in
+	// the assets created implicitly by the consumer, like the AWS
+	// accounts referenced by the annotations of other assets.
+	DerivedKey = "derived"
+)
+
+// Keys of the properties that contain the provenance of a relation.
+const (
+	// ProvenanceSourceKey is the key of the property that contains the
+	// source of a relation (e.g. "vulcan").
+	ProvenanceSourceKey = "provenance_source"
+
+	// ProvenanceVersionKey is the key of the property that contains the
+	// version of the consumer that created or updated a relation.
+	ProvenanceVersionKey = "provenance_version"
+
+	// ProvenancePositionKey is the key of the property that contains the
+	// position in the stream of the message that created or updated a
+	// relation, with the format "topic/partition@offset".
+	ProvenancePositionKey = "provenance_position"
+)
+
+// Inventory represents the operations of the Graph Asset Inventory used by
+// [Store] to manage the properties of the entities. It is implemented by
+// [inventory.Client].
+type Inventory interface {
+	AssetProperties(assetID string) (inventory.Properties, error)
+	SetAssetProperties(assetID string, props inventory.Properties) (inventory.Properties, error)
+	SetTeamProperties(teamID string, props inventory.Properties) (inventory.Properties, error)
+	SetParentProperties(childID, parentID string, props inventory.Properties) (inventory.Properties, error)
+	SetOwnerProperties(assetID, teamID string, props inventory.Properties) (inventory.Properties, error)
+}
+
+var _ Inventory = inventory.Client{}
+
+// Store stores properties of the entities of a Security Graph using the
+// properties API of its Asset Inventory.
+type Store struct {
+	inv Inventory
+}
+
+// NewStore returns a [Store] that stores the properties in the provided
+// Asset Inventory.
+func NewStore(inv Inventory) Store {
+	return Store{inv: inv}
+}
+
+// SetAsset sets the provided properties of the asset with the given ID. The
+// properties not present in props are not modified and the properties with
+// an empty value are removed.
+func (s Store) SetAsset(id string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	if _, err := s.inv.SetAssetProperties(id, props); err != nil {
+		return fmt.Errorf("asset %v: %w", id, err)
+	}
+	return nil
+}
+
+// Asset returns the properties of the asset with the given ID.
+func (s Store) Asset(id string) (map[string]string, error) {
+	props, err := s.inv.AssetProperties(id)
+	if err != nil {
+		return nil, fmt.Errorf("asset %v: %w", id, err)
+	}
+	return props, nil
+}
+
+// SetTeam is like [Store.SetAsset] but it sets the properties of the team
+// with the given ID.
+func (s Store) SetTeam(id string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	if _, err := s.inv.SetTeamProperties(id, props); err != nil {
+		return fmt.Errorf("team %v: %w", id, err)
+	}
+	return nil
+}
+
+// SetParent is like [Store.SetAsset] but it sets the properties of the
+// "parent of" relation between the provided assets.
+func (s Store) SetParent(childID, parentID string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	if _, err := s.inv.SetParentProperties(childID, parentID, props); err != nil {
+		return fmt.Errorf("parent %v of %v: %w", parentID, childID, err)
+	}
+	return nil
+}
+
+// SetOwner is like [Store.SetAsset] but it sets the properties of the "owns"
+// relation between the provided asset and team.
+func (s Store) SetOwner(assetID, teamID string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	if _, err := s.inv.SetOwnerProperties(assetID, teamID, props); err != nil {
+		return fmt.Errorf("owner %v of %v: %w", teamID, assetID, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package props
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/inventory"
+	"github.com/adevinta/graph-vulcan-assets/inventory/inventorytest"
+)
+
+func TestStoreSetAsset(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	asset, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+
+	store := NewStore(inv)
+
+	if err := store.SetAsset(asset.ID, map[string]string{VulcanAssetIDKey: "vulcan-1"}); err != nil {
+		t.Fatalf("error setting properties: %v", err)
+	}
+	if err := store.SetAsset(asset.ID, map[string]string{VulcanAssetIDKey: "vulcan-2"}); err != nil {
+		t.Fatalf("error setting properties: %v", err)
+	}
+
+	got, err := store.Asset(asset.ID)
+	if err != nil {
+		t.Fatalf("error getting properties: %v", err)
+	}
+	want := map[string]string{VulcanAssetIDKey: "vulcan-2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("properties mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestStoreSetRelations(t *testing.T) {
+	inv := inventorytest.NewInMemory()
+	child, err := inv.CreateAsset("Hostname", "example.com", time.Now(), inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	parent, err := inv.CreateAsset("AWSAccount", "arn:aws:iam::111111111111:root", time.Now(), inventory.Unexpired)
+	if err != nil {
+		t.Fatalf("error creating asset: %v", err)
+	}
+	team, err := inv.CreateTeam("team", "Team")
+	if err != nil {
+		t.Fatalf("error creating team: %v", err)
+	}
+	if _, err := inv.UpsertParent(child.ID, parent.ID, time.Now(), inventory.Unexpired); err != nil {
+		t.Fatalf("error creating parent: %v", err)
+	}
+	if _, err := inv.UpsertOwner(child.ID, team.ID, time.Now(), time.Time{}); err != nil {
+		t.Fatalf("error creating owner: %v", err)
+	}
+
+	store := NewStore(inv)
+
+	want := map[string]string{
+		ProvenanceSourceKey:   "vulcan",
+		ProvenancePositionKey: "assets/0@1",
+	}
+	if err := store.SetParent(child.ID, parent.ID, want); err != nil {
+		t.Fatalf("error setting parent properties: %v", err)
+	}
+	if err := store.SetOwner(child.ID, team.ID, want); err != nil {
+		t.Fatalf("error setting owner properties: %v", err)
+	}
+
+	got, err := inv.ParentProperties(child.ID, parent.ID)
+	if err != nil {
+		t.Fatalf("error getting parent properties: %v", err)
+	}
+	if diff := cmp.Diff(inventory.Properties(want), got); diff != "" {
+		t.Errorf("parent properties mismatch (-want +got):\n%v", diff)
+	}
+
+	got, err = inv.OwnerProperties(child.ID, team.ID)
+	if err != nil {
+		t.Fatalf("error getting owner properties: %v", err)
+	}
+	if diff := cmp.Diff(inventory.Properties(want), got); diff != "" {
+		t.Errorf("owner properties mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestStoreNotFound(t *testing.T) {
+	store := NewStore(inventorytest.NewInMemory())
+
+	p := map[string]string{VulcanAssetIDKey: "vulcan-1"}
+	if err := store.SetAsset("notfound", p); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected asset error: %v", err)
+	}
+	if _, err := store.Asset("notfound"); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected asset error: %v", err)
+	}
+	if err := store.SetTeam("notfound", p); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected team error: %v", err)
+	}
+	if err := store.SetParent("notfound", "notfound", p); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected parent error: %v", err)
+	}
+	if err := store.SetOwner("notfound", "notfound", p); !errors.Is(err, inventory.ErrNotFound) {
+		t.Errorf("unexpected owner error: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package s3 implements a minimal client of the Amazon S3 REST API. It only
+// supports uploading objects, which is enough to publish reports and
+// archives without depending on the AWS SDK. Requests are signed with the
+// AWS Signature Version 4 using static credentials.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrMissingCredentials is returned by [NewClient] when the access key ID or
+// the secret access key are not provided.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// Config is the configuration of a [Client].
+type Config struct {
+	// Region is the AWS region of the buckets.
+	Region string
+
+	// Endpoint is the URL of an S3-compatible service. The buckets
+	// are addressed using the path style. If empty, the Amazon S3
+	// endpoint of Region is used with the virtual-hosted style.
+	Endpoint string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the
+	// credentials used to sign the requests. SessionToken is only
+	// required for temporary credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ConfigFromEnv returns the configuration set in the standard AWS
+// environment variables: AWS_REGION (or AWS_DEFAULT_REGION),
+// AWS_ENDPOINT_URL_S3, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN. If no region is set, "us-east-1" is used.
+func ConfigFromEnv() Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return Config{
+		Region:          region,
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// ParseURL parses a URL with the format s3://bucket/prefix and returns the
+// bucket and the prefix. The prefix can be empty.
+func ParseURL(s string) (bucket, prefix string, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("invalid scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", errors.New("missing bucket")
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// Client is an Amazon S3 client.
+type Client struct {
+	cfg      Config
+	endpoint *url.URL
+	httpcli  *http.Client
+	now      func() time.Time
+}
+
+// NewClient returns a client with the provided configuration.
+func NewClient(cfg Config) (Client, error) {
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return Client{}, ErrMissingCredentials
+	}
+	if cfg.Region == "" {
+		return Client{}, errors.New("missing region")
+	}
+
+	var endpoint *url.URL
+	if cfg.Endpoint != "" {
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return Client{}, fmt.Errorf("invalid endpoint: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return Client{}, fmt.Errorf("invalid endpoint: %v", cfg.Endpoint)
+		}
+		endpoint = u
+	}
+
+	return Client{
+		cfg:      cfg,
+		endpoint: endpoint,
+		httpcli:  &http.Client{Timeout: time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// objectURL returns the URL of the object with the provided key.
+func (cli Client) objectURL(bucket, key string) *url.URL {
+	if cli.endpoint == nil {
+		return &url.URL{
+			Scheme:  "https",
+			Host:    bucket + ".s3." + cli.cfg.Region + ".amazonaws.com",
+			Path:    "/" + key,
+			RawPath: "/" + uriEncode(key, false),
+		}
+	}
+
+	u := *cli.endpoint
+	p := strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/"
+	u.Path = p + key
+	u.RawPath = uriEncode(p, false) + uriEncode(key, false)
+	return &u
+}
+
+// PutObject uploads body to the object with the provided bucket and key.
+func (cli Client) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error {
+	if key == "" {
+		return errors.New("missing key")
+	}
+
+	u := cli.objectURL(bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create HTTP request: %w", err)
+	}
+	req.URL = u
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if cli.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cli.cfg.SessionToken)
+	}
+
+	sum := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	cli.sign(req, cli.now())
+
+	resp, err := cli.httpcli.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %v: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// sign adds the X-Amz-Date and Authorization headers to req according to
+// the AWS Signature Version 4. All the headers of the request and the host
+// are signed. The X-Amz-Content-Sha256 header must be already set.
+func (cli Client) sign(req *http.Request, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + cli.cfg.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+cli.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cli.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		cli.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the canonical query string of q.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes s as required by the AWS Signature Version 4. Every byte
+// is percent-encoded except the unreserved characters and, if encodeSlash is
+// false, the slash.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
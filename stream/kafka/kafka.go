@@ -4,7 +4,11 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -12,15 +16,355 @@ import (
 	"github.com/adevinta/graph-vulcan-assets/stream"
 )
 
+// kafkaTimeout is the timeout of the requests sent to the kafka brokers to
+// retrieve metadata and offsets.
+const kafkaTimeout = 10 * time.Second
+
+// AssignmentStrategy is the strategy used to assign the partitions of a topic
+// to the members of a consumer group.
+type AssignmentStrategy string
+
+// Supported assignment strategies.
+const (
+	// AssignmentRange assigns ranges of consecutive partitions. Every
+	// rebalance revokes all the partitions of all the members.
+	AssignmentRange AssignmentStrategy = "range"
+
+	// AssignmentRoundRobin assigns the partitions in a round-robin
+	// fashion. Every rebalance revokes all the partitions of all the
+	// members.
+	AssignmentRoundRobin AssignmentStrategy = "roundrobin"
+
+	// AssignmentCooperativeSticky assigns the partitions incrementally,
+	// so only the partitions that are moved to other members are revoked
+	// during a rebalance.
+	AssignmentCooperativeSticky AssignmentStrategy = "cooperative-sticky"
+)
+
+// Valid reports whether s is a supported assignment strategy.
+func (s AssignmentStrategy) Valid() bool {
+	switch s {
+	case AssignmentRange, AssignmentRoundRobin, AssignmentCooperativeSticky:
+		return true
+	}
+	return false
+}
+
+// aloOptions are the settings of an [AloProcessor] that can be modified
+// using an [AloOption].
+type aloOptions struct {
+	config    kafka.ConfigMap
+	batching  Batching
+	backoff   Backoff
+	lifecycle Lifecycle
+}
+
+// An AloOption configures an [AloProcessor].
+type AloOption func(opts *aloOptions)
+
+// WithAssignmentStrategy sets the partition assignment strategy of the
+// consumer.
+func WithAssignmentStrategy(s AssignmentStrategy) AloOption {
+	return func(opts *aloOptions) {
+		opts.config["partition.assignment.strategy"] = string(s)
+	}
+}
+
+// Batching controls how an [AloProcessor] groups messages in batches.
+type Batching struct {
+	// Size is the maximum number of messages of a batch. If it is lower
+	// than two, messages are not batched.
+	Size int
+
+	// Interval is the maximum time a message waits in a batch before the
+	// batch is processed.
+	Interval time.Duration
+
+	// Parallelism is the maximum number of messages of a batch processed
+	// concurrently. If it is lower than one, messages are processed
+	// sequentially.
+	Parallelism int
+
+	// Priority, if not nil, returns the priority of a message. The
+	// messages of a batch with the same key are started in decreasing
+	// order of the highest priority of their messages, so messages with
+	// high priority do not wait for the routine messages received
+	// before them. If it is nil, messages are started in the order they
+	// were received.
+	Priority func(msg stream.Message) int
+}
+
+// Backoff controls how an [AloProcessor] retries the errors subscribing to
+// a topic and connecting to the kafka brokers.
+type Backoff struct {
+	// Attempts is the maximum number of consecutive retries. If it is
+	// zero, errors are not retried.
+	Attempts int
+
+	// Initial is the time to wait before the first retry. It is doubled
+	// after every retry.
+	Initial time.Duration
+
+	// Max is the maximum time to wait between retries. If it is zero,
+	// the time is not limited.
+	Max time.Duration
+
+	// Notify, if not nil, is called before every retry with the error,
+	// the number of the attempt and the time to wait.
+	Notify func(err error, attempt int, wait time.Duration)
+}
+
+// wait returns the time to wait before the provided attempt, starting at 1.
+func (b Backoff) wait(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+	if b.Max > 0 && d > b.Max {
+		return b.Max
+	}
+	return d
+}
+
+// WithSubscribeBackoff makes the processor retry with exponential backoff
+// the errors subscribing to the topic and the connectivity errors reported
+// while joining the consumer group or fetching messages. Once the retries
+// are exhausted, [AloProcessor.Process] returns a [SubscriptionError].
+func WithSubscribeBackoff(b Backoff) AloOption {
+	return func(opts *aloOptions) {
+		opts.backoff = b
+	}
+}
+
+// SubscriptionError is returned by [AloProcessor.Process] when it cannot
+// subscribe to the topic or connect to the kafka brokers. It allows to
+// distinguish these errors from the errors returned by the message handler.
+type SubscriptionError struct {
+	Err error
+}
+
+func (e SubscriptionError) Error() string {
+	return fmt.Sprintf("subscription error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e SubscriptionError) Unwrap() error {
+	return e.Err
+}
+
+// WithBatching makes the processor group messages in batches. The messages
+// of a batch with the same key are processed sequentially in the order they
+// were received. Messages with different keys are processed concurrently.
+// The offsets of a batch are stored only after all its messages have been
+// processed successfully, so the at-least-once semantics are kept.
+func WithBatching(b Batching) AloOption {
+	return func(opts *aloOptions) {
+		opts.batching = b
+	}
+}
+
+// Lifecycle contains the callbacks called by an [AloProcessor] when the
+// state of its processing loop changes. So, the users of the processor can
+// drive readiness checks, metrics and logs without inferring the state of
+// the processor. The callbacks are called synchronously from the goroutine
+// running [AloProcessor.Process], so they must not block. Nil callbacks are
+// ignored.
+type Lifecycle struct {
+	// OnSubscribe is called after subscribing to a topic.
+	OnSubscribe func(topic string)
+
+	// OnAssign is called when partitions are assigned to the consumer.
+	OnAssign func(partitions []int32)
+
+	// OnRevoke is called when partitions are revoked from the consumer.
+	// lost reports whether the assignment was lost (e.g. the session
+	// timed out) instead of revoked by a rebalance.
+	OnRevoke func(partitions []int32, lost bool)
+
+	// OnFirstMessage is called when the first message is received after
+	// subscribing to a topic.
+	OnFirstMessage func(pos stream.Position)
+
+	// OnError is called with the error returned by
+	// [AloProcessor.Process].
+	OnError func(err error)
+
+	// OnClose is called after closing the processor.
+	OnClose func()
+
+	// OnEvent is called with the events reported by the kafka client,
+	// like broker transport failures or authentication errors. It
+	// allows to distinguish the errors of the kafka cluster from the
+	// errors of the message handler. If it is set, the end of partition
+	// events are enabled, unless the enable.partition.eof configuration
+	// property is set explicitly.
+	OnEvent func(ev Event)
+}
+
+// EventKind is the kind of an [Event].
+type EventKind string
+
+// Kinds of the events reported by [Lifecycle.OnEvent].
+const (
+	// EventTransport is reported when the connection with the kafka
+	// brokers fails. The kafka client reconnects automatically.
+	EventTransport EventKind = "transport"
+
+	// EventAuth is reported when the consumer cannot authenticate with
+	// the kafka brokers or it is not authorized to consume.
+	EventAuth EventKind = "auth"
+
+	// EventPartitionEOF is reported when the consumer reaches the end of
+	// a partition.
+	EventPartitionEOF EventKind = "partition_eof"
+
+	// EventFatal is reported when the kafka client fails with a fatal
+	// error. The processor cannot be used anymore.
+	EventFatal EventKind = "fatal"
+
+	// EventError is reported for the rest of errors of the kafka client.
+	EventError EventKind = "error"
+)
+
+// Event is an event reported by the kafka client.
+type Event struct {
+	Kind EventKind
+
+	// Err is the error reported by the kafka client. It is nil for
+	// [EventPartitionEOF] events.
+	Err error
+
+	// Position is the position of the end of the partition for
+	// [EventPartitionEOF] events.
+	Position stream.Position
+}
+
+// errorEvent returns the [Event] corresponding to kerr.
+func errorEvent(kerr kafka.Error) Event {
+	ev := Event{Kind: EventError, Err: kerr}
+	switch {
+	case kerr.IsFatal():
+		ev.Kind = EventFatal
+	case isConnectivityError(kerr):
+		ev.Kind = EventTransport
+	default:
+		switch kerr.Code() {
+		case kafka.ErrAuthentication, kafka.ErrSaslAuthenticationFailed,
+			kafka.ErrTopicAuthorizationFailed, kafka.ErrGroupAuthorizationFailed,
+			kafka.ErrClusterAuthorizationFailed:
+			ev.Kind = EventAuth
+		}
+	}
+	return ev
+}
+
+// partitionEOFEvent returns the [Event] corresponding to eof.
+func partitionEOFEvent(eof kafka.PartitionEOF) Event {
+	ev := Event{
+		Kind: EventPartitionEOF,
+		Position: stream.Position{
+			Partition: eof.Partition,
+			Offset:    int64(eof.Offset),
+		},
+	}
+	if eof.Topic != nil {
+		ev.Position.Topic = *eof.Topic
+	}
+	return ev
+}
+
+// WithLifecycle sets the callbacks called by the processor when the state of
+// its processing loop changes.
+func WithLifecycle(l Lifecycle) AloOption {
+	return func(opts *aloOptions) {
+		opts.lifecycle = l
+	}
+}
+
+func (l Lifecycle) subscribed(topic string) {
+	if l.OnSubscribe != nil {
+		l.OnSubscribe(topic)
+	}
+}
+
+func (l Lifecycle) assigned(parts []kafka.TopicPartition) {
+	if l.OnAssign != nil {
+		l.OnAssign(partitionIDs(parts))
+	}
+}
+
+func (l Lifecycle) revoked(parts []kafka.TopicPartition, lost bool) {
+	if l.OnRevoke != nil {
+		l.OnRevoke(partitionIDs(parts), lost)
+	}
+}
+
+func (l Lifecycle) firstMessage(pos stream.Position) {
+	if l.OnFirstMessage != nil {
+		l.OnFirstMessage(pos)
+	}
+}
+
+func (l Lifecycle) failed(err error) {
+	if l.OnError != nil {
+		l.OnError(err)
+	}
+}
+
+func (l Lifecycle) closed() {
+	if l.OnClose != nil {
+		l.OnClose()
+	}
+}
+
+func (l Lifecycle) event(ev Event) {
+	if l.OnEvent != nil {
+		l.OnEvent(ev)
+	}
+}
+
+// partitionIDs returns the IDs of the provided partitions.
+func partitionIDs(parts []kafka.TopicPartition) []int32 {
+	ids := make([]int32, len(parts))
+	for i, p := range parts {
+		ids[i] = p.Partition
+	}
+	return ids
+}
+
+// ErrConcurrentProcess is returned by [AloProcessor.Process] when it is
+// called while another call is in progress. Use a [ProcessorPool] to
+// process several topics concurrently.
+var ErrConcurrentProcess = errors.New("concurrent call to Process")
+
 // An AloProcessor allows to process messages from a kafka topic ensuring
 // at-least-once semantics.
 type AloProcessor struct {
-	c *kafka.Consumer
+	c          *kafka.Consumer
+	groupID    string
+	running    *atomic.Bool
+	paused     *atomic.Bool
+	processed  *offsetTracker
+	rebalances *atomic.Int64
+	batch      *batch
+	backoff    Backoff
+	lifecycle  Lifecycle
+}
+
+// LibraryVersion returns the version of librdkafka used by the kafka
+// client (e.g. "2.3.0").
+func LibraryVersion() string {
+	_, v := kafka.LibraryVersion()
+	return v
 }
 
 // NewAloProcessor returns an [AloProcessor] with the provided kafka
+// configuration properties and options. Options take precedence over the
 // configuration properties.
-func NewAloProcessor(config map[string]any) (AloProcessor, error) {
+func NewAloProcessor(config map[string]any, opts ...AloOption) (AloProcessor, error) {
 	kconfig := make(kafka.ConfigMap)
 	for k, v := range config {
 		if err := kconfig.SetKey(k, v); err != nil {
@@ -28,6 +372,11 @@ func NewAloProcessor(config map[string]any) (AloProcessor, error) {
 		}
 	}
 
+	aopts := aloOptions{config: kconfig}
+	for _, opt := range opts {
+		opt(&aopts)
+	}
+
 	// Ensure at-least-once semantics.
 	//
 	// confluent-kafka-go uses librdkafka under the hood. librdkafka, by
@@ -49,63 +398,778 @@ func NewAloProcessor(config map[string]any) (AloProcessor, error) {
 	kconfig["enable.auto.commit"] = true
 	kconfig["enable.auto.offset.store"] = false
 
+	if aopts.lifecycle.OnEvent != nil {
+		if _, ok := kconfig["enable.partition.eof"]; !ok {
+			kconfig["enable.partition.eof"] = true
+		}
+	}
+
 	c, err := kafka.NewConsumer(&kconfig)
 	if err != nil {
 		return AloProcessor{}, fmt.Errorf("failed to create a consumer: %w", err)
 	}
 
-	return AloProcessor{c}, nil
+	groupID, _ := kconfig["group.id"].(string)
+
+	proc := AloProcessor{
+		c:          c,
+		groupID:    groupID,
+		running:    new(atomic.Bool),
+		paused:     new(atomic.Bool),
+		processed:  newOffsetTracker(),
+		rebalances: new(atomic.Int64),
+		backoff:    aopts.backoff,
+		lifecycle:  aopts.lifecycle,
+	}
+	proc.batch = &batch{cfg: aopts.batching, next: new(atomic.Pointer[Batching])}
+	return proc, nil
 }
 
 // Process processes the messages received in the topic called entity by
 // calling h. This method blocks the calling goroutine until the specified
 // context is cancelled or an error occurs. It replaces the current kafka
-// subscription, so it should not be called concurrently.
+// subscription, so it cannot be called concurrently. If it is, it returns
+// [ErrConcurrentProcess]. If batching is enabled, h is called concurrently
+// and, when the context is cancelled, the messages of the pending batch are
+// left to be processed by the next call.
 func (proc AloProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
-	if err := proc.c.Subscribe(entity, nil); err != nil {
-		return fmt.Errorf("failed to subscribe to topic %w", err)
+	if !proc.running.CompareAndSwap(false, true) {
+		return ErrConcurrentProcess
+	}
+	defer proc.running.Store(false)
+
+	err := proc.process(ctx, entity, h)
+	if err != nil {
+		proc.lifecycle.failed(err)
 	}
+	return err
+}
 
+// process implements [AloProcessor.Process].
+func (proc AloProcessor) process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	if proc.batch != nil {
+		proc.batch.h = h
+	}
+
+	if err := proc.subscribe(ctx, entity); err != nil {
+		return err
+	}
+
+	var (
+		paused   bool
+		attempt  int
+		received bool
+	)
 	for {
 		select {
 		case <-ctx.Done():
+			if err := proc.rewindBatch(); err != nil {
+				return fmt.Errorf("error rewinding partition: %w", err)
+			}
 			return nil
 		default:
 		}
 
-		kmsg, err := proc.c.ReadMessage(100 * time.Millisecond)
+		if err := proc.batchError(); err != nil {
+			return fmt.Errorf("error processing message: %w", err)
+		}
+
+		if err := proc.applyBatching(); err != nil {
+			return fmt.Errorf("error processing message: %w", err)
+		}
+
+		if proc.batch.due(time.Now()) {
+			if err := proc.flushBatch(); err != nil {
+				return fmt.Errorf("error processing message: %w", err)
+			}
+		}
+
+		// Pausing is applied on every iteration, so partitions assigned
+		// after a rebalance are also paused.
+		if proc.paused.Load() {
+			// The pending messages were fetched before pausing.
+			// Rewind, so they are processed after resuming.
+			if err := proc.rewindBatch(); err != nil {
+				return fmt.Errorf("error rewinding partition: %w", err)
+			}
+			if err := proc.pauseAssignment(); err != nil {
+				return fmt.Errorf("error pausing partitions: %w", err)
+			}
+			paused = true
+		} else if paused {
+			if err := proc.resumeAssignment(); err != nil {
+				return fmt.Errorf("error resuming partitions: %w", err)
+			}
+			paused = false
+		}
+
+		kmsg, err := proc.readMessage(100 * time.Millisecond)
 		if err != nil {
 			kerr, ok := err.(kafka.Error)
 			if ok && kerr.Code() == kafka.ErrTimedOut {
 				continue
 			}
+			if ok && isConnectivityError(kerr) {
+				attempt++
+				if err := proc.retryWait(ctx, kerr, attempt); err != nil {
+					return err
+				}
+				continue
+			}
 			return fmt.Errorf("error reading message: %w", kerr)
 		}
+		attempt = 0
 
-		msg := stream.Message{
-			Key:   kmsg.Key,
-			Value: kmsg.Value,
+		if !received {
+			proc.lifecycle.firstMessage(streamMessage(kmsg).Position)
+			received = true
 		}
 
-		for _, hdr := range kmsg.Headers {
-			entry := stream.MetadataEntry{
-				Key:   []byte(hdr.Key),
-				Value: hdr.Value,
+		if paused {
+			// The message was fetched before pausing the partition.
+			// Rewind, so it is processed after resuming.
+			if err := proc.c.Seek(kmsg.TopicPartition, 0); err != nil {
+				return fmt.Errorf("error rewinding partition: %w", err)
 			}
-			msg.Metadata = append(msg.Metadata, entry)
+			continue
 		}
 
+		if proc.batch.enabled() {
+			if proc.batch.add(kmsg, time.Now()) {
+				if err := proc.flushBatch(); err != nil {
+					return fmt.Errorf("error processing message: %w", err)
+				}
+			}
+			continue
+		}
+
+		msg := streamMessage(kmsg)
 		if err := h(msg); err != nil {
-			return fmt.Errorf("error processing message: %w", err)
+			return fmt.Errorf("error processing message: %w", messageError(msg, err))
 		}
 
 		if _, err := proc.c.StoreMessage(kmsg); err != nil {
 			return fmt.Errorf("error storing offset: %w", err)
 		}
+
+		proc.processed.set(kmsg.TopicPartition.Partition, int64(kmsg.TopicPartition.Offset))
+	}
+}
+
+// readMessage reads a message like [kafka.Consumer.ReadMessage], but the
+// errors and the end of partition events of the kafka client are also
+// reported to the lifecycle callbacks of the processor.
+func (proc AloProcessor) readMessage(timeout time.Duration) (*kafka.Message, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		switch ev := proc.c.Poll(int(time.Until(deadline) / time.Millisecond)).(type) {
+		case *kafka.Message:
+			if ev.TopicPartition.Error != nil {
+				return ev, ev.TopicPartition.Error
+			}
+			return ev, nil
+		case kafka.Error:
+			proc.lifecycle.event(errorEvent(ev))
+			return nil, ev
+		case kafka.PartitionEOF:
+			proc.lifecycle.event(partitionEOFEvent(ev))
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, kafka.NewError(kafka.ErrTimedOut, "", false)
+		}
+	}
+}
+
+// ProcessedOffsets returns the offset of the last message successfully
+// processed in every partition. Only the partitions with messages processed
+// since the previous call are returned.
+func (proc AloProcessor) ProcessedOffsets() map[int32]int64 {
+	return proc.processed.flush()
+}
+
+// LowWatermarks returns the first offset available in every partition of the
+// topic called entity.
+func (proc AloProcessor) LowWatermarks(entity string) (map[int32]int64, error) {
+	tmd, err := topicMetadata(proc.c, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	low := make(map[int32]int64)
+	for _, p := range tmd.Partitions {
+		l, _, err := proc.c.QueryWatermarkOffsets(entity, p.ID, int(kafkaTimeout.Milliseconds()))
+		if err != nil {
+			return nil, fmt.Errorf("could not get watermark offsets: %w", err)
+		}
+		low[p.ID] = l
+	}
+	return low, nil
+}
+
+// rebalance is called by the kafka consumer when partitions are assigned or
+// revoked. Before revoking partitions, the stored offsets are committed, so
+// the new owners of the partitions do not reprocess the messages processed
+// since the last automatic commit. Partitions are assigned and revoked by
+// the kafka client using the protocol (eager or cooperative) of the
+// configured assignment strategy.
+func (proc AloProcessor) rebalance(c *kafka.Consumer, ev kafka.Event) error {
+	if ev, ok := ev.(kafka.AssignedPartitions); ok {
+		proc.rebalances.Add(1)
+		proc.lifecycle.assigned(ev.Partitions)
+		return nil
+	}
+	revoked, ok := ev.(kafka.RevokedPartitions)
+	if !ok {
+		return nil
+	}
+
+	// If the assignment has been lost (e.g. the session timed out), the
+	// partitions may already belong to other members, so the offsets
+	// cannot be committed.
+	if c.AssignmentLost() {
+		proc.batch.reset()
+		proc.lifecycle.revoked(revoked.Partitions, true)
+		return nil
+	}
+	defer proc.lifecycle.revoked(revoked.Partitions, false)
+
+	// The pending batch is processed before revoking the partitions, so
+	// its offsets can be committed. Errors are reported by
+	// [AloProcessor.Process].
+	if err := proc.flushBatch(); err != nil {
+		proc.batch.setErr(err)
+		return nil
+	}
+
+	if _, err := c.Commit(); err != nil {
+		kerr, ok := err.(kafka.Error)
+		if ok && kerr.Code() == kafka.ErrNoOffset {
+			return nil
+		}
+		return fmt.Errorf("could not commit offsets: %w", err)
+	}
+	return nil
+}
+
+// subscribe subscribes to the topic called entity. Errors are retried
+// according to the backoff of the processor.
+func (proc AloProcessor) subscribe(ctx context.Context, entity string) error {
+	for attempt := 1; ; attempt++ {
+		err := proc.c.Subscribe(entity, proc.rebalance)
+		if err == nil {
+			proc.lifecycle.subscribed(entity)
+			return nil
+		}
+		if err := proc.retryWait(ctx, err, attempt); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// retryWait waits before retrying the provided attempt after err. It returns
+// a [SubscriptionError] if the retries are exhausted. If ctx is done while
+// waiting, it returns nil.
+func (proc AloProcessor) retryWait(ctx context.Context, err error, attempt int) error {
+	if attempt > proc.backoff.Attempts {
+		return SubscriptionError{Err: err}
+	}
+
+	wait := proc.backoff.wait(attempt)
+	if proc.backoff.Notify != nil {
+		proc.backoff.Notify(err, attempt, wait)
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+	return nil
+}
+
+// isConnectivityError reports whether kerr is a non-fatal error caused by
+// the connection with the kafka brokers. The kafka client recovers from
+// these errors automatically.
+func isConnectivityError(kerr kafka.Error) bool {
+	if kerr.IsFatal() {
+		return false
+	}
+	switch kerr.Code() {
+	case kafka.ErrTransport, kafka.ErrAllBrokersDown, kafka.ErrResolve:
+		return true
+	}
+	return false
+}
+
+// SetPaused pauses or resumes the consumption of messages. While paused, the
+// processor keeps polling the kafka brokers, so it does not leave the
+// consumer group and no rebalance is triggered.
+func (proc AloProcessor) SetPaused(paused bool) {
+	proc.paused.Store(paused)
+}
+
+// Paused reports whether the consumption of messages is paused.
+func (proc AloProcessor) Paused() bool {
+	return proc.paused.Load()
+}
+
+// pauseAssignment pauses all the partitions assigned to the consumer.
+func (proc AloProcessor) pauseAssignment() error {
+	parts, err := proc.c.Assignment()
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return proc.c.Pause(parts)
+}
+
+// resumeAssignment resumes all the partitions assigned to the consumer.
+func (proc AloProcessor) resumeAssignment() error {
+	parts, err := proc.c.Assignment()
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return proc.c.Resume(parts)
+}
+
+// CheckTopic checks that the topic called entity exists and its metadata can
+// be read with the configured credentials.
+func (proc AloProcessor) CheckTopic(entity string) error {
+	_, err := topicMetadata(proc.c, entity)
+	return err
+}
+
+// batch contains the messages received by an [AloProcessor] that are
+// pending to be processed. The methods of a nil batch are no-ops. Except
+// next, which is set by [AloProcessor.SetBatching], it is only accessed
+// from the goroutine running [AloProcessor.Process], including the
+// rebalance callback.
+type batch struct {
+	cfg   Batching
+	next  *atomic.Pointer[Batching]
+	h     stream.MsgHandler
+	msgs  []*kafka.Message
+	start time.Time
+	err   error
+}
+
+// enabled reports whether the messages are processed in batches.
+func (b *batch) enabled() bool {
+	return b != nil && b.cfg.Size > 1
+}
+
+// add appends kmsg to the batch. It reports whether the batch is full.
+func (b *batch) add(kmsg *kafka.Message, now time.Time) bool {
+	if len(b.msgs) == 0 {
+		b.start = now
+	}
+	b.msgs = append(b.msgs, kmsg)
+	return len(b.msgs) >= b.cfg.Size
+}
+
+// due reports whether the oldest message of the batch has been waiting for
+// the configured interval.
+func (b *batch) due(now time.Time) bool {
+	if b == nil || len(b.msgs) == 0 {
+		return false
+	}
+	return now.Sub(b.start) >= b.cfg.Interval
+}
+
+// reset discards the pending messages.
+func (b *batch) reset() {
+	if b == nil {
+		return
+	}
+	b.msgs = nil
+}
+
+// setErr records an error that must be returned by [AloProcessor.Process].
+func (b *batch) setErr(err error) {
+	if b == nil {
+		return
+	}
+	b.err = err
+}
+
+// batchError returns and clears the error recorded in the batch.
+func (proc AloProcessor) batchError() error {
+	if proc.batch == nil {
+		return nil
+	}
+	err := proc.batch.err
+	proc.batch.err = nil
+	return err
+}
+
+// SetBatching replaces the batching configuration of the processor. It can
+// be called concurrently with [AloProcessor.Process], which applies the new
+// configuration after processing the pending batch.
+func (proc AloProcessor) SetBatching(b Batching) {
+	proc.batch.next.Store(&b)
+}
+
+// applyBatching applies the configuration set by
+// [AloProcessor.SetBatching], if any, after processing the pending batch.
+func (proc AloProcessor) applyBatching() error {
+	if proc.batch == nil || proc.batch.next == nil {
+		return nil
+	}
+	next := proc.batch.next.Swap(nil)
+	if next == nil {
+		return nil
+	}
+	if err := proc.flushBatch(); err != nil {
+		return err
+	}
+	proc.batch.cfg = *next
+	return nil
+}
+
+// flushBatch processes the pending batch and stores the offsets of its
+// messages. If any message fails, no offset is stored, so the whole batch is
+// processed again after restarting.
+func (proc AloProcessor) flushBatch() error {
+	if proc.batch == nil || len(proc.batch.msgs) == 0 {
+		return nil
+	}
+
+	msgs := proc.batch.msgs
+	proc.batch.reset()
+
+	if err := processBatch(msgs, proc.batch.cfg.Parallelism, proc.batch.cfg.Priority, proc.batch.h); err != nil {
+		return err
+	}
+
+	for _, kmsg := range msgs {
+		if _, err := proc.c.StoreMessage(kmsg); err != nil {
+			return fmt.Errorf("error storing offset: %w", err)
+		}
+		proc.processed.set(kmsg.TopicPartition.Partition, int64(kmsg.TopicPartition.Offset))
+	}
+	return nil
+}
+
+// rewindBatch discards the pending batch and rewinds the partitions to the
+// first pending message, so the messages are received again.
+func (proc AloProcessor) rewindBatch() error {
+	if proc.batch == nil || len(proc.batch.msgs) == 0 {
+		return nil
+	}
+
+	msgs := proc.batch.msgs
+	proc.batch.reset()
+
+	rewound := make(map[int32]bool)
+	for _, kmsg := range msgs {
+		if rewound[kmsg.TopicPartition.Partition] {
+			continue
+		}
+		if err := proc.c.Seek(kmsg.TopicPartition, 0); err != nil {
+			return err
+		}
+		rewound[kmsg.TopicPartition.Partition] = true
+	}
+	return nil
+}
+
+// processBatch calls h for every message in msgs. The messages with the same
+// key are processed sequentially in order. Messages with different keys are
+// processed concurrently with at most parallelism calls in flight. If
+// priority is not nil, the keys are started in decreasing order of the
+// highest priority of their messages. Otherwise, they are started in the
+// order they were received. It returns the first error found, if any, after
+// all the calls have finished.
+func processBatch(msgs []*kafka.Message, parallelism int, priority func(stream.Message) int, h stream.MsgHandler) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		keys       []string
+		groups     = make(map[string][]*kafka.Message)
+		priorities = make(map[string]int)
+	)
+	for _, kmsg := range msgs {
+		key := string(kmsg.Key)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], kmsg)
+
+		if priority == nil {
+			continue
+		}
+		p := priority(streamMessage(kmsg))
+		if cur, ok := priorities[key]; !ok || p > cur {
+			priorities[key] = p
+		}
+	}
+
+	if priority != nil {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return priorities[keys[i]] > priorities[keys[j]]
+		})
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	sem := make(chan struct{}, parallelism)
+	for _, key := range keys {
+		group := groups[key]
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			for _, kmsg := range group {
+				msg := streamMessage(kmsg)
+				if err := h(msg); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = messageError(msg, err)
+					}
+					mu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// GroupStatus is the state of the consumer group membership of an
+// [AloProcessor].
+type GroupStatus struct {
+	// GroupID is the ID of the consumer group.
+	GroupID string `json:"group_id"`
+
+	// RebalanceProtocol is the rebalance protocol of the consumer group,
+	// "EAGER" or "COOPERATIVE". It is empty if it is not known yet.
+	RebalanceProtocol string `json:"rebalance_protocol"`
+
+	// Rebalances is the number of partition assignments received by the
+	// consumer since it was created. The generation ID of the group is
+	// not exposed by the kafka client, so this counter allows to detect
+	// rebalance loops.
+	Rebalances int64 `json:"rebalances"`
+
+	// Partitions are the partitions assigned to the consumer.
+	Partitions []PartitionStatus `json:"partitions"`
+}
+
+// PartitionStatus is the consumption state of a partition.
+type PartitionStatus struct {
+	// Topic is the name of the topic.
+	Topic string `json:"topic"`
+
+	// Partition is the ID of the partition.
+	Partition int32 `json:"partition"`
+
+	// Committed is the committed offset of the consumer group. It is -1
+	// if no offset has been committed.
+	Committed int64 `json:"committed"`
+
+	// Position is the offset of the next message to be read by the
+	// consumer. It is -1 if no message has been read.
+	Position int64 `json:"position"`
+
+	// HighWatermark is the offset of the next message to be written in
+	// the partition.
+	HighWatermark int64 `json:"high_watermark"`
+
+	// Lag is the number of messages between the committed offset and the
+	// high watermark. If no offset has been committed, it is the number
+	// of messages available in the partition.
+	Lag int64 `json:"lag"`
+
+	// Paused reports whether the consumption of the partition is paused.
+	Paused bool `json:"paused"`
+}
+
+// GroupStatus returns the consumer group membership of the processor: the
+// partitions assigned to it and the lag of every partition. The
+// description of the other members of the group is not available in the
+// kafka client.
+func (proc AloProcessor) GroupStatus() (GroupStatus, error) {
+	parts, err := proc.c.Assignment()
+	if err != nil {
+		return GroupStatus{}, fmt.Errorf("could not get assignment: %w", err)
+	}
+
+	status := GroupStatus{
+		GroupID:           proc.groupID,
+		RebalanceProtocol: proc.c.GetRebalanceProtocol(),
+		Rebalances:        proc.rebalances.Load(),
+		Partitions:        []PartitionStatus{},
+	}
+	if len(parts) == 0 {
+		return status, nil
+	}
+
+	committed, err := proc.c.Committed(parts, int(kafkaTimeout.Milliseconds()))
+	if err != nil {
+		return GroupStatus{}, fmt.Errorf("could not get committed offsets: %w", err)
+	}
+	position, err := proc.c.Position(parts)
+	if err != nil {
+		return GroupStatus{}, fmt.Errorf("could not get positions: %w", err)
+	}
+
+	for i, tp := range committed {
+		var topic string
+		if tp.Topic != nil {
+			topic = *tp.Topic
+		}
+
+		low, high, err := proc.c.QueryWatermarkOffsets(topic, tp.Partition, int(kafkaTimeout.Milliseconds()))
+		if err != nil {
+			return GroupStatus{}, fmt.Errorf("could not get watermark offsets: %w", err)
+		}
+
+		ps := PartitionStatus{
+			Topic:         topic,
+			Partition:     tp.Partition,
+			Committed:     validOffset(tp.Offset),
+			Position:      validOffset(position[i].Offset),
+			HighWatermark: high,
+			Paused:        proc.paused.Load(),
+		}
+		if ps.Committed >= 0 {
+			ps.Lag = high - ps.Committed
+		} else {
+			ps.Lag = high - low
+		}
+		status.Partitions = append(status.Partitions, ps)
+	}
+
+	return status, nil
+}
+
+// validOffset returns off if it is an absolute offset and -1 otherwise.
+func validOffset(off kafka.Offset) int64 {
+	if off < 0 {
+		return -1
+	}
+	return int64(off)
+}
+
+// topicMetadata returns the metadata of the provided topic. It returns error
+// if the topic does not exist, it has no partitions or its metadata cannot be
+// read by c.
+func topicMetadata(c *kafka.Consumer, topic string) (kafka.TopicMetadata, error) {
+	md, err := c.GetMetadata(&topic, false, int(kafkaTimeout.Milliseconds()))
+	if err != nil {
+		return kafka.TopicMetadata{}, fmt.Errorf("could not get metadata: %w", err)
+	}
+
+	tmd, ok := md.Topics[topic]
+	if !ok {
+		return kafka.TopicMetadata{}, fmt.Errorf("unknown topic %q", topic)
+	}
+	if tmd.Error.Code() != kafka.ErrNoError {
+		return kafka.TopicMetadata{}, fmt.Errorf("topic %q: %w", topic, tmd.Error)
+	}
+	if len(tmd.Partitions) == 0 {
+		return kafka.TopicMetadata{}, fmt.Errorf("topic %q has no partitions", topic)
+	}
+
+	return tmd, nil
+}
+
+// offsetTracker keeps track of the last offset processed in every partition
+// since the last flush. It is safe for concurrent use.
+type offsetTracker struct {
+	mu      sync.Mutex
+	offsets map[int32]int64
+}
+
+// newOffsetTracker returns an empty [offsetTracker].
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{offsets: make(map[int32]int64)}
+}
+
+// set records offset as the last processed offset of partition.
+func (t *offsetTracker) set(partition int32, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.offsets[partition] = offset
+}
+
+// flush returns the recorded offsets and resets the tracker.
+func (t *offsetTracker) flush() map[int32]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets := t.offsets
+	t.offsets = make(map[int32]int64)
+	return offsets
+}
+
+// streamMessage converts a kafka message into a [stream.Message].
+func streamMessage(kmsg *kafka.Message) stream.Message {
+	msg := stream.Message{
+		Key:   kmsg.Key,
+		Value: kmsg.Value,
+		Position: stream.Position{
+			Partition: kmsg.TopicPartition.Partition,
+			Offset:    int64(kmsg.TopicPartition.Offset),
+		},
+	}
+	if kmsg.TopicPartition.Topic != nil {
+		msg.Position.Topic = *kmsg.TopicPartition.Topic
+	}
+	if kmsg.TimestampType != kafka.TimestampNotAvailable {
+		msg.Timestamp = kmsg.Timestamp
+	}
+
+	for _, hdr := range kmsg.Headers {
+		entry := stream.MetadataEntry{
+			Key:   []byte(hdr.Key),
+			Value: hdr.Value,
+		}
+		msg.Metadata = append(msg.Metadata, entry)
+	}
+
+	return msg
+}
+
+// messageError returns a [stream.MessageError] that identifies msg.
+func messageError(msg stream.Message, err error) stream.MessageError {
+	return stream.MessageError{
+		Position: msg.Position,
+		Key:      msg.Key,
+		Err:      err,
 	}
 }
 
 // Close closes the underlaying kafka consumer.
 func (proc AloProcessor) Close() error {
-	return proc.c.Close()
+	if err := proc.c.Close(); err != nil {
+		return err
+	}
+	proc.lifecycle.closed()
+	return nil
 }
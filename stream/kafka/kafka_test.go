@@ -5,32 +5,52 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra/containers"
 	"github.com/adevinta/graph-vulcan-assets/stream"
 	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
 )
 
 const (
-	bootstrapServers = "127.0.0.1:9092"
-	groupPrefix      = "stream_kafka_kafka_test_group_"
-	topicPrefix      = "stream_kafka_kafka_test_topic_"
-	messagesFile     = "testdata/messages.json"
-	timeout          = 5 * time.Minute
+	groupPrefix  = "stream_kafka_kafka_test_group_"
+	topicPrefix  = "stream_kafka_kafka_test_topic_"
+	messagesFile = "testdata/messages.json"
+	timeout      = 5 * time.Minute
 )
 
+// ignorePosition ignores the position of the messages, which depends on the
+// state of the topic.
+var ignorePosition = cmpopts.IgnoreFields(stream.Message{}, "Position")
+
+func TestMain(m *testing.M) {
+	os.Exit(containers.Main(m, containers.Kafka))
+}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 func setupKafka(topic string) (msgs []stream.Message, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := testinfra.WaitKafka(ctx); err != nil {
+		return nil, err
+	}
+
 	cfg := &kafka.ConfigMap{
-		"bootstrap.servers": bootstrapServers,
+		"bootstrap.servers": testinfra.KafkaBootstrapServers(),
 
 		// Set message timeout to 5s, so the kafka client returns an
 		// error if the broker is not up.
@@ -96,7 +116,7 @@ func TestAloProcessorProcess(t *testing.T) {
 	}
 
 	cfg := map[string]any{
-		"bootstrap.servers":       bootstrapServers,
+		"bootstrap.servers":       testinfra.KafkaBootstrapServers(),
 		"group.id":                groupPrefix + strconv.FormatInt(rand.Int63(), 16),
 		"auto.commit.interval.ms": 100,
 		"auto.offset.reset":       "earliest",
@@ -128,9 +148,112 @@ func TestAloProcessorProcess(t *testing.T) {
 		t.Fatalf("error processing messages: %v", err)
 	}
 
-	if diff := cmp.Diff(want, got); diff != "" {
+	if diff := cmp.Diff(want, got, ignorePosition); diff != "" {
 		t.Errorf("messages mismatch (-want +got):\n%v", diff)
 	}
+
+	for i, msg := range got {
+		wantPos := stream.Position{Topic: topic, Partition: 0, Offset: int64(i)}
+		if msg.Position != wantPos {
+			t.Errorf("unexpected position of message %v: want=%v got=%v", i, wantPos, msg.Position)
+		}
+	}
+
+	wantOffsets := map[int32]int64{0: int64(len(want) - 1)}
+	if diff := cmp.Diff(wantOffsets, proc.ProcessedOffsets()); diff != "" {
+		t.Errorf("processed offsets mismatch (-want +got):\n%v", diff)
+	}
+
+	wantLow := map[int32]int64{0: 0}
+	low, err := proc.LowWatermarks(topic)
+	if err != nil {
+		t.Fatalf("error getting low watermarks: %v", err)
+	}
+	if diff := cmp.Diff(wantLow, low); diff != "" {
+		t.Errorf("low watermarks mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestAloProcessorLifecycle(t *testing.T) {
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	if _, err := setupKafka(topic); err != nil {
+		t.Fatalf("error setting up kafka: %v", err)
+	}
+
+	cfg := map[string]any{
+		"bootstrap.servers":       testinfra.KafkaBootstrapServers(),
+		"group.id":                groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+		"auto.commit.interval.ms": 100,
+		"auto.offset.reset":       "earliest",
+	}
+
+	var (
+		events   []string
+		assigned []int32
+		first    stream.Position
+	)
+	lc := Lifecycle{
+		OnSubscribe: func(topic string) { events = append(events, "subscribe") },
+		OnAssign: func(partitions []int32) {
+			events = append(events, "assign")
+			assigned = partitions
+		},
+		OnFirstMessage: func(pos stream.Position) {
+			events = append(events, "first_message")
+			first = pos
+		},
+		OnError: func(err error) { events = append(events, "error") },
+		OnClose: func() { events = append(events, "close") },
+	}
+
+	proc, err := NewAloProcessor(cfg, WithLifecycle(lc))
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+
+	errHandler := errors.New("handler error")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		return errHandler
+	})
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := proc.Close(); err != nil {
+		t.Fatalf("error closing processor: %v", err)
+	}
+
+	wantEvents := []string{"subscribe", "assign", "first_message", "error", "close"}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]int32{0}, assigned); diff != "" {
+		t.Errorf("assigned partitions mismatch (-want +got):\n%v", diff)
+	}
+	if want := (stream.Position{Topic: topic, Partition: 0, Offset: 0}); first != want {
+		t.Errorf("unexpected position of the first message: want=%v got=%v", want, first)
+	}
+}
+
+func TestLifecycleNilCallbacks(t *testing.T) {
+	var lc Lifecycle
+	lc.subscribed("topic")
+	lc.assigned([]kafka.TopicPartition{{Partition: 0}})
+	lc.revoked([]kafka.TopicPartition{{Partition: 0}}, false)
+	lc.firstMessage(stream.Position{})
+	lc.failed(errors.New("error"))
+	lc.closed()
+	lc.event(Event{Kind: EventError})
+}
+
+func TestPartitionIDs(t *testing.T) {
+	parts := []kafka.TopicPartition{{Partition: 2}, {Partition: 0}}
+	if diff := cmp.Diff([]int32{2, 0}, partitionIDs(parts)); diff != "" {
+		t.Errorf("partition IDs mismatch (-want +got):\n%v", diff)
+	}
 }
 
 func TestAloProcessorProcessAtLeastOnce(t *testing.T) {
@@ -149,7 +272,7 @@ func TestAloProcessorProcessAtLeastOnce(t *testing.T) {
 	}
 
 	cfg := map[string]any{
-		"bootstrap.servers":       bootstrapServers,
+		"bootstrap.servers":       testinfra.KafkaBootstrapServers(),
 		"group.id":                groupPrefix + strconv.FormatInt(rand.Int63(), 16),
 		"auto.commit.interval.ms": 100,
 		"auto.offset.reset":       "earliest",
@@ -205,7 +328,676 @@ func TestAloProcessorProcessAtLeastOnce(t *testing.T) {
 		t.Fatalf("error processing messages: %v", err)
 	}
 
-	if diff := cmp.Diff(want, got); diff != "" {
+	if diff := cmp.Diff(want, got, ignorePosition); diff != "" {
 		t.Errorf("messages mismatch (-want +got):\n%v", diff)
 	}
 }
+
+func TestAloProcessorProcessCooperativeSticky(t *testing.T) {
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	want, err := setupKafka(topic)
+	if err != nil {
+		t.Fatalf("error setting up kafka: %v", err)
+	}
+
+	cfg := map[string]any{
+		"bootstrap.servers":       testinfra.KafkaBootstrapServers(),
+		"group.id":                groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+		"auto.commit.interval.ms": 100,
+		"auto.offset.reset":       "earliest",
+	}
+
+	proc, err := NewAloProcessor(cfg, WithAssignmentStrategy(AssignmentCooperativeSticky))
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+	defer proc.Close()
+
+	var got []stream.Message
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		got = append(got, msg)
+		if len(got) >= len(want) {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing messages: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, ignorePosition); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestAloProcessorGroupStatus(t *testing.T) {
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	msgs, err := setupKafka(topic)
+	if err != nil {
+		t.Fatalf("error setting up kafka: %v", err)
+	}
+
+	groupID := groupPrefix + strconv.FormatInt(rand.Int63(), 16)
+	cfg := map[string]any{
+		"bootstrap.servers": testinfra.KafkaBootstrapServers(),
+		"group.id":          groupID,
+		"auto.offset.reset": "earliest",
+	}
+
+	proc, err := NewAloProcessor(cfg)
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+	defer proc.Close()
+
+	var (
+		n      int
+		status GroupStatus
+		serr   error
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		n++
+		if n >= len(msgs) {
+			status, serr = proc.GroupStatus()
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing messages: %v", err)
+	}
+	if serr != nil {
+		t.Fatalf("error getting group status: %v", serr)
+	}
+
+	if status.GroupID != groupID {
+		t.Errorf("unexpected group ID: want=%v got=%v", groupID, status.GroupID)
+	}
+	if status.Rebalances != 1 {
+		t.Errorf("unexpected number of rebalances: %v", status.Rebalances)
+	}
+	if len(status.Partitions) == 0 {
+		t.Fatal("no partitions assigned")
+	}
+
+	var high int64
+	for _, ps := range status.Partitions {
+		if ps.Topic != topic {
+			t.Errorf("unexpected topic: want=%v got=%v", topic, ps.Topic)
+		}
+		high += ps.HighWatermark
+	}
+	if high != int64(len(msgs)) {
+		t.Errorf("unexpected high watermarks: want=%v got=%v", len(msgs), high)
+	}
+}
+
+func TestAloProcessorProcessBatching(t *testing.T) {
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	want, err := setupKafka(topic)
+	if err != nil {
+		t.Fatalf("error setting up kafka: %v", err)
+	}
+
+	cfg := map[string]any{
+		"bootstrap.servers":       testinfra.KafkaBootstrapServers(),
+		"group.id":                groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+		"auto.commit.interval.ms": 100,
+		"auto.offset.reset":       "earliest",
+	}
+
+	batching := Batching{Size: 2, Interval: 100 * time.Millisecond, Parallelism: 2}
+	proc, err := NewAloProcessor(cfg, WithBatching(batching))
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+	defer proc.Close()
+
+	var (
+		mu  sync.Mutex
+		got []stream.Message
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		got = append(got, msg)
+		if len(got) >= len(want) {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing messages: %v", err)
+	}
+
+	sortMessages := cmpopts.SortSlices(func(a, b stream.Message) bool {
+		return a.Position.Offset < b.Position.Offset
+	})
+	if diff := cmp.Diff(want, got, ignorePosition, sortMessages); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestAssignmentStrategyValid(t *testing.T) {
+	tests := []struct {
+		strategy AssignmentStrategy
+		want     bool
+	}{
+		{strategy: AssignmentRange, want: true},
+		{strategy: AssignmentRoundRobin, want: true},
+		{strategy: AssignmentCooperativeSticky, want: true},
+		{strategy: "sticky", want: false},
+		{strategy: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			if got := tt.strategy.Valid(); got != tt.want {
+				t.Errorf("unexpected result: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithAssignmentStrategy(t *testing.T) {
+	opts := aloOptions{config: kafka.ConfigMap{"partition.assignment.strategy": "range"}}
+	WithAssignmentStrategy(AssignmentCooperativeSticky)(&opts)
+
+	want := kafka.ConfigMap{"partition.assignment.strategy": "cooperative-sticky"}
+	if diff := cmp.Diff(want, opts.config); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.set(0, 10)
+	tracker.set(1, 5)
+	tracker.set(0, 11)
+
+	want := map[int32]int64{0: 11, 1: 5}
+	if diff := cmp.Diff(want, tracker.flush()); diff != "" {
+		t.Errorf("offsets mismatch (-want +got):\n%v", diff)
+	}
+
+	if diff := cmp.Diff(map[int32]int64{}, tracker.flush()); diff != "" {
+		t.Errorf("offsets after flush mismatch (-want +got):\n%v", diff)
+	}
+
+	tracker.set(1, 6)
+
+	want = map[int32]int64{1: 6}
+	if diff := cmp.Diff(want, tracker.flush()); diff != "" {
+		t.Errorf("offsets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestValidOffset(t *testing.T) {
+	tests := []struct {
+		offset kafka.Offset
+		want   int64
+	}{
+		{offset: 10, want: 10},
+		{offset: 0, want: 0},
+		{offset: kafka.OffsetInvalid, want: -1},
+		{offset: kafka.OffsetEnd, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.offset.String(), func(t *testing.T) {
+			if got := validOffset(tt.offset); got != tt.want {
+				t.Errorf("unexpected offset: want=%v got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamMessage(t *testing.T) {
+	topic := "assets"
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		kmsg *kafka.Message
+		want stream.Message
+	}{
+		{
+			name: "timestamp",
+			kmsg: &kafka.Message{
+				TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 10},
+				Key:            []byte("key"),
+				Value:          []byte("value"),
+				Headers:        []kafka.Header{{Key: "version", Value: []byte("0.0.1")}},
+				Timestamp:      ts,
+				TimestampType:  kafka.TimestampCreateTime,
+			},
+			want: stream.Message{
+				Key:       []byte("key"),
+				Value:     []byte("value"),
+				Metadata:  []stream.MetadataEntry{{Key: []byte("version"), Value: []byte("0.0.1")}},
+				Position:  stream.Position{Topic: "assets", Partition: 1, Offset: 10},
+				Timestamp: ts,
+			},
+		},
+		{
+			name: "timestamp not available",
+			kmsg: &kafka.Message{
+				TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 10},
+				Key:            []byte("key"),
+				Timestamp:      ts,
+				TimestampType:  kafka.TimestampNotAvailable,
+			},
+			want: stream.Message{
+				Key:      []byte("key"),
+				Position: stream.Position{Topic: "assets", Partition: 1, Offset: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := streamMessage(tt.kmsg)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("message mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	topic := "topic"
+	newMessage := func(key string, offset int64) *kafka.Message {
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &topic,
+				Partition: 0,
+				Offset:    kafka.Offset(offset),
+			},
+			Key: []byte(key),
+		}
+	}
+
+	msgs := []*kafka.Message{
+		newMessage("a", 0),
+		newMessage("b", 1),
+		newMessage("a", 2),
+		newMessage("c", 3),
+		newMessage("b", 4),
+		newMessage("a", 5),
+	}
+
+	tests := []struct {
+		name        string
+		parallelism int
+		failOffset  int64
+		want        map[string][]int64
+		wantNilErr  bool
+	}{
+		{
+			name:        "sequential",
+			parallelism: 0,
+			failOffset:  -1,
+			want: map[string][]int64{
+				"a": {0, 2, 5},
+				"b": {1, 4},
+				"c": {3},
+			},
+			wantNilErr: true,
+		},
+		{
+			name:        "concurrent",
+			parallelism: 3,
+			failOffset:  -1,
+			want: map[string][]int64{
+				"a": {0, 2, 5},
+				"b": {1, 4},
+				"c": {3},
+			},
+			wantNilErr: true,
+		},
+		{
+			name:        "error",
+			parallelism: 3,
+			failOffset:  2,
+			want: map[string][]int64{
+				"a": {0, 2},
+				"b": {1, 4},
+				"c": {3},
+			},
+			wantNilErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			got := make(map[string][]int64)
+
+			err := processBatch(msgs, tt.parallelism, nil, func(msg stream.Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				key := string(msg.Key)
+				got[key] = append(got[key], msg.Position.Offset)
+				if msg.Position.Offset == tt.failOffset {
+					return errors.New("error")
+				}
+				return nil
+			})
+
+			if (err == nil) != tt.wantNilErr {
+				t.Errorf("unexpected error: wantNilErr=%v, got=%v", tt.wantNilErr, err)
+			}
+
+			var merr stream.MessageError
+			if err != nil && !errors.As(err, &merr) {
+				t.Errorf("unexpected error type: %T", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("processed offsets mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestProcessBatchPriority(t *testing.T) {
+	topic := "topic"
+	var msgs []*kafka.Message
+	for i, key := range []string{"a", "b", "a", "c", "b", "a"} {
+		msgs = append(msgs, &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &topic,
+				Partition: 0,
+				Offset:    kafka.Offset(i),
+			},
+			Key: []byte(key),
+		})
+	}
+
+	// The priority of a key is the highest priority of its messages.
+	priorities := map[int64]int{3: 2, 4: 1}
+	priority := func(msg stream.Message) int {
+		return priorities[msg.Position.Offset]
+	}
+
+	var got []int64
+	err := processBatch(msgs, 1, priority, func(msg stream.Message) error {
+		got = append(got, msg.Position.Offset)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing batch: %v", err)
+	}
+
+	want := []int64{3, 1, 4, 0, 2, 5}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("processed offsets mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	b := &batch{cfg: Batching{Size: 2, Interval: time.Second}}
+	now := time.Now()
+
+	if b.due(now) {
+		t.Error("empty batch is due")
+	}
+
+	if b.add(&kafka.Message{}, now) {
+		t.Error("batch is full after one message")
+	}
+	if b.due(now.Add(time.Second / 2)) {
+		t.Error("batch is due before the interval")
+	}
+	if !b.due(now.Add(time.Second)) {
+		t.Error("batch is not due after the interval")
+	}
+	if !b.add(&kafka.Message{}, now) {
+		t.Error("batch is not full after two messages")
+	}
+
+	b.reset()
+	if b.due(now.Add(time.Second)) {
+		t.Error("batch is due after reset")
+	}
+
+	var nilBatch *batch
+	if nilBatch.due(now) {
+		t.Error("nil batch is due")
+	}
+	if nilBatch.enabled() {
+		t.Error("nil batch is enabled")
+	}
+}
+
+func TestSetBatching(t *testing.T) {
+	proc := AloProcessor{batch: &batch{next: new(atomic.Pointer[Batching])}}
+	if proc.batch.enabled() {
+		t.Fatal("batching enabled by default")
+	}
+
+	want := Batching{Size: 100, Interval: time.Second, Parallelism: 8}
+	proc.SetBatching(want)
+
+	// The configuration is applied by the processing goroutine.
+	if proc.batch.enabled() {
+		t.Fatal("batching enabled before applying the configuration")
+	}
+	if err := proc.applyBatching(); err != nil {
+		t.Fatalf("error applying batching: %v", err)
+	}
+	if diff := cmp.Diff(want, proc.batch.cfg); diff != "" {
+		t.Errorf("batching mismatch (-want +got):\n%v", diff)
+	}
+	if !proc.batch.enabled() {
+		t.Error("batching not enabled")
+	}
+
+	proc.SetBatching(Batching{})
+	if err := proc.applyBatching(); err != nil {
+		t.Fatalf("error applying batching: %v", err)
+	}
+	if proc.batch.enabled() {
+		t.Error("batching not disabled")
+	}
+}
+
+func TestBackoffWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "first attempt",
+			backoff: Backoff{Initial: time.Second, Max: time.Minute},
+			attempt: 1,
+			want:    time.Second,
+		},
+		{
+			name:    "doubled",
+			backoff: Backoff{Initial: time.Second, Max: time.Minute},
+			attempt: 4,
+			want:    8 * time.Second,
+		},
+		{
+			name:    "capped",
+			backoff: Backoff{Initial: time.Second, Max: time.Minute},
+			attempt: 100,
+			want:    time.Minute,
+		},
+		{
+			name:    "initial above max",
+			backoff: Backoff{Initial: 2 * time.Minute, Max: time.Minute},
+			attempt: 1,
+			want:    time.Minute,
+		},
+		{
+			name:    "no max",
+			backoff: Backoff{Initial: time.Second},
+			attempt: 11,
+			want:    1024 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.backoff.wait(tt.attempt); got != tt.want {
+				t.Errorf("unexpected wait: want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAloProcessorRetryWait(t *testing.T) {
+	var notified []int
+	proc := AloProcessor{
+		backoff: Backoff{
+			Attempts: 2,
+			Initial:  time.Millisecond,
+			Notify: func(err error, attempt int, wait time.Duration) {
+				notified = append(notified, attempt)
+			},
+		},
+	}
+
+	errSubscribe := errors.New("subscribe error")
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := proc.retryWait(context.Background(), errSubscribe, attempt); err != nil {
+			t.Fatalf("unexpected error in attempt %v: %v", attempt, err)
+		}
+	}
+
+	err := proc.retryWait(context.Background(), errSubscribe, 3)
+	var serr SubscriptionError
+	if !errors.As(err, &serr) {
+		t.Fatalf("error is not a SubscriptionError: %v", err)
+	}
+	if !errors.Is(err, errSubscribe) {
+		t.Errorf("error does not wrap the subscribe error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2}, notified); diff != "" {
+		t.Errorf("notified attempts mismatch (-want +got):\n%v", diff)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	proc.backoff.Initial = time.Hour
+	if err := proc.retryWait(ctx, errSubscribe, 1); err != nil {
+		t.Errorf("unexpected error with canceled context: %v", err)
+	}
+}
+
+func TestIsConnectivityError(t *testing.T) {
+	tests := []struct {
+		name string
+		kerr kafka.Error
+		want bool
+	}{
+		{
+			name: "all brokers down",
+			kerr: kafka.NewError(kafka.ErrAllBrokersDown, "all brokers down", false),
+			want: true,
+		},
+		{
+			name: "resolve",
+			kerr: kafka.NewError(kafka.ErrResolve, "resolve", false),
+			want: true,
+		},
+		{
+			name: "fatal transport",
+			kerr: kafka.NewError(kafka.ErrTransport, "transport", true),
+			want: false,
+		},
+		{
+			name: "unknown topic",
+			kerr: kafka.NewError(kafka.ErrUnknownTopicOrPart, "unknown topic", false),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnectivityError(tt.kerr); got != tt.want {
+				t.Errorf("unexpected result: want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestErrorEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		kerr     kafka.Error
+		wantKind EventKind
+	}{
+		{
+			name:     "all brokers down",
+			kerr:     kafka.NewError(kafka.ErrAllBrokersDown, "all brokers down", false),
+			wantKind: EventTransport,
+		},
+		{
+			name:     "transport",
+			kerr:     kafka.NewError(kafka.ErrTransport, "transport", false),
+			wantKind: EventTransport,
+		},
+		{
+			name:     "sasl authentication",
+			kerr:     kafka.NewError(kafka.ErrSaslAuthenticationFailed, "sasl authentication failed", false),
+			wantKind: EventAuth,
+		},
+		{
+			name:     "group authorization",
+			kerr:     kafka.NewError(kafka.ErrGroupAuthorizationFailed, "group authorization failed", false),
+			wantKind: EventAuth,
+		},
+		{
+			name:     "fatal transport",
+			kerr:     kafka.NewError(kafka.ErrTransport, "transport", true),
+			wantKind: EventFatal,
+		},
+		{
+			name:     "unknown topic",
+			kerr:     kafka.NewError(kafka.ErrUnknownTopicOrPart, "unknown topic", false),
+			wantKind: EventError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := errorEvent(tt.kerr)
+			if ev.Kind != tt.wantKind {
+				t.Errorf("unexpected kind: want: %v, got: %v", tt.wantKind, ev.Kind)
+			}
+			if !errors.Is(ev.Err, tt.kerr) {
+				t.Errorf("unexpected error: want: %v, got: %v", tt.kerr, ev.Err)
+			}
+		})
+	}
+}
+
+func TestPartitionEOFEvent(t *testing.T) {
+	topic := "assets"
+	eof := kafka.PartitionEOF{Topic: &topic, Partition: 2, Offset: 10}
+
+	want := Event{
+		Kind:     EventPartitionEOF,
+		Position: stream.Position{Topic: "assets", Partition: 2, Offset: 10},
+	}
+	if diff := cmp.Diff(want, partitionEOFEvent(eof)); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// ErrPoolClosed is returned by the methods of a [ProcessorPool] after it
+// has been closed.
+var ErrPoolClosed = errors.New("processor pool closed")
+
+// A ProcessorPool manages one [AloProcessor] per topic, so several topics
+// can be processed concurrently. The processors are created on demand with
+// the same configuration properties and options, so they share the
+// consumer group. It is safe for concurrent use.
+type ProcessorPool struct {
+	config map[string]any
+	opts   []AloOption
+
+	mu     sync.Mutex
+	procs  map[string]AloProcessor
+	closed bool
+}
+
+// NewProcessorPool returns a [ProcessorPool] whose processors are created
+// with the provided kafka configuration properties and options. See
+// [NewAloProcessor].
+func NewProcessorPool(config map[string]any, opts ...AloOption) *ProcessorPool {
+	return &ProcessorPool{
+		config: config,
+		opts:   opts,
+		procs:  make(map[string]AloProcessor),
+	}
+}
+
+// Processor returns the processor of the topic called entity. It is
+// created if it does not exist.
+func (pool *ProcessorPool) Processor(entity string) (AloProcessor, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.closed {
+		return AloProcessor{}, ErrPoolClosed
+	}
+
+	if proc, ok := pool.procs[entity]; ok {
+		return proc, nil
+	}
+
+	proc, err := NewAloProcessor(pool.config, pool.opts...)
+	if err != nil {
+		return AloProcessor{}, fmt.Errorf("could not create processor for %v: %w", entity, err)
+	}
+	pool.procs[entity] = proc
+	return proc, nil
+}
+
+// Process processes the messages received in the topic called entity by
+// calling h using the processor of the topic. It can be called
+// concurrently for different topics. Concurrent calls for the same topic
+// return [ErrConcurrentProcess]. See [AloProcessor.Process].
+func (pool *ProcessorPool) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	proc, err := pool.Processor(entity)
+	if err != nil {
+		return err
+	}
+	return proc.Process(ctx, entity, h)
+}
+
+// Entities returns the topics with a processor sorted alphabetically.
+func (pool *ProcessorPool) Entities() []string {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var entities []string
+	for entity := range pool.procs {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// Close closes all the processors of the pool. It returns the first error
+// found, after trying to close all of them. The processors must not be
+// processing messages.
+func (pool *ProcessorPool) Close() error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.closed {
+		return nil
+	}
+	pool.closed = true
+
+	var first error
+	for entity, proc := range pool.procs {
+		if err := proc.Close(); err != nil && first == nil {
+			first = fmt.Errorf("could not close processor for %v: %w", entity, err)
+		}
+	}
+	return first
+}
@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestProcessorPool(t *testing.T) {
+	pool := NewProcessorPool(map[string]any{
+		"bootstrap.servers": "127.0.0.1:1",
+		"group.id":          groupPrefix + "pool",
+	})
+
+	assets, err := pool.Processor("assets")
+	if err != nil {
+		t.Fatalf("error getting processor: %v", err)
+	}
+	again, err := pool.Processor("assets")
+	if err != nil {
+		t.Fatalf("error getting processor: %v", err)
+	}
+	if again.c != assets.c {
+		t.Error("different processors for the same topic")
+	}
+
+	findings, err := pool.Processor("findings")
+	if err != nil {
+		t.Fatalf("error getting processor: %v", err)
+	}
+	if findings.c == assets.c {
+		t.Error("same processor for different topics")
+	}
+
+	if diff := cmp.Diff([]string{"assets", "findings"}, pool.Entities()); diff != "" {
+		t.Errorf("entities mismatch (-want +got):\n%v", diff)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("error closing pool: %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Errorf("error closing pool twice: %v", err)
+	}
+
+	if _, err := pool.Processor("assets"); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("unexpected error after closing: want=%v got=%v", ErrPoolClosed, err)
+	}
+	err = pool.Process(context.Background(), "assets", func(msg stream.Message) error { return nil })
+	if !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("unexpected error processing after closing: want=%v got=%v", ErrPoolClosed, err)
+	}
+}
+
+func TestConcurrentProcess(t *testing.T) {
+	proc := AloProcessor{running: new(atomic.Bool)}
+	proc.running.Store(true)
+
+	err := proc.Process(context.Background(), "assets", func(msg stream.Message) error { return nil })
+	if !errors.Is(err, ErrConcurrentProcess) {
+		t.Errorf("unexpected error: want=%v got=%v", ErrConcurrentProcess, err)
+	}
+	if !proc.running.Load() {
+		t.Error("running flag of the in-progress call cleared")
+	}
+}
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// A Producer produces messages to kafka topics. Messages are delivered
+// asynchronously, so [Producer.Flush] must be called to make sure that all
+// the messages have been delivered.
+type Producer struct {
+	p *kafka.Producer
+
+	failed   *atomic.Int64
+	mu       *sync.Mutex
+	firstErr *error
+	done     chan struct{}
+}
+
+// NewProducer returns a [Producer] with the provided kafka configuration
+// properties.
+func NewProducer(config map[string]any) (Producer, error) {
+	kconfig := make(kafka.ConfigMap)
+	for k, v := range config {
+		if err := kconfig.SetKey(k, v); err != nil {
+			return Producer{}, fmt.Errorf("could not set config key: %w", err)
+		}
+	}
+
+	p, err := kafka.NewProducer(&kconfig)
+	if err != nil {
+		return Producer{}, fmt.Errorf("failed to create a producer: %w", err)
+	}
+
+	prod := Producer{
+		p:        p,
+		failed:   new(atomic.Int64),
+		mu:       new(sync.Mutex),
+		firstErr: new(error),
+		done:     make(chan struct{}),
+	}
+	go prod.handleEvents()
+
+	return prod, nil
+}
+
+// handleEvents keeps track of the delivery reports of the producer until it
+// is closed.
+func (prod Producer) handleEvents() {
+	defer close(prod.done)
+
+	for e := range prod.p.Events() {
+		kmsg, ok := e.(*kafka.Message)
+		if !ok || kmsg.TopicPartition.Error == nil {
+			continue
+		}
+
+		prod.failed.Add(1)
+
+		prod.mu.Lock()
+		if *prod.firstErr == nil {
+			*prod.firstErr = kmsg.TopicPartition.Error
+		}
+		prod.mu.Unlock()
+	}
+}
+
+// Produce enqueues msg to be delivered to topic. Metadata entries are sent
+// as kafka headers. Delivery errors are reported by [Producer.Flush].
+func (prod Producer) Produce(topic string, msg stream.Message) error {
+	if err := prod.p.Produce(kafkaMessage(topic, msg), nil); err != nil {
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+	return nil
+}
+
+// ProduceSync delivers msg to topic and waits for its delivery report or
+// until ctx is done. Unlike [Producer.Produce], delivery errors are returned
+// directly and are not reported by [Producer.Flush].
+func (prod Producer) ProduceSync(ctx context.Context, topic string, msg stream.Message) error {
+	// The channel is buffered, so the delivery report does not block the
+	// producer if ctx is done before it is received.
+	delivery := make(chan kafka.Event, 1)
+	if err := prod.p.Produce(kafkaMessage(topic, msg), delivery); err != nil {
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("could not wait for delivery report: %w", ctx.Err())
+	case e := <-delivery:
+		kmsg, ok := e.(*kafka.Message)
+		if !ok {
+			return fmt.Errorf("unexpected delivery event %T", e)
+		}
+		if kmsg.TopicPartition.Error != nil {
+			return fmt.Errorf("could not deliver message: %w", kmsg.TopicPartition.Error)
+		}
+	}
+	return nil
+}
+
+// kafkaMessage converts a [stream.Message] into a kafka message to be
+// delivered to topic.
+func kafkaMessage(topic string, msg stream.Message) *kafka.Message {
+	kmsg := &kafka.Message{
+		Key:            msg.Key,
+		Value:          msg.Value,
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+	}
+
+	for _, e := range msg.Metadata {
+		hdr := kafka.Header{
+			Key:   string(e.Key),
+			Value: e.Value,
+		}
+		kmsg.Headers = append(kmsg.Headers, hdr)
+	}
+
+	return kmsg
+}
+
+// Flush waits until all the enqueued messages have been delivered or ctx is
+// done. It returns an error if any message could not be delivered since the
+// producer was created.
+func (prod Producer) Flush(ctx context.Context) error {
+	for prod.p.Flush(100) > 0 {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("could not flush messages: %w", err)
+		}
+	}
+
+	if n := prod.failed.Load(); n > 0 {
+		prod.mu.Lock()
+		defer prod.mu.Unlock()
+		return fmt.Errorf("%v messages could not be delivered: %w", n, *prod.firstErr)
+	}
+	return nil
+}
+
+// Close closes the underlaying kafka producer. Messages that have not been
+// delivered are discarded.
+func (prod Producer) Close() {
+	prod.p.Close()
+	<-prod.done
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestProducerProduce(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := testinfra.WaitKafka(ctx); err != nil {
+		t.Fatalf("error waiting for kafka: %v", err)
+	}
+
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	prod, err := NewProducer(map[string]any{
+		"bootstrap.servers":  testinfra.KafkaBootstrapServers(),
+		"message.timeout.ms": 5000,
+	})
+	if err != nil {
+		t.Fatalf("error creating producer: %v", err)
+	}
+	defer prod.Close()
+
+	want := streamtest.MustParse(messagesFile)
+	for _, msg := range want {
+		if err := prod.Produce(topic, msg); err != nil {
+			t.Fatalf("error producing message: %v", err)
+		}
+	}
+
+	if err := prod.Flush(ctx); err != nil {
+		t.Fatalf("error flushing messages: %v", err)
+	}
+
+	proc, err := NewReplayProcessor(map[string]any{
+		"bootstrap.servers": testinfra.KafkaBootstrapServers(),
+		"group.id":          groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+	}, Window{FromOffset: 0})
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+	defer proc.Close()
+
+	var got []stream.Message
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		got = append(got, msg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing messages: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, ignorePosition); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestProducerProduceSync(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := testinfra.WaitKafka(ctx); err != nil {
+		t.Fatalf("error waiting for kafka: %v", err)
+	}
+
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	prod, err := NewProducer(map[string]any{
+		"bootstrap.servers":  testinfra.KafkaBootstrapServers(),
+		"message.timeout.ms": 5000,
+	})
+	if err != nil {
+		t.Fatalf("error creating producer: %v", err)
+	}
+	defer prod.Close()
+
+	want := streamtest.MustParse(messagesFile)
+	for _, msg := range want {
+		if err := prod.ProduceSync(ctx, topic, msg); err != nil {
+			t.Fatalf("error producing message: %v", err)
+		}
+	}
+
+	proc, err := NewReplayProcessor(map[string]any{
+		"bootstrap.servers": testinfra.KafkaBootstrapServers(),
+		"group.id":          groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+	}, Window{FromOffset: 0})
+	if err != nil {
+		t.Fatalf("error creating kafka processor: %v", err)
+	}
+	defer proc.Close()
+
+	var got []stream.Message
+	err = proc.Process(ctx, topic, func(msg stream.Message) error {
+		got = append(got, msg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing messages: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, ignorePosition); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,239 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// A Window delimits the messages of a topic processed by a
+// [ReplayProcessor].
+type Window struct {
+	// FromTimestamp is the timestamp of the first message to be processed
+	// in every partition. If it is zero, FromOffset is used instead.
+	FromTimestamp time.Time
+
+	// FromOffset is the offset of the first message to be processed in
+	// every partition. It is only used if FromTimestamp is zero. Offsets
+	// that are not available anymore are replaced by the first available
+	// offset.
+	FromOffset int64
+
+	// Until is the timestamp of the last message to be processed. If it
+	// is zero, messages are processed up to the end of the partitions at
+	// the time processing starts.
+	Until time.Time
+
+	// Partitions contains the partitions to be processed. If it is
+	// empty, all the partitions of the topic are processed.
+	Partitions []int32
+
+	// Offsets contains the offset of the first message to be processed
+	// in specific partitions. It takes precedence over FromOffset and it
+	// is only used if FromTimestamp is zero. It allows to resume an
+	// interrupted replay.
+	Offsets map[int32]int64
+}
+
+// includes reports whether the partition is within the window.
+func (w Window) includes(partition int32) bool {
+	if len(w.Partitions) == 0 {
+		return true
+	}
+	for _, p := range w.Partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// A ReplayProcessor processes a bounded window of messages from a kafka
+// topic. Offsets are never committed, so replaying messages does not affect
+// other consumers.
+type ReplayProcessor struct {
+	c      *kafka.Consumer
+	window Window
+}
+
+// NewReplayProcessor returns a [ReplayProcessor] with the provided kafka
+// configuration properties that processes the messages in window.
+func NewReplayProcessor(config map[string]any, window Window) (ReplayProcessor, error) {
+	kconfig := make(kafka.ConfigMap)
+	for k, v := range config {
+		if err := kconfig.SetKey(k, v); err != nil {
+			return ReplayProcessor{}, fmt.Errorf("could not set config key: %w", err)
+		}
+	}
+
+	// A replay must not modify the offsets of the consumer group.
+	kconfig["enable.auto.commit"] = false
+	kconfig["enable.auto.offset.store"] = false
+
+	c, err := kafka.NewConsumer(&kconfig)
+	if err != nil {
+		return ReplayProcessor{}, fmt.Errorf("failed to create a consumer: %w", err)
+	}
+
+	return ReplayProcessor{c: c, window: window}, nil
+}
+
+// Process processes the messages of the topic called entity within the
+// window of the processor by calling h. This method blocks the calling
+// goroutine until all the messages in the window have been processed, the
+// specified context is cancelled or an error occurs.
+func (proc ReplayProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
+	start, end, err := proc.bounds(entity)
+	if err != nil {
+		return fmt.Errorf("could not get window bounds: %w", err)
+	}
+
+	// pending contains the last offset to be processed in every partition
+	// that has not been completely processed yet.
+	pending := make(map[int32]int64)
+	var assignment []kafka.TopicPartition
+	for _, tp := range start {
+		last := end[tp.Partition] - 1
+		if tp.Offset < 0 || int64(tp.Offset) > last {
+			continue
+		}
+		pending[tp.Partition] = last
+		assignment = append(assignment, tp)
+	}
+
+	if len(assignment) == 0 {
+		return nil
+	}
+
+	if err := proc.c.Assign(assignment); err != nil {
+		return fmt.Errorf("failed to assign partitions: %w", err)
+	}
+	defer proc.c.Unassign()
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		kmsg, err := proc.c.ReadMessage(100 * time.Millisecond)
+		if err != nil {
+			kerr, ok := err.(kafka.Error)
+			if ok && kerr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+			return fmt.Errorf("error reading message: %w", err)
+		}
+
+		partition := kmsg.TopicPartition.Partition
+		last, ok := pending[partition]
+		if !ok {
+			continue
+		}
+
+		if !proc.window.Until.IsZero() && kmsg.Timestamp.After(proc.window.Until) {
+			proc.done(pending, kmsg.TopicPartition)
+			continue
+		}
+
+		msg := streamMessage(kmsg)
+		if err := h(msg); err != nil {
+			return fmt.Errorf("error processing message: %w", messageError(msg, err))
+		}
+
+		if int64(kmsg.TopicPartition.Offset) >= last {
+			proc.done(pending, kmsg.TopicPartition)
+		}
+	}
+
+	return nil
+}
+
+// bounds returns the first offset to be processed in every partition of
+// topic and the end offset (high watermark) of every partition.
+func (proc ReplayProcessor) bounds(topic string) (start []kafka.TopicPartition, end map[int32]int64, err error) {
+	tmd, err := topicMetadata(proc.c, topic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end = make(map[int32]int64)
+	for _, p := range tmd.Partitions {
+		if !proc.window.includes(p.ID) {
+			continue
+		}
+
+		low, high, err := proc.c.QueryWatermarkOffsets(topic, p.ID, int(kafkaTimeout.Milliseconds()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get watermark offsets: %w", err)
+		}
+		end[p.ID] = high
+
+		// Offsets below the low watermark have been deleted or
+		// compacted, so start at the first available message.
+		offset := proc.window.FromOffset
+		if o, ok := proc.window.Offsets[p.ID]; ok {
+			offset = o
+		}
+		if offset < low {
+			offset = low
+		}
+
+		tp := kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: p.ID,
+			Offset:    kafka.Offset(offset),
+		}
+		if !proc.window.FromTimestamp.IsZero() {
+			tp.Offset = kafka.Offset(proc.window.FromTimestamp.UnixMilli())
+		}
+		start = append(start, tp)
+	}
+
+	if len(start) == 0 {
+		return nil, end, nil
+	}
+
+	if !proc.window.FromTimestamp.IsZero() {
+		start, err = proc.c.OffsetsForTimes(start, int(kafkaTimeout.Milliseconds()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get offsets for timestamp: %w", err)
+		}
+	}
+
+	return start, end, nil
+}
+
+// Partitions returns the partitions of topic.
+func (proc ReplayProcessor) Partitions(topic string) ([]int32, error) {
+	tmd, err := topicMetadata(proc.c, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]int32, 0, len(tmd.Partitions))
+	for _, p := range tmd.Partitions {
+		partitions = append(partitions, p.ID)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i] < partitions[j]
+	})
+	return partitions, nil
+}
+
+// done marks the partition of tp as processed and pauses it.
+func (proc ReplayProcessor) done(pending map[int32]int64, tp kafka.TopicPartition) {
+	delete(pending, tp.Partition)
+	proc.c.Pause([]kafka.TopicPartition{tp})
+}
+
+// Close closes the underlaying kafka consumer.
+func (proc ReplayProcessor) Close() error {
+	return proc.c.Close()
+}
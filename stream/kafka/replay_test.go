@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/internal/testinfra"
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestReplayProcessorProcess(t *testing.T) {
+	topic := topicPrefix + strconv.FormatInt(rand.Int63(), 16)
+
+	msgs, err := setupKafka(topic)
+	if err != nil {
+		t.Fatalf("error setting up kafka: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		window Window
+		want   []stream.Message
+	}{
+		{
+			name:   "from first offset",
+			window: Window{FromOffset: 0},
+			want:   msgs,
+		},
+		{
+			name:   "from offset",
+			window: Window{FromOffset: 1},
+			want:   msgs[1:],
+		},
+		{
+			name:   "from timestamp",
+			window: Window{FromTimestamp: time.Now().Add(-time.Hour)},
+			want:   msgs,
+		},
+		{
+			name:   "until timestamp",
+			window: Window{FromOffset: 0, Until: time.Now().Add(-time.Hour)},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]any{
+				"bootstrap.servers": testinfra.KafkaBootstrapServers(),
+				"group.id":          groupPrefix + strconv.FormatInt(rand.Int63(), 16),
+			}
+
+			proc, err := NewReplayProcessor(cfg, tt.window)
+			if err != nil {
+				t.Fatalf("error creating kafka processor: %v", err)
+			}
+			defer proc.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			var got []stream.Message
+			err = proc.Process(ctx, topic, func(msg stream.Message) error {
+				got = append(got, msg)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("error processing messages: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, ignorePosition); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMessageTooLarge is returned when the value of a message exceeds the
+// maximum size allowed by a [SizeLimitedProcessor].
+var ErrMessageTooLarge = errors.New("message too large")
+
+// SizeLimitedProcessor is a [Processor] that does not pass to the handler the
+// messages whose value is larger than a maximum size. It protects the
+// handlers from pathological messages, which could cause memory spikes when
+// decoded.
+type SizeLimitedProcessor struct {
+	proc      Processor
+	maxSize   int
+	oversized MsgHandler
+}
+
+// NewSizeLimitedProcessor returns a [SizeLimitedProcessor] that processes the
+// messages of proc. The messages whose value is larger than maxSize bytes are
+// passed to oversized instead of the handler. If oversized returns nil, the
+// message is considered processed. If oversized is nil, processing stops with
+// an error wrapping [ErrMessageTooLarge]. A maxSize of zero means no limit.
+func NewSizeLimitedProcessor(proc Processor, maxSize int, oversized MsgHandler) SizeLimitedProcessor {
+	return SizeLimitedProcessor{
+		proc:      proc,
+		maxSize:   maxSize,
+		oversized: oversized,
+	}
+}
+
+// Process processes the messages of the topic called entity by calling h.
+func (p SizeLimitedProcessor) Process(ctx context.Context, entity string, h MsgHandler) error {
+	if p.maxSize <= 0 {
+		return p.proc.Process(ctx, entity, h)
+	}
+
+	return p.proc.Process(ctx, entity, func(msg Message) error {
+		if len(msg.Value) <= p.maxSize {
+			return h(msg)
+		}
+
+		if p.oversized == nil {
+			return fmt.Errorf("%w: %v bytes, limit is %v bytes", ErrMessageTooLarge, len(msg.Value), p.maxSize)
+		}
+		return p.oversized(msg)
+	})
+}
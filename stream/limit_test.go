@@ -0,0 +1,94 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+var errOversized = errors.New("oversized")
+
+func TestSizeLimitedProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{Key: []byte("small"), Value: []byte("12345")},
+		{Key: []byte("large"), Value: []byte("123456")},
+		{Key: []byte("tombstone"), Value: nil},
+	}
+
+	tests := []struct {
+		name          string
+		maxSize       int
+		oversizedErr  error
+		nilOversized  bool
+		wantHandled   []string
+		wantOversized []string
+		wantErr       error
+	}{
+		{
+			name:          "skip oversized",
+			maxSize:       5,
+			wantHandled:   []string{"small", "tombstone"},
+			wantOversized: []string{"large"},
+			wantErr:       nil,
+		},
+		{
+			name:          "oversized error",
+			maxSize:       5,
+			oversizedErr:  errOversized,
+			wantHandled:   []string{"small"},
+			wantOversized: []string{"large"},
+			wantErr:       errOversized,
+		},
+		{
+			name:         "fail fast",
+			maxSize:      5,
+			nilOversized: true,
+			wantHandled:  []string{"small"},
+			wantErr:      stream.ErrMessageTooLarge,
+		},
+		{
+			name:        "no limit",
+			maxSize:     0,
+			wantHandled: []string{"small", "large", "tombstone"},
+			wantErr:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled, oversized []string
+
+			oversizedFn := func(msg stream.Message) error {
+				oversized = append(oversized, string(msg.Key))
+				return tt.oversizedErr
+			}
+			if tt.nilOversized {
+				oversizedFn = nil
+			}
+
+			proc := stream.NewSizeLimitedProcessor(streamtest.NewMockProcessor(msgs), tt.maxSize, oversizedFn)
+			err := proc.Process(context.Background(), "entity", func(msg stream.Message) error {
+				handled = append(handled, string(msg.Key))
+				return nil
+			})
+
+			if diff := cmp.Diff(tt.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantHandled, handled); diff != "" {
+				t.Errorf("handled messages mismatch (-want +got):\n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantOversized, oversized); diff != "" {
+				t.Errorf("oversized messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
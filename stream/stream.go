@@ -2,13 +2,37 @@
 // platforms.
 package stream
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // Message represents a message coming from a stream.
 type Message struct {
 	Key      []byte
 	Value    []byte
 	Metadata []MetadataEntry
+
+	// Position is the position of the message in the stream. It is
+	// filled on a best-effort basis by the stream processor.
+	Position Position
+
+	// Timestamp is the time of the message as reported by the
+	// stream-processing platform. It is zero if it is not available.
+	Timestamp time.Time
+}
+
+// Position identifies a message in a stream.
+type Position struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// String returns the position with the format "topic/partition@offset".
+func (p Position) String() string {
+	return fmt.Sprintf("%v/%v@%v", p.Topic, p.Partition, p.Offset)
 }
 
 // MetadataEntry represents a metadata entry.
@@ -17,6 +41,24 @@ type MetadataEntry struct {
 	Value []byte
 }
 
+// MessageError is returned by a stream processor when a message cannot be
+// processed. It identifies the message, so it can be inspected with the
+// tools of the stream-processing platform.
+type MessageError struct {
+	Position Position
+	Key      []byte
+	Err      error
+}
+
+func (e MessageError) Error() string {
+	return fmt.Sprintf("message %v (key %q): %v", e.Position, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e MessageError) Unwrap() error {
+	return e.Err
+}
+
 // A Processor represents a stream message processor.
 type Processor interface {
 	Process(ctx context.Context, entity string, h MsgHandler) error
@@ -0,0 +1,189 @@
+package streamtest
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// Default values used by [NewAssetMessage].
+const (
+	DefaultTeamID          = "9a1a0332-88b6-4edc-aa37-50adc1ad96da"
+	DefaultAssetID         = "f110cf6f-803d-442c-9b42-f6d8cd962bf2"
+	DefaultAssetType       = "Hostname"
+	DefaultAssetIdentifier = "www.example.com"
+	DefaultVersion         = "0.1.2"
+)
+
+// AssetMessage builds assets-v0 messages as defined by the Vulcan async API.
+// Its methods return a modified copy of the builder, so a builder can be used
+// as the base of several messages.
+type AssetMessage struct {
+	version     string
+	teamID      string
+	teamName    string
+	teamDesc    string
+	teamTag     string
+	assetID     string
+	typ         string
+	identifier  string
+	alias       string
+	rolfp       string
+	scannable   bool
+	annotations map[string]string
+	tombstone   bool
+}
+
+// NewAssetMessage returns an [AssetMessage] initialized with default values.
+func NewAssetMessage() AssetMessage {
+	return AssetMessage{
+		version:    DefaultVersion,
+		teamID:     DefaultTeamID,
+		assetID:    DefaultAssetID,
+		typ:        DefaultAssetType,
+		identifier: DefaultAssetIdentifier,
+		rolfp:      "R:0/O:0/L:0/F:0/P:0+S:0",
+	}
+}
+
+// WithVersion sets the version of the Vulcan async API in the metadata of
+// the message.
+func (am AssetMessage) WithVersion(version string) AssetMessage {
+	am.version = version
+	return am
+}
+
+// WithTeam sets the ID and name of the team.
+func (am AssetMessage) WithTeam(id, name string) AssetMessage {
+	am.teamID = id
+	am.teamName = name
+	return am
+}
+
+// WithTeamDetails sets the description and tag of the team.
+func (am AssetMessage) WithTeamDetails(description, tag string) AssetMessage {
+	am.teamDesc = description
+	am.teamTag = tag
+	return am
+}
+
+// WithID sets the ID of the asset.
+func (am AssetMessage) WithID(id string) AssetMessage {
+	am.assetID = id
+	return am
+}
+
+// WithAsset sets the type and identifier of the asset.
+func (am AssetMessage) WithAsset(typ, identifier string) AssetMessage {
+	am.typ = typ
+	am.identifier = identifier
+	return am
+}
+
+// WithAlias sets the alias of the asset.
+func (am AssetMessage) WithAlias(alias string) AssetMessage {
+	am.alias = alias
+	return am
+}
+
+// WithRolfp sets the ROLFP of the asset.
+func (am AssetMessage) WithRolfp(rolfp string) AssetMessage {
+	am.rolfp = rolfp
+	return am
+}
+
+// WithScannable sets whether the asset is scannable.
+func (am AssetMessage) WithScannable(scannable bool) AssetMessage {
+	am.scannable = scannable
+	return am
+}
+
+// WithAnnotations adds annotations to the asset. Annotations are encoded
+// sorted by key.
+func (am AssetMessage) WithAnnotations(annotations map[string]string) AssetMessage {
+	merged := make(map[string]string, len(am.annotations)+len(annotations))
+	for k, v := range am.annotations {
+		merged[k] = v
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	am.annotations = merged
+	return am
+}
+
+// Tombstone makes the message a tombstone. That is, a message with a nil
+// value, which means that the asset has been deleted.
+func (am AssetMessage) Tombstone() AssetMessage {
+	am.tombstone = true
+	return am
+}
+
+// Message returns the built message. It panics if the payload cannot be
+// encoded.
+func (am AssetMessage) Message() stream.Message {
+	msg := stream.Message{
+		Key: []byte(am.teamID + "/" + am.assetID),
+		Metadata: []stream.MetadataEntry{
+			{Key: []byte("version"), Value: []byte(am.version)},
+			{Key: []byte("type"), Value: []byte(am.typ)},
+			{Key: []byte("identifier"), Value: []byte(am.identifier)},
+		},
+	}
+
+	if am.tombstone {
+		return msg
+	}
+
+	// The payload is defined here instead of using the types of the
+	// vulcan package to avoid an import cycle with its tests.
+	type annotation struct {
+		Key   string `json:"Key"`
+		Value string `json:"Value"`
+	}
+	payload := struct {
+		ID   string `json:"Id"`
+		Team struct {
+			ID          string `json:"Id"`
+			Name        string `json:"Name"`
+			Description string `json:"Description"`
+			Tag         string `json:"Tag"`
+		} `json:"Team"`
+		Alias       string       `json:"Alias"`
+		Rolfp       string       `json:"Rolfp"`
+		Scannable   bool         `json:"Scannable"`
+		AssetType   string       `json:"AssetType"`
+		Identifier  string       `json:"Identifier"`
+		Annotations []annotation `json:"Annotations"`
+	}{
+		ID:          am.assetID,
+		Alias:       am.alias,
+		Rolfp:       am.rolfp,
+		Scannable:   am.scannable,
+		AssetType:   am.typ,
+		Identifier:  am.identifier,
+		Annotations: []annotation{},
+	}
+	payload.Team.ID = am.teamID
+	payload.Team.Name = am.teamName
+	payload.Team.Description = am.teamDesc
+	payload.Team.Tag = am.teamTag
+
+	keys := make([]string, 0, len(am.annotations))
+	for k := range am.annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		payload.Annotations = append(payload.Annotations, annotation{Key: k, Value: am.annotations[k]})
+	}
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	msg.Value = value
+
+	return msg
+}
@@ -0,0 +1,75 @@
+package streamtest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+func TestAssetMessage(t *testing.T) {
+	base := NewAssetMessage().
+		WithTeam("9a86666e-ef3a-4630-845d-d3c61e167931", "Team name").
+		WithTeamDetails("Team description", "f0eac043").
+		WithID("d2e37146-61d7-4010-aa25-2335c385a980").
+		WithAsset("DockerImage", "busybox:latest")
+
+	metadata := []stream.MetadataEntry{
+		{Key: []byte("version"), Value: []byte("0.1.2")},
+		{Key: []byte("type"), Value: []byte("DockerImage")},
+		{Key: []byte("identifier"), Value: []byte("busybox:latest")},
+	}
+
+	tests := []struct {
+		name string
+		am   AssetMessage
+		want stream.Message
+	}{
+		{
+			name: "asset",
+			am: base.
+				WithAlias("Asset alias").
+				WithRolfp("R:1/O:0/L:1/F:0/P:1+S:0").
+				WithScannable(true).
+				WithAnnotations(map[string]string{"b": "value b"}).
+				WithAnnotations(map[string]string{"a": "value a"}),
+			want: stream.Message{
+				Key:      []byte("9a86666e-ef3a-4630-845d-d3c61e167931/d2e37146-61d7-4010-aa25-2335c385a980"),
+				Value:    []byte(`{"Id":"d2e37146-61d7-4010-aa25-2335c385a980","Team":{"Id":"9a86666e-ef3a-4630-845d-d3c61e167931","Name":"Team name","Description":"Team description","Tag":"f0eac043"},"Alias":"Asset alias","Rolfp":"R:1/O:0/L:1/F:0/P:1+S:0","Scannable":true,"AssetType":"DockerImage","Identifier":"busybox:latest","Annotations":[{"Key":"a","Value":"value a"},{"Key":"b","Value":"value b"}]}`),
+				Metadata: metadata,
+			},
+		},
+		{
+			name: "tombstone",
+			am:   base.Tombstone(),
+			want: stream.Message{
+				Key:      []byte("9a86666e-ef3a-4630-845d-d3c61e167931/d2e37146-61d7-4010-aa25-2335c385a980"),
+				Value:    nil,
+				Metadata: metadata,
+			},
+		},
+		{
+			name: "version",
+			am:   NewAssetMessage().WithVersion("1.0.0").Tombstone(),
+			want: stream.Message{
+				Key:   []byte(DefaultTeamID + "/" + DefaultAssetID),
+				Value: nil,
+				Metadata: []stream.MetadataEntry{
+					{Key: []byte("version"), Value: []byte("1.0.0")},
+					{Key: []byte("type"), Value: []byte(DefaultAssetType)},
+					{Key: []byte("identifier"), Value: []byte(DefaultAssetIdentifier)},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.am.Message()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("message mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package streamtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// AddToCorpus adds msgs to the seed corpus of the fuzz test f. Every message
+// is added as the arguments (key []byte, value []byte, isNil bool, metadata
+// []byte), so the fuzz target must accept them in that order. The target
+// can reconstruct the message with [FuzzMessage].
+func AddToCorpus(f *testing.F, msgs []stream.Message) {
+	for _, msg := range msgs {
+		f.Add(msg.Key, msg.Value, msg.Value == nil, encodeMetadata(msg.Metadata))
+	}
+}
+
+// FuzzMessage returns the message corresponding to the arguments of a fuzz
+// target seeded with [AddToCorpus]. If isNil is true, the returned message
+// has a nil value.
+func FuzzMessage(key, value []byte, isNil bool, metadata []byte) stream.Message {
+	msg := stream.Message{
+		Key:      key,
+		Metadata: decodeMetadata(metadata),
+	}
+	if !isNil {
+		msg.Value = value
+	}
+	return msg
+}
+
+// encodeMetadata encodes metadata entries as newline-separated "key=value"
+// lines.
+func encodeMetadata(entries []stream.MetadataEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(e.Key)
+		buf.WriteByte('=')
+		buf.Write(e.Value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// decodeMetadata decodes metadata entries encoded by encodeMetadata. Lines
+// without "=" are considered keys with an empty value.
+func decodeMetadata(b []byte) []stream.MetadataEntry {
+	var entries []stream.MetadataEntry
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		k, v, _ := bytes.Cut(line, []byte("="))
+		entries = append(entries, stream.MetadataEntry{Key: k, Value: v})
+	}
+	return entries
+}
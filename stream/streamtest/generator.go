@@ -0,0 +1,196 @@
+package streamtest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// GeneratorConfig configures the messages produced by [Generate].
+type GeneratorConfig struct {
+	// Seed is the seed of the pseudo-random generator. The same
+	// configuration always produces the same messages.
+	Seed int64
+
+	// Messages is the number of messages to generate.
+	Messages int
+
+	// Assets is the number of distinct assets. Messages are distributed
+	// among them uniformly, so an asset can receive several events. If
+	// zero, the number of messages is used.
+	Assets int
+
+	// Teams is the number of distinct teams. If zero, one team is used.
+	Teams int
+
+	// AssetTypes are the types of the generated assets. If empty,
+	// [DefaultAssetTypes] is used.
+	AssetTypes []string
+
+	// MaxAnnotations is the maximum number of generic annotations of an
+	// asset. The number of annotations of every asset is uniformly
+	// distributed between zero and MaxAnnotations.
+	MaxAnnotations int
+
+	// AWSAccountKey is the key of the annotation that contains the AWS
+	// account of an asset. If empty, "discovery/aws/account" is used.
+	AWSAccountKey string
+
+	// AWSAccountRatio is the probability of an asset having an AWS account
+	// annotation. It must be in the range [0, 1].
+	AWSAccountRatio float64
+
+	// TombstoneRatio is the probability of a message being a tombstone.
+	// Only assets that have been previously refreshed are deleted. It
+	// must be in the range [0, 1].
+	TombstoneRatio float64
+}
+
+// DefaultAssetTypes are the asset types used by [Generate] if none is
+// specified.
+var DefaultAssetTypes = []string{"Hostname", "DomainName", "IP", "WebAddress", "DockerImage", "AWSAccount"}
+
+// Generate returns a sequence of realistic synthetic asset messages as
+// defined by cfg. The messages can be used directly with [NewMockProcessor] or
+// written as fixtures with [WriteJSON].
+func Generate(cfg GeneratorConfig) []stream.Message {
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+
+	types := cfg.AssetTypes
+	if len(types) == 0 {
+		types = DefaultAssetTypes
+	}
+	nteams := cfg.Teams
+	if nteams <= 0 {
+		nteams = 1
+	}
+	nassets := cfg.Assets
+	if nassets <= 0 {
+		nassets = cfg.Messages
+	}
+	awsKey := cfg.AWSAccountKey
+	if awsKey == "" {
+		awsKey = "discovery/aws/account"
+	}
+
+	teams := make([]AssetMessage, nteams)
+	for i := range teams {
+		teams[i] = NewAssetMessage().
+			WithTeam(uuid(rnd), fmt.Sprintf("team%v name", i)).
+			WithTeamDetails(fmt.Sprintf("team%v description", i), fmt.Sprintf("%08x", rnd.Uint32()))
+	}
+
+	// assets contains the message builder of every asset. Assets are
+	// generated lazily, the first time they are referenced.
+	assets := make([]*AssetMessage, nassets)
+	// live contains the assets that have been refreshed and not deleted.
+	var live liveSet
+
+	var msgs []stream.Message
+	for len(msgs) < cfg.Messages {
+		if live.len() > 0 && rnd.Float64() < cfg.TombstoneRatio {
+			i := live.pop(rnd)
+			msgs = append(msgs, assets[i].Tombstone().Message())
+			continue
+		}
+
+		i := rnd.Intn(nassets)
+		if assets[i] == nil {
+			am := newSyntheticAsset(rnd, cfg, teams[rnd.Intn(nteams)], types, i, awsKey)
+			assets[i] = &am
+		}
+		live.add(i)
+		msgs = append(msgs, assets[i].Message())
+	}
+	return msgs
+}
+
+// newSyntheticAsset returns the builder of the i-th synthetic asset owned by
+// team.
+func newSyntheticAsset(rnd *rand.Rand, cfg GeneratorConfig, team AssetMessage, types []string, i int, awsKey string) AssetMessage {
+	typ := types[rnd.Intn(len(types))]
+
+	annotations := make(map[string]string)
+	n := rnd.Intn(cfg.MaxAnnotations + 1)
+	for j := 0; j < n; j++ {
+		annotations[fmt.Sprintf("annotation/%v", j)] = fmt.Sprintf("value%v/%v", i, j)
+	}
+	if rnd.Float64() < cfg.AWSAccountRatio {
+		annotations[awsKey] = fmt.Sprintf("%012d", rnd.Int63n(1e12))
+	}
+
+	return team.
+		WithID(uuid(rnd)).
+		WithAsset(typ, syntheticIdentifier(rnd, typ, i)).
+		WithAlias(fmt.Sprintf("asset%v alias", i)).
+		WithRolfp(fmt.Sprintf("R:%v/O:%v/L:%v/F:%v/P:%v+S:%v", rnd.Intn(2), rnd.Intn(2), rnd.Intn(2), rnd.Intn(2), rnd.Intn(2), rnd.Intn(2))).
+		WithScannable(rnd.Intn(2) == 0).
+		WithAnnotations(annotations)
+}
+
+// syntheticIdentifier returns a valid identifier for the i-th asset with type
+// typ.
+func syntheticIdentifier(rnd *rand.Rand, typ string, i int) string {
+	switch typ {
+	case "IP":
+		return fmt.Sprintf("10.%v.%v.%v", i>>16&0xff, i>>8&0xff, i&0xff)
+	case "WebAddress":
+		return fmt.Sprintf("https://asset%v.example.com/", i)
+	case "DockerImage":
+		return fmt.Sprintf("registry.example.com/image%v:latest", i)
+	case "AWSAccount":
+		return fmt.Sprintf("arn:aws:iam::%012d:root", rnd.Int63n(1e12))
+	case "DomainName":
+		return fmt.Sprintf("example%v.com", i)
+	default:
+		return fmt.Sprintf("asset%v.example.com", i)
+	}
+}
+
+// uuid returns a pseudo-random version 4 UUID generated with rnd.
+func uuid(rnd *rand.Rand) string {
+	var b [16]byte
+	rnd.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// liveSet is a set of asset indexes that supports picking a pseudo-random
+// element in constant time.
+type liveSet struct {
+	items []int
+	pos   map[int]int
+}
+
+func (ls *liveSet) len() int {
+	return len(ls.items)
+}
+
+// add adds i to the set.
+func (ls *liveSet) add(i int) {
+	if ls.pos == nil {
+		ls.pos = make(map[int]int)
+	}
+	if _, ok := ls.pos[i]; ok {
+		return
+	}
+	ls.pos[i] = len(ls.items)
+	ls.items = append(ls.items, i)
+}
+
+// pop removes a pseudo-random element from the set and returns it. The set
+// must not be empty.
+func (ls *liveSet) pop(rnd *rand.Rand) int {
+	n := rnd.Intn(len(ls.items))
+	i := ls.items[n]
+
+	last := len(ls.items) - 1
+	ls.items[n] = ls.items[last]
+	ls.pos[ls.items[n]] = n
+	ls.items = ls.items[:last]
+	delete(ls.pos, i)
+
+	return i
+}
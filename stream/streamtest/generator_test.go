@@ -0,0 +1,90 @@
+package streamtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/vulcan"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := GeneratorConfig{
+		Seed:            1,
+		Messages:        1000,
+		Assets:          100,
+		Teams:           5,
+		MaxAnnotations:  3,
+		AWSAccountRatio: 0.5,
+		TombstoneRatio:  0.2,
+	}
+
+	msgs := Generate(cfg)
+	if len(msgs) != cfg.Messages {
+		t.Fatalf("unexpected number of messages: want=%v, got=%v", cfg.Messages, len(msgs))
+	}
+
+	if diff := cmp.Diff(msgs, Generate(cfg)); diff != "" {
+		t.Errorf("messages are not deterministic (-first +second):\n%v", diff)
+	}
+
+	var (
+		tombstones int
+		aws        int
+		live       = make(map[string]bool)
+		teams      = make(map[string]bool)
+	)
+	cli := vulcan.NewClient(NewMockProcessor(msgs))
+	err := cli.ProcessAssets(context.Background(), func(payload vulcan.AssetPayload, isNil bool) error {
+		id := string(payload.AssetType) + "/" + payload.Identifier
+		if isNil {
+			if !live[id] {
+				t.Errorf("tombstone for asset not refreshed before: %v", id)
+			}
+			delete(live, id)
+			tombstones++
+			return nil
+		}
+
+		live[id] = true
+		teams[payload.Team.ID] = true
+		for _, a := range payload.Annotations {
+			if a.Key == "discovery/aws/account" {
+				aws++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("invalid generated messages: %v", err)
+	}
+
+	if tombstones < 150 || tombstones > 250 {
+		t.Errorf("unexpected number of tombstones: %v", tombstones)
+	}
+
+	if aws == 0 {
+		t.Errorf("no AWS account annotations were generated")
+	}
+
+	if len(teams) != cfg.Teams {
+		t.Errorf("unexpected number of teams: want=%v, got=%v", cfg.Teams, len(teams))
+	}
+}
+
+func TestGenerateDefaults(t *testing.T) {
+	msgs := Generate(GeneratorConfig{Messages: 10})
+
+	ids := make(map[string]bool)
+	for _, msg := range msgs {
+		if msg.Value == nil {
+			t.Errorf("unexpected tombstone")
+		}
+		ids[string(msg.Key)] = true
+	}
+
+	if len(ids) > 10 || len(msgs) != 10 {
+		t.Errorf("unexpected messages: got %v messages with %v distinct keys", len(msgs), len(ids))
+	}
+}
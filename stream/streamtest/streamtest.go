@@ -4,7 +4,12 @@ package streamtest
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"time"
 
 	"github.com/adevinta/graph-vulcan-assets/stream"
 )
@@ -12,10 +17,20 @@ import (
 // MustParse parses a json file with messages and returns them. It panics if
 // the file cannot be parsed.
 func MustParse(filename string) []stream.Message {
-	f, err := os.Open(filename)
+	msgs, err := Parse(filename)
 	if err != nil {
 		panic(err)
 	}
+	return msgs
+}
+
+// Parse is like [MustParse] but it returns an error if the file cannot be
+// parsed.
+func Parse(filename string) ([]stream.Message, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
 	var testdata []struct {
@@ -28,7 +43,7 @@ func MustParse(filename string) []stream.Message {
 	}
 
 	if err := json.NewDecoder(f).Decode(&testdata); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("could not decode messages: %w", err)
 	}
 
 	var msgs []stream.Message
@@ -42,10 +57,10 @@ func MustParse(filename string) []stream.Message {
 		}
 		for _, e := range td.Metadata {
 			if e.Key == "" {
-				panic("empty metadata key")
+				return nil, errors.New("empty metadata key")
 			}
 			if e.Value == "" {
-				panic("empty metadata value")
+				return nil, errors.New("empty metadata value")
 			}
 			entry := stream.MetadataEntry{
 				Key:   []byte(e.Key),
@@ -56,27 +71,125 @@ func MustParse(filename string) []stream.Message {
 		msgs = append(msgs, msg)
 	}
 
-	return msgs
+	return msgs, nil
 }
 
+// WriteJSON writes msgs to w using the JSON format read by [MustParse]. It is
+// meant to generate fixtures from real messages.
+func WriteJSON(w io.Writer, msgs []stream.Message) error {
+	type metadataEntry struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	type message struct {
+		Key      *string         `json:"key"`
+		Value    *string         `json:"value"`
+		Metadata []metadataEntry `json:"metadata"`
+	}
+
+	testdata := make([]message, 0, len(msgs))
+	for _, msg := range msgs {
+		var td message
+		if msg.Key != nil {
+			key := string(msg.Key)
+			td.Key = &key
+		}
+		if msg.Value != nil {
+			value := string(msg.Value)
+			td.Value = &value
+		}
+		for _, e := range msg.Metadata {
+			entry := metadataEntry{
+				Key:   string(e.Key),
+				Value: string(e.Value),
+			}
+			td.Metadata = append(td.Metadata, entry)
+		}
+		testdata = append(testdata, td)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(testdata); err != nil {
+		return fmt.Errorf("could not encode messages: %w", err)
+	}
+	return nil
+}
+
+// ErrInjected is the error returned by [MockProcessor] when a failure is
+// injected.
+var ErrInjected = errors.New("injected failure")
+
 // MockProcessor mocks a stream processor with a predefined set of messages. It
 // implements the interface [stream.Processor].
+//
+// Failures can be injected by setting the exported fields before calling
+// [MockProcessor.Process]. When a failure is injected, Process returns
+// [ErrInjected] without calling the handler for the affected message, which
+// mimics a stream processor that loses the connection with the broker.
 type MockProcessor struct {
+	// FailNth makes Process fail when it reaches the Nth message, counting
+	// from 1. Zero disables it.
+	FailNth int
+
+	// FailProbability is the probability of failing every message. It
+	// must be in the range [0, 1].
+	FailProbability float64
+
+	// Rand is the source of randomness used by FailProbability. If nil,
+	// the default source of the math/rand package is used.
+	Rand *rand.Rand
+
+	// Delay is the time Process waits before handling every message.
+	Delay time.Duration
+
 	msgs []stream.Message
 }
 
 // NewMockProcessor returns a [MockProcessor]. It initializes its internal list
 // of messages with msgs.
 func NewMockProcessor(msgs []stream.Message) *MockProcessor {
-	return &MockProcessor{msgs}
+	return &MockProcessor{msgs: msgs}
 }
 
-// Process processes the messages passed to [NewMockProcessor].
+// Process processes the messages passed to [NewMockProcessor]. Like the
+// Kafka processor, it returns nil if ctx is canceled. The position of every
+// message is set to partition 0 of the topic called entity, with the index of
+// the message as offset.
 func (mp *MockProcessor) Process(ctx context.Context, entity string, h stream.MsgHandler) error {
-	for _, msg := range mp.msgs {
+	for i, msg := range mp.msgs {
+		if mp.Delay > 0 {
+			t := time.NewTimer(mp.Delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil
+			case <-t.C:
+			}
+		} else if ctx.Err() != nil {
+			return nil
+		}
+
+		if mp.FailNth > 0 && i+1 == mp.FailNth {
+			return fmt.Errorf("message %v: %w", i+1, ErrInjected)
+		}
+
+		if mp.FailProbability > 0 && mp.float64() < mp.FailProbability {
+			return fmt.Errorf("message %v: %w", i+1, ErrInjected)
+		}
+
+		msg.Position = stream.Position{Topic: entity, Offset: int64(i)}
 		if err := h(msg); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// float64 returns a pseudo-random number in the range [0, 1).
+func (mp *MockProcessor) float64() float64 {
+	if mp.Rand != nil {
+		return mp.Rand.Float64()
+	}
+	return rand.Float64()
+}
@@ -1,7 +1,13 @@
 package streamtest
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -92,3 +98,121 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestMockProcessor(t *testing.T) {
+	msgs := []stream.Message{
+		{Key: []byte("key0")},
+		{Key: []byte("key1")},
+		{Key: []byte("key2")},
+	}
+
+	tests := []struct {
+		name        string
+		mp          *MockProcessor
+		canceledCtx bool
+		want        []string
+		wantErr     error
+	}{
+		{
+			name: "no failures",
+			mp:   NewMockProcessor(msgs),
+			want: []string{"key0", "key1", "key2"},
+		},
+		{
+			name:    "fail nth message",
+			mp:      &MockProcessor{msgs: msgs, FailNth: 2},
+			want:    []string{"key0"},
+			wantErr: ErrInjected,
+		},
+		{
+			name:    "always fail",
+			mp:      &MockProcessor{msgs: msgs, FailProbability: 1},
+			want:    nil,
+			wantErr: ErrInjected,
+		},
+		{
+			name: "never fail",
+			mp:   &MockProcessor{msgs: msgs, FailProbability: 0, Rand: rand.New(rand.NewSource(1))},
+			want: []string{"key0", "key1", "key2"},
+		},
+		{
+			name: "delay",
+			mp:   &MockProcessor{msgs: msgs, Delay: time.Millisecond},
+			want: []string{"key0", "key1", "key2"},
+		},
+		{
+			name:        "canceled context",
+			mp:          NewMockProcessor(msgs),
+			canceledCtx: true,
+			want:        nil,
+		},
+		{
+			name:        "canceled context with delay",
+			mp:          &MockProcessor{msgs: msgs, Delay: time.Hour},
+			canceledCtx: true,
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.canceledCtx {
+				cancel()
+			}
+
+			var got []string
+			err := tt.mp.Process(ctx, "entity", func(msg stream.Message) error {
+				got = append(got, string(msg.Key))
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v, got=%v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	want := MustParse("testdata/valid.json")
+
+	f, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("could not create temp file: %v", err)
+	}
+	defer f.Close()
+
+	if err := WriteJSON(f, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := MustParse(f.Name())
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestFuzzMessage(t *testing.T) {
+	for _, want := range MustParse("testdata/valid.json") {
+		got := FuzzMessage(want.Key, want.Value, want.Value == nil, encodeMetadata(want.Metadata))
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("message mismatch (-want +got):\n%v", diff)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package vulcan
+
+import (
+	"sync"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// IdentifierKey is the key of the metadata entry that contains the
+// identifier of the asset a message refers to.
+const IdentifierKey = "identifier"
+
+// Ordering serializes the processing of the messages that refer to the same
+// asset identifier, according to their [IdentifierKey] metadata entry. When
+// the same Ordering is shared by the [Handlers] of several topics, the
+// messages of the same asset are never processed concurrently across
+// topics. For instance, a finding is not attached to an asset while a
+// tombstone of the same asset is expiring it. Messages without identifier
+// are not serialized. It is safe for concurrent use. A nil Ordering does
+// not serialize any message.
+type Ordering struct {
+	mu    sync.Mutex
+	locks map[string]*orderingLock
+}
+
+// orderingLock is the lock of an asset identifier of [Ordering]. refs is
+// the number of messages holding or waiting for the lock, so it is removed
+// when no message needs it anymore.
+type orderingLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewOrdering returns a new [Ordering].
+func NewOrdering() *Ordering {
+	return &Ordering{locks: make(map[string]*orderingLock)}
+}
+
+// lock blocks until no other message with the provided identifier is being
+// processed. It returns a function that must be called once the message is
+// processed.
+func (o *Ordering) lock(identifier string) (unlock func()) {
+	o.mu.Lock()
+	l, ok := o.locks[identifier]
+	if !ok {
+		l = &orderingLock{}
+		o.locks[identifier] = l
+	}
+	l.refs++
+	o.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(o.locks, identifier)
+		}
+	}
+}
+
+// handler returns a [stream.MsgHandler] that processes the messages with h
+// one at a time for every asset identifier.
+func (o *Ordering) handler(h stream.MsgHandler) stream.MsgHandler {
+	if o == nil {
+		return h
+	}
+	return func(msg stream.Message) error {
+		identifier := metadataValue(msg, IdentifierKey)
+		if identifier == "" {
+			return h(msg)
+		}
+
+		unlock := o.lock(identifier)
+		defer unlock()
+
+		return h(msg)
+	}
+}
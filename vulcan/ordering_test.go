@@ -0,0 +1,81 @@
+package vulcan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adevinta/graph-vulcan-assets/stream"
+)
+
+// withIdentifier returns a message with the provided identifier metadata
+// entry. If identifier is empty, the message has no metadata.
+func withIdentifier(identifier string) stream.Message {
+	var msg stream.Message
+	if identifier != "" {
+		msg.Metadata = []stream.MetadataEntry{{Key: []byte(IdentifierKey), Value: []byte(identifier)}}
+	}
+	return msg
+}
+
+func TestOrdering(t *testing.T) {
+	o := NewOrdering()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	assets := o.handler(func(msg stream.Message) error {
+		close(started)
+		<-release
+		return nil
+	})
+	go assets(withIdentifier("example.com"))
+	<-started
+
+	processed := make(chan string, 3)
+	findings := o.handler(func(msg stream.Message) error {
+		processed <- metadataValue(msg, IdentifierKey)
+		return nil
+	})
+	go findings(withIdentifier("example.com"))
+	go findings(withIdentifier("other.example.com"))
+	go findings(withIdentifier(""))
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-processed:
+			got[id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("messages of other assets not processed")
+		}
+	}
+	if got["example.com"] {
+		t.Fatal("message processed while the asset is in flight")
+	}
+
+	close(release)
+
+	select {
+	case id := <-processed:
+		if id != "example.com" {
+			t.Errorf("unexpected identifier: want=%v got=%v", "example.com", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not processed after releasing the asset")
+	}
+}
+
+func TestOrderingNil(t *testing.T) {
+	var o *Ordering
+
+	called := false
+	h := o.handler(func(msg stream.Message) error {
+		called = true
+		return nil
+	})
+	if err := h(withIdentifier("example.com")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("handler not called")
+	}
+}
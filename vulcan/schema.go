@@ -0,0 +1,211 @@
+package vulcan
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// assetPayloadSchemaJSON is the JSON schema of the "assetPayload" model
+// as published in the Vulcan async API.
+//
+//go:embed schema/asset_payload.json
+var assetPayloadSchemaJSON []byte
+
+// assetPayloadSchema is the parsed [assetPayloadSchemaJSON].
+var assetPayloadSchema = mustParseSchema(assetPayloadSchemaJSON)
+
+// SchemaError is returned by [ValidateAssetPayload] when a payload does not
+// conform to the schema. Violations describes every violation found,
+// prefixed by the JSON path of the offending value.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e SchemaError) Error() string {
+	return "schema violation: " + strings.Join(e.Violations, "; ")
+}
+
+// ValidateAssetPayload validates value, the JSON encoded value of an asset
+// message, against the JSON schema of the "assetPayload" model of the
+// Vulcan async API, which is embedded at build time. It returns a
+// [SchemaError] if the payload does not conform to the schema.
+func ValidateAssetPayload(value []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return SchemaError{Violations: []string{fmt.Sprintf("$: invalid JSON: %v", err)}}
+	}
+
+	if violations := assetPayloadSchema.validate("$", v); len(violations) > 0 {
+		return SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+// jsonSchema is the subset of JSON schema used by the models of the Vulcan
+// async API: "type", "required", "properties", "items" and "minLength".
+// The annotation keywords "$schema", "title" and "description" are
+// ignored. Any other keyword is rejected when the schema is parsed, so a
+// schema update that relies on it is not validated partially.
+type jsonSchema struct {
+	Type       schemaTypes            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	MinLength  int                    `json:"minLength"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler]. It returns an error if the
+// schema contains a keyword not supported by [jsonSchema].
+func (s *jsonSchema) UnmarshalJSON(b []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keywords); err != nil {
+		return err
+	}
+	for k := range keywords {
+		if !schemaKeyword(k) {
+			return fmt.Errorf("unsupported keyword %q", k)
+		}
+	}
+
+	// schema has the fields of jsonSchema but not its methods, so
+	// unmarshaling it does not call UnmarshalJSON recursively.
+	type schema jsonSchema
+	return json.Unmarshal(b, (*schema)(s))
+}
+
+// schemaKeyword reports whether k is a keyword allowed in a [jsonSchema].
+// It is a function instead of a map, so it can be used while the package
+// variables, like [assetPayloadSchema], are initialized.
+func schemaKeyword(k string) bool {
+	switch k {
+	case "$schema", "title", "description":
+		return true
+	case "type", "required", "properties", "items", "minLength":
+		return true
+	}
+	return false
+}
+
+// schemaTypes are the types allowed by a [jsonSchema]. In JSON, it can be
+// a string or an array of strings.
+type schemaTypes []string
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = schemaTypes{s}
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return fmt.Errorf("invalid type: %s", b)
+	}
+	*t = ss
+	return nil
+}
+
+// parseSchema parses the provided JSON schema. It returns an error if the
+// schema uses a keyword not supported by [jsonSchema].
+func parseSchema(b []byte) (*jsonSchema, error) {
+	var s jsonSchema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return &s, nil
+}
+
+// mustParseSchema is like [parseSchema] but panics on error.
+func mustParseSchema(b []byte) *jsonSchema {
+	s, err := parseSchema(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// validate validates v, a value decoded with [json.Decoder.UseNumber],
+// against s. path is the JSON path of v. It returns the violations found.
+func (s *jsonSchema) validate(path string, v any) []string {
+	typ := jsonType(v)
+	if len(s.Type) > 0 && !s.allows(typ) {
+		return []string{fmt.Sprintf("%v: got %v, want %v", path, typ, strings.Join(s.Type, " or "))}
+	}
+
+	var violations []string
+	switch v := v.(type) {
+	case string:
+		if n := utf8.RuneCountInString(v); n < s.MinLength {
+			violations = append(violations, fmt.Sprintf("%v: length %v is less than %v", path, n, s.MinLength))
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%v: missing property %q", path, name))
+			}
+		}
+
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if pv, ok := v[name]; ok {
+				violations = append(violations, s.Properties[name].validate(path+"."+name, pv)...)
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			break
+		}
+		for i, iv := range v {
+			violations = append(violations, s.Items.validate(fmt.Sprintf("%v[%v]", path, i), iv)...)
+		}
+	}
+	return violations
+}
+
+// allows reports whether typ is one of the types allowed by s. The type
+// "number" also allows integers.
+func (s *jsonSchema) allows(typ string) bool {
+	for _, t := range s.Type {
+		if t == typ || (t == "number" && typ == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON schema type of v, a value decoded with
+// [json.Decoder.UseNumber].
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,146 @@
+package vulcan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
+)
+
+func TestValidateAssetPayload(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		wantViolations []string
+	}{
+		{
+			name:           "valid",
+			value:          `{"Id":"asset-1","Team":{"Id":"team-1","Name":"Team 1"},"Alias":"","Scannable":true,"AssetType":"Hostname","Identifier":"www.example.com","Annotations":[{"Key":"k","Value":"v"}]}`,
+			wantViolations: nil,
+		},
+		{
+			name:           "null annotations",
+			value:          `{"Id":"asset-1","Team":{"Id":"team-1"},"AssetType":"Hostname","Identifier":"www.example.com","Annotations":null}`,
+			wantViolations: nil,
+		},
+		{
+			name:           "unknown properties",
+			value:          `{"Id":"asset-1","Team":{"Id":"team-1"},"AssetType":"Hostname","Identifier":"www.example.com","Environment":"prod"}`,
+			wantViolations: nil,
+		},
+		{
+			name:  "missing properties",
+			value: `{"Id":"asset-1","Team":{"Name":"Team 1"},"AssetType":"Hostname"}`,
+			wantViolations: []string{
+				`$: missing property "Identifier"`,
+				`$.Team: missing property "Id"`,
+			},
+		},
+		{
+			name:  "wrong types",
+			value: `{"Id":1,"Team":{"Id":"team-1"},"Scannable":"true","AssetType":"Hostname","Identifier":"www.example.com","Annotations":[{"Key":"k","Value":2}]}`,
+			wantViolations: []string{
+				`$.Annotations[0].Value: got integer, want string`,
+				`$.Id: got integer, want string`,
+				`$.Scannable: got string, want boolean`,
+			},
+		},
+		{
+			name:  "empty identifier",
+			value: `{"Id":"asset-1","Team":{"Id":"team-1"},"AssetType":"Hostname","Identifier":""}`,
+			wantViolations: []string{
+				`$.Identifier: length 0 is less than 1`,
+			},
+		},
+		{
+			name:  "not an object",
+			value: `["asset-1"]`,
+			wantViolations: []string{
+				`$: got array, want object`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAssetPayload([]byte(tt.value))
+
+			var got []string
+			var serr SchemaError
+			if errors.As(err, &serr) {
+				got = serr.Violations
+			} else if err != nil {
+				t.Fatalf("unexpected error type: %T", err)
+			}
+
+			if diff := cmp.Diff(tt.wantViolations, got); diff != "" {
+				t.Errorf("violations mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAssetPayloadInvalidJSON(t *testing.T) {
+	err := ValidateAssetPayload([]byte(`{"Id":`))
+
+	var serr SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected schema error: %v", err)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{
+			name:    "embedded schema",
+			schema:  string(assetPayloadSchemaJSON),
+			wantErr: false,
+		},
+		{
+			name:    "annotations",
+			schema:  `{"$schema":"http://json-schema.org/draft-07/schema#","title":"t","description":"d","type":"string"}`,
+			wantErr: false,
+		},
+		{
+			name:    "unsupported keyword",
+			schema:  `{"type":"string","pattern":"^a"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported keyword in property",
+			schema:  `{"type":"object","properties":{"Id":{"type":"string","enum":["a"]}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported keyword in items",
+			schema:  `{"type":"array","items":{"type":"object","additionalProperties":false}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchema([]byte(tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAssetPayloadTestdata(t *testing.T) {
+	for _, msg := range streamtest.MustParse("testdata/valid_assets.json") {
+		if msg.Value == nil {
+			continue
+		}
+		if err := ValidateAssetPayload(msg.Value); err != nil {
+			t.Errorf("message %q does not conform to the schema: %v", msg.Key, err)
+		}
+	}
+}
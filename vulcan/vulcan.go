@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/adevinta/graph-vulcan-assets/stream"
 )
@@ -21,9 +22,58 @@ const (
 
 	// AssetsEntityName is the name of the entity linked to assets.
 	AssetsEntityName = "assets-v0"
+
+	// EntityKey is the key of the metadata entry that identifies the
+	// entity of a message when several entities are multiplexed in the
+	// same topic. The "type" metadata entry cannot be used for this
+	// purpose because it contains the type of the assets.
+	EntityKey = "entity"
+)
+
+// Entities that can be multiplexed in a topic. They are the valid values of
+// the [EntityKey] metadata entry.
+const (
+	AssetEntity   = "asset"
+	FindingEntity = "finding"
+	TeamEntity    = "team"
 )
 
-var ErrUnsupportedVersion = errors.New("unsupported version")
+var (
+	// ErrUnsupportedVersion is returned when the version of a message is
+	// not supported by [Client].
+	ErrUnsupportedVersion = errors.New("unsupported version")
+
+	// ErrMalformedPayload is returned when a message cannot be parsed.
+	ErrMalformedPayload = errors.New("malformed payload")
+
+	// ErrUnsupportedEntity is returned when the entity of a message is
+	// not supported by [Client].
+	ErrUnsupportedEntity = errors.New("unsupported entity")
+)
+
+// InvalidMessageError is returned when a message coming from the stream
+// cannot be processed. Reason is [ErrUnsupportedVersion],
+// [ErrMalformedPayload] or [ErrUnsupportedEntity]. AssetType, TeamID and
+// Position are filled on a best-effort basis, so they can be empty.
+type InvalidMessageError struct {
+	Reason    error
+	AssetType AssetType
+	TeamID    string
+	Position  stream.Position
+	Err       error
+}
+
+func (e InvalidMessageError) Error() string {
+	if e.Err == nil {
+		return e.Reason.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Reason, e.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (e InvalidMessageError) Unwrap() error {
+	return e.Reason
+}
 
 // AssetPayload represents the "assetPayload" model as defined by the Vulcan
 // async API.
@@ -36,6 +86,15 @@ type AssetPayload struct {
 	AssetType   AssetType    `json:"AssetType"`
 	Identifier  string       `json:"Identifier"`
 	Annotations []Annotation `json:"Annotations"`
+
+	// Position is the position in the stream of the message that
+	// contained the asset. It is not part of the Vulcan async API.
+	Position stream.Position `json:"-"`
+
+	// Timestamp is the timestamp of the message that contained the
+	// asset. It is zero if it is not available. It is not part of the
+	// Vulcan async API.
+	Timestamp time.Time `json:"-"`
 }
 
 // Team represents the "team" model as defined by the Vulcan async API.
@@ -84,18 +143,104 @@ func NewClient(proc stream.Processor) Client {
 // using the provided handler. This method blocks the calling goroutine until
 // the specified context is cancelled.
 func (c Client) ProcessAssets(ctx context.Context, h AssetHandler) error {
-	return c.proc.Process(ctx, AssetsEntityName, func(msg stream.Message) error {
-		version, typ, identifier, err := parseMetadata(msg)
-		if err != nil {
-			return fmt.Errorf("invalid metadata: %w", err)
+	return c.proc.Process(ctx, AssetsEntityName, assetMsgHandler(h))
+}
+
+// Handlers contains the handlers of the entities multiplexed in a topic.
+// Nil handlers are ignored, which means that the messages of the
+// corresponding entities are skipped.
+type Handlers struct {
+	Asset   AssetHandler
+	Finding stream.MsgHandler
+	Team    stream.MsgHandler
+
+	// Ordering, if not nil, serializes the messages of all the
+	// entities that refer to the same asset identifier. It can be
+	// shared by the handlers of several topics.
+	Ordering *Ordering
+}
+
+// ProcessEntities receives the messages of the entities multiplexed in the
+// provided topic and dispatches them to the corresponding handler of hs
+// according to their [EntityKey] metadata entry. Messages without this entry
+// are considered assets, so topics with only assets can also be processed.
+// Messages of unknown entities return an [InvalidMessageError] with reason
+// [ErrUnsupportedEntity]. This method blocks the calling goroutine until the
+// specified context is cancelled. Several topics can be processed
+// concurrently only if the processor of the client supports it, like the
+// ProcessorPool of the stream/kafka package.
+func (c Client) ProcessEntities(ctx context.Context, topic string, hs Handlers) error {
+	var assetHandler stream.MsgHandler
+	if hs.Asset != nil {
+		assetHandler = assetMsgHandler(hs.Asset)
+	}
+
+	return c.proc.Process(ctx, topic, hs.Ordering.handler(func(msg stream.Message) error {
+		var h stream.MsgHandler
+
+		switch entity := metadataValue(msg, EntityKey); entity {
+		case "", AssetEntity:
+			h = assetHandler
+		case FindingEntity:
+			h = hs.Finding
+		case TeamEntity:
+			h = hs.Team
+		default:
+			return InvalidMessageError{
+				Reason:   ErrUnsupportedEntity,
+				Position: msg.Position,
+				Err:      fmt.Errorf("entity %q", entity),
+			}
 		}
 
-		if !supportedVersion(version) {
-			return ErrUnsupportedVersion
+		if h == nil {
+			return nil
 		}
+		return h(msg)
+	}))
+}
+
+// metadataValue returns the value of the metadata entry of msg with the
+// provided key. If there is no such entry, it returns an empty string.
+func metadataValue(msg stream.Message, key string) string {
+	for _, e := range msg.Metadata {
+		if string(e.Key) == key {
+			return string(e.Value)
+		}
+	}
+	return ""
+}
 
+// assetMsgHandler returns a [stream.MsgHandler] that parses asset messages
+// and processes them with h.
+func assetMsgHandler(h AssetHandler) stream.MsgHandler {
+	return func(msg stream.Message) error {
 		id := string(msg.Key)
 
+		// The team ID is only used to provide context in errors.
+		teamID, _, _ := parseMessageID(id)
+
+		version, typ, identifier, err := parseMetadata(msg)
+		if err != nil {
+			return InvalidMessageError{
+				Reason:    ErrMalformedPayload,
+				AssetType: AssetType(typ),
+				TeamID:    teamID,
+				Position:  msg.Position,
+				Err:       fmt.Errorf("invalid metadata: %w", err),
+			}
+		}
+
+		if !SupportedVersion(version) {
+			return InvalidMessageError{
+				Reason:    ErrUnsupportedVersion,
+				AssetType: AssetType(typ),
+				TeamID:    teamID,
+				Position:  msg.Position,
+				Err:       fmt.Errorf("version %q", version),
+			}
+		}
+
 		var (
 			payload AssetPayload
 			isNil   bool
@@ -103,12 +248,23 @@ func (c Client) ProcessAssets(ctx context.Context, h AssetHandler) error {
 
 		if msg.Value != nil {
 			if err := json.Unmarshal(msg.Value, &payload); err != nil {
-				return fmt.Errorf("could not unmarshal asset with ID %q: %w", id, err)
+				return InvalidMessageError{
+					Reason:    ErrMalformedPayload,
+					AssetType: AssetType(typ),
+					TeamID:    teamID,
+					Position:  msg.Position,
+					Err:       fmt.Errorf("could not unmarshal asset with ID %q: %w", id, err),
+				}
 			}
 		} else {
 			teamID, assetID, err := parseMessageID(id)
 			if err != nil {
-				return fmt.Errorf("could not parse message ID %q: %w", id, err)
+				return InvalidMessageError{
+					Reason:    ErrMalformedPayload,
+					AssetType: AssetType(typ),
+					Position:  msg.Position,
+					Err:       fmt.Errorf("could not parse message ID %q: %w", id, err),
+				}
 			}
 
 			payload.ID = assetID
@@ -117,9 +273,11 @@ func (c Client) ProcessAssets(ctx context.Context, h AssetHandler) error {
 			payload.Team.ID = teamID
 			isNil = true
 		}
+		payload.Position = msg.Position
+		payload.Timestamp = msg.Timestamp
 
 		return h(payload, isNil)
-	})
+	}
 }
 
 // parseMessageID parses an asset message ID and returns the corresponding team
@@ -143,7 +301,7 @@ func parseMetadata(msg stream.Message) (version, typ, identifier string, err err
 			version = value
 		case "type":
 			typ = value
-		case "identifier":
+		case IdentifierKey:
 			identifier = value
 		}
 	}
@@ -155,9 +313,10 @@ func parseMetadata(msg stream.Message) (version, typ, identifier string, err err
 	return version, typ, identifier, nil
 }
 
-// supportedVersion takes a semantic version string and returns true if it is
-// compatible with [Client].
-func supportedVersion(v string) bool {
+// SupportedVersion takes a semantic version string and returns true if it is
+// compatible with [Client]. The version of a message is the value of its
+// "version" metadata entry.
+func SupportedVersion(v string) bool {
 	if v == "" {
 		return false
 	}
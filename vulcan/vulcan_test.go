@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/adevinta/graph-vulcan-assets/stream"
 	"github.com/adevinta/graph-vulcan-assets/stream/streamtest"
@@ -16,6 +18,10 @@ type asset struct {
 	IsNil   bool
 }
 
+// ignorePosition ignores the position of the payloads, which is checked by
+// [TestClientProcessAssetsPosition].
+var ignorePosition = cmpopts.IgnoreFields(AssetPayload{}, "Position")
+
 var testdataValidAssets = []asset{
 	{
 		Payload: AssetPayload{
@@ -143,25 +149,51 @@ func TestClientProcessAssets(t *testing.T) {
 		name       string
 		msgs       []stream.Message
 		wantAssets []asset
-		wantNilErr bool
+		wantErr    error
 	}{
 		{
 			name:       "valid assets",
 			msgs:       streamtest.MustParse("testdata/valid_assets.json"),
 			wantAssets: testdataValidAssets,
-			wantNilErr: true,
+			wantErr:    nil,
 		},
 		{
 			name:       "malformed assets",
 			msgs:       streamtest.MustParse("testdata/malformed_assets.json"),
 			wantAssets: testdataValidAssets[:2],
-			wantNilErr: false,
+			wantErr:    ErrMalformedPayload,
 		},
 		{
 			name:       "unsupported version",
 			msgs:       streamtest.MustParse("testdata/unsupported_version.json"),
 			wantAssets: testdataValidAssets[:2],
-			wantNilErr: false,
+			wantErr:    ErrUnsupportedVersion,
+		},
+		{
+			name: "built messages",
+			msgs: []stream.Message{
+				streamtest.NewAssetMessage().
+					WithTeam("9a1a0332-88b6-4edc-aa37-50adc1ad96da", "Team name 0").
+					WithTeamDetails("Team description 0", "a76e1486").
+					WithID("f110cf6f-803d-442c-9b42-f6d8cd962bf2").
+					WithAsset("Hostname", "www.example.com").
+					WithAlias("Asset alias 0").
+					WithRolfp("R:0/O:1/L:0/F:1/P:0+S:1").
+					WithScannable(true).
+					WithAnnotations(map[string]string{
+						"annotation0/0": "value0/0",
+						"annotation0/1": "value0/1",
+					}).
+					Message(),
+				streamtest.NewAssetMessage().
+					WithTeam("bdb2e4a3-5d86-46f8-aae0-b2cd3a56e230", "").
+					WithID("15ae9294-e1ed-4615-8423-2b78e5d04b95").
+					WithAsset("DockerImage", "nilvalue:latest").
+					Tombstone().
+					Message(),
+			},
+			wantAssets: []asset{testdataValidAssets[0], testdataValidAssets[4]},
+			wantErr:    nil,
 		},
 	}
 	for _, tt := range tests {
@@ -175,11 +207,11 @@ func TestClientProcessAssets(t *testing.T) {
 				return nil
 			})
 
-			if (err == nil) != tt.wantNilErr {
-				t.Errorf("unexpected error: wantNilErr=%v got=%v", tt.wantNilErr, err)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.wantErr, err)
 			}
 
-			if diff := cmp.Diff(tt.wantAssets, got); diff != "" {
+			if diff := cmp.Diff(tt.wantAssets, got, ignorePosition); diff != "" {
 				t.Errorf("asset mismatch (-want +got):\n%v", diff)
 			}
 		})
@@ -218,11 +250,197 @@ func TestClientProcessAssetsError(t *testing.T) {
 		t.Errorf("error mismatch: want=%v got=%v", wantErr, err)
 	}
 
-	if diff := cmp.Diff(testdataValidAssets[:n], got); diff != "" {
+	if diff := cmp.Diff(testdataValidAssets[:n], got, ignorePosition); diff != "" {
 		t.Errorf("asset mismatch (-want +got):\n%v", diff)
 	}
 }
 
+func TestClientProcessAssetsPosition(t *testing.T) {
+	mp := streamtest.NewMockProcessor(streamtest.MustParse("testdata/valid_assets.json"))
+	cli := NewClient(mp)
+
+	var got []stream.Position
+	err := cli.ProcessAssets(context.Background(), func(payload AssetPayload, isNil bool) error {
+		got = append(got, payload.Position)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing assets: %v", err)
+	}
+
+	var want []stream.Position
+	for i := range testdataValidAssets {
+		want = append(want, stream.Position{Topic: AssetsEntityName, Offset: int64(i)})
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("position mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestClientProcessAssetsTimestamp(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	msgs := streamtest.MustParse("testdata/valid_assets.json")
+	for i := range msgs {
+		msgs[i].Timestamp = ts.Add(time.Duration(i) * time.Second)
+	}
+	cli := NewClient(streamtest.NewMockProcessor(msgs))
+
+	var got []time.Time
+	err := cli.ProcessAssets(context.Background(), func(payload AssetPayload, isNil bool) error {
+		got = append(got, payload.Timestamp)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error processing assets: %v", err)
+	}
+
+	var want []time.Time
+	for i := range msgs {
+		want = append(want, ts.Add(time.Duration(i)*time.Second))
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("timestamp mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestClientProcessAssetsInvalidMessageError(t *testing.T) {
+	mp := streamtest.NewMockProcessor(streamtest.MustParse("testdata/unsupported_version.json"))
+	cli := NewClient(mp)
+
+	err := cli.ProcessAssets(context.Background(), func(payload AssetPayload, isNil bool) error {
+		return nil
+	})
+
+	var merr InvalidMessageError
+	if !errors.As(err, &merr) {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+
+	if merr.Reason != ErrUnsupportedVersion {
+		t.Errorf("unexpected reason: want=%v got=%v", ErrUnsupportedVersion, merr.Reason)
+	}
+
+	if merr.AssetType != "DockerImage" {
+		t.Errorf("unexpected asset type: want=%v got=%v", "DockerImage", merr.AssetType)
+	}
+
+	if merr.TeamID != "e363e9a0-2e0d-465d-99ea-5851dd962e92" {
+		t.Errorf("unexpected team ID: want=%v got=%v", "e363e9a0-2e0d-465d-99ea-5851dd962e92", merr.TeamID)
+	}
+
+	wantPos := stream.Position{Topic: AssetsEntityName, Partition: 0, Offset: 2}
+	if merr.Position != wantPos {
+		t.Errorf("unexpected position: want=%v got=%v", wantPos, merr.Position)
+	}
+}
+
+// withEntity returns a copy of msg with the provided entity metadata entry.
+func withEntity(msg stream.Message, entity string) stream.Message {
+	msg.Metadata = append(msg.Metadata, stream.MetadataEntry{Key: []byte(EntityKey), Value: []byte(entity)})
+	return msg
+}
+
+func TestClientProcessEntities(t *testing.T) {
+	assetMsg := streamtest.NewAssetMessage().Message()
+	findingMsg := stream.Message{Key: []byte("finding-1"), Value: []byte(`{}`)}
+	teamMsg := stream.Message{Key: []byte("team-1"), Value: []byte(`{}`)}
+
+	tests := []struct {
+		name       string
+		msgs       []stream.Message
+		hs         func(got *[]string) Handlers
+		want       []string
+		wantReason error
+	}{
+		{
+			name: "dispatch",
+			msgs: []stream.Message{
+				assetMsg,
+				withEntity(assetMsg, AssetEntity),
+				withEntity(findingMsg, FindingEntity),
+				withEntity(teamMsg, TeamEntity),
+			},
+			hs: func(got *[]string) Handlers {
+				return Handlers{
+					Asset: func(payload AssetPayload, isNil bool) error {
+						*got = append(*got, "asset:"+payload.Identifier)
+						return nil
+					},
+					Finding: func(msg stream.Message) error {
+						*got = append(*got, "finding:"+string(msg.Key))
+						return nil
+					},
+					Team: func(msg stream.Message) error {
+						*got = append(*got, "team:"+string(msg.Key))
+						return nil
+					},
+				}
+			},
+			want: []string{
+				"asset:" + streamtest.DefaultAssetIdentifier,
+				"asset:" + streamtest.DefaultAssetIdentifier,
+				"finding:finding-1",
+				"team:team-1",
+			},
+		},
+		{
+			name: "nil handlers",
+			msgs: []stream.Message{
+				withEntity(findingMsg, FindingEntity),
+				withEntity(teamMsg, TeamEntity),
+				assetMsg,
+			},
+			hs: func(got *[]string) Handlers {
+				return Handlers{
+					Asset: func(payload AssetPayload, isNil bool) error {
+						*got = append(*got, "asset:"+payload.Identifier)
+						return nil
+					},
+				}
+			},
+			want: []string{"asset:" + streamtest.DefaultAssetIdentifier},
+		},
+		{
+			name: "unsupported entity",
+			msgs: []stream.Message{
+				assetMsg,
+				withEntity(teamMsg, "unknown"),
+				assetMsg,
+			},
+			hs: func(got *[]string) Handlers {
+				return Handlers{
+					Asset: func(payload AssetPayload, isNil bool) error {
+						*got = append(*got, "asset:"+payload.Identifier)
+						return nil
+					},
+				}
+			},
+			want:       []string{"asset:" + streamtest.DefaultAssetIdentifier},
+			wantReason: ErrUnsupportedEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := NewClient(streamtest.NewMockProcessor(tt.msgs))
+
+			var got []string
+			err := cli.ProcessEntities(context.Background(), "entities-v1", tt.hs(&got))
+
+			if !errors.Is(err, tt.wantReason) {
+				t.Errorf("unexpected error: want=%v got=%v", tt.wantReason, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("processed messages mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
 func TestSupportedVersion(t *testing.T) {
 	tests := []struct {
 		name string
@@ -258,10 +476,52 @@ func TestSupportedVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := supportedVersion(tt.v)
+			got := SupportedVersion(tt.v)
 			if got != tt.want {
 				t.Errorf("unexpected result: v=%v got=%v want=%v", tt.v, got, tt.want)
 			}
 		})
 	}
 }
+
+func FuzzClientProcessAssets(f *testing.F) {
+	for _, filename := range []string{
+		"testdata/valid_assets.json",
+		"testdata/malformed_assets.json",
+		"testdata/unsupported_version.json",
+	} {
+		streamtest.AddToCorpus(f, streamtest.MustParse(filename))
+	}
+
+	f.Fuzz(func(t *testing.T, key, value []byte, isNil bool, metadata []byte) {
+		msg := streamtest.FuzzMessage(key, value, isNil, metadata)
+		cli := NewClient(streamtest.NewMockProcessor([]stream.Message{msg}))
+
+		var calls int
+		err := cli.ProcessAssets(context.Background(), func(payload AssetPayload, isNil bool) error {
+			calls++
+			if isNil != (msg.Value == nil) {
+				t.Errorf("unexpected isNil: want=%v, got=%v", msg.Value == nil, isNil)
+			}
+			return nil
+		})
+
+		if err != nil {
+			var imerr InvalidMessageError
+			if !errors.As(err, &imerr) {
+				t.Fatalf("unexpected error type: %T: %v", err, err)
+			}
+			if !errors.Is(err, ErrMalformedPayload) && !errors.Is(err, ErrUnsupportedVersion) {
+				t.Fatalf("unexpected error reason: %v", err)
+			}
+			if calls != 0 {
+				t.Errorf("handler called for invalid message")
+			}
+			return
+		}
+
+		if calls != 1 {
+			t.Errorf("unexpected number of handler calls: want=1, got=%v", calls)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+// Package wal implements a local write-ahead log of pending operations. An
+// operation is recorded before being executed and marked as done after. If
+// the process crashes in between, the operation is reported as pending the
+// next time the log is opened, so it can be replayed.
+//
+// The log is stored in a file with one JSON record per line. Records are
+// synced to disk before [Log.Begin] returns. The file is compacted when it is
+// opened and when there are no pending operations and its size exceeds
+// [MaxSize].
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// MaxSize is the size in bytes above which the log file is truncated when
+// there are no pending operations.
+const MaxSize = 1 << 20
+
+// Entry is an operation recorded in the log.
+type Entry struct {
+	// Seq is the sequence number of the operation.
+	Seq uint64 `json:"seq"`
+
+	// Data is the JSON encoded operation.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// record is a line of the log file. If Done is true, it marks the operation
+// with sequence number Seq as done. Otherwise, it records the operation.
+type record struct {
+	Entry
+	Done bool `json:"done,omitempty"`
+}
+
+// Log is a write-ahead log stored in a file. It is safe for concurrent use.
+type Log struct {
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	next    uint64
+	pending map[uint64]bool
+}
+
+// Open opens the log stored in the file with the provided name, creating
+// it if it does not exist. It returns the log and the operations that were
+// recorded but not marked as done, sorted by sequence number. Pending
+// operations are kept in the log until they are marked as done.
+func Open(name string) (*Log, []Entry, error) {
+	entries, err := readPending(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Rewrite the log with the pending operations only.
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create log file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	l := &Log{f: tmp, pending: make(map[uint64]bool)}
+	for _, e := range entries {
+		if err := l.write(record{Entry: e}); err != nil {
+			tmp.Close()
+			return nil, nil, err
+		}
+		l.pending[e.Seq] = true
+		l.next = e.Seq + 1
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, nil, fmt.Errorf("could not sync log file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		tmp.Close()
+		return nil, nil, fmt.Errorf("could not rename log file: %w", err)
+	}
+
+	return l, entries, nil
+}
+
+// readPending returns the pending operations stored in the log file with the
+// provided name. A truncated last line, caused by a crash while writing it,
+// is ignored.
+func readPending(name string) ([]Entry, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file: %w", err)
+	}
+	defer f.Close()
+
+	pending := make(map[uint64]Entry)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 64<<20)
+	for sc.Scan() {
+		var r record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			// Only the last line can be corrupted.
+			if sc.Scan() {
+				return nil, fmt.Errorf("corrupted log record: %w", err)
+			}
+			break
+		}
+		if r.Done {
+			delete(pending, r.Seq)
+		} else {
+			pending[r.Seq] = r.Entry
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read log file: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(pending))
+	for _, e := range pending {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+	return entries, nil
+}
+
+// Begin records the provided operation, which is encoded as JSON. It
+// returns the sequence number of the operation, that must be passed to
+// [Log.Done] once the operation has been executed.
+func (l *Log) Begin(v any) (uint64, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0, fmt.Errorf("could not marshal operation: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seq := l.next
+	if err := l.write(record{Entry: Entry{Seq: seq, Data: data}}); err != nil {
+		return 0, err
+	}
+	if err := l.f.Sync(); err != nil {
+		return 0, fmt.Errorf("could not sync log file: %w", err)
+	}
+	l.next++
+	l.pending[seq] = true
+
+	return seq, nil
+}
+
+// Done marks the operation with the provided sequence number as done. The
+// record is not synced to disk, so the operation could be reported again as
+// pending after a crash. Thus, operations must be idempotent.
+func (l *Log) Done(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.pending[seq] {
+		return fmt.Errorf("unknown operation %v", seq)
+	}
+	delete(l.pending, seq)
+
+	if len(l.pending) == 0 && l.size > MaxSize {
+		return l.truncate()
+	}
+	return l.write(record{Entry: Entry{Seq: seq}, Done: true})
+}
+
+// Pending returns the number of pending operations.
+func (l *Log) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.pending)
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.f.Close()
+}
+
+// write appends r to the log file.
+func (l *Log) write(r record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("could not marshal log record: %w", err)
+	}
+	b = append(b, '\n')
+
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("could not write log record: %w", err)
+	}
+	return nil
+}
+
+// truncate removes all the records of the log file. It must only be called
+// when there are no pending operations.
+func (l *Log) truncate() error {
+	if err := l.f.Truncate(0); err != nil {
+		return fmt.Errorf("could not truncate log file: %w", err)
+	}
+	if _, err := l.f.Seek(0, 0); err != nil {
+		return fmt.Errorf("could not truncate log file: %w", err)
+	}
+	l.size = 0
+	return nil
+}
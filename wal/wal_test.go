@@ -0,0 +1,126 @@
+package wal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLog(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "wal")
+
+	l, pending, err := Open(name)
+	if err != nil {
+		t.Fatalf("error opening log: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("unexpected pending entries: %v", pending)
+	}
+
+	var seqs []uint64
+	for _, op := range []string{"op-1", "op-2", "op-3"} {
+		seq, err := l.Begin(op)
+		if err != nil {
+			t.Fatalf("error recording operation: %v", err)
+		}
+		seqs = append(seqs, seq)
+	}
+	if err := l.Done(seqs[1]); err != nil {
+		t.Fatalf("error marking operation as done: %v", err)
+	}
+	if err := l.Done(seqs[1]); err == nil {
+		t.Errorf("operation marked as done twice")
+	}
+	if n := l.Pending(); n != 2 {
+		t.Errorf("unexpected number of pending operations: got: %v, want: 2", n)
+	}
+
+	// Simulate a crash.
+	if err := l.Close(); err != nil {
+		t.Fatalf("error closing log: %v", err)
+	}
+
+	l, pending, err = Open(name)
+	if err != nil {
+		t.Fatalf("error opening log: %v", err)
+	}
+	defer l.Close()
+
+	want := []Entry{
+		{Seq: seqs[0], Data: json.RawMessage(`"op-1"`)},
+		{Seq: seqs[2], Data: json.RawMessage(`"op-3"`)},
+	}
+	if diff := cmp.Diff(want, pending); diff != "" {
+		t.Errorf("pending entries mismatch (-want +got):\n%v", diff)
+	}
+
+	// Sequence numbers of new operations do not collide with the pending
+	// ones.
+	seq, err := l.Begin("op-4")
+	if err != nil {
+		t.Fatalf("error recording operation: %v", err)
+	}
+	if seq <= seqs[2] {
+		t.Errorf("reused sequence number: %v", seq)
+	}
+}
+
+func TestOpenTruncatedRecord(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "wal")
+	data := `{"seq":0,"data":"op-1"}` + "\n" + `{"seq":1,"da`
+	if err := os.WriteFile(name, []byte(data), 0o600); err != nil {
+		t.Fatalf("error writing log: %v", err)
+	}
+
+	l, pending, err := Open(name)
+	if err != nil {
+		t.Fatalf("error opening log: %v", err)
+	}
+	defer l.Close()
+
+	want := []Entry{{Seq: 0, Data: json.RawMessage(`"op-1"`)}}
+	if diff := cmp.Diff(want, pending); diff != "" {
+		t.Errorf("pending entries mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestOpenCorruptedRecord(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "wal")
+	data := `{"seq":0,"da` + "\n" + `{"seq":1,"data":"op-2"}` + "\n"
+	if err := os.WriteFile(name, []byte(data), 0o600); err != nil {
+		t.Fatalf("error writing log: %v", err)
+	}
+
+	if _, _, err := Open(name); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestDoneTruncate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "wal")
+
+	l, _, err := Open(name)
+	if err != nil {
+		t.Fatalf("error opening log: %v", err)
+	}
+	defer l.Close()
+
+	seq, err := l.Begin(make([]byte, MaxSize))
+	if err != nil {
+		t.Fatalf("error recording operation: %v", err)
+	}
+	if err := l.Done(seq); err != nil {
+		t.Fatalf("error marking operation as done: %v", err)
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("error getting log size: %v", err)
+	}
+	if fi.Size() != 0 {
+		t.Errorf("log not truncated: size=%v", fi.Size())
+	}
+}